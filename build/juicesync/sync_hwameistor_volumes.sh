@@ -1 +1,2 @@
-SSH_PRIVATE_KEY_PATH=/root/.ssh/id_rsa juicesync --links --dirs --perms --force-update root@${sourceNode}:${sourceMountPoint}/ root@${targetNode}:${targetMountPoint}/
+# the extra options are passed as the arguments, e.g. "--bwlimit 800"
+SSH_PRIVATE_KEY_PATH=/root/.ssh/id_rsa juicesync --links --dirs --perms --force-update "$@" root@${sourceNode}:${sourceMountPoint}/ root@${targetNode}:${targetMountPoint}/
@@ -6,6 +6,7 @@ import (
 	"os"
 
 	clusterapiv1alpha1 "github.com/hwameistor/hwameistor-operator/api/v1alpha1"
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 	"github.com/hwameistor/hwameistor/pkg/auditor"
 	"github.com/kubernetes-csi/csi-lib-utils/leaderelection"
 	"github.com/operator-framework/operator-sdk/pkg/k8sutil"
@@ -53,6 +54,10 @@ func main() {
 		log.WithError(err).Error("Failed to setup scheme for all HwameiStor resources")
 		os.Exit(1)
 	}
+	if err := apisv1alpha1.AddToScheme(mgr.GetScheme()); err != nil {
+		log.WithError(err).Error("Failed to setup scheme for all local storage resources")
+		os.Exit(1)
+	}
 
 	go func() {
 		log.Info("Starting the manager of all local storage resources.")
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/hwameistor/hwameistor/pkg/local-storage/utils/datacopy"
+)
+
+var (
+	sourceHost   = flag.String("source-host", "", "host of the source node to read the block device from")
+	sourceDevice = flag.String("source-device", "", "path of the block device on the source node")
+	targetDevice = flag.String("target-device", "", "path of the local block device to write into")
+	sshKeyFile   = flag.String("ssh-key", "/root/.ssh/id_rsa", "private key to access the source node over ssh")
+	chunkSize    = flag.Int("chunk-size", datacopy.BlockSyncDefaultChunkSize, "size of the chunk to copy at a time")
+	skipZero     = flag.Bool("skip-zero", false, "skip writing the zeroed chunks, set for the thin target only")
+	verifyTarget = flag.Bool("verify-target", false, "read back the target device and verify its checksum after the copy")
+	bandwidth    = flag.Int64("bandwidth-limit", 0, "max bytes per second to read from the source, no limit if 0")
+	progressFile = flag.String("progress-file", "", "file to record the bytes copied, reported by the node as the progress")
+	sendMode     = flag.Bool("send", false, "run as the sender on the source node, which reads the device from stdin and writes the block stream into stdout")
+)
+
+const (
+	progressInterval = 5 * time.Second
+	// senderPath is where the binary itself is uploaded on the source node to run as the sender
+	senderPath = "/var/lib/hwameistor/bin/blocksync"
+)
+
+func main() {
+	flag.Parse()
+	log.SetLevel(log.DebugLevel)
+
+	if *sendMode {
+		// stdout is the block stream, so the logs go to stderr
+		if err := sendBlocks(); err != nil {
+			log.WithError(err).Fatal("Failed to send block device")
+		}
+		return
+	}
+
+	if len(*sourceHost) == 0 || len(*sourceDevice) == 0 || len(*targetDevice) == 0 {
+		log.Fatal("source-host, source-device and target-device are required")
+	}
+
+	if err := syncBlockDevice(); err != nil {
+		log.WithError(err).Fatal("Failed to sync block device")
+	}
+}
+
+func syncBlockDevice() error {
+	logCtx := log.WithFields(log.Fields{"sourceHost": *sourceHost, "sourceDevice": *sourceDevice, "targetDevice": *targetDevice, "skipZero": *skipZero})
+	logCtx.Info("Start to sync block device")
+
+	result, err := copyFromSource()
+	if err != nil {
+		return err
+	}
+	logCtx.WithFields(log.Fields{"bytes": result.Bytes, "zeroBytes": result.ZeroBytes, "skippedBytes": result.SkippedBytes, "checksum": result.Checksum}).Info("Copied block device")
+
+	// the checksum rolled over the received data must match the one calculated on the source node
+	output, err := sshCommand(fmt.Sprintf("sha256sum %s", *sourceDevice)).Output()
+	if err != nil {
+		return fmt.Errorf("failed to checksum source device: %v", err)
+	}
+	if fields := strings.Fields(string(output)); len(fields) == 0 || fields[0] != result.Checksum {
+		return fmt.Errorf("checksum mismatched, source: %s, received: %s", strings.TrimSpace(string(output)), result.Checksum)
+	}
+
+	if *verifyTarget {
+		target, err := os.Open(*targetDevice)
+		if err != nil {
+			return err
+		}
+		defer target.Close()
+		checksum, err := datacopy.ChecksumBlocks(io.LimitReader(target, result.Bytes))
+		if err != nil {
+			return fmt.Errorf("failed to checksum target device: %v", err)
+		}
+		if checksum != result.Checksum {
+			return fmt.Errorf("checksum mismatched, source: %s, target: %s", result.Checksum, checksum)
+		}
+	}
+
+	logCtx.Info("Block device is synced and verified")
+	return nil
+}
+
+// sendBlocks checks the device read from stdin for the zeroed chunks, and writes the block stream into stdout
+func sendBlocks() error {
+	output := bufio.NewWriterSize(os.Stdout, *chunkSize)
+	result, err := datacopy.SendBlocks(datacopy.NewThrottledReader(os.Stdin, *bandwidth), output, *chunkSize)
+	if err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{"bytes": result.Bytes, "zeroBytes": result.ZeroBytes}).Info("Sent block device")
+	return output.Flush()
+}
+
+// uploadSender copies the binary itself onto the source node, so the sender always matches the receiver
+func uploadSender() error {
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	binary, err := os.Open(executable)
+	if err != nil {
+		return err
+	}
+	defer binary.Close()
+
+	// replace the binary at once, it may be running for another sync
+	cmd := sshCommand(fmt.Sprintf("mkdir -p %s && cat > %s.$$ && chmod 0755 %s.$$ && mv -f %s.$$ %s",
+		path.Dir(senderPath), senderPath, senderPath, senderPath, senderPath))
+	cmd.Stdin = binary
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to upload sender: %s, %v", strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
+
+// copyFromSource runs the sender on the source node over ssh, and writes the block stream into the target device
+func copyFromSource() (*datacopy.BlockCopyResult, error) {
+	if err := uploadSender(); err != nil {
+		return nil, err
+	}
+
+	target, err := os.OpenFile(*targetDevice, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer target.Close()
+
+	cmd := sshCommand(fmt.Sprintf("set -o pipefail; dd if=%s bs=%d iflag=direct status=none | %s --send --chunk-size=%d --bandwidth-limit=%d",
+		*sourceDevice, *chunkSize, senderPath, *chunkSize, *bandwidth))
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	var copiedBytes int64
+	stopCh := make(chan struct{})
+	progressDone := make(chan struct{})
+	go func() {
+		defer close(progressDone)
+		reportProgress(&copiedBytes, stopCh)
+	}()
+
+	result, copyErr := datacopy.ReceiveBlocks(bufio.NewReaderSize(stdout, *chunkSize), target, *skipZero, func(n int64) {
+		atomic.StoreInt64(&copiedBytes, n)
+	})
+	close(stopCh)
+	<-progressDone
+	if copyErr != nil {
+		// stop reading the source
+		_ = cmd.Process.Kill()
+	}
+	if err := cmd.Wait(); err != nil && copyErr == nil {
+		return nil, fmt.Errorf("failed to read source device: %v", err)
+	}
+	if copyErr != nil {
+		return nil, fmt.Errorf("failed to write target device: %v", copyErr)
+	}
+	return result, target.Sync()
+}
+
+// reportProgress records the bytes copied into the progress file periodically, and once more when stopped
+func reportProgress(copiedBytes *int64, stopCh chan struct{}) {
+	if len(*progressFile) == 0 {
+		return
+	}
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			writeProgress(atomic.LoadInt64(copiedBytes))
+		case <-stopCh:
+			writeProgress(atomic.LoadInt64(copiedBytes))
+			return
+		}
+	}
+}
+
+// writeProgress replaces the progress file at once, so the node never reads a partial one
+func writeProgress(copiedBytes int64) {
+	tmpFile := *progressFile + ".tmp"
+	if err := os.WriteFile(tmpFile, []byte(strconv.FormatInt(copiedBytes, 10)), 0644); err != nil {
+		log.WithError(err).Warning("Failed to write progress")
+		return
+	}
+	if err := os.Rename(tmpFile, *progressFile); err != nil {
+		log.WithError(err).Warning("Failed to write progress")
+	}
+}
+
+// sshCommand runs the command on the source node. The data chunks are compressed by ssh on the wire
+func sshCommand(command string) *exec.Cmd {
+	return exec.Command("ssh", "-C", "-i", *sshKeyFile,
+		"-o", "StrictHostKeyChecking=no", "-o", "UserKnownHostsFile=/dev/null", "-o", "BatchMode=yes",
+		fmt.Sprintf("root@%s", *sourceHost), command)
+}
@@ -6,6 +6,8 @@ import (
 	"flag"
 	"fmt"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/disk"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/raid"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/raid/stor"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/smart"
 	"os"
 	"path"
@@ -137,7 +139,23 @@ func startNodeComponents(c context.Context, mgr manager.Manager) {
 		go disk.NewController(mgr).StartMonitor()
 
 		log.Info("starting collect S.M.A.R.T")
-		go smart.NewCollector().WithSyncPeriod(time.Hour * 6).StartTimerCollect(c)
+		go func() {
+			// the health of the disks is evaluated with the LocalDisks in the cache
+			if !mgr.GetCache().WaitForCacheSync(c) {
+				log.Info("Failed to wait for the cache to sync, the health of the disks is not evaluated")
+			}
+			smart.NewCollector().WithSyncPeriod(time.Hour * 6).
+				WithHealthMonitor(smart.NewHealthMonitor(mgr.GetClient(), csiCfg.NodeID)).StartTimerCollect(c)
+		}()
+
+		log.Info("starting sync RAID info")
+		go func() {
+			// the virtual drives are mapped to the LocalDisks in the cache
+			if !mgr.GetCache().WaitForCacheSync(c) {
+				log.Info("Failed to wait for the cache to sync, the RAID info of the disks is not synced")
+			}
+			raid.NewMonitor(mgr.GetClient(), csiCfg.NodeID, stor.NewStor()).StartTimerSync(c, raid.DefaultSyncPeriod)
+		}()
 
 		if csiCfg.Enable {
 			log.Info("starting Disk CSI Driver")
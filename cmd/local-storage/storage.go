@@ -43,7 +43,7 @@ var (
 	namespace               = flag.String("namespace", "", "Namespace of the Pod")
 	csiSockAddr             = flag.String("csi-address", "", "CSI endpoint")
 	systemMode              = flag.String("system-mode", string(apisv1alpha1.SystemModeDRBD), "dlocal system mode")
-	dataSyncToolName        = flag.String("data-sync-tool", defaultDataSyncToolName, "tool to sync the data across the nodes, e.g. juicesync")
+	dataSyncToolName        = flag.String("data-sync-tool", defaultDataSyncToolName, "tool to sync the data across the nodes, juicesync copies the files and blocksync copies the raw block device")
 	drbdStartPort           = flag.Int("drbd-start-port", defaultDRBDStartPort, "drbd start port, end port=start-port+volume-count-1")
 	haVolumeTotalCount      = flag.Int("max-ha-volume-count", defaultHAVolumeTotalCount, "max HA volume count")
 	httpPort                = flag.Int("http-port", restServerDefaultPort, "HTTP port for REST server")
@@ -52,6 +52,9 @@ var (
 	migrateDataNeedCheck    = flag.Bool("migrate-check", false, "Enable data verification during data migration")
 	snapshotRestoreTimeout  = flag.Int("snapshot-restore-timeout", 600, "Time to restore VolumeReplica Snapshot，in seconds")
 	pvMetadataSize          = flag.Int("pv-metadata-size", 4*1024*1024, "The size of the metadata of the PV in Bytes, default 4MB")
+	storageBackends         = flag.String("storage-backends", "", "storage backend of the pool classes, e.g. HDD=LVM,SSD=ZFS. LVM is used for the pool class not specified")
+	zfsCompression          = flag.String("zfs-compression", "lz4", "compression algorithm of the ZFS pools, e.g. lz4, zstd, off")
+	zfsChecksum             = flag.String("zfs-checksum", "sha256", "checksum algorithm of the ZFS pools, e.g. sha256, fletcher4")
 )
 
 var BUILDVERSION, BUILDTIME, GOVERSION string
@@ -217,6 +220,10 @@ func validateSystemConfig() error {
 		errMsgs = append(errMsgs, fmt.Sprintf("system mode %s not supported", *systemMode))
 	}
 
+	if _, err := parseStorageBackends(*storageBackends); err != nil {
+		errMsgs = append(errMsgs, err.Error())
+	}
+
 	if len(errMsgs) != 0 {
 		return fmt.Errorf(strings.Join(errMsgs, "; "))
 	}
@@ -242,9 +249,46 @@ func getSystemConfig() (apisv1alpha1.SystemConfig, error) {
 			}
 		}
 	}
+
+	config.StorageBackends, _ = parseStorageBackends(*storageBackends)
+	for _, backend := range config.StorageBackends {
+		if backend == apisv1alpha1.StorageBackendZFS {
+			config.ZFS = &apisv1alpha1.ZFSSystemConfig{
+				Compression: *zfsCompression,
+				Checksum:    *zfsChecksum,
+			}
+		}
+	}
 	return config, nil
 }
 
+// parseStorageBackends parses the storage backends of the pool classes, e.g. HDD=LVM,SSD=ZFS
+func parseStorageBackends(value string) (map[string]string, error) {
+	backends := map[string]string{}
+	for _, item := range strings.Split(value, ",") {
+		if len(strings.TrimSpace(item)) == 0 {
+			continue
+		}
+		kv := strings.Split(item, "=")
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid storage backend %s", item)
+		}
+		poolClass, backend := strings.TrimSpace(kv[0]), strings.ToUpper(strings.TrimSpace(kv[1]))
+		switch poolClass {
+		case apisv1alpha1.DiskClassNameHDD, apisv1alpha1.DiskClassNameSSD, apisv1alpha1.DiskClassNameNVMe:
+		default:
+			return nil, fmt.Errorf("pool class %s not supported", poolClass)
+		}
+		switch backend {
+		case apisv1alpha1.StorageBackendLVM, apisv1alpha1.StorageBackendZFS:
+		default:
+			return nil, fmt.Errorf("storage backend %s not supported", backend)
+		}
+		backends[poolClass] = backend
+	}
+	return backends, nil
+}
+
 // setIndexField must be called after scheme has been added
 func setIndexField(cache cache.Cache) {
 	indexes := []struct {
@@ -0,0 +1,123 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// DefaultDiskHealthPolicyName is the name of the DiskHealthPolicy applied to all the disks.
+// The built-in thresholds and reaction are used if it doesn't exist
+const DefaultDiskHealthPolicyName = "default"
+
+// DiskHealthThreshold defines when a S.M.A.R.T attribute makes the disk Degraded or Failing.
+// A zero value disables the check
+type DiskHealthThreshold struct {
+	// Degraded is the value at which the disk becomes Degraded
+	// +optional
+	Degraded int64 `json:"degraded,omitempty"`
+
+	// Failing is the value at which the disk becomes Failing
+	// +optional
+	Failing int64 `json:"failing,omitempty"`
+
+	// Growth is the increase within the sample window at which the disk becomes Degraded,
+	// it works for the counters only
+	// +optional
+	Growth int64 `json:"growth,omitempty"`
+}
+
+// DiskHealthThresholds are the thresholds of the S.M.A.R.T attributes, the built-in one is used if not set
+type DiskHealthThresholds struct {
+	// ReallocatedSectors is the count of the reallocated sectors (ATA attribute 5, SCSI grown defect list)
+	// +optional
+	ReallocatedSectors *DiskHealthThreshold `json:"reallocatedSectors,omitempty"`
+
+	// PendingSectors is the count of the sectors waiting to be reallocated (ATA attribute 197)
+	// +optional
+	PendingSectors *DiskHealthThreshold `json:"pendingSectors,omitempty"`
+
+	// MediaErrors is the count of the unrecovered data integrity errors (NVMe)
+	// +optional
+	MediaErrors *DiskHealthThreshold `json:"mediaErrors,omitempty"`
+
+	// PercentageUsed is the vendor estimate of the percentage of the life used (NVMe)
+	// +optional
+	PercentageUsed *DiskHealthThreshold `json:"percentageUsed,omitempty"`
+
+	// CRCErrors is the count of the CRC errors in the interface transfers (ATA attribute 199)
+	// +optional
+	CRCErrors *DiskHealthThreshold `json:"crcErrors,omitempty"`
+
+	// Temperature is the temperature in Celsius. The disk is Degraded if the average in the sample window
+	// reaches the threshold, and Failing if the latest sample reaches the threshold
+	// +optional
+	Temperature *DiskHealthThreshold `json:"temperature,omitempty"`
+}
+
+// DiskHealthReaction defines how a disk in the LocalStorage pool is handled when it's unhealthy
+type DiskHealthReaction struct {
+	// CordonOn is the health state at which the disk is marked non-allocatable in the pool,
+	// no more volume is allocated on it. The disk is allocatable again once it's healthier
+	// +kubebuilder:validation:Enum:=Degraded;Failing;Never
+	// +optional
+	CordonOn DiskHealthState `json:"cordonOn,omitempty"`
+
+	// EvacuateOn is the health state at which the volumes on the disk are migrated to the other nodes
+	// +kubebuilder:validation:Enum:=Degraded;Failing;Never
+	// +optional
+	EvacuateOn DiskHealthState `json:"evacuateOn,omitempty"`
+}
+
+// DiskHealthPolicySpec defines the desired state of DiskHealthPolicy
+type DiskHealthPolicySpec struct {
+	// SampleWindow is the number of the recent S.M.A.R.T samples to evaluate the trend of the attributes
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=64
+	// +optional
+	SampleWindow int `json:"sampleWindow,omitempty"`
+
+	// Thresholds of the S.M.A.R.T attributes
+	// +optional
+	Thresholds DiskHealthThresholds `json:"thresholds,omitempty"`
+
+	// Reaction on the unhealthy disks in the LocalStorage pools
+	// +optional
+	Reaction DiskHealthReaction `json:"reaction,omitempty"`
+}
+
+// DiskHealthPolicyStatus defines the observed state of DiskHealthPolicy
+type DiskHealthPolicyStatus struct {
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DiskHealthPolicy defines how the health of the disks is evaluated from the S.M.A.R.T attributes,
+// and how the unhealthy disks are handled
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=diskhealthpolicies,scope=Cluster,shortName=dhp
+// +kubebuilder:printcolumn:name="window",type=integer,JSONPath=`.spec.sampleWindow`,description="Number of the samples evaluated"
+// +kubebuilder:printcolumn:name="cordonOn",type=string,JSONPath=`.spec.reaction.cordonOn`,description="Health state to cordon the disk"
+// +kubebuilder:printcolumn:name="evacuateOn",type=string,JSONPath=`.spec.reaction.evacuateOn`,description="Health state to evacuate the disk"
+// +kubebuilder:printcolumn:name="age",type=date,JSONPath=`.metadata.creationTimestamp`
+type DiskHealthPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DiskHealthPolicySpec   `json:"spec,omitempty"`
+	Status DiskHealthPolicyStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DiskHealthPolicyList contains a list of DiskHealthPolicy
+type DiskHealthPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DiskHealthPolicy `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&DiskHealthPolicy{}, &DiskHealthPolicyList{})
+}
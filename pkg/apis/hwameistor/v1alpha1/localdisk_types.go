@@ -47,6 +47,59 @@ type PartitionInfo struct {
 type RAIDInfo struct {
 	// RAIDMaster is the master of the RAID disk, it works for only RAID slave disk, e.g. /dev/bus/0
 	RAIDMaster string `json:"raidMaster,omitempty"`
+
+	// ControllerModel is the model of the RAID controller, e.g. AVAGO MegaRAID SAS 9361-8i
+	ControllerModel string `json:"controllerModel,omitempty"`
+
+	// VirtualDrive is the virtual drive on the RAID controller seen as the disk, e.g. /c0/v0
+	VirtualDrive string `json:"virtualDrive,omitempty"`
+
+	// Level is the RAID level of the virtual drive, e.g. RAID1
+	Level string `json:"level,omitempty"`
+
+	// State is the state of the virtual drive
+	// +kubebuilder:validation:Enum:=Optimal;Degraded;PartiallyDegraded;Rebuilding;Offline;Unknown
+	State RAIDState `json:"state,omitempty"`
+
+	// CachePolicy is the cache policy of the virtual drive reported by the controller,
+	// e.g. RWBD is ReadAhead, WriteBack and Direct IO
+	CachePolicy string `json:"cachePolicy,omitempty"`
+
+	// BBUState is the state of the battery backup unit or the cache vault of the controller, e.g. Optimal
+	BBUState string `json:"bbuState,omitempty"`
+
+	// Members are the physical drives of the virtual drive
+	Members []RAIDMember `json:"members,omitempty"`
+}
+
+// RAIDMember is a physical drive of the RAID virtual drive
+type RAIDMember struct {
+	// EnclosureSlot locates the drive on the controller, e.g. 252:0
+	EnclosureSlot string `json:"enclosureSlot"`
+
+	// DeviceID is the ID of the drive on the controller
+	DeviceID int `json:"deviceID"`
+
+	// State is the state of the drive reported by the controller, e.g. Onln, Rbld, Offln, Failed
+	State string `json:"state,omitempty"`
+
+	// MediaType such as HDD, SSD
+	MediaType string `json:"mediaType,omitempty"`
+
+	// Size of the drive reported by the controller, e.g. 446.625 GB
+	Size string `json:"size,omitempty"`
+
+	// ModelName is the model of the drive
+	ModelName string `json:"modelName,omitempty"`
+
+	// SerialNumber is the serial number of the drive
+	SerialNumber string `json:"serialNumber,omitempty"`
+
+	// SmartDevice is the device type used by smartctl to reach the drive behind the controller, e.g. megaraid,8
+	SmartDevice string `json:"smartDevice,omitempty"`
+
+	// OverallHealth is the S.M.A.R.T self-assessment of the drive
+	OverallHealth SmartAssessResult `json:"overallHealth,omitempty"`
 }
 
 // DiskAttributes represent certain hardware/static attributes of the disk
@@ -154,6 +207,10 @@ const (
 	// 2) used by a LocalDiskClaim object
 	// 3) there is already a filesystem or partition exist
 	LocalDiskBound LocalDiskState = "Bound"
+
+	// LocalDiskSanitizing represents the data on the disk is being removed after the volume on it is deleted.
+	// The disk is not available until the sanitization succeeds
+	LocalDiskSanitizing LocalDiskState = "Sanitizing"
 )
 
 // SmartAssessResult defines the result of self-assessment test
@@ -167,6 +224,122 @@ const (
 	AssessFailed SmartAssessResult = "Failed"
 )
 
+// RAIDState defines the state of a RAID virtual drive
+type RAIDState string
+
+const (
+	// RAIDOptimal means all the members of the virtual drive are online
+	RAIDOptimal RAIDState = "Optimal"
+
+	// RAIDDegraded means the virtual drive lost its redundancy
+	RAIDDegraded RAIDState = "Degraded"
+
+	// RAIDPartiallyDegraded means the virtual drive lost part of its redundancy, e.g. one drive of RAID6
+	RAIDPartiallyDegraded RAIDState = "PartiallyDegraded"
+
+	// RAIDRebuilding means the data is being rebuilt on a member of the virtual drive
+	RAIDRebuilding RAIDState = "Rebuilding"
+
+	// RAIDOffline means the virtual drive is not accessible
+	RAIDOffline RAIDState = "Offline"
+
+	// RAIDUnknown means the state reported by the controller is not recognized
+	RAIDUnknown RAIDState = "Unknown"
+)
+
+// LocalDiskConditionRAIDDegraded is the condition of the LocalDisk which is a RAID virtual drive.
+// It's true when the virtual drive is not optimal, and the reason is the RAIDState
+const LocalDiskConditionRAIDDegraded = "RAIDDegraded"
+
+// DiskHealthState is the health of the disk evaluated from the S.M.A.R.T attributes over time
+type DiskHealthState string
+
+const (
+	// DiskHealthy indicates no S.M.A.R.T attribute reaches the thresholds
+	DiskHealthy DiskHealthState = "Healthy"
+
+	// DiskDegraded indicates the disk is wearing out or has errors, and is likely to fail
+	DiskDegraded DiskHealthState = "Degraded"
+
+	// DiskFailing indicates the disk fails the self-assessment or has too many errors
+	DiskFailing DiskHealthState = "Failing"
+
+	// DiskHealthNever is used by DiskHealthReaction to disable the reaction
+	DiskHealthNever DiskHealthState = "Never"
+)
+
+// Reaches returns true if the state is as bad as or worse than the threshold, Degraded or Failing
+func (s DiskHealthState) Reaches(threshold DiskHealthState) bool {
+	severity := func(state DiskHealthState) int {
+		switch state {
+		case DiskDegraded:
+			return 1
+		case DiskFailing:
+			return 2
+		default:
+			return 0
+		}
+	}
+	if threshold != DiskDegraded && threshold != DiskFailing {
+		return false
+	}
+	return severity(s) >= severity(threshold)
+}
+
+// DiskHealthSample contains the key S.M.A.R.T attributes collected at a time
+type DiskHealthSample struct {
+	// Time when the attributes are collected
+	Time metav1.Time `json:"time"`
+
+	// OverallHealthPassed is the result of the S.M.A.R.T self-assessment
+	OverallHealthPassed bool `json:"overallHealthPassed"`
+
+	// ReallocatedSectors is the count of the reallocated sectors
+	ReallocatedSectors int64 `json:"reallocatedSectors,omitempty"`
+
+	// PendingSectors is the count of the sectors waiting to be reallocated
+	PendingSectors int64 `json:"pendingSectors,omitempty"`
+
+	// MediaErrors is the count of the unrecovered data integrity errors
+	MediaErrors int64 `json:"mediaErrors,omitempty"`
+
+	// PercentageUsed is the percentage of the life used
+	PercentageUsed int64 `json:"percentageUsed,omitempty"`
+
+	// CRCErrors is the count of the CRC errors in the interface transfers
+	CRCErrors int64 `json:"crcErrors,omitempty"`
+
+	// Temperature is the temperature in Celsius
+	Temperature int64 `json:"temperature,omitempty"`
+}
+
+// DiskHealthStatus is the health of the disk evaluated by the DiskHealthPolicy
+type DiskHealthStatus struct {
+	// State is the health state of the disk
+	// +kubebuilder:validation:Enum:=Healthy;Degraded;Failing
+	State DiskHealthState `json:"state"`
+
+	// Reasons why the disk is not healthy
+	// +optional
+	Reasons []string `json:"reasons,omitempty"`
+
+	// LastTransitionTime is the time when the state changed
+	// +optional
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+
+	// Samples are the recent S.M.A.R.T samples in the window of the policy, the latest is the last
+	// +optional
+	Samples []DiskHealthSample `json:"samples,omitempty"`
+
+	// Cordoned represents the disk is marked non-allocatable in the LocalStorage pool
+	// +optional
+	Cordoned bool `json:"cordoned,omitempty"`
+
+	// Migrations are the LocalVolumeMigrates created to move the volumes out of the disk
+	// +optional
+	Migrations []string `json:"migrations,omitempty"`
+}
+
 // LocalDiskSpec defines the desired state of LocalDisk
 type LocalDiskSpec struct {
 	// NodeName represents the node where the disk is attached
@@ -223,6 +396,12 @@ type LocalDiskSpec struct {
 	// +optional
 	ClaimRef *v1.ObjectReference `json:"claimRef,omitempty"`
 
+	// SanitizePolicy is how the disk is sanitized when the volume on it is deleted,
+	// it's copied from the LDC which has claimed this LD
+	// +kubebuilder:validation:Enum:=none;signatures;zero;blkdiscard;nvme-format;nvme-sanitize;ata-secure-erase
+	// +optional
+	SanitizePolicy DiskSanitizePolicy `json:"sanitizePolicy,omitempty"`
+
 	// Reserved represents the disk won't be used in hwameistor later, until it becomes unreserved
 	// +optional
 	Reserved bool `json:"reserved,omitempty"`
@@ -243,8 +422,16 @@ type LocalDiskSpec struct {
 // LocalDiskStatus defines the observed state of LocalDisk
 type LocalDiskStatus struct {
 	// State represents the claim state of the disk
-	// +kubebuilder:validation:Enum:=Bound;Reserved;Available;Pending
+	// +kubebuilder:validation:Enum:=Bound;Reserved;Available;Pending;Sanitizing
 	State LocalDiskState `json:"claimState,omitempty"`
+
+	// Health is evaluated from the S.M.A.R.T attributes collected periodically
+	// +optional
+	Health *DiskHealthStatus `json:"health,omitempty"`
+
+	// Conditions of the disk, e.g. RAIDDegraded
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 // +genclient
@@ -262,6 +449,8 @@ type LocalDiskStatus struct {
 // +kubebuilder:printcolumn:JSONPath=".spec.owner",name=Owner,type=string
 // +kubebuilder:printcolumn:JSONPath=".status.claimState",name=Phase,type=string
 // +kubebuilder:printcolumn:JSONPath=".spec.smartInfo.overallHealth",name=Health,type=string,priority=1
+// +kubebuilder:printcolumn:JSONPath=".status.health.state",name=HealthState,type=string,priority=1
+// +kubebuilder:printcolumn:JSONPath=".spec.raidInfo.state",name=RAIDState,type=string,priority=1
 // +kubebuilder:printcolumn:JSONPath=".spec.reserved",name=Reserved,type=boolean,priority=1
 // +kubebuilder:printcolumn:JSONPath=".spec.state",name=State,type=string
 // +kubebuilder:printcolumn:name="age",type=date,JSONPath=`.metadata.creationTimestamp`
@@ -25,6 +25,12 @@ type LocalDiskClaimSpec struct {
 	// Owner represents which system owns this claim(e.g. local-storage, local-disk-manager)
 	// +kubebuilder:validation:Required
 	Owner string `json:"owner"`
+
+	// SanitizePolicy is how the claimed disks are sanitized when the disk volumes on them are deleted,
+	// unless it's specified by the StorageClass of the volume
+	// +kubebuilder:validation:Enum:=none;signatures;zero;blkdiscard;nvme-format;nvme-sanitize;ata-secure-erase
+	// +optional
+	SanitizePolicy DiskSanitizePolicy `json:"sanitizePolicy,omitempty"`
 }
 
 type LocalDiskClaimSpecArray []LocalDiskClaimSpec
@@ -0,0 +1,79 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// LocalDiskDecommissionSpec defines the desired state of LocalDiskDecommission
+type LocalDiskDecommissionSpec struct {
+	// NodeName is the node where the disk is attached
+	// +kubebuilder:validation:Required
+	NodeName string `json:"nodeName"`
+
+	// DiskName is the name of the LocalDisk to remove from the storage pool
+	// +kubebuilder:validation:Required
+	DiskName string `json:"diskName"`
+
+	// Replace marks the disk as reserved for replacement after it's removed from the pool,
+	// otherwise the disk is released as Available
+	// +kubebuilder:default:=false
+	Replace bool `json:"replace,omitempty"`
+
+	// Abort can be used to abort the decommission before the disk is removed from the pool
+	// +kubebuilder:default:=false
+	Abort bool `json:"abort,omitempty"`
+}
+
+// LocalDiskDecommissionStatus defines the observed state of LocalDiskDecommission
+type LocalDiskDecommissionStatus struct {
+	// PoolName is the storage pool which the disk belongs to
+	PoolName string `json:"poolName,omitempty"`
+
+	// DevicePath is the device path of the disk
+	DevicePath string `json:"devicePath,omitempty"`
+
+	// Migrations are the LocalVolumeMigrates created to move the volumes out of the disk
+	// when the data can't be moved to the other disks in the pool
+	Migrations []string `json:"migrations,omitempty"`
+
+	// State is the phase of the decommission, e.g. Submitted, Evacuate, Migrate, Remove, Completed, Failed, Aborted
+	State State `json:"state,omitempty"`
+
+	// Message error message to describe some states
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalDiskDecommission is a user's request to move the data out of a disk and remove it from the storage pool
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=localdiskdecommissions,scope=Cluster,shortName=lddecom
+// +kubebuilder:printcolumn:name="node",type=string,JSONPath=`.spec.nodeName`,description="Node of the disk"
+// +kubebuilder:printcolumn:name="disk",type=string,JSONPath=`.spec.diskName`,description="Name of the disk"
+// +kubebuilder:printcolumn:name="pool",type=string,JSONPath=`.status.poolName`,description="Pool of the disk"
+// +kubebuilder:printcolumn:name="state",type=string,JSONPath=`.status.state`,description="State of the decommission"
+// +kubebuilder:printcolumn:name="age",type=date,JSONPath=`.metadata.creationTimestamp`
+type LocalDiskDecommission struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocalDiskDecommissionSpec   `json:"spec,omitempty"`
+	Status LocalDiskDecommissionStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalDiskDecommissionList contains a list of LocalDiskDecommission
+type LocalDiskDecommissionList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalDiskDecommission `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LocalDiskDecommission{}, &LocalDiskDecommissionList{})
+}
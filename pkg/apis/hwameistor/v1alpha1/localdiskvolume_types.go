@@ -34,6 +34,101 @@ type LocalDiskVolumeSpec struct {
 	// CanWipe represents if disk can wipe after Volume is deleted
 	// If disk has been writen data, this is will be changed to true
 	CanWipe bool `json:"canWipe,omitempty"`
+
+	// SanitizePolicy is how the disk is sanitized after the volume is deleted.
+	// The policy of the LocalDiskClaim is used if not set, and signatures by default
+	// +kubebuilder:validation:Enum:=none;signatures;zero;blkdiscard;nvme-format;nvme-sanitize;ata-secure-erase
+	SanitizePolicy DiskSanitizePolicy `json:"sanitizePolicy,omitempty"`
+
+	// AllocationMode represents whether the volume takes the whole disk, or a partition of the disk shared with other volumes
+	// +kubebuilder:validation:Enum:=disk;partition
+	// +kubebuilder:default:=disk
+	AllocationMode DiskAllocationMode `json:"allocationMode,omitempty"`
+}
+
+// DiskAllocationMode defines how the disk is allocated to the volume
+type DiskAllocationMode string
+
+const (
+	// DiskAllocationModeDisk allocates the whole disk to the volume
+	DiskAllocationModeDisk DiskAllocationMode = "disk"
+
+	// DiskAllocationModePartition allocates a GPT partition on the disk to the volume
+	DiskAllocationModePartition DiskAllocationMode = "partition"
+)
+
+// DiskPartition is the GPT partition allocated to the volume
+type DiskPartition struct {
+	// Number is the number of the partition on the disk, it's set after the partition is created
+	Number int `json:"number,omitempty"`
+
+	// StartBytes is the offset of the partition on the disk
+	StartBytes int64 `json:"startBytes"`
+
+	// SizeBytes is the size of the partition
+	SizeBytes int64 `json:"sizeBytes"`
+
+	// DevPath is the partition path in the OS, e.g. /dev/sdb1
+	DevPath string `json:"devPath,omitempty"`
+}
+
+// DiskSanitizePolicy defines how the data on the disk is removed when the disk is released
+type DiskSanitizePolicy string
+
+const (
+	// DiskSanitizePolicyNone keeps the data on the disk
+	DiskSanitizePolicyNone DiskSanitizePolicy = "none"
+
+	// DiskSanitizePolicySignatures removes the filesystem and partition signatures by wipefs
+	DiskSanitizePolicySignatures DiskSanitizePolicy = "signatures"
+
+	// DiskSanitizePolicyZero overwrites the whole disk with zeros
+	DiskSanitizePolicyZero DiskSanitizePolicy = "zero"
+
+	// DiskSanitizePolicyBlkDiscard discards all the blocks of the disk by blkdiscard
+	DiskSanitizePolicyBlkDiscard DiskSanitizePolicy = "blkdiscard"
+
+	// DiskSanitizePolicyNVMeFormat formats the NVMe namespace with the user data erase
+	DiskSanitizePolicyNVMeFormat DiskSanitizePolicy = "nvme-format"
+
+	// DiskSanitizePolicyNVMeSanitize sanitizes the NVMe disk by the block erase
+	DiskSanitizePolicyNVMeSanitize DiskSanitizePolicy = "nvme-sanitize"
+
+	// DiskSanitizePolicyATASecureErase erases the ATA disk by the security erase unit command
+	DiskSanitizePolicyATASecureErase DiskSanitizePolicy = "ata-secure-erase"
+)
+
+// DiskSanitizeStatus is the status of the disk sanitization
+type DiskSanitizeStatus struct {
+	// Policy is the sanitize policy used
+	Policy DiskSanitizePolicy `json:"policy,omitempty"`
+
+	// State is the state of the sanitization, e.g. InProgress, Completed, Failed
+	State State `json:"state,omitempty"`
+
+	// Progress is the percentage of the sanitization
+	Progress int64 `json:"progress,omitempty"`
+
+	// StartTime is the time when the sanitization starts
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time when the sanitization completes or fails
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Message is the error message of the failed sanitization
+	Message string `json:"message,omitempty"`
+}
+
+// DiskVolumeExpansionStatus is the status of the volume expansion
+type DiskVolumeExpansionStatus struct {
+	// RequiredCapacityBytes is the capacity the volume is expanded to
+	RequiredCapacityBytes int64 `json:"requiredCapacityBytes"`
+
+	// State is the state of the expansion, e.g. InProgress, Completed, Failed
+	State State `json:"state,omitempty"`
+
+	// Message is the reason why the expansion fails
+	Message string `json:"message,omitempty"`
 }
 
 // MountPoint
@@ -99,6 +194,15 @@ type LocalDiskVolumeStatus struct {
 
 	// State is the phase of volume replica, e.g. Creating, Ready, NotReady, ToBeDeleted, Deleted
 	State State `json:"state,omitempty"`
+
+	// Sanitize is the status of the disk sanitization after the volume is deleted
+	Sanitize *DiskSanitizeStatus `json:"sanitize,omitempty"`
+
+	// Partition is the partition of the disk allocated to the volume, only for the partition volume
+	Partition *DiskPartition `json:"partition,omitempty"`
+
+	// Expansion is the status of the latest expansion of the volume
+	Expansion *DiskVolumeExpansionStatus `json:"expansion,omitempty"`
 }
 
 // +genclient
@@ -33,6 +33,11 @@ const (
 	StorageExpandFailure StorageNodeConditionType = "ExpandFailure"
 	// StorageExpandSuccess is added in a storagenode when a disk succeeds to be joined the storage pool
 	StorageExpandSuccess StorageNodeConditionType = "ExpandSuccess"
+	// StorageThinPoolExtendSuccess is added in a storagenode when a thin pool is extended automatically
+	StorageThinPoolExtendSuccess StorageNodeConditionType = "ThinPoolExtendSuccess"
+	// StorageThinPoolExtendFailure is added in a storagenode when a thin pool reaches the threshold but can't be
+	// extended automatically, e.g. no free space in the pool. No more thin volume is allocated on the node
+	StorageThinPoolExtendFailure StorageNodeConditionType = "ThinPoolExtendFailure"
 )
 
 const (
@@ -130,6 +135,10 @@ type LocalPool struct {
 	// VG path
 	Path string `json:"path,omitempty"`
 
+	// Backend is the storage backend of the pool, e.g. LVM, ZFS
+	// +kubebuilder:validation:Enum:=LVM;ZFS
+	Backend string `json:"backend,omitempty"`
+
 	TotalCapacityBytes int64 `json:"totalCapacityBytes"`
 
 	UsedCapacityBytes int64 `json:"usedCapacityBytes"`
@@ -164,6 +173,21 @@ type LocalDevice struct {
 
 	// Possible state: Available, Inuse, Offline
 	State State `json:"state,omitempty"`
+
+	// Partitioned represents the disk is partitioned and shared by the partition volumes
+	Partitioned bool `json:"partitioned,omitempty"`
+
+	// FreeExtents is the free space of the partitioned disk to create partition volumes
+	FreeExtents []DiskExtent `json:"freeExtents,omitempty"`
+}
+
+// DiskExtent is a contiguous space on the disk
+type DiskExtent struct {
+	// StartBytes is the offset of the extent on the disk
+	StartBytes int64 `json:"startBytes"`
+
+	// SizeBytes is the size of the extent
+	SizeBytes int64 `json:"sizeBytes"`
 }
 
 type ThinPoolInfo struct {
@@ -177,6 +201,9 @@ type ThinPoolInfo struct {
 	OverProvisionRatio    string           `json:"overProvisionRatio,omitempty"`
 	ThinVolumes           []string         `json:"thinVolumes,omitempty"`
 	State                 metav1.Condition `json:"state,omitempty"`
+	// ExtendFailure is the reason why the thin pool can't be extended by the autoextend policy,
+	// no more thin volume is allocated in the pool until it's cleared
+	ExtendFailure string `json:"extendFailure,omitempty"`
 }
 
 type ThinPoolExtendRecordArray []ThinPoolExtendRecord
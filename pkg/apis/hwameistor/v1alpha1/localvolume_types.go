@@ -35,7 +35,7 @@ type LocalVolumeSpec struct {
 	// PoolName is the name of the storage pool, e.g. LocalStorage_PoolHDD, LocalStorage_PoolSSD, etc..
 	PoolName string `json:"poolName,omitempty"`
 
-	// replica number: 1 - non-HA, 2 - HA, 4 - migration (temp)
+	// replica number: 1 - non-HA, 2/3 - HA, one more replica during migration (temp)
 	// +kubebuilder:validation:Minimum:=1
 	// +kubebuilder:validation:Maximum:=4
 	ReplicaNumber int64 `json:"replicaNumber,omitempty"`
@@ -67,6 +67,18 @@ type LocalVolumeSpec struct {
 	// ThinOrigin is the origin info of a thin volume
 	ThinOrigin *ThinOrigin `json:"thinOrigin,omitempty"`
 
+	// Mirror is to indicate if the volume replica is a LVM raid1 volume mirrored across the disks of the pool
+	Mirror bool `json:"mirror,omitempty"`
+
+	// StorageBackend is the storage backend required by the volume, e.g. LVM, ZFS.
+	// The volume can be placed on any pool if it's empty
+	// +kubebuilder:validation:Enum:=LVM;ZFS
+	StorageBackend string `json:"storageBackend,omitempty"`
+
+	// VolumeCache is the cache carved from a faster pool on the same node to accelerate the volume,
+	// e.g. a NVMe cache for the HDD volume. It's only for the non-HA LVM volume
+	VolumeCache *VolumeCache `json:"volumeCache,omitempty"`
+
 	// Delete is to indicate where the replica should be deleted or not.
 	// It's different from the regular resource delete interface in Kubernetes.
 	// The purpose is to protect it from any mistakes
@@ -80,6 +92,102 @@ type VolumeQoS struct {
 	Throughput string `json:"throughput,omitempty"`
 	// IOPS defines the IOPS of the volume
 	IOPS string `json:"iops,omitempty"`
+
+	// BurstThroughput is the throughput the volume can burst to for BurstDuration, e.g. 200Mi
+	BurstThroughput string `json:"burstThroughput,omitempty"`
+	// BurstIOPS is the IOPS the volume can burst to for BurstDuration, e.g. 3000
+	BurstIOPS string `json:"burstIOPS,omitempty"`
+	// BurstDuration is how long the volume can keep bursting above Throughput and IOPS, e.g. 60s.
+	// The burst credit is refilled while the volume runs below them
+	BurstDuration string `json:"burstDuration,omitempty"`
+
+	// Weight is the proportional share of the disk with the cgroup v2 io.weight of the pod, from 1 to 10000
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=10000
+	Weight int64 `json:"weight,omitempty"`
+	// LatencyTarget is the io latency target of the disk with the cgroup v2 io.latency of the pod, e.g. 10ms
+	LatencyTarget string `json:"latencyTarget,omitempty"`
+}
+
+// VolumeCache is the cache tier of the volume
+type VolumeCache struct {
+	// PoolName is the pool where the cache is carved from, e.g. LocalStorage_PoolNVMe, LocalStorage_PoolSSD
+	// +kubebuilder:validation:Enum:=LocalStorage_PoolSSD;LocalStorage_PoolNVMe
+	PoolName string `json:"poolName"`
+
+	// CapacityBytes is the capacity of the cache. It can be increased, and the cache is detached,
+	// resized and attached again online
+	// +kubebuilder:validation:Minimum:=4194304
+	CapacityBytes int64 `json:"capacityBytes"`
+
+	// Mode is the cache mode, writethrough with dm-cache, or writeback with dm-writecache
+	// +kubebuilder:validation:Enum:=writethrough;writeback
+	// +kubebuilder:default:=writethrough
+	Mode string `json:"mode,omitempty"`
+}
+
+// VolumeCacheStatus is the state and the statistics of the cache attached to the volume replica
+type VolumeCacheStatus struct {
+	// PoolName is the pool where the cache is carved from
+	PoolName string `json:"poolName,omitempty"`
+
+	// Mode is the cache mode in use
+	Mode string `json:"mode,omitempty"`
+
+	// AllocatedCapacityBytes is the capacity allocated for the cache
+	AllocatedCapacityBytes int64 `json:"allocatedCapacityBytes,omitempty"`
+
+	// BlockSizeBytes is the size of the cache block
+	BlockSizeBytes int64 `json:"blockSizeBytes,omitempty"`
+
+	// TotalBlocks is the number of the cache blocks
+	TotalBlocks int64 `json:"totalBlocks,omitempty"`
+
+	// UsedBlocks is the number of the cache blocks in use
+	UsedBlocks int64 `json:"usedBlocks,omitempty"`
+
+	// DirtyBlocks is the number of the cache blocks not written back to the volume yet
+	DirtyBlocks int64 `json:"dirtyBlocks,omitempty"`
+
+	// ReadHits is the number of the reads served by the cache
+	ReadHits int64 `json:"readHits,omitempty"`
+
+	// ReadMisses is the number of the reads served by the volume
+	ReadMisses int64 `json:"readMisses,omitempty"`
+
+	// WriteHits is the number of the writes to the blocks in the cache
+	WriteHits int64 `json:"writeHits,omitempty"`
+
+	// WriteMisses is the number of the writes to the blocks not in the cache
+	WriteMisses int64 `json:"writeMisses,omitempty"`
+
+	// LastUpdateTime is the time when the statistics are collected
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
+// VolumeQoSStatus is the QoS applied on the volume
+type VolumeQoSStatus struct {
+	// Throughput is the throughput limit applied on the volume, 0 for no limit
+	Throughput int64 `json:"throughput,omitempty"`
+	// IOPS is the IOPS limit applied on the volume, 0 for no limit
+	IOPS int64 `json:"iops,omitempty"`
+
+	// Bursting is to indicate if the burst limits are applied
+	Bursting bool `json:"bursting,omitempty"`
+
+	// GroupShared is to indicate if the limits are narrowed by the budget of the LocalVolumeGroup
+	GroupShared bool `json:"groupShared,omitempty"`
+
+	// Weight is the io.weight applied for the pods using the volume
+	Weight int64 `json:"weight,omitempty"`
+	// LatencyTarget is the io.latency target applied for the pods using the volume
+	LatencyTarget string `json:"latencyTarget,omitempty"`
+
+	// Message describes the QoS failed to apply, e.g. io.weight is not supported by cgroup v1
+	Message string `json:"message,omitempty"`
+
+	// LastUpdateTime is the time when the QoS is applied
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
 }
 
 type VolumeEncrypt struct {
@@ -95,6 +203,37 @@ type VolumeEncrypt struct {
 	// Secret is the key for encryption
 	// Don't set secret directly, fetch it from apiserver by name
 	SecretNamespacedName string `json:"secret,omitempty"`
+
+	// KeyProvider is where the key for encryption is held, e.g., Secret, KMS
+	// +kubebuilder:default=Secret
+	// +kubebuilder:validation:Enum=Secret;KMS
+	KeyProvider string `json:"keyProvider,omitempty"`
+
+	// KMS is the config of the KMS which holds the key when KeyProvider is KMS
+	KMS *VolumeEncryptKMS `json:"kms,omitempty"`
+}
+
+const (
+	// VolumeEncryptKeyProviderSecret keeps the key in a Kubernetes Secret
+	VolumeEncryptKeyProviderSecret = "Secret"
+
+	// VolumeEncryptKeyProviderKMS keeps the key encrypted by a KMS with the Vault transit API
+	VolumeEncryptKeyProviderKMS = "KMS"
+)
+
+// VolumeEncryptKMS is the config of the KMS key provider
+type VolumeEncryptKMS struct {
+	// Address is the URL of the KMS, e.g., https://vault.example.com:8200
+	Address string `json:"address"`
+
+	// KeyName is the name of the key in the KMS to encrypt the data key of the volume
+	KeyName string `json:"keyName"`
+
+	// TokenSecret is the namespaced name of the Secret with the token to access the KMS, e.g., hwameistor/kms-token
+	TokenSecret string `json:"tokenSecret,omitempty"`
+
+	// WrappedKey is the data key of the volume encrypted by the KMS. The plain key is never saved
+	WrappedKey string `json:"wrappedKey,omitempty"`
 }
 
 // AccessibilityTopology of the volume
@@ -184,6 +323,9 @@ type VolumeConfig struct {
 	ReadyToInitialize bool            `json:"readyToInitialize"`
 	Initialized       bool            `json:"initialized"`
 	Replicas          []VolumeReplica `json:"replicas"`
+
+	// ResyncBandwidthLimit caps the bandwidth to resync the replicas in bytes per second, e.g. during the migration
+	ResyncBandwidthLimit int64 `json:"resyncBandwidthLimit,omitempty"`
 }
 
 // DeepEqual check if the two configs are equal completely or not
@@ -203,6 +345,9 @@ func (vc *VolumeConfig) DeepEqual(peer *VolumeConfig) bool {
 	if vc.Convertible != peer.Convertible {
 		return false
 	}
+	if vc.ResyncBandwidthLimit != peer.ResyncBandwidthLimit {
+		return false
+	}
 	if len(vc.Replicas) != len(peer.Replicas) {
 		return false
 	}
@@ -293,6 +438,10 @@ type LocalVolumeStatus struct {
 	// PublishedRawBlock is for raw block
 	// +kubebuilder:default:=false
 	PublishedRawBlock bool `json:"rawblock"`
+
+	// VolumeQoS is the QoS applied on the replica in use
+	VolumeQoS *VolumeQoSStatus `json:"volumeQoS,omitempty"`
+
 	// Synced is the sync state of the volume replica, which is important in HA volume
 	// +kubebuilder:default:=false
 	//Synced bool `json:"synced,omitempty"`
@@ -0,0 +1,146 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+const (
+	// BackupDefaultChunkSizeBytes is the default size of the chunk which the volume data is split into, 4MiB
+	BackupDefaultChunkSizeBytes int64 = 4 * 1024 * 1024
+
+	// SourceVolumeBackupAnnoKey is set on the LocalVolume restored from a backup
+	SourceVolumeBackupAnnoKey = "hwameistor.io/source-backup"
+
+	// VolumeBackupCleanupFinalizer deletes the manifest and the unshared chunks of the backup from the target
+	VolumeBackupCleanupFinalizer = "hwameistor.io/backup-cleanup"
+)
+
+// BackupTarget describes the S3-compatible object store where the backup data is stored
+type BackupTarget struct {
+	// Endpoint is the URL of the S3-compatible service, e.g. http://minio.minio-system:9000
+	// +kubebuilder:validation:Required
+	Endpoint string `json:"endpoint"`
+
+	// Bucket is the name of the bucket to store the backup data
+	// +kubebuilder:validation:Required
+	Bucket string `json:"bucket"`
+
+	// Prefix is the key prefix of all the objects in the bucket
+	Prefix string `json:"prefix,omitempty"`
+
+	// Region of the bucket
+	// +kubebuilder:default:=us-east-1
+	Region string `json:"region,omitempty"`
+
+	// CredentialSecret is the namespaced name of the secret (e.g. hwameistor/s3-credential)
+	// which contains the keys "accessKeyID" and "secretAccessKey"
+	// +kubebuilder:validation:Required
+	CredentialSecret string `json:"credentialSecret"`
+
+	// InsecureSkipTLSVerify skips the verification of the server's certificate
+	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify,omitempty"`
+}
+
+// LocalVolumeBackupSpec defines the desired state of LocalVolumeBackup
+type LocalVolumeBackupSpec struct {
+	// SourceVolumeSnapshot is the name of the LocalVolumeSnapshot to back up.
+	// The data is read from the snapshot, so the backup is crash-consistent
+	// +kubebuilder:validation:Required
+	SourceVolumeSnapshot string `json:"sourceVolumeSnapshot"`
+
+	// Target is the object store where the backup is uploaded to
+	// +kubebuilder:validation:Required
+	Target BackupTarget `json:"target"`
+
+	// ChunkSizeBytes is the size of the chunk which the volume data is split into. Chunks are compressed and
+	// addressed by the hash of their content, so the unchanged chunks are uploaded only once
+	// +kubebuilder:default:=4194304
+	// +kubebuilder:validation:Minimum:=65536
+	ChunkSizeBytes int64 `json:"chunkSizeBytes,omitempty"`
+
+	// Full indicates to make a full backup, rather than an incremental one against the latest backup of the same volume
+	// +kubebuilder:default:=false
+	Full bool `json:"full,omitempty"`
+
+	// Abort can be used to abort the backup operation
+	// +kubebuilder:default:=false
+	Abort bool `json:"abort,omitempty"`
+}
+
+// LocalVolumeBackupStatus defines the observed state of LocalVolumeBackup
+type LocalVolumeBackupStatus struct {
+	// SourceVolume is the volume which the snapshot is taken from
+	SourceVolume string `json:"sourceVolume,omitempty"`
+
+	// PoolName is the storage pool of the source volume
+	PoolName string `json:"poolName,omitempty"`
+
+	// NodeName is the node where the backup is executed
+	NodeName string `json:"nodeName,omitempty"`
+
+	// ParentBackup is the backup which this incremental backup is based on, empty for a full backup
+	ParentBackup string `json:"parentBackup,omitempty"`
+
+	// ManifestKey is the object key of the backup manifest in the bucket
+	ManifestKey string `json:"manifestKey,omitempty"`
+
+	// VolumeCapacityBytes is the capacity of the volume in the backup
+	VolumeCapacityBytes int64 `json:"volumeCapacityBytes,omitempty"`
+
+	// TotalChunks is the number of the chunks in the backup
+	TotalChunks int64 `json:"totalChunks,omitempty"`
+
+	// UploadedChunks is the number of the chunks uploaded by this backup, the others are reused from the existing backups
+	UploadedChunks int64 `json:"uploadedChunks,omitempty"`
+
+	// UploadedBytes is the compressed size of the uploaded chunks
+	UploadedBytes int64 `json:"uploadedBytes,omitempty"`
+
+	// StartTime is the time when the data transfer starts
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time when the backup completes
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// State is the phase of the backup, e.g. Submitted, InProgress, Completed, Failed, Aborted
+	State State `json:"state,omitempty"`
+
+	// Message error message to describe some states
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeBackup is a user's request to upload a LocalVolumeSnapshot to an S3-compatible object store
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=localvolumebackups,scope=Cluster,shortName=lvbackup
+// +kubebuilder:printcolumn:name="sourcesnapshot",type=string,JSONPath=`.spec.sourceVolumeSnapshot`,description="Source snapshot of the backup"
+// +kubebuilder:printcolumn:name="sourcevolume",type=string,JSONPath=`.status.sourceVolume`,description="Source volume of the backup"
+// +kubebuilder:printcolumn:name="parent",type=string,JSONPath=`.status.parentBackup`,description="Parent of the incremental backup"
+// +kubebuilder:printcolumn:name="uploaded",type=integer,JSONPath=`.status.uploadedBytes`,description="Uploaded bytes"
+// +kubebuilder:printcolumn:name="state",type=string,JSONPath=`.status.state`,description="State of the backup"
+// +kubebuilder:printcolumn:name="age",type=date,JSONPath=`.metadata.creationTimestamp`
+type LocalVolumeBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocalVolumeBackupSpec   `json:"spec,omitempty"`
+	Status LocalVolumeBackupStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeBackupList contains a list of LocalVolumeBackup
+type LocalVolumeBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalVolumeBackup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LocalVolumeBackup{}, &LocalVolumeBackupList{})
+}
@@ -0,0 +1,80 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// LocalVolumeBackupRestoreSpec defines the desired state of LocalVolumeBackupRestore
+type LocalVolumeBackupRestoreSpec struct {
+	// SourceBackup is the name of the LocalVolumeBackup to restore from. Any completed backup can be used,
+	// no matter it's a full or an incremental one
+	// +kubebuilder:validation:Required
+	SourceBackup string `json:"sourceBackup"`
+
+	// TargetVolume is the name of the new LocalVolume to restore to
+	// +kubebuilder:validation:Required
+	TargetVolume string `json:"targetVolume"`
+
+	// TargetPoolName is the storage pool of the new volume. By default, it's the same as the source volume
+	TargetPoolName string `json:"targetPoolName,omitempty"`
+
+	// TargetNodeName is the node where the new volume is placed at. By default, it's selected by the scheduler
+	TargetNodeName string `json:"targetNodeName,omitempty"`
+
+	// Abort can be used to abort the restore operation
+	// +kubebuilder:default:=false
+	Abort bool `json:"abort,omitempty"`
+}
+
+// LocalVolumeBackupRestoreStatus defines the observed state of LocalVolumeBackupRestore
+type LocalVolumeBackupRestoreStatus struct {
+	// NodeName is the node where the data is restored
+	NodeName string `json:"nodeName,omitempty"`
+
+	// RestoredChunks is the number of the chunks written to the new volume
+	RestoredChunks int64 `json:"restoredChunks,omitempty"`
+
+	// CompletionTime is the time when the restore completes
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// State is the phase of the restore, e.g. Submitted, InProgress, Completed, Failed, Aborted
+	State State `json:"state,omitempty"`
+
+	// Message error message to describe some states
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeBackupRestore is a user's request to rebuild a new LocalVolume from a LocalVolumeBackup
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=localvolumebackuprestores,scope=Cluster,shortName=lvbrestore
+// +kubebuilder:printcolumn:name="sourcebackup",type=string,JSONPath=`.spec.sourceBackup`,description="Source backup of the restore"
+// +kubebuilder:printcolumn:name="targetvolume",type=string,JSONPath=`.spec.targetVolume`,description="Target volume of the restore"
+// +kubebuilder:printcolumn:name="node",type=string,JSONPath=`.status.nodeName`,description="Node where the data is restored"
+// +kubebuilder:printcolumn:name="state",type=string,JSONPath=`.status.state`,description="State of the restore"
+// +kubebuilder:printcolumn:name="age",type=date,JSONPath=`.metadata.creationTimestamp`
+type LocalVolumeBackupRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocalVolumeBackupRestoreSpec   `json:"spec,omitempty"`
+	Status LocalVolumeBackupRestoreStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeBackupRestoreList contains a list of LocalVolumeBackupRestore
+type LocalVolumeBackupRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalVolumeBackupRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LocalVolumeBackupRestore{}, &LocalVolumeBackupRestoreList{})
+}
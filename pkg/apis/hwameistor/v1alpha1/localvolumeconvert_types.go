@@ -4,6 +4,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+const (
+	// VolumeReplicationRemovingAnnoKey is set on the volume reduced to 1 replica until its DRBD resource is removed,
+	// only then the node manager re-points the replica from the DRBD device to the LV
+	VolumeReplicationRemovingAnnoKey = "hwameistor.io/replication-removing"
+)
+
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
@@ -17,12 +23,18 @@ type LocalVolumeConvertSpec struct {
 
 	VolumeName string `json:"volumeName,omitempty"`
 
-	// ReplicaNumber is the number of replicas which the volume will be converted to
-	// currently, only support the case of converting a non-HA volume to HA
-	// +kubebuilder:validation:Minimum:=2
-	// +kubebuilder:validation:Maximum:=2
+	// ReplicaNumber is the number of replicas which the volume will be converted to.
+	// Adding replicas, i.e. 1 -> 2, 1 -> 3 and 2 -> 3, or reducing replicas, i.e. 3 -> 2, 3 -> 1 and 2 -> 1.
+	// When reducing to 1 replica, the DRBD resource is removed and the volume becomes inconvertible non-HA volume
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=3
 	ReplicaNumber int64 `json:"replicaNumber,omitempty"`
 
+	// KeepNode is the node of the replica to keep when reducing the replicas.
+	// By default, the replica on the node where the volume is published is kept
+	// +optional
+	KeepNode string `json:"keepNode,omitempty"`
+
 	// *** common section of all the operations ***
 
 	// +kubebuilder:default:=false
@@ -38,6 +50,9 @@ type LocalVolumeConvertStatus struct {
 	State State `json:"state,omitempty"`
 
 	Message string `json:"message,omitempty"`
+
+	// KeptNodes are the nodes of the replicas kept when reducing the replicas
+	KeptNodes []string `json:"keptNodes,omitempty"`
 }
 
 // +genclient
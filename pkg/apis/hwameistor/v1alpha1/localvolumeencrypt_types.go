@@ -0,0 +1,105 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+const (
+	// VolumeEncryptInProgressAnnoKey is set on the LocalVolume with the name of the LocalVolumeEncrypt
+	// until the data is encrypted completely
+	VolumeEncryptInProgressAnnoKey = "hwameistor.io/encrypt-in-progress"
+)
+
+// LocalVolumeEncryptSpec defines the desired state of LocalVolumeEncrypt
+type LocalVolumeEncryptSpec struct {
+	// VolumeName is the name of the unencrypted LocalVolume to encrypt
+	// +kubebuilder:validation:Required
+	VolumeName string `json:"volumeName"`
+
+	// Type is the encryption type, e.g., LUKS
+	// +kubebuilder:default=LUKS
+	// +kubebuilder:validation:Enum=LUKS
+	Type string `json:"type,omitempty"`
+
+	// Secret is the namespaced name of the Secret with the key, e.g., hwameistor/encrypt-secret
+	Secret string `json:"secret,omitempty"`
+
+	// KeyProvider is where the key for encryption is held, e.g., Secret, KMS
+	// +kubebuilder:default=Secret
+	// +kubebuilder:validation:Enum=Secret;KMS
+	KeyProvider string `json:"keyProvider,omitempty"`
+
+	// KMS is the config of the KMS which holds the key when KeyProvider is KMS
+	KMS *VolumeEncryptKMS `json:"kms,omitempty"`
+
+	// EvictPod evicts the pod using the volume, so the pod is restarted with the encrypted volume
+	// +kubebuilder:default:=false
+	EvictPod bool `json:"evictPod,omitempty"`
+
+	// Abort can be used to abort the encryption. The encryption is interrupted safely if it's started already,
+	// and can be resumed by another LocalVolumeEncrypt
+	// +kubebuilder:default:=false
+	Abort bool `json:"abort,omitempty"`
+}
+
+// LocalVolumeEncryptStatus defines the observed state of LocalVolumeEncrypt
+type LocalVolumeEncryptStatus struct {
+	// NodeName is the node of the volume replica to encrypt
+	NodeName string `json:"nodeName,omitempty"`
+
+	// OriginalCapacityBytes is the size of the volume replica before extended for the LUKS header
+	OriginalCapacityBytes int64 `json:"originalCapacityBytes,omitempty"`
+
+	// VolumeEncrypt is the encryption config to set on the volume
+	VolumeEncrypt *VolumeEncrypt `json:"volumeEncrypt,omitempty"`
+
+	// Progress is the percentage of the data encrypted
+	Progress int64 `json:"progress,omitempty"`
+
+	// StartTime is the time when the encryption starts
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time when the encryption completes
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// State is the phase of the encryption, e.g. Submitted, Initialize, Reencrypt, Completed, Failed, Aborted
+	State State `json:"state,omitempty"`
+
+	// Message error message to describe some states
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeEncrypt is a user's request to encrypt an existing LocalVolume online
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=localvolumeencrypts,scope=Cluster,shortName=lvencrypt
+// +kubebuilder:printcolumn:name="volume",type=string,JSONPath=`.spec.volumeName`,description="Name of the volume"
+// +kubebuilder:printcolumn:name="node",type=string,JSONPath=`.status.nodeName`,description="Node of the volume"
+// +kubebuilder:printcolumn:name="progress",type=integer,JSONPath=`.status.progress`,description="Percentage of the data encrypted"
+// +kubebuilder:printcolumn:name="state",type=string,JSONPath=`.status.state`,description="State of the encryption"
+// +kubebuilder:printcolumn:name="age",type=date,JSONPath=`.metadata.creationTimestamp`
+type LocalVolumeEncrypt struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocalVolumeEncryptSpec   `json:"spec,omitempty"`
+	Status LocalVolumeEncryptStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeEncryptList contains a list of LocalVolumeEncrypt
+type LocalVolumeEncryptList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalVolumeEncrypt `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LocalVolumeEncrypt{}, &LocalVolumeEncryptList{})
+}
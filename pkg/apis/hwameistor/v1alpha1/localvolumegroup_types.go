@@ -22,6 +22,10 @@ type LocalVolumeGroupSpec struct {
 	Pods []string `json:"pods,omitempty"`
 
 	Namespace string `json:"namespace,omitempty"`
+
+	// VolumeQoS is the IOPS and throughput budget shared by all the volumes of the pods on a node.
+	// It's divided among the volumes by their usage, and narrows the limits of each volume
+	VolumeQoS *VolumeQoS `json:"volumeQoS,omitempty"`
 }
 
 type VolumeInfo struct {
@@ -0,0 +1,76 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+const (
+	// VolumeGroupSnapshotLabelKey is set on the member LocalVolumeSnapshot of a LocalVolumeGroupSnapshot
+	VolumeGroupSnapshotLabelKey = "hwameistor.io/group-snapshot"
+)
+
+// LocalVolumeGroupSnapshotSpec defines the desired state of LocalVolumeGroupSnapshot
+type LocalVolumeGroupSnapshotSpec struct {
+	// SourceVolumeGroup is the name of the LocalVolumeGroup to take the snapshot of
+	// +kubebuilder:validation:Required
+	SourceVolumeGroup string `json:"sourceVolumeGroup"`
+
+	// Quiesce describes how to quiesce the volumes during the snapshot, the filesystems are frozen by default
+	Quiesce *SnapshotQuiesce `json:"quiesce,omitempty"`
+
+	// Delete the group snapshot and all the member snapshots if it is true
+	// +kubebuilder:default:=false
+	Delete bool `json:"delete,omitempty"`
+}
+
+// LocalVolumeGroupSnapshotStatus defines the observed state of LocalVolumeGroupSnapshot
+type LocalVolumeGroupSnapshotStatus struct {
+	// VolumeSnapshots are the member LocalVolumeSnapshots, one for each volume in the group
+	VolumeSnapshots []string `json:"volumeSnapshots,omitempty"`
+
+	// NodeName is the node where all the member snapshots are taken
+	NodeName string `json:"nodeName,omitempty"`
+
+	// CreationTime is the time when all the member snapshots are ready
+	CreationTime *metav1.Time `json:"creationTime,omitempty"`
+
+	// State is the phase of the group snapshot, e.g. Creating, Ready, NotReady, ToBeDeleted
+	State State `json:"state,omitempty"`
+
+	// Message error message to describe some states
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeGroupSnapshot is a user's request for taking the snapshots of all the volumes in a LocalVolumeGroup at the same instant
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=localvolumegroupsnapshots,scope=Cluster,shortName=lvgsnap
+// +kubebuilder:printcolumn:name="sourcegroup",type=string,JSONPath=`.spec.sourceVolumeGroup`,description="Name of the source volume group"
+// +kubebuilder:printcolumn:name="node",type=string,JSONPath=`.status.nodeName`,description="Node of the snapshots"
+// +kubebuilder:printcolumn:name="state",type=string,JSONPath=`.status.state`,description="State of the group snapshot"
+// +kubebuilder:printcolumn:name="age",type=date,JSONPath=`.metadata.creationTimestamp`
+type LocalVolumeGroupSnapshot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocalVolumeGroupSnapshotSpec   `json:"spec,omitempty"`
+	Status LocalVolumeGroupSnapshotStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeGroupSnapshotList contains a list of LocalVolumeGroupSnapshot
+type LocalVolumeGroupSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalVolumeGroupSnapshot `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LocalVolumeGroupSnapshot{}, &LocalVolumeGroupSnapshotList{})
+}
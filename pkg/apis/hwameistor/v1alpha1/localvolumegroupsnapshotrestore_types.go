@@ -0,0 +1,72 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+const (
+	// VolumeGroupSnapshotRestoreLabelKey is set on the member LocalVolumeSnapshotRestore of a LocalVolumeGroupSnapshotRestore
+	VolumeGroupSnapshotRestoreLabelKey = "hwameistor.io/group-snapshot-restore"
+)
+
+// LocalVolumeGroupSnapshotRestoreSpec defines the desired state of LocalVolumeGroupSnapshotRestore
+type LocalVolumeGroupSnapshotRestoreSpec struct {
+	// SourceGroupSnapshot is the LocalVolumeGroupSnapshot to restore from
+	// +kubebuilder:validation:Required
+	SourceGroupSnapshot string `json:"sourceGroupSnapshot"`
+
+	// RestoreType is the type about how to restore the volumes, e.g., rollback, create.
+	// rollback merges every member snapshot into its source volume, create restores every member snapshot to a new volume
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum:=rollback;create
+	RestoreType RestoreType `json:"restoreType"`
+}
+
+// LocalVolumeGroupSnapshotRestoreStatus defines the observed state of LocalVolumeGroupSnapshotRestore
+type LocalVolumeGroupSnapshotRestoreStatus struct {
+	// VolumeSnapshotRestores are the member LocalVolumeSnapshotRestores, one for each member snapshot
+	VolumeSnapshotRestores []string `json:"volumeSnapshotRestores,omitempty"`
+
+	// TargetVolumes are the volumes restored to, in the same order of VolumeSnapshotRestores
+	TargetVolumes []string `json:"targetVolumes,omitempty"`
+
+	// State is the phase of the group restore, e.g. Submitted, InProgress, Completed, Failed
+	State State `json:"state,omitempty"`
+
+	// Message error message to describe some states
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeGroupSnapshotRestore is a user's request for restoring all the volumes of a LocalVolumeGroupSnapshot together
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=localvolumegroupsnapshotrestores,scope=Cluster,shortName=lvgsrestore;lvgsnaprestore
+// +kubebuilder:printcolumn:name="sourcegroupsnapshot",type=string,JSONPath=`.spec.sourceGroupSnapshot`,description="Source group snapshot for the restore"
+// +kubebuilder:printcolumn:name="type",type=string,JSONPath=`.spec.restoreType`,description="Type of the restore"
+// +kubebuilder:printcolumn:name="state",type=string,JSONPath=`.status.state`,description="State of the restore"
+// +kubebuilder:printcolumn:name="age",type=date,JSONPath=`.metadata.creationTimestamp`
+type LocalVolumeGroupSnapshotRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocalVolumeGroupSnapshotRestoreSpec   `json:"spec,omitempty"`
+	Status LocalVolumeGroupSnapshotRestoreStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeGroupSnapshotRestoreList contains a list of LocalVolumeGroupSnapshotRestore
+type LocalVolumeGroupSnapshotRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalVolumeGroupSnapshotRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LocalVolumeGroupSnapshotRestore{}, &LocalVolumeGroupSnapshotRestoreList{})
+}
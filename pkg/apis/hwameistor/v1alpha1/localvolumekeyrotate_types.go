@@ -0,0 +1,93 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// LocalVolumeKeyRotateSpec defines the desired state of LocalVolumeKeyRotate
+type LocalVolumeKeyRotateSpec struct {
+	// VolumeName is the name of the encrypted LocalVolume to rotate the key
+	// +kubebuilder:validation:Required
+	VolumeName string `json:"volumeName"`
+
+	// NewSecret is the namespaced name of the Secret with the new key, e.g., hwameistor/new-encrypt-secret.
+	// It's required when the key is held by Secret, and a new data key is generated when held by KMS
+	NewSecret string `json:"newSecret,omitempty"`
+
+	// Abort can be used to abort the rotation before the volume switches to the new key
+	// +kubebuilder:default:=false
+	Abort bool `json:"abort,omitempty"`
+}
+
+// VolumeKeyRotateReplicaStatus is the state of the rotation on a volume replica
+type VolumeKeyRotateReplicaStatus struct {
+	// NodeName is the node of the volume replica
+	NodeName string `json:"nodeName"`
+
+	// State is the phase of the rotation on the replica, e.g. KeyAdded, Completed, Failed, Aborted
+	State State `json:"state,omitempty"`
+
+	// Message error message to describe some states
+	Message string `json:"message,omitempty"`
+}
+
+// LocalVolumeKeyRotateStatus defines the observed state of LocalVolumeKeyRotate
+type LocalVolumeKeyRotateStatus struct {
+	// KeyProvider is where the key of the volume is held, e.g., Secret, KMS
+	KeyProvider string `json:"keyProvider,omitempty"`
+
+	// OldKey is the reference of the key to remove, i.e. the Secret name or the wrapped key of KMS
+	OldKey string `json:"oldKey,omitempty"`
+
+	// NewKey is the reference of the key to add, i.e. the Secret name or the wrapped key of KMS
+	NewKey string `json:"newKey,omitempty"`
+
+	// Replicas are the states of the rotation on each volume replica
+	Replicas []VolumeKeyRotateReplicaStatus `json:"replicas,omitempty"`
+
+	// StartTime is the time when the rotation starts
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time when the rotation completes
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// State is the phase of the rotation, e.g. Submitted, AddKey, RemoveKey, Rollback, Completed, Failed, Aborted
+	State State `json:"state,omitempty"`
+
+	// Message error message to describe some states
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeKeyRotate is a user's request to rotate the encryption key of a LocalVolume
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=localvolumekeyrotates,scope=Cluster,shortName=lvkeyrotate
+// +kubebuilder:printcolumn:name="volume",type=string,JSONPath=`.spec.volumeName`,description="Name of the volume"
+// +kubebuilder:printcolumn:name="provider",type=string,JSONPath=`.status.keyProvider`,description="Key provider of the volume"
+// +kubebuilder:printcolumn:name="state",type=string,JSONPath=`.status.state`,description="State of the rotation"
+// +kubebuilder:printcolumn:name="age",type=date,JSONPath=`.metadata.creationTimestamp`
+type LocalVolumeKeyRotate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocalVolumeKeyRotateSpec   `json:"spec,omitempty"`
+	Status LocalVolumeKeyRotateStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeKeyRotateList contains a list of LocalVolumeKeyRotate
+type LocalVolumeKeyRotateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalVolumeKeyRotate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LocalVolumeKeyRotate{}, &LocalVolumeKeyRotateList{})
+}
@@ -8,6 +8,11 @@ const (
 	VolumeMigrateCompletedAnnoKey = "hwameistor.io/volume-migrate-state"
 	MigrateStarted                = "migrateStarted"
 	MigrateCompleted              = "migrateCompleted"
+	// MigrateSwitchover is set when the new replica of the online migration is consistent,
+	// and the pod can only be scheduled to the target node
+	MigrateSwitchover = "migrateSwitchover"
+
+	VolumeMigrateTargetNodeAnnoKey = "hwameistor.io/volume-migrate-target-node"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -36,6 +41,53 @@ type LocalVolumeMigrateSpec struct {
 
 	// +kubebuilder:default:=false
 	Abort bool `json:"abort,omitempty"`
+
+	// Online migrates the convertible volume while it's in use. The new replica is synced by DRBD as the pod
+	// keeps running on the source node, and the pod is switched to the target node on its next restart
+	Online bool `json:"online,omitempty"`
+
+	// EvictPod evicts the pod from the source node once the new replica is consistent, rather than waiting
+	// for its next restart. For the online migration only
+	EvictPod bool `json:"evictPod,omitempty"`
+
+	// BandwidthLimit caps the bandwidth of the data copy in bytes per second, e.g. 100Mi. No limit if not set
+	BandwidthLimit string `json:"bandwidthLimit,omitempty"`
+
+	// TimeWindows are the daily time windows to start copying the data, e.g. at night. The data copy
+	// started within a window runs till the end. Can start at any time if not set
+	TimeWindows []MigrateTimeWindow `json:"timeWindows,omitempty"`
+}
+
+// MigrateTimeWindow is a daily time window in UTC. The window crosses midnight if Start is after End
+type MigrateTimeWindow struct {
+	// Start time of the window, in format HH:MM
+	// +kubebuilder:validation:Pattern:=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// End time of the window, in format HH:MM
+	// +kubebuilder:validation:Pattern:=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+}
+
+// MigrateProgress is the progress of copying the data of all the volumes to be migrated
+type MigrateProgress struct {
+	// CopiedBytes is the size of the data copied to the target node
+	CopiedBytes int64 `json:"copiedBytes"`
+
+	// TotalBytes is the size of all the data to copy
+	TotalBytes int64 `json:"totalBytes"`
+
+	// Throughput is the average bytes copied per second since the copy started
+	Throughput int64 `json:"throughput"`
+
+	// ETASeconds is the estimated seconds to complete the copy
+	ETASeconds int64 `json:"etaSeconds"`
+
+	// StartTime is the time when the data copy started
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// UpdateTime is the last time when the progress is updated
+	UpdateTime *metav1.Time `json:"updateTime,omitempty"`
 }
 
 // LocalVolumeMigrateStatus defines the observed state of LocalVolumeMigrate
@@ -55,6 +107,9 @@ type LocalVolumeMigrateStatus struct {
 	State State `json:"state,omitempty"`
 	// error message to describe some states
 	Message string `json:"message,omitempty"`
+
+	// Progress of the data copy
+	Progress *MigrateProgress `json:"progress,omitempty"`
 }
 
 // +genclient
@@ -68,6 +123,9 @@ type LocalVolumeMigrateStatus struct {
 // +kubebuilder:printcolumn:name="from",type=string,JSONPath=`.spec.sourceNode`,description="Node name of the volume replica to be migrated"
 // +kubebuilder:printcolumn:name="to",type=string,JSONPath=`.status.targetNode`,description="Node name of the new volume replica"
 // +kubebuilder:printcolumn:name="state",type=string,JSONPath=`.status.state`,description="State of the migration"
+// +kubebuilder:printcolumn:name="copied",type=integer,JSONPath=`.status.progress.copiedBytes`,description="Bytes copied to the new volume replica"
+// +kubebuilder:printcolumn:name="total",type=integer,JSONPath=`.status.progress.totalBytes`,description="Total bytes to copy"
+// +kubebuilder:printcolumn:name="eta",type=integer,JSONPath=`.status.progress.etaSeconds`,description="Estimated seconds to complete the copy",priority=1
 // +kubebuilder:printcolumn:name="age",type=date,JSONPath=`.metadata.creationTimestamp`
 type LocalVolumeMigrate struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -35,6 +35,12 @@ type LocalVolumeReplicaSpec struct {
 	// ThinOriginVolume is the name of the volume from which the thin volume is created
 	ThinOriginVolume *string `json:"thinOriginVolume,omitempty"`
 
+	// Mirror is to indicate if the volume replica is a LVM raid1 volume mirrored across the disks of the pool
+	Mirror bool `json:"mirror,omitempty"`
+
+	// VolumeCache is the cache carved from a faster pool on the node for the volume replica
+	VolumeCache *VolumeCache `json:"volumeCache,omitempty"`
+
 	// Delete is to indicate where the replica should be deleted or not.
 	// It's different from the regular resource delete interface in Kubernetes.
 	// The purpose is to protect it from any mistakes
@@ -71,13 +77,22 @@ type LocalVolumeReplicaStatus struct {
 	// +kubebuilder:default:=false
 	Synced bool `json:"synced,omitempty"`
 
+	// VolumeCache is the cache attached to the volume replica, the volume is used at DevicePath through the cache
+	VolumeCache *VolumeCacheStatus `json:"volumeCache,omitempty"`
+
 	// HAState is state for ha replica, replica.Status.State == Ready only when HAState is Consistent of nil
 	HAState *HAState `json:"haState,omitempty"`
 
+	// OutOfSyncBytes is the size of the data to be resynced from the peers, for the HA replica being resynced
+	OutOfSyncBytes int64 `json:"outOfSyncBytes,omitempty"`
+
 	// InUse is one of volume replica's states, which indicates the replica is used by a Pod or not
 	// +kubebuilder:default:=false
 	InUse bool `json:"inuse,omitempty"`
 
+	// VolumeQoS is the QoS applied on the volume replica
+	VolumeQoS *VolumeQoSStatus `json:"volumeQoS,omitempty"`
+
 	// Conditions records the information of the volume replica
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
@@ -33,6 +33,9 @@ type LocalVolumeReplicaSnapshotSpec struct {
 	// +kubebuilder:validation:Minimum:=4194304
 	RequiredCapacityBytes int64 `json:"requiredCapacityBytes"`
 
+	// Quiesce describes how to quiesce the volume replica during the snapshot
+	Quiesce *SnapshotQuiesce `json:"quiesce,omitempty"`
+
 	// Delete this snapshot if it is true
 	// +kubebuilder:default:=false
 	Delete bool `json:"delete,omitempty"`
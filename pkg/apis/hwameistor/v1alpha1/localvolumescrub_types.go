@@ -0,0 +1,124 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+// ScrubMethod is the way to verify the data of a volume replica
+type ScrubMethod string
+
+const (
+	// ScrubMethodDRBDVerify runs the DRBD online verify between the replicas of a HA volume
+	ScrubMethodDRBDVerify ScrubMethod = "DRBDVerify"
+
+	// ScrubMethodRaidCheck runs the raid check between the mirror images of a raid-backed LV
+	ScrubMethodRaidCheck ScrubMethod = "RaidCheck"
+
+	// ScrubMethodSnapshotChecksum checksums all the snapshots of a volume replica and compares them with the checksums
+	// recorded by the previous scrubs
+	ScrubMethodSnapshotChecksum ScrubMethod = "SnapshotChecksum"
+
+	// VolumeScrubChecksumAnnoKey is set on the LocalVolumeReplicaSnapshot with the checksum of its data
+	VolumeScrubChecksumAnnoKey = "hwameistor.io/scrub-checksum"
+)
+
+// LocalVolumeScrubSpec defines the desired state of LocalVolumeScrub
+type LocalVolumeScrubSpec struct {
+	// VolumeName is the name of the LocalVolume to scrub
+	// +kubebuilder:validation:Required
+	VolumeName string `json:"volumeName"`
+
+	// Abort can be used to abort the scrub operation
+	// +kubebuilder:default:=false
+	Abort bool `json:"abort,omitempty"`
+}
+
+// VolumeScrubResult is the result of the scrub executed on a node
+type VolumeScrubResult struct {
+	// NodeName is the node where the scrub is executed
+	NodeName string `json:"nodeName"`
+
+	// Method is the way to verify the data
+	// +kubebuilder:validation:Enum:=DRBDVerify;RaidCheck;SnapshotChecksum
+	Method ScrubMethod `json:"method"`
+
+	// OutOfSyncBytes is the size of the blocks which are found different between the DRBD replicas
+	OutOfSyncBytes int64 `json:"outOfSyncBytes,omitempty"`
+
+	// Mismatches is the number of the mismatched regions between the raid images
+	Mismatches int64 `json:"mismatches,omitempty"`
+
+	// Snapshot is the latest LocalVolumeReplicaSnapshot which the checksum is computed on
+	Snapshot string `json:"snapshot,omitempty"`
+
+	// Checksum is the sha256 checksum of the latest snapshot data
+	Checksum string `json:"checksum,omitempty"`
+
+	// Inconsistent indicates the data is found corrupted or out of sync
+	Inconsistent bool `json:"inconsistent,omitempty"`
+
+	// State is the phase of the scrub on the node, e.g. InProgress, Completed, Failed
+	State State `json:"state,omitempty"`
+
+	// Message error message to describe some states
+	Message string `json:"message,omitempty"`
+}
+
+// LocalVolumeScrubStatus defines the observed state of LocalVolumeScrub
+type LocalVolumeScrubStatus struct {
+	// PoolName is the storage pool of the volume
+	PoolName string `json:"poolName,omitempty"`
+
+	// Results are the results of the scrub on each node
+	Results []VolumeScrubResult `json:"results,omitempty"`
+
+	// Inconsistent indicates the data is found corrupted or out of sync on any of the nodes
+	Inconsistent bool `json:"inconsistent,omitempty"`
+
+	// StartTime is the time when the scrub starts
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time when the scrub completes
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// State is the phase of the scrub, e.g. Submitted, InProgress, Completed, Failed, Aborted
+	State State `json:"state,omitempty"`
+
+	// Message error message to describe some states
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeScrub is a user's request to verify the data integrity of a LocalVolume
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=localvolumescrubs,scope=Cluster,shortName=lvscrub
+// +kubebuilder:printcolumn:name="volume",type=string,JSONPath=`.spec.volumeName`,description="Name of the volume to scrub"
+// +kubebuilder:printcolumn:name="pool",type=string,JSONPath=`.status.poolName`,description="Pool of the volume"
+// +kubebuilder:printcolumn:name="inconsistent",type=boolean,JSONPath=`.status.inconsistent`,description="Data is found inconsistent or not"
+// +kubebuilder:printcolumn:name="state",type=string,JSONPath=`.status.state`,description="State of the scrub"
+// +kubebuilder:printcolumn:name="age",type=date,JSONPath=`.metadata.creationTimestamp`
+type LocalVolumeScrub struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocalVolumeScrubSpec   `json:"spec,omitempty"`
+	Status LocalVolumeScrubStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeScrubList contains a list of LocalVolumeScrub
+type LocalVolumeScrubList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalVolumeScrub `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LocalVolumeScrub{}, &LocalVolumeScrubList{})
+}
@@ -0,0 +1,90 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+const (
+	// VolumeScrubScheduleLabelKey is set on the LocalVolumeScrub created by a LocalVolumeScrubSchedule
+	VolumeScrubScheduleLabelKey = "hwameistor.io/scrub-schedule"
+
+	// VolumeScrubVolumeLabelKey is set on the LocalVolumeScrub created by a LocalVolumeScrubSchedule
+	VolumeScrubVolumeLabelKey = "hwameistor.io/scrub-volume"
+
+	// VolumeScrubDefaultHistoryLimit is the default number of the finished scrubs to keep for each volume
+	VolumeScrubDefaultHistoryLimit = 3
+)
+
+// LocalVolumeScrubScheduleSpec defines the desired state of LocalVolumeScrubSchedule
+type LocalVolumeScrubScheduleSpec struct {
+	// Schedule is the cron expression in the standard format, e.g. "0 3 * * 0", "@weekly"
+	// +kubebuilder:validation:Required
+	Schedule string `json:"schedule"`
+
+	// PoolName is the storage pool whose volumes are scrubbed, e.g. LocalStorage_PoolHDD
+	// +kubebuilder:validation:Required
+	PoolName string `json:"poolName"`
+
+	// HistoryLimit is the number of the finished scrubs to keep for each volume
+	// +kubebuilder:default:=3
+	// +kubebuilder:validation:Minimum:=1
+	HistoryLimit int `json:"historyLimit,omitempty"`
+
+	// Suspend stops the subsequent scrubs, the existing ones are not affected
+	// +kubebuilder:default:=false
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// LocalVolumeScrubScheduleStatus defines the observed state of LocalVolumeScrubSchedule
+type LocalVolumeScrubScheduleStatus struct {
+	// LastScheduleTime is the last time when the scrubs are created
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// NextScheduleTime is the next time to create the scrubs
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+
+	// Schedule is the cron expression which NextScheduleTime is computed from
+	Schedule string `json:"schedule,omitempty"`
+
+	// Volumes are the volumes in the pool at the last schedule
+	Volumes []string `json:"volumes,omitempty"`
+
+	// Message error message to describe some states
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeScrubSchedule is a policy to scrub the volumes of a storage pool periodically
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=localvolumescrubschedules,scope=Cluster,shortName=lvscrubschedule
+// +kubebuilder:printcolumn:name="pool",type=string,JSONPath=`.spec.poolName`,description="Pool to scrub"
+// +kubebuilder:printcolumn:name="schedule",type=string,JSONPath=`.spec.schedule`,description="Cron expression of the schedule"
+// +kubebuilder:printcolumn:name="suspend",type=boolean,JSONPath=`.spec.suspend`,description="Suspend the schedule or not"
+// +kubebuilder:printcolumn:name="lastrun",type=date,JSONPath=`.status.lastScheduleTime`,description="Last schedule time"
+// +kubebuilder:printcolumn:name="nextrun",type=string,JSONPath=`.status.nextScheduleTime`,description="Next schedule time"
+// +kubebuilder:printcolumn:name="age",type=date,JSONPath=`.metadata.creationTimestamp`
+type LocalVolumeScrubSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocalVolumeScrubScheduleSpec   `json:"spec,omitempty"`
+	Status LocalVolumeScrubScheduleStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeScrubScheduleList contains a list of LocalVolumeScrubSchedule
+type LocalVolumeScrubScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalVolumeScrubSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LocalVolumeScrubSchedule{}, &LocalVolumeScrubScheduleList{})
+}
@@ -0,0 +1,104 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+const (
+	// VolumeShrinkInProgressAnnoKey is set on the LocalVolume and its replicas with the name of the LocalVolumeShrink
+	// until the volume is shrunk. The volume can't be published while it's set
+	VolumeShrinkInProgressAnnoKey = "hwameistor.io/shrink-in-progress"
+)
+
+// LocalVolumeShrinkSpec defines the desired state of LocalVolumeShrink
+type LocalVolumeShrinkSpec struct {
+	// VolumeName is the name of the offline LocalVolume to shrink
+	// +kubebuilder:validation:Required
+	VolumeName string `json:"volumeName"`
+
+	// RequiredCapacityBytes is the new capacity of the volume, which must be less than the current one
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum:=4194304
+	RequiredCapacityBytes int64 `json:"requiredCapacityBytes"`
+
+	// Abort can be used to abort the shrink before the filesystem is shrunk
+	// +kubebuilder:default:=false
+	Abort bool `json:"abort,omitempty"`
+}
+
+// LocalVolumeShrinkStatus defines the observed state of LocalVolumeShrink
+type LocalVolumeShrinkStatus struct {
+	// NodeName is the node to shrink the filesystem on, i.e. the primary replica of the volume
+	NodeName string `json:"nodeName,omitempty"`
+
+	// OriginalCapacityBytes is the capacity of the volume before shrunk
+	OriginalCapacityBytes int64 `json:"originalCapacityBytes,omitempty"`
+
+	// AllocatedCapacityBytes is the capacity of the volume after shrunk
+	AllocatedCapacityBytes int64 `json:"allocatedCapacityBytes,omitempty"`
+
+	// FSType is the filesystem found on the volume
+	FSType string `json:"fsType,omitempty"`
+
+	// Subs are the volume replicas shrunk already
+	Subs []string `json:"subs,omitempty"`
+
+	// StartTime is the time when the shrink starts
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time when the shrink completes
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// State is the phase of the shrink, e.g. Submitted, ShrinkFilesystem, ShrinkReplica, InProgress, Completed, Failed, Aborted
+	State State `json:"state,omitempty"`
+
+	// Message error message to describe some states
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeShrink is a user's request to shrink an offline LocalVolume, the filesystem is shrunk before the volume
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=localvolumeshrinks,scope=Cluster,shortName=lvshrink
+// +kubebuilder:printcolumn:name="volume",type=string,JSONPath=`.spec.volumeName`,description="Name of the volume"
+// +kubebuilder:printcolumn:name="newCapacity",type=integer,JSONPath=`.spec.requiredCapacityBytes`,description="New capacity of the volume"
+// +kubebuilder:printcolumn:name="fsType",type=string,JSONPath=`.status.fsType`,description="Filesystem on the volume"
+// +kubebuilder:printcolumn:name="state",type=string,JSONPath=`.status.state`,description="State of the shrink"
+// +kubebuilder:printcolumn:name="message",type=string,JSONPath=`.status.message`,description="Event message of the shrink"
+// +kubebuilder:printcolumn:name="age",type=date,JSONPath=`.metadata.creationTimestamp`
+type LocalVolumeShrink struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocalVolumeShrinkSpec   `json:"spec,omitempty"`
+	Status LocalVolumeShrinkStatus `json:"status,omitempty"`
+}
+
+// HasSub returns true if the volume replica is shrunk already
+func (v *LocalVolumeShrink) HasSub(name string) bool {
+	for _, subname := range v.Status.Subs {
+		if subname == name {
+			return true
+		}
+	}
+	return false
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeShrinkList contains a list of LocalVolumeShrink
+type LocalVolumeShrinkList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalVolumeShrink `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LocalVolumeShrink{}, &LocalVolumeShrinkList{})
+}
@@ -8,6 +8,40 @@ const (
 	SnapshotRestoringFinalizer = "provisioner.hwameistor.io/restoring-protection"
 )
 
+// consts for snapshot quiesce
+const (
+	// SnapshotQuiesceModeNone takes the crash-consistent snapshot, the filesystem is live during the snapshot
+	SnapshotQuiesceModeNone = "None"
+	// SnapshotQuiesceModeFSFreeze freezes the mounted filesystem of the volume during the snapshot
+	SnapshotQuiesceModeFSFreeze = "FSFreeze"
+	// SnapshotQuiesceModeHook executes the pre/post hook commands in the containers of the pods using the volume
+	SnapshotQuiesceModeHook = "Hook"
+
+	SnapshotQuiesceDefaultTimeoutSeconds = 30
+
+	// SnapshotPreHookCommandAnnoKey is the pod annotation of the command executed before the snapshot, e.g. "mysql -e 'FLUSH TABLES WITH READ LOCK'"
+	SnapshotPreHookCommandAnnoKey = "pre.hook.snapshot.hwameistor.io/command"
+	// SnapshotPostHookCommandAnnoKey is the pod annotation of the command executed after the snapshot
+	SnapshotPostHookCommandAnnoKey = "post.hook.snapshot.hwameistor.io/command"
+	// SnapshotHookContainerAnnoKey is the pod annotation of the container to execute the hook commands, the first container by default
+	SnapshotHookContainerAnnoKey = "hook.snapshot.hwameistor.io/container"
+)
+
+// SnapshotQuiesce describes how to quiesce the volume to take an application-consistent snapshot
+type SnapshotQuiesce struct {
+	// Mode is the way to quiesce the volume, valid options are None, FSFreeze, Hook.
+	// When the volume is in a LocalVolumeGroup, all the volumes in the group are quiesced together
+	// +kubebuilder:validation:Enum:=None;FSFreeze;Hook
+	// +kubebuilder:default:=None
+	Mode string `json:"mode,omitempty"`
+
+	// TimeoutSeconds is the timeout of each hook command and the longest time the volume is kept quiesced.
+	// The volume is always resumed after the snapshot, even if the snapshot fails
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:default:=30
+	TimeoutSeconds int `json:"timeoutSeconds,omitempty"`
+}
+
 // VolumeSnapshotSpec describes the common attributes of a volume snapshot.
 
 // LocalVolumeSnapshotSpec describes the common attributes of a localvolume snapshot.
@@ -28,6 +62,9 @@ type LocalVolumeSnapshotSpec struct {
 	// Thin indicates LocalVolumeSnapshot is thin provisioned or not
 	Thin bool `json:"thin,omitempty"`
 
+	// Quiesce describes how to quiesce the volume during the snapshot, no quiesce by default
+	Quiesce *SnapshotQuiesce `json:"quiesce,omitempty"`
+
 	// +kubebuilder:default:=false
 	Delete bool `json:"delete,omitempty"`
 }
@@ -0,0 +1,118 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
+// NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
+
+const (
+	// VolumeSnapshotScheduleLabelKey is set on the LocalVolumeSnapshot created by a LocalVolumeSnapshotSchedule
+	VolumeSnapshotScheduleLabelKey = "hwameistor.io/snapshot-schedule"
+
+	// VolumeSnapshotSourceVolumeLabelKey is set on the LocalVolumeSnapshot created by a LocalVolumeSnapshotSchedule
+	VolumeSnapshotSourceVolumeLabelKey = "hwameistor.io/snapshot-source-volume"
+)
+
+// SnapshotRetention describes which of the scheduled snapshots of a volume are kept.
+// A snapshot is kept if it's selected by any of the rules, all the snapshots are kept if no rule is set
+type SnapshotRetention struct {
+	// KeepLast is the number of the latest snapshots to keep
+	// +kubebuilder:validation:Minimum:=0
+	KeepLast int `json:"keepLast,omitempty"`
+
+	// KeepDaily is the number of the days to keep the latest snapshot of each day
+	// +kubebuilder:validation:Minimum:=0
+	KeepDaily int `json:"keepDaily,omitempty"`
+
+	// KeepWeekly is the number of the weeks to keep the latest snapshot of each week
+	// +kubebuilder:validation:Minimum:=0
+	KeepWeekly int `json:"keepWeekly,omitempty"`
+}
+
+// LocalVolumeSnapshotScheduleSpec defines the desired state of LocalVolumeSnapshotSchedule
+type LocalVolumeSnapshotScheduleSpec struct {
+	// Schedule is the cron expression in the standard format, e.g. "0 2 * * *", "@daily"
+	// +kubebuilder:validation:Required
+	Schedule string `json:"schedule"`
+
+	// The volumes of the PVCs matched by all the selectors are snapshotted. At least one selector is required
+	StorageClassSelector *metav1.LabelSelector `json:"storageClassSelector,omitempty"`
+
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+
+	PVCSelector *metav1.LabelSelector `json:"pvcSelector,omitempty"`
+
+	// Retention describes how to prune the scheduled snapshots
+	Retention SnapshotRetention `json:"retention,omitempty"`
+
+	// Suspend stops the subsequent snapshots, the existing ones are not affected
+	// +kubebuilder:default:=false
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// SnapshotScheduleFailure describes a failure of the scheduled snapshot on a volume
+type SnapshotScheduleFailure struct {
+	// VolumeName is the name of the LocalVolume
+	VolumeName string `json:"volumeName"`
+
+	// Message describes the failure
+	Message string `json:"message,omitempty"`
+}
+
+// LocalVolumeSnapshotScheduleStatus defines the observed state of LocalVolumeSnapshotSchedule
+type LocalVolumeSnapshotScheduleStatus struct {
+	// LastScheduleTime is the last time when the snapshots are taken
+	LastScheduleTime *metav1.Time `json:"lastScheduleTime,omitempty"`
+
+	// LastSuccessfulTime is the last time when the snapshots of all the volumes are taken successfully
+	LastSuccessfulTime *metav1.Time `json:"lastSuccessfulTime,omitempty"`
+
+	// NextScheduleTime is the next time to take the snapshots
+	NextScheduleTime *metav1.Time `json:"nextScheduleTime,omitempty"`
+
+	// Schedule is the cron expression which NextScheduleTime is computed from
+	Schedule string `json:"schedule,omitempty"`
+
+	// Volumes are the volumes matched by the selectors at the last schedule
+	Volumes []string `json:"volumes,omitempty"`
+
+	// Failures are the failures at the last schedule
+	Failures []SnapshotScheduleFailure `json:"failures,omitempty"`
+
+	// Message error message to describe some states
+	Message string `json:"message,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeSnapshotSchedule is a policy to take the snapshots of the selected volumes periodically and prune the old ones
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=localvolumesnapshotschedules,scope=Cluster,shortName=lvsschedule
+// +kubebuilder:printcolumn:name="schedule",type=string,JSONPath=`.spec.schedule`,description="Cron expression of the schedule"
+// +kubebuilder:printcolumn:name="suspend",type=boolean,JSONPath=`.spec.suspend`,description="Suspend the schedule or not"
+// +kubebuilder:printcolumn:name="lastsuccess",type=date,JSONPath=`.status.lastSuccessfulTime`,description="Last successful time"
+// +kubebuilder:printcolumn:name="nextrun",type=string,JSONPath=`.status.nextScheduleTime`,description="Next schedule time"
+// +kubebuilder:printcolumn:name="age",type=date,JSONPath=`.metadata.creationTimestamp`
+type LocalVolumeSnapshotSchedule struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   LocalVolumeSnapshotScheduleSpec   `json:"spec,omitempty"`
+	Status LocalVolumeSnapshotScheduleStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// LocalVolumeSnapshotScheduleList contains a list of LocalVolumeSnapshotSchedule
+type LocalVolumeSnapshotScheduleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []LocalVolumeSnapshotSchedule `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&LocalVolumeSnapshotSchedule{}, &LocalVolumeSnapshotScheduleList{})
+}
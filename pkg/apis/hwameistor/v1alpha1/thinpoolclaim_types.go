@@ -62,6 +62,38 @@ type ThinPoolClaimDescription struct {
 	// +kubebuilder:validation:Minimum:=1
 	// +kubebuilder:validation:Maximum:=1048576
 	PoolMetadataSize *uint `json:"poolMetadataSize,omitempty"`
+
+	// AutoExtend extends the thin pool automatically with the free space of the pool when the data or
+	// metadata usage reaches the threshold. Hwameistor will pick the latest non-nil value as the final value.
+	// +optional
+	AutoExtend *ThinPoolAutoExtendPolicy `json:"autoExtend,omitempty"`
+}
+
+// ThinPoolAutoExtendPolicy defines when and how much to extend the thin pool
+type ThinPoolAutoExtendPolicy struct {
+	// ThresholdPercent is the data or metadata usage in percent to trigger the extension
+	// +kubebuilder:default:=80
+	// +kubebuilder:validation:Minimum:=50
+	// +kubebuilder:validation:Maximum:=99
+	ThresholdPercent int64 `json:"thresholdPercent,omitempty"`
+
+	// ExtendPercent is the step to extend the thin pool data, in percent of its current size
+	// +kubebuilder:default:=20
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=100
+	ExtendPercent int64 `json:"extendPercent,omitempty"`
+
+	// MaxCapacity is the max size of the thin pool data in GiB. No limit if not set
+	// +optional
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=1048576
+	MaxCapacity int64 `json:"maxCapacity,omitempty"`
+
+	// MetadataExtendSize is the step to extend the thin pool metadata in GiB
+	// +kubebuilder:default:=1
+	// +kubebuilder:validation:Minimum:=1
+	// +kubebuilder:validation:Maximum:=16
+	MetadataExtendSize int64 `json:"metadataExtendSize,omitempty"`
 }
 
 // ThinPoolClaimSpec defines the desired state of ThinPoolClaim
@@ -79,16 +79,28 @@ const (
 
 	// purpose of the following CRDs is for operational job,
 	// so, they will be in different state machine from volume/volumereplica
-	OperationStateSubmitted           State = "Submitted"
-	OperationStateMigrateAddReplica   State = "AddReplica"
-	OperationStateMigrateSyncReplica  State = "SyncReplica"
-	OperationStateMigratePruneReplica State = "PruneReplica"
-	OperationStateInProgress          State = "InProgress"
-	OperationStateCompleted           State = "Completed"
-	OperationStateToBeAborted         State = "ToBeAborted"
-	OperationStateAborting            State = "Cancelled"
-	OperationStateAborted             State = "Aborted"
-	OperationStateFailed              State = "Failed"
+	OperationStateSubmitted            State = "Submitted"
+	OperationStateMigrateAddReplica    State = "AddReplica"
+	OperationStateMigrateSyncReplica   State = "SyncReplica"
+	OperationStateMigratePruneReplica  State = "PruneReplica"
+	OperationStateMigrateSwitchover    State = "Switchover"
+	OperationStateDecommissionEvacuate State = "Evacuate"
+	OperationStateDecommissionMigrate  State = "Migrate"
+	OperationStateDecommissionRemove   State = "Remove"
+	OperationStateKeyRotateAddKey      State = "AddKey"
+	OperationStateKeyRotateKeyAdded    State = "KeyAdded"
+	OperationStateKeyRotateRemoveKey   State = "RemoveKey"
+	OperationStateRollback             State = "Rollback"
+	OperationStateEncryptInitialize    State = "Initialize"
+	OperationStateEncryptReencrypt     State = "Reencrypt"
+	OperationStateShrinkFilesystem     State = "ShrinkFilesystem"
+	OperationStateShrinkReplica        State = "ShrinkReplica"
+	OperationStateInProgress           State = "InProgress"
+	OperationStateCompleted            State = "Completed"
+	OperationStateToBeAborted          State = "ToBeAborted"
+	OperationStateAborting             State = "Cancelled"
+	OperationStateAborted              State = "Aborted"
+	OperationStateFailed               State = "Failed"
 
 	DiskStateAvailable State = "Available"
 	DiskStateInUse     State = "InUse"
@@ -112,6 +124,9 @@ const (
 	VolumeReplicaConditionSubmit = "Submit"
 	VolumeReplicaConditionCreate = "Create"
 	VolumeReplicaConditionCheck  = "Ready"
+	// for the mirrored LVM volume replica
+	VolumeReplicaConditionMirrorSync   = "MirrorSynced"
+	VolumeReplicaConditionMirrorRepair = "MirrorRepaired"
 
 	VolumeReplicaSnapshotConditionSubmit     = "Submit"
 	VolumeReplicaSnapshotConditionCreate     = "Create"
@@ -139,6 +154,31 @@ const (
 	ThinPoolName = "LocalStorage_ThinPool"
 )
 
+// storage backends of the pools
+const (
+	StorageBackendLVM = "LVM"
+	StorageBackendZFS = "ZFS"
+)
+
+// MaxVolumeReplicaNumber is the max number of the replicas of a HA volume, not including the temp one for migration
+const MaxVolumeReplicaNumber = 3
+
+// LVMMirrorCopies is the number of the data copies of a mirrored (raid1) LVM volume replica, each on a distinct disk
+const LVMMirrorCopies = 2
+
+// consts for the cache mode of the volume
+const (
+	// VolumeCacheModeWritethrough caches the reads with dm-cache, the writes go to the volume at the same time
+	VolumeCacheModeWritethrough = "writethrough"
+	// VolumeCacheModeWriteback caches the writes with dm-writecache, and writes them back to the volume later
+	VolumeCacheModeWriteback = "writeback"
+
+	// VolumeCacheCapacityPercentDefault is the default capacity of the cache, in percentage of the volume
+	VolumeCacheCapacityPercentDefault = 10
+	// VolumeCacheCapacityBytesMin is the minimum capacity of the cache
+	VolumeCacheCapacityBytesMin = 4 * 1024 * 1024
+)
+
 // consts
 const (
 	VolumeParameterPoolClassKey     = "poolClass"
@@ -148,13 +188,25 @@ const (
 	VolumeParameterConvertible      = "convertible"
 	VolumeParameterThroughput       = "provision-throughput-on-creation"
 	VolumeParameterIOPS             = "provision-iops-on-creation"
+	VolumeParameterBurstThroughput  = "provision-burst-throughput-on-creation"
+	VolumeParameterBurstIOPS        = "provision-burst-iops-on-creation"
+	VolumeParameterBurstDuration    = "provision-burst-duration-on-creation"
+	VolumeParameterIOWeight         = "provision-io-weight-on-creation"
+	VolumeParameterIOLatencyTarget  = "provision-io-latency-target-on-creation"
 	VolumeParameterThin             = "thin"
+	VolumeParameterMirror           = "mirror"
+	VolumeParameterStorageBackend   = "storageBackend"
+	VolumeParameterCachePoolClass   = "cachePoolClass"
+	VolumeParameterCacheCapacity    = "cacheCapacity"
+	VolumeParameterCacheMode        = "cacheMode"
 )
 
 // consts for snapshot class
 
 const (
-	SnapshotParameterSizeKey = "snapsize"
+	SnapshotParameterSizeKey           = "snapsize"
+	SnapshotParameterQuiesceKey        = "quiesce"
+	SnapshotParameterQuiesceTimeoutKey = "quiesceTimeout"
 )
 
 type RestoreType string
@@ -286,12 +338,32 @@ type DRBDSystemConfig struct {
 	EndPort   int `json:"haEndPort"`
 }
 
+// ZFSSystemConfig of ZFS storage backend
+type ZFSSystemConfig struct {
+	// Compression algorithm of the zpool, e.g. lz4, zstd, off
+	Compression string `json:"compression"`
+	// Checksum algorithm of the zpool, e.g. sha256, fletcher4
+	Checksum string `json:"checksum"`
+}
+
 // SystemConfig is volume HA related system configuration
 type SystemConfig struct {
 	Mode             SystemMode        `json:"mode"`
 	DRBD             *DRBDSystemConfig `json:"drbd"`
 	MaxHAVolumeCount int               `json:"maxVolumeCount"`
 	SyncToolName     string            `json:"syncTool"`
+	// StorageBackends is the storage backend of each pool class, e.g. HDD -> LVM, SSD -> ZFS.
+	// The pool class which is not in it will be backed by LVM
+	StorageBackends map[string]string `json:"storageBackends,omitempty"`
+	ZFS             *ZFSSystemConfig  `json:"zfs,omitempty"`
+}
+
+// GetStorageBackend returns the storage backend of the pool class
+func (c SystemConfig) GetStorageBackend(poolClass string) string {
+	if backend, ok := c.StorageBackends[poolClass]; ok && backend != "" {
+		return backend
+	}
+	return StorageBackendLVM
 }
 
 //go:generate mockgen -source=types.go -destination=../../../member/controller/volumegroup/manager_mock.go  -package=volumegroup
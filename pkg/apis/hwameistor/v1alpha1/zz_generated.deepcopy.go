@@ -42,6 +42,22 @@ func (in *AccessibilityTopology) DeepCopy() *AccessibilityTopology {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupTarget) DeepCopyInto(out *BackupTarget) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupTarget.
+func (in *BackupTarget) DeepCopy() *BackupTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DRBDSystemConfig) DeepCopyInto(out *DRBDSystemConfig) {
 	*out = *in
@@ -116,6 +132,302 @@ func (in *DiskClaimDescription) DeepCopy() *DiskClaimDescription {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskExtent) DeepCopyInto(out *DiskExtent) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskExtent.
+func (in *DiskExtent) DeepCopy() *DiskExtent {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskExtent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskHealthPolicy) DeepCopyInto(out *DiskHealthPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskHealthPolicy.
+func (in *DiskHealthPolicy) DeepCopy() *DiskHealthPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskHealthPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DiskHealthPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskHealthPolicyList) DeepCopyInto(out *DiskHealthPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DiskHealthPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskHealthPolicyList.
+func (in *DiskHealthPolicyList) DeepCopy() *DiskHealthPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskHealthPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DiskHealthPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskHealthPolicySpec) DeepCopyInto(out *DiskHealthPolicySpec) {
+	*out = *in
+	in.Thresholds.DeepCopyInto(&out.Thresholds)
+	out.Reaction = in.Reaction
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskHealthPolicySpec.
+func (in *DiskHealthPolicySpec) DeepCopy() *DiskHealthPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskHealthPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskHealthPolicyStatus) DeepCopyInto(out *DiskHealthPolicyStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskHealthPolicyStatus.
+func (in *DiskHealthPolicyStatus) DeepCopy() *DiskHealthPolicyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskHealthPolicyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskHealthReaction) DeepCopyInto(out *DiskHealthReaction) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskHealthReaction.
+func (in *DiskHealthReaction) DeepCopy() *DiskHealthReaction {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskHealthReaction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskHealthSample) DeepCopyInto(out *DiskHealthSample) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskHealthSample.
+func (in *DiskHealthSample) DeepCopy() *DiskHealthSample {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskHealthSample)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskHealthStatus) DeepCopyInto(out *DiskHealthStatus) {
+	*out = *in
+	if in.Reasons != nil {
+		in, out := &in.Reasons, &out.Reasons
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	if in.Samples != nil {
+		in, out := &in.Samples, &out.Samples
+		*out = make([]DiskHealthSample, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Migrations != nil {
+		in, out := &in.Migrations, &out.Migrations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskHealthStatus.
+func (in *DiskHealthStatus) DeepCopy() *DiskHealthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskHealthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskHealthThreshold) DeepCopyInto(out *DiskHealthThreshold) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskHealthThreshold.
+func (in *DiskHealthThreshold) DeepCopy() *DiskHealthThreshold {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskHealthThreshold)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskHealthThresholds) DeepCopyInto(out *DiskHealthThresholds) {
+	*out = *in
+	if in.ReallocatedSectors != nil {
+		in, out := &in.ReallocatedSectors, &out.ReallocatedSectors
+		*out = new(DiskHealthThreshold)
+		**out = **in
+	}
+	if in.PendingSectors != nil {
+		in, out := &in.PendingSectors, &out.PendingSectors
+		*out = new(DiskHealthThreshold)
+		**out = **in
+	}
+	if in.MediaErrors != nil {
+		in, out := &in.MediaErrors, &out.MediaErrors
+		*out = new(DiskHealthThreshold)
+		**out = **in
+	}
+	if in.PercentageUsed != nil {
+		in, out := &in.PercentageUsed, &out.PercentageUsed
+		*out = new(DiskHealthThreshold)
+		**out = **in
+	}
+	if in.CRCErrors != nil {
+		in, out := &in.CRCErrors, &out.CRCErrors
+		*out = new(DiskHealthThreshold)
+		**out = **in
+	}
+	if in.Temperature != nil {
+		in, out := &in.Temperature, &out.Temperature
+		*out = new(DiskHealthThreshold)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskHealthThresholds.
+func (in *DiskHealthThresholds) DeepCopy() *DiskHealthThresholds {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskHealthThresholds)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskPartition) DeepCopyInto(out *DiskPartition) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskPartition.
+func (in *DiskPartition) DeepCopy() *DiskPartition {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskPartition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskSanitizeStatus) DeepCopyInto(out *DiskSanitizeStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskSanitizeStatus.
+func (in *DiskSanitizeStatus) DeepCopy() *DiskSanitizeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskSanitizeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskVolumeExpansionStatus) DeepCopyInto(out *DiskVolumeExpansionStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskVolumeExpansionStatus.
+func (in *DiskVolumeExpansionStatus) DeepCopy() *DiskVolumeExpansionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskVolumeExpansionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Event) DeepCopyInto(out *Event) {
 	*out = *in
@@ -268,6 +580,11 @@ func (in *HAState) DeepCopy() *HAState {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LocalDevice) DeepCopyInto(out *LocalDevice) {
 	*out = *in
+	if in.FreeExtents != nil {
+		in, out := &in.FreeExtents, &out.FreeExtents
+		*out = make([]DiskExtent, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -287,7 +604,7 @@ func (in *LocalDisk) DeepCopyInto(out *LocalDisk) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -552,32 +869,27 @@ func (in *LocalDiskClaimStatus) DeepCopy() *LocalDiskClaimStatus {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalDiskList) DeepCopyInto(out *LocalDiskList) {
+func (in *LocalDiskDecommission) DeepCopyInto(out *LocalDiskDecommission) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]LocalDisk, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalDiskList.
-func (in *LocalDiskList) DeepCopy() *LocalDiskList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalDiskDecommission.
+func (in *LocalDiskDecommission) DeepCopy() *LocalDiskDecommission {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalDiskList)
+	out := new(LocalDiskDecommission)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalDiskList) DeepCopyObject() runtime.Object {
+func (in *LocalDiskDecommission) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -585,21 +897,124 @@ func (in *LocalDiskList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalDiskNode) DeepCopyInto(out *LocalDiskNode) {
+func (in *LocalDiskDecommissionList) DeepCopyInto(out *LocalDiskDecommissionList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	in.Status.DeepCopyInto(&out.Status)
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LocalDiskDecommission, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalDiskNode.
-func (in *LocalDiskNode) DeepCopy() *LocalDiskNode {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalDiskDecommissionList.
+func (in *LocalDiskDecommissionList) DeepCopy() *LocalDiskDecommissionList {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalDiskNode)
+	out := new(LocalDiskDecommissionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalDiskDecommissionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalDiskDecommissionSpec) DeepCopyInto(out *LocalDiskDecommissionSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalDiskDecommissionSpec.
+func (in *LocalDiskDecommissionSpec) DeepCopy() *LocalDiskDecommissionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalDiskDecommissionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalDiskDecommissionStatus) DeepCopyInto(out *LocalDiskDecommissionStatus) {
+	*out = *in
+	if in.Migrations != nil {
+		in, out := &in.Migrations, &out.Migrations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalDiskDecommissionStatus.
+func (in *LocalDiskDecommissionStatus) DeepCopy() *LocalDiskDecommissionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalDiskDecommissionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalDiskList) DeepCopyInto(out *LocalDiskList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LocalDisk, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalDiskList.
+func (in *LocalDiskList) DeepCopy() *LocalDiskList {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalDiskList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalDiskList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalDiskNode) DeepCopyInto(out *LocalDiskNode) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalDiskNode.
+func (in *LocalDiskNode) DeepCopy() *LocalDiskNode {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalDiskNode)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -721,7 +1136,7 @@ func (in *LocalDiskSpec) DeepCopyInto(out *LocalDiskSpec) {
 		*out = make([]PartitionInfo, len(*in))
 		copy(*out, *in)
 	}
-	out.RAIDInfo = in.RAIDInfo
+	in.RAIDInfo.DeepCopyInto(&out.RAIDInfo)
 	out.SmartInfo = in.SmartInfo
 	out.DiskAttributes = in.DiskAttributes
 	if in.ClaimRef != nil {
@@ -745,6 +1160,18 @@ func (in *LocalDiskSpec) DeepCopy() *LocalDiskSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LocalDiskStatus) DeepCopyInto(out *LocalDiskStatus) {
 	*out = *in
+	if in.Health != nil {
+		in, out := &in.Health, &out.Health
+		*out = new(DiskHealthStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -861,6 +1288,21 @@ func (in *LocalDiskVolumeStatus) DeepCopyInto(out *LocalDiskVolumeStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Sanitize != nil {
+		in, out := &in.Sanitize, &out.Sanitize
+		*out = new(DiskSanitizeStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Partition != nil {
+		in, out := &in.Partition, &out.Partition
+		*out = new(DiskPartition)
+		**out = **in
+	}
+	if in.Expansion != nil {
+		in, out := &in.Expansion, &out.Expansion
+		*out = new(DiskVolumeExpansionStatus)
+		**out = **in
+	}
 	return
 }
 
@@ -880,7 +1322,9 @@ func (in *LocalPool) DeepCopyInto(out *LocalPool) {
 	if in.Disks != nil {
 		in, out := &in.Disks, &out.Disks
 		*out = make([]LocalDevice, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 	if in.Volumes != nil {
 		in, out := &in.Volumes, &out.Volumes
@@ -1076,27 +1520,27 @@ func (in *LocalVolume) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeConvert) DeepCopyInto(out *LocalVolumeConvert) {
+func (in *LocalVolumeBackup) DeepCopyInto(out *LocalVolumeBackup) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeConvert.
-func (in *LocalVolumeConvert) DeepCopy() *LocalVolumeConvert {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeBackup.
+func (in *LocalVolumeBackup) DeepCopy() *LocalVolumeBackup {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeConvert)
+	out := new(LocalVolumeBackup)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeConvert) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeBackup) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1104,13 +1548,13 @@ func (in *LocalVolumeConvert) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeConvertList) DeepCopyInto(out *LocalVolumeConvertList) {
+func (in *LocalVolumeBackupList) DeepCopyInto(out *LocalVolumeBackupList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]LocalVolumeConvert, len(*in))
+		*out = make([]LocalVolumeBackup, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1118,18 +1562,18 @@ func (in *LocalVolumeConvertList) DeepCopyInto(out *LocalVolumeConvertList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeConvertList.
-func (in *LocalVolumeConvertList) DeepCopy() *LocalVolumeConvertList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeBackupList.
+func (in *LocalVolumeBackupList) DeepCopy() *LocalVolumeBackupList {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeConvertList)
+	out := new(LocalVolumeBackupList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeConvertList) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeBackupList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1137,39 +1581,7 @@ func (in *LocalVolumeConvertList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeConvertSpec) DeepCopyInto(out *LocalVolumeConvertSpec) {
-	*out = *in
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeConvertSpec.
-func (in *LocalVolumeConvertSpec) DeepCopy() *LocalVolumeConvertSpec {
-	if in == nil {
-		return nil
-	}
-	out := new(LocalVolumeConvertSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeConvertStatus) DeepCopyInto(out *LocalVolumeConvertStatus) {
-	*out = *in
-	return
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeConvertStatus.
-func (in *LocalVolumeConvertStatus) DeepCopy() *LocalVolumeConvertStatus {
-	if in == nil {
-		return nil
-	}
-	out := new(LocalVolumeConvertStatus)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeExpand) DeepCopyInto(out *LocalVolumeExpand) {
+func (in *LocalVolumeBackupRestore) DeepCopyInto(out *LocalVolumeBackupRestore) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
@@ -1178,18 +1590,18 @@ func (in *LocalVolumeExpand) DeepCopyInto(out *LocalVolumeExpand) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeExpand.
-func (in *LocalVolumeExpand) DeepCopy() *LocalVolumeExpand {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeBackupRestore.
+func (in *LocalVolumeBackupRestore) DeepCopy() *LocalVolumeBackupRestore {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeExpand)
+	out := new(LocalVolumeBackupRestore)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeExpand) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeBackupRestore) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1197,13 +1609,13 @@ func (in *LocalVolumeExpand) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeExpandList) DeepCopyInto(out *LocalVolumeExpandList) {
+func (in *LocalVolumeBackupRestoreList) DeepCopyInto(out *LocalVolumeBackupRestoreList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]LocalVolumeExpand, len(*in))
+		*out = make([]LocalVolumeBackupRestore, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1211,18 +1623,18 @@ func (in *LocalVolumeExpandList) DeepCopyInto(out *LocalVolumeExpandList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeExpandList.
-func (in *LocalVolumeExpandList) DeepCopy() *LocalVolumeExpandList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeBackupRestoreList.
+func (in *LocalVolumeBackupRestoreList) DeepCopy() *LocalVolumeBackupRestoreList {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeExpandList)
+	out := new(LocalVolumeBackupRestoreList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeExpandList) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeBackupRestoreList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1230,64 +1642,104 @@ func (in *LocalVolumeExpandList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeExpandSpec) DeepCopyInto(out *LocalVolumeExpandSpec) {
+func (in *LocalVolumeBackupRestoreSpec) DeepCopyInto(out *LocalVolumeBackupRestoreSpec) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeExpandSpec.
-func (in *LocalVolumeExpandSpec) DeepCopy() *LocalVolumeExpandSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeBackupRestoreSpec.
+func (in *LocalVolumeBackupRestoreSpec) DeepCopy() *LocalVolumeBackupRestoreSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeExpandSpec)
+	out := new(LocalVolumeBackupRestoreSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeExpandStatus) DeepCopyInto(out *LocalVolumeExpandStatus) {
+func (in *LocalVolumeBackupRestoreStatus) DeepCopyInto(out *LocalVolumeBackupRestoreStatus) {
 	*out = *in
-	if in.Subs != nil {
-		in, out := &in.Subs, &out.Subs
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeExpandStatus.
-func (in *LocalVolumeExpandStatus) DeepCopy() *LocalVolumeExpandStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeBackupRestoreStatus.
+func (in *LocalVolumeBackupRestoreStatus) DeepCopy() *LocalVolumeBackupRestoreStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeExpandStatus)
+	out := new(LocalVolumeBackupRestoreStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeGroup) DeepCopyInto(out *LocalVolumeGroup) {
+func (in *LocalVolumeBackupSpec) DeepCopyInto(out *LocalVolumeBackupSpec) {
+	*out = *in
+	out.Target = in.Target
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeBackupSpec.
+func (in *LocalVolumeBackupSpec) DeepCopy() *LocalVolumeBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeBackupStatus) DeepCopyInto(out *LocalVolumeBackupStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeBackupStatus.
+func (in *LocalVolumeBackupStatus) DeepCopy() *LocalVolumeBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeConvert) DeepCopyInto(out *LocalVolumeConvert) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeGroup.
-func (in *LocalVolumeGroup) DeepCopy() *LocalVolumeGroup {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeConvert.
+func (in *LocalVolumeConvert) DeepCopy() *LocalVolumeConvert {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeGroup)
+	out := new(LocalVolumeConvert)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeGroup) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeConvert) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1295,13 +1747,13 @@ func (in *LocalVolumeGroup) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeGroupList) DeepCopyInto(out *LocalVolumeGroupList) {
+func (in *LocalVolumeConvertList) DeepCopyInto(out *LocalVolumeConvertList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]LocalVolumeGroup, len(*in))
+		*out = make([]LocalVolumeConvert, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1309,18 +1761,18 @@ func (in *LocalVolumeGroupList) DeepCopyInto(out *LocalVolumeGroupList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeGroupList.
-func (in *LocalVolumeGroupList) DeepCopy() *LocalVolumeGroupList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeConvertList.
+func (in *LocalVolumeConvertList) DeepCopy() *LocalVolumeConvertList {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeGroupList)
+	out := new(LocalVolumeConvertList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeGroupList) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeConvertList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1328,56 +1780,78 @@ func (in *LocalVolumeGroupList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeGroupSpec) DeepCopyInto(out *LocalVolumeGroupSpec) {
+func (in *LocalVolumeConvertSpec) DeepCopyInto(out *LocalVolumeConvertSpec) {
 	*out = *in
-	if in.Volumes != nil {
-		in, out := &in.Volumes, &out.Volumes
-		*out = make([]VolumeInfo, len(*in))
-		copy(*out, *in)
-	}
-	in.Accessibility.DeepCopyInto(&out.Accessibility)
-	if in.Pods != nil {
-		in, out := &in.Pods, &out.Pods
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeGroupSpec.
-func (in *LocalVolumeGroupSpec) DeepCopy() *LocalVolumeGroupSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeConvertSpec.
+func (in *LocalVolumeConvertSpec) DeepCopy() *LocalVolumeConvertSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeGroupSpec)
+	out := new(LocalVolumeConvertSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeGroupStatus) DeepCopyInto(out *LocalVolumeGroupStatus) {
+func (in *LocalVolumeConvertStatus) DeepCopyInto(out *LocalVolumeConvertStatus) {
 	*out = *in
+	if in.KeptNodes != nil {
+		in, out := &in.KeptNodes, &out.KeptNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeGroupStatus.
-func (in *LocalVolumeGroupStatus) DeepCopy() *LocalVolumeGroupStatus {
-	if in == nil {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeConvertStatus.
+func (in *LocalVolumeConvertStatus) DeepCopy() *LocalVolumeConvertStatus {
+	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeGroupStatus)
+	out := new(LocalVolumeConvertStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeList) DeepCopyInto(out *LocalVolumeList) {
+func (in *LocalVolumeEncrypt) DeepCopyInto(out *LocalVolumeEncrypt) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeEncrypt.
+func (in *LocalVolumeEncrypt) DeepCopy() *LocalVolumeEncrypt {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeEncrypt)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeEncrypt) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeEncryptList) DeepCopyInto(out *LocalVolumeEncryptList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]LocalVolume, len(*in))
+		*out = make([]LocalVolumeEncrypt, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1385,18 +1859,18 @@ func (in *LocalVolumeList) DeepCopyInto(out *LocalVolumeList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeList.
-func (in *LocalVolumeList) DeepCopy() *LocalVolumeList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeEncryptList.
+func (in *LocalVolumeEncryptList) DeepCopy() *LocalVolumeEncryptList {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeList)
+	out := new(LocalVolumeEncryptList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeList) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeEncryptList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1404,27 +1878,77 @@ func (in *LocalVolumeList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeMigrate) DeepCopyInto(out *LocalVolumeMigrate) {
+func (in *LocalVolumeEncryptSpec) DeepCopyInto(out *LocalVolumeEncryptSpec) {
+	*out = *in
+	if in.KMS != nil {
+		in, out := &in.KMS, &out.KMS
+		*out = new(VolumeEncryptKMS)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeEncryptSpec.
+func (in *LocalVolumeEncryptSpec) DeepCopy() *LocalVolumeEncryptSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeEncryptSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeEncryptStatus) DeepCopyInto(out *LocalVolumeEncryptStatus) {
+	*out = *in
+	if in.VolumeEncrypt != nil {
+		in, out := &in.VolumeEncrypt, &out.VolumeEncrypt
+		*out = new(VolumeEncrypt)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeEncryptStatus.
+func (in *LocalVolumeEncryptStatus) DeepCopy() *LocalVolumeEncryptStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeEncryptStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeExpand) DeepCopyInto(out *LocalVolumeExpand) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	out.Spec = in.Spec
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeMigrate.
-func (in *LocalVolumeMigrate) DeepCopy() *LocalVolumeMigrate {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeExpand.
+func (in *LocalVolumeExpand) DeepCopy() *LocalVolumeExpand {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeMigrate)
+	out := new(LocalVolumeExpand)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeMigrate) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeExpand) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1432,13 +1956,13 @@ func (in *LocalVolumeMigrate) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeMigrateList) DeepCopyInto(out *LocalVolumeMigrateList) {
+func (in *LocalVolumeExpandList) DeepCopyInto(out *LocalVolumeExpandList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]LocalVolumeMigrate, len(*in))
+		*out = make([]LocalVolumeExpand, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1446,18 +1970,18 @@ func (in *LocalVolumeMigrateList) DeepCopyInto(out *LocalVolumeMigrateList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeMigrateList.
-func (in *LocalVolumeMigrateList) DeepCopy() *LocalVolumeMigrateList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeExpandList.
+func (in *LocalVolumeExpandList) DeepCopy() *LocalVolumeExpandList {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeMigrateList)
+	out := new(LocalVolumeExpandList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeMigrateList) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeExpandList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1465,69 +1989,64 @@ func (in *LocalVolumeMigrateList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeMigrateSpec) DeepCopyInto(out *LocalVolumeMigrateSpec) {
+func (in *LocalVolumeExpandSpec) DeepCopyInto(out *LocalVolumeExpandSpec) {
 	*out = *in
-	if in.TargetNodesSuggested != nil {
-		in, out := &in.TargetNodesSuggested, &out.TargetNodesSuggested
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeMigrateSpec.
-func (in *LocalVolumeMigrateSpec) DeepCopy() *LocalVolumeMigrateSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeExpandSpec.
+func (in *LocalVolumeExpandSpec) DeepCopy() *LocalVolumeExpandSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeMigrateSpec)
+	out := new(LocalVolumeExpandSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeMigrateStatus) DeepCopyInto(out *LocalVolumeMigrateStatus) {
+func (in *LocalVolumeExpandStatus) DeepCopyInto(out *LocalVolumeExpandStatus) {
 	*out = *in
-	if in.Volumes != nil {
-		in, out := &in.Volumes, &out.Volumes
+	if in.Subs != nil {
+		in, out := &in.Subs, &out.Subs
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeMigrateStatus.
-func (in *LocalVolumeMigrateStatus) DeepCopy() *LocalVolumeMigrateStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeExpandStatus.
+func (in *LocalVolumeExpandStatus) DeepCopy() *LocalVolumeExpandStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeMigrateStatus)
+	out := new(LocalVolumeExpandStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeReplica) DeepCopyInto(out *LocalVolumeReplica) {
+func (in *LocalVolumeGroup) DeepCopyInto(out *LocalVolumeGroup) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	out.Status = in.Status
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplica.
-func (in *LocalVolumeReplica) DeepCopy() *LocalVolumeReplica {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeGroup.
+func (in *LocalVolumeGroup) DeepCopy() *LocalVolumeGroup {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeReplica)
+	out := new(LocalVolumeGroup)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeReplica) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeGroup) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1535,13 +2054,13 @@ func (in *LocalVolumeReplica) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeReplicaList) DeepCopyInto(out *LocalVolumeReplicaList) {
+func (in *LocalVolumeGroupList) DeepCopyInto(out *LocalVolumeGroupList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]LocalVolumeReplica, len(*in))
+		*out = make([]LocalVolumeGroup, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1549,18 +2068,18 @@ func (in *LocalVolumeReplicaList) DeepCopyInto(out *LocalVolumeReplicaList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaList.
-func (in *LocalVolumeReplicaList) DeepCopy() *LocalVolumeReplicaList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeGroupList.
+func (in *LocalVolumeGroupList) DeepCopy() *LocalVolumeGroupList {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeReplicaList)
+	out := new(LocalVolumeGroupList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeReplicaList) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeGroupList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1568,27 +2087,27 @@ func (in *LocalVolumeReplicaList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeReplicaSnapshot) DeepCopyInto(out *LocalVolumeReplicaSnapshot) {
+func (in *LocalVolumeGroupSnapshot) DeepCopyInto(out *LocalVolumeGroupSnapshot) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSnapshot.
-func (in *LocalVolumeReplicaSnapshot) DeepCopy() *LocalVolumeReplicaSnapshot {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeGroupSnapshot.
+func (in *LocalVolumeGroupSnapshot) DeepCopy() *LocalVolumeGroupSnapshot {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeReplicaSnapshot)
+	out := new(LocalVolumeGroupSnapshot)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeReplicaSnapshot) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeGroupSnapshot) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1596,13 +2115,13 @@ func (in *LocalVolumeReplicaSnapshot) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeReplicaSnapshotList) DeepCopyInto(out *LocalVolumeReplicaSnapshotList) {
+func (in *LocalVolumeGroupSnapshotList) DeepCopyInto(out *LocalVolumeGroupSnapshotList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]LocalVolumeReplicaSnapshot, len(*in))
+		*out = make([]LocalVolumeGroupSnapshot, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1610,18 +2129,18 @@ func (in *LocalVolumeReplicaSnapshotList) DeepCopyInto(out *LocalVolumeReplicaSn
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSnapshotList.
-func (in *LocalVolumeReplicaSnapshotList) DeepCopy() *LocalVolumeReplicaSnapshotList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeGroupSnapshotList.
+func (in *LocalVolumeGroupSnapshotList) DeepCopy() *LocalVolumeGroupSnapshotList {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeReplicaSnapshotList)
+	out := new(LocalVolumeGroupSnapshotList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeReplicaSnapshotList) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeGroupSnapshotList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1629,27 +2148,27 @@ func (in *LocalVolumeReplicaSnapshotList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeReplicaSnapshotRestore) DeepCopyInto(out *LocalVolumeReplicaSnapshotRestore) {
+func (in *LocalVolumeGroupSnapshotRestore) DeepCopyInto(out *LocalVolumeGroupSnapshotRestore) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSnapshotRestore.
-func (in *LocalVolumeReplicaSnapshotRestore) DeepCopy() *LocalVolumeReplicaSnapshotRestore {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeGroupSnapshotRestore.
+func (in *LocalVolumeGroupSnapshotRestore) DeepCopy() *LocalVolumeGroupSnapshotRestore {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeReplicaSnapshotRestore)
+	out := new(LocalVolumeGroupSnapshotRestore)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeReplicaSnapshotRestore) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeGroupSnapshotRestore) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1657,13 +2176,13 @@ func (in *LocalVolumeReplicaSnapshotRestore) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeReplicaSnapshotRestoreList) DeepCopyInto(out *LocalVolumeReplicaSnapshotRestoreList) {
+func (in *LocalVolumeGroupSnapshotRestoreList) DeepCopyInto(out *LocalVolumeGroupSnapshotRestoreList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]LocalVolumeReplicaSnapshotRestore, len(*in))
+		*out = make([]LocalVolumeGroupSnapshotRestore, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1671,18 +2190,18 @@ func (in *LocalVolumeReplicaSnapshotRestoreList) DeepCopyInto(out *LocalVolumeRe
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSnapshotRestoreList.
-func (in *LocalVolumeReplicaSnapshotRestoreList) DeepCopy() *LocalVolumeReplicaSnapshotRestoreList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeGroupSnapshotRestoreList.
+func (in *LocalVolumeGroupSnapshotRestoreList) DeepCopy() *LocalVolumeGroupSnapshotRestoreList {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeReplicaSnapshotRestoreList)
+	out := new(LocalVolumeGroupSnapshotRestoreList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeReplicaSnapshotRestoreList) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeGroupSnapshotRestoreList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1690,159 +2209,163 @@ func (in *LocalVolumeReplicaSnapshotRestoreList) DeepCopyObject() runtime.Object
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeReplicaSnapshotRestoreSpec) DeepCopyInto(out *LocalVolumeReplicaSnapshotRestoreSpec) {
+func (in *LocalVolumeGroupSnapshotRestoreSpec) DeepCopyInto(out *LocalVolumeGroupSnapshotRestoreSpec) {
 	*out = *in
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSnapshotRestoreSpec.
-func (in *LocalVolumeReplicaSnapshotRestoreSpec) DeepCopy() *LocalVolumeReplicaSnapshotRestoreSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeGroupSnapshotRestoreSpec.
+func (in *LocalVolumeGroupSnapshotRestoreSpec) DeepCopy() *LocalVolumeGroupSnapshotRestoreSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeReplicaSnapshotRestoreSpec)
+	out := new(LocalVolumeGroupSnapshotRestoreSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeReplicaSnapshotRestoreStatus) DeepCopyInto(out *LocalVolumeReplicaSnapshotRestoreStatus) {
+func (in *LocalVolumeGroupSnapshotRestoreStatus) DeepCopyInto(out *LocalVolumeGroupSnapshotRestoreStatus) {
 	*out = *in
+	if in.VolumeSnapshotRestores != nil {
+		in, out := &in.VolumeSnapshotRestores, &out.VolumeSnapshotRestores
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TargetVolumes != nil {
+		in, out := &in.TargetVolumes, &out.TargetVolumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSnapshotRestoreStatus.
-func (in *LocalVolumeReplicaSnapshotRestoreStatus) DeepCopy() *LocalVolumeReplicaSnapshotRestoreStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeGroupSnapshotRestoreStatus.
+func (in *LocalVolumeGroupSnapshotRestoreStatus) DeepCopy() *LocalVolumeGroupSnapshotRestoreStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeReplicaSnapshotRestoreStatus)
+	out := new(LocalVolumeGroupSnapshotRestoreStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeReplicaSnapshotSpec) DeepCopyInto(out *LocalVolumeReplicaSnapshotSpec) {
+func (in *LocalVolumeGroupSnapshotSpec) DeepCopyInto(out *LocalVolumeGroupSnapshotSpec) {
 	*out = *in
+	if in.Quiesce != nil {
+		in, out := &in.Quiesce, &out.Quiesce
+		*out = new(SnapshotQuiesce)
+		**out = **in
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSnapshotSpec.
-func (in *LocalVolumeReplicaSnapshotSpec) DeepCopy() *LocalVolumeReplicaSnapshotSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeGroupSnapshotSpec.
+func (in *LocalVolumeGroupSnapshotSpec) DeepCopy() *LocalVolumeGroupSnapshotSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeReplicaSnapshotSpec)
+	out := new(LocalVolumeGroupSnapshotSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeReplicaSnapshotStatus) DeepCopyInto(out *LocalVolumeReplicaSnapshotStatus) {
+func (in *LocalVolumeGroupSnapshotStatus) DeepCopyInto(out *LocalVolumeGroupSnapshotStatus) {
 	*out = *in
+	if in.VolumeSnapshots != nil {
+		in, out := &in.VolumeSnapshots, &out.VolumeSnapshots
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.CreationTime != nil {
 		in, out := &in.CreationTime, &out.CreationTime
 		*out = (*in).DeepCopy()
 	}
-	out.Attribute = in.Attribute
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSnapshotStatus.
-func (in *LocalVolumeReplicaSnapshotStatus) DeepCopy() *LocalVolumeReplicaSnapshotStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeGroupSnapshotStatus.
+func (in *LocalVolumeGroupSnapshotStatus) DeepCopy() *LocalVolumeGroupSnapshotStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeReplicaSnapshotStatus)
+	out := new(LocalVolumeGroupSnapshotStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeReplicaSpec) DeepCopyInto(out *LocalVolumeReplicaSpec) {
+func (in *LocalVolumeGroupSpec) DeepCopyInto(out *LocalVolumeGroupSpec) {
 	*out = *in
-	out.VolumeQoS = in.VolumeQoS
-	out.VolumeEncrypt = in.VolumeEncrypt
-	if in.ThinOriginVolume != nil {
-		in, out := &in.ThinOriginVolume, &out.ThinOriginVolume
-		*out = new(string)
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]VolumeInfo, len(*in))
+		copy(*out, *in)
+	}
+	in.Accessibility.DeepCopyInto(&out.Accessibility)
+	if in.Pods != nil {
+		in, out := &in.Pods, &out.Pods
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VolumeQoS != nil {
+		in, out := &in.VolumeQoS, &out.VolumeQoS
+		*out = new(VolumeQoS)
 		**out = **in
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSpec.
-func (in *LocalVolumeReplicaSpec) DeepCopy() *LocalVolumeReplicaSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeGroupSpec.
+func (in *LocalVolumeGroupSpec) DeepCopy() *LocalVolumeGroupSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeReplicaSpec)
+	out := new(LocalVolumeGroupSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeReplicaStatus) DeepCopyInto(out *LocalVolumeReplicaStatus) {
+func (in *LocalVolumeGroupStatus) DeepCopyInto(out *LocalVolumeGroupStatus) {
 	*out = *in
-	if in.Disks != nil {
-		in, out := &in.Disks, &out.Disks
-		*out = make([]string, len(*in))
-		copy(*out, *in)
-	}
-	if in.HAState != nil {
-		in, out := &in.HAState, &out.HAState
-		*out = new(HAState)
-		**out = **in
-	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]metav1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaStatus.
-func (in *LocalVolumeReplicaStatus) DeepCopy() *LocalVolumeReplicaStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeGroupStatus.
+func (in *LocalVolumeGroupStatus) DeepCopy() *LocalVolumeGroupStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeReplicaStatus)
+	out := new(LocalVolumeGroupStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeSnapshot) DeepCopyInto(out *LocalVolumeSnapshot) {
+func (in *LocalVolumeKeyRotate) DeepCopyInto(out *LocalVolumeKeyRotate) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
+	out.Spec = in.Spec
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSnapshot.
-func (in *LocalVolumeSnapshot) DeepCopy() *LocalVolumeSnapshot {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeKeyRotate.
+func (in *LocalVolumeKeyRotate) DeepCopy() *LocalVolumeKeyRotate {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeSnapshot)
+	out := new(LocalVolumeKeyRotate)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeSnapshot) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeKeyRotate) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1850,7 +2373,912 @@ func (in *LocalVolumeSnapshot) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeSnapshotList) DeepCopyInto(out *LocalVolumeSnapshotList) {
+func (in *LocalVolumeKeyRotateList) DeepCopyInto(out *LocalVolumeKeyRotateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LocalVolumeKeyRotate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeKeyRotateList.
+func (in *LocalVolumeKeyRotateList) DeepCopy() *LocalVolumeKeyRotateList {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeKeyRotateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeKeyRotateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeKeyRotateSpec) DeepCopyInto(out *LocalVolumeKeyRotateSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeKeyRotateSpec.
+func (in *LocalVolumeKeyRotateSpec) DeepCopy() *LocalVolumeKeyRotateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeKeyRotateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeKeyRotateStatus) DeepCopyInto(out *LocalVolumeKeyRotateStatus) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make([]VolumeKeyRotateReplicaStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeKeyRotateStatus.
+func (in *LocalVolumeKeyRotateStatus) DeepCopy() *LocalVolumeKeyRotateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeKeyRotateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeList) DeepCopyInto(out *LocalVolumeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LocalVolume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeList.
+func (in *LocalVolumeList) DeepCopy() *LocalVolumeList {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeMigrate) DeepCopyInto(out *LocalVolumeMigrate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeMigrate.
+func (in *LocalVolumeMigrate) DeepCopy() *LocalVolumeMigrate {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeMigrate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeMigrate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeMigrateList) DeepCopyInto(out *LocalVolumeMigrateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LocalVolumeMigrate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeMigrateList.
+func (in *LocalVolumeMigrateList) DeepCopy() *LocalVolumeMigrateList {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeMigrateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeMigrateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeMigrateSpec) DeepCopyInto(out *LocalVolumeMigrateSpec) {
+	*out = *in
+	if in.TargetNodesSuggested != nil {
+		in, out := &in.TargetNodesSuggested, &out.TargetNodesSuggested
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TimeWindows != nil {
+		in, out := &in.TimeWindows, &out.TimeWindows
+		*out = make([]MigrateTimeWindow, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeMigrateSpec.
+func (in *LocalVolumeMigrateSpec) DeepCopy() *LocalVolumeMigrateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeMigrateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeMigrateStatus) DeepCopyInto(out *LocalVolumeMigrateStatus) {
+	*out = *in
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Progress != nil {
+		in, out := &in.Progress, &out.Progress
+		*out = new(MigrateProgress)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeMigrateStatus.
+func (in *LocalVolumeMigrateStatus) DeepCopy() *LocalVolumeMigrateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeMigrateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeReplica) DeepCopyInto(out *LocalVolumeReplica) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplica.
+func (in *LocalVolumeReplica) DeepCopy() *LocalVolumeReplica {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeReplica)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeReplica) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeReplicaList) DeepCopyInto(out *LocalVolumeReplicaList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LocalVolumeReplica, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaList.
+func (in *LocalVolumeReplicaList) DeepCopy() *LocalVolumeReplicaList {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeReplicaList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeReplicaList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeReplicaSnapshot) DeepCopyInto(out *LocalVolumeReplicaSnapshot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSnapshot.
+func (in *LocalVolumeReplicaSnapshot) DeepCopy() *LocalVolumeReplicaSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeReplicaSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeReplicaSnapshot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeReplicaSnapshotList) DeepCopyInto(out *LocalVolumeReplicaSnapshotList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LocalVolumeReplicaSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSnapshotList.
+func (in *LocalVolumeReplicaSnapshotList) DeepCopy() *LocalVolumeReplicaSnapshotList {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeReplicaSnapshotList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeReplicaSnapshotList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeReplicaSnapshotRestore) DeepCopyInto(out *LocalVolumeReplicaSnapshotRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	out.Status = in.Status
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSnapshotRestore.
+func (in *LocalVolumeReplicaSnapshotRestore) DeepCopy() *LocalVolumeReplicaSnapshotRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeReplicaSnapshotRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeReplicaSnapshotRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeReplicaSnapshotRestoreList) DeepCopyInto(out *LocalVolumeReplicaSnapshotRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LocalVolumeReplicaSnapshotRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSnapshotRestoreList.
+func (in *LocalVolumeReplicaSnapshotRestoreList) DeepCopy() *LocalVolumeReplicaSnapshotRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeReplicaSnapshotRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeReplicaSnapshotRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeReplicaSnapshotRestoreSpec) DeepCopyInto(out *LocalVolumeReplicaSnapshotRestoreSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSnapshotRestoreSpec.
+func (in *LocalVolumeReplicaSnapshotRestoreSpec) DeepCopy() *LocalVolumeReplicaSnapshotRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeReplicaSnapshotRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeReplicaSnapshotRestoreStatus) DeepCopyInto(out *LocalVolumeReplicaSnapshotRestoreStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSnapshotRestoreStatus.
+func (in *LocalVolumeReplicaSnapshotRestoreStatus) DeepCopy() *LocalVolumeReplicaSnapshotRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeReplicaSnapshotRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeReplicaSnapshotSpec) DeepCopyInto(out *LocalVolumeReplicaSnapshotSpec) {
+	*out = *in
+	if in.Quiesce != nil {
+		in, out := &in.Quiesce, &out.Quiesce
+		*out = new(SnapshotQuiesce)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSnapshotSpec.
+func (in *LocalVolumeReplicaSnapshotSpec) DeepCopy() *LocalVolumeReplicaSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeReplicaSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeReplicaSnapshotStatus) DeepCopyInto(out *LocalVolumeReplicaSnapshotStatus) {
+	*out = *in
+	if in.CreationTime != nil {
+		in, out := &in.CreationTime, &out.CreationTime
+		*out = (*in).DeepCopy()
+	}
+	out.Attribute = in.Attribute
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSnapshotStatus.
+func (in *LocalVolumeReplicaSnapshotStatus) DeepCopy() *LocalVolumeReplicaSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeReplicaSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeReplicaSpec) DeepCopyInto(out *LocalVolumeReplicaSpec) {
+	*out = *in
+	out.VolumeQoS = in.VolumeQoS
+	in.VolumeEncrypt.DeepCopyInto(&out.VolumeEncrypt)
+	if in.ThinOriginVolume != nil {
+		in, out := &in.ThinOriginVolume, &out.ThinOriginVolume
+		*out = new(string)
+		**out = **in
+	}
+	if in.VolumeCache != nil {
+		in, out := &in.VolumeCache, &out.VolumeCache
+		*out = new(VolumeCache)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaSpec.
+func (in *LocalVolumeReplicaSpec) DeepCopy() *LocalVolumeReplicaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeReplicaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeReplicaStatus) DeepCopyInto(out *LocalVolumeReplicaStatus) {
+	*out = *in
+	if in.Disks != nil {
+		in, out := &in.Disks, &out.Disks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.VolumeCache != nil {
+		in, out := &in.VolumeCache, &out.VolumeCache
+		*out = new(VolumeCacheStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HAState != nil {
+		in, out := &in.HAState, &out.HAState
+		*out = new(HAState)
+		**out = **in
+	}
+	if in.VolumeQoS != nil {
+		in, out := &in.VolumeQoS, &out.VolumeQoS
+		*out = new(VolumeQoSStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeReplicaStatus.
+func (in *LocalVolumeReplicaStatus) DeepCopy() *LocalVolumeReplicaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeReplicaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeScrub) DeepCopyInto(out *LocalVolumeScrub) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeScrub.
+func (in *LocalVolumeScrub) DeepCopy() *LocalVolumeScrub {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeScrub)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeScrub) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeScrubList) DeepCopyInto(out *LocalVolumeScrubList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LocalVolumeScrub, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeScrubList.
+func (in *LocalVolumeScrubList) DeepCopy() *LocalVolumeScrubList {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeScrubList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeScrubList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeScrubSchedule) DeepCopyInto(out *LocalVolumeScrubSchedule) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeScrubSchedule.
+func (in *LocalVolumeScrubSchedule) DeepCopy() *LocalVolumeScrubSchedule {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeScrubSchedule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeScrubSchedule) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeScrubScheduleList) DeepCopyInto(out *LocalVolumeScrubScheduleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LocalVolumeScrubSchedule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeScrubScheduleList.
+func (in *LocalVolumeScrubScheduleList) DeepCopy() *LocalVolumeScrubScheduleList {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeScrubScheduleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeScrubScheduleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeScrubScheduleSpec) DeepCopyInto(out *LocalVolumeScrubScheduleSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeScrubScheduleSpec.
+func (in *LocalVolumeScrubScheduleSpec) DeepCopy() *LocalVolumeScrubScheduleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeScrubScheduleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeScrubScheduleStatus) DeepCopyInto(out *LocalVolumeScrubScheduleStatus) {
+	*out = *in
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeScrubScheduleStatus.
+func (in *LocalVolumeScrubScheduleStatus) DeepCopy() *LocalVolumeScrubScheduleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeScrubScheduleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeScrubSpec) DeepCopyInto(out *LocalVolumeScrubSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeScrubSpec.
+func (in *LocalVolumeScrubSpec) DeepCopy() *LocalVolumeScrubSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeScrubSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeScrubStatus) DeepCopyInto(out *LocalVolumeScrubStatus) {
+	*out = *in
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]VolumeScrubResult, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeScrubStatus.
+func (in *LocalVolumeScrubStatus) DeepCopy() *LocalVolumeScrubStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeScrubStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeShrink) DeepCopyInto(out *LocalVolumeShrink) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeShrink.
+func (in *LocalVolumeShrink) DeepCopy() *LocalVolumeShrink {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeShrink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeShrink) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeShrinkList) DeepCopyInto(out *LocalVolumeShrinkList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LocalVolumeShrink, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeShrinkList.
+func (in *LocalVolumeShrinkList) DeepCopy() *LocalVolumeShrinkList {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeShrinkList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeShrinkList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeShrinkSpec) DeepCopyInto(out *LocalVolumeShrinkSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeShrinkSpec.
+func (in *LocalVolumeShrinkSpec) DeepCopy() *LocalVolumeShrinkSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeShrinkSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeShrinkStatus) DeepCopyInto(out *LocalVolumeShrinkStatus) {
+	*out = *in
+	if in.Subs != nil {
+		in, out := &in.Subs, &out.Subs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeShrinkStatus.
+func (in *LocalVolumeShrinkStatus) DeepCopy() *LocalVolumeShrinkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeShrinkStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeSnapshot) DeepCopyInto(out *LocalVolumeSnapshot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSnapshot.
+func (in *LocalVolumeSnapshot) DeepCopy() *LocalVolumeSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeSnapshot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeSnapshotList) DeepCopyInto(out *LocalVolumeSnapshotList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
@@ -1864,46 +3292,144 @@ func (in *LocalVolumeSnapshotList) DeepCopyInto(out *LocalVolumeSnapshotList) {
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSnapshotList.
-func (in *LocalVolumeSnapshotList) DeepCopy() *LocalVolumeSnapshotList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSnapshotList.
+func (in *LocalVolumeSnapshotList) DeepCopy() *LocalVolumeSnapshotList {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeSnapshotList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeSnapshotList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeSnapshotRestore) DeepCopyInto(out *LocalVolumeSnapshotRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSnapshotRestore.
+func (in *LocalVolumeSnapshotRestore) DeepCopy() *LocalVolumeSnapshotRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeSnapshotRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeSnapshotRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeSnapshotRestoreList) DeepCopyInto(out *LocalVolumeSnapshotRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]LocalVolumeSnapshotRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSnapshotRestoreList.
+func (in *LocalVolumeSnapshotRestoreList) DeepCopy() *LocalVolumeSnapshotRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeSnapshotRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *LocalVolumeSnapshotRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeSnapshotRestoreSpec) DeepCopyInto(out *LocalVolumeSnapshotRestoreSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSnapshotRestoreSpec.
+func (in *LocalVolumeSnapshotRestoreSpec) DeepCopy() *LocalVolumeSnapshotRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LocalVolumeSnapshotRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LocalVolumeSnapshotRestoreStatus) DeepCopyInto(out *LocalVolumeSnapshotRestoreStatus) {
+	*out = *in
+	if in.VolumeReplicaSnapshotRestore != nil {
+		in, out := &in.VolumeReplicaSnapshotRestore, &out.VolumeReplicaSnapshotRestore
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSnapshotRestoreStatus.
+func (in *LocalVolumeSnapshotRestoreStatus) DeepCopy() *LocalVolumeSnapshotRestoreStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeSnapshotList)
+	out := new(LocalVolumeSnapshotRestoreStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeSnapshotList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeSnapshotRestore) DeepCopyInto(out *LocalVolumeSnapshotRestore) {
+func (in *LocalVolumeSnapshotSchedule) DeepCopyInto(out *LocalVolumeSnapshotSchedule) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSnapshotRestore.
-func (in *LocalVolumeSnapshotRestore) DeepCopy() *LocalVolumeSnapshotRestore {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSnapshotSchedule.
+func (in *LocalVolumeSnapshotSchedule) DeepCopy() *LocalVolumeSnapshotSchedule {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeSnapshotRestore)
+	out := new(LocalVolumeSnapshotSchedule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeSnapshotRestore) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeSnapshotSchedule) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1911,13 +3437,13 @@ func (in *LocalVolumeSnapshotRestore) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeSnapshotRestoreList) DeepCopyInto(out *LocalVolumeSnapshotRestoreList) {
+func (in *LocalVolumeSnapshotScheduleList) DeepCopyInto(out *LocalVolumeSnapshotScheduleList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]LocalVolumeSnapshotRestore, len(*in))
+		*out = make([]LocalVolumeSnapshotSchedule, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
@@ -1925,18 +3451,18 @@ func (in *LocalVolumeSnapshotRestoreList) DeepCopyInto(out *LocalVolumeSnapshotR
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSnapshotRestoreList.
-func (in *LocalVolumeSnapshotRestoreList) DeepCopy() *LocalVolumeSnapshotRestoreList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSnapshotScheduleList.
+func (in *LocalVolumeSnapshotScheduleList) DeepCopy() *LocalVolumeSnapshotScheduleList {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeSnapshotRestoreList)
+	out := new(LocalVolumeSnapshotScheduleList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *LocalVolumeSnapshotRestoreList) DeepCopyObject() runtime.Object {
+func (in *LocalVolumeSnapshotScheduleList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -1944,38 +3470,71 @@ func (in *LocalVolumeSnapshotRestoreList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeSnapshotRestoreSpec) DeepCopyInto(out *LocalVolumeSnapshotRestoreSpec) {
+func (in *LocalVolumeSnapshotScheduleSpec) DeepCopyInto(out *LocalVolumeSnapshotScheduleSpec) {
 	*out = *in
+	if in.StorageClassSelector != nil {
+		in, out := &in.StorageClassSelector, &out.StorageClassSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PVCSelector != nil {
+		in, out := &in.PVCSelector, &out.PVCSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Retention = in.Retention
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSnapshotRestoreSpec.
-func (in *LocalVolumeSnapshotRestoreSpec) DeepCopy() *LocalVolumeSnapshotRestoreSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSnapshotScheduleSpec.
+func (in *LocalVolumeSnapshotScheduleSpec) DeepCopy() *LocalVolumeSnapshotScheduleSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeSnapshotRestoreSpec)
+	out := new(LocalVolumeSnapshotScheduleSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *LocalVolumeSnapshotRestoreStatus) DeepCopyInto(out *LocalVolumeSnapshotRestoreStatus) {
+func (in *LocalVolumeSnapshotScheduleStatus) DeepCopyInto(out *LocalVolumeSnapshotScheduleStatus) {
 	*out = *in
-	if in.VolumeReplicaSnapshotRestore != nil {
-		in, out := &in.VolumeReplicaSnapshotRestore, &out.VolumeReplicaSnapshotRestore
+	if in.LastScheduleTime != nil {
+		in, out := &in.LastScheduleTime, &out.LastScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastSuccessfulTime != nil {
+		in, out := &in.LastSuccessfulTime, &out.LastSuccessfulTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NextScheduleTime != nil {
+		in, out := &in.NextScheduleTime, &out.NextScheduleTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Volumes != nil {
+		in, out := &in.Volumes, &out.Volumes
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Failures != nil {
+		in, out := &in.Failures, &out.Failures
+		*out = make([]SnapshotScheduleFailure, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSnapshotRestoreStatus.
-func (in *LocalVolumeSnapshotRestoreStatus) DeepCopy() *LocalVolumeSnapshotRestoreStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LocalVolumeSnapshotScheduleStatus.
+func (in *LocalVolumeSnapshotScheduleStatus) DeepCopy() *LocalVolumeSnapshotScheduleStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(LocalVolumeSnapshotRestoreStatus)
+	out := new(LocalVolumeSnapshotScheduleStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -1984,6 +3543,11 @@ func (in *LocalVolumeSnapshotRestoreStatus) DeepCopy() *LocalVolumeSnapshotResto
 func (in *LocalVolumeSnapshotSpec) DeepCopyInto(out *LocalVolumeSnapshotSpec) {
 	*out = *in
 	in.Accessibility.DeepCopyInto(&out.Accessibility)
+	if in.Quiesce != nil {
+		in, out := &in.Quiesce, &out.Quiesce
+		*out = new(SnapshotQuiesce)
+		**out = **in
+	}
 	return
 }
 
@@ -2027,7 +3591,7 @@ func (in *LocalVolumeSnapshotStatus) DeepCopy() *LocalVolumeSnapshotStatus {
 func (in *LocalVolumeSpec) DeepCopyInto(out *LocalVolumeSpec) {
 	*out = *in
 	out.VolumeQoS = in.VolumeQoS
-	out.VolumeEncrypt = in.VolumeEncrypt
+	in.VolumeEncrypt.DeepCopyInto(&out.VolumeEncrypt)
 	in.Accessibility.DeepCopyInto(&out.Accessibility)
 	if in.Config != nil {
 		in, out := &in.Config, &out.Config
@@ -2039,6 +3603,11 @@ func (in *LocalVolumeSpec) DeepCopyInto(out *LocalVolumeSpec) {
 		*out = new(ThinOrigin)
 		**out = **in
 	}
+	if in.VolumeCache != nil {
+		in, out := &in.VolumeCache, &out.VolumeCache
+		*out = new(VolumeCache)
+		**out = **in
+	}
 	return
 }
 
@@ -2060,6 +3629,11 @@ func (in *LocalVolumeStatus) DeepCopyInto(out *LocalVolumeStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.VolumeQoS != nil {
+		in, out := &in.VolumeQoS, &out.VolumeQoS
+		*out = new(VolumeQoSStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -2073,6 +3647,46 @@ func (in *LocalVolumeStatus) DeepCopy() *LocalVolumeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrateProgress) DeepCopyInto(out *MigrateProgress) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.UpdateTime != nil {
+		in, out := &in.UpdateTime, &out.UpdateTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrateProgress.
+func (in *MigrateProgress) DeepCopy() *MigrateProgress {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrateProgress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrateTimeWindow) DeepCopyInto(out *MigrateTimeWindow) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrateTimeWindow.
+func (in *MigrateTimeWindow) DeepCopy() *MigrateTimeWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrateTimeWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MountPoint) DeepCopyInto(out *MountPoint) {
 	*out = *in
@@ -2136,6 +3750,11 @@ func (in *PartitionInfo) DeepCopy() *PartitionInfo {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RAIDInfo) DeepCopyInto(out *RAIDInfo) {
 	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]RAIDMember, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -2149,6 +3768,22 @@ func (in *RAIDInfo) DeepCopy() *RAIDInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RAIDMember) DeepCopyInto(out *RAIDMember) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RAIDMember.
+func (in *RAIDMember) DeepCopy() *RAIDMember {
+	if in == nil {
+		return nil
+	}
+	out := new(RAIDMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResizePolicy) DeepCopyInto(out *ResizePolicy) {
 	*out = *in
@@ -2273,6 +3908,54 @@ func (in *SmartInfo) DeepCopy() *SmartInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotQuiesce) DeepCopyInto(out *SnapshotQuiesce) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotQuiesce.
+func (in *SnapshotQuiesce) DeepCopy() *SnapshotQuiesce {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotQuiesce)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotRetention) DeepCopyInto(out *SnapshotRetention) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotRetention.
+func (in *SnapshotRetention) DeepCopy() *SnapshotRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotScheduleFailure) DeepCopyInto(out *SnapshotScheduleFailure) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotScheduleFailure.
+func (in *SnapshotScheduleFailure) DeepCopy() *SnapshotScheduleFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotScheduleFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageNodeCondition) DeepCopyInto(out *StorageNodeCondition) {
 	*out = *in
@@ -2299,6 +3982,18 @@ func (in *SystemConfig) DeepCopyInto(out *SystemConfig) {
 		*out = new(DRBDSystemConfig)
 		**out = **in
 	}
+	if in.StorageBackends != nil {
+		in, out := &in.StorageBackends, &out.StorageBackends
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ZFS != nil {
+		in, out := &in.ZFS, &out.ZFS
+		*out = new(ZFSSystemConfig)
+		**out = **in
+	}
 	return
 }
 
@@ -2328,6 +4023,22 @@ func (in *ThinOrigin) DeepCopy() *ThinOrigin {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ThinPoolAutoExtendPolicy) DeepCopyInto(out *ThinPoolAutoExtendPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ThinPoolAutoExtendPolicy.
+func (in *ThinPoolAutoExtendPolicy) DeepCopy() *ThinPoolAutoExtendPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ThinPoolAutoExtendPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ThinPoolClaim) DeepCopyInto(out *ThinPoolClaim) {
 	*out = *in
@@ -2369,6 +4080,11 @@ func (in *ThinPoolClaimDescription) DeepCopyInto(out *ThinPoolClaimDescription)
 		*out = new(uint)
 		**out = **in
 	}
+	if in.AutoExtend != nil {
+		in, out := &in.AutoExtend, &out.AutoExtend
+		*out = new(ThinPoolAutoExtendPolicy)
+		**out = **in
+	}
 	return
 }
 
@@ -2525,6 +4241,42 @@ func (in *Topology) DeepCopy() *Topology {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeCache) DeepCopyInto(out *VolumeCache) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeCache.
+func (in *VolumeCache) DeepCopy() *VolumeCache {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeCacheStatus) DeepCopyInto(out *VolumeCacheStatus) {
+	*out = *in
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeCacheStatus.
+func (in *VolumeCacheStatus) DeepCopy() *VolumeCacheStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeCacheStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeCapability) DeepCopyInto(out *VolumeCapability) {
 	*out = *in
@@ -2565,6 +4317,11 @@ func (in *VolumeConfig) DeepCopy() *VolumeConfig {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeEncrypt) DeepCopyInto(out *VolumeEncrypt) {
 	*out = *in
+	if in.KMS != nil {
+		in, out := &in.KMS, &out.KMS
+		*out = new(VolumeEncryptKMS)
+		**out = **in
+	}
 	return
 }
 
@@ -2578,6 +4335,22 @@ func (in *VolumeEncrypt) DeepCopy() *VolumeEncrypt {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeEncryptKMS) DeepCopyInto(out *VolumeEncryptKMS) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeEncryptKMS.
+func (in *VolumeEncryptKMS) DeepCopy() *VolumeEncryptKMS {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeEncryptKMS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeInfo) DeepCopyInto(out *VolumeInfo) {
 	*out = *in
@@ -2594,6 +4367,22 @@ func (in *VolumeInfo) DeepCopy() *VolumeInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeKeyRotateReplicaStatus) DeepCopyInto(out *VolumeKeyRotateReplicaStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeKeyRotateReplicaStatus.
+func (in *VolumeKeyRotateReplicaStatus) DeepCopy() *VolumeKeyRotateReplicaStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeKeyRotateReplicaStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeQoS) DeepCopyInto(out *VolumeQoS) {
 	*out = *in
@@ -2610,6 +4399,26 @@ func (in *VolumeQoS) DeepCopy() *VolumeQoS {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeQoSStatus) DeepCopyInto(out *VolumeQoSStatus) {
+	*out = *in
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeQoSStatus.
+func (in *VolumeQoSStatus) DeepCopy() *VolumeQoSStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeQoSStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeReplica) DeepCopyInto(out *VolumeReplica) {
 	*out = *in
@@ -2626,6 +4435,22 @@ func (in *VolumeReplica) DeepCopy() *VolumeReplica {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VolumeScrubResult) DeepCopyInto(out *VolumeScrubResult) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VolumeScrubResult.
+func (in *VolumeScrubResult) DeepCopy() *VolumeScrubResult {
+	if in == nil {
+		return nil
+	}
+	out := new(VolumeScrubResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeSnapshotAttr) DeepCopyInto(out *VolumeSnapshotAttr) {
 	*out = *in
@@ -2641,3 +4466,19 @@ func (in *VolumeSnapshotAttr) DeepCopy() *VolumeSnapshotAttr {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ZFSSystemConfig) DeepCopyInto(out *ZFSSystemConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ZFSSystemConfig.
+func (in *ZFSSystemConfig) DeepCopy() *ZFSSystemConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ZFSSystemConfig)
+	in.DeepCopyInto(out)
+	return out
+}
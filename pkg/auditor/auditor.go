@@ -48,8 +48,13 @@ func (ad *auditor) Run(informersCache runtimecache.Cache, stopCh <-chan struct{}
 	newAuditorForLocalVolumeMigrate(eventStore).Run(lsFactory, stopCh)
 	newAuditorForLocalVolumeConvert(eventStore).Run(lsFactory, stopCh)
 	newAuditorForLocalVolumeExpand(eventStore).Run(lsFactory, stopCh)
+	newAuditorForLocalVolumeScrub(eventStore).Run(informersCache, stopCh)
+	newAuditorForLocalVolumeKeyRotate(eventStore).Run(informersCache, stopCh)
+	newAuditorForLocalVolumeEncrypt(eventStore).Run(informersCache, stopCh)
+	newAuditorForLocalVolumeShrink(eventStore).Run(informersCache, stopCh)
 
 	newAuditorForLocalDisk(eventStore).Run(lsFactory, stopCh)
+	newAuditorForLocalDiskDecommission(eventStore).Run(informersCache, stopCh)
 
 	<-stopCh
 	return nil
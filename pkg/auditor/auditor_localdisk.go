@@ -99,4 +99,50 @@ func (ad *auditorForLocalDisk) onUpdate(oldObj, newObj interface{}) {
 
 		ad.events.AddRecordForResource(ResourceTypeDisk, newInstance.Name, record)
 	}
+
+	ad.onHealthUpdate(oldInstance, newInstance)
+}
+
+func (ad *auditorForLocalDisk) onHealthUpdate(oldInstance, newInstance *localstorageapis.LocalDisk) {
+	oldHealth, newHealth := oldInstance.Status.Health, newInstance.Status.Health
+	if newHealth == nil {
+		return
+	}
+	if oldHealth == nil {
+		oldHealth = &localstorageapis.DiskHealthStatus{}
+	}
+
+	if newHealth.State != oldHealth.State {
+		record := &localstorageapis.EventRecord{
+			Time:          metav1.Time{Time: time.Now()},
+			Action:        ActionDiskHealthChange,
+			ActionContent: contentString(newHealth.Reasons),
+			State:         string(newHealth.State),
+		}
+
+		ad.events.AddRecordForResource(ResourceTypeDisk, newInstance.Name, record)
+	}
+
+	if newHealth.Cordoned != oldHealth.Cordoned {
+		record := &localstorageapis.EventRecord{
+			Time:          metav1.Time{Time: time.Now()},
+			Action:        ActionDiskCordon,
+			ActionContent: contentString(newHealth.Reasons),
+		}
+		if !newHealth.Cordoned {
+			record.Action = ActionDiskUncordon
+		}
+
+		ad.events.AddRecordForResource(ResourceTypeDisk, newInstance.Name, record)
+	}
+
+	if len(newHealth.Migrations) > 0 && len(oldHealth.Migrations) == 0 {
+		record := &localstorageapis.EventRecord{
+			Time:          metav1.Time{Time: time.Now()},
+			Action:        ActionDiskEvacuate,
+			ActionContent: contentString(newHealth.Migrations),
+		}
+
+		ad.events.AddRecordForResource(ResourceTypeDisk, newInstance.Name, record)
+	}
 }
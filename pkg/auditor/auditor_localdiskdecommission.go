@@ -0,0 +1,80 @@
+package auditor
+
+import (
+	"context"
+	"time"
+
+	localstorageapis "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	runtimecache "sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+type auditorForLocalDiskDecommission struct {
+	events *EventStore
+}
+
+func newAuditorForLocalDiskDecommission(events *EventStore) *auditorForLocalDiskDecommission {
+	return &auditorForLocalDiskDecommission{events: events}
+}
+
+func (ad *auditorForLocalDiskDecommission) Run(informersCache runtimecache.Cache, stopCh <-chan struct{}) {
+	informer, err := informersCache.GetInformer(context.TODO(), &localstorageapis.LocalDiskDecommission{})
+	if err != nil {
+		// error happens, crash the node
+		log.WithError(err).Fatal("Failed to get informer for LocalDiskDecommission")
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    ad.onAdd,
+		UpdateFunc: ad.onUpdate,
+	})
+}
+
+func (ad *auditorForLocalDiskDecommission) onAdd(obj interface{}) {
+	instance, _ := obj.(*localstorageapis.LocalDiskDecommission)
+
+	if len(instance.Status.State) != 0 {
+		return
+	}
+
+	record := &localstorageapis.EventRecord{
+		Time:   metav1.Time{Time: time.Now()},
+		ID:     instance.Name,
+		Action: ActionDiskDecommission,
+		State:  ActionStateSubmit,
+	}
+
+	ad.events.AddRecordForResource(ResourceTypeDisk, instance.Spec.DiskName, record)
+}
+
+func (ad *auditorForLocalDiskDecommission) onUpdate(oldObj, newObj interface{}) {
+	oldInstance, _ := oldObj.(*localstorageapis.LocalDiskDecommission)
+	instance, _ := newObj.(*localstorageapis.LocalDiskDecommission)
+
+	if oldInstance.Status.State == instance.Status.State {
+		return
+	}
+
+	record := &localstorageapis.EventRecord{
+		Time:   metav1.Time{Time: time.Now()},
+		ID:     instance.Name,
+		Action: ActionDiskDecommission,
+	}
+	switch instance.Status.State {
+	case localstorageapis.OperationStateDecommissionEvacuate, localstorageapis.OperationStateDecommissionMigrate:
+		record.State = ActionStateStart
+		record.StateContent = contentString(instance.Status)
+	case localstorageapis.OperationStateCompleted, localstorageapis.OperationStateFailed:
+		record.State = ActionStateComplete
+		record.StateContent = contentString(instance.Status)
+	case localstorageapis.OperationStateAborted:
+		record.State = ActionStateAbort
+		record.StateContent = contentString(instance.Status)
+	default:
+		return
+	}
+
+	ad.events.AddRecordForResource(ResourceTypeDisk, instance.Spec.DiskName, record)
+}
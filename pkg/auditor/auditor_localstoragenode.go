@@ -106,4 +106,29 @@ func (ad *auditorForLocalStorageNode) onUpdate(oldObj, newObj interface{}) {
 
 	}
 
+	for _, newCondition := range newInstance.Status.Conditions {
+		if newCondition.Type != localstorageapis.StorageThinPoolExtendSuccess && newCondition.Type != localstorageapis.StorageThinPoolExtendFailure {
+			continue
+		}
+		if oldCondition := findStorageNodeCondition(oldInstance.Status.Conditions, newCondition.Type); oldCondition != nil &&
+			oldCondition.Status == newCondition.Status && oldCondition.LastUpdateTime.Equal(&newCondition.LastUpdateTime) {
+			continue
+		}
+		// found an event for thin pool autoextend
+		record := &localstorageapis.EventRecord{
+			Time:          metav1.NewTime(time.Now()),
+			Action:        ActionNodeThinPoolExtend,
+			ActionContent: contentString(newCondition),
+		}
+		ad.events.AddRecordForResource(ResourceTypeStorageNode, newInstance.Name, record)
+	}
+}
+
+func findStorageNodeCondition(conditions []localstorageapis.StorageNodeCondition, conditionType localstorageapis.StorageNodeConditionType) *localstorageapis.StorageNodeCondition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
 }
@@ -0,0 +1,87 @@
+package auditor
+
+import (
+	"context"
+	"time"
+
+	localstorageapis "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	runtimecache "sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+type auditorForLocalVolumeEncrypt struct {
+	events *EventStore
+}
+
+func newAuditorForLocalVolumeEncrypt(events *EventStore) *auditorForLocalVolumeEncrypt {
+	return &auditorForLocalVolumeEncrypt{events: events}
+}
+
+func (ad *auditorForLocalVolumeEncrypt) Run(informersCache runtimecache.Cache, stopCh <-chan struct{}) {
+	informer, err := informersCache.GetInformer(context.TODO(), &localstorageapis.LocalVolumeEncrypt{})
+	if err != nil {
+		// error happens, crash the node
+		log.WithError(err).Fatal("Failed to get informer for LocalVolumeEncrypt")
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    ad.onAdd,
+		UpdateFunc: ad.onUpdate,
+	})
+}
+
+func (ad *auditorForLocalVolumeEncrypt) onAdd(obj interface{}) {
+	instance, _ := obj.(*localstorageapis.LocalVolumeEncrypt)
+
+	if len(instance.Status.State) != 0 {
+		return
+	}
+
+	record := &localstorageapis.EventRecord{
+		Time:   metav1.Time{Time: time.Now()},
+		ID:     instance.Name,
+		Action: ActionVolumeEncrypt,
+		State:  ActionStateSubmit,
+	}
+
+	ad.events.AddRecordForResource(ResourceTypeVolume, instance.Spec.VolumeName, record)
+}
+
+func (ad *auditorForLocalVolumeEncrypt) onUpdate(oldObj, newObj interface{}) {
+	oldInstance, _ := oldObj.(*localstorageapis.LocalVolumeEncrypt)
+	instance, _ := newObj.(*localstorageapis.LocalVolumeEncrypt)
+
+	// the progress is updated by the node many times, only record the state changes
+	if oldInstance.Status.State == instance.Status.State {
+		return
+	}
+
+	record := &localstorageapis.EventRecord{
+		Time:   metav1.Time{Time: time.Now()},
+		ID:     instance.Name,
+		Action: ActionVolumeEncrypt,
+	}
+	switch instance.Status.State {
+	case localstorageapis.OperationStateEncryptInitialize:
+		record.State = ActionStateStart
+	case localstorageapis.OperationStateEncryptReencrypt:
+		// the interrupted encryption is resumed from Reencrypt directly
+		if oldInstance.Status.State == localstorageapis.OperationStateEncryptInitialize {
+			return
+		}
+		record.State = ActionStateStart
+	case localstorageapis.OperationStateCompleted, localstorageapis.OperationStateFailed:
+		// only the reference of the key is kept in the event, never the key
+		record.State = ActionStateComplete
+		record.StateContent = contentString(instance.Status)
+	case localstorageapis.OperationStateAborted:
+		record.State = ActionStateAbort
+		record.StateContent = contentString(instance.Status)
+	default:
+		return
+	}
+
+	ad.events.AddRecordForResource(ResourceTypeVolume, instance.Spec.VolumeName, record)
+}
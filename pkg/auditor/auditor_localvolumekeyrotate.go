@@ -0,0 +1,81 @@
+package auditor
+
+import (
+	"context"
+	"time"
+
+	localstorageapis "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	runtimecache "sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+type auditorForLocalVolumeKeyRotate struct {
+	events *EventStore
+}
+
+func newAuditorForLocalVolumeKeyRotate(events *EventStore) *auditorForLocalVolumeKeyRotate {
+	return &auditorForLocalVolumeKeyRotate{events: events}
+}
+
+func (ad *auditorForLocalVolumeKeyRotate) Run(informersCache runtimecache.Cache, stopCh <-chan struct{}) {
+	informer, err := informersCache.GetInformer(context.TODO(), &localstorageapis.LocalVolumeKeyRotate{})
+	if err != nil {
+		// error happens, crash the node
+		log.WithError(err).Fatal("Failed to get informer for LocalVolumeKeyRotate")
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    ad.onAdd,
+		UpdateFunc: ad.onUpdate,
+	})
+}
+
+func (ad *auditorForLocalVolumeKeyRotate) onAdd(obj interface{}) {
+	instance, _ := obj.(*localstorageapis.LocalVolumeKeyRotate)
+
+	if len(instance.Status.State) != 0 {
+		return
+	}
+
+	record := &localstorageapis.EventRecord{
+		Time:   metav1.Time{Time: time.Now()},
+		ID:     instance.Name,
+		Action: ActionVolumeKeyRotate,
+		State:  ActionStateSubmit,
+	}
+
+	ad.events.AddRecordForResource(ResourceTypeVolume, instance.Spec.VolumeName, record)
+}
+
+func (ad *auditorForLocalVolumeKeyRotate) onUpdate(oldObj, newObj interface{}) {
+	oldInstance, _ := oldObj.(*localstorageapis.LocalVolumeKeyRotate)
+	instance, _ := newObj.(*localstorageapis.LocalVolumeKeyRotate)
+
+	// the replicas are updated by the nodes many times, only record the state changes
+	if oldInstance.Status.State == instance.Status.State {
+		return
+	}
+
+	record := &localstorageapis.EventRecord{
+		Time:   metav1.Time{Time: time.Now()},
+		ID:     instance.Name,
+		Action: ActionVolumeKeyRotate,
+	}
+	switch instance.Status.State {
+	case localstorageapis.OperationStateKeyRotateAddKey:
+		record.State = ActionStateStart
+	case localstorageapis.OperationStateCompleted, localstorageapis.OperationStateFailed, localstorageapis.OperationStateAborted:
+		// only the references of the keys are kept in the event, never the keys
+		record.State = ActionStateComplete
+		record.StateContent = contentString(instance.Status)
+	case localstorageapis.OperationStateToBeAborted:
+		record.State = ActionStateAbort
+		record.StateContent = contentString(instance.Status)
+	default:
+		return
+	}
+
+	ad.events.AddRecordForResource(ResourceTypeVolume, instance.Spec.VolumeName, record)
+}
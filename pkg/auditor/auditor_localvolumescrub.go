@@ -0,0 +1,81 @@
+package auditor
+
+import (
+	"context"
+	"time"
+
+	localstorageapis "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	runtimecache "sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+type auditorForLocalVolumeScrub struct {
+	events *EventStore
+}
+
+func newAuditorForLocalVolumeScrub(events *EventStore) *auditorForLocalVolumeScrub {
+	return &auditorForLocalVolumeScrub{events: events}
+}
+
+func (ad *auditorForLocalVolumeScrub) Run(informersCache runtimecache.Cache, stopCh <-chan struct{}) {
+	informer, err := informersCache.GetInformer(context.TODO(), &localstorageapis.LocalVolumeScrub{})
+	if err != nil {
+		// error happens, crash the node
+		log.WithError(err).Fatal("Failed to get informer for LocalVolumeScrub")
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    ad.onAdd,
+		UpdateFunc: ad.onUpdate,
+	})
+}
+
+func (ad *auditorForLocalVolumeScrub) onAdd(obj interface{}) {
+	instance, _ := obj.(*localstorageapis.LocalVolumeScrub)
+
+	if len(instance.Status.State) != 0 {
+		return
+	}
+
+	record := &localstorageapis.EventRecord{
+		Time:   metav1.Time{Time: time.Now()},
+		ID:     instance.Name,
+		Action: ActionVolumeScrub,
+		State:  ActionStateSubmit,
+	}
+
+	ad.events.AddRecordForResource(ResourceTypeVolume, instance.Spec.VolumeName, record)
+}
+
+func (ad *auditorForLocalVolumeScrub) onUpdate(oldObj, newObj interface{}) {
+	oldInstance, _ := oldObj.(*localstorageapis.LocalVolumeScrub)
+	instance, _ := newObj.(*localstorageapis.LocalVolumeScrub)
+
+	// the results are updated by the nodes many times, only record the state changes
+	if oldInstance.Status.State == instance.Status.State {
+		return
+	}
+
+	record := &localstorageapis.EventRecord{
+		Time:   metav1.Time{Time: time.Now()},
+		ID:     instance.Name,
+		Action: ActionVolumeScrub,
+	}
+	switch instance.Status.State {
+	case localstorageapis.OperationStateInProgress:
+		record.State = ActionStateStart
+	case localstorageapis.OperationStateCompleted, localstorageapis.OperationStateFailed:
+		// the results with the out-of-sync blocks, mismatches and checksums are kept in the event
+		record.State = ActionStateComplete
+		record.StateContent = contentString(instance.Status)
+	case localstorageapis.OperationStateToBeAborted:
+		record.State = ActionStateAbort
+		record.StateContent = contentString(instance.Status)
+	default:
+		return
+	}
+
+	ad.events.AddRecordForResource(ResourceTypeVolume, instance.Spec.VolumeName, record)
+}
@@ -0,0 +1,80 @@
+package auditor
+
+import (
+	"context"
+	"time"
+
+	localstorageapis "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	runtimecache "sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+type auditorForLocalVolumeShrink struct {
+	events *EventStore
+}
+
+func newAuditorForLocalVolumeShrink(events *EventStore) *auditorForLocalVolumeShrink {
+	return &auditorForLocalVolumeShrink{events: events}
+}
+
+func (ad *auditorForLocalVolumeShrink) Run(informersCache runtimecache.Cache, stopCh <-chan struct{}) {
+	informer, err := informersCache.GetInformer(context.TODO(), &localstorageapis.LocalVolumeShrink{})
+	if err != nil {
+		// error happens, crash the node
+		log.WithError(err).Fatal("Failed to get informer for LocalVolumeShrink")
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    ad.onAdd,
+		UpdateFunc: ad.onUpdate,
+	})
+}
+
+func (ad *auditorForLocalVolumeShrink) onAdd(obj interface{}) {
+	instance, _ := obj.(*localstorageapis.LocalVolumeShrink)
+
+	if len(instance.Status.State) != 0 {
+		return
+	}
+
+	record := &localstorageapis.EventRecord{
+		Time:   metav1.Time{Time: time.Now()},
+		ID:     instance.Name,
+		Action: ActionVolumeShrink,
+		State:  ActionStateSubmit,
+	}
+
+	ad.events.AddRecordForResource(ResourceTypeVolume, instance.Spec.VolumeName, record)
+}
+
+func (ad *auditorForLocalVolumeShrink) onUpdate(oldObj, newObj interface{}) {
+	oldInstance, _ := oldObj.(*localstorageapis.LocalVolumeShrink)
+	instance, _ := newObj.(*localstorageapis.LocalVolumeShrink)
+
+	// the shrunk replicas are updated many times, only record the state changes
+	if oldInstance.Status.State == instance.Status.State {
+		return
+	}
+
+	record := &localstorageapis.EventRecord{
+		Time:   metav1.Time{Time: time.Now()},
+		ID:     instance.Name,
+		Action: ActionVolumeShrink,
+	}
+	switch instance.Status.State {
+	case localstorageapis.OperationStateShrinkFilesystem:
+		record.State = ActionStateStart
+	case localstorageapis.OperationStateCompleted, localstorageapis.OperationStateFailed:
+		record.State = ActionStateComplete
+		record.StateContent = contentString(instance.Status)
+	case localstorageapis.OperationStateAborted:
+		record.State = ActionStateAbort
+		record.StateContent = contentString(instance.Status)
+	default:
+		return
+	}
+
+	ad.events.AddRecordForResource(ResourceTypeVolume, instance.Spec.VolumeName, record)
+}
@@ -21,13 +21,17 @@ const (
 	ResourceTypeVolume      = "Volume"
 	ResourceTypeDisk        = "Disk"
 
-	ActionVolumeCreate  = "Create"
-	ActionVolumeDelete  = "Delete"
-	ActionVolumeMount   = "Mount"
-	ActionVolumeUnmount = "Unmount"
-	ActionVolumeConvert = "Convert"
-	ActionVolumeMigrate = "Migrate"
-	ActionVolumeExpand  = "Expand"
+	ActionVolumeCreate    = "Create"
+	ActionVolumeDelete    = "Delete"
+	ActionVolumeMount     = "Mount"
+	ActionVolumeUnmount   = "Unmount"
+	ActionVolumeConvert   = "Convert"
+	ActionVolumeMigrate   = "Migrate"
+	ActionVolumeExpand    = "Expand"
+	ActionVolumeScrub     = "Scrub"
+	ActionVolumeKeyRotate = "KeyRotate"
+	ActionVolumeEncrypt   = "Encrypt"
+	ActionVolumeShrink    = "Shrink"
 
 	ActionStateSubmit   = "Submit"
 	ActionStateStart    = "Start"
@@ -38,17 +42,23 @@ const (
 	ActionNodeRemove                    = "Remove"
 	ActionNodeStateChange               = "StateChange"
 	ActionNodeStoragePoolCapacityExpand = "CapacityExpand"
+	ActionNodeThinPoolExtend            = "ThinPoolExtend"
 
 	ActionClusterInstall = "Install"
 	ActionClusterChange  = "Change"
 	ActionClusterUpgrade = "Upgrade"
 
-	ActionDiskAdd      = "Add"
-	ActionDiskRelocate = "Relocate"
-	ActionDiskAllocate = "Allocate"
-	ActionDiskReserve  = "Reserve"
-	ActionDiskRelease  = "Release"
-	ActionDiskChange   = "Change"
+	ActionDiskAdd          = "Add"
+	ActionDiskRelocate     = "Relocate"
+	ActionDiskAllocate     = "Allocate"
+	ActionDiskReserve      = "Reserve"
+	ActionDiskRelease      = "Release"
+	ActionDiskChange       = "Change"
+	ActionDiskDecommission = "Decommission"
+	ActionDiskHealthChange = "HealthChange"
+	ActionDiskCordon       = "Cordon"
+	ActionDiskUncordon     = "Uncordon"
+	ActionDiskEvacuate     = "Evacuate"
 
 	ErrMsgSuccess = "Added a record"
 	ErrMsgFailure = "Failed to add a record"
@@ -4,12 +4,16 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/labels"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 )
 
 type LocalDiskMetricsCollector struct {
 	dataCache *metricsCache
 
-	capacityMetricsDesc *prometheus.Desc
+	capacityMetricsDesc        *prometheus.Desc
+	raidDegradedMetricsDesc    *prometheus.Desc
+	raidMemberStateMetricsDesc *prometheus.Desc
 }
 
 func newCollectorForLocalDisk(dataCache *metricsCache) prometheus.Collector {
@@ -21,6 +25,18 @@ func newCollectorForLocalDisk(dataCache *metricsCache) prometheus.Collector {
 			[]string{"uuid", "nodeName", "type", "devPath", "reserved", "owner", "status"},
 			nil,
 		),
+		raidDegradedMetricsDesc: prometheus.NewDesc(
+			"hwameistor_localdisk_raid_degraded",
+			"Whether the RAID virtual drive of the localdisk is degraded or rebuilding.",
+			[]string{"uuid", "nodeName", "devPath", "virtualDrive", "level", "state"},
+			nil,
+		),
+		raidMemberStateMetricsDesc: prometheus.NewDesc(
+			"hwameistor_localdisk_raid_member_state",
+			"The state of the physical drive in the RAID virtual drive of the localdisk.",
+			[]string{"uuid", "nodeName", "devPath", "virtualDrive", "enclosureSlot", "serialNumber", "state", "overallHealth"},
+			nil,
+		),
 	}
 }
 
@@ -50,5 +66,28 @@ func (mc *LocalDiskMetricsCollector) Collect(ch chan<- prometheus.Metric) {
 			float64(disk.Spec.Capacity),
 			disk.Spec.UUID, disk.Spec.NodeName, disk.Spec.DiskAttributes.Type, disk.Spec.DevicePath, reserved, owner, string(disk.Status.State),
 		)
+
+		if disk.Spec.HasRAID && len(disk.Spec.RAIDInfo.VirtualDrive) > 0 {
+			mc.collectRAID(ch, disk)
+		}
+	}
+}
+
+func (mc *LocalDiskMetricsCollector) collectRAID(ch chan<- prometheus.Metric, disk *apisv1alpha1.LocalDisk) {
+	info := disk.Spec.RAIDInfo
+	degraded := 0.0
+	if info.State != apisv1alpha1.RAIDOptimal {
+		degraded = 1
+	}
+	ch <- prometheus.MustNewConstMetric(
+		mc.raidDegradedMetricsDesc, prometheus.GaugeValue, degraded,
+		disk.Spec.UUID, disk.Spec.NodeName, disk.Spec.DevicePath, info.VirtualDrive, info.Level, string(info.State),
+	)
+	for _, member := range info.Members {
+		ch <- prometheus.MustNewConstMetric(
+			mc.raidMemberStateMetricsDesc, prometheus.GaugeValue, 1,
+			disk.Spec.UUID, disk.Spec.NodeName, disk.Spec.DevicePath, info.VirtualDrive, member.EnclosureSlot, member.SerialNumber,
+			member.State, string(member.OverallHealth),
+		)
 	}
 }
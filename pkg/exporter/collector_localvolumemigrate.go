@@ -11,8 +11,12 @@ import (
 type LocalVolumeMigrateMetricsCollector struct {
 	dataCache *metricsCache
 
-	durationMetricsDesc *prometheus.Desc
-	statusMetricsDesc   *prometheus.Desc
+	durationMetricsDesc    *prometheus.Desc
+	statusMetricsDesc      *prometheus.Desc
+	copiedBytesMetricsDesc *prometheus.Desc
+	totalBytesMetricsDesc  *prometheus.Desc
+	throughputMetricsDesc  *prometheus.Desc
+	etaMetricsDesc         *prometheus.Desc
 }
 
 func newCollectorForLocalVolumeMigrate(dataCache *metricsCache) prometheus.Collector {
@@ -31,6 +35,30 @@ func newCollectorForLocalVolumeMigrate(dataCache *metricsCache) prometheus.Colle
 			[]string{"volumeName", "status"},
 			nil,
 		),
+		copiedBytesMetricsDesc: prometheus.NewDesc(
+			"hwameistor_localvolumemigrate_copied_bytes",
+			"The bytes copied to the new replica by the localvolumemigrate operation.",
+			[]string{"volumeName"},
+			nil,
+		),
+		totalBytesMetricsDesc: prometheus.NewDesc(
+			"hwameistor_localvolumemigrate_total_bytes",
+			"The total bytes to copy by the localvolumemigrate operation.",
+			[]string{"volumeName"},
+			nil,
+		),
+		throughputMetricsDesc: prometheus.NewDesc(
+			"hwameistor_localvolumemigrate_throughput_bytes",
+			"The average bytes copied per second by the localvolumemigrate operation.",
+			[]string{"volumeName"},
+			nil,
+		),
+		etaMetricsDesc: prometheus.NewDesc(
+			"hwameistor_localvolumemigrate_eta_seconds",
+			"The estimated seconds to complete the data copy of the localvolumemigrate operation.",
+			[]string{"volumeName"},
+			nil,
+		),
 	}
 
 }
@@ -58,5 +86,11 @@ func (mc *LocalVolumeMigrateMetricsCollector) Collect(ch chan<- prometheus.Metri
 			migrate.Status.TargetNode,
 		)
 		ch <- prometheus.MustNewConstMetric(mc.statusMetricsDesc, prometheus.GaugeValue, 1, migrate.Spec.VolumeName, string(migrate.Status.State))
+		if progress := migrate.Status.Progress; progress != nil {
+			ch <- prometheus.MustNewConstMetric(mc.copiedBytesMetricsDesc, prometheus.GaugeValue, float64(progress.CopiedBytes), migrate.Spec.VolumeName)
+			ch <- prometheus.MustNewConstMetric(mc.totalBytesMetricsDesc, prometheus.GaugeValue, float64(progress.TotalBytes), migrate.Spec.VolumeName)
+			ch <- prometheus.MustNewConstMetric(mc.throughputMetricsDesc, prometheus.GaugeValue, float64(progress.Throughput), migrate.Spec.VolumeName)
+			ch <- prometheus.MustNewConstMetric(mc.etaMetricsDesc, prometheus.GaugeValue, float64(progress.ETASeconds), migrate.Spec.VolumeName)
+		}
 	}
 }
@@ -11,6 +11,11 @@ type LocalVolumeReplicaMetricsCollector struct {
 
 	statusMetricsDesc   *prometheus.Desc
 	capacityMetricsDesc *prometheus.Desc
+
+	cacheHitsMetricsDesc       *prometheus.Desc
+	cacheMissesMetricsDesc     *prometheus.Desc
+	cacheUsedBytesMetricsDesc  *prometheus.Desc
+	cacheDirtyBytesMetricsDesc *prometheus.Desc
 }
 
 func newCollectorForLocalVolumeReplica(dataCache *metricsCache) prometheus.Collector {
@@ -29,6 +34,34 @@ func newCollectorForLocalVolumeReplica(dataCache *metricsCache) prometheus.Colle
 			[]string{"nodeName", "poolName", "volumeName"},
 			nil,
 		),
+
+		cacheHitsMetricsDesc: prometheus.NewDesc(
+			"hwameistor_localvolumereplica_cache_hits",
+			"The cache hits of the localvolumereplica.",
+			[]string{"nodeName", "poolName", "volumeName", "cachePoolName", "operation"},
+			nil,
+		),
+
+		cacheMissesMetricsDesc: prometheus.NewDesc(
+			"hwameistor_localvolumereplica_cache_misses",
+			"The cache misses of the localvolumereplica.",
+			[]string{"nodeName", "poolName", "volumeName", "cachePoolName", "operation"},
+			nil,
+		),
+
+		cacheUsedBytesMetricsDesc: prometheus.NewDesc(
+			"hwameistor_localvolumereplica_cache_used_bytes",
+			"The used cache capacity of the localvolumereplica.",
+			[]string{"nodeName", "poolName", "volumeName", "cachePoolName"},
+			nil,
+		),
+
+		cacheDirtyBytesMetricsDesc: prometheus.NewDesc(
+			"hwameistor_localvolumereplica_cache_dirty_bytes",
+			"The dirty cache capacity of the localvolumereplica, which is not written back to the origin yet.",
+			[]string{"nodeName", "poolName", "volumeName", "cachePoolName"},
+			nil,
+		),
 	}
 }
 
@@ -48,5 +81,15 @@ func (mc *LocalVolumeReplicaMetricsCollector) Collect(ch chan<- prometheus.Metri
 		poolName := unifiedPoolName(replica.Spec.PoolName)
 		ch <- prometheus.MustNewConstMetric(mc.capacityMetricsDesc, prometheus.GaugeValue, float64(replica.Status.AllocatedCapacityBytes), replica.Spec.NodeName, poolName, replica.Spec.VolumeName)
 		ch <- prometheus.MustNewConstMetric(mc.statusMetricsDesc, prometheus.GaugeValue, 1, replica.Spec.NodeName, poolName, replica.Spec.VolumeName, string(replica.Status.State))
+
+		if cache := replica.Status.VolumeCache; cache != nil {
+			cachePoolName := unifiedPoolName(cache.PoolName)
+			ch <- prometheus.MustNewConstMetric(mc.cacheHitsMetricsDesc, prometheus.CounterValue, float64(cache.ReadHits), replica.Spec.NodeName, poolName, replica.Spec.VolumeName, cachePoolName, "read")
+			ch <- prometheus.MustNewConstMetric(mc.cacheHitsMetricsDesc, prometheus.CounterValue, float64(cache.WriteHits), replica.Spec.NodeName, poolName, replica.Spec.VolumeName, cachePoolName, "write")
+			ch <- prometheus.MustNewConstMetric(mc.cacheMissesMetricsDesc, prometheus.CounterValue, float64(cache.ReadMisses), replica.Spec.NodeName, poolName, replica.Spec.VolumeName, cachePoolName, "read")
+			ch <- prometheus.MustNewConstMetric(mc.cacheMissesMetricsDesc, prometheus.CounterValue, float64(cache.WriteMisses), replica.Spec.NodeName, poolName, replica.Spec.VolumeName, cachePoolName, "write")
+			ch <- prometheus.MustNewConstMetric(mc.cacheUsedBytesMetricsDesc, prometheus.GaugeValue, float64(cache.UsedBlocks*cache.BlockSizeBytes), replica.Spec.NodeName, poolName, replica.Spec.VolumeName, cachePoolName)
+			ch <- prometheus.MustNewConstMetric(mc.cacheDirtyBytesMetricsDesc, prometheus.GaugeValue, float64(cache.DirtyBlocks*cache.BlockSizeBytes), replica.Spec.NodeName, poolName, replica.Spec.VolumeName, cachePoolName)
+		}
 	}
 }
@@ -40,7 +40,7 @@ func init() {
 		"provisioner:{lvm.hwameistor.io,disk.hwameistor.io}")
 	storageClassAdd.Flags().StringVar(&convertible, "convertible", "false", "convertible")
 	storageClassAdd.Flags().StringVar(&striped, "striped", "true", "striped")
-	storageClassAdd.Flags().StringVar(&replicaNumber, "replicaNumber", "1", "replicaNumber:1,2")
+	storageClassAdd.Flags().StringVar(&replicaNumber, "replicaNumber", "1", "replicaNumber:1,2,3")
 	storageClassAdd.Flags().StringVar(&fstype, "fstype", "xfs", "fstype")
 	storageClassAdd.Flags().StringVar(&poolClass, "poolClass", "HDD", "poolClass:HDD,SSD,NVMe")
 	storageClassAdd.Flags().StringVar(&poolType, "poolType", "REGULAR", "poolType")
@@ -1,6 +1,9 @@
 package volume
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/jedib0t/go-pretty/v6/table"
 	"github.com/spf13/cobra"
 
@@ -29,12 +32,19 @@ func volumeMigrateListRunE(_ *cobra.Command, _ []string) error {
 	}
 
 	migrateListHeader := table.Row{"#", "Name", "VolumeName", "SourceNode", "TargetNode",
-		"Abort", "State", "Message"}
+		"Abort", "State", "Progress", "Throughput", "ETA", "Message"}
 	migrateListRows := make([]table.Row, len(migrateList.Items))
 	for i, migrate := range migrateList.Items {
+		progress, throughput, eta := "-", "-", "-"
+		if p := migrate.Status.Progress; p != nil && p.TotalBytes > 0 {
+			progress = fmt.Sprintf("%s / %s (%s)", formatter.FormatBytesToSize(p.CopiedBytes),
+				formatter.FormatBytesToSize(p.TotalBytes), formatter.FormatPercentString(p.CopiedBytes, p.TotalBytes))
+			throughput = formatter.FormatBytesToSize(p.Throughput) + "/s"
+			eta = (time.Duration(p.ETASeconds) * time.Second).String()
+		}
 		migrateListRows[i] = table.Row{i + 1, migrate.Name, migrate.Spec.VolumeName,
 			migrate.Spec.SourceNode, migrate.Status.TargetNode,
-			migrate.Spec.Abort, migrate.Status.State, migrate.Status.Message}
+			migrate.Spec.Abort, migrate.Status.State, progress, throughput, eta, migrate.Status.Message}
 	}
 
 	formatter.PrintTable("Migrate operation list", migrateListHeader, migrateListRows)
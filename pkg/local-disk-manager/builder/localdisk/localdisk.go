@@ -61,7 +61,7 @@ func (builder *Builder) SetupRaidInfo(raid manager.RaidInfo) *Builder {
 		return builder
 	}
 
-	// complete RAID INFO here
+	// the RAID info of the virtual drives is synced from the RAID controller by raid.Monitor
 	return builder
 }
 
@@ -49,6 +49,11 @@ func (k *Kubeclient) Get(name string) (*v1alpha1.LocalDiskVolume, error) {
 func (k *Kubeclient) Update(volume *v1alpha1.LocalDiskVolume) (*v1alpha1.LocalDiskVolume, error) {
 	return k.clientset.HwameistorV1alpha1().LocalDiskVolumes().Update(context.Background(), volume, v1.UpdateOptions{})
 }
+
+func (k *Kubeclient) List() (*v1alpha1.LocalDiskVolumeList, error) {
+	return k.clientset.HwameistorV1alpha1().LocalDiskVolumes().List(context.Background(), v1.ListOptions{})
+}
+
 func (k *Kubeclient) SetClient(cli clientset.Interface) {
 	k.clientset = cli
 }
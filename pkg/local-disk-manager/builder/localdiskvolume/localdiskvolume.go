@@ -137,6 +137,33 @@ func (builder *Builder) SetupDiskType(diskTpe string) *Builder {
 	return builder
 }
 
+func (builder *Builder) SetupSanitizePolicy(policy v1alpha1.DiskSanitizePolicy) *Builder {
+	if err := builder.assertVolumeNotNil(); err != nil {
+		return builder
+	}
+
+	builder.volume.Spec.SanitizePolicy = policy
+	return builder
+}
+
+func (builder *Builder) SetupAllocationMode(mode v1alpha1.DiskAllocationMode) *Builder {
+	if err := builder.assertVolumeNotNil(); err != nil {
+		return builder
+	}
+
+	builder.volume.Spec.AllocationMode = mode
+	return builder
+}
+
+func (builder *Builder) SetupPartition(partition *v1alpha1.DiskPartition) *Builder {
+	if err := builder.assertVolumeNotNil(); err != nil {
+		return builder
+	}
+
+	builder.volume.Status.Partition = partition
+	return builder
+}
+
 func (builder *Builder) SetupStatus(status v1alpha1.State) *Builder {
 	if err := builder.assertVolumeNotNil(); err != nil {
 		return builder
@@ -139,6 +139,9 @@ func (r *ReconcileLocalDisk) Reconcile(_ context.Context, req reconcile.Request)
 		err = r.processDiskAvailable(localDisk)
 	case v1alpha1.LocalDiskBound:
 		err = r.processDiskBound(localDisk)
+	case v1alpha1.LocalDiskSanitizing:
+		// the disk is released by the LocalDiskVolume after it's sanitized
+		log.WithField("name", localDisk.Name).Debug("Disk is being sanitized, no operation here")
 	default:
 		err = fmt.Errorf("invalid disk state: %v", localDisk.Status.State)
 	}
@@ -19,6 +19,7 @@ import (
 
 	v1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/handler/localdiskvolume"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/sanitize"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/utils"
 )
 
@@ -34,6 +35,8 @@ func newReconciler(mgr manager.Manager) reconcile.Reconciler {
 		client:   mgr.GetClient(),
 		scheme:   mgr.GetScheme(),
 		Recorder: mgr.GetEventRecorderFor("localdiskvolume-controller"),
+		// the sanitizations keep running across the reconciles
+		sanitizeRunner: sanitize.NewRunner(),
 	}
 }
 
@@ -99,6 +102,8 @@ type ReconcileLocalDiskVolume struct {
 	client   client.Client
 	scheme   *runtime.Scheme
 	Recorder record.EventRecorder
+	// sanitizeRunner runs the disk sanitizations on this node in the background
+	sanitizeRunner *sanitize.Runner
 }
 
 // Reconcile
@@ -120,6 +125,11 @@ func (r *ReconcileLocalDiskVolume) Reconcile(_ context.Context, request reconcil
 		log.WithError(err).Errorf("Failed to check finalizers for LocalDiskVolume %s", request.Name)
 	}
 
+	// expand the volume before the other operations, so that the expansion isn't blocked by the mount
+	if v.NeedExpand() {
+		return v.ReconcileExpand()
+	}
+
 	switch v.VolumeState() {
 	// Create Volumes
 	case v1alpha1.VolumeStateCreating:
@@ -157,5 +167,6 @@ func (r *ReconcileLocalDiskVolume) reconcileForVolume(name client.ObjectKey) (*l
 	}
 
 	volumeHandler.For(volume)
+	volumeHandler.SetSanitizeRunner(r.sanitizeRunner)
 	return volumeHandler, nil
 }
@@ -167,10 +167,27 @@ func (s *Server) ListSnapshots(context.Context, *csi.ListSnapshotsRequest) (*csi
 	return nil, fmt.Errorf("not implemented")
 }
 
-func (s *Server) ControllerExpandVolume(context.Context, *csi.ControllerExpandVolumeRequest) (*csi.ControllerExpandVolumeResponse, error) {
+func (s *Server) ControllerExpandVolume(ctx context.Context, req *csi.ControllerExpandVolumeRequest) (*csi.ControllerExpandVolumeResponse, error) {
 	log.Infof("Calling %s ...", utils.FuncName())
 
-	return nil, fmt.Errorf("not implemented")
+	// validate request
+	if err := s.validateControllerExpandVolumeRequest(req); err != nil {
+		log.WithError(err).Error("ControllerExpandVolumeRequest is invalid")
+		return nil, err
+	}
+
+	capacity, err := s.vm.ExpandVolume(ctx, req.GetVolumeId(), req.GetCapacityRange().GetRequiredBytes())
+	if err != nil {
+		log.WithError(err).Error("Failed to ExpandVolume")
+		return nil, err
+	}
+	log.Infof("Volume %s expanded to %d success", req.GetVolumeId(), capacity)
+
+	return &csi.ControllerExpandVolumeResponse{
+		CapacityBytes: capacity,
+		// the filesystem is resized on the node, while the raw block volume is ready to use
+		NodeExpansionRequired: req.GetVolumeCapability().GetBlock() == nil,
+	}, nil
 }
 
 func (s *Server) ControllerGetVolume(context.Context, *csi.ControllerGetVolumeRequest) (*csi.ControllerGetVolumeResponse, error) {
@@ -201,6 +218,23 @@ func (s *Server) validateCreateVolumeRequest(req *csi.CreateVolumeRequest) error
 	return nil
 }
 
+func (s *Server) validateControllerExpandVolumeRequest(req *csi.ControllerExpandVolumeRequest) error {
+	// verify Capability
+	if err := s.verifyControllerCapability(csi.ControllerServiceCapability_RPC_EXPAND_VOLUME); err != nil {
+		return err
+	}
+
+	if req.GetVolumeId() == "" {
+		return status.Error(codes.InvalidArgument, "VolumeId is empty")
+	}
+
+	if req.GetCapacityRange() == nil || req.GetCapacityRange().GetRequiredBytes() <= 0 {
+		return status.Error(codes.InvalidArgument, "RequiredBytes is empty")
+	}
+
+	return nil
+}
+
 func (s *Server) initControllerCapability() {
 	caps := []csi.ControllerServiceCapability_RPC_Type{
 		// for volume
@@ -208,6 +242,7 @@ func (s *Server) initControllerCapability() {
 		//ControllerServiceCapability_RPC_PUBLISH_UNPUBLISH_VOLUME,
 		csi.ControllerServiceCapability_RPC_LIST_VOLUMES,
 		csi.ControllerServiceCapability_RPC_GET_VOLUME,
+		csi.ControllerServiceCapability_RPC_EXPAND_VOLUME,
 	}
 	for _, c := range caps {
 		s.supportControllerCapability = append(s.supportControllerCapability, newControllerServiceCapability(c))
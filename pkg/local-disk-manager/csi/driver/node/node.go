@@ -44,6 +44,7 @@ func (s *Server) initNodeCapabilities() {
 	caps := []csi.NodeServiceCapability_RPC_Type{
 		csi.NodeServiceCapability_RPC_GET_VOLUME_STATS,
 		csi.NodeServiceCapability_RPC_VOLUME_CONDITION,
+		csi.NodeServiceCapability_RPC_EXPAND_VOLUME,
 	}
 	for _, c := range caps {
 		s.supportNodeCapability = append(s.supportNodeCapability, newNodeServiceCapability(c))
@@ -140,8 +141,16 @@ func (s *Server) NodeGetVolumeStats(ctx context.Context, req *csi.NodeGetVolumeS
 	}, nil
 }
 
-func (s *Server) NodeExpandVolume(context.Context, *csi.NodeExpandVolumeRequest) (*csi.NodeExpandVolumeResponse, error) {
-	return nil, fmt.Errorf("not implemented")
+func (s *Server) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandVolumeRequest) (*csi.NodeExpandVolumeResponse, error) {
+	if err := s.validateNodeExpandRequest(req); err != nil {
+		return nil, err
+	}
+
+	capacity, err := s.vm.NodeExpandVolume(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &csi.NodeExpandVolumeResponse{CapacityBytes: capacity}, nil
 }
 
 func (s *Server) NodeGetCapabilities(context.Context, *csi.NodeGetCapabilitiesRequest) (*csi.NodeGetCapabilitiesResponse, error) {
@@ -185,3 +194,15 @@ func (s *Server) validateNodeUnPublishRequest(req *csi.NodeUnpublishVolumeReques
 
 	return nil
 }
+
+func (s *Server) validateNodeExpandRequest(req *csi.NodeExpandVolumeRequest) error {
+	if req.GetVolumeId() == "" {
+		return fmt.Errorf("VolumeId is empty")
+	}
+
+	if req.GetVolumePath() == "" {
+		return fmt.Errorf("VolumePath is empty")
+	}
+
+	return nil
+}
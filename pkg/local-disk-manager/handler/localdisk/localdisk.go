@@ -135,6 +135,7 @@ func (ldHandler *Handler) BoundTo(ldc *v1alpha1.LocalDiskClaim) error {
 	ldcRef, _ := reference.GetReference(nil, ldc)
 	ldHandler.localDisk.Spec.ClaimRef = ldcRef
 	ldHandler.localDisk.Spec.Owner = ldc.Spec.Owner
+	ldHandler.localDisk.Spec.SanitizePolicy = ldc.Spec.SanitizePolicy
 
 	err := ldHandler.Update()
 	if err == nil {
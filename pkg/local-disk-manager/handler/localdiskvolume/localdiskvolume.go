@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -11,12 +12,18 @@ import (
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/member/node/registry"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/member/node/volume"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/member/types"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/sanitize"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/udev"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/utils/sys"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/mount-utils"
+	utilexec "k8s.io/utils/exec"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -28,8 +35,23 @@ import (
 const (
 	// LocalDiskFinalizer for the LocalDiskVolume CR
 	LocalDiskFinalizer string = "localdisk.hwameistor.io/finalizer"
+
+	// SanitizeCheckInterval is the interval to check the progress of the disk sanitization
+	SanitizeCheckInterval = 10 * time.Second
+
+	// SanitizeRetryInterval is the interval to retry the failed disk sanitization
+	SanitizeRetryInterval = 5 * time.Minute
 )
 
+// getDeviceCapacity returns the size of the block device, can be replaced in tests
+var getDeviceCapacity = func(devPath string) (int64, error) {
+	device, err := sys.NewSysFsDeviceFromDevPath(devPath)
+	if err != nil {
+		return 0, err
+	}
+	return device.GetCapacityInBytes()
+}
+
 // DiskVolumeHandler
 type DiskVolumeHandler struct {
 	client.Client
@@ -38,6 +60,8 @@ type DiskVolumeHandler struct {
 	hostVM       volume.Manager
 	hostRegistry registry.Manager
 	mounter      lscsi.Mounter
+	// sanitizeRunner runs the disk sanitizations in the background, it's shared by the handlers on this node
+	sanitizeRunner *sanitize.Runner
 }
 
 // NewLocalDiskHandler
@@ -65,7 +89,15 @@ func (v *DiskVolumeHandler) ReconcileCreated() (reconcile.Result, error) {
 			}
 		}
 	}
-	err := v.hostVM.CreateVolume(volumeName, types.GetLocalDiskPoolName(volumeType), selectedDisk)
+	var err error
+	if partition := v.Ldv.Status.Partition; partition != nil {
+		err = v.hostVM.CreatePartitionVolume(volumeName, types.GetLocalDiskPoolName(volumeType), selectedDisk, partition)
+		if err == nil {
+			v.Ldv.Status.DevPath = partition.DevPath
+		}
+	} else {
+		err = v.hostVM.CreateVolume(volumeName, types.GetLocalDiskPoolName(volumeType), selectedDisk)
+	}
 	if err != nil {
 		return reconcile.Result{}, err
 	}
@@ -165,35 +197,206 @@ func (v *DiskVolumeHandler) ReconcileToBeDeleted() (reconcile.Result, error) {
 }
 
 func (v *DiskVolumeHandler) ReconcileDeleted() (reconcile.Result, error) {
-	// 1. delete volume
-	if err := v.hostVM.DeleteVolume(v.Ldv.Name, types.GetLocalDiskPoolName(v.Ldv.Spec.DiskType)); err != nil {
+	// 1. sanitize disk before the volume is deleted, so that the disk is kept in use until it's sanitized
+	sanitized, err := v.SanitizeDisk()
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !sanitized {
+		return reconcile.Result{RequeueAfter: SanitizeCheckInterval}, nil
+	}
+
+	// 2. delete volume, the partition is removed so that its space can be allocated again
+	if partition := v.Ldv.Status.Partition; partition != nil {
+		err = v.hostVM.DeletePartitionVolume(v.Ldv.Name, types.GetLocalDiskPoolName(v.Ldv.Spec.DiskType), partition)
+	} else {
+		err = v.hostVM.DeleteVolume(v.Ldv.Name, types.GetLocalDiskPoolName(v.Ldv.Spec.DiskType))
+	}
+	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	// 2. wipe disk
-	if err := v.WipeDisk(); err != nil {
+	// 3. release disk
+	if err := v.setupBoundDiskState(v1alpha1.LocalDiskSanitizing, v1alpha1.LocalDiskBound); err != nil {
 		return reconcile.Result{}, err
 	}
 	return reconcile.Result{}, v.Delete(context.Background(), v.Ldv)
 }
 
-// WipeDisk use wipefs to wipe disk
-func (v *DiskVolumeHandler) WipeDisk() error {
-	logCtx := log.Fields{"volume": v.Ldv.GetName(), "localdisk": v.GetBoundDisk(), "devName": v.GetDevPath()}
+// NeedExpand returns true if the volume is created and its expansion is in progress
+func (v *DiskVolumeHandler) NeedExpand() bool {
+	switch v.VolumeState() {
+	case v1alpha1.VolumeStateCreated, v1alpha1.VolumeStateReady, v1alpha1.VolumeStateNotReady, v1alpha1.VolumeStateEmpty:
+	default:
+		return false
+	}
+	return v.Ldv.Status.Expansion != nil && v.Ldv.Status.Expansion.State == v1alpha1.OperationStateInProgress
+}
+
+// ReconcileExpand grows the volume to the required capacity. The partition volume grows into the free space after it,
+// and the whole disk volume takes the capacity of the disk, which only grows if the disk is replaced by a larger one
+func (v *DiskVolumeHandler) ReconcileExpand() (reconcile.Result, error) {
+	expansion := v.Ldv.Status.Expansion
+	logCtx := log.WithFields(log.Fields{"volume": v.Ldv.Name, "requiredCapacityBytes": expansion.RequiredCapacityBytes})
+
+	var capacity int64
+	var err error
+	if partition := v.Ldv.Status.Partition; partition != nil {
+		sizeBytes := types.AlignPartitionSize(expansion.RequiredCapacityBytes)
+		if err = v.hostVM.ExpandPartitionVolume(v.Ldv.Name, types.GetLocalDiskPoolName(v.Ldv.Spec.DiskType), partition, sizeBytes); err == nil {
+			partition.SizeBytes = sizeBytes
+			capacity = sizeBytes
+		}
+	} else {
+		var devPath string
+		if devPath, err = filepath.EvalSymlinks(v.GetVolumePath()); err == nil {
+			if capacity, err = getDeviceCapacity(devPath); err == nil && capacity < expansion.RequiredCapacityBytes {
+				err = fmt.Errorf("capacity %d of disk %s is less than required %d, replace it with a larger disk",
+					capacity, devPath, expansion.RequiredCapacityBytes)
+			}
+		}
+	}
+
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to expand volume")
+		expansion.State = v1alpha1.OperationStateFailed
+		expansion.Message = err.Error()
+		v.RecordEvent(corev1.EventTypeWarning, "ExpandFailed", "Failed to expand volume to %d: %v", expansion.RequiredCapacityBytes, err)
+		return reconcile.Result{}, v.UpdateLocalDiskVolume()
+	}
+
+	logCtx.WithField("capacity", capacity).Info("Succeed to expand volume")
+	v.Ldv.Status.AllocatedCapacityBytes = capacity
+	expansion.State = v1alpha1.OperationStateCompleted
+	expansion.Message = ""
+	v.RecordEvent(corev1.EventTypeNormal, "Expanded", "Succeed to expand volume to %d", capacity)
+	return reconcile.Result{}, v.UpdateLocalDiskVolume()
+}
+
+// ExpandFileSystem resizes the filesystem mounted at the target path to the size of the volume
+func (v *DiskVolumeHandler) ExpandFileSystem(targetPath string) error {
+	resizer := mount.NewResizeFs(utilexec.New())
+	needResize, err := resizer.NeedResize(v.GetDevPath(), targetPath)
+	if err != nil || !needResize {
+		return err
+	}
+	_, err = resizer.Resize(v.GetDevPath(), targetPath)
+	return err
+}
+
+// SanitizeDisk removes the data on the disk according to the sanitize policy. The sanitization runs in the background,
+// and returns true once it succeeds or is not required. The disk stays in Sanitizing state until then
+func (v *DiskVolumeHandler) SanitizeDisk() (bool, error) {
+	logCtx := log.WithFields(log.Fields{"volume": v.Ldv.GetName(), "localdisk": v.GetBoundDisk(), "devName": v.GetDevPath()})
 	if !v.GetCanWipe() {
-		log.WithFields(logCtx).Debug("disk will not be wiped")
-		return nil
+		logCtx.Debug("disk will not be sanitized")
+		return true, nil
+	}
+
+	policy, err := v.GetSanitizePolicy()
+	if err != nil {
+		return false, err
+	}
+	if policy == v1alpha1.DiskSanitizePolicyNone {
+		logCtx.Debug("disk will not be sanitized by policy")
+		return true, nil
+	}
+	// the other partitions on the disk must be kept, so only the space of the partition is overwritten
+	if v.Ldv.Status.Partition != nil && sanitize.IsWholeDevicePolicy(policy) {
+		logCtx.WithField("policy", policy).Info("policy can't be applied to partition, overwrite it with zeros instead")
+		policy = v1alpha1.DiskSanitizePolicyZero
+	}
+
+	status := v.Ldv.Status.Sanitize
+	if status != nil && status.Policy == policy {
+		switch status.State {
+		case v1alpha1.OperationStateCompleted:
+			return true, nil
+		case v1alpha1.OperationStateFailed:
+			if status.CompletionTime != nil && time.Since(status.CompletionTime.Time) < SanitizeRetryInterval {
+				return false, nil
+			}
+		}
+	}
+
+	if v.sanitizeRunner == nil {
+		return false, fmt.Errorf("no runner to sanitize disk %s", v.GetDevPath())
+	}
+	sanitizer, err := sanitize.New(policy)
+	if err != nil {
+		return false, err
+	}
+	job := v.sanitizeRunner.Start(v.Ldv.Name, v.GetDevPath(), sanitizer)
+	if status == nil || status.Policy != policy || status.State != v1alpha1.OperationStateInProgress {
+		logCtx.WithField("policy", policy).Info("disk will be sanitized")
+		if err = v.setupBoundDiskState(v1alpha1.LocalDiskBound, v1alpha1.LocalDiskSanitizing); err != nil {
+			return false, err
+		}
+		startTime := metav1.Now()
+		status = &v1alpha1.DiskSanitizeStatus{Policy: policy, State: v1alpha1.OperationStateInProgress, StartTime: &startTime}
+		v.Ldv.Status.Sanitize = status
+		v.RecordEvent(corev1.EventTypeNormal, "Sanitizing", "Start to sanitize disk %s by %s", v.GetDevPath(), policy)
+	}
+
+	status.Progress = job.Progress()
+	if done, jobErr := job.Done(); done {
+		v.sanitizeRunner.Remove(v.Ldv.Name)
+		completionTime := metav1.Now()
+		status.CompletionTime = &completionTime
+		if jobErr != nil {
+			status.State = v1alpha1.OperationStateFailed
+			status.Message = jobErr.Error()
+			v.RecordEvent(corev1.EventTypeWarning, "SanitizeFailed", "Failed to sanitize disk %s, retry after %v: %v",
+				v.GetDevPath(), SanitizeRetryInterval, jobErr)
+		} else {
+			status.State = v1alpha1.OperationStateCompleted
+			status.Progress = 100
+			status.Message = ""
+			v.RecordEvent(corev1.EventTypeNormal, "Sanitized", "Succeed to sanitize disk %s", v.GetDevPath())
+		}
+	}
+
+	return status.State == v1alpha1.OperationStateCompleted, v.UpdateLocalDiskVolume()
+}
+
+// GetSanitizePolicy returns the sanitize policy of the volume, which is from the StorageClass,
+// or the LocalDiskClaim of the disk, and removes the signatures by default
+func (v *DiskVolumeHandler) GetSanitizePolicy() (v1alpha1.DiskSanitizePolicy, error) {
+	if v.Ldv.Spec.SanitizePolicy != "" {
+		return v.Ldv.Spec.SanitizePolicy, nil
 	}
 
-	wipefs := fmt.Sprintf("wipefs -af %s", v.GetDevPath())
-	log.WithFields(logCtx).Debugf("disk will be wiped by cmd %s", wipefs)
+	if v.GetBoundDisk() != "" {
+		disk := &v1alpha1.LocalDisk{}
+		if err := v.Get(context.Background(), client.ObjectKey{Name: v.GetBoundDisk()}, disk); err != nil {
+			if !errors.IsNotFound(err) {
+				return "", err
+			}
+		} else if disk.Spec.SanitizePolicy != "" {
+			return disk.Spec.SanitizePolicy, nil
+		}
+	}
+	return v1alpha1.DiskSanitizePolicySignatures, nil
+}
 
-	if _, err := utils.Bash(wipefs); err != nil {
-		log.WithFields(logCtx).WithError(err).Error("failed to wipe disk")
+// setupBoundDiskState changes the state of the bound disk to the new state, if it's in the expected state.
+// The disk shared by the partition volumes is left unchanged
+func (v *DiskVolumeHandler) setupBoundDiskState(expected, state v1alpha1.LocalDiskState) error {
+	if v.GetBoundDisk() == "" || v.Ldv.Status.Partition != nil {
+		return nil
+	}
+	disk := &v1alpha1.LocalDisk{}
+	if err := v.Get(context.Background(), client.ObjectKey{Name: v.GetBoundDisk()}, disk); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
 		return err
 	}
-	log.WithFields(logCtx).Debug("disk wipe success")
-	return nil
+	if disk.Status.State != expected {
+		return nil
+	}
+	disk.Status.State = state
+	return v.Status().Update(context.Background(), disk)
 }
 
 func (v *DiskVolumeHandler) GetLocalDiskVolume(key client.ObjectKey) (volume *v1alpha1.LocalDiskVolume, err error) {
@@ -242,6 +445,10 @@ func (v *DiskVolumeHandler) SetCanWipe(canWipe bool) {
 	v.Ldv.Spec.CanWipe = canWipe
 }
 
+func (v *DiskVolumeHandler) SetSanitizeRunner(runner *sanitize.Runner) {
+	v.sanitizeRunner = runner
+}
+
 func (v *DiskVolumeHandler) GetVolumePath() string {
 	return v.Ldv.Status.VolumePath
 }
@@ -352,6 +559,9 @@ func (v *DiskVolumeHandler) UpdateMountPointPhase(targetPath string, phase v1alp
 func (v *DiskVolumeHandler) UpdateDevPathAccordingVolume() {
 	if vol := v.hostRegistry.GetVolumeByName(v.Ldv.Name); vol != nil {
 		v.Ldv.Status.DevPath = vol.AttachPath
+		if v.Ldv.Status.Partition != nil {
+			v.Ldv.Status.Partition.DevPath = vol.AttachPath
+		}
 		return
 	}
 }
@@ -405,6 +615,38 @@ func (v *DiskVolumeHandler) WaitVolume(ctx context.Context, state v1alpha1.State
 	}
 }
 
+// WaitVolumeExpanded wait the expansion of the volume completes or fails
+func (v *DiskVolumeHandler) WaitVolumeExpanded(ctx context.Context) error {
+	if _, ok := ctx.Deadline(); !ok {
+		return fmt.Errorf("no deadline is set")
+	}
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("context error occured when wait volume expanded: %v", ctx.Err())
+		case <-timer.C:
+		}
+
+		if err := v.RefreshVolume(); err != nil {
+			return err
+		}
+		expansion := v.Ldv.Status.Expansion
+		if expansion == nil {
+			return fmt.Errorf("no expansion found for volume %s", v.Ldv.Name)
+		}
+		switch expansion.State {
+		case v1alpha1.OperationStateCompleted:
+			return nil
+		case v1alpha1.OperationStateFailed:
+			return fmt.Errorf("failed to expand volume %s: %s", v.Ldv.Name, expansion.Message)
+		}
+		timer.Reset(1 * time.Second)
+	}
+}
+
 // WaitVolumeUnmounted wait a special mountpoint is unmounted
 func (v *DiskVolumeHandler) WaitVolumeUnmounted(ctx context.Context, mountPoint string) error {
 	if _, ok := ctx.Deadline(); !ok {
@@ -522,6 +764,10 @@ func getDeviceLinkByVolume(volumePath string) (string, error) {
 	if err != nil {
 		return "", err
 	}
+	// the partition volume links to the partition directly, e.g. /dev/disk/by-id/nvme-xxx-part1
+	if path.IsAbs(devicePath) {
+		return devicePath, nil
+	}
 	// after convert: /etc/hwameistor/LocalDisk_PoolHDD/disk/pci-0000:03:00.0-scsi-0:0:30:0
 	devicePath = path.Join(types.GetLocalDiskPoolPathFromVolume(volumePath), strings.TrimPrefix(devicePath, "../"))
 
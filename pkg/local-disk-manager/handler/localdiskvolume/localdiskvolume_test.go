@@ -2,17 +2,20 @@ package localdiskvolume
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/hwameistor/hwameistor/pkg/apis/client/clientset/versioned/scheme"
 	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/member/node/volume"
 )
 
 func TestLocalDiskVolumeHandler_AppendMountPoint(t *testing.T) {
@@ -141,3 +144,187 @@ func CreateFakeClient() (client.Client, *runtime.Scheme) {
 func newEmptyVolumeHandler() *DiskVolumeHandler {
 	return &DiskVolumeHandler{}
 }
+
+func TestLocalDiskVolumeHandler_GetSanitizePolicy(t *testing.T) {
+	v := newEmptyVolumeHandler()
+	c, _ := CreateFakeClient()
+	v.Client = c
+
+	disk := &v1alpha1.LocalDisk{
+		ObjectMeta: v1.ObjectMeta{Name: "node1-sdb"},
+		Spec:       v1alpha1.LocalDiskSpec{SanitizePolicy: v1alpha1.DiskSanitizePolicyZero},
+	}
+	if err := v.Create(context.TODO(), disk); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		Description string
+		Ldv         *v1alpha1.LocalDiskVolume
+		WantPolicy  v1alpha1.DiskSanitizePolicy
+	}{
+		{
+			Description: "Should use the policy of StorageClass",
+			Ldv: &v1alpha1.LocalDiskVolume{
+				Spec:   v1alpha1.LocalDiskVolumeSpec{SanitizePolicy: v1alpha1.DiskSanitizePolicyBlkDiscard},
+				Status: v1alpha1.LocalDiskVolumeStatus{LocalDiskName: "node1-sdb"},
+			},
+			WantPolicy: v1alpha1.DiskSanitizePolicyBlkDiscard,
+		},
+		{
+			Description: "Should use the policy of LocalDiskClaim",
+			Ldv: &v1alpha1.LocalDiskVolume{
+				Status: v1alpha1.LocalDiskVolumeStatus{LocalDiskName: "node1-sdb"},
+			},
+			WantPolicy: v1alpha1.DiskSanitizePolicyZero,
+		},
+		{
+			Description: "Should remove signatures by default",
+			Ldv: &v1alpha1.LocalDiskVolume{
+				Status: v1alpha1.LocalDiskVolumeStatus{LocalDiskName: "node1-sdc"},
+			},
+			WantPolicy: v1alpha1.DiskSanitizePolicySignatures,
+		},
+	}
+
+	for _, testcase := range testCases {
+		t.Run(testcase.Description, func(t *testing.T) {
+			v.Ldv = testcase.Ldv
+			policy, err := v.GetSanitizePolicy()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if policy != testcase.WantPolicy {
+				t.Errorf("Expect policy %s, got %s", testcase.WantPolicy, policy)
+			}
+		})
+	}
+}
+
+func TestLocalDiskVolumeHandler_SanitizeDisk(t *testing.T) {
+	v := newEmptyVolumeHandler()
+	c, _ := CreateFakeClient()
+	v.Client = c
+
+	disk := &v1alpha1.LocalDisk{
+		ObjectMeta: v1.ObjectMeta{Name: "node1-sdb"},
+		Status:     v1alpha1.LocalDiskStatus{State: v1alpha1.LocalDiskBound},
+	}
+	if err := v.Create(context.TODO(), disk); err != nil {
+		t.Fatal(err)
+	}
+
+	// the disk is never written
+	v.Ldv = &v1alpha1.LocalDiskVolume{
+		Spec:   v1alpha1.LocalDiskVolumeSpec{SanitizePolicy: v1alpha1.DiskSanitizePolicyZero},
+		Status: v1alpha1.LocalDiskVolumeStatus{LocalDiskName: "node1-sdb"},
+	}
+	if done, err := v.SanitizeDisk(); !done || err != nil {
+		t.Errorf("Expect unwritten disk not to be sanitized, got %v %v", done, err)
+	}
+
+	// the data is kept by policy
+	v.Ldv.Spec.CanWipe = true
+	v.Ldv.Spec.SanitizePolicy = v1alpha1.DiskSanitizePolicyNone
+	if done, err := v.SanitizeDisk(); !done || err != nil {
+		t.Errorf("Expect disk not to be sanitized by none policy, got %v %v", done, err)
+	}
+
+	// the failed sanitization is not retried immediately
+	failedTime := v1.Now()
+	v.Ldv.Spec.SanitizePolicy = v1alpha1.DiskSanitizePolicyZero
+	v.Ldv.Status.Sanitize = &v1alpha1.DiskSanitizeStatus{
+		Policy:         v1alpha1.DiskSanitizePolicyZero,
+		State:          v1alpha1.OperationStateFailed,
+		CompletionTime: &failedTime,
+	}
+	if done, err := v.SanitizeDisk(); done || err != nil {
+		t.Errorf("Expect failed sanitization to wait for retry, got %v %v", done, err)
+	}
+
+	// the sanitization is run by the runner of the reconciler
+	v.Ldv.Status.Sanitize.CompletionTime = &v1.Time{Time: failedTime.Add(-SanitizeRetryInterval)}
+	if done, err := v.SanitizeDisk(); done || err == nil {
+		t.Errorf("Expect sanitization to fail without runner, got %v %v", done, err)
+	}
+
+	// the disk is sanitized already
+	v.Ldv.Status.Sanitize.State = v1alpha1.OperationStateCompleted
+	if done, err := v.SanitizeDisk(); !done || err != nil {
+		t.Errorf("Expect sanitized disk to be done, got %v %v", done, err)
+	}
+
+	// the disk is released after sanitized
+	if err := v.setupBoundDiskState(v1alpha1.LocalDiskBound, v1alpha1.LocalDiskSanitizing); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.setupBoundDiskState(v1alpha1.LocalDiskSanitizing, v1alpha1.LocalDiskBound); err != nil {
+		t.Fatal(err)
+	}
+	if err := v.Get(context.TODO(), client.ObjectKey{Name: "node1-sdb"}, disk); err != nil {
+		t.Fatal(err)
+	}
+	if disk.Status.State != v1alpha1.LocalDiskBound {
+		t.Errorf("Expect disk Bound, got %s", disk.Status.State)
+	}
+}
+
+type fakeVolumeManager struct {
+	volume.Manager
+	expandErr error
+}
+
+func (m *fakeVolumeManager) ExpandPartitionVolume(name string, pool string, partition *v1alpha1.DiskPartition, sizeBytes int64) error {
+	return m.expandErr
+}
+
+func TestLocalDiskVolumeHandler_ReconcileExpand(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	v := newEmptyVolumeHandler()
+	c, _ := CreateFakeClient()
+	v.Client = c
+	v.EventRecorder = record.NewFakeRecorder(10)
+	hostVM := &fakeVolumeManager{}
+	v.hostVM = hostVM
+
+	v.Ldv = &v1alpha1.LocalDiskVolume{
+		ObjectMeta: v1.ObjectMeta{Name: "pvc-partition"},
+		Spec:       v1alpha1.LocalDiskVolumeSpec{DiskType: "NVMe", AllocationMode: v1alpha1.DiskAllocationModePartition},
+		Status: v1alpha1.LocalDiskVolumeStatus{
+			State:                  v1alpha1.VolumeStateReady,
+			AllocatedCapacityBytes: 10 * gib,
+			Partition:              &v1alpha1.DiskPartition{Number: 1, StartBytes: 1024 * 1024, SizeBytes: 10 * gib},
+			Expansion:              &v1alpha1.DiskVolumeExpansionStatus{RequiredCapacityBytes: 20*gib + 1, State: v1alpha1.OperationStateInProgress},
+		},
+	}
+	if err := v.Create(context.TODO(), v.Ldv); err != nil {
+		t.Fatal(err)
+	}
+	if !v.NeedExpand() {
+		t.Fatal("Expect volume to be expanded")
+	}
+
+	// the space after the partition is taken
+	hostVM.expandErr = fmt.Errorf("partition nvme0n1p2 overlaps with the required space")
+	if _, err := v.ReconcileExpand(); err != nil {
+		t.Fatal(err)
+	}
+	if v.Ldv.Status.Expansion.State != v1alpha1.OperationStateFailed || v.Ldv.Status.Expansion.Message != hostVM.expandErr.Error() {
+		t.Errorf("Expect expansion failed, got %v", v.Ldv.Status.Expansion)
+	}
+	if v.NeedExpand() {
+		t.Error("Expect failed expansion not to be retried by the node")
+	}
+
+	// the partition is grown to the aligned size
+	hostVM.expandErr = nil
+	v.Ldv.Status.Expansion.State = v1alpha1.OperationStateInProgress
+	if _, err := v.ReconcileExpand(); err != nil {
+		t.Fatal(err)
+	}
+	alignedBytes := int64(20*gib + 1024*1024)
+	if v.Ldv.Status.Expansion.State != v1alpha1.OperationStateCompleted || v.Ldv.Status.AllocatedCapacityBytes != alignedBytes ||
+		v.Ldv.Status.Partition.SizeBytes != alignedBytes {
+		t.Errorf("Expect partition expanded to %d, got %v %v", alignedBytes, v.Ldv.Status.Expansion, v.Ldv.Status.Partition)
+	}
+}
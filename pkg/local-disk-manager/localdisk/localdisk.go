@@ -130,7 +130,6 @@ func (ctr Controller) ConvertDiskToLocalDisk(disk manager.DiskInfo) (ld v1alpha1
 func (ctr Controller) mergeLocalDiskAttr(oldLd *v1alpha1.LocalDisk, newLd v1alpha1.LocalDisk) {
 	oldLd.Spec.DiskAttributes = newLd.Spec.DiskAttributes
 	oldLd.Spec.Capacity = newLd.Spec.Capacity
-	oldLd.Spec.HasRAID = newLd.Spec.HasRAID
 	oldLd.Spec.HasSmartInfo = newLd.Spec.HasSmartInfo
 	oldLd.Spec.SmartInfo = newLd.Spec.SmartInfo
 	oldLd.Spec.HasPartition = newLd.Spec.HasPartition
@@ -140,6 +139,7 @@ func (ctr Controller) mergeLocalDiskAttr(oldLd *v1alpha1.LocalDisk, newLd v1alph
 	oldLd.Spec.Major = newLd.Spec.Major
 	oldLd.Spec.Minor = newLd.Spec.Minor
 	oldLd.Spec.DevLinks = newLd.Spec.DevLinks
+	// HasRAID and RAIDInfo are kept, they are synced from the RAID controller by raid.Monitor
 
 	// record historical information about where the disk was attached and the os path
 	// see issue #982 for more details
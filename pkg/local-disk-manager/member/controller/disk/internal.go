@@ -156,11 +156,13 @@ func (ldn *localDiskNodesManager) ListLocalDiskByNodeDevicePath(nodeName, device
 
 func convertToDisk(diskNode string, disk v1alpha1.LocalDevice) *types.Disk {
 	return &types.Disk{
-		AttachNode: diskNode,
-		Name:       disk.DevPath,
-		DevPath:    disk.DevPath,
-		Capacity:   disk.CapacityBytes,
-		DiskType:   disk.Class,
-		Status:     types.DiskStatus(disk.State),
+		AttachNode:  diskNode,
+		Name:        disk.DevPath,
+		DevPath:     disk.DevPath,
+		Capacity:    disk.CapacityBytes,
+		DiskType:    disk.Class,
+		Status:      types.DiskStatus(disk.State),
+		Partitioned: disk.Partitioned,
+		FreeExtents: disk.FreeExtents,
 	}
 }
@@ -5,17 +5,21 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"sync"
 
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/member/controller/disk"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/member/types"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
 	log "github.com/sirupsen/logrus"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/builder/localdiskvolume"
 	volumectr "github.com/hwameistor/hwameistor/pkg/local-disk-manager/handler/localdiskvolume"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/sanitize"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/utils"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/utils/kubernetes"
 )
@@ -26,6 +30,8 @@ type DiskType = string
 const (
 	VolumeParameterDiskTypeKey     = "diskType"
 	VolumeParameterMinCapacityKey  = "minCap"
+	VolumeParameterSanitizePolicy  = "sanitizePolicy"
+	VolumeParameterAllocationMode  = "allocationMode"
 	VolumeParameterPVCNameKey      = "csi.storage.k8s.io/pvc/name"
 	VolumeParameterPVCNameSpaceKey = "csi.storage.k8s.io/pvc/namespace"
 	VolumeSelectedNodeKey          = "volume.kubernetes.io/selected-node"
@@ -46,6 +52,10 @@ type localDiskVolumeManager struct {
 	// volume
 	// The handler cannot be placed here directly as an object because thread safety cannot be guaranteed
 	GetVolumeHandler func() (*volumectr.DiskVolumeHandler, error)
+
+	// partitionLock serializes the allocation of the disks and partitions, so that the same free extent is never
+	// allocated twice, and a disk being partitioned is never allocated as a whole
+	partitionLock sync.Mutex
 }
 
 // VolumeRequest
@@ -71,6 +81,12 @@ type VolumeRequest struct {
 	// OwnerNodeName represents where this disk volume located
 	OwnerNodeName string `json:"ownerNodeName"`
 
+	// SanitizePolicy represents how the disk is sanitized after this disk volume is deleted
+	SanitizePolicy v1alpha1.DiskSanitizePolicy `json:"sanitizePolicy"`
+
+	// AllocationMode represents whether this disk volume uses a whole disk or a partition of the disk
+	AllocationMode v1alpha1.DiskAllocationMode `json:"allocationMode"`
+
 	// VolumeCap
 	VolumeCap *csi.VolumeCapability
 
@@ -104,6 +120,14 @@ func (r *VolumeRequest) SetDiskType(diskType string) {
 	r.DiskType = diskType
 }
 
+func (r *VolumeRequest) SetSanitizePolicy(policy string) {
+	r.SanitizePolicy = v1alpha1.DiskSanitizePolicy(policy)
+}
+
+func (r *VolumeRequest) SetAllocationMode(mode string) {
+	r.AllocationMode = v1alpha1.DiskAllocationMode(mode)
+}
+
 func (r *VolumeRequest) Valid() error {
 	if r.DiskType == "" {
 		return fmt.Errorf("DevType is empty")
@@ -114,6 +138,18 @@ func (r *VolumeRequest) Valid() error {
 	if r.OwnerNodeName == "" {
 		return fmt.Errorf("SelectedNode is empty")
 	}
+	if !sanitize.IsValidPolicy(r.SanitizePolicy) {
+		return fmt.Errorf("SanitizePolicy %s is invalid", r.SanitizePolicy)
+	}
+	switch r.AllocationMode {
+	case "", v1alpha1.DiskAllocationModeDisk:
+	case v1alpha1.DiskAllocationModePartition:
+		if sanitize.IsWholeDevicePolicy(r.SanitizePolicy) {
+			return fmt.Errorf("SanitizePolicy %s can't be used with the partition volume", r.SanitizePolicy)
+		}
+	default:
+		return fmt.Errorf("AllocationMode %s is invalid", r.AllocationMode)
+	}
 	return nil
 }
 
@@ -139,7 +175,15 @@ func (vm *localDiskVolumeManager) CreateVolume(name string, parameters interface
 		"pvcNamespaceName": volumeRequest.PVCNameSpace + "/" + volumeRequest.PVCName}
 
 	// select suitable disk for the volume
-	selectedDisk, err := vm.findSuitableDisk(volumeRequest)
+	var selectedDisk *types.Disk
+	var partition *v1alpha1.DiskPartition
+	vm.partitionLock.Lock()
+	defer vm.partitionLock.Unlock()
+	if volumeRequest.AllocationMode == v1alpha1.DiskAllocationModePartition {
+		selectedDisk, partition, err = vm.findSuitablePartition(volumeRequest)
+	} else {
+		selectedDisk, err = vm.findSuitableDisk(volumeRequest)
+	}
 	if err != nil {
 		log.WithFields(logCtx).WithError(err).Error("Failed to find suitable disk")
 		return nil, err
@@ -150,6 +194,11 @@ func (vm *localDiskVolumeManager) CreateVolume(name string, parameters interface
 	}
 	log.WithFields(logCtx).Debugf("Select disk %s to place volume", selectedDisk.Name)
 
+	allocateCap := selectedDisk.Capacity
+	if partition != nil {
+		allocateCap = partition.SizeBytes
+	}
+
 	// get localdisk by device path
 	selectedLocalDisk, err := vm.getLocalDiskByNodeDevicePath(selectedDisk.AttachNode, selectedDisk.DevPath)
 	if err != nil {
@@ -163,9 +212,12 @@ func (vm *localDiskVolumeManager) CreateVolume(name string, parameters interface
 		SetupDisk(selectedDisk.DevPath).
 		SetupDevSymLinks(selectedLocalDisk.Spec.DevLinks).
 		SetupLocalDiskName(selectedLocalDisk.Name).
-		SetupAllocateCap(selectedDisk.Capacity).
+		SetupAllocateCap(allocateCap).
 		SetupRequiredCapacityBytes(volumeRequest.RequireCapacity).
 		SetupPVCNameSpaceName(volumeRequest.PVCNameSpace + "/" + volumeRequest.PVCName).
+		SetupSanitizePolicy(volumeRequest.SanitizePolicy).
+		SetupAllocationMode(volumeRequest.AllocationMode).
+		SetupPartition(partition).
 		SetupAccessibility(v1alpha1.AccessibilityTopology{Nodes: []string{volumeRequest.OwnerNodeName}}).
 		SetupVolumePath(types.ComposePoolVolumePath(types.GetLocalDiskPoolName(volumeRequest.DiskType), name)).
 		SetupStatus(v1alpha1.VolumeStateCreating).Build()
@@ -341,6 +393,144 @@ func (vm *localDiskVolumeManager) DeleteVolume(ctx context.Context, name string)
 	return volume.UpdateLocalDiskVolume()
 }
 
+// ExpandVolume reserves the required capacity for the volume, and waits until the volume is expanded on the node.
+// The request is rejected at once if the capacity can't be satisfied
+func (vm *localDiskVolumeManager) ExpandVolume(ctx context.Context, name string, requiredBytes int64) (int64, error) {
+	volume, err := vm.getVolume(name)
+	if err != nil {
+		return 0, err
+	}
+	if volume.Status.AllocatedCapacityBytes >= requiredBytes {
+		return volume.Status.AllocatedCapacityBytes, nil
+	}
+	logCtx := log.WithFields(log.Fields{"volume": name, "requiredBytes": requiredBytes})
+
+	expansion := volume.Status.Expansion
+	if expansion == nil || expansion.RequiredCapacityBytes != requiredBytes || expansion.State == v1alpha1.OperationStateFailed {
+		if err = vm.reserveExpansion(volume, requiredBytes); err != nil {
+			logCtx.WithError(err).Error("Failed to expand volume")
+			return 0, err
+		}
+		logCtx.Info("Start to expand volume")
+	}
+
+	vh, err := vm.newHandlerForVolume(name)
+	if err != nil {
+		return 0, err
+	}
+	if err = vh.WaitVolumeExpanded(ctx); err != nil {
+		if vh.Ldv.Status.Expansion != nil && vh.Ldv.Status.Expansion.State == v1alpha1.OperationStateFailed {
+			return 0, status.Error(codes.FailedPrecondition, err.Error())
+		}
+		return 0, status.Error(codes.Unavailable, err.Error())
+	}
+
+	return vh.Ldv.Status.AllocatedCapacityBytes, nil
+}
+
+// reserveExpansion checks whether the volume can be expanded, and records the expansion to the volume. The space
+// after the partition is reserved by the expansion, so that it's not allocated to the other partition volumes
+func (vm *localDiskVolumeManager) reserveExpansion(volume *v1alpha1.LocalDiskVolume, requiredBytes int64) error {
+	if len(volume.Spec.Accessibility.Nodes) == 0 {
+		return status.Errorf(codes.FailedPrecondition, "volume %s is not located on any node", volume.Name)
+	}
+	node := volume.Spec.Accessibility.Nodes[0]
+
+	if partition := volume.Status.Partition; partition != nil {
+		vm.partitionLock.Lock()
+		defer vm.partitionLock.Unlock()
+
+		extents, err := vm.getDiskFreeExtents(node, volume.Status.LocalDiskName, volume.Name)
+		if err != nil {
+			return err
+		}
+		var adjacentBytes int64
+		for _, extent := range extents {
+			if extent.StartBytes == partition.StartBytes+partition.SizeBytes {
+				adjacentBytes = extent.SizeBytes
+			}
+		}
+		if growBytes := types.AlignPartitionSize(requiredBytes) - partition.SizeBytes; growBytes > adjacentBytes {
+			return status.Errorf(codes.OutOfRange, "only %d bytes free after the partition of volume %s, %d bytes required",
+				adjacentBytes, volume.Name, growBytes)
+		}
+	} else {
+		localDisks, err := vm.dm.ListLocalDiskByNodeDevicePath(node, volume.Status.DevPath)
+		if err != nil {
+			return err
+		}
+		if len(localDisks) != 1 {
+			return status.Errorf(codes.FailedPrecondition, "no LocalDisk found for volume %s at %s/%s", volume.Name, node, volume.Status.DevPath)
+		}
+		if capacity := localDisks[0].Spec.Capacity; capacity < requiredBytes {
+			return status.Errorf(codes.OutOfRange, "capacity %d of disk %s is less than required %d, "+
+				"the whole disk volume can only be expanded after the disk is replaced by a larger one", capacity, localDisks[0].Name, requiredBytes)
+		}
+	}
+
+	volume.Spec.RequiredCapacityBytes = requiredBytes
+	volume.Status.Expansion = &v1alpha1.DiskVolumeExpansionStatus{
+		RequiredCapacityBytes: requiredBytes,
+		State:                 v1alpha1.OperationStateInProgress,
+	}
+	_, err := vm.updateVolume(volume)
+	return err
+}
+
+// getDiskFreeExtents returns the free extents of the LocalDisk on the node, excluding the ones allocated
+// to the volumes except the given volume
+func (vm *localDiskVolumeManager) getDiskFreeExtents(node, localDiskName, excludeVolume string) ([]v1alpha1.DiskExtent, error) {
+	nodeDisks, err := vm.dm.GetNodeDisks(node)
+	if err != nil {
+		return nil, err
+	}
+	allocatedPartitions, err := vm.listNodePartitions(node, excludeVolume)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, nodeDisk := range nodeDisks {
+		localDisk, err := vm.getLocalDiskByNodeDevicePath(nodeDisk.AttachNode, nodeDisk.DevPath)
+		if err != nil {
+			return nil, err
+		}
+		if localDisk.Name != localDiskName {
+			continue
+		}
+		extents := nodeDisk.PartitionExtents()
+		for _, partition := range allocatedPartitions[localDiskName] {
+			extents = types.SubtractExtent(extents, partition)
+		}
+		return extents, nil
+	}
+	return nil, status.Errorf(codes.FailedPrecondition, "disk %s not found on node %s", localDiskName, node)
+}
+
+// NodeExpandVolume resizes the filesystem of the volume after it's expanded
+func (vm *localDiskVolumeManager) NodeExpandVolume(ctx context.Context, volumeReq interface{}) (int64, error) {
+	r, ok := volumeReq.(*csi.NodeExpandVolumeRequest)
+	if !ok {
+		return 0, fmt.Errorf("NodeExpandVolumeRequest is not valid")
+	}
+
+	volume, err := vm.newHandlerForVolume(r.GetVolumeId())
+	if err != nil {
+		return 0, err
+	}
+	capacity := volume.Ldv.Status.AllocatedCapacityBytes
+	if capacity < r.GetCapacityRange().GetRequiredBytes() {
+		return 0, status.Errorf(codes.FailedPrecondition, "volume %s is not expanded to %d yet",
+			r.GetVolumeId(), r.GetCapacityRange().GetRequiredBytes())
+	}
+
+	// expand fs only when volumeMode is not block
+	if r.GetVolumeCapability().GetBlock() != nil {
+		return capacity, nil
+	}
+	volume.UpdateDevPathAccordingVolume()
+	return capacity, volume.ExpandFileSystem(r.GetVolumePath())
+}
+
 func (vm *localDiskVolumeManager) GetVolumeInfo(name string) (*types.Volume, error) {
 	volume := &types.Volume{}
 	exist, err := vm.VolumeIsExist(name)
@@ -475,6 +665,8 @@ func (vm *localDiskVolumeManager) ParseVolumeRequest(parameters interface{}) (*V
 	volumeRequest.SetDiskType(r.GetParameters()[VolumeParameterDiskTypeKey])
 	volumeRequest.SetPVCName(r.GetParameters()[VolumeParameterPVCNameKey])
 	volumeRequest.SetPVCNameSpace(r.GetParameters()[VolumeParameterPVCNameSpaceKey])
+	volumeRequest.SetSanitizePolicy(r.GetParameters()[VolumeParameterSanitizePolicy])
+	volumeRequest.SetAllocationMode(r.GetParameters()[VolumeParameterAllocationMode])
 	if r.AccessibilityRequirements != nil &&
 		len(r.AccessibilityRequirements.Requisite) == 1 {
 		if nodeName, ok := r.AccessibilityRequirements.Requisite[0].Segments[TopologyNodeKey]; ok {
@@ -577,16 +769,110 @@ func (vm *localDiskVolumeManager) findSuitableDisk(vq *VolumeRequest) (*types.Di
 	if err != nil {
 		return nil, err
 	}
+	// the partitions allocated recently may not be reported by the node yet, the disk is still available then
+	allocatedPartitions, err := vm.listNodePartitions(vq.OwnerNodeName, "")
+	if err != nil {
+		return nil, err
+	}
 	sort.Sort(utils.ByDiskSize(nodeAvailableDisks))
 	for _, availableDisk := range nodeAvailableDisks {
-		if availableDisk.DiskType == vq.DiskType && availableDisk.Capacity >= vq.RequireCapacity {
-			return &availableDisk, nil
+		if availableDisk.DiskType != vq.DiskType || availableDisk.Capacity < vq.RequireCapacity {
+			continue
 		}
-		continue
+		if len(allocatedPartitions) > 0 {
+			localDisk, err := vm.getLocalDiskByNodeDevicePath(availableDisk.AttachNode, availableDisk.DevPath)
+			if err != nil {
+				return nil, err
+			}
+			if len(allocatedPartitions[localDisk.Name]) > 0 {
+				continue
+			}
+		}
+		return &availableDisk, nil
 	}
 	return nil, nil
 }
 
+// findSuitablePartition finds the smallest free extent for the partition volume on the attach-node. The disks already
+// partitioned are preferred, so that the available disks are kept for the whole disk volumes
+func (vm *localDiskVolumeManager) findSuitablePartition(vq *VolumeRequest) (*types.Disk, *v1alpha1.DiskPartition, error) {
+	nodeDisks, err := vm.dm.GetNodeDisks(vq.OwnerNodeName)
+	if err != nil {
+		return nil, nil, err
+	}
+	// the partitions allocated recently may not be reported by the node yet
+	allocatedPartitions, err := vm.listNodePartitions(vq.OwnerNodeName, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	size := types.AlignPartitionSize(vq.RequireCapacity)
+	var selectedDisk *types.Disk
+	var selectedExtent v1alpha1.DiskExtent
+	for i, nodeDisk := range nodeDisks {
+		if nodeDisk.DiskType != vq.DiskType {
+			continue
+		}
+		extents := nodeDisk.PartitionExtents()
+		if len(extents) == 0 {
+			continue
+		}
+		if len(allocatedPartitions) > 0 {
+			localDisk, err := vm.getLocalDiskByNodeDevicePath(nodeDisk.AttachNode, nodeDisk.DevPath)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, partition := range allocatedPartitions[localDisk.Name] {
+				extents = types.SubtractExtent(extents, partition)
+			}
+		}
+
+		j := types.FindBestFitExtent(extents, size)
+		if j < 0 {
+			continue
+		}
+		if selectedDisk == nil || (nodeDisk.Partitioned && !selectedDisk.Partitioned) ||
+			(nodeDisk.Partitioned == selectedDisk.Partitioned && extents[j].SizeBytes < selectedExtent.SizeBytes) {
+			selectedDisk = &nodeDisks[i]
+			selectedExtent = extents[j]
+		}
+	}
+	if selectedDisk == nil {
+		return nil, nil, nil
+	}
+
+	return selectedDisk, &v1alpha1.DiskPartition{StartBytes: selectedExtent.StartBytes, SizeBytes: size}, nil
+}
+
+// listNodePartitions returns the space of the partitions of the volumes on the node except the given volume,
+// grouped by the LocalDisk. The space reserved by the expansion in progress is included
+func (vm *localDiskVolumeManager) listNodePartitions(node, excludeVolume string) (map[string][]v1alpha1.DiskExtent, error) {
+	client, err := vm.GetClient()
+	if err != nil {
+		return nil, err
+	}
+	volumes, err := client.List()
+	if err != nil {
+		return nil, err
+	}
+
+	partitions := map[string][]v1alpha1.DiskExtent{}
+	for _, volume := range volumes.Items {
+		if volume.Status.Partition == nil || volume.Name == excludeVolume ||
+			len(volume.Spec.Accessibility.Nodes) == 0 || volume.Spec.Accessibility.Nodes[0] != node {
+			continue
+		}
+		extent := v1alpha1.DiskExtent{StartBytes: volume.Status.Partition.StartBytes, SizeBytes: volume.Status.Partition.SizeBytes}
+		if expansion := volume.Status.Expansion; expansion != nil && expansion.State == v1alpha1.OperationStateInProgress {
+			if reserved := types.AlignPartitionSize(expansion.RequiredCapacityBytes); reserved > extent.SizeBytes {
+				extent.SizeBytes = reserved
+			}
+		}
+		partitions[volume.Status.LocalDiskName] = append(partitions[volume.Status.LocalDiskName], extent)
+	}
+	return partitions, nil
+}
+
 func (vm *localDiskVolumeManager) markNodeDiskInuse(node string, disk *types.Disk) error {
 	return vm.dm.MarkNodeDiskInuse(node, disk)
 }
@@ -4,8 +4,10 @@ import (
 	"github.com/hwameistor/hwameistor/pkg/apis/client/clientset/versioned/fake"
 	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/builder/localdiskvolume"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/member/controller/disk"
 	types2 "github.com/hwameistor/hwameistor/pkg/local-disk-manager/member/types"
 	"reflect"
+	"strings"
 
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -143,3 +145,221 @@ func Test_GetVolumeInfo(t *testing.T) {
 		})
 	}
 }
+
+type fakeDiskManager struct {
+	disk.Manager
+	disks        []types2.Disk
+	diskCapacity int64
+}
+
+func (m *fakeDiskManager) GetNodeDisks(node string) ([]types2.Disk, error) {
+	return m.disks, nil
+}
+
+func (m *fakeDiskManager) GetNodeAvailableDisks(node string) ([]types2.Disk, error) {
+	var disks []types2.Disk
+	for _, disk := range m.disks {
+		if disk.Status == types2.DiskStatusAvailable {
+			disks = append(disks, disk)
+		}
+	}
+	return disks, nil
+}
+
+func (m *fakeDiskManager) ListLocalDiskByNodeDevicePath(nodeName, devicePath string) ([]v1alpha1.LocalDisk, error) {
+	return []v1alpha1.LocalDisk{{
+		ObjectMeta: v1.ObjectMeta{Name: nodeName + strings.ReplaceAll(devicePath, "/dev/", "-")},
+		Spec:       v1alpha1.LocalDiskSpec{Capacity: m.diskCapacity},
+	}}, nil
+}
+
+func Test_findSuitablePartition(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	disks := []types2.Disk{
+		{AttachNode: fakeNodename, DevPath: "/dev/sdb", Capacity: 100 * gib, DiskType: fakeDiskType, Status: types2.DiskStatusAvailable},
+		{AttachNode: fakeNodename, DevPath: "/dev/sdc", Capacity: 100 * gib, DiskType: fakeDiskType, Partitioned: true,
+			FreeExtents: []v1alpha1.DiskExtent{{StartBytes: 50 * gib, SizeBytes: 20 * gib}, {StartBytes: 80 * gib, SizeBytes: 15 * gib}}},
+		{AttachNode: fakeNodename, DevPath: "/dev/sdd", Capacity: 100 * gib, DiskType: "SSD", Partitioned: true,
+			FreeExtents: []v1alpha1.DiskExtent{{StartBytes: 10 * gib, SizeBytes: 10 * gib}}},
+	}
+
+	testcases := []struct {
+		Description     string
+		RequireCapacity int64
+		Allocated       *v1alpha1.DiskPartition
+		ExpectDisk      string
+		ExpectPartition *v1alpha1.DiskPartition
+	}{
+		{
+			Description:     "prefer the smallest extent of the partitioned disk",
+			RequireCapacity: 10 * gib,
+			ExpectDisk:      "/dev/sdc",
+			ExpectPartition: &v1alpha1.DiskPartition{StartBytes: 80 * gib, SizeBytes: 10 * gib},
+		},
+		{
+			Description:     "skip the extent allocated to another volume",
+			RequireCapacity: 10 * gib,
+			Allocated:       &v1alpha1.DiskPartition{StartBytes: 80 * gib, SizeBytes: 10 * gib},
+			ExpectDisk:      "/dev/sdc",
+			ExpectPartition: &v1alpha1.DiskPartition{StartBytes: 50 * gib, SizeBytes: 10 * gib},
+		},
+		{
+			Description:     "use the available disk if no extent is large enough",
+			RequireCapacity: 30*gib + 1,
+			ExpectDisk:      "/dev/sdb",
+			ExpectPartition: &v1alpha1.DiskPartition{StartBytes: types2.PartitionAlignBytes, SizeBytes: 30*gib + types2.PartitionAlignBytes},
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.Description, func(t *testing.T) {
+			client, _ := CreateFakeKubeClient()
+			if testcase.Allocated != nil {
+				ldv := GenFakeLocalDiskVolumeObject()
+				ldv.Status.LocalDiskName = fakeNodename + "-sdc"
+				ldv.Status.Partition = testcase.Allocated
+				if _, err := client.Create(ldv); err != nil {
+					t.Fatal("create LocalDiskVolume failed")
+				}
+			}
+			ldvManager := localDiskVolumeManager{
+				dm:        &fakeDiskManager{disks: disks},
+				GetClient: func() (*localdiskvolume.Kubeclient, error) { return client, nil },
+			}
+
+			selectedDisk, partition, err := ldvManager.findSuitablePartition(&VolumeRequest{
+				RequireCapacity: testcase.RequireCapacity, DiskType: fakeDiskType, OwnerNodeName: fakeNodename})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if selectedDisk == nil || selectedDisk.DevPath != testcase.ExpectDisk {
+				t.Fatalf("Expect disk %s, got %v", testcase.ExpectDisk, selectedDisk)
+			}
+			if !reflect.DeepEqual(partition, testcase.ExpectPartition) {
+				t.Fatalf("Expect partition %v, got %v", testcase.ExpectPartition, partition)
+			}
+		})
+	}
+}
+
+func Test_findSuitableDisk(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	disks := []types2.Disk{
+		{AttachNode: fakeNodename, DevPath: "/dev/sdb", Capacity: 100 * gib, DiskType: fakeDiskType, Status: types2.DiskStatusAvailable},
+		{AttachNode: fakeNodename, DevPath: "/dev/sdc", Capacity: 200 * gib, DiskType: fakeDiskType, Status: types2.DiskStatusAvailable},
+	}
+
+	client, _ := CreateFakeKubeClient()
+	ldvManager := localDiskVolumeManager{
+		dm:        &fakeDiskManager{disks: disks},
+		GetClient: func() (*localdiskvolume.Kubeclient, error) { return client, nil },
+	}
+	request := &VolumeRequest{RequireCapacity: 50 * gib, DiskType: fakeDiskType, OwnerNodeName: fakeNodename}
+
+	selectedDisk, err := ldvManager.findSuitableDisk(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selectedDisk == nil || selectedDisk.DevPath != "/dev/sdb" {
+		t.Fatalf("Expect disk /dev/sdb, got %v", selectedDisk)
+	}
+
+	// the partition volume just allocated on the disk isn't reported by the node yet
+	ldv := GenFakeLocalDiskVolumeObject()
+	ldv.Spec.Accessibility.Nodes = []string{fakeNodename}
+	ldv.Status.LocalDiskName = fakeNodename + "-sdb"
+	ldv.Status.Partition = &v1alpha1.DiskPartition{StartBytes: types2.PartitionAlignBytes, SizeBytes: 10 * gib}
+	if _, err = client.Create(ldv); err != nil {
+		t.Fatal("create LocalDiskVolume failed")
+	}
+	selectedDisk, err = ldvManager.findSuitableDisk(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if selectedDisk == nil || selectedDisk.DevPath != "/dev/sdc" {
+		t.Fatalf("Expect disk /dev/sdc, got %v", selectedDisk)
+	}
+}
+
+func Test_reserveExpansion(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	disks := []types2.Disk{
+		{AttachNode: fakeNodename, DevPath: "/dev/sdc", Capacity: 100 * gib, DiskType: fakeDiskType, Partitioned: true,
+			FreeExtents: []v1alpha1.DiskExtent{{StartBytes: 11 * gib, SizeBytes: 30 * gib}}},
+	}
+
+	testcases := []struct {
+		Description  string
+		Partition    *v1alpha1.DiskPartition
+		Reserved     *v1alpha1.DiskPartition
+		RequireBytes int64
+		ExpectError  bool
+	}{
+		{
+			Description:  "grow the partition into the free space after it",
+			Partition:    &v1alpha1.DiskPartition{StartBytes: gib, SizeBytes: 10 * gib},
+			RequireBytes: 40 * gib,
+		},
+		{
+			Description:  "no enough free space after the partition",
+			Partition:    &v1alpha1.DiskPartition{StartBytes: gib, SizeBytes: 10 * gib},
+			RequireBytes: 40*gib + 1,
+			ExpectError:  true,
+		},
+		{
+			Description:  "the free space after the partition is allocated to another volume",
+			Partition:    &v1alpha1.DiskPartition{StartBytes: gib, SizeBytes: 10 * gib},
+			Reserved:     &v1alpha1.DiskPartition{StartBytes: 11 * gib, SizeBytes: gib},
+			RequireBytes: 20 * gib,
+			ExpectError:  true,
+		},
+		{
+			Description:  "the whole disk volume is larger than the disk",
+			RequireBytes: 100*gib + 1,
+			ExpectError:  true,
+		},
+		{
+			Description:  "the whole disk volume takes the replaced larger disk",
+			RequireBytes: 100 * gib,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.Description, func(t *testing.T) {
+			client, _ := CreateFakeKubeClient()
+			if testcase.Reserved != nil {
+				ldv := GenFakeLocalDiskVolumeObject()
+				ldv.Name = "reserved"
+				ldv.Status.LocalDiskName = fakeNodename + "-sdc"
+				ldv.Status.Partition = testcase.Reserved
+				if _, err := client.Create(ldv); err != nil {
+					t.Fatal("create LocalDiskVolume failed")
+				}
+			}
+			ldv := GenFakeLocalDiskVolumeObject()
+			ldv.Status.LocalDiskName = fakeNodename + "-sdc"
+			ldv.Status.Partition = testcase.Partition
+			ldv, err := client.Create(ldv)
+			if err != nil {
+				t.Fatal("create LocalDiskVolume failed")
+			}
+			ldvManager := localDiskVolumeManager{
+				dm:        &fakeDiskManager{disks: disks, diskCapacity: 100 * gib},
+				GetClient: func() (*localdiskvolume.Kubeclient, error) { return client, nil },
+			}
+
+			err = ldvManager.reserveExpansion(ldv, testcase.RequireBytes)
+			if (err != nil) != testcase.ExpectError {
+				t.Fatalf("Expect error %v, got %v", testcase.ExpectError, err)
+			}
+			if err != nil {
+				return
+			}
+			volume, _ := client.Get(ldv.Name)
+			if volume.Status.Expansion == nil || volume.Status.Expansion.State != v1alpha1.OperationStateInProgress ||
+				volume.Status.Expansion.RequiredCapacityBytes != testcase.RequireBytes {
+				t.Fatalf("Expect expansion in progress, got %v", volume.Status.Expansion)
+			}
+		})
+	}
+}
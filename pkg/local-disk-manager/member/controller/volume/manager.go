@@ -26,6 +26,12 @@ type Manager interface {
 	// DeleteVolume
 	DeleteVolume(ctx context.Context, name string) error
 
+	// ExpandVolume grow the volume to the required capacity, and returns the capacity of the volume
+	ExpandVolume(ctx context.Context, name string, requiredBytes int64) (int64, error)
+
+	// NodeExpandVolume resize the filesystem of the volume, and returns the capacity of the volume
+	NodeExpandVolume(ctx context.Context, volumeRequest interface{}) (int64, error)
+
 	// GetVolumeInfo
 	GetVolumeInfo(name string) (*types.Volume, error)
 
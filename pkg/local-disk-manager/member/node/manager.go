@@ -11,6 +11,7 @@ import (
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/member/types"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/utils"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/utils/kubernetes"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/utils/sys"
 	"github.com/hwameistor/hwameistor/pkg/local-storage/common"
 	utils2 "github.com/hwameistor/hwameistor/pkg/utils"
 	log "github.com/sirupsen/logrus"
@@ -287,6 +288,39 @@ var findDiskState = func(devPath string, inuseDisks []string) apisv1alpha1.State
 	return apisv1alpha1.DiskStateAvailable
 }
 
+// listDiskPartitions lists the partitions on the disk, can be replaced in tests
+var listDiskPartitions = func(devPath string) ([]sys.Partition, error) {
+	device, err := sys.NewSysFsDeviceFromDevPath(devPath)
+	if err != nil {
+		return nil, err
+	}
+	return device.ListPartitions()
+}
+
+// setupPartitionedDisk marks the disk partitioned if there are partitions on it, and computes its free extents.
+// The partitions on the disk used by a whole disk volume belong to the application, so it's skipped
+func setupPartitionedDisk(disk *apisv1alpha1.LocalDevice) {
+	if disk.State != apisv1alpha1.DiskStateAvailable {
+		return
+	}
+	partitions, err := listDiskPartitions(disk.DevPath)
+	if err != nil {
+		log.WithError(err).WithField("disk", disk.DevPath).Error("Failed to list partitions")
+		return
+	}
+	if len(partitions) == 0 {
+		return
+	}
+
+	var used []apisv1alpha1.DiskExtent
+	for _, partition := range partitions {
+		used = append(used, apisv1alpha1.DiskExtent{StartBytes: partition.StartBytes, SizeBytes: partition.SizeBytes})
+	}
+	disk.State = apisv1alpha1.DiskStateInUse
+	disk.Partitioned = true
+	disk.FreeExtents = types.ComputeFreeExtents(disk.CapacityBytes, used)
+}
+
 // rebuildLocalPools according discovery disks and volumes
 func (m *nodeManager) rebuildLocalPools() {
 	m.lock.Lock()
@@ -305,7 +339,7 @@ func (m *nodeManager) rebuildLocalPools() {
 
 		// rebuild discovery disks
 		var discoveryDisks []apisv1alpha1.LocalDevice
-		var totalCapacity, maxCapacity int64
+		var totalCapacity, maxCapacity, freeVolumeCount int64
 		for _, classDisk := range m.registryManager.ListDisksByType(devType) {
 			discoveryDisk := apisv1alpha1.LocalDevice{
 				DevPath:       classDisk.DevPath,
@@ -313,8 +347,19 @@ func (m *nodeManager) rebuildLocalPools() {
 				CapacityBytes: classDisk.Capacity,
 				State:         findDiskState(classDisk.DevPath, inuseDisks),
 			}
-			if discoveryDisk.State == apisv1alpha1.DiskStateAvailable && maxCapacity < classDisk.Capacity {
-				maxCapacity = classDisk.Capacity
+			// the disk shared by the partition volumes is in use, but can still hold new volumes in its free extents
+			setupPartitionedDisk(&discoveryDisk)
+			if discoveryDisk.State == apisv1alpha1.DiskStateAvailable {
+				freeVolumeCount++
+				if maxCapacity < classDisk.Capacity {
+					maxCapacity = classDisk.Capacity
+				}
+			}
+			if maxExtent := types.MaxExtentSize(discoveryDisk.FreeExtents); maxExtent > 0 {
+				freeVolumeCount++
+				if maxCapacity < maxExtent {
+					maxCapacity = maxExtent
+				}
 			}
 			totalCapacity += classDisk.Capacity
 			discoveryDisks = append(discoveryDisks, discoveryDisk)
@@ -335,10 +380,10 @@ func (m *nodeManager) rebuildLocalPools() {
 		m.pools[poolName].TotalCapacityBytes = totalCapacity
 		m.pools[poolName].UsedCapacityBytes = usedCapacity
 		m.pools[poolName].FreeCapacityBytes = totalCapacity - usedCapacity
-		m.pools[poolName].TotalVolumeCount = int64(len(discoveryDisks))
+		m.pools[poolName].TotalVolumeCount = int64(len(discoveryVolumes)) + freeVolumeCount
 		m.pools[poolName].UsedVolumeCount = int64(len(discoveryVolumes))
 		m.pools[poolName].VolumeCapacityBytesLimit = maxCapacity
-		m.pools[poolName].FreeVolumeCount = int64(len(discoveryDisks) - len(discoveryVolumes))
+		m.pools[poolName].FreeVolumeCount = freeVolumeCount
 	}
 }
 
@@ -1,6 +1,7 @@
 package volume
 
 import (
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/member/types"
 	"k8s.io/kubernetes/pkg/volume/util/hostutil"
 	"os"
@@ -15,6 +16,15 @@ type Manager interface {
 	// DeleteVolume delete volume from pool and release bound disk
 	DeleteVolume(name string, pool string) error
 
+	// CreatePartitionVolume create volume from a new partition on device exist in pool
+	CreatePartitionVolume(name string, pool string, device string, partition *v1alpha1.DiskPartition) error
+
+	// ExpandPartitionVolume grow the partition of the volume to the size
+	ExpandPartitionVolume(name string, pool string, partition *v1alpha1.DiskPartition, sizeBytes int64) error
+
+	// DeletePartitionVolume delete volume from pool and remove the partition
+	DeletePartitionVolume(name string, pool string, partition *v1alpha1.DiskPartition) error
+
 	// GetVolume return info about this volume
 	GetVolume(name string) *types.Volume
 }
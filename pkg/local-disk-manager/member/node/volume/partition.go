@@ -0,0 +1,282 @@
+package volume
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/member/types"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/utils"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/utils/sys"
+)
+
+// maxPartitionNameLength is the max length of the GPT partition name
+const maxPartitionNameLength = 36
+
+// runCommand runs the command and returns its output, can be replaced in tests
+var runCommand = func(name string, args ...string) (string, error) {
+	return utils.RunCommand(context.Background(), name, args...)
+}
+
+// listPartitions lists the partitions on the disk, can be replaced in tests
+var listPartitions = func(diskDevPath string) ([]sys.Partition, error) {
+	device, err := sys.NewSysFsDeviceFromDevPath(diskDevPath)
+	if err != nil {
+		return nil, err
+	}
+	return device.ListPartitions()
+}
+
+// getParentDevPath returns the disk which the partition is on, can be replaced in tests
+var getParentDevPath = sys.GetParentDevPath
+
+// CreatePartitionVolume creates the partition on the device in the pool, and the volume symlink to the partition.
+// The number and the path of the created partition are set to the partition
+func (v *volume) CreatePartitionVolume(volume string, pool string, device string, partition *v1alpha1.DiskPartition) error {
+	logCtx := log.WithFields(log.Fields{"volume": volume, "device": device, "start": partition.StartBytes, "size": partition.SizeBytes})
+	if device == "" {
+		return fmt.Errorf("no device found in pool %s for volume %s", pool, volume)
+	}
+
+	// the device in pool links to the stable link of the disk, e.g. /dev/disk/by-id/xxx,
+	// and udev creates the link of the partition as /dev/disk/by-id/xxx-part1
+	diskLink := types.ComposePoolDevicePath(pool, device)
+	diskStableLink, err := os.Readlink(diskLink)
+	if err != nil {
+		return err
+	}
+	diskDevPath, err := filepath.EvalSymlinks(diskLink)
+	if err != nil {
+		return err
+	}
+
+	number, err := findOrCreatePartition(volume, diskDevPath, partition)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to create partition")
+		return err
+	}
+	partitionLink := fmt.Sprintf("%s-part%d", diskStableLink, number)
+	if _, err = runCommand("udevadm", "settle", "--exit-if-exists="+partitionLink); err != nil {
+		return err
+	}
+	partitionDevPath, err := filepath.EvalSymlinks(partitionLink)
+	if err != nil {
+		return err
+	}
+
+	volumePath := types.ComposePoolVolumePath(pool, volume)
+	exist, err := v.hu.PathExists(volumePath)
+	if err != nil {
+		return err
+	}
+	if !exist {
+		if err = os.Symlink(partitionLink, volumePath); err != nil {
+			return err
+		}
+	}
+
+	partition.Number = number
+	partition.DevPath = partitionDevPath
+	logCtx.WithFields(log.Fields{"partition": partitionDevPath}).Info("Succeed to create partition volume")
+	return nil
+}
+
+// findOrCreatePartition returns the number of the partition at the start of the disk, and creates it if not exists
+func findOrCreatePartition(volume string, diskDevPath string, partition *v1alpha1.DiskPartition) (int, error) {
+	partitions, err := listPartitions(diskDevPath)
+	if err != nil {
+		return 0, err
+	}
+	if number := findPartitionByStart(partitions, partition.StartBytes); number > 0 {
+		return number, nil
+	}
+	for _, p := range partitions {
+		if p.StartBytes < partition.StartBytes+partition.SizeBytes && partition.StartBytes < p.StartBytes+p.SizeBytes {
+			return 0, fmt.Errorf("partition %s overlaps with the required space", p.Name)
+		}
+	}
+
+	if err = ensurePartitionTable(diskDevPath); err != nil {
+		return 0, err
+	}
+	name := volume
+	if len(name) > maxPartitionNameLength {
+		name = name[:maxPartitionNameLength]
+	}
+	if _, err = runCommand("parted", "-s", "-a", "none", diskDevPath, "unit", "B", "mkpart", name,
+		strconv.FormatInt(partition.StartBytes, 10), strconv.FormatInt(partition.StartBytes+partition.SizeBytes-1, 10)); err != nil {
+		return 0, err
+	}
+	if _, err = runCommand("udevadm", "settle"); err != nil {
+		return 0, err
+	}
+
+	if partitions, err = listPartitions(diskDevPath); err != nil {
+		return 0, err
+	}
+	number := findPartitionByStart(partitions, partition.StartBytes)
+	if number == 0 {
+		return 0, fmt.Errorf("partition at %d not found on %s after created", partition.StartBytes, diskDevPath)
+	}
+
+	// remove the signatures left on the space by the previous volume
+	partitionDevPath := "/dev/" + findPartitionName(partitions, number)
+	if _, err = runCommand("wipefs", "-af", partitionDevPath); err != nil {
+		return 0, err
+	}
+	return number, nil
+}
+
+// ensurePartitionTable creates the GPT partition table on the disk if there is no partition table.
+// The disk used by the whole disk volume is never partitioned
+func ensurePartitionTable(diskDevPath string) error {
+	out, err := runCommand("blkid", "-p", "-o", "export", diskDevPath)
+	// blkid exits with 2 if no signature found
+	if err != nil && !isNoSignatureError(err) {
+		return err
+	}
+
+	signatures := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		if kv := strings.SplitN(strings.TrimSpace(line), "=", 2); len(kv) == 2 {
+			signatures[kv[0]] = kv[1]
+		}
+	}
+	if fsType, exists := signatures["TYPE"]; exists {
+		return fmt.Errorf("disk %s is in use by %s, can't be partitioned", diskDevPath, fsType)
+	}
+	switch signatures["PTTYPE"] {
+	case "gpt":
+		return nil
+	case "":
+		_, err = runCommand("parted", "-s", diskDevPath, "mklabel", "gpt")
+		return err
+	default:
+		return fmt.Errorf("disk %s has %s partition table, only gpt is supported", diskDevPath, signatures["PTTYPE"])
+	}
+}
+
+func isNoSignatureError(err error) bool {
+	return strings.Contains(err.Error(), "exit status 2")
+}
+
+func findPartitionByStart(partitions []sys.Partition, startBytes int64) int {
+	for _, p := range partitions {
+		if p.StartBytes == startBytes {
+			return p.Number
+		}
+	}
+	return 0
+}
+
+func findPartitionName(partitions []sys.Partition, number int) string {
+	for _, p := range partitions {
+		if p.Number == number {
+			return p.Name
+		}
+	}
+	return ""
+}
+
+// ExpandPartitionVolume grows the partition of the volume to the size, into the free space after it
+func (v *volume) ExpandPartitionVolume(volume string, pool string, partition *v1alpha1.DiskPartition, sizeBytes int64) error {
+	logCtx := log.WithFields(log.Fields{"volume": volume, "start": partition.StartBytes, "size": sizeBytes})
+	partitionDevPath, err := filepath.EvalSymlinks(types.ComposePoolVolumePath(pool, volume))
+	if err != nil {
+		return err
+	}
+	diskDevPath, err := getParentDevPath(partitionDevPath)
+	if err != nil {
+		return err
+	}
+	partitions, err := listPartitions(diskDevPath)
+	if err != nil {
+		return err
+	}
+
+	number := findPartitionByStart(partitions, partition.StartBytes)
+	if number == 0 || "/dev/"+findPartitionName(partitions, number) != partitionDevPath {
+		return fmt.Errorf("partition %s is not at %d of disk %s", partitionDevPath, partition.StartBytes, diskDevPath)
+	}
+	for _, p := range partitions {
+		if p.Number == number {
+			if p.SizeBytes >= sizeBytes {
+				return nil
+			}
+			continue
+		}
+		if p.StartBytes < partition.StartBytes+sizeBytes && partition.StartBytes < p.StartBytes+p.SizeBytes {
+			return fmt.Errorf("partition %s overlaps with the required space", p.Name)
+		}
+	}
+
+	// the kernel is informed of the new size, so the partition can be resized while it's in use
+	if _, err = runCommand("parted", "-s", "-a", "none", diskDevPath, "unit", "B", "resizepart", strconv.Itoa(number),
+		strconv.FormatInt(partition.StartBytes+sizeBytes-1, 10)); err != nil {
+		logCtx.WithError(err).Error("Failed to resize partition")
+		return err
+	}
+	if _, err = runCommand("udevadm", "settle"); err != nil {
+		return err
+	}
+
+	logCtx.Info("Succeed to expand partition volume")
+	return nil
+}
+
+// DeletePartitionVolume wipes and removes the partition of the volume, and the volume symlink
+func (v *volume) DeletePartitionVolume(volume string, pool string, partition *v1alpha1.DiskPartition) error {
+	logCtx := log.WithFields(log.Fields{"volume": volume, "start": partition.StartBytes, "size": partition.SizeBytes})
+	volumePath := types.ComposePoolVolumePath(pool, volume)
+	if _, err := os.Lstat(volumePath); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	partitionDevPath, err := filepath.EvalSymlinks(volumePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	// the partition is removed already if the link doesn't exist
+	if err == nil {
+		if err = removePartition(partitionDevPath, partition); err != nil {
+			logCtx.WithError(err).Error("Failed to remove partition")
+			return err
+		}
+	}
+
+	logCtx.Info("Succeed to delete partition volume")
+	return os.Remove(volumePath)
+}
+
+func removePartition(partitionDevPath string, partition *v1alpha1.DiskPartition) error {
+	diskDevPath, err := getParentDevPath(partitionDevPath)
+	if err != nil {
+		return err
+	}
+	partitions, err := listPartitions(diskDevPath)
+	if err != nil {
+		return err
+	}
+	number := findPartitionByStart(partitions, partition.StartBytes)
+	if number == 0 || "/dev/"+findPartitionName(partitions, number) != partitionDevPath {
+		return fmt.Errorf("partition %s is not at %d of disk %s", partitionDevPath, partition.StartBytes, diskDevPath)
+	}
+
+	if _, err = runCommand("wipefs", "-af", partitionDevPath); err != nil {
+		return err
+	}
+	if _, err = runCommand("parted", "-s", diskDevPath, "rm", strconv.Itoa(number)); err != nil {
+		return err
+	}
+	_, err = runCommand("udevadm", "settle")
+	return err
+}
@@ -1,5 +1,7 @@
 package types
 
+import "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+
 type DiskStatus = string
 
 const (
@@ -33,4 +35,21 @@ type Disk struct {
 
 	// Status
 	Status DiskStatus `json:"status,omitempty"`
+
+	// Partitioned represents the disk is shared by the partition volumes
+	Partitioned bool `json:"partitioned,omitempty"`
+
+	// FreeExtents is the free space of the partitioned disk
+	FreeExtents []v1alpha1.DiskExtent `json:"freeExtents,omitempty"`
+}
+
+// PartitionExtents returns the free space to create partition volumes on the disk
+func (d *Disk) PartitionExtents() []v1alpha1.DiskExtent {
+	if d.Partitioned {
+		return d.FreeExtents
+	}
+	if d.Status == DiskStatusAvailable {
+		return []v1alpha1.DiskExtent{WholeDiskExtent(d.Capacity)}
+	}
+	return nil
 }
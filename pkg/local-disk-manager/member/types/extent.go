@@ -0,0 +1,103 @@
+package types
+
+import (
+	"sort"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+// PartitionAlignBytes is the alignment of the partitions on the disk. The first and the last
+// aligned unit of the disk are reserved for the GPT header and its backup
+const PartitionAlignBytes int64 = 1024 * 1024
+
+// AlignPartitionSize rounds the size up to the partition alignment
+func AlignPartitionSize(sizeBytes int64) int64 {
+	return (sizeBytes + PartitionAlignBytes - 1) / PartitionAlignBytes * PartitionAlignBytes
+}
+
+// WholeDiskExtent returns the space of the disk that can be partitioned
+func WholeDiskExtent(capacityBytes int64) v1alpha1.DiskExtent {
+	end := capacityBytes/PartitionAlignBytes*PartitionAlignBytes - PartitionAlignBytes
+	if end <= PartitionAlignBytes {
+		return v1alpha1.DiskExtent{StartBytes: PartitionAlignBytes}
+	}
+	return v1alpha1.DiskExtent{StartBytes: PartitionAlignBytes, SizeBytes: end - PartitionAlignBytes}
+}
+
+// ComputeFreeExtents returns the free space of the disk which is not used by the partitions, ordered by the offset
+func ComputeFreeExtents(capacityBytes int64, partitions []v1alpha1.DiskExtent) []v1alpha1.DiskExtent {
+	free := []v1alpha1.DiskExtent{WholeDiskExtent(capacityBytes)}
+	for _, partition := range partitions {
+		free = SubtractExtent(free, partition)
+	}
+	return free
+}
+
+// SubtractExtent removes the used extent from the free extents. The remaining extents are aligned,
+// and the ones smaller than the alignment are dropped
+func SubtractExtent(free []v1alpha1.DiskExtent, used v1alpha1.DiskExtent) []v1alpha1.DiskExtent {
+	usedEnd := used.StartBytes + used.SizeBytes
+	var result []v1alpha1.DiskExtent
+	for _, extent := range free {
+		end := extent.StartBytes + extent.SizeBytes
+		if usedEnd <= extent.StartBytes || used.StartBytes >= end {
+			result = appendAlignedExtent(result, extent.StartBytes, end)
+			continue
+		}
+		result = appendAlignedExtent(result, extent.StartBytes, used.StartBytes)
+		result = appendAlignedExtent(result, usedEnd, end)
+	}
+	return result
+}
+
+func appendAlignedExtent(extents []v1alpha1.DiskExtent, start, end int64) []v1alpha1.DiskExtent {
+	start = AlignPartitionSize(start)
+	end = end / PartitionAlignBytes * PartitionAlignBytes
+	if end-start < PartitionAlignBytes {
+		return extents
+	}
+	return append(extents, v1alpha1.DiskExtent{StartBytes: start, SizeBytes: end - start})
+}
+
+// FindBestFitExtent returns the index of the smallest extent which can hold the size, -1 if not found
+func FindBestFitExtent(extents []v1alpha1.DiskExtent, sizeBytes int64) int {
+	index := -1
+	for i, extent := range extents {
+		if extent.SizeBytes < sizeBytes {
+			continue
+		}
+		if index < 0 || extent.SizeBytes < extents[index].SizeBytes {
+			index = i
+		}
+	}
+	return index
+}
+
+// MaxExtentSize returns the size of the largest extent
+func MaxExtentSize(extents []v1alpha1.DiskExtent) int64 {
+	var maxSize int64
+	for _, extent := range extents {
+		if extent.SizeBytes > maxSize {
+			maxSize = extent.SizeBytes
+		}
+	}
+	return maxSize
+}
+
+// FitPartitions returns true if all the partitions can be allocated from the free extents. The larger
+// partition is allocated first from the smallest extent that can hold it
+func FitPartitions(extents []v1alpha1.DiskExtent, sizes []int64) bool {
+	free := append([]v1alpha1.DiskExtent{}, extents...)
+	sorted := append([]int64{}, sizes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] > sorted[j] })
+	for _, size := range sorted {
+		size = AlignPartitionSize(size)
+		i := FindBestFitExtent(free, size)
+		if i < 0 {
+			return false
+		}
+		free[i].StartBytes += size
+		free[i].SizeBytes -= size
+	}
+	return true
+}
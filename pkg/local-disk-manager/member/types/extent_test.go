@@ -0,0 +1,101 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+const mib = PartitionAlignBytes
+
+func TestComputeFreeExtents(t *testing.T) {
+	testCases := []struct {
+		description string
+		capacity    int64
+		partitions  []v1alpha1.DiskExtent
+		expect      []v1alpha1.DiskExtent
+	}{
+		{
+			description: "empty disk",
+			capacity:    100 * mib,
+			expect:      []v1alpha1.DiskExtent{{StartBytes: mib, SizeBytes: 98 * mib}},
+		},
+		{
+			description: "unaligned capacity",
+			capacity:    100*mib + 4096,
+			expect:      []v1alpha1.DiskExtent{{StartBytes: mib, SizeBytes: 98 * mib}},
+		},
+		{
+			description: "partitions at the start and in the middle",
+			capacity:    100 * mib,
+			partitions: []v1alpha1.DiskExtent{
+				{StartBytes: mib, SizeBytes: 10 * mib},
+				{StartBytes: 50 * mib, SizeBytes: 20 * mib},
+			},
+			expect: []v1alpha1.DiskExtent{
+				{StartBytes: 11 * mib, SizeBytes: 39 * mib},
+				{StartBytes: 70 * mib, SizeBytes: 29 * mib},
+			},
+		},
+		{
+			description: "full disk",
+			capacity:    100 * mib,
+			partitions:  []v1alpha1.DiskExtent{{StartBytes: mib, SizeBytes: 98 * mib}},
+		},
+		{
+			description: "unaligned partition",
+			capacity:    100 * mib,
+			partitions:  []v1alpha1.DiskExtent{{StartBytes: 2048 * 512, SizeBytes: 10*mib + 512}},
+			expect:      []v1alpha1.DiskExtent{{StartBytes: 12 * mib, SizeBytes: 87 * mib}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			free := ComputeFreeExtents(testCase.capacity, testCase.partitions)
+			if !reflect.DeepEqual(free, testCase.expect) {
+				t.Errorf("Expect free extents %v, got %v", testCase.expect, free)
+			}
+		})
+	}
+}
+
+func TestFindBestFitExtent(t *testing.T) {
+	extents := []v1alpha1.DiskExtent{
+		{StartBytes: mib, SizeBytes: 50 * mib},
+		{StartBytes: 60 * mib, SizeBytes: 10 * mib},
+		{StartBytes: 80 * mib, SizeBytes: 20 * mib},
+	}
+	if i := FindBestFitExtent(extents, 15*mib); i != 2 {
+		t.Errorf("Expect the 20MiB extent, got %d", i)
+	}
+	if i := FindBestFitExtent(extents, 10*mib); i != 1 {
+		t.Errorf("Expect the 10MiB extent, got %d", i)
+	}
+	if i := FindBestFitExtent(extents, 60*mib); i != -1 {
+		t.Errorf("Expect no extent, got %d", i)
+	}
+	if size := MaxExtentSize(extents); size != 50*mib {
+		t.Errorf("Expect max extent 50MiB, got %d", size)
+	}
+}
+
+func TestFitPartitions(t *testing.T) {
+	extents := []v1alpha1.DiskExtent{
+		{StartBytes: mib, SizeBytes: 30 * mib},
+		{StartBytes: 40 * mib, SizeBytes: 20 * mib},
+	}
+	if !FitPartitions(extents, []int64{10 * mib, 20 * mib, 20 * mib}) {
+		t.Error("Expect partitions fit")
+	}
+	if FitPartitions(extents, []int64{25 * mib, 25 * mib}) {
+		t.Error("Expect partitions not fit")
+	}
+	if FitPartitions(extents, []int64{10 * mib, 20*mib + 1, 20 * mib}) {
+		t.Error("Expect aligned partitions not fit")
+	}
+	if extents[0].SizeBytes != 30*mib {
+		t.Error("Expect extents not changed")
+	}
+}
@@ -0,0 +1,263 @@
+package raid
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/disk/manager"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/smart"
+)
+
+const (
+	// DefaultSyncPeriod is the period to sync the RAID info of the disks
+	DefaultSyncPeriod = 5 * time.Minute
+	// DefaultSmartCheckPeriod is the period to check the S.M.A.R.T health of the member drives, same as the one
+	// of the disks, as smartctl is much more expensive than storcli and the health changes slowly
+	DefaultSmartCheckPeriod = 6 * time.Hour
+
+	// the physical drive is online in the virtual drive
+	pdStateOnline = "Onln"
+)
+
+// Monitor maps the virtual drives on the RAID controllers to the LocalDisks on the node,
+// and keeps the RAID info and conditions of the LocalDisks up to date
+type Monitor struct {
+	client   client.Client
+	nodeName string
+	manager  Manager
+
+	// smartHealth gets the S.M.A.R.T self-assessment of a member drive through the disk of its virtual drive
+	smartHealth func(devName, smartDevice string) (bool, error)
+	// smartCheckPeriod is how long the S.M.A.R.T health of a member drive is cached
+	smartCheckPeriod time.Duration
+	// memberHealths caches the S.M.A.R.T health of the member drives, it's only accessed by Sync
+	memberHealths map[string]memberHealth
+	now           func() time.Time
+}
+
+// memberHealth is the cached S.M.A.R.T health of a member drive
+type memberHealth struct {
+	// state is the state of the member drive when checked, the drive is checked again once it changes
+	state     string
+	health    v1alpha1.SmartAssessResult
+	checkTime time.Time
+}
+
+func NewMonitor(cli client.Client, nodeName string, manager Manager) *Monitor {
+	return &Monitor{
+		client:           cli,
+		nodeName:         nodeName,
+		manager:          manager,
+		smartHealth:      memberSmartHealth,
+		smartCheckPeriod: DefaultSmartCheckPeriod,
+		memberHealths:    map[string]memberHealth{},
+		now:              time.Now,
+	}
+}
+
+// StartTimerSync syncs the RAID info of the disks periodically
+func (m *Monitor) StartTimerSync(ctx context.Context, syncPeriod time.Duration) {
+	log.WithField("syncPeriod", syncPeriod).Info("Start RAID timer sync")
+	go wait.Until(func() { _ = m.Sync() }, syncPeriod, ctx.Done())
+
+	<-ctx.Done()
+}
+
+// Sync lists the virtual drives on the node, and updates the RAID info of the LocalDisks they are seen as
+func (m *Monitor) Sync() error {
+	logCtx := log.WithField("node", m.nodeName)
+	count, err := m.manager.GetControllerCount()
+	if err != nil {
+		// storcli is not installed, or there is no controller supported by it
+		logCtx.WithError(err).Debug("No RAID controller found")
+		return nil
+	}
+	if count == 0 {
+		return nil
+	}
+
+	controllers, err := m.manager.ListControllers()
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to list RAID controllers")
+		return err
+	}
+
+	// drop the expired S.M.A.R.T health, including the one of the member drives gone
+	for key, cached := range m.memberHealths {
+		if m.now().Sub(cached.checkTime) >= m.smartCheckPeriod {
+			delete(m.memberHealths, key)
+		}
+	}
+
+	diskList := &v1alpha1.LocalDiskList{}
+	if err = m.client.List(context.TODO(), diskList, client.MatchingFields{"spec.nodeName": m.nodeName}); err != nil {
+		logCtx.WithError(err).Error("Failed to list LocalDisks")
+		return err
+	}
+
+	for i := range diskList.Items {
+		disk := &diskList.Items[i]
+		ctrl, vd := findVirtualDrive(controllers, disk)
+		if vd == nil {
+			continue
+		}
+		if err = m.updateDiskRAID(disk, m.raidInfo(ctrl, vd)); err != nil {
+			logCtx.WithFields(log.Fields{"disk": disk.Name, "virtualDrive": vd.Path}).WithError(err).Error("Failed to update the RAID info of LocalDisk")
+			return err
+		}
+	}
+	return nil
+}
+
+// raidInfo gets the RAID info of the virtual drive, with the S.M.A.R.T self-assessment of each member drive
+func (m *Monitor) raidInfo(ctrl *Controller, vd *VirtualDrive) v1alpha1.RAIDInfo {
+	info := NewRAIDInfo(ctrl, vd)
+	for i := range info.Members {
+		member := &info.Members[i]
+		member.OverallHealth = m.memberSmartHealth(ctrl, vd, member)
+	}
+	return info
+}
+
+// memberSmartHealth returns the cached S.M.A.R.T health of the member drive, and checks it again if the cache is
+// expired or the drive has changed. The failure is also cached, it's not retried at every sync
+func (m *Monitor) memberSmartHealth(ctrl *Controller, vd *VirtualDrive, member *v1alpha1.RAIDMember) v1alpha1.SmartAssessResult {
+	key := fmt.Sprintf("%d/%s/%s", ctrl.ID, member.SmartDevice, member.SerialNumber)
+	if cached, ok := m.memberHealths[key]; ok && cached.state == member.State && m.now().Sub(cached.checkTime) < m.smartCheckPeriod {
+		return cached.health
+	}
+
+	cached := memberHealth{state: member.State, checkTime: m.now()}
+	passed, err := m.smartHealth(vd.OSDriveName, member.SmartDevice)
+	if err != nil {
+		log.WithFields(log.Fields{"virtualDrive": vd.Path, "member": member.EnclosureSlot}).WithError(err).Warning("Failed to get the S.M.A.R.T health of the member drive")
+	} else if passed {
+		cached.health = v1alpha1.AssessPassed
+	} else {
+		cached.health = v1alpha1.AssessFailed
+	}
+	m.memberHealths[key] = cached
+	return cached.health
+}
+
+func (m *Monitor) updateDiskRAID(disk *v1alpha1.LocalDisk, info v1alpha1.RAIDInfo) error {
+	// RAIDMaster is not discovered from the controller
+	info.RAIDMaster = disk.Spec.RAIDInfo.RAIDMaster
+
+	oldDisk := disk.DeepCopy()
+	disk.Spec.HasRAID = true
+	disk.Spec.RAIDInfo = info
+	if !reflect.DeepEqual(oldDisk.Spec, disk.Spec) {
+		if oldDisk.Spec.RAIDInfo.State != info.State {
+			log.WithFields(log.Fields{"disk": disk.Name, "virtualDrive": info.VirtualDrive, "from": oldDisk.Spec.RAIDInfo.State, "to": info.State}).Info("RAID state of the disk changed")
+		}
+		if err := m.client.Patch(context.TODO(), disk, client.MergeFrom(oldDisk)); err != nil {
+			return err
+		}
+	}
+
+	oldDisk = disk.DeepCopy()
+	meta.SetStatusCondition(&disk.Status.Conditions, RAIDCondition(info))
+	if reflect.DeepEqual(oldDisk.Status, disk.Status) {
+		return nil
+	}
+	return m.client.Status().Patch(context.TODO(), disk, client.MergeFrom(oldDisk))
+}
+
+// findVirtualDrive finds the virtual drive seen as the disk by the WWN, or the device path
+func findVirtualDrive(controllers []Controller, disk *v1alpha1.LocalDisk) (*Controller, *VirtualDrive) {
+	for i := range controllers {
+		ctrl := &controllers[i]
+		for j := range ctrl.VirtualDrives {
+			vd := &ctrl.VirtualDrives[j]
+			if len(vd.SCSINAAID) == 0 {
+				continue
+			}
+			wwnLink := "/wwn-0x" + strings.ToLower(vd.SCSINAAID)
+			for _, devLink := range disk.Spec.DevLinks {
+				if strings.HasSuffix(devLink, wwnLink) {
+					return ctrl, vd
+				}
+			}
+		}
+	}
+	for i := range controllers {
+		ctrl := &controllers[i]
+		for j := range ctrl.VirtualDrives {
+			vd := &ctrl.VirtualDrives[j]
+			if len(vd.OSDriveName) > 0 && vd.OSDriveName == disk.Spec.DevicePath {
+				return ctrl, vd
+			}
+		}
+	}
+	return nil, nil
+}
+
+// NewRAIDInfo converts the virtual drive to the RAID info of the LocalDisk
+func NewRAIDInfo(ctrl *Controller, vd *VirtualDrive) v1alpha1.RAIDInfo {
+	info := v1alpha1.RAIDInfo{
+		ControllerModel: ctrl.Model,
+		VirtualDrive:    vd.Path,
+		Level:           vd.Level,
+		State:           vd.State,
+		CachePolicy:     vd.CachePolicy,
+		BBUState:        ctrl.BBUState,
+	}
+	for _, id := range vd.Members {
+		for _, pd := range ctrl.PhysicalDrives {
+			if pd.DeviceID != id {
+				continue
+			}
+			info.Members = append(info.Members, v1alpha1.RAIDMember{
+				EnclosureSlot: pd.EnclosureSlot,
+				DeviceID:      pd.DeviceID,
+				State:         pd.State,
+				MediaType:     pd.Med,
+				Size:          pd.Size,
+				ModelName:     pd.Model,
+				SerialNumber:  pd.SerialNumber,
+				SmartDevice:   pd.SmartDevice(),
+			})
+		}
+	}
+	return info
+}
+
+// RAIDCondition returns the RAIDDegraded condition of the disk, it's true if the virtual drive is not optimal
+func RAIDCondition(info v1alpha1.RAIDInfo) metav1.Condition {
+	condition := metav1.Condition{
+		Type:    v1alpha1.LocalDiskConditionRAIDDegraded,
+		Status:  metav1.ConditionFalse,
+		Reason:  string(info.State),
+		Message: fmt.Sprintf("%s %s is %s", info.VirtualDrive, info.Level, info.State),
+	}
+	if info.State == v1alpha1.RAIDOptimal {
+		return condition
+	}
+
+	condition.Status = metav1.ConditionTrue
+	var members []string
+	for _, member := range info.Members {
+		if member.State != pdStateOnline {
+			members = append(members, fmt.Sprintf("%s(%s)", member.EnclosureSlot, member.State))
+		}
+	}
+	if len(members) > 0 {
+		condition.Message += ", members not online: " + strings.Join(members, ", ")
+	}
+	return condition
+}
+
+func memberSmartHealth(devName, smartDevice string) (bool, error) {
+	return smart.NewSMARTController(&manager.DiskIdentify{DevName: devName}, "--device", smartDevice).GetHealthStatus()
+}
@@ -0,0 +1,176 @@
+package raid
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+var fakeControllers = []Controller{
+	{
+		ID:       0,
+		Model:    "AVAGO MegaRAID SAS 9361-8i",
+		BBUState: "Optimal",
+		VirtualDrives: []VirtualDrive{
+			{Path: "/c0/v0", Level: "RAID1", State: v1alpha1.RAIDOptimal, CachePolicy: "RWBD", OSDriveName: "/dev/sda",
+				SCSINAAID: "600605B00D3C6A1027F0F6A21AC7E6B4", Members: []int{8, 9}},
+			{Path: "/c0/v1", ID: 1, Level: "RAID5", State: v1alpha1.RAIDRebuilding, CachePolicy: "RWBD", OSDriveName: "/dev/sdb",
+				SCSINAAID: "600605b00d3c6a1027f0f6c51d2b9a0e", Members: []int{10, 11, 12}},
+		},
+		PhysicalDrives: []PhysicalDrive{
+			{EnclosureSlot: "252:0", DeviceID: 8, State: "Onln", Med: "SSD", SerialNumber: "PHYG9310012L480BGN"},
+			{EnclosureSlot: "252:1", DeviceID: 9, State: "Onln", Med: "SSD", SerialNumber: "PHYG9310013A480BGN"},
+			{EnclosureSlot: "252:2", DeviceID: 10, State: "Onln", Med: "HDD", SerialNumber: "ZC20A1B2"},
+			{EnclosureSlot: "252:3", DeviceID: 11, State: "Onln", Med: "HDD", SerialNumber: "ZC20A1C7"},
+			{EnclosureSlot: "252:4", DeviceID: 12, State: "Rbld", Med: "HDD", SerialNumber: "ZC20A9F1"},
+		},
+	},
+}
+
+func Test_findVirtualDrive(t *testing.T) {
+	testCases := []struct {
+		name   string
+		disk   v1alpha1.LocalDiskSpec
+		expect string
+	}{
+		{
+			name: "by wwn",
+			disk: v1alpha1.LocalDiskSpec{DevicePath: "/dev/sdc",
+				DevLinks: []string{"/dev/disk/by-id/wwn-0x600605b00d3c6a1027f0f6a21ac7e6b4"}},
+			expect: "/c0/v0",
+		},
+		{
+			name:   "by device path",
+			disk:   v1alpha1.LocalDiskSpec{DevicePath: "/dev/sdb"},
+			expect: "/c0/v1",
+		},
+		{
+			name: "not a virtual drive",
+			disk: v1alpha1.LocalDiskSpec{DevicePath: "/dev/nvme0n1",
+				DevLinks: []string{"/dev/disk/by-id/wwn-0x5000c500b1a2000c"}},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, vd := findVirtualDrive(fakeControllers, &v1alpha1.LocalDisk{Spec: testCase.disk})
+			got := ""
+			if vd != nil {
+				got = vd.Path
+			}
+			if got != testCase.expect {
+				t.Errorf("expect virtual drive %q, but got %q", testCase.expect, got)
+			}
+		})
+	}
+}
+
+func TestRAIDCondition(t *testing.T) {
+	info := NewRAIDInfo(&fakeControllers[0], &fakeControllers[0].VirtualDrives[1])
+	if len(info.Members) != 3 || info.Members[2].SmartDevice != "megaraid,12" || info.BBUState != "Optimal" {
+		t.Fatalf("unexpected RAID info %+v", info)
+	}
+
+	condition := RAIDCondition(info)
+	if condition.Status != metav1.ConditionTrue || condition.Reason != string(v1alpha1.RAIDRebuilding) {
+		t.Errorf("expect the disk degraded for rebuilding, but got %+v", condition)
+	}
+	if expect := "/c0/v1 RAID5 is Rebuilding, members not online: 252:4(Rbld)"; condition.Message != expect {
+		t.Errorf("expect message %q, but got %q", expect, condition.Message)
+	}
+
+	condition = RAIDCondition(NewRAIDInfo(&fakeControllers[0], &fakeControllers[0].VirtualDrives[0]))
+	if condition.Status != metav1.ConditionFalse || condition.Reason != string(v1alpha1.RAIDOptimal) {
+		t.Errorf("expect the disk not degraded, but got %+v", condition)
+	}
+}
+
+func TestMonitor_updateDiskRAID(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	disk := &v1alpha1.LocalDisk{
+		ObjectMeta: metav1.ObjectMeta{Name: "localdisk-1"},
+		Spec:       v1alpha1.LocalDiskSpec{NodeName: "node1", DevicePath: "/dev/sdb"},
+		Status:     v1alpha1.LocalDiskStatus{State: v1alpha1.LocalDiskBound},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(disk).Build()
+	monitor := NewMonitor(cli, "node1", nil)
+	monitor.smartHealth = func(devName, smartDevice string) (bool, error) {
+		if smartDevice == "megaraid,12" {
+			return false, fmt.Errorf("device is rebuilding")
+		}
+		return smartDevice != "megaraid,11", nil
+	}
+
+	if err := monitor.updateDiskRAID(disk, monitor.raidInfo(&fakeControllers[0], &fakeControllers[0].VirtualDrives[1])); err != nil {
+		t.Fatal(err)
+	}
+
+	updated := &v1alpha1.LocalDisk{}
+	if err := cli.Get(context.TODO(), types.NamespacedName{Name: disk.Name}, updated); err != nil {
+		t.Fatal(err)
+	}
+	if !updated.Spec.HasRAID || updated.Spec.RAIDInfo.VirtualDrive != "/c0/v1" || updated.Spec.RAIDInfo.State != v1alpha1.RAIDRebuilding {
+		t.Errorf("unexpected RAID info %+v", updated.Spec.RAIDInfo)
+	}
+	healths := []v1alpha1.SmartAssessResult{v1alpha1.AssessPassed, v1alpha1.AssessFailed, ""}
+	for i, member := range updated.Spec.RAIDInfo.Members {
+		if member.OverallHealth != healths[i] {
+			t.Errorf("expect health %q of member %s, but got %q", healths[i], member.EnclosureSlot, member.OverallHealth)
+		}
+	}
+	if !meta.IsStatusConditionTrue(updated.Status.Conditions, v1alpha1.LocalDiskConditionRAIDDegraded) {
+		t.Errorf("expect the disk degraded, but got %+v", updated.Status.Conditions)
+	}
+	if updated.Status.State != v1alpha1.LocalDiskBound {
+		t.Error("expect the claim state kept")
+	}
+}
+
+func TestMonitor_memberSmartHealth(t *testing.T) {
+	now := time.Now()
+	checks := 0
+	monitor := NewMonitor(nil, "node1", nil)
+	monitor.now = func() time.Time { return now }
+	monitor.smartHealth = func(devName, smartDevice string) (bool, error) {
+		checks++
+		return true, nil
+	}
+	ctrl := fakeControllers[0]
+	ctrl.PhysicalDrives = append([]PhysicalDrive{}, fakeControllers[0].PhysicalDrives...)
+	vd := &ctrl.VirtualDrives[1]
+
+	syncMembers := func(wantChecks int) {
+		t.Helper()
+		info := monitor.raidInfo(&ctrl, vd)
+		for _, member := range info.Members {
+			if member.OverallHealth != v1alpha1.AssessPassed {
+				t.Errorf("expect member %s passed, but got %q", member.EnclosureSlot, member.OverallHealth)
+			}
+		}
+		if checks != wantChecks {
+			t.Errorf("expect %d S.M.A.R.T checks, but got %d", wantChecks, checks)
+		}
+	}
+
+	syncMembers(3)
+	// cached within the check period
+	now = now.Add(DefaultSyncPeriod)
+	syncMembers(3)
+	// the rebuilt member is checked again
+	ctrl.PhysicalDrives[4].State = "Onln"
+	syncMembers(4)
+	// all checked again once expired
+	now = now.Add(DefaultSmartCheckPeriod)
+	syncMembers(7)
+}
@@ -1,8 +1,17 @@
 package raid
 
+import (
+	"fmt"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
 // Manager define interfaces about how to get RAID info
 type Manager interface {
 	GetControllerCount() (count int, err error)
+
+	// ListControllers returns the controllers on the node, with their virtual drives and physical drives
+	ListControllers() (controllers []Controller, err error)
 }
 
 type CommandStatus struct {
@@ -17,7 +26,62 @@ type ResponseData struct {
 	ControllerCount int `json:"Controller Count"`
 }
 
+// Controller is a RAID controller on the node
+type Controller struct {
+	// ID is the index of the controller, e.g. 0 for /c0
+	ID int
+
+	Model string
+
+	SerialNumber string
+
+	FirmwareVersion string
+
+	// BBUState is the state of the battery backup unit or the cache vault, it's empty if there is none
+	BBUState string
+
+	VirtualDrives []VirtualDrive
+
+	PhysicalDrives []PhysicalDrive
+}
+
+// VirtualDrive is a RAID array exposed to the OS as a disk
+type VirtualDrive struct {
+	// Path of the virtual drive, e.g. /c0/v0
+	Path string
+
+	// ID is the index of the virtual drive on the controller
+	ID int
+
+	// Level such as RAID0, RAID1, RAID5
+	Level string
+
+	// State is the state of the virtual drive, a member rebuilding makes it Rebuilding
+	State v1alpha1.RAIDState
+
+	// CachePolicy e.g. RWBD
+	CachePolicy string
+
+	// Size e.g. 446.625 GB
+	Size string
+
+	// OSDriveName is the disk seen by the OS, e.g. /dev/sda
+	OSDriveName string
+
+	// SCSINAAID is the WWN of the disk seen by the OS
+	SCSINAAID string
+
+	// Members are the device IDs of the physical drives of the virtual drive
+	Members []int
+}
+
 type PhysicalDrive struct {
+	// EnclosureSlot locates the drive on the controller, e.g. 252:0
+	EnclosureSlot string `json:"EID:Slt"`
+
+	// DeviceID is used to reach the drive by smartctl, e.g. megaraid,8
+	DeviceID int `json:"DID"`
+
 	// Storage Capacity
 	Size string `json:"Size"`
 
@@ -26,4 +90,16 @@ type PhysicalDrive struct {
 
 	// Disk type e.g, HDD,SSD,NVMe
 	Med string `json:"Med"`
+
+	// Interface e.g, SATA,SAS
+	Intf string `json:"Intf"`
+
+	Model string `json:"Model"`
+
+	SerialNumber string `json:"-"`
+}
+
+// SmartDevice returns the device type used by smartctl to reach the drive behind the controller
+func (pd PhysicalDrive) SmartDevice() string {
+	return fmt.Sprintf("megaraid,%d", pd.DeviceID)
 }
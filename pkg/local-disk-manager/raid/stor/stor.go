@@ -3,8 +3,16 @@ package stor
 import (
 	"encoding/json"
 	"fmt"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 
-	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/utils"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/raid"
 )
 
 const (
@@ -13,16 +21,48 @@ const (
 
 	// Print the number of controllers connected in JSON format.
 	STOR_SHOW_CTRL_COUNT = "show ctrlcount j"
+
+	// Print the basics, version and BBU of all the controllers in JSON format.
+	STOR_SHOW_ALL_CTRL = "/call show all j"
+
+	// Print the virtual drives with their physical drives and properties in JSON format.
+	STOR_SHOW_ALL_VD = "/call/vall show all j"
+
+	// Print the physical drives with their detailed information in JSON format.
+	STOR_SHOW_ALL_PD = "/call/eall/sall show all j"
+
+	statusSuccess = "Success"
+
+	// the physical drive is rebuilding
+	pdStateRebuild = "Rbld"
+)
+
+var (
+	// the state of the virtual drives shown by storcli
+	virtualDriveStates = map[string]v1alpha1.RAIDState{
+		"Optl": v1alpha1.RAIDOptimal,
+		"Dgrd": v1alpha1.RAIDDegraded,
+		"Pdgd": v1alpha1.RAIDPartiallyDegraded,
+		"OfLn": v1alpha1.RAIDOffline,
+		"Rec":  v1alpha1.RAIDRebuilding,
+	}
+
+	virtualDriveKey   = regexp.MustCompile(`^/c(\d+)/v(\d+)$`)
+	physicalDriveKey  = regexp.MustCompile(`^Drive (/c\d+(?:/e\d+)?/s\d+)$`)
+	physicalDriveInfo = regexp.MustCompile(`^Drive (/c\d+(?:/e\d+)?/s\d+) - Detailed Information$`)
 )
 
 // Stor implements the raid.Manager interface.
 type Stor struct {
 	Path string `json:"Path"`
+
+	// execCmd runs storcli with the arguments, it's replaced in the tests
+	execCmd func(command, args string) ([]byte, error)
 }
 
 // NewStor returns a Stor instance with default values.
 func NewStor() *Stor {
-	return &Stor{Path: defaultCmd}
+	return &Stor{Path: defaultCmd, execCmd: execStorCmd}
 }
 
 // GetControllerCount implements the raid.Manager interface.
@@ -34,7 +74,7 @@ func (s *Stor) GetControllerCount() (int, error) {
 		result ControllerCounts
 	)
 
-	info, err = utils.ExecCmd(s.Path, STOR_SHOW_CTRL_COUNT)
+	info, err = s.execCmd(s.Path, STOR_SHOW_CTRL_COUNT)
 	if err != nil {
 		return count, err
 	}
@@ -57,3 +97,234 @@ func (s *Stor) GetControllerCount() (int, error) {
 	return count, fmt.Errorf("get controller count err: %s",
 		result.Controllers[0].CommandStatus.Description)
 }
+
+// ListControllers implements the raid.Manager interface.
+func (s *Stor) ListControllers() ([]raid.Controller, error) {
+	var infos ControllerInfos
+	if err := s.run(STOR_SHOW_ALL_CTRL, &infos); err != nil {
+		return nil, err
+	}
+
+	var controllers []raid.Controller
+	for _, info := range infos.Controllers {
+		if info.CommandStatus.Status != statusSuccess {
+			return nil, fmt.Errorf("get controller %d info err: %s",
+				info.CommandStatus.Controller, info.CommandStatus.Description)
+		}
+		controllers = append(controllers, parseController(info))
+	}
+
+	var pdResponses, vdResponses ControllerResponses
+	if err := s.run(STOR_SHOW_ALL_PD, &pdResponses); err != nil {
+		return nil, err
+	}
+	if err := s.run(STOR_SHOW_ALL_VD, &vdResponses); err != nil {
+		return nil, err
+	}
+
+	for i := range controllers {
+		ctrl := &controllers[i]
+		for _, resp := range pdResponses.Controllers {
+			if resp.CommandStatus.Controller != ctrl.ID || !isSuccess(resp) {
+				continue
+			}
+			pds, err := parsePhysicalDrives(resp.ResponseData)
+			if err != nil {
+				return nil, err
+			}
+			ctrl.PhysicalDrives = pds
+		}
+		for _, resp := range vdResponses.Controllers {
+			if resp.CommandStatus.Controller != ctrl.ID || !isSuccess(resp) {
+				continue
+			}
+			vds, err := parseVirtualDrives(resp.ResponseData)
+			if err != nil {
+				return nil, err
+			}
+			ctrl.VirtualDrives = vds
+		}
+	}
+
+	return controllers, nil
+}
+
+// run executes the storcli command and decodes its JSON output
+func (s *Stor) run(args string, result interface{}) error {
+	out, err := s.execCmd(s.Path, args)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(out, result)
+}
+
+// isSuccess returns whether the command succeeded on the controller,
+// e.g. it fails on the controller without any virtual drive
+func isSuccess(resp ControllerResponse) bool {
+	if resp.CommandStatus.Status != statusSuccess {
+		log.WithFields(log.Fields{"controller": resp.CommandStatus.Controller,
+			"description": resp.CommandStatus.Description}).Debug("Nothing found on the controller")
+		return false
+	}
+	return true
+}
+
+func parseController(info ControllerInfo) raid.Controller {
+	ctrl := raid.Controller{
+		ID:              info.CommandStatus.Controller,
+		Model:           info.ResponseData.Basics.Model,
+		SerialNumber:    info.ResponseData.Basics.SerialNumber,
+		FirmwareVersion: info.ResponseData.Version.FirmwareVersion,
+	}
+	// the controller may have more than one unit, report the worst one
+	for _, bbu := range append(info.ResponseData.BBUInfo, info.ResponseData.CachevaultInfo...) {
+		if len(ctrl.BBUState) == 0 || bbuStateSeverity(bbu.State) > bbuStateSeverity(ctrl.BBUState) {
+			ctrl.BBUState = bbu.State
+		}
+	}
+	return ctrl
+}
+
+// parsePhysicalDrives parses the response data of "/cx/eall/sall show all j"
+func parsePhysicalDrives(data map[string]json.RawMessage) ([]raid.PhysicalDrive, error) {
+	var pds []raid.PhysicalDrive
+	for key, value := range data {
+		match := physicalDriveKey.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+		var rows []raid.PhysicalDrive
+		if err := json.Unmarshal(value, &rows); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", key, err)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		pd := rows[0]
+
+		attrs, err := parseDriveAttributes(data, match[1])
+		if err != nil {
+			return nil, err
+		}
+		pd.SerialNumber = strings.TrimSpace(attrs.SN)
+		if len(pd.Model) == 0 {
+			pd.Model = strings.TrimSpace(attrs.ModelNumber)
+		}
+		pds = append(pds, pd)
+	}
+
+	sort.Slice(pds, func(i, j int) bool { return pds[i].DeviceID < pds[j].DeviceID })
+	return pds, nil
+}
+
+// parseDriveAttributes parses the "Drive /cx/ex/sx Device attributes" in the detailed information of the drive
+func parseDriveAttributes(data map[string]json.RawMessage, drive string) (DriveAttributes, error) {
+	var attrs DriveAttributes
+	for key, value := range data {
+		match := physicalDriveInfo.FindStringSubmatch(key)
+		if match == nil || match[1] != drive {
+			continue
+		}
+		var details map[string]json.RawMessage
+		if err := json.Unmarshal(value, &details); err != nil {
+			return attrs, fmt.Errorf("failed to parse %s: %v", key, err)
+		}
+		if raw, ok := details[fmt.Sprintf("Drive %s Device attributes", drive)]; ok {
+			if err := json.Unmarshal(raw, &attrs); err != nil {
+				return attrs, fmt.Errorf("failed to parse the device attributes of %s: %v", drive, err)
+			}
+		}
+	}
+	return attrs, nil
+}
+
+// parseVirtualDrives parses the response data of "/cx/vall show all j"
+func parseVirtualDrives(data map[string]json.RawMessage) ([]raid.VirtualDrive, error) {
+	var vds []raid.VirtualDrive
+	for key, value := range data {
+		match := virtualDriveKey.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+		id, _ := strconv.Atoi(match[2])
+
+		var rows []VirtualDriveRow
+		if err := json.Unmarshal(value, &rows); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %v", key, err)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		var members []raid.PhysicalDrive
+		if raw, ok := data[fmt.Sprintf("PDs for VD %d", id)]; ok {
+			if err := json.Unmarshal(raw, &members); err != nil {
+				return nil, fmt.Errorf("failed to parse the physical drives of %s: %v", key, err)
+			}
+		}
+
+		var props VirtualDriveProperties
+		if raw, ok := data[fmt.Sprintf("VD%d Properties", id)]; ok {
+			if err := json.Unmarshal(raw, &props); err != nil {
+				return nil, fmt.Errorf("failed to parse the properties of %s: %v", key, err)
+			}
+		}
+
+		vd := raid.VirtualDrive{
+			Path:        key,
+			ID:          id,
+			Level:       rows[0].Type,
+			State:       virtualDriveState(rows[0].State, members),
+			CachePolicy: rows[0].Cache,
+			Size:        rows[0].Size,
+			OSDriveName: props.OSDriveName,
+			SCSINAAID:   props.SCSINAAID,
+		}
+		for _, member := range members {
+			vd.Members = append(vd.Members, member.DeviceID)
+		}
+		vds = append(vds, vd)
+	}
+
+	sort.Slice(vds, func(i, j int) bool { return vds[i].ID < vds[j].ID })
+	return vds, nil
+}
+
+// virtualDriveState converts the state shown by storcli, the virtual drive is Rebuilding if any of its members is
+func virtualDriveState(state string, members []raid.PhysicalDrive) v1alpha1.RAIDState {
+	for _, member := range members {
+		if member.State == pdStateRebuild {
+			return v1alpha1.RAIDRebuilding
+		}
+	}
+	if s, ok := virtualDriveStates[state]; ok {
+		return s
+	}
+	return v1alpha1.RAIDUnknown
+}
+
+// bbuStateSeverity ranks the state of the battery backup unit or the cache vault, the higher the worse.
+// The states other than the optimal, degraded and failed ones are transient, e.g. Learning or Charging
+func bbuStateSeverity(state string) int {
+	state = strings.ToLower(state)
+	switch {
+	case state == "optimal":
+		return 0
+	case strings.Contains(state, "fail") || strings.Contains(state, "missing"):
+		return 3
+	case strings.Contains(state, "degraded") || strings.Contains(state, "dgd"):
+		return 2
+	default:
+		return 1
+	}
+}
+
+// execStorCmd runs storcli and returns its output even if it exits with non-zero code,
+// because the status of the command on each controller is in the JSON output
+func execStorCmd(command, args string) ([]byte, error) {
+	out, err := exec.Command(command, strings.Split(args, " ")...).Output()
+	if _, ok := err.(*exec.ExitError); ok && json.Valid(out) {
+		return out, nil
+	}
+	return out, err
+}
@@ -0,0 +1,179 @@
+package stor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/raid"
+)
+
+// newFakeStor returns a Stor replying the commands with the captured outputs in testdata
+func newFakeStor(t *testing.T, outputs map[string]string) *Stor {
+	return &Stor{
+		Path: defaultCmd,
+		execCmd: func(command, args string) ([]byte, error) {
+			file, ok := outputs[args]
+			if !ok {
+				return nil, fmt.Errorf("unexpected command: %s %s", command, args)
+			}
+			out, err := os.ReadFile(filepath.Join("testdata", file))
+			if err != nil {
+				t.Fatalf("failed to read %s: %v", file, err)
+			}
+			return out, nil
+		},
+	}
+}
+
+func TestStor_GetControllerCount(t *testing.T) {
+	s := newFakeStor(t, map[string]string{STOR_SHOW_CTRL_COUNT: "show_ctrlcount.json"})
+	count, err := s.GetControllerCount()
+	if err != nil {
+		t.Fatalf("GetControllerCount() error = %v", err)
+	}
+	if count != 1 {
+		t.Errorf("GetControllerCount() = %d, want 1", count)
+	}
+}
+
+func TestStor_ListControllers(t *testing.T) {
+	s := newFakeStor(t, map[string]string{
+		STOR_SHOW_ALL_CTRL: "show_all_ctrl.json",
+		STOR_SHOW_ALL_VD:   "show_all_vd.json",
+		STOR_SHOW_ALL_PD:   "show_all_pd.json",
+	})
+	controllers, err := s.ListControllers()
+	if err != nil {
+		t.Fatalf("ListControllers() error = %v", err)
+	}
+	if len(controllers) != 1 {
+		t.Fatalf("ListControllers() got %d controllers, want 1", len(controllers))
+	}
+
+	ctrl := controllers[0]
+	if ctrl.ID != 0 || ctrl.Model != "AVAGO MegaRAID SAS 9361-8i" || ctrl.SerialNumber != "SK91932471" ||
+		ctrl.FirmwareVersion != "4.680.00-8519" || ctrl.BBUState != "Optimal" {
+		t.Errorf("unexpected controller %+v", ctrl)
+	}
+
+	wantVDs := []raid.VirtualDrive{
+		{
+			Path:        "/c0/v0",
+			ID:          0,
+			Level:       "RAID1",
+			State:       v1alpha1.RAIDOptimal,
+			CachePolicy: "RWBD",
+			Size:        "446.625 GB",
+			OSDriveName: "/dev/sda",
+			SCSINAAID:   "600605b00d3c6a1027f0f6a21ac7e6b4",
+			Members:     []int{8, 9},
+		},
+		{
+			Path:        "/c0/v1",
+			ID:          1,
+			Level:       "RAID5",
+			State:       v1alpha1.RAIDRebuilding,
+			CachePolicy: "RWBD",
+			Size:        "3.637 TB",
+			OSDriveName: "/dev/sdb",
+			SCSINAAID:   "600605b00d3c6a1027f0f6c51d2b9a0e",
+			Members:     []int{10, 11, 12},
+		},
+	}
+	if !reflect.DeepEqual(ctrl.VirtualDrives, wantVDs) {
+		t.Errorf("VirtualDrives = %+v, want %+v", ctrl.VirtualDrives, wantVDs)
+	}
+
+	if len(ctrl.PhysicalDrives) != 6 {
+		t.Fatalf("got %d PhysicalDrives, want 6", len(ctrl.PhysicalDrives))
+	}
+	wantPD := raid.PhysicalDrive{
+		EnclosureSlot: "252:4",
+		DeviceID:      12,
+		Size:          "1.818 TB",
+		State:         "Rbld",
+		Med:           "HDD",
+		Intf:          "SATA",
+		Model:         "ST2000NM0055-1V4104",
+		SerialNumber:  "ZC20A9F1",
+	}
+	if ctrl.PhysicalDrives[4] != wantPD {
+		t.Errorf("PhysicalDrives[4] = %+v, want %+v", ctrl.PhysicalDrives[4], wantPD)
+	}
+	if got := ctrl.PhysicalDrives[4].SmartDevice(); got != "megaraid,12" {
+		t.Errorf("SmartDevice() = %s, want megaraid,12", got)
+	}
+	// the unconfigured drive has no drive group
+	if ctrl.PhysicalDrives[5].State != "UGood" {
+		t.Errorf("PhysicalDrives[5] = %+v, want the unconfigured drive", ctrl.PhysicalDrives[5])
+	}
+}
+
+func TestStor_ListControllers_NoVirtualDrive(t *testing.T) {
+	s := newFakeStor(t, map[string]string{
+		STOR_SHOW_ALL_CTRL: "show_all_ctrl.json",
+		STOR_SHOW_ALL_VD:   "show_all_vd_none.json",
+		STOR_SHOW_ALL_PD:   "show_all_pd.json",
+	})
+	controllers, err := s.ListControllers()
+	if err != nil {
+		t.Fatalf("ListControllers() error = %v", err)
+	}
+	if len(controllers) != 1 || len(controllers[0].VirtualDrives) != 0 || len(controllers[0].PhysicalDrives) != 6 {
+		t.Errorf("unexpected controllers %+v", controllers)
+	}
+}
+
+func Test_virtualDriveState(t *testing.T) {
+	online := []raid.PhysicalDrive{{State: "Onln"}, {State: "Onln"}}
+	tests := []struct {
+		state   string
+		members []raid.PhysicalDrive
+		want    v1alpha1.RAIDState
+	}{
+		{state: "Optl", members: online, want: v1alpha1.RAIDOptimal},
+		{state: "Dgrd", members: online[:1], want: v1alpha1.RAIDDegraded},
+		{state: "Pdgd", members: online, want: v1alpha1.RAIDPartiallyDegraded},
+		{state: "Dgrd", members: []raid.PhysicalDrive{{State: "Onln"}, {State: "Rbld"}}, want: v1alpha1.RAIDRebuilding},
+		{state: "OfLn", want: v1alpha1.RAIDOffline},
+		{state: "Cac", want: v1alpha1.RAIDUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.state, func(t *testing.T) {
+			if got := virtualDriveState(tt.state, tt.members); got != tt.want {
+				t.Errorf("virtualDriveState() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseController_BBUState(t *testing.T) {
+	tests := []struct {
+		name  string
+		units []BBUInfo
+		want  string
+	}{
+		{name: "no unit", want: ""},
+		{name: "all optimal", units: []BBUInfo{{State: "Optimal"}, {State: "Optimal"}}, want: "Optimal"},
+		{name: "learning", units: []BBUInfo{{State: "Learning"}, {State: "Optimal"}}, want: "Learning"},
+		{name: "degraded", units: []BBUInfo{{State: "Dgd (Needs Attention)"}, {State: "Learning"}}, want: "Dgd (Needs Attention)"},
+		{name: "failed", units: []BBUInfo{{State: "Degraded"}, {State: "Failed"}, {State: "Optimal"}}, want: "Failed"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := ControllerInfo{}
+			// the worst unit counts no matter it's a BBU or a cache vault
+			if len(tt.units) > 0 {
+				info.ResponseData.BBUInfo = tt.units[:1]
+				info.ResponseData.CachevaultInfo = tt.units[1:]
+			}
+			if got := parseController(info).BBUState; got != tt.want {
+				t.Errorf("parseController() got BBUState %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
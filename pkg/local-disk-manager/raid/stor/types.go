@@ -1,5 +1,7 @@
 package stor
 
+import "encoding/json"
+
 type OperateResult struct {
 	Controllers []Controllers `json:"Controllers"`
 }
@@ -27,3 +29,70 @@ type ControllerCount struct {
 	CommandStatus CommandStatus     `json:"Command Status"`
 	ResponseData  CountResponseData `json:"Response Data"`
 }
+
+// ControllerInfos is the result of "/call show all j"
+type ControllerInfos struct {
+	Controllers []ControllerInfo `json:"Controllers"`
+}
+
+type ControllerInfo struct {
+	CommandStatus CommandStatus              `json:"Command Status"`
+	ResponseData  ControllerInfoResponseData `json:"Response Data"`
+}
+
+type ControllerInfoResponseData struct {
+	Basics         ControllerBasics  `json:"Basics"`
+	Version        ControllerVersion `json:"Version"`
+	BBUInfo        []BBUInfo         `json:"BBU_Info"`
+	CachevaultInfo []BBUInfo         `json:"Cachevault_Info"`
+}
+
+type ControllerBasics struct {
+	Controller   int    `json:"Controller"`
+	Model        string `json:"Model"`
+	SerialNumber string `json:"Serial Number"`
+}
+
+type ControllerVersion struct {
+	FirmwareVersion string `json:"Firmware Version"`
+}
+
+// BBUInfo is the battery backup unit or the cache vault of the controller
+type BBUInfo struct {
+	Model string `json:"Model"`
+	State string `json:"State"`
+}
+
+// ControllerResponses is the result of "/call/vall show all j" and "/call/eall/sall show all j",
+// the keys of the response data contain the paths of the virtual drives or physical drives
+type ControllerResponses struct {
+	Controllers []ControllerResponse `json:"Controllers"`
+}
+
+type ControllerResponse struct {
+	CommandStatus CommandStatus              `json:"Command Status"`
+	ResponseData  map[string]json.RawMessage `json:"Response Data"`
+}
+
+// VirtualDriveRow is the row of a virtual drive in the "/cx/vx" list
+type VirtualDriveRow struct {
+	DGVD   string `json:"DG/VD"`
+	Type   string `json:"TYPE"`
+	State  string `json:"State"`
+	Access string `json:"Access"`
+	Cache  string `json:"Cache"`
+	Size   string `json:"Size"`
+	Name   string `json:"Name"`
+}
+
+// VirtualDriveProperties is the "VDx Properties" of a virtual drive
+type VirtualDriveProperties struct {
+	OSDriveName string `json:"OS Drive Name"`
+	SCSINAAID   string `json:"SCSI NAA Id"`
+}
+
+// DriveAttributes is the "Drive /cx/ex/sx Device attributes" of a physical drive
+type DriveAttributes struct {
+	SN          string `json:"SN"`
+	ModelNumber string `json:"Model Number"`
+}
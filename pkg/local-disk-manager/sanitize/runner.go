@@ -0,0 +1,93 @@
+package sanitize
+
+import (
+	"context"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Job is a sanitization running in the background
+type Job struct {
+	lock     sync.Mutex
+	progress int64
+	done     bool
+	err      error
+}
+
+// Progress returns the percentage of the sanitization
+func (j *Job) Progress() int64 {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.progress
+}
+
+// Done returns true and the result if the sanitization has finished
+func (j *Job) Done() (bool, error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	return j.done, j.err
+}
+
+func (j *Job) setProgress(percent int64) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.progress = percent
+}
+
+func (j *Job) finish(err error) {
+	j.lock.Lock()
+	defer j.lock.Unlock()
+	j.done = true
+	j.err = err
+}
+
+// Runner runs the sanitizations in the background, so that the reconciler is not blocked by
+// the sanitization which may take hours
+type Runner struct {
+	lock sync.Mutex
+	jobs map[string]*Job
+}
+
+// NewRunner creates a Runner
+func NewRunner() *Runner {
+	return &Runner{jobs: map[string]*Job{}}
+}
+
+// Start starts to sanitize devPath for the key if not started yet, and returns the job
+func (r *Runner) Start(key string, devPath string, sanitizer Sanitizer) *Job {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if job, exists := r.jobs[key]; exists {
+		return job
+	}
+
+	job := &Job{}
+	r.jobs[key] = job
+	go func() {
+		logCtx := log.WithFields(log.Fields{"key": key, "devPath": devPath})
+		logCtx.Info("Start to sanitize disk")
+		err := sanitizer.Sanitize(context.Background(), devPath, job.setProgress)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to sanitize disk")
+		} else {
+			logCtx.Info("Succeed to sanitize disk")
+		}
+		job.finish(err)
+	}()
+	return job
+}
+
+// Get returns the job of the key, nil if not exists
+func (r *Runner) Get(key string) *Job {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.jobs[key]
+}
+
+// Remove removes the finished job of the key
+func (r *Runner) Remove(key string) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.jobs, key)
+}
@@ -0,0 +1,254 @@
+package sanitize
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/tidwall/gjson"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/utils"
+)
+
+const (
+	// zeroChunkSize is the size of each write when overwriting the disk with zeros
+	zeroChunkSize = 4 * 1024 * 1024
+
+	// nvmeSanitizePollInterval is the interval to poll the progress of the NVMe sanitize
+	nvmeSanitizePollInterval = 10 * time.Second
+
+	// ataSecurityPassword is the temporary password to enable the ATA security feature,
+	// it's cleared by the drive once the security erase completes
+	ataSecurityPassword = "hwameistor"
+
+	// json path in nvme sanitize-log result
+	_NVMeSanitizeProgress = "sprog"
+	_NVMeSanitizeStatus   = "sstat"
+)
+
+// NVMe sanitize status, the lowest 3 bits of sstat
+const (
+	nvmeSanitizeCompleted      = 1
+	nvmeSanitizeInProgress     = 2
+	nvmeSanitizeFailed         = 3
+	nvmeSanitizeCompletedNoDea = 4
+)
+
+// ProgressFunc receives the percentage of the sanitization
+type ProgressFunc func(percent int64)
+
+// Sanitizer removes the data on the disk
+type Sanitizer interface {
+	// Sanitize blocks until the data on devPath is removed, the progress is reported if known
+	Sanitize(ctx context.Context, devPath string, progress ProgressFunc) error
+}
+
+// execCommand runs the command, can be replaced in tests
+var execCommand = utils.RunCommand
+
+// New returns the Sanitizer of the policy
+func New(policy v1alpha1.DiskSanitizePolicy) (Sanitizer, error) {
+	switch policy {
+	case v1alpha1.DiskSanitizePolicySignatures:
+		return &commandSanitizer{name: "wipefs", args: []string{"-af"}}, nil
+	case v1alpha1.DiskSanitizePolicyZero:
+		return &zeroSanitizer{}, nil
+	case v1alpha1.DiskSanitizePolicyBlkDiscard:
+		return &commandSanitizer{name: "blkdiscard"}, nil
+	case v1alpha1.DiskSanitizePolicyNVMeFormat:
+		return &commandSanitizer{name: "nvme", args: []string{"format", "--ses=1", "--force"}}, nil
+	case v1alpha1.DiskSanitizePolicyNVMeSanitize:
+		return &nvmeSanitizer{pollInterval: nvmeSanitizePollInterval}, nil
+	case v1alpha1.DiskSanitizePolicyATASecureErase:
+		return &ataSecureEraseSanitizer{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported sanitize policy %q", policy)
+	}
+}
+
+// IsValidPolicy returns true if the policy is empty or known
+func IsValidPolicy(policy v1alpha1.DiskSanitizePolicy) bool {
+	if policy == "" || policy == v1alpha1.DiskSanitizePolicyNone {
+		return true
+	}
+	_, err := New(policy)
+	return err == nil
+}
+
+// IsWholeDevicePolicy returns true if the policy erases the whole device by the drive, which can't be applied to a partition
+func IsWholeDevicePolicy(policy v1alpha1.DiskSanitizePolicy) bool {
+	switch policy {
+	case v1alpha1.DiskSanitizePolicyNVMeFormat, v1alpha1.DiskSanitizePolicyNVMeSanitize, v1alpha1.DiskSanitizePolicyATASecureErase:
+		return true
+	default:
+		return false
+	}
+}
+
+// commandSanitizer sanitizes the disk by a single command, the progress is unknown
+type commandSanitizer struct {
+	name string
+	args []string
+}
+
+func (s *commandSanitizer) Sanitize(ctx context.Context, devPath string, progress ProgressFunc) error {
+	if _, err := execCommand(ctx, s.name, append(s.args, devPath)...); err != nil {
+		return err
+	}
+	progress(100)
+	return nil
+}
+
+// zeroSanitizer overwrites the whole disk with zeros
+type zeroSanitizer struct{}
+
+func (s *zeroSanitizer) Sanitize(ctx context.Context, devPath string, progress ProgressFunc) error {
+	f, err := os.OpenFile(devPath, os.O_WRONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// the size of the block device is only known by seeking to the end
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	buf := make([]byte, zeroChunkSize)
+	var written, reported int64
+	for written < size {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		n := int64(len(buf))
+		if size-written < n {
+			n = size - written
+		}
+		if _, err = f.Write(buf[:n]); err != nil {
+			return fmt.Errorf("failed to write zeros at offset %d: %v", written, err)
+		}
+		written += n
+
+		if percent := written * 100 / size; percent != reported {
+			reported = percent
+			progress(percent)
+		}
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	progress(100)
+	return nil
+}
+
+// nvmeSanitizer sanitizes the NVMe disk by the block erase, and polls the sanitize log for the progress
+type nvmeSanitizer struct {
+	pollInterval time.Duration
+}
+
+func (s *nvmeSanitizer) Sanitize(ctx context.Context, devPath string, progress ProgressFunc) error {
+	if _, err := execCommand(ctx, "nvme", "sanitize", devPath, "--sanact=2"); err != nil {
+		return err
+	}
+
+	for {
+		out, err := execCommand(ctx, "nvme", "sanitize-log", devPath, "--output-format=json")
+		if err != nil {
+			return err
+		}
+		percent, done, err := parseNVMeSanitizeLog(out)
+		if err != nil {
+			return err
+		}
+		progress(percent)
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(s.pollInterval):
+		}
+	}
+}
+
+// parseNVMeSanitizeLog returns the progress of the sanitize, and whether it has completed
+func parseNVMeSanitizeLog(out string) (int64, bool, error) {
+	// the log is keyed by the device name in some versions of nvme-cli
+	result := gjson.Parse(out)
+	if !result.Get(_NVMeSanitizeStatus).Exists() {
+		result.ForEach(func(_, value gjson.Result) bool {
+			if value.Get(_NVMeSanitizeStatus).Exists() {
+				result = value
+				return false
+			}
+			return true
+		})
+	}
+	if !result.Get(_NVMeSanitizeStatus).Exists() {
+		return 0, false, fmt.Errorf("no sanitize status found in %q", out)
+	}
+
+	switch result.Get(_NVMeSanitizeStatus).Int() & 0x7 {
+	case nvmeSanitizeCompleted, nvmeSanitizeCompletedNoDea:
+		return 100, true, nil
+	case nvmeSanitizeInProgress:
+		// sprog is the fraction of 65536
+		return result.Get(_NVMeSanitizeProgress).Int() * 100 / 65536, false, nil
+	case nvmeSanitizeFailed:
+		return 0, false, fmt.Errorf("nvme sanitize failed")
+	default:
+		return 0, false, fmt.Errorf("nvme sanitize not started")
+	}
+}
+
+// ataSecureEraseSanitizer erases the ATA disk by the security erase unit command
+type ataSecureEraseSanitizer struct{}
+
+func (s *ataSecureEraseSanitizer) Sanitize(ctx context.Context, devPath string, progress ProgressFunc) error {
+	out, err := execCommand(ctx, "hdparm", "-I", devPath)
+	if err != nil {
+		return err
+	}
+	if isATASecurityFrozen(out) {
+		return fmt.Errorf("security of %s is frozen, suspend and resume the node to unfreeze it", devPath)
+	}
+
+	if _, err = execCommand(ctx, "hdparm", "--user-master", "u", "--security-set-pass", ataSecurityPassword, devPath); err != nil {
+		return err
+	}
+	if _, err = execCommand(ctx, "hdparm", "--user-master", "u", "--security-erase", ataSecurityPassword, devPath); err != nil {
+		// clear the password, otherwise the drive is locked by it after the next power cycle.
+		// It's done even if the sanitization is cancelled
+		if _, disableErr := execCommand(context.Background(), "hdparm", "--user-master", "u", "--security-disable", ataSecurityPassword, devPath); disableErr != nil {
+			log.WithError(disableErr).WithField("disk", devPath).Error("Failed to disable the security of the disk")
+		}
+		return err
+	}
+	progress(100)
+	return nil
+}
+
+// isATASecurityFrozen returns true if the security of the drive is frozen, which is reported as
+// "frozen" in the Security section of hdparm -I, and "not	frozen" otherwise
+func isATASecurityFrozen(out string) bool {
+	for _, line := range strings.Split(out, "\n") {
+		if strings.TrimSpace(line) == "frozen" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,306 @@
+package sanitize
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/utils"
+)
+
+func TestNew(t *testing.T) {
+	for _, policy := range []v1alpha1.DiskSanitizePolicy{
+		v1alpha1.DiskSanitizePolicySignatures,
+		v1alpha1.DiskSanitizePolicyZero,
+		v1alpha1.DiskSanitizePolicyBlkDiscard,
+		v1alpha1.DiskSanitizePolicyNVMeFormat,
+		v1alpha1.DiskSanitizePolicyNVMeSanitize,
+		v1alpha1.DiskSanitizePolicyATASecureErase,
+	} {
+		if _, err := New(policy); err != nil {
+			t.Errorf("Expect sanitizer of %s, got error %v", policy, err)
+		}
+		if !IsValidPolicy(policy) {
+			t.Errorf("Expect %s to be valid", policy)
+		}
+	}
+
+	if _, err := New(v1alpha1.DiskSanitizePolicyNone); err == nil {
+		t.Error("Expect no sanitizer of none policy")
+	}
+	if !IsValidPolicy("") || !IsValidPolicy(v1alpha1.DiskSanitizePolicyNone) {
+		t.Error("Expect empty and none policy to be valid")
+	}
+	if IsValidPolicy("shred") {
+		t.Error("Expect shred policy to be invalid")
+	}
+}
+
+func TestZeroSanitizer_Sanitize(t *testing.T) {
+	devPath := filepath.Join(t.TempDir(), "disk")
+	size := zeroChunkSize*2 + 4096
+	if err := os.WriteFile(devPath, bytes.Repeat([]byte{0xff}, size), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var progresses []int64
+	if err := (&zeroSanitizer{}).Sanitize(context.TODO(), devPath, func(percent int64) {
+		progresses = append(progresses, percent)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(devPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != size {
+		t.Fatalf("Expect size %d, got %d", size, len(data))
+	}
+	if !bytes.Equal(data, make([]byte, size)) {
+		t.Error("Expect disk to be overwritten with zeros")
+	}
+	if len(progresses) < 2 || progresses[len(progresses)-1] != 100 {
+		t.Errorf("Expect progress to end with 100, got %v", progresses)
+	}
+}
+
+func TestCommandSanitizer_Sanitize(t *testing.T) {
+	var cmds []string
+	execCommand = func(_ context.Context, name string, args ...string) (string, error) {
+		cmds = append(cmds, strings.Join(append([]string{name}, args...), " "))
+		return "", nil
+	}
+	defer func() { execCommand = utils.RunCommand }()
+
+	for policy, want := range map[v1alpha1.DiskSanitizePolicy]string{
+		v1alpha1.DiskSanitizePolicySignatures: "wipefs -af /dev/sdb",
+		v1alpha1.DiskSanitizePolicyBlkDiscard: "blkdiscard /dev/sdb",
+		v1alpha1.DiskSanitizePolicyNVMeFormat: "nvme format --ses=1 --force /dev/sdb",
+	} {
+		cmds = nil
+		sanitizer, _ := New(policy)
+		var progress int64
+		if err := sanitizer.Sanitize(context.TODO(), "/dev/sdb", func(percent int64) { progress = percent }); err != nil {
+			t.Fatal(err)
+		}
+		if len(cmds) != 1 || cmds[0] != want {
+			t.Errorf("Expect %s to run %q, got %v", policy, want, cmds)
+		}
+		if progress != 100 {
+			t.Errorf("Expect progress 100 of %s, got %d", policy, progress)
+		}
+	}
+}
+
+func TestParseNVMeSanitizeLog(t *testing.T) {
+	testCases := []struct {
+		description string
+		out         string
+		progress    int64
+		done        bool
+		wantErr     bool
+	}{
+		{
+			description: "in progress",
+			out:         `{"sprog":32768,"sstat":2,"scdw10":2}`,
+			progress:    50,
+		},
+		{
+			description: "in progress keyed by device",
+			out:         `{"nvme0n1":{"sprog":16384,"sstat":258,"scdw10":2}}`,
+			progress:    25,
+		},
+		{
+			description: "completed",
+			out:         `{"sprog":65535,"sstat":257,"scdw10":2}`,
+			progress:    100,
+			done:        true,
+		},
+		{
+			description: "completed without deallocation",
+			out:         `{"sprog":65535,"sstat":4,"scdw10":2}`,
+			progress:    100,
+			done:        true,
+		},
+		{
+			description: "failed",
+			out:         `{"sprog":0,"sstat":3,"scdw10":2}`,
+			wantErr:     true,
+		},
+		{
+			description: "not started",
+			out:         `{"sprog":0,"sstat":0,"scdw10":0}`,
+			wantErr:     true,
+		},
+		{
+			description: "no status",
+			out:         `{}`,
+			wantErr:     true,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.description, func(t *testing.T) {
+			progress, done, err := parseNVMeSanitizeLog(testCase.out)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("Expect error %v, got %v", testCase.wantErr, err)
+			}
+			if progress != testCase.progress || done != testCase.done {
+				t.Errorf("Expect progress %d done %v, got %d %v", testCase.progress, testCase.done, progress, done)
+			}
+		})
+	}
+}
+
+func TestNVMeSanitizer_Sanitize(t *testing.T) {
+	logs := []string{`{"sprog":32768,"sstat":2}`, `{"sprog":65535,"sstat":1}`}
+	execCommand = func(_ context.Context, name string, args ...string) (string, error) {
+		if args[0] != "sanitize-log" {
+			return "", nil
+		}
+		out := logs[0]
+		logs = logs[1:]
+		return out, nil
+	}
+	defer func() { execCommand = utils.RunCommand }()
+
+	var progresses []int64
+	if err := (&nvmeSanitizer{pollInterval: time.Millisecond}).Sanitize(context.TODO(), "/dev/nvme0n1", func(percent int64) {
+		progresses = append(progresses, percent)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(progresses) != "[50 100]" {
+		t.Errorf("Expect progress [50 100], got %v", progresses)
+	}
+}
+
+func TestATASecureEraseSanitizer_Sanitize(t *testing.T) {
+	const notFrozen = `
+Security:
+	Master password revision code = 65534
+		supported
+	not	enabled
+	not	locked
+	not	frozen
+	not	expired: security count
+`
+	const frozen = `
+Security:
+	Master password revision code = 65534
+		supported
+	not	enabled
+	not	locked
+		frozen
+	not	expired: security count
+`
+	if isATASecurityFrozen(notFrozen) {
+		t.Error("Expect security not frozen")
+	}
+	if !isATASecurityFrozen(frozen) {
+		t.Error("Expect security frozen")
+	}
+
+	var cmds []string
+	identify := frozen
+	execCommand = func(_ context.Context, name string, args ...string) (string, error) {
+		cmds = append(cmds, strings.Join(append([]string{name}, args...), " "))
+		return identify, nil
+	}
+	defer func() { execCommand = utils.RunCommand }()
+
+	if err := (&ataSecureEraseSanitizer{}).Sanitize(context.TODO(), "/dev/sdb", func(int64) {}); err == nil {
+		t.Error("Expect frozen drive to be refused")
+	}
+	if len(cmds) != 1 {
+		t.Errorf("Expect no erase of frozen drive, got %v", cmds)
+	}
+
+	cmds, identify = nil, notFrozen
+	if err := (&ataSecureEraseSanitizer{}).Sanitize(context.TODO(), "/dev/sdb", func(int64) {}); err != nil {
+		t.Fatal(err)
+	}
+	if len(cmds) != 3 || !strings.Contains(cmds[2], "--security-erase") {
+		t.Errorf("Expect security erase, got %v", cmds)
+	}
+
+	// the password is cleared if the erase fails
+	cmds = nil
+	execCommand = func(_ context.Context, name string, args ...string) (string, error) {
+		cmds = append(cmds, strings.Join(append([]string{name}, args...), " "))
+		if strings.Contains(cmds[len(cmds)-1], "--security-erase") {
+			return "", fmt.Errorf("security erase failed")
+		}
+		return notFrozen, nil
+	}
+	if err := (&ataSecureEraseSanitizer{}).Sanitize(context.TODO(), "/dev/sdb", func(int64) {}); err == nil {
+		t.Error("Expect failed security erase to be reported")
+	}
+	if len(cmds) != 4 || !strings.Contains(cmds[3], "--security-disable") {
+		t.Errorf("Expect security disable after failed erase, got %v", cmds)
+	}
+}
+
+func TestATASecurityPasswordMasked(t *testing.T) {
+	for _, flag := range []string{"--security-set-pass", "--security-erase", "--security-disable"} {
+		cmdLine := utils.MaskCommandLine("hdparm", "--user-master", "u", flag, ataSecurityPassword, "/dev/sdb")
+		if strings.Contains(cmdLine, ataSecurityPassword) || !strings.HasSuffix(cmdLine, "/dev/sdb") {
+			t.Errorf("Expect password to be masked, got %s", cmdLine)
+		}
+	}
+
+	_, err := utils.RunCommand(context.TODO(), "/nonexistent/hdparm", "--security-set-pass", ataSecurityPassword, "/dev/sdb")
+	if err == nil || strings.Contains(err.Error(), ataSecurityPassword) {
+		t.Errorf("Expect password to be masked in error, got %v", err)
+	}
+}
+
+func TestRunner(t *testing.T) {
+	devPath := filepath.Join(t.TempDir(), "disk")
+	if err := os.WriteFile(devPath, bytes.Repeat([]byte{0xff}, 4096), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := NewRunner()
+	job := runner.Start("ldv-1", devPath, &zeroSanitizer{})
+	if runner.Start("ldv-1", devPath, &zeroSanitizer{}) != job {
+		t.Error("Expect the running job to be returned")
+	}
+
+	for i := 0; i < 100; i++ {
+		if done, _ := job.Done(); done {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	done, err := job.Done()
+	if !done || err != nil {
+		t.Fatalf("Expect job done without error, got %v %v", done, err)
+	}
+	if job.Progress() != 100 {
+		t.Errorf("Expect progress 100, got %d", job.Progress())
+	}
+
+	runner.Remove("ldv-1")
+	if runner.Get("ldv-1") != nil {
+		t.Error("Expect job to be removed")
+	}
+
+	job = runner.Start("ldv-2", filepath.Join(t.TempDir(), "missing"), &zeroSanitizer{})
+	for i := 0; i < 100; i++ {
+		if done, _ := job.Done(); done {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if done, err := job.Done(); !done || err == nil {
+		t.Errorf("Expect job failed on missing disk, got %v %v", done, err)
+	}
+}
@@ -2,6 +2,7 @@ package scheduler
 
 import (
 	"fmt"
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/member/controller/disk"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/member/controller/volume"
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/member/types"
@@ -12,7 +13,6 @@ import (
 	"k8s.io/kubernetes/pkg/scheduler/framework"
 	"sort"
 	"strings"
-	"sync"
 
 	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/csi/driver/identity"
 )
@@ -101,8 +101,50 @@ func (s *diskVolumeSchedulerPlugin) Unreserve(pendingVolumes []*v1.PersistentVol
 	return nil
 }
 
+// Score prefers the node where the partition volumes can be placed on the disks already partitioned,
+// so that the available disks are kept for the whole disk volumes
 func (s *diskVolumeSchedulerPlugin) Score(unboundPVCs []*v1.PersistentVolumeClaim, node string) (int64, error) {
-	return framework.MinNodeScore, nil
+	if len(unboundPVCs) == 0 {
+		return framework.MinNodeScore, nil
+	}
+	nodeDisks, err := s.diskNodeHandler.GetNodeDisks(node)
+	if err != nil {
+		return 0, err
+	}
+	freeExtents := map[string][]v1alpha1.DiskExtent{}
+	for _, d := range nodeDisks {
+		if d.Partitioned {
+			freeExtents[d.DiskType] = append(freeExtents[d.DiskType], d.FreeExtents...)
+		}
+	}
+
+	// score for each volume
+	var scoreTotal int64
+	for _, volume := range unboundPVCs {
+		score, err := s.scoreOneVolume(volume, freeExtents)
+		if err != nil {
+			return 0, err
+		}
+		scoreTotal += score
+	}
+
+	return int64(float64(scoreTotal) / float64(framework.MaxNodeScore*int64(len(unboundPVCs))) * float64(framework.MaxNodeScore)), nil
+}
+
+func (s *diskVolumeSchedulerPlugin) scoreOneVolume(pvc *v1.PersistentVolumeClaim, freeExtents map[string][]v1alpha1.DiskExtent) (int64, error) {
+	params, err := s.getParamsFromStorageClass(pvc)
+	if err != nil {
+		return 0, err
+	}
+	if params.AllocationMode != string(v1alpha1.DiskAllocationModePartition) {
+		return framework.MinNodeScore, nil
+	}
+
+	size := types.AlignPartitionSize(pvc.Spec.Resources.Requests.Storage().Value())
+	if types.FindBestFitExtent(freeExtents[params.DiskType], size) < 0 {
+		return framework.MinNodeScore, nil
+	}
+	return framework.MaxNodeScore, nil
 }
 
 func (s *diskVolumeSchedulerPlugin) removeDuplicatePVC(pendingVolumes []*v1.PersistentVolumeClaim) (pvs []*v1.PersistentVolumeClaim) {
@@ -168,76 +210,81 @@ func (s *diskVolumeSchedulerPlugin) getParamsFromStorageClass(volume *v1.Persist
 
 // filterPendingVolumes select free disks for pending pvc
 func (s *diskVolumeSchedulerPlugin) filterPendingVolumes(pendingVolumes []*v1.PersistentVolumeClaim, tobeScheduleNode string) (bool, error) {
-	avaSortDisks, err := s.diskNodeHandler.GetNodeAvailableDisks(tobeScheduleNode)
+	nodeDisks, err := s.diskNodeHandler.GetNodeDisks(tobeScheduleNode)
 	if err != nil {
 		return false, err
 	}
 
-	pendingSortVolumes := s.removeDuplicatePVC(pendingVolumes)
-	if len(pendingVolumes) > len(avaSortDisks) {
-		log.WithFields(log.Fields{"avaSortDisks": len(avaSortDisks), "pendingVolumes": len(pendingVolumes)}).Info("No enough free disks")
-		return false, nil
-	}
-
-	// descending order
-	sort.Sort(sort.Reverse(utils.ByDiskSize(avaSortDisks)))
-	sort.Sort(sort.Reverse(utils.ByVolumeCapacity(pendingSortVolumes)))
-
-	// we should order available disks and persistent volume claim by d type(e.g. HDD,SSD etc.)
-	var classSortDisks, classSortVolumes sync.Map
-	for _, d := range avaSortDisks {
-		v, ok := classSortDisks.Load(d.DiskType)
-		var classDisks []types.Disk
-		if ok {
-			classDisks = v.([]types.Disk)
-		}
-
-		classDisks = append(classDisks, d)
-		classSortDisks.Store(d.DiskType, classDisks)
+	diskRequests, partitionRequests, err := s.groupPendingVolumes(s.removeDuplicatePVC(pendingVolumes))
+	if err != nil {
+		return false, err
 	}
+	return fitPendingVolumes(nodeDisks, diskRequests, partitionRequests), nil
+}
 
-	for _, vol := range pendingSortVolumes {
+// groupPendingVolumes groups the request capacity of the pvc by the disk type(e.g. HDD,SSD etc.),
+// for the whole disk volumes and the partition volumes respectively
+func (s *diskVolumeSchedulerPlugin) groupPendingVolumes(pendingVolumes []*v1.PersistentVolumeClaim) (map[string][]int64, map[string][]int64, error) {
+	diskRequests, partitionRequests := map[string][]int64{}, map[string][]int64{}
+	for _, vol := range pendingVolumes {
 		params, err := s.getParamsFromStorageClass(vol)
 		if err != nil {
-			return false, err
+			return nil, nil, err
 		}
-		var classVolumes []*v1.PersistentVolumeClaim
-		v, ok := classSortVolumes.Load(params.DiskType)
-		if ok {
-			classVolumes = v.([]*v1.PersistentVolumeClaim)
+		request := vol.Spec.Resources.Requests.Storage().Value()
+		if params.AllocationMode == string(v1alpha1.DiskAllocationModePartition) {
+			partitionRequests[params.DiskType] = append(partitionRequests[params.DiskType], request)
+		} else {
+			diskRequests[params.DiskType] = append(diskRequests[params.DiskType], request)
 		}
-
-		classVolumes = append(classVolumes, vol)
-		classSortVolumes.Store(params.DiskType, classVolumes)
 	}
+	return diskRequests, partitionRequests, nil
+}
 
-	var meetup = true
-	// compare request storage capacity and available disk capacity
-	classSortVolumes.Range(func(key, value any) bool {
-		volumeType := key.(string)
-		classPendingVolumes := value.([]*v1.PersistentVolumeClaim)
-		if len(classPendingVolumes) == 0 {
-			return true
+// fitPendingVolumes returns true if the disks on the node can hold all the pending volumes.
+// The largest whole disk volume takes the largest available disk, and the partition volumes
+// are allocated from the free extents of the partitioned disks and the available disks left
+func fitPendingVolumes(nodeDisks []types.Disk, diskRequests, partitionRequests map[string][]int64) bool {
+	availableDisks := map[string][]types.Disk{}
+	freeExtents := map[string][]v1alpha1.DiskExtent{}
+	for _, d := range nodeDisks {
+		if d.Status == types.DiskStatusAvailable {
+			availableDisks[d.DiskType] = append(availableDisks[d.DiskType], d)
+		} else if d.Partitioned {
+			freeExtents[d.DiskType] = append(freeExtents[d.DiskType], d.FreeExtents...)
 		}
-		v, ok := classSortDisks.Load(volumeType)
-		if !ok {
-			log.WithFields(log.Fields{"volumeType": volumeType, "volumes": len(classPendingVolumes)}).Info("There is no matchable type disk available")
-			meetup = false
-			return meetup
+	}
+
+	// compare request storage capacity and available disk capacity in descending order
+	for diskType, requests := range diskRequests {
+		classAvailableDisks := availableDisks[diskType]
+		if len(requests) > len(classAvailableDisks) {
+			log.WithFields(log.Fields{"volumeType": diskType, "avaSortDisks": len(classAvailableDisks), "pendingVolumes": len(requests)}).Info("No enough free disks")
+			return false
 		}
-		classAvailableDisks := v.([]types.Disk)
-		for i, pendingVolume := range classPendingVolumes {
-			if pendingVolume.Spec.Resources.Requests.Storage().Value() <= classAvailableDisks[i].Capacity {
-				continue
+		sort.Sort(sort.Reverse(utils.ByDiskSize(classAvailableDisks)))
+		sort.Slice(requests, func(i, j int) bool { return requests[i] > requests[j] })
+		for i, request := range requests {
+			if request > classAvailableDisks[i].Capacity {
+				log.WithFields(log.Fields{"index": i, "volumeType": diskType, "requestCapacity": request}).
+					Info("Can't meetup volume request storage capacity")
+				return false
 			}
-			log.WithFields(log.Fields{"index": i, "pendingVolume": pendingVolume.GetName(), "requestCapacity": pendingVolume.Spec.Resources.Requests.Storage().Value()}).
-				Info("Can't meetup volume request storage capacity")
-			meetup = false
-			return meetup
 		}
-		return true
-	})
-	return meetup, nil
+		availableDisks[diskType] = classAvailableDisks[len(requests):]
+	}
+
+	for diskType, requests := range partitionRequests {
+		extents := append([]v1alpha1.DiskExtent{}, freeExtents[diskType]...)
+		for _, d := range availableDisks[diskType] {
+			extents = append(extents, types.WholeDiskExtent(d.Capacity))
+		}
+		if !types.FitPartitions(extents, requests) {
+			log.WithFields(log.Fields{"volumeType": diskType, "pendingVolumes": len(requests)}).Info("No enough free space for partition volumes")
+			return false
+		}
+	}
+	return true
 }
 
 func (s *diskVolumeSchedulerPlugin) CSIDriverName() string {
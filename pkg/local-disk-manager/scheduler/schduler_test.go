@@ -0,0 +1,66 @@
+package scheduler
+
+import (
+	"testing"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-disk-manager/member/types"
+)
+
+func Test_fitPendingVolumes(t *testing.T) {
+	const gib = 1024 * 1024 * 1024
+	nodeDisks := []types.Disk{
+		{DevPath: "/dev/sdb", Capacity: 100 * gib, DiskType: types.DevTypeHDD, Status: types.DiskStatusAvailable},
+		{DevPath: "/dev/sdc", Capacity: 50 * gib, DiskType: types.DevTypeHDD, Status: types.DiskStatusAvailable},
+		{DevPath: "/dev/nvme0n1", Capacity: 100 * gib, DiskType: types.DevTypeNVMe, Partitioned: true,
+			FreeExtents: []v1alpha1.DiskExtent{{StartBytes: 60 * gib, SizeBytes: 20 * gib}}},
+	}
+
+	testcases := []struct {
+		Description       string
+		DiskRequests      map[string][]int64
+		PartitionRequests map[string][]int64
+		Expect            bool
+	}{
+		{
+			Description:  "whole disk volumes fit the available disks",
+			DiskRequests: map[string][]int64{types.DevTypeHDD: {50 * gib, 80 * gib}},
+			Expect:       true,
+		},
+		{
+			Description:  "more whole disk volumes than the available disks",
+			DiskRequests: map[string][]int64{types.DevTypeHDD: {gib, gib, gib}},
+			Expect:       false,
+		},
+		{
+			Description:       "partition volumes fit the free extents",
+			PartitionRequests: map[string][]int64{types.DevTypeNVMe: {10 * gib, 10 * gib}},
+			Expect:            true,
+		},
+		{
+			Description:       "partition volumes exceed the free extents",
+			PartitionRequests: map[string][]int64{types.DevTypeNVMe: {10 * gib, 10*gib + 1}},
+			Expect:            false,
+		},
+		{
+			Description:       "partition volumes use the available disk left by the whole disk volumes",
+			DiskRequests:      map[string][]int64{types.DevTypeHDD: {80 * gib}},
+			PartitionRequests: map[string][]int64{types.DevTypeHDD: {20 * gib, 20 * gib}},
+			Expect:            true,
+		},
+		{
+			Description:       "partition volumes can't use the disk taken by the whole disk volumes",
+			DiskRequests:      map[string][]int64{types.DevTypeHDD: {80 * gib}},
+			PartitionRequests: map[string][]int64{types.DevTypeHDD: {60 * gib}},
+			Expect:            false,
+		},
+	}
+
+	for _, testcase := range testcases {
+		t.Run(testcase.Description, func(t *testing.T) {
+			if fit := fitPendingVolumes(nodeDisks, testcase.DiskRequests, testcase.PartitionRequests); fit != testcase.Expect {
+				t.Fatalf("Expect %v, got %v", testcase.Expect, fit)
+			}
+		})
+	}
+}
@@ -5,11 +5,13 @@ import (
 )
 
 type StorageClassParams struct {
-	DiskType string `json:"diskType"`
+	DiskType       string `json:"diskType"`
+	AllocationMode string `json:"allocationMode"`
 }
 
 func parseParams(params map[string]string) *StorageClassParams {
 	return &StorageClassParams{
-		DiskType: params[volume.VolumeParameterDiskTypeKey],
+		DiskType:       params[volume.VolumeParameterDiskTypeKey],
+		AllocationMode: params[volume.VolumeParameterAllocationMode],
 	}
 }
@@ -0,0 +1,164 @@
+package smart
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+const (
+	// DefaultHealthSampleWindow is the number of the samples evaluated if not set by the policy
+	DefaultHealthSampleWindow = 8
+
+	// ATA S.M.A.R.T attribute IDs
+	_ATAReallocatedSectorCount = 5
+	_ATACurrentPendingSector   = 197
+	_ATAUDMACRCErrorCount      = 199
+)
+
+// DefaultHealthThresholds are used for the attributes not set by the policy
+var DefaultHealthThresholds = v1alpha1.DiskHealthThresholds{
+	ReallocatedSectors: &v1alpha1.DiskHealthThreshold{Degraded: 10, Failing: 100, Growth: 5},
+	PendingSectors:     &v1alpha1.DiskHealthThreshold{Degraded: 1, Failing: 10, Growth: 1},
+	MediaErrors:        &v1alpha1.DiskHealthThreshold{Degraded: 1, Failing: 10, Growth: 1},
+	PercentageUsed:     &v1alpha1.DiskHealthThreshold{Degraded: 90, Failing: 100},
+	CRCErrors:          &v1alpha1.DiskHealthThreshold{Growth: 10},
+	Temperature:        &v1alpha1.DiskHealthThreshold{Degraded: 60, Failing: 70},
+}
+
+// MergeHealthPolicy fills the fields not set in the policy with the defaults
+func MergeHealthPolicy(spec *v1alpha1.DiskHealthPolicySpec) *v1alpha1.DiskHealthPolicySpec {
+	merged := &v1alpha1.DiskHealthPolicySpec{}
+	if spec != nil {
+		merged = spec.DeepCopy()
+	}
+	if merged.SampleWindow <= 0 {
+		merged.SampleWindow = DefaultHealthSampleWindow
+	}
+
+	thresholds := &merged.Thresholds
+	for _, t := range []struct {
+		threshold    **v1alpha1.DiskHealthThreshold
+		defaultValue *v1alpha1.DiskHealthThreshold
+	}{
+		{&thresholds.ReallocatedSectors, DefaultHealthThresholds.ReallocatedSectors},
+		{&thresholds.PendingSectors, DefaultHealthThresholds.PendingSectors},
+		{&thresholds.MediaErrors, DefaultHealthThresholds.MediaErrors},
+		{&thresholds.PercentageUsed, DefaultHealthThresholds.PercentageUsed},
+		{&thresholds.CRCErrors, DefaultHealthThresholds.CRCErrors},
+		{&thresholds.Temperature, DefaultHealthThresholds.Temperature},
+	} {
+		if *t.threshold == nil {
+			*t.threshold = t.defaultValue.DeepCopy()
+		}
+	}
+	return merged
+}
+
+// NewHealthSample picks the key attributes from the result of smartctl
+func NewHealthSample(result *Result, now time.Time) v1alpha1.DiskHealthSample {
+	sample := v1alpha1.DiskHealthSample{
+		Time: metav1.Time{Time: now},
+		// the self-assessment is unknown for some devices, e.g. USB disks, don't take it as failed
+		OverallHealthPassed: result.SmartStatus == nil || result.SmartStatus.Passed,
+		ReallocatedSectors:  result.ScsiGrownDefectList,
+		MediaErrors:         result.NvmeSmartHealthInformationLog.MediaErrors,
+		PercentageUsed:      int64(result.NvmeSmartHealthInformationLog.PercentageUsed),
+		Temperature:         int64(result.Temperature.Current),
+	}
+
+	for _, attr := range result.AtaSmartAttributes.Table {
+		switch attr.ID {
+		case _ATAReallocatedSectorCount:
+			sample.ReallocatedSectors = int64(attr.Raw.Value)
+		case _ATACurrentPendingSector:
+			sample.PendingSectors = int64(attr.Raw.Value)
+		case _ATAUDMACRCErrorCount:
+			sample.CRCErrors = int64(attr.Raw.Value)
+		}
+	}
+	return sample
+}
+
+// AppendHealthSample appends the sample and drops the ones out of the window
+func AppendHealthSample(samples []v1alpha1.DiskHealthSample, sample v1alpha1.DiskHealthSample, window int) []v1alpha1.DiskHealthSample {
+	samples = append(samples, sample)
+	if len(samples) > window {
+		samples = samples[len(samples)-window:]
+	}
+	return samples
+}
+
+// EvaluateHealth evaluates the health state of the disk from the samples in the window, the latest is the last.
+// The state is the worst one of all the attributes, and the reasons are given for the unhealthy attributes
+func EvaluateHealth(spec *v1alpha1.DiskHealthPolicySpec, samples []v1alpha1.DiskHealthSample) (v1alpha1.DiskHealthState, []string) {
+	state := v1alpha1.DiskHealthy
+	var reasons []string
+	if len(samples) == 0 {
+		return state, reasons
+	}
+	if len(samples) > spec.SampleWindow {
+		samples = samples[len(samples)-spec.SampleWindow:]
+	}
+	degrade := func(s v1alpha1.DiskHealthState, reason string) {
+		if !state.Reaches(s) {
+			state = s
+		}
+		reasons = append(reasons, reason)
+	}
+
+	if !samples[len(samples)-1].OverallHealthPassed {
+		degrade(v1alpha1.DiskFailing, "S.M.A.R.T overall-health self-assessment failed")
+	}
+
+	thresholds := spec.Thresholds
+	for _, attr := range []struct {
+		name      string
+		threshold *v1alpha1.DiskHealthThreshold
+		counter   bool
+		value     func(v1alpha1.DiskHealthSample) int64
+	}{
+		{"ReallocatedSectors", thresholds.ReallocatedSectors, true, func(s v1alpha1.DiskHealthSample) int64 { return s.ReallocatedSectors }},
+		{"PendingSectors", thresholds.PendingSectors, true, func(s v1alpha1.DiskHealthSample) int64 { return s.PendingSectors }},
+		{"MediaErrors", thresholds.MediaErrors, true, func(s v1alpha1.DiskHealthSample) int64 { return s.MediaErrors }},
+		{"PercentageUsed", thresholds.PercentageUsed, false, func(s v1alpha1.DiskHealthSample) int64 { return s.PercentageUsed }},
+		{"CRCErrors", thresholds.CRCErrors, true, func(s v1alpha1.DiskHealthSample) int64 { return s.CRCErrors }},
+	} {
+		if attr.threshold == nil {
+			continue
+		}
+		first, latest := attr.value(samples[0]), attr.value(samples[len(samples)-1])
+		switch {
+		case attr.threshold.Failing > 0 && latest >= attr.threshold.Failing:
+			degrade(v1alpha1.DiskFailing, fmt.Sprintf("%s %d reaches the failing threshold %d", attr.name, latest, attr.threshold.Failing))
+		case attr.threshold.Degraded > 0 && latest >= attr.threshold.Degraded:
+			degrade(v1alpha1.DiskDegraded, fmt.Sprintf("%s %d reaches the degraded threshold %d", attr.name, latest, attr.threshold.Degraded))
+		case attr.counter && attr.threshold.Growth > 0 && latest-first >= attr.threshold.Growth:
+			degrade(v1alpha1.DiskDegraded, fmt.Sprintf("%s increased by %d in the last %d samples", attr.name, latest-first, len(samples)))
+		}
+	}
+
+	if threshold := thresholds.Temperature; threshold != nil {
+		// a single spike of the temperature doesn't degrade the disk, the average does
+		var sum, count int64
+		for _, sample := range samples {
+			if sample.Temperature > 0 {
+				sum += sample.Temperature
+				count++
+			}
+		}
+		latest := samples[len(samples)-1].Temperature
+		switch {
+		case threshold.Failing > 0 && latest >= threshold.Failing:
+			degrade(v1alpha1.DiskFailing, fmt.Sprintf("Temperature %d reaches the failing threshold %d", latest, threshold.Failing))
+		case threshold.Degraded > 0 && count > 0 && sum/count >= threshold.Degraded:
+			degrade(v1alpha1.DiskDegraded, fmt.Sprintf("Average temperature %d in the last %d samples reaches the degraded threshold %d",
+				sum/count, count, threshold.Degraded))
+		}
+	}
+
+	return state, reasons
+}
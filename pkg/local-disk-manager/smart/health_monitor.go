@@ -0,0 +1,109 @@
+package smart
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+// HealthMonitor evaluates the health of the LocalDisks on the node from the collected S.M.A.R.T results
+type HealthMonitor struct {
+	client   client.Client
+	nodeName string
+}
+
+func NewHealthMonitor(cli client.Client, nodeName string) *HealthMonitor {
+	return &HealthMonitor{client: cli, nodeName: nodeName}
+}
+
+// Update appends the samples of the results to the LocalDisks on the node, and evaluates their health again
+func (m *HealthMonitor) Update(results TotalResult) error {
+	logCtx := log.WithField("node", m.nodeName)
+	spec, err := m.getPolicy()
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get DiskHealthPolicy")
+		return err
+	}
+
+	diskList := &v1alpha1.LocalDiskList{}
+	if err = m.client.List(context.TODO(), diskList, client.MatchingFields{"spec.nodeName": m.nodeName}); err != nil {
+		logCtx.WithError(err).Error("Failed to list LocalDisks")
+		return err
+	}
+
+	now := time.Now()
+	for i := range diskList.Items {
+		disk := &diskList.Items[i]
+		result := findResultOfDisk(results, disk)
+		if result == nil {
+			continue
+		}
+		if err = m.updateDiskHealth(disk, spec, NewHealthSample(result, now)); err != nil {
+			logCtx.WithField("disk", disk.Name).WithError(err).Error("Failed to update the health of LocalDisk")
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *HealthMonitor) getPolicy() (*v1alpha1.DiskHealthPolicySpec, error) {
+	policy := &v1alpha1.DiskHealthPolicy{}
+	if err := m.client.Get(context.TODO(), types.NamespacedName{Name: v1alpha1.DefaultDiskHealthPolicyName}, policy); err != nil {
+		if errors.IsNotFound(err) {
+			return MergeHealthPolicy(nil), nil
+		}
+		return nil, err
+	}
+	return MergeHealthPolicy(&policy.Spec), nil
+}
+
+func (m *HealthMonitor) updateDiskHealth(disk *v1alpha1.LocalDisk, spec *v1alpha1.DiskHealthPolicySpec, sample v1alpha1.DiskHealthSample) error {
+	oldDisk := disk.DeepCopy()
+	if disk.Status.Health == nil {
+		disk.Status.Health = &v1alpha1.DiskHealthStatus{}
+	}
+	health := disk.Status.Health
+	health.Samples = AppendHealthSample(health.Samples, sample, spec.SampleWindow)
+
+	state, reasons := EvaluateHealth(spec, health.Samples)
+	if state != health.State {
+		log.WithFields(log.Fields{"disk": disk.Name, "from": health.State, "to": state, "reasons": reasons}).Info("Health state of the disk changed")
+		health.State = state
+		health.LastTransitionTime = metav1.Time{Time: sample.Time.Time}
+	}
+	health.Reasons = reasons
+
+	if reflect.DeepEqual(oldDisk.Status, disk.Status) {
+		return nil
+	}
+	// the disk is patched, so the reaction recorded by LocalStorage is kept
+	return m.client.Status().Patch(context.TODO(), disk, client.MergeFrom(oldDisk))
+}
+
+// findResultOfDisk finds the result of the disk by the serial number, or the device path
+func findResultOfDisk(results TotalResult, disk *v1alpha1.LocalDisk) *Result {
+	for i := range results {
+		result := &results[i]
+		if len(result.Error) > 0 {
+			continue
+		}
+		if len(disk.Spec.DiskAttributes.SerialNumber) > 0 && result.SerialNumber == disk.Spec.DiskAttributes.SerialNumber {
+			return result
+		}
+	}
+	for i := range results {
+		result := &results[i]
+		if len(result.Error) == 0 && !disk.Spec.HasRAID && result.Device.Name == disk.Spec.DevicePath {
+			return result
+		}
+	}
+	return nil
+}
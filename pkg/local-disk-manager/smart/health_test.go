@@ -0,0 +1,316 @@
+package smart
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+const (
+	ataSmartResultJson = `{
+  "device": {"name": "/dev/sda", "info_name": "/dev/sda [SAT]", "type": "sat", "protocol": "ATA"},
+  "serial_number": "WD-WCC4N0000001",
+  "smart_status": {"passed": true},
+  "ata_smart_attributes": {
+    "revision": 16,
+    "table": [
+      {"id": 5, "name": "Reallocated_Sector_Ct", "value": 200, "worst": 200, "thresh": 140, "raw": {"value": 12, "string": "12"}},
+      {"id": 194, "name": "Temperature_Celsius", "value": 112, "worst": 100, "thresh": 0, "raw": {"value": 38, "string": "38"}},
+      {"id": 197, "name": "Current_Pending_Sector", "value": 200, "worst": 200, "thresh": 0, "raw": {"value": 2, "string": "2"}},
+      {"id": 199, "name": "UDMA_CRC_Error_Count", "value": 200, "worst": 200, "thresh": 0, "raw": {"value": 7, "string": "7"}}
+    ]
+  },
+  "temperature": {"current": 38}
+}`
+
+	nvmeSmartResultJson = `{
+  "device": {"name": "/dev/nvme0", "info_name": "/dev/nvme0", "type": "nvme", "protocol": "NVMe"},
+  "serial_number": "S4EWNX0N000001",
+  "smart_status": {"passed": false},
+  "nvme_smart_health_information_log": {
+    "critical_warning": 4,
+    "temperature": 45,
+    "available_spare": 100,
+    "percentage_used": 92,
+    "media_errors": 3,
+    "num_err_log_entries": 15
+  },
+  "temperature": {"current": 45}
+}`
+)
+
+func TestNewHealthSample(t *testing.T) {
+	now := time.Now()
+	testCases := []struct {
+		Description  string
+		ResultJson   string
+		ExpectSample v1alpha1.DiskHealthSample
+	}{
+		{
+			Description: "ATA disk",
+			ResultJson:  ataSmartResultJson,
+			ExpectSample: v1alpha1.DiskHealthSample{
+				Time:                metav1.Time{Time: now},
+				OverallHealthPassed: true,
+				ReallocatedSectors:  12,
+				PendingSectors:      2,
+				CRCErrors:           7,
+				Temperature:         38,
+			},
+		},
+		{
+			Description: "NVMe disk",
+			ResultJson:  nvmeSmartResultJson,
+			ExpectSample: v1alpha1.DiskHealthSample{
+				Time:           metav1.Time{Time: now},
+				MediaErrors:    3,
+				PercentageUsed: 92,
+				Temperature:    45,
+			},
+		},
+		{
+			Description: "no self-assessment",
+			ResultJson:  `{"device": {"name": "/dev/sdb"}, "temperature": {"current": 30}}`,
+			ExpectSample: v1alpha1.DiskHealthSample{
+				Time:                metav1.Time{Time: now},
+				OverallHealthPassed: true,
+				Temperature:         30,
+			},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Description, func(t *testing.T) {
+			result := Result{}
+			if err := json.Unmarshal([]byte(testCase.ResultJson), &result); err != nil {
+				t.Fatal(err)
+			}
+			if sample := NewHealthSample(&result, now); !reflect.DeepEqual(sample, testCase.ExpectSample) {
+				t.Errorf("expect sample %+v, but got %+v", testCase.ExpectSample, sample)
+			}
+		})
+	}
+}
+
+func TestEvaluateHealth(t *testing.T) {
+	testCases := []struct {
+		Description   string
+		Samples       []v1alpha1.DiskHealthSample
+		ExpectState   v1alpha1.DiskHealthState
+		ExpectReasons []string
+	}{
+		{
+			Description: "no sample",
+			ExpectState: v1alpha1.DiskHealthy,
+		},
+		{
+			Description: "healthy",
+			Samples: []v1alpha1.DiskHealthSample{
+				{OverallHealthPassed: true, ReallocatedSectors: 2, CRCErrors: 5, Temperature: 40},
+				{OverallHealthPassed: true, ReallocatedSectors: 3, CRCErrors: 8, Temperature: 42},
+			},
+			ExpectState: v1alpha1.DiskHealthy,
+		},
+		{
+			Description: "self-assessment failed",
+			Samples: []v1alpha1.DiskHealthSample{
+				{OverallHealthPassed: false},
+			},
+			ExpectState:   v1alpha1.DiskFailing,
+			ExpectReasons: []string{"S.M.A.R.T overall-health self-assessment failed"},
+		},
+		{
+			Description: "reallocated sectors grow",
+			Samples: []v1alpha1.DiskHealthSample{
+				{OverallHealthPassed: true, ReallocatedSectors: 1},
+				{OverallHealthPassed: true, ReallocatedSectors: 4},
+				{OverallHealthPassed: true, ReallocatedSectors: 6},
+			},
+			ExpectState:   v1alpha1.DiskDegraded,
+			ExpectReasons: []string{"ReallocatedSectors increased by 5 in the last 3 samples"},
+		},
+		{
+			Description: "growth out of the window",
+			Samples: func() []v1alpha1.DiskHealthSample {
+				samples := []v1alpha1.DiskHealthSample{{OverallHealthPassed: true, ReallocatedSectors: 1}}
+				for i := 0; i < DefaultHealthSampleWindow; i++ {
+					samples = append(samples, v1alpha1.DiskHealthSample{OverallHealthPassed: true, ReallocatedSectors: 6})
+				}
+				return samples
+			}(),
+			ExpectState: v1alpha1.DiskHealthy,
+		},
+		{
+			Description: "worst of the attributes",
+			Samples: []v1alpha1.DiskHealthSample{
+				{OverallHealthPassed: true, MediaErrors: 12, PercentageUsed: 95},
+			},
+			ExpectState: v1alpha1.DiskFailing,
+			ExpectReasons: []string{
+				"MediaErrors 12 reaches the failing threshold 10",
+				"PercentageUsed 95 reaches the degraded threshold 90",
+			},
+		},
+		{
+			Description: "temperature spike",
+			Samples: []v1alpha1.DiskHealthSample{
+				{OverallHealthPassed: true, Temperature: 40},
+				{OverallHealthPassed: true, Temperature: 40},
+				{OverallHealthPassed: true, Temperature: 65},
+			},
+			ExpectState: v1alpha1.DiskHealthy,
+		},
+		{
+			Description: "temperature sustained high",
+			Samples: []v1alpha1.DiskHealthSample{
+				{OverallHealthPassed: true, Temperature: 62},
+				{OverallHealthPassed: true},
+				{OverallHealthPassed: true, Temperature: 64},
+			},
+			ExpectState:   v1alpha1.DiskDegraded,
+			ExpectReasons: []string{"Average temperature 63 in the last 2 samples reaches the degraded threshold 60"},
+		},
+	}
+
+	spec := MergeHealthPolicy(nil)
+	for _, testCase := range testCases {
+		t.Run(testCase.Description, func(t *testing.T) {
+			state, reasons := EvaluateHealth(spec, testCase.Samples)
+			if state != testCase.ExpectState {
+				t.Errorf("expect state %s, but got %s", testCase.ExpectState, state)
+			}
+			if !reflect.DeepEqual(reasons, testCase.ExpectReasons) {
+				t.Errorf("expect reasons %v, but got %v", testCase.ExpectReasons, reasons)
+			}
+		})
+	}
+}
+
+func TestMergeHealthPolicy(t *testing.T) {
+	spec := &v1alpha1.DiskHealthPolicySpec{
+		Thresholds: v1alpha1.DiskHealthThresholds{
+			Temperature: &v1alpha1.DiskHealthThreshold{Degraded: 50},
+		},
+	}
+	merged := MergeHealthPolicy(spec)
+	if merged.SampleWindow != DefaultHealthSampleWindow {
+		t.Errorf("expect sample window %d, but got %d", DefaultHealthSampleWindow, merged.SampleWindow)
+	}
+	if !reflect.DeepEqual(merged.Thresholds.Temperature, spec.Thresholds.Temperature) {
+		t.Errorf("expect temperature threshold %+v, but got %+v", spec.Thresholds.Temperature, merged.Thresholds.Temperature)
+	}
+	if !reflect.DeepEqual(merged.Thresholds.ReallocatedSectors, DefaultHealthThresholds.ReallocatedSectors) {
+		t.Errorf("expect default reallocated sectors threshold, but got %+v", merged.Thresholds.ReallocatedSectors)
+	}
+	if spec.Thresholds.ReallocatedSectors != nil {
+		t.Error("the policy should not be modified")
+	}
+}
+
+func TestHealthMonitor_updateDiskHealth(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	disk := &v1alpha1.LocalDisk{
+		ObjectMeta: metav1.ObjectMeta{Name: "localdisk-1"},
+		Spec:       v1alpha1.LocalDiskSpec{NodeName: "node1", DevicePath: "/dev/sda"},
+		Status: v1alpha1.LocalDiskStatus{
+			State: v1alpha1.LocalDiskBound,
+			Health: &v1alpha1.DiskHealthStatus{
+				State:    v1alpha1.DiskHealthy,
+				Cordoned: true,
+				Samples:  []v1alpha1.DiskHealthSample{{OverallHealthPassed: true, PendingSectors: 0}},
+			},
+		},
+	}
+	cli := fake.NewClientBuilder().WithScheme(scheme).WithObjects(disk).Build()
+	monitor := NewHealthMonitor(cli, "node1")
+
+	spec := MergeHealthPolicy(&v1alpha1.DiskHealthPolicySpec{SampleWindow: 2})
+	for _, pending := range []int64{1, 2} {
+		current := &v1alpha1.LocalDisk{}
+		if err := cli.Get(context.TODO(), types.NamespacedName{Name: disk.Name}, current); err != nil {
+			t.Fatal(err)
+		}
+		sample := v1alpha1.DiskHealthSample{Time: metav1.Now(), OverallHealthPassed: true, PendingSectors: pending}
+		if err := monitor.updateDiskHealth(current, spec, sample); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	updated := &v1alpha1.LocalDisk{}
+	if err := cli.Get(context.TODO(), types.NamespacedName{Name: disk.Name}, updated); err != nil {
+		t.Fatal(err)
+	}
+	health := updated.Status.Health
+	if health.State != v1alpha1.DiskDegraded {
+		t.Errorf("expect state %s, but got %s", v1alpha1.DiskDegraded, health.State)
+	}
+	if len(health.Samples) != 2 || health.Samples[1].PendingSectors != 2 {
+		t.Errorf("expect the latest 2 samples kept, but got %+v", health.Samples)
+	}
+	if !health.Cordoned {
+		t.Error("expect the reaction kept")
+	}
+	if health.LastTransitionTime.IsZero() {
+		t.Error("expect the transition time set")
+	}
+}
+
+func TestFindResultOfDisk(t *testing.T) {
+	results := TotalResult{}
+	for _, resultJson := range []string{ataSmartResultJson, nvmeSmartResultJson} {
+		result := Result{}
+		if err := json.Unmarshal([]byte(resultJson), &result); err != nil {
+			t.Fatal(err)
+		}
+		results = append(results, result)
+	}
+
+	testCases := []struct {
+		Description  string
+		Disk         v1alpha1.LocalDisk
+		ExpectSerial string
+	}{
+		{
+			Description: "by serial number",
+			Disk: v1alpha1.LocalDisk{Spec: v1alpha1.LocalDiskSpec{
+				DevicePath:     "/dev/nvme0n1",
+				DiskAttributes: v1alpha1.DiskAttributes{SerialNumber: "S4EWNX0N000001"},
+			}},
+			ExpectSerial: "S4EWNX0N000001",
+		},
+		{
+			Description:  "by device path",
+			Disk:         v1alpha1.LocalDisk{Spec: v1alpha1.LocalDiskSpec{DevicePath: "/dev/sda"}},
+			ExpectSerial: "WD-WCC4N0000001",
+		},
+		{
+			Description: "not found",
+			Disk:        v1alpha1.LocalDisk{Spec: v1alpha1.LocalDiskSpec{DevicePath: "/dev/sdc"}},
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.Description, func(t *testing.T) {
+			result := findResultOfDisk(results, &testCase.Disk)
+			serial := ""
+			if result != nil {
+				serial = result.SerialNumber
+			}
+			if serial != testCase.ExpectSerial {
+				t.Errorf("expect result of %q, but got %q", testCase.ExpectSerial, serial)
+			}
+		})
+	}
+}
@@ -40,7 +40,8 @@ func NewSMARTStorage() (*storage.ConfigMap, error) {
 
 // collector collect stats by smartctl
 type collector struct {
-	syncPeriod time.Duration
+	syncPeriod    time.Duration
+	healthMonitor *HealthMonitor
 }
 
 func NewCollector() *collector {
@@ -52,6 +53,12 @@ func (c *collector) WithSyncPeriod(syncPeriod time.Duration) *collector {
 	return c
 }
 
+// WithHealthMonitor evaluates the health of the disks each time the result is collected
+func (c *collector) WithHealthMonitor(healthMonitor *HealthMonitor) *collector {
+	c.healthMonitor = healthMonitor
+	return c
+}
+
 // StartTimerCollect  collect S.M.A.R.T result periodically and save to configmap
 func (c *collector) StartTimerCollect(ctx context.Context) {
 	log.WithField("syncPeriod", c.syncPeriod).Info("Start S.M.A.R.T timer collect")
@@ -88,6 +95,12 @@ func (c *collector) collectAndSaveConfigMap() {
 		return
 	}
 
+	if c.healthMonitor != nil {
+		if err = c.healthMonitor.Update(totalResult); err != nil {
+			log.WithError(err).WithFields(logCtx).Error("Failed to update the health of the disks")
+		}
+	}
+
 	// storage result to configmap: hwameistor/hwameistor-smart-result
 	err = smartStorage.SetKV(node, (&totalResult).Marshal())
 	if err != nil {
@@ -245,7 +258,8 @@ type Result struct {
 		TimeT   int    `json:"time_t"`
 		Asctime string `json:"asctime"`
 	} `json:"local_time"`
-	SmartStatus struct {
+	// SmartStatus is nil if the device doesn't report the self-assessment
+	SmartStatus *struct {
 		Passed bool `json:"passed"`
 	} `json:"smart_status"`
 	AtaSmartData struct {
@@ -347,5 +361,14 @@ type Result struct {
 		} `json:"flags"`
 		PowerUpScanResumeMinutes int `json:"power_up_scan_resume_minutes"`
 	} `json:"ata_smart_selective_self_test_log"`
-	Error string `json:"error"`
+	NvmeSmartHealthInformationLog struct {
+		CriticalWarning  int   `json:"critical_warning"`
+		Temperature      int   `json:"temperature"`
+		AvailableSpare   int   `json:"available_spare"`
+		PercentageUsed   int   `json:"percentage_used"`
+		MediaErrors      int64 `json:"media_errors"`
+		NumErrLogEntries int64 `json:"num_err_log_entries"`
+	} `json:"nvme_smart_health_information_log"`
+	ScsiGrownDefectList int64  `json:"scsi_grown_defect_list"`
+	Error               string `json:"error"`
 }
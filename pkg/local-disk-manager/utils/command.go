@@ -2,6 +2,8 @@ package utils
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"os/exec"
 	"strings"
 
@@ -98,3 +100,36 @@ func ExecCmd(command, args string) (out []byte, err error) {
 
 	return buf, nil
 }
+
+// secretFlags are the flags followed by a secret, e.g. the ATA security password of hdparm
+var secretFlags = map[string]bool{
+	"--security-set-pass": true,
+	"--security-erase":    true,
+	"--security-disable":  true,
+	"--security-unlock":   true,
+}
+
+// MaskCommandLine returns the command line with the secrets following the secretFlags masked, for logging
+func MaskCommandLine(name string, args ...string) string {
+	masked := make([]string, 0, len(args)+1)
+	masked = append(masked, name)
+	for i, arg := range args {
+		if i > 0 && secretFlags[args[i-1]] {
+			arg = "******"
+		}
+		masked = append(masked, arg)
+	}
+	return strings.Join(masked, " ")
+}
+
+// RunCommand runs the command and returns its combined output. The command line is logged at debug level,
+// and the secrets in it are masked in both the log and the error
+func RunCommand(ctx context.Context, name string, args ...string) (string, error) {
+	cmdLine := MaskCommandLine(name, args...)
+	log.Debug(cmdLine)
+	out, err := exec.CommandContext(ctx, name, args...).CombinedOutput()
+	if err != nil {
+		return string(out), fmt.Errorf("%s: %v, %s", cmdLine, err, strings.TrimSpace(string(out)))
+	}
+	return string(out), nil
+}
@@ -117,6 +117,67 @@ func (s Device) GetPartitions() ([]string, bool) {
 	return partitions, true
 }
 
+// Partition is a partition on the device
+type Partition struct {
+	// Name is the device name of the partition, e.g. sdb1
+	Name string
+
+	// Number is the number of the partition on the device
+	Number int
+
+	// StartBytes is the offset of the partition on the device
+	StartBytes int64
+
+	// SizeBytes is the size of the partition
+	SizeBytes int64
+}
+
+// ListPartitions lists the partitions of this device with the position on the device
+func (s Device) ListPartitions() ([]Partition, error) {
+	names, ok := s.GetPartitions()
+	if !ok {
+		return nil, fmt.Errorf("failed to list partitions of %s", s.deviceName)
+	}
+
+	var partitions []Partition
+	for _, name := range names {
+		partitionPath := s.sysPath + name + "/"
+		number, err := utils.ReadSysFSFileAsInt64(partitionPath + "partition")
+		if err != nil {
+			// not a partition, e.g. the device name is prefix of another device
+			continue
+		}
+		start, err := utils.ReadSysFSFileAsInt64(partitionPath + "start")
+		if err != nil {
+			return nil, err
+		}
+		size, err := utils.ReadSysFSFileAsInt64(partitionPath + "size")
+		if err != nil {
+			return nil, err
+		}
+		partitions = append(partitions, Partition{
+			Name:       name,
+			Number:     int(number),
+			StartBytes: start * sectorSize,
+			SizeBytes:  size * sectorSize,
+		})
+	}
+	return partitions, nil
+}
+
+// GetParentDevPath returns the path of the device which the partition is on, e.g. /dev/sdb for /dev/sdb1
+func GetParentDevPath(partitionDevPath string) (string, error) {
+	device, err := NewSysFsDeviceFromDevPath(partitionDevPath)
+	if err != nil {
+		return "", err
+	}
+	parent, ok := device.getParent()
+	if !ok {
+		return "", fmt.Errorf("%s is not a partition", partitionDevPath)
+	}
+	return "/dev/" + parent, nil
+}
+
 // getHolders gets the devices that are held by this device
 func (s Device) getHolders() ([]string, bool) {
 	holderPath := s.sysPath + "holders/"
@@ -42,6 +42,11 @@ func (q *TaskQueue) AddRateLimited(task string) {
 	q.queue.AddRateLimited(task)
 }
 
+// AddAfter adds a task into the queue after the duration
+func (q *TaskQueue) AddAfter(task string, duration time.Duration) {
+	q.queue.AddAfter(task, duration)
+}
+
 // Get a task from queue. It's a blocking call
 func (q *TaskQueue) Get() (string, bool) {
 	item, shutdown := q.queue.Get()
@@ -68,6 +68,26 @@ type manager struct {
 
 	volumeGroupConvertTaskQueue *common.TaskQueue
 
+	volumeBackupTaskQueue *common.TaskQueue
+
+	volumeBackupRestoreTaskQueue *common.TaskQueue
+
+	volumeSnapshotScheduleTaskQueue *common.TaskQueue
+
+	volumeGroupSnapshotTaskQueue *common.TaskQueue
+
+	volumeGroupSnapshotRestoreTaskQueue *common.TaskQueue
+
+	volumeScrubTaskQueue *common.TaskQueue
+
+	volumeScrubScheduleTaskQueue *common.TaskQueue
+
+	volumeKeyRotateTaskQueue *common.TaskQueue
+
+	volumeEncryptTaskQueue *common.TaskQueue
+
+	volumeShrinkTaskQueue *common.TaskQueue
+
 	localNodes map[string]apisv1alpha1.State // nodeName -> status
 
 	replicaSnapRestoreRecords map[string]map[string]*apisv1alpha1.LocalVolumeReplicaSnapshotRestore // volume snapshot restore -> nodeName
@@ -77,6 +97,8 @@ type manager struct {
 	lock sync.Mutex
 
 	dataCopyManager *datacopyutil.DataCopyManager
+
+	podEvictor podEvictor
 }
 
 // New cluster manager
@@ -105,14 +127,26 @@ func New(name string, namespace string, cli client.Client, scheme *runtime.Schem
 		migrateConcurrentNumber: MigrateConcurrentNumber,
 		volumeConvertTaskQueue:  common.NewTaskQueue("VolumeConvertTask", maxRetries),
 
-		volumeGroupMigrateTaskQueue:    common.NewTaskQueue("VolumeGroupMigrateTask", maxRetries),
-		volumeGroupConvertTaskQueue:    common.NewTaskQueue("VolumeGroupConvertTask", maxRetries),
-		volumeSnapshotTaskQueue:        common.NewTaskQueue("VolumeSnapshotTask", maxRetries),
-		volumeSnapshotRestoreTaskQueue: common.NewTaskQueue("VolumeSnapshotRestoreTask", maxRetries),
-		localNodes:                     map[string]apisv1alpha1.State{},
-		replicaSnapRestoreRecords:      map[string]map[string]*apisv1alpha1.LocalVolumeReplicaSnapshotRestore{},
-		logger:                         log.WithField("Module", "ControllerManager"),
-		dataCopyManager:                dcm,
+		volumeGroupMigrateTaskQueue:     common.NewTaskQueue("VolumeGroupMigrateTask", maxRetries),
+		volumeGroupConvertTaskQueue:     common.NewTaskQueue("VolumeGroupConvertTask", maxRetries),
+		volumeSnapshotTaskQueue:         common.NewTaskQueue("VolumeSnapshotTask", maxRetries),
+		volumeSnapshotRestoreTaskQueue:  common.NewTaskQueue("VolumeSnapshotRestoreTask", maxRetries),
+		volumeBackupTaskQueue:           common.NewTaskQueue("VolumeBackupTask", maxRetries),
+		volumeBackupRestoreTaskQueue:    common.NewTaskQueue("VolumeBackupRestoreTask", maxRetries),
+		volumeSnapshotScheduleTaskQueue: common.NewTaskQueue("VolumeSnapshotScheduleTask", maxRetries),
+		localNodes:                      map[string]apisv1alpha1.State{},
+		replicaSnapRestoreRecords:       map[string]map[string]*apisv1alpha1.LocalVolumeReplicaSnapshotRestore{},
+		logger:                          log.WithField("Module", "ControllerManager"),
+		dataCopyManager:                 dcm,
+		podEvictor:                      &k8sPodEvictor{},
+
+		volumeGroupSnapshotTaskQueue:        common.NewTaskQueue("VolumeGroupSnapshotTask", maxRetries),
+		volumeGroupSnapshotRestoreTaskQueue: common.NewTaskQueue("VolumeGroupSnapshotRestoreTask", maxRetries),
+		volumeScrubTaskQueue:                common.NewTaskQueue("VolumeScrubTask", maxRetries),
+		volumeScrubScheduleTaskQueue:        common.NewTaskQueue("VolumeScrubScheduleTask", maxRetries),
+		volumeKeyRotateTaskQueue:            common.NewTaskQueue("VolumeKeyRotateTask", maxRetries),
+		volumeEncryptTaskQueue:              common.NewTaskQueue("VolumeEncryptTask", maxRetries),
+		volumeShrinkTaskQueue:               common.NewTaskQueue("VolumeShrinkTask", maxRetries),
 	}, nil
 }
 
@@ -141,6 +175,16 @@ func (m *manager) start(stopCh <-chan struct{}) {
 		go m.startVolumeConvertTaskWorker(stopCh)
 		go m.startVolumeSnapshotTaskWorker(stopCh)
 		go m.startVolumeSnapshotRestoreTaskWorker(stopCh)
+		go m.startVolumeBackupTaskWorker(stopCh)
+		go m.startVolumeBackupRestoreTaskWorker(stopCh)
+		go m.startVolumeSnapshotScheduleTaskWorker(stopCh)
+		go m.startVolumeGroupSnapshotTaskWorker(stopCh)
+		go m.startVolumeGroupSnapshotRestoreTaskWorker(stopCh)
+		go m.startVolumeScrubTaskWorker(stopCh)
+		go m.startVolumeScrubScheduleTaskWorker(stopCh)
+		go m.startVolumeKeyRotateTaskWorker(stopCh)
+		go m.startVolumeEncryptTaskWorker(stopCh)
+		go m.startVolumeShrinkTaskWorker(stopCh)
 
 		m.setupInformers()
 
@@ -255,6 +299,116 @@ func (m *manager) setupInformers() {
 		DeleteFunc: m.handleVolumeSnapshotRestoreDeleteEvent,
 	})
 
+	// setup LocalVolumeBackup informer
+	volumeBackupInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalVolumeBackup{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalVolumeBackup")
+	}
+	volumeBackupInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleVolumeBackupAddEvent,
+		UpdateFunc: m.handleVolumeBackupUpdateEvent,
+	})
+
+	// setup LocalVolumeBackupRestore informer
+	volumeBackupRestoreInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalVolumeBackupRestore{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalVolumeBackupRestore")
+	}
+	volumeBackupRestoreInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleVolumeBackupRestoreAddEvent,
+		UpdateFunc: m.handleVolumeBackupRestoreUpdateEvent,
+	})
+
+	// setup LocalVolumeSnapshotSchedule informer
+	volumeSnapshotScheduleInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalVolumeSnapshotSchedule{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalVolumeSnapshotSchedule")
+	}
+	volumeSnapshotScheduleInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleVolumeSnapshotScheduleAddEvent,
+		UpdateFunc: m.handleVolumeSnapshotScheduleUpdateEvent,
+	})
+
+	// setup LocalVolumeGroupSnapshot informer
+	volumeGroupSnapshotInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalVolumeGroupSnapshot{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalVolumeGroupSnapshot")
+	}
+	volumeGroupSnapshotInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleVolumeGroupSnapshotAddEvent,
+		UpdateFunc: m.handleVolumeGroupSnapshotUpdateEvent,
+	})
+
+	// setup LocalVolumeGroupSnapshotRestore informer
+	volumeGroupSnapshotRestoreInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalVolumeGroupSnapshotRestore{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalVolumeGroupSnapshotRestore")
+	}
+	volumeGroupSnapshotRestoreInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleVolumeGroupSnapshotRestoreAddEvent,
+		UpdateFunc: m.handleVolumeGroupSnapshotRestoreUpdateEvent,
+	})
+
+	// setup LocalVolumeScrub informer
+	volumeScrubInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalVolumeScrub{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalVolumeScrub")
+	}
+	volumeScrubInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleVolumeScrubAddEvent,
+		UpdateFunc: m.handleVolumeScrubUpdateEvent,
+	})
+
+	// setup LocalVolumeScrubSchedule informer
+	volumeScrubScheduleInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalVolumeScrubSchedule{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalVolumeScrubSchedule")
+	}
+	volumeScrubScheduleInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleVolumeScrubScheduleAddEvent,
+		UpdateFunc: m.handleVolumeScrubScheduleUpdateEvent,
+	})
+
+	// setup LocalVolumeKeyRotate informer
+	volumeKeyRotateInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalVolumeKeyRotate{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalVolumeKeyRotate")
+	}
+	volumeKeyRotateInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleVolumeKeyRotateAddEvent,
+		UpdateFunc: m.handleVolumeKeyRotateUpdateEvent,
+	})
+
+	// setup LocalVolumeEncrypt informer
+	volumeEncryptInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalVolumeEncrypt{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalVolumeEncrypt")
+	}
+	volumeEncryptInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleVolumeEncryptAddEvent,
+		UpdateFunc: m.handleVolumeEncryptUpdateEvent,
+	})
+
+	// setup LocalVolumeShrink informer
+	volumeShrinkInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalVolumeShrink{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalVolumeShrink")
+	}
+	volumeShrinkInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleVolumeShrinkAddEvent,
+		UpdateFunc: m.handleVolumeShrinkUpdateEvent,
+	})
+
 	// setup pvc informer
 	pvcInformer, err := m.informersCache.GetInformer(context.TODO(), &corev1.PersistentVolumeClaim{})
 	if err != nil {
@@ -279,6 +433,10 @@ func (m *manager) handleVolumeSnapshotAddEvent(newObject interface{}) {
 	volumeSnapshot, ok := newObject.(*apisv1alpha1.LocalVolumeSnapshot)
 	if ok {
 		m.volumeSnapshotTaskQueue.Add(volumeSnapshot.Name)
+		// check the group snapshot when the member snapshot changes
+		if groupSnapshotName, exists := volumeSnapshot.Labels[apisv1alpha1.VolumeGroupSnapshotLabelKey]; exists {
+			m.volumeGroupSnapshotTaskQueue.Add(groupSnapshotName)
+		}
 		return
 	}
 	volumeReplicaSnapshot, ok := newObject.(*apisv1alpha1.LocalVolumeReplicaSnapshot)
@@ -293,6 +451,10 @@ func (m *manager) handleVolumeSnapshotRestoreAddEvent(newObject interface{}) {
 	volumeSnapshotRestore, ok := newObject.(*apisv1alpha1.LocalVolumeSnapshotRestore)
 	if ok {
 		m.volumeSnapshotRestoreTaskQueue.Add(volumeSnapshotRestore.Name)
+		// check the group restore when the member restore changes
+		if groupRestoreName, exists := volumeSnapshotRestore.Labels[apisv1alpha1.VolumeGroupSnapshotRestoreLabelKey]; exists {
+			m.volumeGroupSnapshotRestoreTaskQueue.Add(groupRestoreName)
+		}
 		return
 	}
 	volumeReplicaSnapshotRestore, ok := newObject.(*apisv1alpha1.LocalVolumeReplicaSnapshotRestore)
@@ -303,6 +465,126 @@ func (m *manager) handleVolumeSnapshotRestoreAddEvent(newObject interface{}) {
 
 }
 
+func (m *manager) handleVolumeBackupAddEvent(newObject interface{}) {
+	volumeBackup, ok := newObject.(*apisv1alpha1.LocalVolumeBackup)
+	if !ok {
+		return
+	}
+	m.volumeBackupTaskQueue.Add(volumeBackup.Name)
+}
+
+func (m *manager) handleVolumeBackupUpdateEvent(oldObj, newObj interface{}) {
+	m.handleVolumeBackupAddEvent(newObj)
+}
+
+func (m *manager) handleVolumeBackupRestoreAddEvent(newObject interface{}) {
+	backupRestore, ok := newObject.(*apisv1alpha1.LocalVolumeBackupRestore)
+	if !ok {
+		return
+	}
+	m.volumeBackupRestoreTaskQueue.Add(backupRestore.Name)
+}
+
+func (m *manager) handleVolumeBackupRestoreUpdateEvent(oldObj, newObj interface{}) {
+	m.handleVolumeBackupRestoreAddEvent(newObj)
+}
+
+func (m *manager) handleVolumeSnapshotScheduleAddEvent(newObject interface{}) {
+	schedule, ok := newObject.(*apisv1alpha1.LocalVolumeSnapshotSchedule)
+	if !ok {
+		return
+	}
+	m.volumeSnapshotScheduleTaskQueue.Add(schedule.Name)
+}
+
+func (m *manager) handleVolumeSnapshotScheduleUpdateEvent(oldObj, newObj interface{}) {
+	m.handleVolumeSnapshotScheduleAddEvent(newObj)
+}
+
+func (m *manager) handleVolumeGroupSnapshotAddEvent(newObject interface{}) {
+	groupSnapshot, ok := newObject.(*apisv1alpha1.LocalVolumeGroupSnapshot)
+	if !ok {
+		return
+	}
+	m.volumeGroupSnapshotTaskQueue.Add(groupSnapshot.Name)
+}
+
+func (m *manager) handleVolumeGroupSnapshotUpdateEvent(oldObj, newObj interface{}) {
+	m.handleVolumeGroupSnapshotAddEvent(newObj)
+}
+
+func (m *manager) handleVolumeGroupSnapshotRestoreAddEvent(newObject interface{}) {
+	groupRestore, ok := newObject.(*apisv1alpha1.LocalVolumeGroupSnapshotRestore)
+	if !ok {
+		return
+	}
+	m.volumeGroupSnapshotRestoreTaskQueue.Add(groupRestore.Name)
+}
+
+func (m *manager) handleVolumeGroupSnapshotRestoreUpdateEvent(oldObj, newObj interface{}) {
+	m.handleVolumeGroupSnapshotRestoreAddEvent(newObj)
+}
+
+func (m *manager) handleVolumeScrubAddEvent(newObject interface{}) {
+	volumeScrub, ok := newObject.(*apisv1alpha1.LocalVolumeScrub)
+	if !ok {
+		return
+	}
+	m.volumeScrubTaskQueue.Add(volumeScrub.Name)
+}
+
+func (m *manager) handleVolumeScrubUpdateEvent(oldObj, newObj interface{}) {
+	m.handleVolumeScrubAddEvent(newObj)
+}
+
+func (m *manager) handleVolumeScrubScheduleAddEvent(newObject interface{}) {
+	schedule, ok := newObject.(*apisv1alpha1.LocalVolumeScrubSchedule)
+	if !ok {
+		return
+	}
+	m.volumeScrubScheduleTaskQueue.Add(schedule.Name)
+}
+
+func (m *manager) handleVolumeScrubScheduleUpdateEvent(oldObj, newObj interface{}) {
+	m.handleVolumeScrubScheduleAddEvent(newObj)
+}
+
+func (m *manager) handleVolumeKeyRotateAddEvent(newObject interface{}) {
+	keyRotate, ok := newObject.(*apisv1alpha1.LocalVolumeKeyRotate)
+	if !ok {
+		return
+	}
+	m.volumeKeyRotateTaskQueue.Add(keyRotate.Name)
+}
+
+func (m *manager) handleVolumeKeyRotateUpdateEvent(oldObj, newObj interface{}) {
+	m.handleVolumeKeyRotateAddEvent(newObj)
+}
+
+func (m *manager) handleVolumeEncryptAddEvent(newObject interface{}) {
+	volEncrypt, ok := newObject.(*apisv1alpha1.LocalVolumeEncrypt)
+	if !ok {
+		return
+	}
+	m.volumeEncryptTaskQueue.Add(volEncrypt.Name)
+}
+
+func (m *manager) handleVolumeEncryptUpdateEvent(oldObj, newObj interface{}) {
+	m.handleVolumeEncryptAddEvent(newObj)
+}
+
+func (m *manager) handleVolumeShrinkAddEvent(newObject interface{}) {
+	volShrink, ok := newObject.(*apisv1alpha1.LocalVolumeShrink)
+	if !ok {
+		return
+	}
+	m.volumeShrinkTaskQueue.Add(volShrink.Name)
+}
+
+func (m *manager) handleVolumeShrinkUpdateEvent(oldObj, newObj interface{}) {
+	m.handleVolumeShrinkAddEvent(newObj)
+}
+
 func (m *manager) handleVolumeSnapshotRestoreUpdateEvent(oldObj, newObj interface{}) {
 	m.handleVolumeSnapshotRestoreAddEvent(newObj)
 }
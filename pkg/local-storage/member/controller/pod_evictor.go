@@ -0,0 +1,32 @@
+package controller
+
+import (
+	"context"
+
+	policyv1 "k8s.io/api/policy/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	k8sutils "github.com/hwameistor/hwameistor/pkg/utils/kubernetes"
+)
+
+// podEvictor evicts the pod by the pods/eviction API, so the PodDisruptionBudget is respected
+type podEvictor interface {
+	Evict(namespace, name string) error
+}
+
+type k8sPodEvictor struct{}
+
+func (e *k8sPodEvictor) Evict(namespace, name string) error {
+	config, err := k8sutils.GetConfig()
+	if err != nil {
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	return clientset.CoreV1().Pods(namespace).EvictV1(context.TODO(), &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name},
+	})
+}
@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+// nextCronScheduleTime works out the next time to run the cron schedule, for both the scrub and snapshot schedules.
+// recordedSchedule is the schedule which nextScheduleTime was computed from. It returns the next schedule time to
+// record, which is nextScheduleTime itself if not changed, and whether it's time to run now
+func nextCronScheduleTime(schedule string, recordedSchedule string, nextScheduleTime *metav1.Time, now time.Time) (*metav1.Time, bool, error) {
+	cronSchedule, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return nil, false, fmt.Errorf("invalid schedule %s: %v", schedule, err)
+	}
+
+	// the first run is at the next schedule time rather than at once, and so is the changed schedule,
+	// which must not run at the time computed from the old one
+	if nextScheduleTime == nil || recordedSchedule != schedule {
+		return &metav1.Time{Time: cronSchedule.Next(now)}, false, nil
+	}
+	if now.Before(nextScheduleTime.Time) {
+		return nextScheduleTime, false, nil
+	}
+	// only run once for all the missed schedule times
+	return &metav1.Time{Time: cronSchedule.Next(now)}, true, nil
+}
+
+// scheduledObject is a scrub or snapshot created by a schedule
+type scheduledObject struct {
+	name              string
+	creationTimestamp metav1.Time
+}
+
+// selectScheduledObjectsToPrune returns the names of the objects of a volume which are not kept by any rule
+// of the retention, the latest first. Nothing is pruned if there is no rule
+func selectScheduledObjectsToPrune(objects []scheduledObject, retention apisv1alpha1.SnapshotRetention) []string {
+	if retention.KeepLast == 0 && retention.KeepDaily == 0 && retention.KeepWeekly == 0 {
+		return nil
+	}
+
+	// the latest first
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[j].creationTimestamp.Before(&objects[i].creationTimestamp)
+	})
+
+	kept := map[string]bool{}
+	for i := 0; i < retention.KeepLast && i < len(objects); i++ {
+		kept[objects[i].name] = true
+	}
+	keepLatestOfEachPeriod := func(periods int, periodOf func(t time.Time) string) {
+		seen := map[string]bool{}
+		for _, object := range objects {
+			period := periodOf(object.creationTimestamp.UTC())
+			if seen[period] {
+				continue
+			}
+			if len(seen) >= periods {
+				break
+			}
+			seen[period] = true
+			kept[object.name] = true
+		}
+	}
+	keepLatestOfEachPeriod(retention.KeepDaily, func(t time.Time) string {
+		return t.Format("2006-01-02")
+	})
+	keepLatestOfEachPeriod(retention.KeepWeekly, func(t time.Time) string {
+		year, week := t.ISOWeek()
+		return fmt.Sprintf("%d-%d", year, week)
+	})
+
+	var pruned []string
+	for _, object := range objects {
+		if !kept[object.name] {
+			pruned = append(pruned, object.name)
+		}
+	}
+	return pruned
+}
@@ -0,0 +1,44 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Test_nextCronScheduleTime(t *testing.T) {
+	now := time.Date(2023, 6, 30, 12, 30, 0, 0, time.Local)
+	nextHour := time.Date(2023, 6, 30, 13, 0, 0, 0, time.Local)
+	nextDay := time.Date(2023, 7, 1, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name             string
+		schedule         string
+		recordedSchedule string
+		nextScheduleTime *metav1.Time
+		want             time.Time
+		wantDue          bool
+		wantErr          bool
+	}{
+		{name: "invalid", schedule: "every hour", wantErr: true},
+		{name: "first run", schedule: "@hourly", want: nextHour},
+		{name: "not due", schedule: "@hourly", recordedSchedule: "@hourly", nextScheduleTime: &metav1.Time{Time: nextHour}, want: nextHour},
+		{name: "due", schedule: "@hourly", recordedSchedule: "@hourly", nextScheduleTime: &metav1.Time{Time: now.Add(-time.Hour)}, want: nextHour, wantDue: true},
+		{name: "changed", schedule: "@daily", recordedSchedule: "@hourly", nextScheduleTime: &metav1.Time{Time: now.Add(-time.Minute)}, want: nextDay},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, due, err := nextCronScheduleTime(tt.schedule, tt.recordedSchedule, tt.nextScheduleTime, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("nextCronScheduleTime() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !got.Time.Equal(tt.want) || due != tt.wantDue {
+				t.Errorf("nextCronScheduleTime() = %v, %v, want %v, %v", got, due, tt.want, tt.wantDue)
+			}
+		})
+	}
+}
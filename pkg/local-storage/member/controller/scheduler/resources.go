@@ -273,6 +273,12 @@ func (r *resources) getAssociatedVolumes(vol *apisv1alpha1.LocalVolume) map[stri
 		replica, _ := strconv.Atoi(sc.Parameters[apisv1alpha1.VolumeParameterReplicaNumberKey])
 		lv.Spec.ReplicaNumber = int64(replica)
 		lv.Spec.Thin = utils.IsSupportThinProvisioning(sc.Parameters)
+		lv.Spec.Mirror = utils.IsMirrorEnabled(sc.Parameters)
+		lv.Spec.StorageBackend = sc.Parameters[apisv1alpha1.VolumeParameterStorageBackend]
+		if lv.Spec.VolumeCache, err = utils.GetVolumeCache(sc.Parameters, lv.Spec.RequiredCapacityBytes); err != nil {
+			r.logger.WithError(err).Errorf("get volume cache err")
+			return lvs
+		}
 		lvs[poolName] = append(lvs[poolName], lv)
 		r.logger.Debugf("adding associated LV(capacity: %d) to pool %s, current %d volume(s)", lv.Spec.RequiredCapacityBytes, poolName, len(lvs[poolName]))
 	}
@@ -280,6 +286,31 @@ func (r *resources) getAssociatedVolumes(vol *apisv1alpha1.LocalVolume) map[stri
 	return lvs
 }
 
+// getVolumeConsumedCapacityBytes returns the pool capacity consumed by a replica of the volume,
+// a mirrored volume replica keeps a full copy of the data on each disk
+func getVolumeConsumedCapacityBytes(vol *apisv1alpha1.LocalVolume, capacityBytes int64) int64 {
+	if vol.Spec.Mirror {
+		return capacityBytes * apisv1alpha1.LVMMirrorCopies
+	}
+	return capacityBytes
+}
+
+// isThinPoolExtendFailed returns true if the thin pool in the pool reaches the threshold but can't be extended
+func isThinPoolExtendFailed(node *apisv1alpha1.LocalStorageNode, poolName string) bool {
+	pool, exists := node.Status.Pools[poolName]
+	return exists && pool.ThinPool != nil && len(pool.ThinPool.ExtendFailure) > 0
+}
+
+// getPoolStorageBackend returns the storage backend of the pool on the node, LVM by default
+func (r *resources) getPoolStorageBackend(nodeName string, poolName string) string {
+	if node, exists := r.storageNodes[nodeName]; exists {
+		if pool, exists := node.Status.Pools[poolName]; exists && len(pool.Backend) > 0 {
+			return pool.Backend
+		}
+	}
+	return apisv1alpha1.StorageBackendLVM
+}
+
 func (r *resources) predicate(vol *apisv1alpha1.LocalVolume, nodeName string) error {
 	r.logger.WithFields(log.Fields{"namespace": vol.Spec.PersistentVolumeClaimNamespace, "pvc": vol.Spec.PersistentVolumeClaimName, "node": nodeName}).Debug("Predicting a volume against a node")
 	if _, ok := r.storageNodes[nodeName]; !ok {
@@ -300,9 +331,21 @@ func (r *resources) predicate(vol *apisv1alpha1.LocalVolume, nodeName string) er
 
 		r.logger.Debugf("found %d volume(s) in pool %s", len(lvs), poolName)
 
+		poolBackend := r.getPoolStorageBackend(nodeName, poolName)
 		for _, lv := range lvs {
+			if len(lv.Spec.StorageBackend) > 0 && lv.Spec.StorageBackend != poolBackend {
+				r.logger.WithFields(log.Fields{"pool": poolName, "node": nodeName, "poolBackend": poolBackend, "requiredBackend": lv.Spec.StorageBackend}).Error("Mismatched storage backend")
+				return fmt.Errorf("storage backend of pool %s is %s, not %s", poolName, poolBackend, lv.Spec.StorageBackend)
+			}
+		}
+
+		for _, lv := range lvs {
+			if lv.Spec.Mirror && len(r.storageNodes[nodeName].Status.Pools[poolName].Disks) < apisv1alpha1.LVMMirrorCopies {
+				r.logger.WithFields(log.Fields{"pool": poolName, "node": nodeName}).Error("No enough disks for mirrored volume")
+				return fmt.Errorf("not enough disks in pool %s for mirrored volume", poolName)
+			}
 			if !lv.Spec.Thin {
-				requiredCapacityBytes += lv.Spec.RequiredCapacityBytes
+				requiredCapacityBytes += getVolumeConsumedCapacityBytes(lv, lv.Spec.RequiredCapacityBytes)
 				r.logger.Debugf("adding requiredCapacity %d to pool %s, current requiredCapacity %d", lv.Spec.RequiredCapacityBytes, poolName, requiredCapacityBytes)
 			} else {
 				requiredThinCapacityBytes += lv.Spec.RequiredCapacityBytes
@@ -314,10 +357,21 @@ func (r *resources) predicate(vol *apisv1alpha1.LocalVolume, nodeName string) er
 			}
 		}
 
+		if err := r.predicateVolumeCache(lvs, nodeName, poolName, requiredCapacityBytes); err != nil {
+			return err
+		}
+
 		totalPool := r.totalStorages.pools[poolName]
 		allocatedPool := r.allocatedStorages.pools[poolName]
 
-		if float64(requiredThinCapacityBytes) > float64(totalPool.thinPoolCapacities[nodeName])-float64(allocatedPool.thinPoolCapacities[nodeName]) {
+		// the thin pool reaches the threshold of the autoextend policy, but can't be extended any more
+		if poolBackend != apisv1alpha1.StorageBackendZFS && requiredThinCapacityBytes > 0 && isThinPoolExtendFailed(r.storageNodes[nodeName], poolName) {
+			r.logger.WithFields(log.Fields{"pool": poolName, "node": nodeName}).Error("Thin pool can't be extended")
+			return fmt.Errorf("thin pool in pool %s can't be extended", poolName)
+		}
+
+		// sparse zvol is used for the thin volume on ZFS pool, so there is no thin pool to check
+		if poolBackend != apisv1alpha1.StorageBackendZFS && float64(requiredThinCapacityBytes) > float64(totalPool.thinPoolCapacities[nodeName])-float64(allocatedPool.thinPoolCapacities[nodeName]) {
 			r.logger.WithFields(log.Fields{"pool": poolName,
 				"node":                      nodeName,
 				"requiredThinCapacityBytes": requiredThinCapacityBytes,
@@ -344,6 +398,39 @@ func (r *resources) predicate(vol *apisv1alpha1.LocalVolume, nodeName string) er
 	return nil
 }
 
+// predicateVolumeCache checks the cache pools on the node have enough unallocated capacity for the volume caches.
+// The volumes in the pool poolName require requiredCapacityBytes, which is taken into account if a cache is in the same pool
+func (r *resources) predicateVolumeCache(lvs []*apisv1alpha1.LocalVolume, nodeName string, poolName string, requiredCapacityBytes int64) error {
+	requiredCacheCapacityBytes := map[string]int64{}
+	for _, lv := range lvs {
+		if lv.Spec.VolumeCache != nil {
+			requiredCacheCapacityBytes[lv.Spec.VolumeCache.PoolName] += lv.Spec.VolumeCache.CapacityBytes
+		}
+	}
+
+	for cachePoolName, capacityBytes := range requiredCacheCapacityBytes {
+		if _, exists := r.storageNodes[nodeName].Status.Pools[cachePoolName]; !exists {
+			r.logger.WithFields(log.Fields{"pool": cachePoolName, "node": nodeName}).Error("Cache pool doesn't exist")
+			return fmt.Errorf("cache pool %s not exists", cachePoolName)
+		}
+		if cachePoolName == poolName {
+			capacityBytes += requiredCapacityBytes
+		}
+		totalCapacityBytes := r.totalStorages.pools[cachePoolName].capacities[nodeName]
+		allocatedCapacityBytes := r.allocatedStorages.pools[cachePoolName].capacities[nodeName]
+		// the cache metadata is allocated from the cache pool too, but it's small enough to be ignored here
+		if capacityBytes > totalCapacityBytes-allocatedCapacityBytes {
+			r.logger.WithFields(log.Fields{"pool": cachePoolName,
+				"node":                       nodeName,
+				"requiredCacheCapacityBytes": capacityBytes,
+				"totalPoolCapacityBytes":     totalCapacityBytes,
+				"allocatedCapacityBytes":     allocatedCapacityBytes}).Error("No enough cache capacity")
+			return fmt.Errorf("not enough capacity in cache pool %s", cachePoolName)
+		}
+	}
+	return nil
+}
+
 // Score calculate node socre for this volume
 func (r *resources) Score(vol *apisv1alpha1.LocalVolume, nodeName string) (score int64, err error) {
 	r.lock.Lock()
@@ -374,7 +461,7 @@ func (r *resources) score(vol *apisv1alpha1.LocalVolume, nodeName string) (int64
 			if lv.Spec.Thin {
 				requiredThinCapacityBytes += lv.Spec.RequiredCapacityBytes
 			} else {
-				requiredCapacityBytes += lv.Spec.RequiredCapacityBytes
+				requiredCapacityBytes += getVolumeConsumedCapacityBytes(lv, lv.Spec.RequiredCapacityBytes)
 			}
 		}
 		totalPool := r.totalStorages.pools[poolName]
@@ -482,15 +569,44 @@ func (r *resources) getNodeCandidates(vol *apisv1alpha1.LocalVolume) ([]*apisv1a
 		)
 	}
 
+	var scoredNodes []*apisv1alpha1.LocalStorageNode
 	for pq.Len() > 0 {
 		item := heap.Pop(&pq).(*PriorityItem)
-		candidates = append(candidates, r.storageNodes[item.name])
+		scoredNodes = append(scoredNodes, r.storageNodes[item.name])
 		r.logger.WithFields(log.Fields{"node": item.name, "total": pq.Len()}).Debug("Adding a candidate")
 	}
 
+	// step 4. spread the replicas across the zones where possible
+	usedZones := map[string]int{}
+	for nn := range excludedNodes {
+		if node, exists := r.storageNodes[nn]; exists {
+			usedZones[node.Spec.Topo.Zone]++
+		}
+	}
+	candidates = append(candidates, spreadNodesAcrossZones(scoredNodes, usedZones)...)
+
 	return candidates, nil
 }
 
+// spreadNodesAcrossZones reorders the sorted nodes to put the nodes of the least used zones first,
+// and keeps the original order of the nodes in the same zone
+func spreadNodesAcrossZones(nodes []*apisv1alpha1.LocalStorageNode, usedZones map[string]int) []*apisv1alpha1.LocalStorageNode {
+	remaining := append([]*apisv1alpha1.LocalStorageNode{}, nodes...)
+	spread := make([]*apisv1alpha1.LocalStorageNode, 0, len(nodes))
+	for len(remaining) > 0 {
+		selected := 0
+		for i, node := range remaining {
+			if usedZones[node.Spec.Topo.Zone] < usedZones[remaining[selected].Spec.Topo.Zone] {
+				selected = i
+			}
+		}
+		usedZones[remaining[selected].Spec.Topo.Zone]++
+		spread = append(spread, remaining[selected])
+		remaining = append(remaining[:selected], remaining[selected+1:]...)
+	}
+	return spread
+}
+
 func (r *resources) getResourceIDForVolume(vol *apisv1alpha1.LocalVolume) (int, error) {
 	if vol.Spec.ReplicaNumber <= 2 && !vol.Spec.Convertible {
 		// try to recycle the resource ID in case of this volume is HA before
@@ -596,11 +712,18 @@ func (r *resources) addAllocatedStorage(vol *apisv1alpha1.LocalVolume) {
 		if vol.Spec.Thin {
 			r.allocatedStorages.pools[vol.Spec.PoolName].thinPoolCapacities[replica.Hostname] += vol.Spec.Config.RequiredCapacityBytes
 		} else {
-			r.allocatedStorages.pools[vol.Spec.PoolName].capacities[replica.Hostname] += vol.Spec.Config.RequiredCapacityBytes
+			r.allocatedStorages.pools[vol.Spec.PoolName].capacities[replica.Hostname] += getVolumeConsumedCapacityBytes(vol, vol.Spec.Config.RequiredCapacityBytes)
 		}
 
 		// for volume count
 		r.allocatedStorages.pools[vol.Spec.PoolName].volumeCount[replica.Hostname]++
+
+		// for volume cache, which is allocated from the cache pool on the same node
+		if vol.Spec.VolumeCache != nil {
+			if cachePool, exists := r.allocatedStorages.pools[vol.Spec.VolumeCache.PoolName]; exists {
+				cachePool.capacities[replica.Hostname] += vol.Spec.VolumeCache.CapacityBytes
+			}
+		}
 	}
 }
 
@@ -631,11 +754,18 @@ func (r *resources) recycleAllocatedStorage(vol *apisv1alpha1.LocalVolume) {
 		if vol.Spec.Thin {
 			r.allocatedStorages.pools[vol.Spec.PoolName].thinPoolCapacities[replica.Hostname] -= vol.Spec.Config.RequiredCapacityBytes
 		} else {
-			r.allocatedStorages.pools[vol.Spec.PoolName].capacities[replica.Hostname] -= vol.Spec.Config.RequiredCapacityBytes
+			r.allocatedStorages.pools[vol.Spec.PoolName].capacities[replica.Hostname] -= getVolumeConsumedCapacityBytes(vol, vol.Spec.Config.RequiredCapacityBytes)
 		}
 
 		// for volume count
 		r.allocatedStorages.pools[vol.Spec.PoolName].volumeCount[replica.Hostname]--
+
+		// for volume cache, which is allocated from the cache pool on the same node
+		if vol.Spec.VolumeCache != nil {
+			if cachePool, exists := r.allocatedStorages.pools[vol.Spec.VolumeCache.PoolName]; exists {
+				cachePool.capacities[replica.Hostname] -= vol.Spec.VolumeCache.CapacityBytes
+			}
+		}
 	}
 
 }
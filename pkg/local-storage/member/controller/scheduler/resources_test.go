@@ -595,6 +595,53 @@ func Test_resources_handleVolumeUpdate(t *testing.T) {
 	}
 }
 
+func Test_resources_handleVolumeUpdate_recycleResourceID(t *testing.T) {
+	newVolume := func(convertible bool, replicas int) *v1alpha1.LocalVolume {
+		vol := &v1alpha1.LocalVolume{}
+		vol.Name = fakeLocalVolumeName
+		vol.Spec.Config = &v1alpha1.VolumeConfig{Convertible: convertible}
+		for i := 1; i <= replicas; i++ {
+			vol.Spec.Config.Replicas = append(vol.Spec.Config.Replicas, v1alpha1.VolumeReplica{ID: i})
+		}
+		return vol
+	}
+
+	tests := []struct {
+		name     string
+		vol      *v1alpha1.LocalVolume
+		wantFree []int
+	}{
+		{
+			name:     "HA volume with 2 replicas",
+			vol:      newVolume(true, 2),
+			wantFree: []int{},
+		},
+		{
+			name:     "HA volume reduced to 1 replica, still convertible",
+			vol:      newVolume(true, 1),
+			wantFree: []int{},
+		},
+		{
+			name:     "HA volume reduced to 1 replica, DRBD removed",
+			vol:      newVolume(false, 1),
+			wantFree: []int{5},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &resources{
+				allocatedResourceIDs: map[string]int{fakeLocalVolumeName: 5},
+				freeResourceIDList:   []int{},
+				logger:               log.WithField("Module", "Scheduler"),
+			}
+			r.handleVolumeUpdate(nil, tt.vol)
+			if !reflect.DeepEqual(r.freeResourceIDList, tt.wantFree) {
+				t.Errorf("handleVolumeUpdate() freeResourceIDList = %v, want %v", r.freeResourceIDList, tt.wantFree)
+			}
+		})
+	}
+}
+
 func Test_resources_initilizeResources(t *testing.T) {
 	type fields struct {
 		apiClient            client.Client
@@ -1052,6 +1099,28 @@ func TestResources_ThinProvisioning(t *testing.T) {
 		if err == nil {
 			t.Error("Expected predicate to fail for insufficient thin pool capacity")
 		}
+
+		// Test with the thin pool which can't be extended
+		vol.Spec.RequiredCapacityBytes = 512 * 1024 * 1024
+		node.Status.Pools[v1alpha1.PoolNameForHDD].ThinPool.ExtendFailure = "unable to extend thin pool"
+		err = r.predicate(vol, "test-node")
+		if err == nil {
+			t.Error("Expected predicate to fail for thin pool extend failure")
+		}
+
+		// Test with the thin pool of another pool which can't be extended
+		node.Status.Pools[v1alpha1.PoolNameForHDD].ThinPool.ExtendFailure = ""
+		node.Status.Pools[v1alpha1.PoolNameForSSD] = v1alpha1.LocalPool{
+			Name:     v1alpha1.PoolNameForSSD,
+			ThinPool: &v1alpha1.ThinPoolInfo{ExtendFailure: "unable to extend thin pool"},
+		}
+		node.Status.Conditions = []v1alpha1.StorageNodeCondition{
+			{Type: v1alpha1.StorageThinPoolExtendFailure, Status: v1alpha1.ConditionTrue},
+		}
+		err = r.predicate(vol, "test-node")
+		if err != nil {
+			t.Errorf("Predicate failed for thin volume: %v", err)
+		}
 	})
 
 	// Test thin volume scoring
@@ -1096,3 +1165,125 @@ func TestResources_ThinProvisioning(t *testing.T) {
 		}
 	})
 }
+
+func TestResources_VolumeCache(t *testing.T) {
+	client, _ := CreateFakeClient()
+	r := newResources(10, client)
+
+	node := &v1alpha1.LocalStorageNode{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-node"},
+		Status: v1alpha1.LocalStorageNodeStatus{
+			Pools: map[string]v1alpha1.LocalPool{
+				v1alpha1.PoolNameForHDD: {
+					Name:               v1alpha1.PoolNameForHDD,
+					FreeCapacityBytes:  10 * 1024 * 1024 * 1024,
+					TotalCapacityBytes: 10 * 1024 * 1024 * 1024, // 10GB
+					TotalVolumeCount:   1000,
+				},
+				v1alpha1.PoolNameForSSD: {
+					Name:               v1alpha1.PoolNameForSSD,
+					FreeCapacityBytes:  2 * 1024 * 1024 * 1024,
+					TotalCapacityBytes: 2 * 1024 * 1024 * 1024, // 2GB
+					TotalVolumeCount:   1000,
+				},
+			},
+		},
+	}
+	r.storageNodes["test-node"] = node
+	r.addTotalStorage(node)
+
+	newCachedVolume := func(name string, cacheCapacityBytes int64) *v1alpha1.LocalVolume {
+		vol := &v1alpha1.LocalVolume{
+			Spec: v1alpha1.LocalVolumeSpec{
+				RequiredCapacityBytes: 1024 * 1024 * 1024,
+				PoolName:              v1alpha1.PoolNameForHDD,
+				VolumeCache:           &v1alpha1.VolumeCache{PoolName: v1alpha1.PoolNameForSSD, CapacityBytes: cacheCapacityBytes},
+				Config: &v1alpha1.VolumeConfig{
+					RequiredCapacityBytes: 1024 * 1024 * 1024,
+					Replicas:              []v1alpha1.VolumeReplica{{Hostname: "test-node"}},
+				},
+			},
+		}
+		vol.Name = name
+		return vol
+	}
+
+	// the cache fits in the free capacity of the cache pool
+	vol := newCachedVolume("vol1", 1536*1024*1024)
+	if err := r.predicate(vol, "test-node"); err != nil {
+		t.Errorf("Predicate failed for cached volume: %v", err)
+	}
+	r.addAllocatedStorage(vol)
+
+	// the free capacity reported by the node is not updated yet, but the allocated cache must be counted in
+	another := newCachedVolume("vol2", 1024*1024*1024)
+	if err := r.predicate(another, "test-node"); err == nil {
+		t.Error("Expected predicate to fail for insufficient cache pool capacity")
+	}
+
+	r.recycleAllocatedStorage(vol)
+	if err := r.predicate(another, "test-node"); err != nil {
+		t.Errorf("Predicate failed for cached volume: %v", err)
+	}
+
+	// the cache and the volume are allocated from the same pool
+	another.Spec.VolumeCache.PoolName = v1alpha1.PoolNameForHDD
+	another.Spec.VolumeCache.CapacityBytes = 9*1024*1024*1024 + 1
+	if err := r.predicate(another, "test-node"); err == nil {
+		t.Error("Expected predicate to fail for insufficient capacity of the volume and the cache")
+	}
+}
+
+func Test_spreadNodesAcrossZones(t *testing.T) {
+	newNode := func(name, zone string) *v1alpha1.LocalStorageNode {
+		node := &v1alpha1.LocalStorageNode{}
+		node.Name = name
+		node.Spec.Topo.Zone = zone
+		return node
+	}
+	// sorted by the score
+	nodes := []*v1alpha1.LocalStorageNode{
+		newNode("node1", "zone-a"),
+		newNode("node2", "zone-a"),
+		newNode("node3", "zone-b"),
+		newNode("node4", "zone-c"),
+		newNode("node5", "zone-b"),
+	}
+
+	tests := []struct {
+		name      string
+		nodes     []*v1alpha1.LocalStorageNode
+		usedZones map[string]int
+		want      []string
+	}{
+		{
+			name:      "no replica allocated",
+			nodes:     nodes,
+			usedZones: map[string]int{},
+			want:      []string{"node1", "node3", "node4", "node2", "node5"},
+		},
+		{
+			name:      "replica allocated in zone-b",
+			nodes:     nodes,
+			usedZones: map[string]int{"zone-b": 1},
+			want:      []string{"node1", "node4", "node2", "node3", "node5"},
+		},
+		{
+			name:      "all in the same zone",
+			nodes:     []*v1alpha1.LocalStorageNode{newNode("node1", "default"), newNode("node2", "default"), newNode("node3", "default")},
+			usedZones: map[string]int{"default": 1},
+			want:      []string{"node1", "node2", "node3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, node := range spreadNodesAcrossZones(tt.nodes, tt.usedZones) {
+				got = append(got, node.Name)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("spreadNodesAcrossZones() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,435 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/utils"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/utils/backup"
+)
+
+func (m *manager) startVolumeBackupTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("Volume Backup Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.volumeBackupTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the Volume Backup worker")
+				break
+			}
+			if err := m.processVolumeBackup(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.volumeBackupTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process Volume Backup task, retry later")
+				m.volumeBackupTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a Volume Backup task.")
+				m.volumeBackupTaskQueue.Forget(task)
+			}
+			m.volumeBackupTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.volumeBackupTaskQueue.Shutdown()
+}
+
+func (m *manager) processVolumeBackup(backupName string) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeBackup": backupName})
+	logCtx.Debug("Working on a VolumeBackup task")
+	volumeBackup := &apisv1alpha1.LocalVolumeBackup{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: backupName}, volumeBackup); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get VolumeBackup from cache")
+			return err
+		}
+		logCtx.Info("Not found the VolumeBackup from cache, should be deleted already")
+		return nil
+	}
+
+	if volumeBackup.DeletionTimestamp != nil {
+		return m.volumeBackupCleanup(volumeBackup)
+	}
+
+	if volumeBackup.Spec.Abort &&
+		volumeBackup.Status.State != apisv1alpha1.OperationStateToBeAborted &&
+		volumeBackup.Status.State != apisv1alpha1.OperationStateAborted &&
+		volumeBackup.Status.State != apisv1alpha1.OperationStateCompleted &&
+		volumeBackup.Status.State != apisv1alpha1.OperationStateFailed {
+
+		volumeBackup.Status.State = apisv1alpha1.OperationStateToBeAborted
+		return m.apiClient.Status().Update(context.TODO(), volumeBackup)
+	}
+
+	logCtx = m.logger.WithFields(log.Fields{"VolumeBackup": volumeBackup.Name, "Spec": volumeBackup.Spec, "Status": volumeBackup.Status})
+	logCtx.Debug("Starting to process a VolumeBackup")
+
+	// state chain: (empty) -> Submitted -> InProgress -> Completed/Failed
+	// the data is uploaded by the node where the source snapshot is located at when InProgress
+	switch volumeBackup.Status.State {
+	case "":
+		return m.volumeBackupSubmit(volumeBackup)
+	case apisv1alpha1.OperationStateSubmitted:
+		return m.volumeBackupStart(volumeBackup)
+	case apisv1alpha1.OperationStateToBeAborted:
+		return m.volumeBackupAbort(volumeBackup)
+	case apisv1alpha1.OperationStateInProgress, apisv1alpha1.OperationStateCompleted,
+		apisv1alpha1.OperationStateFailed, apisv1alpha1.OperationStateAborted:
+		return nil
+	default:
+		logCtx.Error("Invalid state/phase")
+	}
+	return fmt.Errorf("invalid state")
+}
+
+func (m *manager) volumeBackupSubmit(volumeBackup *apisv1alpha1.LocalVolumeBackup) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeBackup": volumeBackup.Name, "Spec": volumeBackup.Spec})
+	logCtx.Debug("Submit a VolumeBackup")
+
+	volumeSnapshot := &apisv1alpha1.LocalVolumeSnapshot{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeBackup.Spec.SourceVolumeSnapshot}, volumeSnapshot); err != nil {
+		logCtx.WithError(err).Error("Failed to get source VolumeSnapshot")
+		return err
+	}
+	if volumeSnapshot.Status.State != apisv1alpha1.VolumeStateReady || len(volumeSnapshot.Status.ReplicaSnapshots) == 0 {
+		err := fmt.Errorf("source VolumeSnapshot %s is not ready", volumeSnapshot.Name)
+		logCtx.WithError(err).Error("Failed to submit VolumeBackup")
+		return err
+	}
+
+	// clean up the data in the target when the VolumeBackup is deleted
+	if !hasVolumeBackupCleanupFinalizer(volumeBackup) {
+		volumeBackup.SetFinalizers(utils.AddUniqueStringItem(volumeBackup.Finalizers, apisv1alpha1.VolumeBackupCleanupFinalizer))
+		if err := m.apiClient.Update(context.TODO(), volumeBackup); err != nil {
+			logCtx.WithError(err).Error("Failed to add finalizer to VolumeBackup")
+			return err
+		}
+	}
+
+	volumeBackup.Status.SourceVolume = volumeSnapshot.Spec.SourceVolume
+	volumeBackup.Status.State = apisv1alpha1.OperationStateSubmitted
+	return m.apiClient.Status().Update(context.TODO(), volumeBackup)
+}
+
+func (m *manager) volumeBackupStart(volumeBackup *apisv1alpha1.LocalVolumeBackup) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeBackup": volumeBackup.Name, "Spec": volumeBackup.Spec})
+	logCtx.Debug("Start a VolumeBackup")
+
+	volumeSnapshot := &apisv1alpha1.LocalVolumeSnapshot{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeBackup.Spec.SourceVolumeSnapshot}, volumeSnapshot); err != nil {
+		logCtx.WithError(err).Error("Failed to get source VolumeSnapshot")
+		return err
+	}
+	if len(volumeSnapshot.Status.ReplicaSnapshots) == 0 {
+		return fmt.Errorf("no replica snapshot found for VolumeSnapshot %s", volumeSnapshot.Name)
+	}
+
+	// all the replica snapshots have the same data, back up from the first one
+	replicaSnapshot := &apisv1alpha1.LocalVolumeReplicaSnapshot{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeSnapshot.Status.ReplicaSnapshots[0]}, replicaSnapshot); err != nil {
+		logCtx.WithError(err).Error("Failed to get VolumeReplicaSnapshot")
+		return err
+	}
+
+	sourceVolume := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeSnapshot.Spec.SourceVolume}, sourceVolume); err != nil {
+		logCtx.WithError(err).Error("Failed to get source volume")
+		return err
+	}
+
+	parentBackup := ""
+	if !volumeBackup.Spec.Full {
+		latestBackup, err := m.getLatestCompletedVolumeBackup(sourceVolume.Name, volumeBackup.Spec.Target)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to get the latest completed VolumeBackup")
+			return err
+		}
+		if latestBackup != nil {
+			parentBackup = latestBackup.Name
+		}
+	}
+
+	volumeBackup.Status.SourceVolume = sourceVolume.Name
+	volumeBackup.Status.PoolName = replicaSnapshot.Spec.PoolName
+	volumeBackup.Status.NodeName = replicaSnapshot.Spec.NodeName
+	volumeBackup.Status.VolumeCapacityBytes = sourceVolume.Spec.RequiredCapacityBytes
+	volumeBackup.Status.ParentBackup = parentBackup
+	volumeBackup.Status.State = apisv1alpha1.OperationStateInProgress
+	return m.apiClient.Status().Update(context.TODO(), volumeBackup)
+}
+
+// getLatestCompletedVolumeBackup returns the latest completed backup of the volume in the same target, nil if not found
+func (m *manager) getLatestCompletedVolumeBackup(volumeName string, target apisv1alpha1.BackupTarget) (*apisv1alpha1.LocalVolumeBackup, error) {
+	volumeBackupList := &apisv1alpha1.LocalVolumeBackupList{}
+	if err := m.apiClient.List(context.TODO(), volumeBackupList); err != nil {
+		return nil, err
+	}
+
+	var latestBackup *apisv1alpha1.LocalVolumeBackup
+	for i, volumeBackup := range volumeBackupList.Items {
+		if volumeBackup.Status.SourceVolume != volumeName || volumeBackup.DeletionTimestamp != nil ||
+			volumeBackup.Status.State != apisv1alpha1.OperationStateCompleted ||
+			volumeBackup.Status.CompletionTime == nil ||
+			!backup.SameTarget(volumeBackup.Spec.Target, target) {
+			continue
+		}
+		if latestBackup == nil || latestBackup.Status.CompletionTime.Before(volumeBackup.Status.CompletionTime) {
+			latestBackup = &volumeBackupList.Items[i]
+		}
+	}
+	return latestBackup, nil
+}
+
+func (m *manager) volumeBackupAbort(volumeBackup *apisv1alpha1.LocalVolumeBackup) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeBackup": volumeBackup.Name, "Spec": volumeBackup.Spec})
+	logCtx.Debug("Abort a VolumeBackup")
+
+	volumeBackup.Status.State = apisv1alpha1.OperationStateAborted
+	return m.apiClient.Status().Update(context.TODO(), volumeBackup)
+}
+
+// volumeBackupCleanup deletes the manifest and the unshared chunks of a completed backup from the target,
+// and then releases the VolumeBackup. The node stops uploading once the VolumeBackup is deleted, the chunks
+// uploaded by an incomplete backup are left in the target, as they may be shared by other backups
+func (m *manager) volumeBackupCleanup(volumeBackup *apisv1alpha1.LocalVolumeBackup) error {
+	if !hasVolumeBackupCleanupFinalizer(volumeBackup) {
+		return nil
+	}
+	logCtx := m.logger.WithFields(log.Fields{"VolumeBackup": volumeBackup.Name, "Spec": volumeBackup.Spec})
+	logCtx.Debug("Clean up a VolumeBackup")
+
+	if volumeBackup.Status.State == apisv1alpha1.OperationStateCompleted {
+		// the chunks of the backup may be referenced by the running backups of the same target before their
+		// manifests are uploaded, and a running restore still reads the chunks. So wait for them to complete
+		if err := m.checkVolumeBackupInUse(volumeBackup); err != nil {
+			logCtx.WithError(err).Info("VolumeBackup is still in use, clean up it later")
+			return err
+		}
+
+		backupManager, err := backup.NewManagerForTarget(m.apiClient, volumeBackup.Spec.Target)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to connect to backup target")
+			return err
+		}
+		if _, err = backupManager.Delete(context.TODO(), volumeBackup.Name); err != nil {
+			logCtx.WithError(err).Error("Failed to delete the data of VolumeBackup from backup target")
+			return err
+		}
+	}
+
+	volumeBackup.SetFinalizers(utils.RemoveStringItem(volumeBackup.Finalizers, apisv1alpha1.VolumeBackupCleanupFinalizer))
+	return m.apiClient.Update(context.TODO(), volumeBackup)
+}
+
+func hasVolumeBackupCleanupFinalizer(volumeBackup *apisv1alpha1.LocalVolumeBackup) bool {
+	for _, finalizer := range volumeBackup.Finalizers {
+		if finalizer == apisv1alpha1.VolumeBackupCleanupFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// checkVolumeBackupInUse returns an error if there is any running backup of the same target,
+// or any running restore from the backup
+func (m *manager) checkVolumeBackupInUse(volumeBackup *apisv1alpha1.LocalVolumeBackup) error {
+	volumeBackupList := &apisv1alpha1.LocalVolumeBackupList{}
+	if err := m.apiClient.List(context.TODO(), volumeBackupList); err != nil {
+		return err
+	}
+	for _, other := range volumeBackupList.Items {
+		if other.Name != volumeBackup.Name && other.Status.State == apisv1alpha1.OperationStateInProgress &&
+			backup.SameTarget(other.Spec.Target, volumeBackup.Spec.Target) {
+			return fmt.Errorf("VolumeBackup %s of the same target is in progress", other.Name)
+		}
+	}
+
+	backupRestoreList := &apisv1alpha1.LocalVolumeBackupRestoreList{}
+	if err := m.apiClient.List(context.TODO(), backupRestoreList); err != nil {
+		return err
+	}
+	for _, backupRestore := range backupRestoreList.Items {
+		if backupRestore.Spec.SourceBackup == volumeBackup.Name &&
+			(backupRestore.Status.State == apisv1alpha1.OperationStateSubmitted || backupRestore.Status.State == apisv1alpha1.OperationStateInProgress) {
+			return fmt.Errorf("VolumeBackupRestore %s from the backup is in progress", backupRestore.Name)
+		}
+	}
+	return nil
+}
+
+func (m *manager) startVolumeBackupRestoreTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("Volume Backup Restore Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.volumeBackupRestoreTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the Volume Backup Restore worker")
+				break
+			}
+			if err := m.processVolumeBackupRestore(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.volumeBackupRestoreTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process Volume Backup Restore task, retry later")
+				m.volumeBackupRestoreTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a Volume Backup Restore task.")
+				m.volumeBackupRestoreTaskQueue.Forget(task)
+			}
+			m.volumeBackupRestoreTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.volumeBackupRestoreTaskQueue.Shutdown()
+}
+
+func (m *manager) processVolumeBackupRestore(restoreName string) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeBackupRestore": restoreName})
+	logCtx.Debug("Working on a VolumeBackupRestore task")
+	backupRestore := &apisv1alpha1.LocalVolumeBackupRestore{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: restoreName}, backupRestore); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get VolumeBackupRestore from cache")
+			return err
+		}
+		logCtx.Info("Not found the VolumeBackupRestore from cache, should be deleted already")
+		return nil
+	}
+
+	if backupRestore.Spec.Abort &&
+		backupRestore.Status.State != apisv1alpha1.OperationStateToBeAborted &&
+		backupRestore.Status.State != apisv1alpha1.OperationStateAborted &&
+		backupRestore.Status.State != apisv1alpha1.OperationStateCompleted &&
+		backupRestore.Status.State != apisv1alpha1.OperationStateFailed {
+
+		backupRestore.Status.State = apisv1alpha1.OperationStateToBeAborted
+		return m.apiClient.Status().Update(context.TODO(), backupRestore)
+	}
+
+	logCtx = m.logger.WithFields(log.Fields{"VolumeBackupRestore": backupRestore.Name, "Spec": backupRestore.Spec, "Status": backupRestore.Status})
+	logCtx.Debug("Starting to process a VolumeBackupRestore")
+
+	// state chain: (empty) -> Submitted -> InProgress -> Completed/Failed
+	// the data is downloaded by the node where the target volume is located at when InProgress
+	switch backupRestore.Status.State {
+	case "":
+		return m.volumeBackupRestoreSubmit(backupRestore)
+	case apisv1alpha1.OperationStateSubmitted:
+		return m.volumeBackupRestoreStart(backupRestore)
+	case apisv1alpha1.OperationStateInProgress:
+		return m.checkInProgressVolumeBackupRestore(backupRestore)
+	case apisv1alpha1.OperationStateToBeAborted:
+		return m.volumeBackupRestoreAbort(backupRestore)
+	case apisv1alpha1.OperationStateCompleted, apisv1alpha1.OperationStateFailed, apisv1alpha1.OperationStateAborted:
+		return nil
+	default:
+		logCtx.Error("Invalid state/phase")
+	}
+	return fmt.Errorf("invalid state")
+}
+
+func (m *manager) volumeBackupRestoreSubmit(backupRestore *apisv1alpha1.LocalVolumeBackupRestore) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeBackupRestore": backupRestore.Name, "Spec": backupRestore.Spec})
+	logCtx.Debug("Submit a VolumeBackupRestore")
+
+	volumeBackup := &apisv1alpha1.LocalVolumeBackup{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: backupRestore.Spec.SourceBackup}, volumeBackup); err != nil {
+		logCtx.WithError(err).Error("Failed to get source VolumeBackup")
+		return err
+	}
+	if volumeBackup.Status.State != apisv1alpha1.OperationStateCompleted {
+		err := fmt.Errorf("source VolumeBackup %s is not completed", volumeBackup.Name)
+		logCtx.WithError(err).Error("Failed to submit VolumeBackupRestore")
+		return err
+	}
+
+	// the target volume must be a new one, to avoid overwriting the data in use
+	targetVolume := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: backupRestore.Spec.TargetVolume}, targetVolume); err == nil {
+		backupRestore.Status.State = apisv1alpha1.OperationStateFailed
+		backupRestore.Status.Message = fmt.Sprintf("target volume %s already exists", targetVolume.Name)
+		return m.apiClient.Status().Update(context.TODO(), backupRestore)
+	} else if !errors.IsNotFound(err) {
+		logCtx.WithError(err).Error("Failed to get target volume")
+		return err
+	}
+
+	backupRestore.Status.State = apisv1alpha1.OperationStateSubmitted
+	return m.apiClient.Status().Update(context.TODO(), backupRestore)
+}
+
+func (m *manager) volumeBackupRestoreStart(backupRestore *apisv1alpha1.LocalVolumeBackupRestore) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeBackupRestore": backupRestore.Name, "Spec": backupRestore.Spec})
+	logCtx.Debug("Start a VolumeBackupRestore")
+
+	volumeBackup := &apisv1alpha1.LocalVolumeBackup{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: backupRestore.Spec.SourceBackup}, volumeBackup); err != nil {
+		logCtx.WithError(err).Error("Failed to get source VolumeBackup")
+		return err
+	}
+
+	poolName := backupRestore.Spec.TargetPoolName
+	if len(poolName) == 0 {
+		poolName = volumeBackup.Status.PoolName
+	}
+	targetVolume := &apisv1alpha1.LocalVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        backupRestore.Spec.TargetVolume,
+			Annotations: map[string]string{apisv1alpha1.SourceVolumeBackupAnnoKey: volumeBackup.Name},
+		},
+		Spec: apisv1alpha1.LocalVolumeSpec{
+			RequiredCapacityBytes: volumeBackup.Status.VolumeCapacityBytes,
+			PoolName:              poolName,
+			ReplicaNumber:         1,
+		},
+	}
+	if len(backupRestore.Spec.TargetNodeName) > 0 {
+		targetVolume.Spec.Accessibility.Nodes = []string{backupRestore.Spec.TargetNodeName}
+	}
+	if err := m.apiClient.Create(context.TODO(), targetVolume); err != nil && !errors.IsAlreadyExists(err) {
+		logCtx.WithError(err).Error("Failed to create target volume")
+		return err
+	}
+
+	backupRestore.Status.State = apisv1alpha1.OperationStateInProgress
+	return m.apiClient.Status().Update(context.TODO(), backupRestore)
+}
+
+func (m *manager) checkInProgressVolumeBackupRestore(backupRestore *apisv1alpha1.LocalVolumeBackupRestore) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeBackupRestore": backupRestore.Name, "Spec": backupRestore.Spec})
+	logCtx.Debug("Check a InProgress VolumeBackupRestore")
+
+	// the node is already assigned, wait for it to complete the restore
+	if len(backupRestore.Status.NodeName) > 0 {
+		return nil
+	}
+
+	targetVolume := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: backupRestore.Spec.TargetVolume}, targetVolume); err != nil {
+		logCtx.WithError(err).Error("Failed to get target volume")
+		return err
+	}
+	if targetVolume.Status.State != apisv1alpha1.VolumeStateReady || len(targetVolume.Status.Replicas) == 0 {
+		return fmt.Errorf("target volume %s is not ready", targetVolume.Name)
+	}
+
+	replica := &apisv1alpha1.LocalVolumeReplica{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: targetVolume.Status.Replicas[0]}, replica); err != nil {
+		logCtx.WithError(err).Error("Failed to get replica of target volume")
+		return err
+	}
+
+	backupRestore.Status.NodeName = replica.Spec.NodeName
+	return m.apiClient.Status().Update(context.TODO(), backupRestore)
+}
+
+func (m *manager) volumeBackupRestoreAbort(backupRestore *apisv1alpha1.LocalVolumeBackupRestore) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeBackupRestore": backupRestore.Name, "Spec": backupRestore.Spec})
+	logCtx.Debug("Abort a VolumeBackupRestore")
+
+	backupRestore.Status.State = apisv1alpha1.OperationStateAborted
+	return m.apiClient.Status().Update(context.TODO(), backupRestore)
+}
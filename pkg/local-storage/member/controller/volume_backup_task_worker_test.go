@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+func newFakeBackupManager(t *testing.T, objs ...client.Object) *manager {
+	s := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(s); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	return &manager{
+		apiClient: fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build(),
+		logger:    log.WithField("Module", "ControllerManager"),
+	}
+}
+
+func genFakeVolumeBackup(name string, volume string, target v1alpha1.BackupTarget, completionTime time.Time) *v1alpha1.LocalVolumeBackup {
+	volumeBackup := &v1alpha1.LocalVolumeBackup{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	volumeBackup.Spec.Target = target
+	volumeBackup.Status.SourceVolume = volume
+	volumeBackup.Status.State = v1alpha1.OperationStateCompleted
+	volumeBackup.Status.CompletionTime = &metav1.Time{Time: completionTime}
+	volumeBackup.Status.PoolName = v1alpha1.PoolNameForHDD
+	volumeBackup.Status.VolumeCapacityBytes = 1073741824
+	return volumeBackup
+}
+
+func Test_manager_processVolumeBackup(t *testing.T) {
+	target := v1alpha1.BackupTarget{Endpoint: "http://minio:9000", Bucket: "backups", CredentialSecret: "hwameistor/s3"}
+	otherTarget := v1alpha1.BackupTarget{Endpoint: "http://minio:9000", Bucket: "others", CredentialSecret: "hwameistor/s3"}
+	now := time.Now()
+
+	volume := &v1alpha1.LocalVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"}}
+	volume.Spec.RequiredCapacityBytes = 1073741824
+	volumeSnapshot := &v1alpha1.LocalVolumeSnapshot{ObjectMeta: metav1.ObjectMeta{Name: "snapshot-3"}}
+	volumeSnapshot.Spec.SourceVolume = volume.Name
+	volumeSnapshot.Status.State = v1alpha1.VolumeStateReady
+	volumeSnapshot.Status.ReplicaSnapshots = []string{"snapshot-3-replica"}
+	replicaSnapshot := &v1alpha1.LocalVolumeReplicaSnapshot{ObjectMeta: metav1.ObjectMeta{Name: "snapshot-3-replica"}}
+	replicaSnapshot.Spec.NodeName = "node1"
+	replicaSnapshot.Spec.PoolName = v1alpha1.PoolNameForHDD
+
+	tests := []struct {
+		name       string
+		full       bool
+		wantParent string
+	}{
+		{name: "incremental", wantParent: "backup-2"},
+		{name: "full", full: true, wantParent: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			volumeBackup := &v1alpha1.LocalVolumeBackup{ObjectMeta: metav1.ObjectMeta{Name: "backup-3"}}
+			volumeBackup.Spec.SourceVolumeSnapshot = volumeSnapshot.Name
+			volumeBackup.Spec.Target = target
+			volumeBackup.Spec.Full = tt.full
+
+			m := newFakeBackupManager(t, volume, volumeSnapshot, replicaSnapshot, volumeBackup,
+				genFakeVolumeBackup("backup-1", volume.Name, target, now.Add(-2*time.Hour)),
+				genFakeVolumeBackup("backup-2", volume.Name, target, now.Add(-time.Hour)),
+				genFakeVolumeBackup("backup-other-target", volume.Name, otherTarget, now),
+				genFakeVolumeBackup("backup-other-volume", "pvc-2", target, now),
+			)
+
+			// (empty) -> Submitted -> InProgress
+			for i := 0; i < 2; i++ {
+				if err := m.processVolumeBackup(volumeBackup.Name); err != nil {
+					t.Fatalf("processVolumeBackup() error = %v", err)
+				}
+			}
+
+			got := &v1alpha1.LocalVolumeBackup{}
+			if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeBackup.Name}, got); err != nil {
+				t.Fatalf("Get LocalVolumeBackup error = %v", err)
+			}
+			if got.Status.State != v1alpha1.OperationStateInProgress || got.Status.NodeName != "node1" ||
+				got.Status.PoolName != v1alpha1.PoolNameForHDD || got.Status.ParentBackup != tt.wantParent {
+				t.Errorf("processVolumeBackup() got status %+v", got.Status)
+			}
+			if !hasVolumeBackupCleanupFinalizer(got) {
+				t.Errorf("processVolumeBackup() got finalizers %v", got.Finalizers)
+			}
+		})
+	}
+}
+
+func Test_manager_volumeBackupCleanup(t *testing.T) {
+	target := v1alpha1.BackupTarget{Endpoint: "http://minio:9000", Bucket: "backups", CredentialSecret: "hwameistor/s3"}
+	deletionTime := metav1.Now()
+
+	genDeletingVolumeBackup := func(name string, state v1alpha1.State) *v1alpha1.LocalVolumeBackup {
+		volumeBackup := genFakeVolumeBackup(name, "pvc-1", target, time.Now())
+		volumeBackup.Status.State = state
+		volumeBackup.Finalizers = []string{v1alpha1.VolumeBackupCleanupFinalizer}
+		volumeBackup.DeletionTimestamp = &deletionTime
+		return volumeBackup
+	}
+	runningBackup := genFakeVolumeBackup("backup-running", "pvc-2", target, time.Now())
+	runningBackup.Status.State = v1alpha1.OperationStateInProgress
+
+	tests := []struct {
+		name        string
+		state       v1alpha1.State
+		wantErr     bool
+		wantDeleted bool
+	}{
+		// nothing is in the target, release it at once
+		{name: "backup-aborted", state: v1alpha1.OperationStateAborted, wantDeleted: true},
+		// the chunks may be referenced by the running backup of the same target
+		{name: "backup-completed", state: v1alpha1.OperationStateCompleted, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newFakeBackupManager(t, genDeletingVolumeBackup(tt.name, tt.state), runningBackup)
+			if err := m.processVolumeBackup(tt.name); (err != nil) != tt.wantErr {
+				t.Fatalf("processVolumeBackup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got := &v1alpha1.LocalVolumeBackup{}
+			err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: tt.name}, got)
+			if deleted := errors.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("processVolumeBackup() got deleted %v, error %v", deleted, err)
+			}
+		})
+	}
+}
+
+func Test_manager_processVolumeBackupRestore(t *testing.T) {
+	target := v1alpha1.BackupTarget{Endpoint: "http://minio:9000", Bucket: "backups", CredentialSecret: "hwameistor/s3"}
+	volumeBackup := genFakeVolumeBackup("backup-1", "pvc-1", target, time.Now())
+
+	backupRestore := &v1alpha1.LocalVolumeBackupRestore{ObjectMeta: metav1.ObjectMeta{Name: "restore-1"}}
+	backupRestore.Spec.SourceBackup = volumeBackup.Name
+	backupRestore.Spec.TargetVolume = "pvc-1-restored"
+	backupRestore.Spec.TargetNodeName = "node2"
+
+	m := newFakeBackupManager(t, volumeBackup, backupRestore)
+
+	// (empty) -> Submitted -> InProgress
+	for i := 0; i < 2; i++ {
+		if err := m.processVolumeBackupRestore(backupRestore.Name); err != nil {
+			t.Fatalf("processVolumeBackupRestore() error = %v", err)
+		}
+	}
+
+	targetVolume := &v1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: backupRestore.Spec.TargetVolume}, targetVolume); err != nil {
+		t.Fatalf("Get target LocalVolume error = %v", err)
+	}
+	if targetVolume.Spec.RequiredCapacityBytes != 1073741824 || targetVolume.Spec.PoolName != v1alpha1.PoolNameForHDD ||
+		targetVolume.Spec.ReplicaNumber != 1 || len(targetVolume.Spec.Accessibility.Nodes) != 1 ||
+		targetVolume.Annotations[v1alpha1.SourceVolumeBackupAnnoKey] != volumeBackup.Name {
+		t.Errorf("processVolumeBackupRestore() got target volume %+v", targetVolume)
+	}
+
+	// the target volume is not ready yet
+	if err := m.processVolumeBackupRestore(backupRestore.Name); err == nil {
+		t.Errorf("processVolumeBackupRestore() should wait for the target volume")
+	}
+
+	// restore to an existing volume is not allowed
+	backupRestore = &v1alpha1.LocalVolumeBackupRestore{ObjectMeta: metav1.ObjectMeta{Name: "restore-2"}}
+	backupRestore.Spec.SourceBackup = volumeBackup.Name
+	backupRestore.Spec.TargetVolume = targetVolume.Name
+	if err := m.apiClient.Create(context.TODO(), backupRestore); err != nil {
+		t.Fatalf("Create LocalVolumeBackupRestore error = %v", err)
+	}
+	if err := m.processVolumeBackupRestore(backupRestore.Name); err != nil {
+		t.Fatalf("processVolumeBackupRestore() error = %v", err)
+	}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: backupRestore.Name}, backupRestore); err != nil {
+		t.Fatalf("Get LocalVolumeBackupRestore error = %v", err)
+	}
+	if backupRestore.Status.State != v1alpha1.OperationStateFailed {
+		t.Errorf("processVolumeBackupRestore() got state %s, want %s", backupRestore.Status.State, v1alpha1.OperationStateFailed)
+	}
+}
@@ -3,8 +3,10 @@ package controller
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/wxnacy/wgo/arrays"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 
@@ -137,21 +139,80 @@ func (m *manager) volumeConvertSubmit(convert *apisv1alpha1.LocalVolumeConvert)
 						convert.Status.Message = msg
 						convert.Status.State = apisv1alpha1.OperationStateFailed
 						break
-					} else if vol.Spec.ReplicaNumber == 1 && convert.Spec.ReplicaNumber == 2 {
-						// currently, only support convertible non-HA volume to HA conversion
+					} else if vol.Spec.ReplicaNumber < convert.Spec.ReplicaNumber && convert.Spec.ReplicaNumber <= apisv1alpha1.MaxVolumeReplicaNumber {
+						// adding replicas, e.g. non-HA to HA, or 2 replicas to 3 replicas
+						convert.Status.State = apisv1alpha1.OperationStateSubmitted
+					} else if vol.Spec.ReplicaNumber > convert.Spec.ReplicaNumber && convert.Spec.ReplicaNumber >= 1 {
+						// reducing replicas, e.g. HA to non-HA
 						convert.Status.State = apisv1alpha1.OperationStateSubmitted
 					} else {
 						logCtx.WithField("volume", vol.Spec).Error("Too big convert")
 						convert.Status.Message = "Not supported"
+						convert.Status.State = apisv1alpha1.OperationStateFailed
+						break
 					}
 				}
 			}
 		}
 	}
 
+	if convert.Status.State == apisv1alpha1.OperationStateSubmitted && vol.Spec.ReplicaNumber > convert.Spec.ReplicaNumber {
+		keptNodes, err := m.selectVolumeReplicaNodesToKeep(convert, vol, lvg)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to select the replicas to keep")
+			convert.Status.State = apisv1alpha1.OperationStateFailed
+			convert.Status.Message = err.Error()
+		}
+		convert.Status.KeptNodes = keptNodes
+	}
+
 	return m.apiClient.Status().Update(ctx, convert)
 }
 
+// selectVolumeReplicaNodesToKeep selects the nodes of the replicas to keep when reducing the replicas,
+// the node specified by the convert is preferred, then the node where the volumes in the group are published, then the primary one
+func (m *manager) selectVolumeReplicaNodesToKeep(convert *apisv1alpha1.LocalVolumeConvert, vol *apisv1alpha1.LocalVolume, lvg *apisv1alpha1.LocalVolumeGroup) ([]string, error) {
+	if vol.Spec.Config == nil {
+		return nil, fmt.Errorf("volume %s is not configured yet", vol.Name)
+	}
+	var replicaNodes, preferredNodes []string
+	for _, replica := range vol.Spec.Config.Replicas {
+		replicaNodes = append(replicaNodes, replica.Hostname)
+	}
+
+	if len(convert.Spec.KeepNode) > 0 {
+		if arrays.ContainsString(replicaNodes, convert.Spec.KeepNode) == -1 {
+			return nil, fmt.Errorf("no replica of volume %s on node %s to keep", vol.Name, convert.Spec.KeepNode)
+		}
+		preferredNodes = append(preferredNodes, convert.Spec.KeepNode)
+	}
+	for _, volumeInfo := range lvg.Spec.Volumes {
+		groupVolume := &apisv1alpha1.LocalVolume{}
+		if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: volumeInfo.LocalVolumeName}, groupVolume); err != nil {
+			return nil, err
+		}
+		if len(groupVolume.Status.PublishedNodeName) > 0 {
+			preferredNodes = append(preferredNodes, groupVolume.Status.PublishedNodeName)
+		}
+	}
+	for _, replica := range vol.Spec.Config.Replicas {
+		if replica.Primary {
+			preferredNodes = append(preferredNodes, replica.Hostname)
+		}
+	}
+
+	var keptNodes []string
+	for _, nodeName := range append(preferredNodes, replicaNodes...) {
+		if len(keptNodes) == int(convert.Spec.ReplicaNumber) {
+			break
+		}
+		if arrays.ContainsString(replicaNodes, nodeName) != -1 && arrays.ContainsString(keptNodes, nodeName) == -1 {
+			keptNodes = append(keptNodes, nodeName)
+		}
+	}
+	return keptNodes, nil
+}
+
 func (m *manager) volumeConvertStart(convert *apisv1alpha1.LocalVolumeConvert) error {
 	logCtx := m.logger.WithFields(log.Fields{"convert": convert.Name, "spec": convert.Spec, "status": convert.Status})
 	logCtx.Debug("Start a VolumeConvert")
@@ -187,6 +248,9 @@ func (m *manager) volumeConvertStart(convert *apisv1alpha1.LocalVolumeConvert) e
 					if vol.Spec.ReplicaNumber == convert.Spec.ReplicaNumber {
 						continue
 					}
+					if vol.Spec.ReplicaNumber > convert.Spec.ReplicaNumber {
+						pruneVolumeReplicas(&vol, convert.Status.KeptNodes)
+					}
 					vol.Spec.ReplicaNumber = convert.Spec.ReplicaNumber
 					if err := m.apiClient.Update(ctx, &vol); err != nil {
 						logCtx.WithField("volName", vol.Name).WithError(err).Error("Volume failed to start the volume convert")
@@ -199,6 +263,14 @@ func (m *manager) volumeConvertStart(convert *apisv1alpha1.LocalVolumeConvert) e
 		}
 	}
 
+	if len(convert.Status.KeptNodes) > 0 && !reflect.DeepEqual(lvg.Spec.Accessibility.Nodes, convert.Status.KeptNodes) {
+		lvg.Spec.Accessibility.Nodes = convert.Status.KeptNodes
+		if err := m.apiClient.Update(ctx, lvg); err != nil {
+			logCtx.WithField("LocalVolumeGroup", lvg.Name).WithError(err).Error("Failed to update LocalVolumeGroup")
+			return err
+		}
+	}
+
 	convert.Status.State = apisv1alpha1.OperationStateInProgress
 	logCtx.WithField("status", convert.Status).Debug("Started volume convert")
 	return m.apiClient.Status().Update(ctx, convert)
@@ -256,6 +328,12 @@ func (m *manager) volumeConvertInProgress(convert *apisv1alpha1.LocalVolumeConve
 						convert.Status.Message = "In Progress"
 						return fmt.Errorf("volume not ready")
 					}
+					if convert.Spec.ReplicaNumber == 1 {
+						if err := m.removeVolumeReplication(convert, &vol, replicas[0]); err != nil {
+							logCtx.WithField("volume", vol.Name).WithError(err).Debug("Removing the replication of the volume")
+							return err
+						}
+					}
 				}
 			}
 		}
@@ -300,3 +378,61 @@ func (m *manager) queryLocalVolumeGroup(ctx context.Context, volumeGroupName str
 	}
 	return lvg, nil
 }
+
+// pruneVolumeReplicas removes the replicas not on the kept nodes from the volume config,
+// the node manager deletes the replicas not in the config
+func pruneVolumeReplicas(vol *apisv1alpha1.LocalVolume, keptNodes []string) {
+	if vol.Spec.Config == nil {
+		return
+	}
+	replicas := []apisv1alpha1.VolumeReplica{}
+	hasPrimary := false
+	for _, replica := range vol.Spec.Config.Replicas {
+		if arrays.ContainsString(keptNodes, replica.Hostname) != -1 {
+			replicas = append(replicas, replica)
+			hasPrimary = hasPrimary || replica.Primary
+		}
+	}
+	if !hasPrimary && len(replicas) > 0 {
+		replicas[0].Primary = true
+	}
+	vol.Spec.Config.Replicas = replicas
+	vol.Spec.Config.Version++
+}
+
+// removeVolumeReplication removes the DRBD resource of the volume reduced to 1 replica.
+// The DRBD device can't be removed while it's in use, so wait for the volume to be unpublished,
+// and then the replica is re-pointed to the LV by the node manager without data copy
+func (m *manager) removeVolumeReplication(convert *apisv1alpha1.LocalVolumeConvert, vol *apisv1alpha1.LocalVolume, replica *apisv1alpha1.LocalVolumeReplica) error {
+	ctx := context.TODO()
+	if vol.Spec.Config.Convertible {
+		if len(vol.Status.PublishedNodeName) > 0 {
+			convert.Status.Message = fmt.Sprintf("Waiting for volume %s to be unpublished to remove the DRBD resource", vol.Name)
+			m.apiClient.Status().Update(ctx, convert)
+			return fmt.Errorf("volume %s is still published on node %s", vol.Name, vol.Status.PublishedNodeName)
+		}
+		// tell the node manager to remove the DRBD resource rather than keeping it for the replica
+		if vol.Annotations == nil {
+			vol.Annotations = map[string]string{}
+		}
+		vol.Annotations[apisv1alpha1.VolumeReplicationRemovingAnnoKey] = "true"
+		// the resource ID is recycled by the scheduler once the volume is non-HA
+		vol.Spec.Convertible = false
+		vol.Spec.Config.Convertible = false
+		vol.Spec.Config.ResourceID = -1
+		vol.Spec.Config.Version++
+		if err := m.apiClient.Update(ctx, vol); err != nil {
+			return err
+		}
+		return fmt.Errorf("removing the DRBD resource of volume %s", vol.Name)
+	}
+
+	if replica.Status.DevicePath != replica.Status.StoragePath {
+		return fmt.Errorf("replica %s is not re-pointed to the LV yet", replica.Name)
+	}
+	if _, exists := vol.Annotations[apisv1alpha1.VolumeReplicationRemovingAnnoKey]; exists {
+		delete(vol.Annotations, apisv1alpha1.VolumeReplicationRemovingAnnoKey)
+		return m.apiClient.Update(ctx, vol)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"context"
+	"reflect"
 	"sync"
 	"testing"
 
@@ -9,6 +10,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 	"github.com/hwameistor/hwameistor/pkg/local-storage/common"
@@ -600,3 +602,264 @@ func Test_manager_volumeConvertSubmit(t *testing.T) {
 		})
 	}
 }
+
+func Test_manager_volumeConvertSubmit_replicaNumber(t *testing.T) {
+	tests := []struct {
+		name                 string
+		volumeReplicaNumber  int64
+		convertible          bool
+		convertReplicaNumber int64
+		wantState            v1alpha1.State
+	}{
+		{
+			name:                 "non-HA to 2 replicas",
+			volumeReplicaNumber:  1,
+			convertible:          true,
+			convertReplicaNumber: 2,
+			wantState:            v1alpha1.OperationStateSubmitted,
+		},
+		{
+			name:                 "non-HA to 3 replicas",
+			volumeReplicaNumber:  1,
+			convertible:          true,
+			convertReplicaNumber: 3,
+			wantState:            v1alpha1.OperationStateSubmitted,
+		},
+		{
+			name:                 "2 replicas to 3 replicas",
+			volumeReplicaNumber:  2,
+			convertible:          true,
+			convertReplicaNumber: 3,
+			wantState:            v1alpha1.OperationStateSubmitted,
+		},
+		{
+			name:                 "inconvertible non-HA",
+			volumeReplicaNumber:  1,
+			convertReplicaNumber: 3,
+			wantState:            v1alpha1.OperationStateFailed,
+		},
+		{
+			name:                 "non-HA to 4 replicas",
+			volumeReplicaNumber:  1,
+			convertible:          true,
+			convertReplicaNumber: 4,
+			wantState:            v1alpha1.OperationStateFailed,
+		},
+		{
+			name:                 "3 replicas to 2 replicas, not configured",
+			volumeReplicaNumber:  3,
+			convertible:          true,
+			convertReplicaNumber: 2,
+			wantState:            v1alpha1.OperationStateFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lvg := &v1alpha1.LocalVolumeGroup{}
+			lvg.Name = "lvg-1"
+			lvg.Finalizers = []string{volumeGroupFinalizer}
+			lv := &v1alpha1.LocalVolume{}
+			lv.Name = "pvc-1"
+			lv.Spec.VolumeGroup = lvg.Name
+			lv.Spec.ReplicaNumber = tt.volumeReplicaNumber
+			lv.Spec.Convertible = tt.convertible
+			lvc := &v1alpha1.LocalVolumeConvert{}
+			lvc.Name = "convert-1"
+			lvc.Spec.VolumeName = lv.Name
+			lvc.Spec.ReplicaNumber = tt.convertReplicaNumber
+
+			s := runtime.NewScheme()
+			_ = v1alpha1.AddToScheme(s)
+			m := &manager{
+				apiClient: fake.NewClientBuilder().WithScheme(s).WithObjects(lvg, lv, lvc).Build(),
+				logger:    log.WithField("Module", "ControllerManager"),
+			}
+			if err := m.volumeConvertSubmit(lvc); err != nil {
+				t.Fatalf("volumeConvertSubmit() error = %v", err)
+			}
+			if lvc.Status.State != tt.wantState {
+				t.Errorf("volumeConvertSubmit() got state %q, want %q", lvc.Status.State, tt.wantState)
+			}
+		})
+	}
+}
+
+func newFakeReplicatedVolume(name string, nodes ...string) *v1alpha1.LocalVolume {
+	lv := &v1alpha1.LocalVolume{}
+	lv.Name = name
+	lv.Spec.VolumeGroup = "lvg-1"
+	lv.Spec.ReplicaNumber = int64(len(nodes))
+	lv.Spec.Convertible = true
+	lv.Spec.Config = &v1alpha1.VolumeConfig{
+		VolumeName:  name,
+		Version:     1,
+		Convertible: true,
+		ResourceID:  5,
+	}
+	for i, node := range nodes {
+		lv.Spec.Config.Replicas = append(lv.Spec.Config.Replicas, v1alpha1.VolumeReplica{ID: i + 1, Hostname: node, Primary: i == 0})
+	}
+	return lv
+}
+
+func Test_manager_volumeConvertSubmit_reduceReplicas(t *testing.T) {
+	tests := []struct {
+		name                 string
+		publishedNode        string
+		keepNode             string
+		convertReplicaNumber int64
+		wantState            v1alpha1.State
+		wantKeptNodes        []string
+	}{
+		{
+			name:                 "keep the primary replica",
+			convertReplicaNumber: 1,
+			wantState:            v1alpha1.OperationStateSubmitted,
+			wantKeptNodes:        []string{"node1"},
+		},
+		{
+			name:                 "keep the replica attached to the pod",
+			publishedNode:        "node3",
+			convertReplicaNumber: 1,
+			wantState:            v1alpha1.OperationStateSubmitted,
+			wantKeptNodes:        []string{"node3"},
+		},
+		{
+			name:                 "keep the specified node first",
+			publishedNode:        "node3",
+			keepNode:             "node2",
+			convertReplicaNumber: 2,
+			wantState:            v1alpha1.OperationStateSubmitted,
+			wantKeptNodes:        []string{"node2", "node3"},
+		},
+		{
+			name:                 "no replica on the specified node",
+			keepNode:             "node4",
+			convertReplicaNumber: 1,
+			wantState:            v1alpha1.OperationStateFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lv := newFakeReplicatedVolume("pvc-1", "node1", "node2", "node3")
+			lv.Status.PublishedNodeName = tt.publishedNode
+			lvg := &v1alpha1.LocalVolumeGroup{}
+			lvg.Name = lv.Spec.VolumeGroup
+			lvg.Finalizers = []string{volumeGroupFinalizer}
+			lvg.Spec.Volumes = []v1alpha1.VolumeInfo{{LocalVolumeName: lv.Name}}
+			lvc := &v1alpha1.LocalVolumeConvert{}
+			lvc.Name = "convert-1"
+			lvc.Spec.VolumeName = lv.Name
+			lvc.Spec.ReplicaNumber = tt.convertReplicaNumber
+			lvc.Spec.KeepNode = tt.keepNode
+
+			s := runtime.NewScheme()
+			_ = v1alpha1.AddToScheme(s)
+			m := &manager{
+				apiClient: fake.NewClientBuilder().WithScheme(s).WithObjects(lvg, lv, lvc).Build(),
+				logger:    log.WithField("Module", "ControllerManager"),
+			}
+			if err := m.volumeConvertSubmit(lvc); err != nil {
+				t.Fatalf("volumeConvertSubmit() error = %v", err)
+			}
+			if lvc.Status.State != tt.wantState {
+				t.Errorf("volumeConvertSubmit() got state %q, want %q", lvc.Status.State, tt.wantState)
+			}
+			if !reflect.DeepEqual(lvc.Status.KeptNodes, tt.wantKeptNodes) {
+				t.Errorf("volumeConvertSubmit() got kept nodes %v, want %v", lvc.Status.KeptNodes, tt.wantKeptNodes)
+			}
+		})
+	}
+}
+
+func Test_manager_volumeConvertStart_reduceReplicas(t *testing.T) {
+	lv := newFakeReplicatedVolume("pvc-1", "node1", "node2")
+	lvg := &v1alpha1.LocalVolumeGroup{}
+	lvg.Name = lv.Spec.VolumeGroup
+	lvg.Finalizers = []string{volumeGroupFinalizer}
+	lvg.Spec.Accessibility.Nodes = []string{"node1", "node2"}
+	lvc := &v1alpha1.LocalVolumeConvert{}
+	lvc.Name = "convert-1"
+	lvc.Spec.VolumeName = lv.Name
+	lvc.Spec.ReplicaNumber = 1
+	lvc.Status.State = v1alpha1.OperationStateSubmitted
+	lvc.Status.KeptNodes = []string{"node2"}
+
+	s := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(s)
+	m := &manager{
+		apiClient: fake.NewClientBuilder().WithScheme(s).WithObjects(lvg, lv, lvc).Build(),
+		logger:    log.WithField("Module", "ControllerManager"),
+	}
+	if err := m.volumeConvertStart(lvc); err != nil {
+		t.Fatalf("volumeConvertStart() error = %v", err)
+	}
+	if lvc.Status.State != v1alpha1.OperationStateInProgress {
+		t.Errorf("volumeConvertStart() got state %q, want %q", lvc.Status.State, v1alpha1.OperationStateInProgress)
+	}
+
+	gotVol, _ := m.queryLocalVolume(context.TODO(), lv.Name)
+	wantReplicas := []v1alpha1.VolumeReplica{{ID: 2, Hostname: "node2", Primary: true}}
+	if gotVol.Spec.ReplicaNumber != 1 || !reflect.DeepEqual(gotVol.Spec.Config.Replicas, wantReplicas) {
+		t.Errorf("volumeConvertStart() got volume replicas %d %v, want 1 %v", gotVol.Spec.ReplicaNumber, gotVol.Spec.Config.Replicas, wantReplicas)
+	}
+	if !gotVol.Spec.Config.Convertible {
+		t.Errorf("volumeConvertStart() the DRBD resource shouldn't be removed before the replicas are pruned")
+	}
+	gotLvg, _ := m.queryLocalVolumeGroup(context.TODO(), lvg.Name)
+	if !reflect.DeepEqual(gotLvg.Spec.Accessibility.Nodes, lvc.Status.KeptNodes) {
+		t.Errorf("volumeConvertStart() got accessibility nodes %v, want %v", gotLvg.Spec.Accessibility.Nodes, lvc.Status.KeptNodes)
+	}
+}
+
+func Test_manager_removeVolumeReplication(t *testing.T) {
+	lv := newFakeReplicatedVolume("pvc-1", "node1")
+	lv.Status.PublishedNodeName = "node1"
+	replica := &v1alpha1.LocalVolumeReplica{}
+	replica.Name = "pvc-1-replica"
+	replica.Status.StoragePath = "/dev/LocalStorage_PoolHDD/pvc-1"
+	replica.Status.DevicePath = "/dev/LocalStorage_PoolHDD-HA/pvc-1"
+	lvc := &v1alpha1.LocalVolumeConvert{}
+	lvc.Name = "convert-1"
+	lvc.Spec.VolumeName = lv.Name
+	lvc.Spec.ReplicaNumber = 1
+
+	s := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(s)
+	m := &manager{
+		apiClient: fake.NewClientBuilder().WithScheme(s).WithObjects(lv, lvc).Build(),
+		logger:    log.WithField("Module", "ControllerManager"),
+	}
+
+	// the DRBD resource can't be removed while the volume is published
+	if err := m.removeVolumeReplication(lvc, lv, replica); err == nil {
+		t.Fatalf("removeVolumeReplication() expects error when the volume is published")
+	}
+	if gotVol, _ := m.queryLocalVolume(context.TODO(), lv.Name); !gotVol.Spec.Config.Convertible {
+		t.Errorf("removeVolumeReplication() shouldn't remove the DRBD resource of the published volume")
+	}
+
+	// the volume becomes non-HA once unpublished
+	lv.Status.PublishedNodeName = ""
+	if err := m.removeVolumeReplication(lvc, lv, replica); err == nil {
+		t.Fatalf("removeVolumeReplication() expects error when the DRBD resource is being removed")
+	}
+	gotVol, _ := m.queryLocalVolume(context.TODO(), lv.Name)
+	if gotVol.Spec.Convertible || gotVol.Spec.Config.Convertible || gotVol.Spec.Config.ResourceID != -1 {
+		t.Errorf("removeVolumeReplication() got convertible %v, config convertible %v, resource ID %d, want false, false, -1",
+			gotVol.Spec.Convertible, gotVol.Spec.Config.Convertible, gotVol.Spec.Config.ResourceID)
+	}
+	if _, exists := gotVol.Annotations[v1alpha1.VolumeReplicationRemovingAnnoKey]; !exists {
+		t.Errorf("removeVolumeReplication() should annotate the volume to remove the DRBD resource")
+	}
+
+	// completed once the replica is re-pointed to the LV
+	replica.Status.DevicePath = replica.Status.StoragePath
+	if err := m.removeVolumeReplication(lvc, gotVol, replica); err != nil {
+		t.Errorf("removeVolumeReplication() error = %v", err)
+	}
+	gotVol, _ = m.queryLocalVolume(context.TODO(), lv.Name)
+	if _, exists := gotVol.Annotations[v1alpha1.VolumeReplicationRemovingAnnoKey]; exists {
+		t.Errorf("removeVolumeReplication() should remove the annotation once completed")
+	}
+}
@@ -0,0 +1,364 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/encrypt"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/utils"
+)
+
+func (m *manager) startVolumeEncryptTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("Volume Encrypt Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.volumeEncryptTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the Volume Encrypt worker")
+				break
+			}
+			if err := m.processVolumeEncrypt(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.volumeEncryptTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process Volume Encrypt task, retry later")
+				m.volumeEncryptTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a Volume Encrypt task.")
+				m.volumeEncryptTaskQueue.Forget(task)
+			}
+			m.volumeEncryptTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.volumeEncryptTaskQueue.Shutdown()
+}
+
+func (m *manager) processVolumeEncrypt(volEncryptName string) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeEncrypt": volEncryptName})
+	logCtx.Debug("Working on a VolumeEncrypt task")
+	volEncrypt := &apisv1alpha1.LocalVolumeEncrypt{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: volEncryptName}, volEncrypt); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get VolumeEncrypt from cache")
+			return err
+		}
+		logCtx.Info("Not found the VolumeEncrypt from cache, should be deleted already")
+		return nil
+	}
+
+	// the volume is untouched before Initialize, so it's aborted directly.
+	// Once the data is being encrypted, the node interrupts the encryption safely
+	if volEncrypt.Spec.Abort &&
+		(volEncrypt.Status.State == "" || volEncrypt.Status.State == apisv1alpha1.OperationStateSubmitted) {
+
+		volEncrypt.Status.State = apisv1alpha1.OperationStateAborted
+		volEncrypt.Status.Message = "aborted by user"
+		return m.apiClient.Status().Update(context.TODO(), volEncrypt)
+	}
+
+	logCtx = m.logger.WithFields(log.Fields{"VolumeEncrypt": volEncrypt.Name, "Spec": volEncrypt.Spec, "Status": volEncrypt.Status})
+	logCtx.Debug("Starting to process a VolumeEncrypt")
+
+	// state chain: (empty) -> Submitted -> Initialize -> Reencrypt -> Completed/Failed/Aborted
+	// the controller switches the volume to the encryption config when Submitted, and the node initializes the LUKS2
+	// header and encrypts the data in place when Initialize and Reencrypt. The volume is switched back by Rollback
+	// if the initialization fails
+	switch volEncrypt.Status.State {
+	case "":
+		return m.volumeEncryptSubmit(volEncrypt)
+	case apisv1alpha1.OperationStateSubmitted:
+		return m.volumeEncryptStart(volEncrypt)
+	case apisv1alpha1.OperationStateRollback:
+		return m.volumeEncryptRollback(volEncrypt)
+	case apisv1alpha1.OperationStateCompleted:
+		return m.volumeEncryptCleanup(volEncrypt)
+	case apisv1alpha1.OperationStateEncryptInitialize, apisv1alpha1.OperationStateEncryptReencrypt,
+		apisv1alpha1.OperationStateFailed, apisv1alpha1.OperationStateAborted:
+		return nil
+	default:
+		logCtx.Error("Invalid state/phase")
+	}
+	return fmt.Errorf("invalid state")
+}
+
+func (m *manager) volumeEncryptSubmit(volEncrypt *apisv1alpha1.LocalVolumeEncrypt) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeEncrypt": volEncrypt.Name, "Spec": volEncrypt.Spec})
+	logCtx.Debug("Submit a VolumeEncrypt")
+
+	volume := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volEncrypt.Spec.VolumeName}, volume); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get volume")
+			return err
+		}
+		return m.volumeEncryptFail(volEncrypt, fmt.Sprintf("volume %s not found", volEncrypt.Spec.VolumeName))
+	}
+	nodes := getVolumeReplicaNodes(volume)
+	if len(nodes) != 1 || volume.Spec.Convertible {
+		return m.volumeEncryptFail(volEncrypt, "only the non-HA and non-convertible volume can be encrypted")
+	}
+
+	if volume.Spec.VolumeEncrypt.Enable {
+		// the volume is encrypted already, unless the encryption was interrupted and is resumed now
+		if err := m.volumeEncryptResume(volEncrypt, volume); err != nil {
+			return m.volumeEncryptFail(volEncrypt, err.Error())
+		}
+		return nil
+	}
+
+	if volume.Status.State != apisv1alpha1.VolumeStateReady {
+		err := fmt.Errorf("volume %s is not ready", volume.Name)
+		logCtx.WithError(err).Error("Failed to submit VolumeEncrypt")
+		return err
+	}
+	if volume.Status.PublishedRawBlock {
+		return m.volumeEncryptFail(volEncrypt, "raw block volume can't be encrypted online")
+	}
+
+	volumeEncrypt, err := m.newVolumeEncryptConfig(volEncrypt)
+	if err != nil {
+		return m.volumeEncryptFail(volEncrypt, err.Error())
+	}
+
+	replicas, err := m.getReplicasForVolume(volume.Name)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to list volume replicas")
+		return err
+	}
+	volEncrypt.Status.OriginalCapacityBytes = volume.Spec.RequiredCapacityBytes
+	for _, replica := range replicas {
+		if replica.Spec.NodeName == nodes[0] && replica.Status.AllocatedCapacityBytes > 0 {
+			volEncrypt.Status.OriginalCapacityBytes = replica.Status.AllocatedCapacityBytes
+		}
+	}
+
+	volEncrypt.Status.NodeName = nodes[0]
+	volEncrypt.Status.VolumeEncrypt = volumeEncrypt
+	volEncrypt.Status.State = apisv1alpha1.OperationStateSubmitted
+	return m.apiClient.Status().Update(context.TODO(), volEncrypt)
+}
+
+// newVolumeEncryptConfig returns the encryption config of the volume. A new data key is generated by the KMS
+// for the KMS provider, and the Secret is checked for the Secret provider
+func (m *manager) newVolumeEncryptConfig(volEncrypt *apisv1alpha1.LocalVolumeEncrypt) (*apisv1alpha1.VolumeEncrypt, error) {
+	volumeEncrypt := &apisv1alpha1.VolumeEncrypt{
+		Enable:               true,
+		Type:                 volEncrypt.Spec.Type,
+		SecretNamespacedName: volEncrypt.Spec.Secret,
+		KeyProvider:          volEncrypt.Spec.KeyProvider,
+	}
+	if volumeEncrypt.Type == "" {
+		volumeEncrypt.Type = "LUKS"
+	}
+	if volumeEncrypt.KeyProvider == "" {
+		volumeEncrypt.KeyProvider = apisv1alpha1.VolumeEncryptKeyProviderSecret
+	}
+	if volEncrypt.Spec.KMS != nil {
+		volumeEncrypt.KMS = volEncrypt.Spec.KMS.DeepCopy()
+	}
+
+	provider, err := encrypt.NewKeyProvider(m.apiClient, volumeEncrypt)
+	if err != nil {
+		return nil, err
+	}
+	if volumeEncrypt.KeyProvider == apisv1alpha1.VolumeEncryptKeyProviderKMS {
+		wrappedKey, err := provider.NewKey()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate key by KMS: %v", err)
+		}
+		encrypt.SetKeyRef(volumeEncrypt, wrappedKey)
+		return volumeEncrypt, nil
+	}
+
+	if volumeEncrypt.SecretNamespacedName == "" {
+		return nil, fmt.Errorf("secret is required to encrypt with the key held by Secret")
+	}
+	if _, err := provider.GetKey(volumeEncrypt.SecretNamespacedName); err != nil {
+		return nil, fmt.Errorf("invalid secret %s: %v", volumeEncrypt.SecretNamespacedName, err)
+	}
+	return volumeEncrypt, nil
+}
+
+// volumeEncryptResume takes over the interrupted encryption of the volume, the node resumes it from the LUKS2 header
+func (m *manager) volumeEncryptResume(volEncrypt *apisv1alpha1.LocalVolumeEncrypt, volume *apisv1alpha1.LocalVolume) error {
+	inProgress, exists := volume.Annotations[apisv1alpha1.VolumeEncryptInProgressAnnoKey]
+	if !exists {
+		return fmt.Errorf("volume %s is encrypted already", volume.Name)
+	}
+	if inProgress != volEncrypt.Name {
+		previous := &apisv1alpha1.LocalVolumeEncrypt{}
+		err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: inProgress}, previous)
+		if err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		if err == nil && previous.Status.State != apisv1alpha1.OperationStateFailed && previous.Status.State != apisv1alpha1.OperationStateAborted {
+			return fmt.Errorf("volume %s is being encrypted by %s", volume.Name, inProgress)
+		}
+
+		volume.Annotations[apisv1alpha1.VolumeEncryptInProgressAnnoKey] = volEncrypt.Name
+		if err := m.apiClient.Update(context.TODO(), volume); err != nil {
+			return err
+		}
+	}
+
+	startTime := metav1.Now()
+	volEncrypt.Status.StartTime = &startTime
+	volEncrypt.Status.NodeName = getVolumeReplicaNodes(volume)[0]
+	volEncrypt.Status.VolumeEncrypt = volume.Spec.VolumeEncrypt.DeepCopy()
+	volEncrypt.Status.State = apisv1alpha1.OperationStateEncryptReencrypt
+	volEncrypt.Status.Message = fmt.Sprintf("resume the encryption interrupted by %s", inProgress)
+	return m.apiClient.Status().Update(context.TODO(), volEncrypt)
+}
+
+func (m *manager) volumeEncryptStart(volEncrypt *apisv1alpha1.LocalVolumeEncrypt) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeEncrypt": volEncrypt.Name, "Spec": volEncrypt.Spec})
+	logCtx.Debug("Start a VolumeEncrypt")
+
+	volume := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volEncrypt.Spec.VolumeName}, volume); err != nil {
+		logCtx.WithError(err).Error("Failed to get volume")
+		return err
+	}
+
+	// the device can't be encrypted in place while it's mounted
+	if volume.Status.PublishedNodeName != "" {
+		message := "waiting for the volume to be unpublished"
+		if volEncrypt.Spec.EvictPod {
+			if err := m.evictPodsUsingVolume(volume, volume.Status.PublishedNodeName); err != nil {
+				logCtx.WithError(err).Error("Failed to evict the pods using the volume")
+				return err
+			}
+			message = "waiting for the pods using the volume to be evicted"
+		}
+		if volEncrypt.Status.Message != message {
+			volEncrypt.Status.Message = message
+			if err := m.apiClient.Status().Update(context.TODO(), volEncrypt); err != nil {
+				return err
+			}
+		}
+		return fmt.Errorf("volume %s is still in use", volume.Name)
+	}
+
+	// the LUKS2 header takes the space at the end of the volume, which is required from the volume as an expansion,
+	// so it's accounted in the pool before the node initializes the header
+	requiredCapacityBytes := utils.NumericToLVMBytes(volEncrypt.Status.OriginalCapacityBytes + encrypt.LUKSHeaderReserveBytes)
+	if volume.Spec.RequiredCapacityBytes < requiredCapacityBytes {
+		logCtx.WithField("capacity", requiredCapacityBytes).Info("Reserving the capacity for the LUKS2 header")
+		volume.Spec.RequiredCapacityBytes = requiredCapacityBytes
+		if err := m.apiClient.Update(context.TODO(), volume); err != nil {
+			logCtx.WithError(err).Error("Failed to update volume with the capacity for the LUKS2 header")
+			return err
+		}
+	}
+	if volume.Status.AllocatedCapacityBytes < requiredCapacityBytes {
+		message := "waiting for the volume to be extended for the LUKS2 header"
+		if volEncrypt.Status.Message != message {
+			volEncrypt.Status.Message = message
+			if err := m.apiClient.Status().Update(context.TODO(), volEncrypt); err != nil {
+				return err
+			}
+		}
+		return fmt.Errorf("volume %s is not extended yet", volume.Name)
+	}
+
+	// from now on, the volume is staged as an encrypted one, which blocks the pod until the header is initialized
+	if err := m.switchVolumeEncrypt(volume, volEncrypt.Status.VolumeEncrypt, volEncrypt.Name); err != nil {
+		logCtx.WithError(err).Error("Failed to switch volume to the encryption")
+		return err
+	}
+
+	startTime := metav1.Now()
+	volEncrypt.Status.StartTime = &startTime
+	volEncrypt.Status.Message = ""
+	volEncrypt.Status.State = apisv1alpha1.OperationStateEncryptInitialize
+	return m.apiClient.Status().Update(context.TODO(), volEncrypt)
+}
+
+// switchVolumeEncrypt sets the encryption config to the volume and all its replicas, and marks the volume with
+// the in-progress encryption. The volume is switched back to unencrypted if volumeEncrypt is nil
+func (m *manager) switchVolumeEncrypt(volume *apisv1alpha1.LocalVolume, volumeEncrypt *apisv1alpha1.VolumeEncrypt, volEncryptName string) error {
+	newVolumeEncrypt := apisv1alpha1.VolumeEncrypt{}
+	if volumeEncrypt != nil {
+		newVolumeEncrypt = *volumeEncrypt
+	}
+
+	if volume.Annotations == nil {
+		volume.Annotations = map[string]string{}
+	}
+	if volumeEncrypt != nil {
+		volume.Annotations[apisv1alpha1.VolumeEncryptInProgressAnnoKey] = volEncryptName
+	} else {
+		delete(volume.Annotations, apisv1alpha1.VolumeEncryptInProgressAnnoKey)
+	}
+	volume.Spec.VolumeEncrypt = newVolumeEncrypt
+	if err := m.apiClient.Update(context.TODO(), volume); err != nil {
+		return err
+	}
+
+	replicas, err := m.getReplicasForVolume(volume.Name)
+	if err != nil {
+		return err
+	}
+	for _, replica := range replicas {
+		if reflect.DeepEqual(replica.Spec.VolumeEncrypt, newVolumeEncrypt) {
+			continue
+		}
+		replica.Spec.VolumeEncrypt = newVolumeEncrypt
+		if err := m.apiClient.Update(context.TODO(), replica); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *manager) volumeEncryptRollback(volEncrypt *apisv1alpha1.LocalVolumeEncrypt) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeEncrypt": volEncrypt.Name, "Spec": volEncrypt.Spec})
+	logCtx.Debug("Switch the volume back to unencrypted")
+
+	volume := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volEncrypt.Spec.VolumeName}, volume); err != nil {
+		logCtx.WithError(err).Error("Failed to get volume")
+		return err
+	}
+	if volume.Annotations[apisv1alpha1.VolumeEncryptInProgressAnnoKey] == volEncrypt.Name {
+		if err := m.switchVolumeEncrypt(volume, nil, volEncrypt.Name); err != nil {
+			logCtx.WithError(err).Error("Failed to switch volume back to unencrypted")
+			return err
+		}
+	}
+
+	completionTime := metav1.Now()
+	volEncrypt.Status.CompletionTime = &completionTime
+	volEncrypt.Status.State = apisv1alpha1.OperationStateFailed
+	return m.apiClient.Status().Update(context.TODO(), volEncrypt)
+}
+
+func (m *manager) volumeEncryptCleanup(volEncrypt *apisv1alpha1.LocalVolumeEncrypt) error {
+	volume := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volEncrypt.Spec.VolumeName}, volume); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	if volume.Annotations[apisv1alpha1.VolumeEncryptInProgressAnnoKey] != volEncrypt.Name {
+		return nil
+	}
+
+	m.logger.WithFields(log.Fields{"VolumeEncrypt": volEncrypt.Name, "LocalVolume": volume.Name}).Info("Volume is encrypted completely")
+	delete(volume.Annotations, apisv1alpha1.VolumeEncryptInProgressAnnoKey)
+	return m.apiClient.Update(context.TODO(), volume)
+}
+
+func (m *manager) volumeEncryptFail(volEncrypt *apisv1alpha1.LocalVolumeEncrypt, message string) error {
+	volEncrypt.Status.State = apisv1alpha1.OperationStateFailed
+	volEncrypt.Status.Message = message
+	return m.apiClient.Status().Update(context.TODO(), volEncrypt)
+}
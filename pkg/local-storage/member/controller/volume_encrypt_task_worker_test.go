@@ -0,0 +1,170 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/encrypt"
+)
+
+func getFakeVolumeEncrypt(t *testing.T, m *manager, name string) *v1alpha1.LocalVolumeEncrypt {
+	volEncrypt := &v1alpha1.LocalVolumeEncrypt{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: name}, volEncrypt); err != nil {
+		t.Fatalf("Failed to get LocalVolumeEncrypt: %v", err)
+	}
+	return volEncrypt
+}
+
+func Test_manager_volumeEncryptSubmit(t *testing.T) {
+	interruptedVolume := genFakeEncryptedVolume("pvc-interrupted", "node1")
+	interruptedVolume.Annotations = map[string]string{v1alpha1.VolumeEncryptInProgressAnnoKey: "previous"}
+	previous := &v1alpha1.LocalVolumeEncrypt{ObjectMeta: metav1.ObjectMeta{Name: "previous"}}
+	previous.Status.State = v1alpha1.OperationStateAborted
+
+	tests := []struct {
+		name      string
+		spec      v1alpha1.LocalVolumeEncryptSpec
+		wantState v1alpha1.State
+	}{
+		{
+			name:      "encrypt with secret",
+			spec:      v1alpha1.LocalVolumeEncryptSpec{VolumeName: "pvc-plain", Secret: "hwameistor/new-secret"},
+			wantState: v1alpha1.OperationStateSubmitted,
+		},
+		{
+			name:      "secret not set",
+			spec:      v1alpha1.LocalVolumeEncryptSpec{VolumeName: "pvc-plain"},
+			wantState: v1alpha1.OperationStateFailed,
+		},
+		{
+			name:      "secret not found",
+			spec:      v1alpha1.LocalVolumeEncryptSpec{VolumeName: "pvc-plain", Secret: "hwameistor/not-exist"},
+			wantState: v1alpha1.OperationStateFailed,
+		},
+		{
+			name:      "HA volume",
+			spec:      v1alpha1.LocalVolumeEncryptSpec{VolumeName: "pvc-ha", Secret: "hwameistor/new-secret"},
+			wantState: v1alpha1.OperationStateFailed,
+		},
+		{
+			name:      "encrypted already",
+			spec:      v1alpha1.LocalVolumeEncryptSpec{VolumeName: "pvc-encrypted", Secret: "hwameistor/new-secret"},
+			wantState: v1alpha1.OperationStateFailed,
+		},
+		{
+			name:      "resume interrupted encryption",
+			spec:      v1alpha1.LocalVolumeEncryptSpec{VolumeName: "pvc-interrupted"},
+			wantState: v1alpha1.OperationStateEncryptReencrypt,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			volEncrypt := &v1alpha1.LocalVolumeEncrypt{ObjectMeta: metav1.ObjectMeta{Name: "encrypt"}, Spec: tt.spec}
+			replica := &v1alpha1.LocalVolumeReplica{ObjectMeta: metav1.ObjectMeta{Name: "pvc-plain-node1"}}
+			replica.Spec.VolumeName = "pvc-plain"
+			replica.Spec.NodeName = "node1"
+			replica.Status.AllocatedCapacityBytes = 1024 * 1024 * 1024
+			m := newFakeKeyRotateManager(t, volEncrypt, previous, replica, interruptedVolume,
+				genFakeScrubVolume("pvc-plain", false, false, "node1"), genFakeScrubVolume("pvc-ha", true, false, "node1", "node2"),
+				genFakeEncryptedVolume("pvc-encrypted", "node1"), genFakeEncryptSecret("new-secret"))
+
+			if err := m.volumeEncryptSubmit(volEncrypt); err != nil {
+				t.Fatalf("volumeEncryptSubmit() error = %v", err)
+			}
+			got := getFakeVolumeEncrypt(t, m, "encrypt")
+			if got.Status.State != tt.wantState {
+				t.Errorf("volumeEncryptSubmit() got status %+v", got.Status)
+			}
+			if tt.wantState == v1alpha1.OperationStateSubmitted &&
+				(got.Status.NodeName != "node1" || got.Status.OriginalCapacityBytes != replica.Status.AllocatedCapacityBytes ||
+					got.Status.VolumeEncrypt == nil || got.Status.VolumeEncrypt.SecretNamespacedName != "hwameistor/new-secret") {
+				t.Errorf("volumeEncryptSubmit() got status %+v", got.Status)
+			}
+		})
+	}
+}
+
+func Test_manager_volumeEncryptStartAndRollback(t *testing.T) {
+	volEncrypt := &v1alpha1.LocalVolumeEncrypt{ObjectMeta: metav1.ObjectMeta{Name: "encrypt"}}
+	volEncrypt.Spec.VolumeName = "pvc-1"
+	volEncrypt.Status.State = v1alpha1.OperationStateSubmitted
+	volEncrypt.Status.VolumeEncrypt = &v1alpha1.VolumeEncrypt{Enable: true, Type: "LUKS", SecretNamespacedName: "hwameistor/new-secret"}
+	volume := genFakeScrubVolume("pvc-1", false, false, "node1")
+	volume.Status.PublishedNodeName = "node1"
+	replica := &v1alpha1.LocalVolumeReplica{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1-node1"}}
+	replica.Spec.VolumeName = "pvc-1"
+	m := newFakeKeyRotateManager(t, volEncrypt, volume, replica)
+
+	getVolume := func() (*v1alpha1.LocalVolume, *v1alpha1.LocalVolumeReplica) {
+		gotVolume, gotReplica := &v1alpha1.LocalVolume{}, &v1alpha1.LocalVolumeReplica{}
+		if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: "pvc-1"}, gotVolume); err != nil {
+			t.Fatal(err)
+		}
+		if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: "pvc-1-node1"}, gotReplica); err != nil {
+			t.Fatal(err)
+		}
+		return gotVolume, gotReplica
+	}
+
+	// the volume is still in use
+	if err := m.volumeEncryptStart(volEncrypt); err == nil {
+		t.Fatal("volumeEncryptStart() expected error for published volume")
+	}
+	if gotVolume, _ := getVolume(); gotVolume.Spec.VolumeEncrypt.Enable {
+		t.Errorf("volumeEncryptStart() switched the published volume")
+	}
+
+	volume, _ = getVolume()
+	volume.Status.PublishedNodeName = ""
+	if err := m.apiClient.Update(context.TODO(), volume); err != nil {
+		t.Fatal(err)
+	}
+	// the capacity for the LUKS2 header is required from the volume at first
+	volEncrypt = getFakeVolumeEncrypt(t, m, "encrypt")
+	if err := m.volumeEncryptStart(volEncrypt); err == nil {
+		t.Fatal("volumeEncryptStart() expected error for the volume not extended")
+	}
+	volume, _ = getVolume()
+	wantCapacityBytes := volEncrypt.Status.OriginalCapacityBytes + encrypt.LUKSHeaderReserveBytes
+	if volume.Spec.RequiredCapacityBytes != wantCapacityBytes || volume.Spec.VolumeEncrypt.Enable {
+		t.Errorf("volumeEncryptStart() got capacity %d, want %d", volume.Spec.RequiredCapacityBytes, wantCapacityBytes)
+	}
+
+	volume.Status.AllocatedCapacityBytes = wantCapacityBytes
+	if err := m.apiClient.Update(context.TODO(), volume); err != nil {
+		t.Fatal(err)
+	}
+	volEncrypt = getFakeVolumeEncrypt(t, m, "encrypt")
+	if err := m.volumeEncryptStart(volEncrypt); err != nil {
+		t.Fatalf("volumeEncryptStart() error = %v", err)
+	}
+	if got := getFakeVolumeEncrypt(t, m, "encrypt"); got.Status.State != v1alpha1.OperationStateEncryptInitialize {
+		t.Errorf("volumeEncryptStart() got state %v", got.Status.State)
+	}
+	gotVolume, gotReplica := getVolume()
+	if !gotVolume.Spec.VolumeEncrypt.Enable || !gotReplica.Spec.VolumeEncrypt.Enable ||
+		gotVolume.Annotations[v1alpha1.VolumeEncryptInProgressAnnoKey] != "encrypt" {
+		t.Errorf("volumeEncryptStart() got volume %+v, replica %+v", gotVolume.Spec.VolumeEncrypt, gotReplica.Spec.VolumeEncrypt)
+	}
+
+	// the node fails to initialize the encryption
+	volEncrypt = getFakeVolumeEncrypt(t, m, "encrypt")
+	volEncrypt.Status.State = v1alpha1.OperationStateRollback
+	if err := m.volumeEncryptRollback(volEncrypt); err != nil {
+		t.Fatalf("volumeEncryptRollback() error = %v", err)
+	}
+	if got := getFakeVolumeEncrypt(t, m, "encrypt"); got.Status.State != v1alpha1.OperationStateFailed {
+		t.Errorf("volumeEncryptRollback() got state %v", got.Status.State)
+	}
+	gotVolume, gotReplica = getVolume()
+	if gotVolume.Spec.VolumeEncrypt.Enable || gotReplica.Spec.VolumeEncrypt.Enable {
+		t.Errorf("volumeEncryptRollback() got volume %+v, replica %+v", gotVolume.Spec.VolumeEncrypt, gotReplica.Spec.VolumeEncrypt)
+	}
+	if _, exists := gotVolume.Annotations[v1alpha1.VolumeEncryptInProgressAnnoKey]; exists {
+		t.Errorf("volumeEncryptRollback() got annotations %v", gotVolume.Annotations)
+	}
+}
@@ -0,0 +1,499 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/utils"
+)
+
+func (m *manager) startVolumeGroupSnapshotTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("Volume Group Snapshot Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.volumeGroupSnapshotTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the Volume Group Snapshot worker")
+				break
+			}
+			if err := m.processVolumeGroupSnapshot(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.volumeGroupSnapshotTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process Volume Group Snapshot task, retry later")
+				m.volumeGroupSnapshotTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a Volume Group Snapshot task.")
+				m.volumeGroupSnapshotTaskQueue.Forget(task)
+			}
+			m.volumeGroupSnapshotTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.volumeGroupSnapshotTaskQueue.Shutdown()
+}
+
+func (m *manager) processVolumeGroupSnapshot(groupSnapshotName string) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeGroupSnapshot": groupSnapshotName})
+	logCtx.Debug("Working on a VolumeGroupSnapshot task")
+	groupSnapshot := &apisv1alpha1.LocalVolumeGroupSnapshot{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: groupSnapshotName}, groupSnapshot); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get VolumeGroupSnapshot from cache")
+			return err
+		}
+		logCtx.Info("Not found the VolumeGroupSnapshot from cache, should be deleted already")
+		return nil
+	}
+
+	if groupSnapshot.Spec.Delete && groupSnapshot.Status.State != apisv1alpha1.VolumeStateToBeDeleted {
+		groupSnapshot.Status.State = apisv1alpha1.VolumeStateToBeDeleted
+		return m.apiClient.Status().Update(context.TODO(), groupSnapshot)
+	}
+
+	// log with namespace/name is enough
+	logCtx = m.logger.WithFields(log.Fields{"VolumeGroup": groupSnapshot.Spec.SourceVolumeGroup, "GroupSnapshot": groupSnapshot.Name, "Spec": groupSnapshot.Spec, "Status": groupSnapshot.Status})
+	logCtx.Debug("Starting to process a VolumeGroupSnapshot")
+
+	// state chain: (empty) -> Creating -> Ready/NotReady -> ToBeDeleted
+	switch groupSnapshot.Status.State {
+	case "":
+		return m.volumeGroupSnapshotSubmit(groupSnapshot)
+	case apisv1alpha1.VolumeStateCreating, apisv1alpha1.VolumeStateReady, apisv1alpha1.VolumeStateNotReady:
+		return m.volumeGroupSnapshotReadyOrNot(groupSnapshot)
+	case apisv1alpha1.VolumeStateToBeDeleted:
+		return m.volumeGroupSnapshotDelete(groupSnapshot)
+	default:
+		logCtx.Error("Invalid state")
+	}
+	return fmt.Errorf("invalid state")
+}
+
+// volumeGroupSnapshotSubmit creates a member snapshot for each volume in the group.
+// The node takes all the member snapshots at once, see createQuiescedVolumeReplicaSnapshot
+func (m *manager) volumeGroupSnapshotSubmit(groupSnapshot *apisv1alpha1.LocalVolumeGroupSnapshot) error {
+	logCtx := m.logger.WithFields(log.Fields{"GroupSnapshot": groupSnapshot.Name, "Spec": groupSnapshot.Spec})
+	logCtx.Debug("Submit a VolumeGroupSnapshot")
+
+	volumes, err := m.getGroupSnapshotSourceVolumes(groupSnapshot.Spec.SourceVolumeGroup)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get volumes of the group")
+		if groupSnapshot.Status.Message != err.Error() {
+			groupSnapshot.Status.Message = err.Error()
+			if updateErr := m.apiClient.Status().Update(context.TODO(), groupSnapshot); updateErr != nil {
+				return updateErr
+			}
+		}
+		return err
+	}
+
+	quiesce := groupSnapshot.Spec.Quiesce
+	if quiesce == nil {
+		quiesce = &apisv1alpha1.SnapshotQuiesce{
+			Mode:           apisv1alpha1.SnapshotQuiesceModeFSFreeze,
+			TimeoutSeconds: apisv1alpha1.SnapshotQuiesceDefaultTimeoutSeconds,
+		}
+	}
+
+	var volumeSnapshots []string
+	for _, volume := range volumes {
+		volumeSnapshot := &apisv1alpha1.LocalVolumeSnapshot{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("%s-%s", groupSnapshot.Name, volume.Name),
+				Labels: map[string]string{apisv1alpha1.VolumeGroupSnapshotLabelKey: groupSnapshot.Name},
+			},
+			Spec: apisv1alpha1.LocalVolumeSnapshotSpec{
+				SourceVolume:          volume.Name,
+				Accessibility:         volume.Spec.Accessibility,
+				RequiredCapacityBytes: volume.Status.AllocatedCapacityBytes,
+				Thin:                  volume.Spec.Thin,
+				Quiesce:               quiesce,
+			},
+		}
+		if err = m.apiClient.Create(context.TODO(), volumeSnapshot); err != nil && !errors.IsAlreadyExists(err) {
+			logCtx.WithField("VolumeSnapshot", volumeSnapshot.Name).WithError(err).Error("Failed to create member VolumeSnapshot")
+			return err
+		}
+		volumeSnapshots = append(volumeSnapshots, volumeSnapshot.Name)
+	}
+
+	groupSnapshot.Status.VolumeSnapshots = volumeSnapshots
+	groupSnapshot.Status.NodeName = volumes[0].Spec.Accessibility.Nodes[0]
+	groupSnapshot.Status.State = apisv1alpha1.VolumeStateCreating
+	groupSnapshot.Status.Message = ""
+	return m.apiClient.Status().Update(context.TODO(), groupSnapshot)
+}
+
+// getGroupSnapshotSourceVolumes returns the volumes in the group, they must be ready and located at the same single node
+func (m *manager) getGroupSnapshotSourceVolumes(volumeGroupName string) ([]*apisv1alpha1.LocalVolume, error) {
+	volumeGroup := &apisv1alpha1.LocalVolumeGroup{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeGroupName}, volumeGroup); err != nil {
+		return nil, err
+	}
+	if len(volumeGroup.Spec.Volumes) == 0 {
+		return nil, fmt.Errorf("no volume in the group")
+	}
+
+	var (
+		volumes  []*apisv1alpha1.LocalVolume
+		nodeName string
+	)
+	for _, volumeInfo := range volumeGroup.Spec.Volumes {
+		volume := &apisv1alpha1.LocalVolume{}
+		if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeInfo.LocalVolumeName}, volume); err != nil {
+			return nil, err
+		}
+		if volume.Status.State != apisv1alpha1.VolumeStateReady {
+			return nil, fmt.Errorf("volume %s is not ready", volume.Name)
+		}
+		// for now, we only support take snapshot on single replica volume
+		if len(volume.Spec.Accessibility.Nodes) != 1 {
+			return nil, fmt.Errorf("haven't support take snapshot on HA-Volume %s", volume.Name)
+		}
+		if len(nodeName) == 0 {
+			nodeName = volume.Spec.Accessibility.Nodes[0]
+		} else if nodeName != volume.Spec.Accessibility.Nodes[0] {
+			return nil, fmt.Errorf("volumes of the group are not located at the same node")
+		}
+		volumes = append(volumes, volume)
+	}
+	return volumes, nil
+}
+
+func (m *manager) volumeGroupSnapshotReadyOrNot(groupSnapshot *apisv1alpha1.LocalVolumeGroupSnapshot) error {
+	logCtx := m.logger.WithFields(log.Fields{"GroupSnapshot": groupSnapshot.Name, "Spec": groupSnapshot.Spec})
+	logCtx.Debug("Check a VolumeGroupSnapshot status")
+
+	var (
+		message      string
+		readyCount   int
+		creationTime *metav1.Time
+	)
+	for _, volumeSnapshotName := range groupSnapshot.Status.VolumeSnapshots {
+		volumeSnapshot := &apisv1alpha1.LocalVolumeSnapshot{}
+		if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeSnapshotName}, volumeSnapshot); err != nil {
+			if !errors.IsNotFound(err) {
+				logCtx.WithField("VolumeSnapshot", volumeSnapshotName).WithError(err).Error("Failed to get member VolumeSnapshot")
+				return err
+			}
+			message += fmt.Sprintf("%s is not found;", volumeSnapshotName)
+			continue
+		}
+
+		if volumeSnapshot.Status.State == apisv1alpha1.VolumeStateReady {
+			readyCount++
+			if creationTime == nil || (volumeSnapshot.Status.CreationTime != nil && creationTime.Before(volumeSnapshot.Status.CreationTime)) {
+				creationTime = volumeSnapshot.Status.CreationTime
+			}
+			continue
+		}
+		if volumeSnapshot.Status.Message != "" {
+			message += fmt.Sprintf("%s: %s;", volumeSnapshotName, volumeSnapshot.Status.Message)
+		} else {
+			message += fmt.Sprintf("%s is %s;", volumeSnapshotName, volumeSnapshot.Status.State)
+		}
+	}
+
+	state := groupSnapshot.Status.State
+	if readyCount == len(groupSnapshot.Status.VolumeSnapshots) {
+		state = apisv1alpha1.VolumeStateReady
+		groupSnapshot.Status.CreationTime = creationTime
+	} else if state == apisv1alpha1.VolumeStateReady {
+		// some member snapshot is broken after the group snapshot is ready
+		state = apisv1alpha1.VolumeStateNotReady
+	}
+	if state == groupSnapshot.Status.State && message == groupSnapshot.Status.Message {
+		return nil
+	}
+	groupSnapshot.Status.State = state
+	groupSnapshot.Status.Message = message
+	return m.apiClient.Status().Update(context.TODO(), groupSnapshot)
+}
+
+func (m *manager) volumeGroupSnapshotDelete(groupSnapshot *apisv1alpha1.LocalVolumeGroupSnapshot) error {
+	logCtx := m.logger.WithFields(log.Fields{"GroupSnapshot": groupSnapshot.Name, "Spec": groupSnapshot.Spec})
+	logCtx.Debug("Delete a VolumeGroupSnapshot")
+
+	remaining := 0
+	for _, volumeSnapshotName := range groupSnapshot.Status.VolumeSnapshots {
+		volumeSnapshot := &apisv1alpha1.LocalVolumeSnapshot{}
+		if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeSnapshotName}, volumeSnapshot); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			logCtx.WithField("VolumeSnapshot", volumeSnapshotName).WithError(err).Error("Failed to get member VolumeSnapshot")
+			return err
+		}
+		remaining++
+		if !volumeSnapshot.Spec.Delete {
+			volumeSnapshot.Spec.Delete = true
+			if err := m.apiClient.Update(context.TODO(), volumeSnapshot); err != nil {
+				logCtx.WithField("VolumeSnapshot", volumeSnapshotName).WithError(err).Error("Failed to delete member VolumeSnapshot")
+				return err
+			}
+		}
+	}
+
+	if remaining > 0 {
+		err := fmt.Errorf("remaining %d member VolumeSnapshot to delete", remaining)
+		logCtx.WithError(err).Info("VolumeGroupSnapshot is deleting")
+		return err
+	}
+	return m.apiClient.Delete(context.TODO(), groupSnapshot)
+}
+
+func (m *manager) startVolumeGroupSnapshotRestoreTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("Volume Group Snapshot Restore Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.volumeGroupSnapshotRestoreTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the Volume Group Snapshot Restore worker")
+				break
+			}
+			if err := m.processVolumeGroupSnapshotRestore(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.volumeGroupSnapshotRestoreTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process Volume Group Snapshot Restore task, retry later")
+				m.volumeGroupSnapshotRestoreTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a Volume Group Snapshot Restore task.")
+				m.volumeGroupSnapshotRestoreTaskQueue.Forget(task)
+			}
+			m.volumeGroupSnapshotRestoreTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.volumeGroupSnapshotRestoreTaskQueue.Shutdown()
+}
+
+func (m *manager) processVolumeGroupSnapshotRestore(restoreName string) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeGroupSnapshotRestore": restoreName})
+	logCtx.Debug("Working on a VolumeGroupSnapshotRestore task")
+	groupRestore := &apisv1alpha1.LocalVolumeGroupSnapshotRestore{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: restoreName}, groupRestore); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get VolumeGroupSnapshotRestore from cache")
+			return err
+		}
+		logCtx.Info("Not found the VolumeGroupSnapshotRestore from cache, should be deleted already")
+		return nil
+	}
+
+	// log with namespace/name is enough
+	logCtx = m.logger.WithFields(log.Fields{"GroupSnapshotRestore": groupRestore.Name, "Spec": groupRestore.Spec, "Status": groupRestore.Status})
+	logCtx.Debug("Starting to process a VolumeGroupSnapshotRestore")
+
+	// state chain: (empty) -> Submitted -> InProgress -> Completed/Failed
+	switch groupRestore.Status.State {
+	case "":
+		return m.volumeGroupSnapshotRestoreSubmit(groupRestore)
+	case apisv1alpha1.OperationStateSubmitted:
+		return m.volumeGroupSnapshotRestoreStart(groupRestore)
+	case apisv1alpha1.OperationStateInProgress:
+		return m.checkInProgressVolumeGroupSnapshotRestore(groupRestore)
+	case apisv1alpha1.OperationStateCompleted, apisv1alpha1.OperationStateFailed:
+		return nil
+	default:
+		logCtx.Error("Invalid state/phase")
+	}
+	return fmt.Errorf("invalid state")
+}
+
+func (m *manager) volumeGroupSnapshotRestoreSubmit(groupRestore *apisv1alpha1.LocalVolumeGroupSnapshotRestore) error {
+	logCtx := m.logger.WithFields(log.Fields{"GroupSnapshotRestore": groupRestore.Name, "Spec": groupRestore.Spec})
+	logCtx.Debug("Submit a VolumeGroupSnapshotRestore")
+
+	groupSnapshot := &apisv1alpha1.LocalVolumeGroupSnapshot{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: groupRestore.Spec.SourceGroupSnapshot}, groupSnapshot); err != nil {
+		logCtx.WithError(err).Error("Failed to get source VolumeGroupSnapshot")
+		return err
+	}
+	if groupSnapshot.Status.State != apisv1alpha1.VolumeStateReady {
+		err := fmt.Errorf("source VolumeGroupSnapshot %s is not ready", groupSnapshot.Name)
+		logCtx.WithError(err).Error("Failed to submit VolumeGroupSnapshotRestore")
+		return err
+	}
+
+	var targetVolumes []string
+	for _, volumeSnapshotName := range groupSnapshot.Status.VolumeSnapshots {
+		sourceVolume, err := m.getSourceVolumeFromSnapshot(volumeSnapshotName)
+		if err != nil {
+			logCtx.WithField("VolumeSnapshot", volumeSnapshotName).WithError(err).Error("Failed to get source volume of member VolumeSnapshot")
+			return err
+		}
+		if groupRestore.Spec.RestoreType == apisv1alpha1.RestoreTypeRollback {
+			targetVolumes = append(targetVolumes, sourceVolume.Name)
+			continue
+		}
+
+		// the target volume must be a new one, to avoid overwriting the data in use
+		targetVolumeName := fmt.Sprintf("%s-%s", groupRestore.Name, sourceVolume.Name)
+		if err = m.apiClient.Get(context.TODO(), client.ObjectKey{Name: targetVolumeName}, &apisv1alpha1.LocalVolume{}); err == nil {
+			groupRestore.Status.State = apisv1alpha1.OperationStateFailed
+			groupRestore.Status.Message = fmt.Sprintf("target volume %s already exists", targetVolumeName)
+			return m.apiClient.Status().Update(context.TODO(), groupRestore)
+		} else if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get target volume")
+			return err
+		}
+		targetVolumes = append(targetVolumes, targetVolumeName)
+	}
+
+	groupRestore.Status.TargetVolumes = targetVolumes
+	groupRestore.Status.State = apisv1alpha1.OperationStateSubmitted
+	return m.apiClient.Status().Update(context.TODO(), groupRestore)
+}
+
+// volumeGroupSnapshotRestoreStart creates a member restore for each member snapshot.
+// For the create type, the target volumes are created and must be all ready before any member restore starts
+func (m *manager) volumeGroupSnapshotRestoreStart(groupRestore *apisv1alpha1.LocalVolumeGroupSnapshotRestore) error {
+	logCtx := m.logger.WithFields(log.Fields{"GroupSnapshotRestore": groupRestore.Name, "Spec": groupRestore.Spec})
+	logCtx.Debug("Start a VolumeGroupSnapshotRestore")
+
+	groupSnapshot := &apisv1alpha1.LocalVolumeGroupSnapshot{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: groupRestore.Spec.SourceGroupSnapshot}, groupSnapshot); err != nil {
+		logCtx.WithError(err).Error("Failed to get source VolumeGroupSnapshot")
+		return err
+	}
+
+	if groupRestore.Spec.RestoreType == apisv1alpha1.RestoreTypeCreate {
+		if err := m.createGroupSnapshotRestoreTargetVolumes(groupRestore, groupSnapshot); err != nil {
+			logCtx.WithError(err).Error("Target volumes are not ready")
+			return err
+		}
+	}
+
+	var volumeSnapshotRestores []string
+	for i, volumeSnapshotName := range groupSnapshot.Status.VolumeSnapshots {
+		sourceVolume, err := m.getSourceVolumeFromSnapshot(volumeSnapshotName)
+		if err != nil {
+			logCtx.WithField("VolumeSnapshot", volumeSnapshotName).WithError(err).Error("Failed to get source volume of member VolumeSnapshot")
+			return err
+		}
+		snapshotRestore := &apisv1alpha1.LocalVolumeSnapshotRestore{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   fmt.Sprintf("%s-%s", groupRestore.Name, sourceVolume.Name),
+				Labels: map[string]string{apisv1alpha1.VolumeGroupSnapshotRestoreLabelKey: groupRestore.Name},
+				// hold the member restore until the group restore knows it's completed
+				Finalizers: []string{apisv1alpha1.SnapshotRestoringFinalizer},
+			},
+			Spec: apisv1alpha1.LocalVolumeSnapshotRestoreSpec{
+				SourceVolumeSnapshot: volumeSnapshotName,
+				RestoreType:          groupRestore.Spec.RestoreType,
+				TargetVolume:         groupRestore.Status.TargetVolumes[i],
+				TargetPoolName:       sourceVolume.Spec.PoolName,
+			},
+		}
+		if err = m.apiClient.Create(context.TODO(), snapshotRestore); err != nil && !errors.IsAlreadyExists(err) {
+			logCtx.WithField("VolumeSnapshotRestore", snapshotRestore.Name).WithError(err).Error("Failed to create member VolumeSnapshotRestore")
+			return err
+		}
+		volumeSnapshotRestores = append(volumeSnapshotRestores, snapshotRestore.Name)
+	}
+
+	groupRestore.Status.VolumeSnapshotRestores = volumeSnapshotRestores
+	groupRestore.Status.State = apisv1alpha1.OperationStateInProgress
+	return m.apiClient.Status().Update(context.TODO(), groupRestore)
+}
+
+func (m *manager) createGroupSnapshotRestoreTargetVolumes(groupRestore *apisv1alpha1.LocalVolumeGroupSnapshotRestore, groupSnapshot *apisv1alpha1.LocalVolumeGroupSnapshot) error {
+	notReady := 0
+	for i, volumeSnapshotName := range groupSnapshot.Status.VolumeSnapshots {
+		sourceVolume, err := m.getSourceVolumeFromSnapshot(volumeSnapshotName)
+		if err != nil {
+			return err
+		}
+
+		targetVolume := &apisv1alpha1.LocalVolume{}
+		if err = m.apiClient.Get(context.TODO(), client.ObjectKey{Name: groupRestore.Status.TargetVolumes[i]}, targetVolume); err == nil {
+			if targetVolume.Status.State != apisv1alpha1.VolumeStateReady {
+				notReady++
+			}
+			continue
+		} else if !errors.IsNotFound(err) {
+			return err
+		}
+
+		// the snapshot can only be restored on the node where it is
+		targetVolume = &apisv1alpha1.LocalVolume{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        groupRestore.Status.TargetVolumes[i],
+				Annotations: map[string]string{apisv1alpha1.SourceVolumeSnapshotAnnoKey: volumeSnapshotName},
+			},
+			Spec: apisv1alpha1.LocalVolumeSpec{
+				RequiredCapacityBytes: sourceVolume.Spec.RequiredCapacityBytes,
+				PoolName:              sourceVolume.Spec.PoolName,
+				ReplicaNumber:         1,
+				Accessibility:         apisv1alpha1.AccessibilityTopology{Nodes: []string{groupSnapshot.Status.NodeName}},
+			},
+		}
+		if err = m.apiClient.Create(context.TODO(), targetVolume); err != nil && !errors.IsAlreadyExists(err) {
+			return err
+		}
+		notReady++
+	}
+
+	if notReady > 0 {
+		return fmt.Errorf("remaining %d target volumes to be ready", notReady)
+	}
+	return nil
+}
+
+func (m *manager) checkInProgressVolumeGroupSnapshotRestore(groupRestore *apisv1alpha1.LocalVolumeGroupSnapshotRestore) error {
+	logCtx := m.logger.WithFields(log.Fields{"GroupSnapshotRestore": groupRestore.Name, "Spec": groupRestore.Spec})
+	logCtx.Debug("Check a InProgress VolumeGroupSnapshotRestore")
+
+	var (
+		message                 string
+		completedCount, failure int
+		snapshotRestores        []*apisv1alpha1.LocalVolumeSnapshotRestore
+	)
+	for _, snapshotRestoreName := range groupRestore.Status.VolumeSnapshotRestores {
+		snapshotRestore := &apisv1alpha1.LocalVolumeSnapshotRestore{}
+		if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: snapshotRestoreName}, snapshotRestore); err != nil {
+			logCtx.WithField("VolumeSnapshotRestore", snapshotRestoreName).WithError(err).Error("Failed to get member VolumeSnapshotRestore")
+			return err
+		}
+		snapshotRestores = append(snapshotRestores, snapshotRestore)
+
+		switch snapshotRestore.Status.State {
+		case apisv1alpha1.OperationStateCompleted:
+			completedCount++
+		case apisv1alpha1.OperationStateAborted, apisv1alpha1.OperationStateFailed:
+			failure++
+		}
+		if snapshotRestore.Status.Message != "" {
+			message += fmt.Sprintf("%s: %s;", snapshotRestoreName, snapshotRestore.Status.Message)
+		} else {
+			message += fmt.Sprintf("%s is %s;", snapshotRestoreName, snapshotRestore.Status.State)
+		}
+	}
+
+	groupRestore.Status.Message = message
+	switch {
+	case failure > 0:
+		groupRestore.Status.State = apisv1alpha1.OperationStateFailed
+	case completedCount == len(snapshotRestores):
+		groupRestore.Status.State = apisv1alpha1.OperationStateCompleted
+	default:
+		return m.apiClient.Status().Update(context.TODO(), groupRestore)
+	}
+
+	// release the member restores to be cleaned up
+	for _, snapshotRestore := range snapshotRestores {
+		finalizers := utils.RemoveStringItem(snapshotRestore.Finalizers, apisv1alpha1.SnapshotRestoringFinalizer)
+		if len(finalizers) == len(snapshotRestore.Finalizers) {
+			continue
+		}
+		snapshotRestore.SetFinalizers(finalizers)
+		if err := m.apiClient.Update(context.TODO(), snapshotRestore); err != nil {
+			logCtx.WithField("VolumeSnapshotRestore", snapshotRestore.Name).WithError(err).Error("Failed to release member VolumeSnapshotRestore")
+			return err
+		}
+	}
+	return m.apiClient.Status().Update(context.TODO(), groupRestore)
+}
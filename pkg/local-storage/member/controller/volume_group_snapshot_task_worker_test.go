@@ -0,0 +1,282 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+func newFakeGroupSnapshotManager(objs ...client.Object) *manager {
+	s := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(s)
+	_ = v1alpha1.AddToScheme(s)
+	return &manager{
+		apiClient: fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build(),
+		logger:    log.WithField("Module", "ControllerManager"),
+	}
+}
+
+func newFakeGroupVolumes(nodes ...string) (*v1alpha1.LocalVolumeGroup, []client.Object) {
+	volumeGroup := &v1alpha1.LocalVolumeGroup{ObjectMeta: metav1.ObjectMeta{Name: "lvg-1"}}
+	objs := []client.Object{volumeGroup}
+	for i, node := range nodes {
+		volume := &v1alpha1.LocalVolume{ObjectMeta: metav1.ObjectMeta{Name: []string{"pvc-1", "pvc-2", "pvc-3"}[i]}}
+		volume.Spec.VolumeGroup = volumeGroup.Name
+		volume.Spec.PoolName = v1alpha1.PoolNameForHDD
+		volume.Spec.RequiredCapacityBytes = 1073741824
+		volume.Spec.Accessibility.Nodes = []string{node}
+		volume.Status.State = v1alpha1.VolumeStateReady
+		volume.Status.AllocatedCapacityBytes = 1073741824
+		volumeGroup.Spec.Volumes = append(volumeGroup.Spec.Volumes, v1alpha1.VolumeInfo{LocalVolumeName: volume.Name})
+		objs = append(objs, volume)
+	}
+	return volumeGroup, objs
+}
+
+func Test_manager_processVolumeGroupSnapshot(t *testing.T) {
+	volumeGroup, objs := newFakeGroupVolumes("node1", "node1")
+	groupSnapshot := &v1alpha1.LocalVolumeGroupSnapshot{ObjectMeta: metav1.ObjectMeta{Name: "snap-1"}}
+	groupSnapshot.Spec.SourceVolumeGroup = volumeGroup.Name
+	m := newFakeGroupSnapshotManager(append(objs, groupSnapshot)...)
+
+	// submit the member snapshots
+	if err := m.processVolumeGroupSnapshot(groupSnapshot.Name); err != nil {
+		t.Fatalf("processVolumeGroupSnapshot() error = %v", err)
+	}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: groupSnapshot.Name}, groupSnapshot); err != nil {
+		t.Fatal(err)
+	}
+	wantMembers := []string{"snap-1-pvc-1", "snap-1-pvc-2"}
+	if !reflect.DeepEqual(groupSnapshot.Status.VolumeSnapshots, wantMembers) {
+		t.Errorf("VolumeSnapshots = %v, want %v", groupSnapshot.Status.VolumeSnapshots, wantMembers)
+	}
+	if groupSnapshot.Status.State != v1alpha1.VolumeStateCreating || groupSnapshot.Status.NodeName != "node1" {
+		t.Errorf("got state %s on node %s", groupSnapshot.Status.State, groupSnapshot.Status.NodeName)
+	}
+	for _, member := range wantMembers {
+		volumeSnapshot := &v1alpha1.LocalVolumeSnapshot{}
+		if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: member}, volumeSnapshot); err != nil {
+			t.Fatalf("member VolumeSnapshot %s is not created: %v", member, err)
+		}
+		if volumeSnapshot.Labels[v1alpha1.VolumeGroupSnapshotLabelKey] != groupSnapshot.Name {
+			t.Errorf("member VolumeSnapshot %s is not labeled with the group snapshot", member)
+		}
+		if volumeSnapshot.Spec.Quiesce == nil || volumeSnapshot.Spec.Quiesce.Mode != v1alpha1.SnapshotQuiesceModeFSFreeze {
+			t.Errorf("member VolumeSnapshot %s is not frozen by default, got %v", member, volumeSnapshot.Spec.Quiesce)
+		}
+	}
+
+	// the group snapshot is ready only when all the members are ready
+	setVolumeSnapshotState := func(name string, state v1alpha1.State) {
+		volumeSnapshot := &v1alpha1.LocalVolumeSnapshot{}
+		if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: name}, volumeSnapshot); err != nil {
+			t.Fatal(err)
+		}
+		volumeSnapshot.Status.State = state
+		volumeSnapshot.Status.CreationTime = &metav1.Time{}
+		if err := m.apiClient.Status().Update(context.TODO(), volumeSnapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, member := range wantMembers {
+		setVolumeSnapshotState(member, v1alpha1.VolumeStateReady)
+		if err := m.processVolumeGroupSnapshot(groupSnapshot.Name); err != nil {
+			t.Fatalf("processVolumeGroupSnapshot() error = %v", err)
+		}
+		if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: groupSnapshot.Name}, groupSnapshot); err != nil {
+			t.Fatal(err)
+		}
+		wantState := v1alpha1.VolumeStateCreating
+		if i == len(wantMembers)-1 {
+			wantState = v1alpha1.VolumeStateReady
+		}
+		if groupSnapshot.Status.State != wantState {
+			t.Errorf("got state %s with %d ready members, want %s", groupSnapshot.Status.State, i+1, wantState)
+		}
+	}
+
+	// delete the members before the group snapshot
+	groupSnapshot.Spec.Delete = true
+	if err := m.apiClient.Update(context.TODO(), groupSnapshot); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.processVolumeGroupSnapshot(groupSnapshot.Name); err != nil {
+		t.Fatalf("processVolumeGroupSnapshot() error = %v", err)
+	}
+	if err := m.processVolumeGroupSnapshot(groupSnapshot.Name); err == nil {
+		t.Errorf("processVolumeGroupSnapshot() deleted the group snapshot with remaining members")
+	}
+	for _, member := range wantMembers {
+		volumeSnapshot := &v1alpha1.LocalVolumeSnapshot{}
+		if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: member}, volumeSnapshot); err != nil {
+			t.Fatal(err)
+		}
+		if !volumeSnapshot.Spec.Delete {
+			t.Errorf("member VolumeSnapshot %s is not deleted", member)
+		}
+		if err := m.apiClient.Delete(context.TODO(), volumeSnapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.processVolumeGroupSnapshot(groupSnapshot.Name); err != nil {
+		t.Fatalf("processVolumeGroupSnapshot() error = %v", err)
+	}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: groupSnapshot.Name}, groupSnapshot); err == nil {
+		t.Errorf("group snapshot is not deleted")
+	}
+}
+
+func Test_manager_getGroupSnapshotSourceVolumes(t *testing.T) {
+	tests := []struct {
+		name    string
+		nodes   []string
+		wantErr bool
+	}{
+		{
+			name:  "volumes on the same node",
+			nodes: []string{"node1", "node1", "node1"},
+		},
+		{
+			name:    "volumes on different nodes",
+			nodes:   []string{"node1", "node2"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			volumeGroup, objs := newFakeGroupVolumes(tt.nodes...)
+			m := newFakeGroupSnapshotManager(objs...)
+			volumes, err := m.getGroupSnapshotSourceVolumes(volumeGroup.Name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getGroupSnapshotSourceVolumes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && len(volumes) != len(tt.nodes) {
+				t.Errorf("getGroupSnapshotSourceVolumes() got %d volumes, want %d", len(volumes), len(tt.nodes))
+			}
+		})
+	}
+}
+
+func Test_manager_processVolumeGroupSnapshotRestore(t *testing.T) {
+	_, objs := newFakeGroupVolumes("node1", "node1")
+	groupSnapshot := &v1alpha1.LocalVolumeGroupSnapshot{ObjectMeta: metav1.ObjectMeta{Name: "snap-1"}}
+	groupSnapshot.Spec.SourceVolumeGroup = "lvg-1"
+	groupSnapshot.Status.VolumeSnapshots = []string{"snap-1-pvc-1", "snap-1-pvc-2"}
+	groupSnapshot.Status.NodeName = "node1"
+	groupSnapshot.Status.State = v1alpha1.VolumeStateReady
+	objs = append(objs, groupSnapshot)
+	for i, member := range groupSnapshot.Status.VolumeSnapshots {
+		volumeSnapshot := &v1alpha1.LocalVolumeSnapshot{ObjectMeta: metav1.ObjectMeta{Name: member}}
+		volumeSnapshot.Spec.SourceVolume = []string{"pvc-1", "pvc-2"}[i]
+		objs = append(objs, volumeSnapshot)
+	}
+
+	tests := []struct {
+		name              string
+		restoreType       v1alpha1.RestoreType
+		wantTargetVolumes []string
+	}{
+		{
+			name:              "rollback",
+			restoreType:       v1alpha1.RestoreTypeRollback,
+			wantTargetVolumes: []string{"pvc-1", "pvc-2"},
+		},
+		{
+			name:              "create",
+			restoreType:       v1alpha1.RestoreTypeCreate,
+			wantTargetVolumes: []string{"restore-1-pvc-1", "restore-1-pvc-2"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groupRestore := &v1alpha1.LocalVolumeGroupSnapshotRestore{ObjectMeta: metav1.ObjectMeta{Name: "restore-1"}}
+			groupRestore.Spec.SourceGroupSnapshot = groupSnapshot.Name
+			groupRestore.Spec.RestoreType = tt.restoreType
+			m := newFakeGroupSnapshotManager(append(objs, groupRestore)...)
+
+			getGroupRestore := func() {
+				if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: groupRestore.Name}, groupRestore); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// submit
+			if err := m.processVolumeGroupSnapshotRestore(groupRestore.Name); err != nil {
+				t.Fatalf("processVolumeGroupSnapshotRestore() error = %v", err)
+			}
+			getGroupRestore()
+			if !reflect.DeepEqual(groupRestore.Status.TargetVolumes, tt.wantTargetVolumes) {
+				t.Errorf("TargetVolumes = %v, want %v", groupRestore.Status.TargetVolumes, tt.wantTargetVolumes)
+			}
+
+			// the new target volumes must be ready before restoring
+			if tt.restoreType == v1alpha1.RestoreTypeCreate {
+				if err := m.processVolumeGroupSnapshotRestore(groupRestore.Name); err == nil {
+					t.Fatalf("processVolumeGroupSnapshotRestore() started with target volumes not ready")
+				}
+				for _, targetVolumeName := range tt.wantTargetVolumes {
+					targetVolume := &v1alpha1.LocalVolume{}
+					if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: targetVolumeName}, targetVolume); err != nil {
+						t.Fatalf("target volume %s is not created: %v", targetVolumeName, err)
+					}
+					if !reflect.DeepEqual(targetVolume.Spec.Accessibility.Nodes, []string{"node1"}) {
+						t.Errorf("target volume %s is not on the node of the group snapshot", targetVolumeName)
+					}
+					targetVolume.Status.State = v1alpha1.VolumeStateReady
+					if err := m.apiClient.Status().Update(context.TODO(), targetVolume); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			// start the member restores
+			if err := m.processVolumeGroupSnapshotRestore(groupRestore.Name); err != nil {
+				t.Fatalf("processVolumeGroupSnapshotRestore() error = %v", err)
+			}
+			getGroupRestore()
+			if groupRestore.Status.State != v1alpha1.OperationStateInProgress || len(groupRestore.Status.VolumeSnapshotRestores) != 2 {
+				t.Fatalf("got state %s with member restores %v", groupRestore.Status.State, groupRestore.Status.VolumeSnapshotRestores)
+			}
+
+			// complete the member restores one by one
+			for i, member := range groupRestore.Status.VolumeSnapshotRestores {
+				snapshotRestore := &v1alpha1.LocalVolumeSnapshotRestore{}
+				if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: member}, snapshotRestore); err != nil {
+					t.Fatal(err)
+				}
+				if snapshotRestore.Spec.TargetVolume != tt.wantTargetVolumes[i] {
+					t.Errorf("member restore %s got target volume %s, want %s", member, snapshotRestore.Spec.TargetVolume, tt.wantTargetVolumes[i])
+				}
+				snapshotRestore.Status.State = v1alpha1.OperationStateCompleted
+				if err := m.apiClient.Status().Update(context.TODO(), snapshotRestore); err != nil {
+					t.Fatal(err)
+				}
+				if err := m.processVolumeGroupSnapshotRestore(groupRestore.Name); err != nil {
+					t.Fatalf("processVolumeGroupSnapshotRestore() error = %v", err)
+				}
+			}
+			getGroupRestore()
+			if groupRestore.Status.State != v1alpha1.OperationStateCompleted {
+				t.Errorf("got state %s, want %s", groupRestore.Status.State, v1alpha1.OperationStateCompleted)
+			}
+			for _, member := range groupRestore.Status.VolumeSnapshotRestores {
+				snapshotRestore := &v1alpha1.LocalVolumeSnapshotRestore{}
+				if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: member}, snapshotRestore); err != nil {
+					t.Fatal(err)
+				}
+				if len(snapshotRestore.Finalizers) > 0 {
+					t.Errorf("member restore %s is not released, got finalizers %v", member, snapshotRestore.Finalizers)
+				}
+			}
+		})
+	}
+}
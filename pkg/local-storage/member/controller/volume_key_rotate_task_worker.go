@@ -0,0 +1,346 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/encrypt"
+)
+
+func (m *manager) startVolumeKeyRotateTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("Volume KeyRotate Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.volumeKeyRotateTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the Volume KeyRotate worker")
+				break
+			}
+			if err := m.processVolumeKeyRotate(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.volumeKeyRotateTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process Volume KeyRotate task, retry later")
+				m.volumeKeyRotateTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a Volume KeyRotate task.")
+				m.volumeKeyRotateTaskQueue.Forget(task)
+			}
+			m.volumeKeyRotateTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.volumeKeyRotateTaskQueue.Shutdown()
+}
+
+func (m *manager) processVolumeKeyRotate(keyRotateName string) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeKeyRotate": keyRotateName})
+	logCtx.Debug("Working on a VolumeKeyRotate task")
+	keyRotate := &apisv1alpha1.LocalVolumeKeyRotate{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: keyRotateName}, keyRotate); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get VolumeKeyRotate from cache")
+			return err
+		}
+		logCtx.Info("Not found the VolumeKeyRotate from cache, should be deleted already")
+		return nil
+	}
+
+	// it can't be aborted any more after the volume switches to the new key
+	if keyRotate.Spec.Abort &&
+		(keyRotate.Status.State == "" ||
+			keyRotate.Status.State == apisv1alpha1.OperationStateSubmitted ||
+			keyRotate.Status.State == apisv1alpha1.OperationStateKeyRotateAddKey) {
+
+		keyRotate.Status.State = apisv1alpha1.OperationStateToBeAborted
+		return m.apiClient.Status().Update(context.TODO(), keyRotate)
+	}
+
+	logCtx = m.logger.WithFields(log.Fields{"VolumeKeyRotate": keyRotate.Name, "Spec": keyRotate.Spec, "Status": keyRotate.Status})
+	logCtx.Debug("Starting to process a VolumeKeyRotate")
+
+	// state chain: (empty) -> Submitted -> AddKey -> RemoveKey -> Completed/Failed
+	// the nodes add and verify the new key on all the replicas when AddKey, then the volume switches to the new key,
+	// and the nodes remove the old key when RemoveKey. The added keys are removed by Rollback if failed or aborted
+	switch keyRotate.Status.State {
+	case "":
+		return m.volumeKeyRotateSubmit(keyRotate)
+	case apisv1alpha1.OperationStateSubmitted:
+		return m.volumeKeyRotateStart(keyRotate)
+	case apisv1alpha1.OperationStateKeyRotateAddKey:
+		return m.volumeKeyRotateCheckAddKey(keyRotate)
+	case apisv1alpha1.OperationStateKeyRotateRemoveKey:
+		return m.volumeKeyRotateCheckRemoveKey(keyRotate)
+	case apisv1alpha1.OperationStateToBeAborted:
+		return m.volumeKeyRotateAbort(keyRotate)
+	case apisv1alpha1.OperationStateRollback:
+		return m.volumeKeyRotateCheckRollback(keyRotate)
+	case apisv1alpha1.OperationStateCompleted, apisv1alpha1.OperationStateFailed, apisv1alpha1.OperationStateAborted:
+		return nil
+	default:
+		logCtx.Error("Invalid state/phase")
+	}
+	return fmt.Errorf("invalid state")
+}
+
+func (m *manager) volumeKeyRotateSubmit(keyRotate *apisv1alpha1.LocalVolumeKeyRotate) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeKeyRotate": keyRotate.Name, "Spec": keyRotate.Spec})
+	logCtx.Debug("Submit a VolumeKeyRotate")
+
+	volume := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: keyRotate.Spec.VolumeName}, volume); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get volume")
+			return err
+		}
+		return m.volumeKeyRotateFail(keyRotate, fmt.Sprintf("volume %s not found", keyRotate.Spec.VolumeName))
+	}
+	if !volume.Spec.VolumeEncrypt.Enable {
+		return m.volumeKeyRotateFail(keyRotate, fmt.Sprintf("volume %s is not encrypted", volume.Name))
+	}
+	if volume.Status.State != apisv1alpha1.VolumeStateReady {
+		err := fmt.Errorf("volume %s is not ready", volume.Name)
+		logCtx.WithError(err).Error("Failed to submit VolumeKeyRotate")
+		return err
+	}
+
+	if inProgressKeyRotate, err := m.getInProgressVolumeKeyRotate(volume.Name, keyRotate.Name); err != nil {
+		logCtx.WithError(err).Error("Failed to list VolumeKeyRotates")
+		return err
+	} else if inProgressKeyRotate != nil {
+		return m.volumeKeyRotateFail(keyRotate, fmt.Sprintf("volume key is being rotated by %s", inProgressKeyRotate.Name))
+	}
+
+	provider, err := encrypt.NewKeyProvider(m.apiClient, &volume.Spec.VolumeEncrypt)
+	if err != nil {
+		return m.volumeKeyRotateFail(keyRotate, err.Error())
+	}
+	newKey, err := getVolumeKeyRotateNewKey(keyRotate, &volume.Spec.VolumeEncrypt, provider)
+	if err != nil {
+		return m.volumeKeyRotateFail(keyRotate, err.Error())
+	}
+
+	keyRotate.Status.KeyProvider = volume.Spec.VolumeEncrypt.KeyProvider
+	if keyRotate.Status.KeyProvider == "" {
+		keyRotate.Status.KeyProvider = apisv1alpha1.VolumeEncryptKeyProviderSecret
+	}
+	keyRotate.Status.OldKey = encrypt.GetKeyRef(&volume.Spec.VolumeEncrypt)
+	keyRotate.Status.NewKey = newKey
+	keyRotate.Status.Replicas = nil
+	for _, nodeName := range getVolumeKeyRotateNodes(volume) {
+		keyRotate.Status.Replicas = append(keyRotate.Status.Replicas, apisv1alpha1.VolumeKeyRotateReplicaStatus{NodeName: nodeName})
+	}
+	keyRotate.Status.State = apisv1alpha1.OperationStateSubmitted
+	return m.apiClient.Status().Update(context.TODO(), keyRotate)
+}
+
+// getVolumeKeyRotateNewKey returns the reference of the new key. It's the Secret given by the user for the Secret provider,
+// and a new data key generated by the KMS for the KMS provider
+func getVolumeKeyRotateNewKey(keyRotate *apisv1alpha1.LocalVolumeKeyRotate, volumeEncrypt *apisv1alpha1.VolumeEncrypt, provider encrypt.KeyProvider) (string, error) {
+	if volumeEncrypt.KeyProvider == apisv1alpha1.VolumeEncryptKeyProviderKMS {
+		return provider.NewKey()
+	}
+
+	if keyRotate.Spec.NewSecret == "" {
+		return "", fmt.Errorf("newSecret is required to rotate the key held by Secret")
+	}
+	if keyRotate.Spec.NewSecret == volumeEncrypt.SecretNamespacedName {
+		return "", fmt.Errorf("newSecret %s is in use by the volume already", keyRotate.Spec.NewSecret)
+	}
+	if _, err := provider.GetKey(keyRotate.Spec.NewSecret); err != nil {
+		return "", fmt.Errorf("invalid newSecret %s: %v", keyRotate.Spec.NewSecret, err)
+	}
+	return keyRotate.Spec.NewSecret, nil
+}
+
+// getVolumeKeyRotateNodes returns the nodes to rotate the key on. The HA volume is encrypted on the DRBD device,
+// which can only be opened on the primary node, and the LUKS header is replicated to the other nodes by DRBD.
+// So the key of HA volume is rotated only on the node where the volume is published, or the primary node if not published
+func getVolumeKeyRotateNodes(volume *apisv1alpha1.LocalVolume) []string {
+	if volume.Spec.Convertible && volume.Spec.Config != nil && len(volume.Spec.Config.Replicas) > 0 {
+		if len(volume.Status.PublishedNodeName) > 0 {
+			return []string{volume.Status.PublishedNodeName}
+		}
+		for _, replica := range volume.Spec.Config.Replicas {
+			if replica.Primary {
+				return []string{replica.Hostname}
+			}
+		}
+		return []string{volume.Spec.Config.Replicas[0].Hostname}
+	}
+	return getVolumeReplicaNodes(volume)
+}
+
+// getVolumeReplicaNodes returns the nodes of the volume replicas
+func getVolumeReplicaNodes(volume *apisv1alpha1.LocalVolume) []string {
+	if volume.Spec.Config != nil && len(volume.Spec.Config.Replicas) > 0 {
+		var nodes []string
+		for _, replica := range volume.Spec.Config.Replicas {
+			nodes = append(nodes, replica.Hostname)
+		}
+		return nodes
+	}
+	return volume.Spec.Accessibility.Nodes
+}
+
+// getInProgressVolumeKeyRotate returns the other unfinished key rotation of the volume, nil if not found
+func (m *manager) getInProgressVolumeKeyRotate(volumeName string, excludedKeyRotate string) (*apisv1alpha1.LocalVolumeKeyRotate, error) {
+	keyRotateList := &apisv1alpha1.LocalVolumeKeyRotateList{}
+	if err := m.apiClient.List(context.TODO(), keyRotateList); err != nil {
+		return nil, err
+	}
+	for i, keyRotate := range keyRotateList.Items {
+		if keyRotate.Name == excludedKeyRotate || keyRotate.Spec.VolumeName != volumeName {
+			continue
+		}
+		switch keyRotate.Status.State {
+		case "", apisv1alpha1.OperationStateCompleted, apisv1alpha1.OperationStateFailed, apisv1alpha1.OperationStateAborted:
+		default:
+			return &keyRotateList.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *manager) volumeKeyRotateStart(keyRotate *apisv1alpha1.LocalVolumeKeyRotate) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeKeyRotate": keyRotate.Name, "Spec": keyRotate.Spec})
+	logCtx.Debug("Start a VolumeKeyRotate")
+
+	startTime := metav1.Now()
+	keyRotate.Status.StartTime = &startTime
+	keyRotate.Status.State = apisv1alpha1.OperationStateKeyRotateAddKey
+	return m.apiClient.Status().Update(context.TODO(), keyRotate)
+}
+
+func (m *manager) volumeKeyRotateCheckAddKey(keyRotate *apisv1alpha1.LocalVolumeKeyRotate) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeKeyRotate": keyRotate.Name, "Spec": keyRotate.Spec})
+	logCtx.Debug("Check the new key added on the replicas")
+
+	if failures := getVolumeKeyRotateFailures(keyRotate); len(failures) > 0 {
+		// remove the new key from the other replicas, the volume still uses the old one
+		keyRotate.Status.State = apisv1alpha1.OperationStateRollback
+		keyRotate.Status.Message = fmt.Sprintf("failed to add new key: %s", strings.Join(failures, "; "))
+		return m.apiClient.Status().Update(context.TODO(), keyRotate)
+	}
+	for _, replica := range keyRotate.Status.Replicas {
+		if replica.State != apisv1alpha1.OperationStateKeyRotateKeyAdded {
+			// wait for all the replicas to add the new key
+			return nil
+		}
+	}
+
+	// all the replicas can be unlocked by the new key now, switch the volume to it
+	if err := m.switchVolumeKey(keyRotate.Spec.VolumeName, keyRotate.Status.NewKey); err != nil {
+		logCtx.WithError(err).Error("Failed to switch volume to the new key")
+		return err
+	}
+
+	keyRotate.Status.State = apisv1alpha1.OperationStateKeyRotateRemoveKey
+	return m.apiClient.Status().Update(context.TODO(), keyRotate)
+}
+
+// switchVolumeKey sets the new key reference to the volume and all its replicas
+func (m *manager) switchVolumeKey(volumeName string, newKey string) error {
+	volume := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeName}, volume); err != nil {
+		return err
+	}
+	if encrypt.GetKeyRef(&volume.Spec.VolumeEncrypt) != newKey {
+		encrypt.SetKeyRef(&volume.Spec.VolumeEncrypt, newKey)
+		if err := m.apiClient.Update(context.TODO(), volume); err != nil {
+			return err
+		}
+	}
+
+	replicaList := &apisv1alpha1.LocalVolumeReplicaList{}
+	if err := m.apiClient.List(context.TODO(), replicaList); err != nil {
+		return err
+	}
+	for i := range replicaList.Items {
+		replica := &replicaList.Items[i]
+		if replica.Spec.VolumeName != volumeName || encrypt.GetKeyRef(&replica.Spec.VolumeEncrypt) == newKey {
+			continue
+		}
+		encrypt.SetKeyRef(&replica.Spec.VolumeEncrypt, newKey)
+		if err := m.apiClient.Update(context.TODO(), replica); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *manager) volumeKeyRotateCheckRemoveKey(keyRotate *apisv1alpha1.LocalVolumeKeyRotate) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeKeyRotate": keyRotate.Name, "Spec": keyRotate.Spec})
+	logCtx.Debug("Check the old key removed from the replicas")
+
+	for _, replica := range keyRotate.Status.Replicas {
+		if replica.State != apisv1alpha1.OperationStateCompleted && replica.State != apisv1alpha1.OperationStateFailed {
+			// wait for all the replicas to remove the old key
+			return nil
+		}
+	}
+
+	completionTime := metav1.Now()
+	keyRotate.Status.CompletionTime = &completionTime
+	if failures := getVolumeKeyRotateFailures(keyRotate); len(failures) > 0 {
+		// the volume uses the new key already, the old key should be removed manually
+		keyRotate.Status.State = apisv1alpha1.OperationStateFailed
+		keyRotate.Status.Message = fmt.Sprintf("failed to remove old key: %s", strings.Join(failures, "; "))
+	} else {
+		keyRotate.Status.State = apisv1alpha1.OperationStateCompleted
+		keyRotate.Status.Message = ""
+	}
+	return m.apiClient.Status().Update(context.TODO(), keyRotate)
+}
+
+func (m *manager) volumeKeyRotateAbort(keyRotate *apisv1alpha1.LocalVolumeKeyRotate) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeKeyRotate": keyRotate.Name, "Spec": keyRotate.Spec})
+	logCtx.Debug("Abort a VolumeKeyRotate")
+
+	keyRotate.Status.State = apisv1alpha1.OperationStateRollback
+	keyRotate.Status.Message = "aborted by user"
+	return m.apiClient.Status().Update(context.TODO(), keyRotate)
+}
+
+func (m *manager) volumeKeyRotateCheckRollback(keyRotate *apisv1alpha1.LocalVolumeKeyRotate) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeKeyRotate": keyRotate.Name, "Spec": keyRotate.Spec})
+	logCtx.Debug("Check the new key removed from the replicas")
+
+	for _, replica := range keyRotate.Status.Replicas {
+		if replica.State != apisv1alpha1.OperationStateAborted && replica.State != apisv1alpha1.OperationStateFailed {
+			// wait for all the replicas to remove the new key
+			return nil
+		}
+	}
+
+	completionTime := metav1.Now()
+	keyRotate.Status.CompletionTime = &completionTime
+	if keyRotate.Spec.Abort && len(getVolumeKeyRotateFailures(keyRotate)) == 0 {
+		keyRotate.Status.State = apisv1alpha1.OperationStateAborted
+	} else {
+		keyRotate.Status.State = apisv1alpha1.OperationStateFailed
+	}
+	return m.apiClient.Status().Update(context.TODO(), keyRotate)
+}
+
+func (m *manager) volumeKeyRotateFail(keyRotate *apisv1alpha1.LocalVolumeKeyRotate, message string) error {
+	keyRotate.Status.State = apisv1alpha1.OperationStateFailed
+	keyRotate.Status.Message = message
+	return m.apiClient.Status().Update(context.TODO(), keyRotate)
+}
+
+func getVolumeKeyRotateFailures(keyRotate *apisv1alpha1.LocalVolumeKeyRotate) []string {
+	var failures []string
+	for _, replica := range keyRotate.Status.Replicas {
+		if replica.State == apisv1alpha1.OperationStateFailed {
+			failures = append(failures, fmt.Sprintf("%s: %s", replica.NodeName, replica.Message))
+		}
+	}
+	return failures
+}
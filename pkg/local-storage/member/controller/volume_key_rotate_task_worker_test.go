@@ -0,0 +1,253 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+func newFakeKeyRotateManager(t *testing.T, objs ...client.Object) *manager {
+	s := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(s); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	if err := corev1.AddToScheme(s); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	return &manager{
+		apiClient: fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build(),
+		logger:    log.WithField("Module", "ControllerManager"),
+	}
+}
+
+func genFakeEncryptedVolume(name string, nodes ...string) *v1alpha1.LocalVolume {
+	volume := genFakeScrubVolume(name, len(nodes) > 1, false, nodes...)
+	volume.Spec.VolumeEncrypt = v1alpha1.VolumeEncrypt{Enable: true, Type: "LUKS", SecretNamespacedName: "hwameistor/old-secret"}
+	return volume
+}
+
+func genFakeEncryptSecret(name string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "hwameistor", Name: name},
+		Data:       map[string][]byte{"key": []byte(name)},
+	}
+}
+
+func getFakeKeyRotate(t *testing.T, m *manager, name string) *v1alpha1.LocalVolumeKeyRotate {
+	keyRotate := &v1alpha1.LocalVolumeKeyRotate{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: name}, keyRotate); err != nil {
+		t.Fatalf("Failed to get LocalVolumeKeyRotate: %v", err)
+	}
+	return keyRotate
+}
+
+func Test_manager_volumeKeyRotateSubmit(t *testing.T) {
+	plainVolume := genFakeScrubVolume("pvc-plain", false, false, "node1")
+	tests := []struct {
+		name         string
+		spec         v1alpha1.LocalVolumeKeyRotateSpec
+		wantState    v1alpha1.State
+		wantReplicas int
+	}{
+		{
+			name:         "rotate to new secret",
+			spec:         v1alpha1.LocalVolumeKeyRotateSpec{VolumeName: "pvc-1", NewSecret: "hwameistor/new-secret"},
+			wantState:    v1alpha1.OperationStateSubmitted,
+			wantReplicas: 1,
+		},
+		{
+			name:      "new secret not set",
+			spec:      v1alpha1.LocalVolumeKeyRotateSpec{VolumeName: "pvc-1"},
+			wantState: v1alpha1.OperationStateFailed,
+		},
+		{
+			name:      "new secret not found",
+			spec:      v1alpha1.LocalVolumeKeyRotateSpec{VolumeName: "pvc-1", NewSecret: "hwameistor/not-exist"},
+			wantState: v1alpha1.OperationStateFailed,
+		},
+		{
+			name:      "same secret",
+			spec:      v1alpha1.LocalVolumeKeyRotateSpec{VolumeName: "pvc-1", NewSecret: "hwameistor/old-secret"},
+			wantState: v1alpha1.OperationStateFailed,
+		},
+		{
+			name:      "volume not encrypted",
+			spec:      v1alpha1.LocalVolumeKeyRotateSpec{VolumeName: plainVolume.Name, NewSecret: "hwameistor/new-secret"},
+			wantState: v1alpha1.OperationStateFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyRotate := &v1alpha1.LocalVolumeKeyRotate{ObjectMeta: metav1.ObjectMeta{Name: "rotate"}, Spec: tt.spec}
+			m := newFakeKeyRotateManager(t, keyRotate, plainVolume, genFakeEncryptedVolume("pvc-1", "node1", "node2"),
+				genFakeEncryptSecret("old-secret"), genFakeEncryptSecret("new-secret"))
+
+			if err := m.volumeKeyRotateSubmit(keyRotate); err != nil {
+				t.Fatalf("volumeKeyRotateSubmit() error = %v", err)
+			}
+			got := getFakeKeyRotate(t, m, "rotate")
+			if got.Status.State != tt.wantState || len(got.Status.Replicas) != tt.wantReplicas {
+				t.Errorf("volumeKeyRotateSubmit() got status %+v", got.Status)
+			}
+			if tt.wantState == v1alpha1.OperationStateSubmitted &&
+				(got.Status.OldKey != "hwameistor/old-secret" || got.Status.NewKey != "hwameistor/new-secret" || got.Status.KeyProvider != v1alpha1.VolumeEncryptKeyProviderSecret) {
+				t.Errorf("volumeKeyRotateSubmit() got keys %+v", got.Status)
+			}
+		})
+	}
+}
+
+func Test_getVolumeKeyRotateNodes(t *testing.T) {
+	publishedVolume := genFakeEncryptedVolume("pvc-1", "node1", "node2")
+	publishedVolume.Status.PublishedNodeName = "node1"
+	tests := []struct {
+		name   string
+		volume *v1alpha1.LocalVolume
+		want   []string
+	}{
+		{
+			name:   "non-HA volume",
+			volume: genFakeScrubVolume("pvc-1", false, false, "node1"),
+			want:   []string{"node1"},
+		},
+		{
+			name:   "HA volume not published",
+			volume: genFakeEncryptedVolume("pvc-1", "node1", "node2"),
+			want:   []string{"node2"},
+		},
+		{
+			name:   "HA volume published",
+			volume: publishedVolume,
+			want:   []string{"node1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getVolumeKeyRotateNodes(tt.volume); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getVolumeKeyRotateNodes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_manager_volumeKeyRotateCheckAddKey(t *testing.T) {
+	genKeyRotate := func(states ...v1alpha1.State) *v1alpha1.LocalVolumeKeyRotate {
+		keyRotate := &v1alpha1.LocalVolumeKeyRotate{ObjectMeta: metav1.ObjectMeta{Name: "rotate"}}
+		keyRotate.Spec.VolumeName = "pvc-1"
+		keyRotate.Status.State = v1alpha1.OperationStateKeyRotateAddKey
+		keyRotate.Status.OldKey = "hwameistor/old-secret"
+		keyRotate.Status.NewKey = "hwameistor/new-secret"
+		for i, state := range states {
+			keyRotate.Status.Replicas = append(keyRotate.Status.Replicas, v1alpha1.VolumeKeyRotateReplicaStatus{NodeName: []string{"node1", "node2"}[i], State: state})
+		}
+		return keyRotate
+	}
+	tests := []struct {
+		name         string
+		keyRotate    *v1alpha1.LocalVolumeKeyRotate
+		wantState    v1alpha1.State
+		wantSwitched bool
+	}{
+		{
+			name:      "waiting for replicas",
+			keyRotate: genKeyRotate(v1alpha1.OperationStateKeyRotateKeyAdded, ""),
+			wantState: v1alpha1.OperationStateKeyRotateAddKey,
+		},
+		{
+			name:         "new key added on all the replicas",
+			keyRotate:    genKeyRotate(v1alpha1.OperationStateKeyRotateKeyAdded, v1alpha1.OperationStateKeyRotateKeyAdded),
+			wantState:    v1alpha1.OperationStateKeyRotateRemoveKey,
+			wantSwitched: true,
+		},
+		{
+			name:      "failed on a replica",
+			keyRotate: genKeyRotate(v1alpha1.OperationStateKeyRotateKeyAdded, v1alpha1.OperationStateFailed),
+			wantState: v1alpha1.OperationStateRollback,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replica := &v1alpha1.LocalVolumeReplica{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1-node1"}}
+			replica.Spec.VolumeName = "pvc-1"
+			replica.Spec.VolumeEncrypt = v1alpha1.VolumeEncrypt{Enable: true, Type: "LUKS", SecretNamespacedName: "hwameistor/old-secret"}
+			m := newFakeKeyRotateManager(t, tt.keyRotate, genFakeEncryptedVolume("pvc-1", "node1", "node2"), replica)
+
+			if err := m.volumeKeyRotateCheckAddKey(tt.keyRotate); err != nil {
+				t.Fatalf("volumeKeyRotateCheckAddKey() error = %v", err)
+			}
+			if got := getFakeKeyRotate(t, m, "rotate"); got.Status.State != tt.wantState {
+				t.Errorf("volumeKeyRotateCheckAddKey() got state %v, want %v", got.Status.State, tt.wantState)
+			}
+
+			wantSecret := "hwameistor/old-secret"
+			if tt.wantSwitched {
+				wantSecret = "hwameistor/new-secret"
+			}
+			volume := &v1alpha1.LocalVolume{}
+			if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: "pvc-1"}, volume); err != nil {
+				t.Fatal(err)
+			}
+			if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: "pvc-1-node1"}, replica); err != nil {
+				t.Fatal(err)
+			}
+			if volume.Spec.VolumeEncrypt.SecretNamespacedName != wantSecret || replica.Spec.VolumeEncrypt.SecretNamespacedName != wantSecret {
+				t.Errorf("Got volume secret %s and replica secret %s, want %s",
+					volume.Spec.VolumeEncrypt.SecretNamespacedName, replica.Spec.VolumeEncrypt.SecretNamespacedName, wantSecret)
+			}
+		})
+	}
+}
+
+func Test_manager_volumeKeyRotateCheckRollback(t *testing.T) {
+	tests := []struct {
+		name      string
+		abort     bool
+		states    []v1alpha1.State
+		wantState v1alpha1.State
+	}{
+		{
+			name:      "waiting for replicas",
+			abort:     true,
+			states:    []v1alpha1.State{v1alpha1.OperationStateAborted, v1alpha1.OperationStateKeyRotateKeyAdded},
+			wantState: v1alpha1.OperationStateRollback,
+		},
+		{
+			name:      "aborted",
+			abort:     true,
+			states:    []v1alpha1.State{v1alpha1.OperationStateAborted, v1alpha1.OperationStateAborted},
+			wantState: v1alpha1.OperationStateAborted,
+		},
+		{
+			name:      "rolled back after failure",
+			states:    []v1alpha1.State{v1alpha1.OperationStateAborted, v1alpha1.OperationStateFailed},
+			wantState: v1alpha1.OperationStateFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyRotate := &v1alpha1.LocalVolumeKeyRotate{ObjectMeta: metav1.ObjectMeta{Name: "rotate"}}
+			keyRotate.Spec.Abort = tt.abort
+			keyRotate.Status.State = v1alpha1.OperationStateRollback
+			for i, state := range tt.states {
+				keyRotate.Status.Replicas = append(keyRotate.Status.Replicas, v1alpha1.VolumeKeyRotateReplicaStatus{NodeName: []string{"node1", "node2"}[i], State: state})
+			}
+			m := newFakeKeyRotateManager(t, keyRotate)
+
+			if err := m.volumeKeyRotateCheckRollback(keyRotate); err != nil {
+				t.Fatalf("volumeKeyRotateCheckRollback() error = %v", err)
+			}
+			if got := getFakeKeyRotate(t, m, "rotate"); got.Status.State != tt.wantState {
+				t.Errorf("volumeKeyRotateCheckRollback() got state %v, want %v", got.Status.State, tt.wantState)
+			}
+		})
+	}
+}
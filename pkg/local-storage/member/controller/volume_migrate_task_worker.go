@@ -3,6 +3,8 @@ package controller
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"time"
 
 	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 	"github.com/hwameistor/hwameistor/pkg/local-storage/utils"
@@ -11,6 +13,8 @@ import (
 	"github.com/wxnacy/wgo/arrays"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -66,8 +70,22 @@ func (m *manager) processVolumeMigrate(vmName string) error {
 		migrate.Status.State != apisv1alpha1.OperationStateAborted &&
 		migrate.Status.State != apisv1alpha1.OperationStateCompleted {
 
-		migrate.Status.State = apisv1alpha1.OperationStateToBeAborted
-		return m.apiClient.Status().Update(context.TODO(), migrate)
+		publishedVolumes, err := m.volumesPublishedOnTarget(migrate)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to check the volumes published on the target node")
+			return err
+		}
+		switch {
+		// the replica on the source node is being removed, no way to roll it back
+		case migrate.Status.State == apisv1alpha1.OperationStateMigratePruneReplica:
+			logCtx.Warning("Can't abort the migration when pruning the source replica")
+		// the workload is running on the new replicas, which can't be removed
+		case len(publishedVolumes) > 0:
+			logCtx.WithField("volumes", publishedVolumes).Warning("Can't abort the migration when the volumes are published on the target node")
+		default:
+			migrate.Status.State = apisv1alpha1.OperationStateToBeAborted
+			return m.apiClient.Status().Update(context.TODO(), migrate)
+		}
 	}
 	ctx := context.TODO()
 
@@ -95,7 +113,15 @@ func (m *manager) processVolumeMigrate(vmName string) error {
 		return err
 	}
 
-	// state chain: (empty) -> Submitted -> Start -> InProgress -> Completed
+	// state chain: (empty) -> Submitted -> AddReplica -> SyncReplica -> (Switchover) -> PruneReplica -> Completed
+	// abort: ToBeAborted -> Cancelled -> Aborted
+
+	// the progress is saved along with the status update of the states below
+	if migrate.Status.State == apisv1alpha1.OperationStateMigrateAddReplica || migrate.Status.State == apisv1alpha1.OperationStateMigrateSyncReplica {
+		if err := m.updateVolumeMigrateProgress(migrate); err != nil {
+			logCtx.WithError(err).Warning("Failed to update the progress of the migration")
+		}
+	}
 
 	logCtx = m.logger.WithFields(log.Fields{"migration": migrate.Name, "spec": migrate.Spec, "status": migrate.Status})
 	logCtx.Debug("Starting to process a VolumeMigrate task")
@@ -108,10 +134,14 @@ func (m *manager) processVolumeMigrate(vmName string) error {
 		return m.volumeMigrateAddReplica(migrate, vol)
 	case apisv1alpha1.OperationStateMigrateSyncReplica:
 		return m.volumeMigrateSyncReplica(migrate, vol)
+	case apisv1alpha1.OperationStateMigrateSwitchover:
+		return m.volumeMigrateSwitchover(migrate, lvg)
 	case apisv1alpha1.OperationStateMigratePruneReplica:
 		return m.volumeMigratePruneReplica(migrate, vol, lvg)
 	case apisv1alpha1.OperationStateToBeAborted:
-		return m.volumeMigrateAbort(migrate)
+		return m.volumeMigrateAbort(migrate, lvg)
+	case apisv1alpha1.OperationStateAborting:
+		return m.volumeMigrateRollback(migrate)
 	case apisv1alpha1.OperationStateCompleted, apisv1alpha1.OperationStateAborted:
 		return m.volumeMigrateCleanup(migrate)
 	default:
@@ -125,21 +155,31 @@ func (m *manager) volumeMigrateSubmit(migrate *apisv1alpha1.LocalVolumeMigrate,
 	logCtx.Debug("Submit a VolumeMigrate")
 
 	ctx := context.TODO()
-	//Indicates that lvm is being migrated
-	var anno map[string]string
-	if anno = vol.GetAnnotations(); anno == nil {
-		anno = make(map[string]string)
-	}
-	anno[apisv1alpha1.VolumeMigrateCompletedAnnoKey] = apisv1alpha1.MigrateStarted
-	vol.SetAnnotations(anno)
-	err := m.apiClient.Update(ctx, vol)
-	if err != nil {
-		logCtx.WithField("LocalVolume", vol.Name).WithError(err).Debug("lvm anno set file")
-		return err
+	if migrate.Spec.Online {
+		// the pod keeps running during the online migration, so it's never blocked from scheduling
+		if !vol.Spec.Convertible {
+			logCtx.Warning("Can't migrate the unconvertible volume online")
+			migrate.Status.Message = "Can't migrate the unconvertible volume online"
+			m.apiClient.Status().Update(ctx, migrate)
+			return fmt.Errorf("can't migrate the unconvertible volume online")
+		}
+	} else {
+		//Indicates that lvm is being migrated
+		var anno map[string]string
+		if anno = vol.GetAnnotations(); anno == nil {
+			anno = make(map[string]string)
+		}
+		anno[apisv1alpha1.VolumeMigrateCompletedAnnoKey] = apisv1alpha1.MigrateStarted
+		vol.SetAnnotations(anno)
+		err := m.apiClient.Update(ctx, vol)
+		if err != nil {
+			logCtx.WithField("LocalVolume", vol.Name).WithError(err).Debug("lvm anno set file")
+			return err
+		}
 	}
 
 	// if LV is still in use, waiting for it to be released
-	if vol.Status.PublishedNodeName == migrate.Spec.SourceNode {
+	if !migrate.Spec.Online && vol.Status.PublishedNodeName == migrate.Spec.SourceNode {
 		logCtx.WithField("PublishedNode", vol.Status.PublishedNodeName).Warning("LocalVolume is still in use by source node, try it later")
 		migrate.Status.Message = "Volume is still in use"
 		m.apiClient.Status().Update(ctx, migrate)
@@ -219,6 +259,12 @@ func (m *manager) volumeMigrateSubmit(migrate *apisv1alpha1.LocalVolumeMigrate,
 		}
 		migrate.Status.Volumes = []string{}
 		for i := range volList {
+			if migrate.Spec.Online && !volList[i].Spec.Convertible {
+				logCtx.WithField("LocalVolume", volList[i].Name).Warning("Can't migrate the unconvertible volume online")
+				migrate.Status.Message = fmt.Sprintf("Can't migrate the unconvertible volume %s online", volList[i].Name)
+				m.apiClient.Status().Update(ctx, migrate)
+				return fmt.Errorf("can't migrate the unconvertible volume %s online", volList[i].Name)
+			}
 			if err := m.checkReplicasForVolume(volList[i]); err != nil {
 				logCtx.WithField("LocalVolume", volList[i].Name).WithError(err).Error("Replicas are in problem")
 				migrate.Status.Message = err.Error()
@@ -238,6 +284,17 @@ func (m *manager) volumeMigrateStart(migrate *apisv1alpha1.LocalVolumeMigrate, v
 	logCtx.Debug("Start setting target node")
 
 	ctx := context.TODO()
+	// the data is copied as soon as the new replica is added, so wait for the time window here
+	if inWindow, wait := inMigrateTimeWindows(migrate.Spec.TimeWindows, time.Now()); !inWindow {
+		logCtx.WithField("wait", wait).Debug("Waiting for the time window to start the migration")
+		migrate.Status.Message = fmt.Sprintf("Waiting for the time window, starts in %s", wait.Round(time.Minute))
+		if err := m.apiClient.Status().Update(ctx, migrate); err != nil {
+			return err
+		}
+		m.volumeMigrateTaskQueue.AddAfter(migrate.Name, wait)
+		return nil
+	}
+
 	// set the target node in LocalVolumeGroup, so that the replica will be allocated base on it
 	if arrays.ContainsString(lvg.Spec.Accessibility.Nodes, migrate.Status.TargetNode) == -1 {
 		lvg.Spec.Accessibility.Nodes = append(lvg.Spec.Accessibility.Nodes, migrate.Status.TargetNode)
@@ -266,6 +323,14 @@ func (m *manager) volumeMigrateAddReplica(migrate *apisv1alpha1.LocalVolumeMigra
 		return err
 	}
 
+	bandwidthLimit, err := parseMigrateBandwidthLimit(migrate.Spec.BandwidthLimit)
+	if err != nil {
+		logCtx.WithError(err).Error("Invalid bandwidth limit")
+		migrate.Status.Message = err.Error()
+		m.apiClient.Status().Update(ctx, migrate)
+		return err
+	}
+
 	for _, volName := range migrate.Status.Volumes {
 		vol := &apisv1alpha1.LocalVolume{}
 		if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: volName}, vol); err != nil {
@@ -289,6 +354,10 @@ func (m *manager) volumeMigrateAddReplica(migrate *apisv1alpha1.LocalVolumeMigra
 			m.apiClient.Status().Update(ctx, migrate)
 			return err
 		}
+		// the new replica of the convertible volume is synced by DRBD, limit its resync rate
+		if vol.Spec.Convertible {
+			conf.ResyncBandwidthLimit = bandwidthLimit
+		}
 		vol.Spec.Config = conf
 		if err := m.apiClient.Update(ctx, vol); err != nil {
 			logCtx.WithField("LocalVolume", volName).WithError(err).Error("Failed to add a new replica to the volume")
@@ -329,16 +398,113 @@ func (m *manager) volumeMigrateSyncReplica(migrate *apisv1alpha1.LocalVolumeMigr
 			return err
 		}
 	}
+	if migrate.Spec.Online {
+		migrate.Status.State = apisv1alpha1.OperationStateMigrateSwitchover
+	} else {
+		migrate.Status.State = apisv1alpha1.OperationStateMigratePruneReplica
+	}
+	return m.apiClient.Status().Update(ctx, migrate)
+}
+
+// volumeMigrateSwitchover waits for the pod to leave the source node, after the new replicas are consistent. The pod
+// can only be scheduled to the target node then, and DRBD promotes the new replica when the volume is published there
+func (m *manager) volumeMigrateSwitchover(migrate *apisv1alpha1.LocalVolumeMigrate, lvg *apisv1alpha1.LocalVolumeGroup) error {
+	logCtx := m.logger.WithFields(log.Fields{"migration": migrate.Name, "spec": migrate.Spec, "status": migrate.Status})
+	logCtx.Debug("Start switching over the volumes")
+
+	ctx := context.TODO()
+	if arrays.ContainsString(lvg.Spec.Accessibility.Nodes, migrate.Spec.SourceNode) != -1 {
+		lvg.Spec.Accessibility.Nodes = utils.RemoveStringItem(lvg.Spec.Accessibility.Nodes, migrate.Spec.SourceNode)
+		if err := m.apiClient.Update(ctx, lvg); err != nil {
+			logCtx.WithField("LocalVolumeGroup", lvg.Name).WithError(err).Error("Failed to update LocalVolumeGroup")
+			return err
+		}
+	}
+
+	var inUseVolumes []*apisv1alpha1.LocalVolume
+	for _, volName := range migrate.Status.Volumes {
+		vol := &apisv1alpha1.LocalVolume{}
+		if err := m.apiClient.Get(ctx, types.NamespacedName{Name: volName}, vol); err != nil {
+			return err
+		}
+		anno := vol.GetAnnotations()
+		if anno == nil {
+			anno = make(map[string]string)
+		}
+		if anno[apisv1alpha1.VolumeMigrateCompletedAnnoKey] != apisv1alpha1.MigrateSwitchover || anno[apisv1alpha1.VolumeMigrateTargetNodeAnnoKey] != migrate.Status.TargetNode {
+			anno[apisv1alpha1.VolumeMigrateCompletedAnnoKey] = apisv1alpha1.MigrateSwitchover
+			anno[apisv1alpha1.VolumeMigrateTargetNodeAnnoKey] = migrate.Status.TargetNode
+			vol.SetAnnotations(anno)
+			if err := m.apiClient.Update(ctx, vol); err != nil {
+				logCtx.WithField("LocalVolume", volName).WithError(err).Error("Failed to set the switchover annotation")
+				return err
+			}
+		}
+		if vol.Status.PublishedNodeName == migrate.Spec.SourceNode {
+			inUseVolumes = append(inUseVolumes, vol)
+		}
+	}
+
+	if len(inUseVolumes) > 0 {
+		if migrate.Spec.EvictPod {
+			for _, vol := range inUseVolumes {
+				if err := m.evictPodsUsingVolume(vol, migrate.Spec.SourceNode); err != nil {
+					logCtx.WithField("LocalVolume", vol.Name).WithError(err).Error("Failed to evict the pod")
+					migrate.Status.Message = fmt.Sprintf("Failed to evict the pod using volume %s: %s", vol.Name, err.Error())
+					m.apiClient.Status().Update(ctx, migrate)
+					return err
+				}
+			}
+			migrate.Status.Message = "Waiting for the evicted pod to start on the target node"
+		} else {
+			migrate.Status.Message = "Waiting for the pod to restart on the target node"
+		}
+		m.apiClient.Status().Update(ctx, migrate)
+		return fmt.Errorf("volume still in use on the source node")
+	}
+
+	logCtx.Debug("Volumes are switched over to the target node")
+	migrate.Status.Message = "Switched over to the target node"
 	migrate.Status.State = apisv1alpha1.OperationStateMigratePruneReplica
 	return m.apiClient.Status().Update(ctx, migrate)
 }
 
+// evictPodsUsingVolume evicts the pods on the node using the PVC of the volume
+func (m *manager) evictPodsUsingVolume(vol *apisv1alpha1.LocalVolume, nodeName string) error {
+	if len(vol.Spec.PersistentVolumeClaimName) == 0 {
+		return nil
+	}
+	podList := &corev1.PodList{}
+	if err := m.apiClient.List(context.TODO(), podList, client.InNamespace(vol.Spec.PersistentVolumeClaimNamespace)); err != nil {
+		return err
+	}
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName != nodeName || pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, podVol := range pod.Spec.Volumes {
+			if podVol.PersistentVolumeClaim != nil && podVol.PersistentVolumeClaim.ClaimName == vol.Spec.PersistentVolumeClaimName {
+				m.logger.WithFields(log.Fields{"namespace": pod.Namespace, "pod": pod.Name, "LocalVolume": vol.Name}).Info("Evicting the pod for the migration")
+				if err := m.podEvictor.Evict(pod.Namespace, pod.Name); err != nil {
+					return err
+				}
+				break
+			}
+		}
+	}
+	return nil
+}
+
 func (m *manager) syncReplica(migrate *apisv1alpha1.LocalVolumeMigrate, vol *apisv1alpha1.LocalVolume) (err error) {
 	logCtx := m.logger.WithFields(log.Fields{"migration": migrate.Name, "volume": vol.Name})
 	logCtx.Debug("Preparing the resources for data sync ...")
 
+	bandwidthLimit, err := parseMigrateBandwidthLimit(migrate.Spec.BandwidthLimit)
+	if err != nil {
+		return err
+	}
 	jobName := generateJobName(migrate.Name, vol.Spec.PersistentVolumeClaimName)
-	return m.dataCopyManager.Sync(jobName, migrate.Spec.SourceNode, migrate.Status.TargetNode, vol.Name)
+	return m.dataCopyManager.Sync(jobName, migrate.Spec.SourceNode, migrate.Status.TargetNode, vol.Name, bandwidthLimit)
 }
 
 func (m *manager) volumeMigratePruneReplica(migrate *apisv1alpha1.LocalVolumeMigrate, vol *apisv1alpha1.LocalVolume, lvg *apisv1alpha1.LocalVolumeGroup) error {
@@ -387,6 +553,7 @@ func (m *manager) volumeMigratePruneReplica(migrate *apisv1alpha1.LocalVolumeMig
 				}
 			}
 			vol.Spec.Config.Replicas = replicas
+			vol.Spec.Config.ResyncBandwidthLimit = 0
 			vol.Spec.ReplicaNumber = migrate.Status.OriginalReplicaNumber
 			if err := m.apiClient.Update(ctx, vol); err != nil {
 				logCtx.WithField("LocalVolume", volName).WithError(err).Error("Failed to prune a replica")
@@ -410,6 +577,7 @@ func (m *manager) volumeMigratePruneReplica(migrate *apisv1alpha1.LocalVolumeMig
 		anno = make(map[string]string)
 	}
 	anno[apisv1alpha1.VolumeMigrateCompletedAnnoKey] = apisv1alpha1.MigrateCompleted
+	delete(anno, apisv1alpha1.VolumeMigrateTargetNodeAnnoKey)
 	vol.SetAnnotations(anno)
 	err := m.apiClient.Update(ctx, vol)
 	if err != nil {
@@ -423,12 +591,284 @@ func (m *manager) volumeMigratePruneReplica(migrate *apisv1alpha1.LocalVolumeMig
 
 }
 
-func (m *manager) volumeMigrateAbort(migrate *apisv1alpha1.LocalVolumeMigrate) error {
+// updateVolumeMigrateProgress sums up the progress of copying the data of all the volumes. The new replica of
+// the convertible volume is synced by DRBD, and the others are copied by the data sync job
+func (m *manager) updateVolumeMigrateProgress(migrate *apisv1alpha1.LocalVolumeMigrate) error {
+	ctx := context.TODO()
+
+	var copiedBytes, totalBytes int64
+	for _, volName := range migrate.Status.Volumes {
+		vol := &apisv1alpha1.LocalVolume{}
+		if err := m.apiClient.Get(ctx, types.NamespacedName{Name: volName}, vol); err != nil {
+			return err
+		}
+
+		if vol.Spec.Convertible {
+			totalBytes += vol.Spec.RequiredCapacityBytes
+			replicas, err := m.getReplicasForVolume(volName)
+			if err != nil {
+				return err
+			}
+			for _, replica := range replicas {
+				if replica.Spec.NodeName != migrate.Status.TargetNode {
+					continue
+				}
+				// the replica is not ready until it's consistent, and reports the bytes out of sync while resyncing
+				if replica.Status.State == apisv1alpha1.VolumeReplicaStateReady {
+					copiedBytes += vol.Spec.RequiredCapacityBytes
+				} else if replica.Status.OutOfSyncBytes > 0 {
+					copiedBytes += vol.Spec.RequiredCapacityBytes - replica.Status.OutOfSyncBytes
+				}
+			}
+			continue
+		}
+
+		progress, err := m.dataCopyManager.GetProgress(volName)
+		if err != nil {
+			return err
+		}
+		if progress == nil {
+			// not started yet, the total size is unknown until the source volume is mounted
+			totalBytes += vol.Spec.RequiredCapacityBytes
+			continue
+		}
+		copiedBytes += progress.CopiedBytes
+		totalBytes += progress.TotalBytes
+	}
+
+	migrate.Status.Progress = calculateMigrateProgress(migrate.Status.Progress, copiedBytes, totalBytes, time.Now())
+	return nil
+}
+
+// calculateMigrateProgress calculates the average throughput since the copy started, and the ETA based on it
+func calculateMigrateProgress(prev *apisv1alpha1.MigrateProgress, copiedBytes, totalBytes int64, now time.Time) *apisv1alpha1.MigrateProgress {
+	if copiedBytes > totalBytes {
+		copiedBytes = totalBytes
+	}
+	progress := &apisv1alpha1.MigrateProgress{
+		CopiedBytes: copiedBytes,
+		TotalBytes:  totalBytes,
+		UpdateTime:  &metav1.Time{Time: now},
+	}
+	if prev != nil && prev.StartTime != nil {
+		progress.StartTime = prev.StartTime
+	} else {
+		progress.StartTime = &metav1.Time{Time: now}
+	}
+
+	elapsed := now.Sub(progress.StartTime.Time).Seconds()
+	if elapsed >= 1 && copiedBytes > 0 {
+		progress.Throughput = int64(float64(copiedBytes) / elapsed)
+	}
+	if progress.Throughput > 0 {
+		progress.ETASeconds = (totalBytes - copiedBytes) / progress.Throughput
+	}
+	return progress
+}
+
+// parseMigrateBandwidthLimit returns the bandwidth limit in bytes per second, 0 for no limit
+func parseMigrateBandwidthLimit(bandwidthLimit string) (int64, error) {
+	if len(bandwidthLimit) == 0 {
+		return 0, nil
+	}
+	quantity, err := resource.ParseQuantity(bandwidthLimit)
+	if err != nil {
+		return 0, fmt.Errorf("invalid bandwidth limit %s: %v", bandwidthLimit, err)
+	}
+	if quantity.Value() < 0 {
+		return 0, fmt.Errorf("invalid bandwidth limit %s: negative", bandwidthLimit)
+	}
+	return quantity.Value(), nil
+}
+
+// inMigrateTimeWindows checks if now is in any of the time windows, and returns how long to wait for
+// the next window if not. It's always in the window if no windows are set
+func inMigrateTimeWindows(windows []apisv1alpha1.MigrateTimeWindow, now time.Time) (bool, time.Duration) {
+	if len(windows) == 0 {
+		return true, 0
+	}
+
+	now = now.UTC()
+	minuteOfDay := now.Hour()*60 + now.Minute()
+	wait := 24 * time.Hour
+	for _, window := range windows {
+		start, err := parseMinuteOfDay(window.Start)
+		if err != nil {
+			continue
+		}
+		end, err := parseMinuteOfDay(window.End)
+		if err != nil {
+			continue
+		}
+
+		var in bool
+		if start <= end {
+			in = minuteOfDay >= start && minuteOfDay < end
+		} else {
+			// crosses midnight
+			in = minuteOfDay >= start || minuteOfDay < end
+		}
+		if in {
+			return true, 0
+		}
+
+		untilStart := time.Duration((start-minuteOfDay+24*60)%(24*60))*time.Minute - time.Duration(now.Second())*time.Second
+		if untilStart < wait {
+			wait = untilStart
+		}
+	}
+	return false, wait
+}
+
+func parseMinuteOfDay(hhmm string) (int, error) {
+	t, err := time.Parse("15:04", hhmm)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// volumeMigrateAbort rolls back the changes made by the migration: removes the new replicas on the target node,
+// stops the data sync and restores the accessibility of the source node
+func (m *manager) volumeMigrateAbort(migrate *apisv1alpha1.LocalVolumeMigrate, lvg *apisv1alpha1.LocalVolumeGroup) error {
 	logCtx := m.logger.WithFields(log.Fields{"migration": migrate.Name, "spec": migrate.Spec, "status": migrate.Status})
 	logCtx.Debug("Abort a VolumeMigrate")
 
+	ctx := context.TODO()
+	// the pod may have just restarted on the target node, the new replicas can't be removed until it's switched back
+	publishedVolumes, err := m.volumesPublishedOnTarget(migrate)
+	if err != nil {
+		return err
+	}
+	if len(publishedVolumes) > 0 {
+		logCtx.WithField("volumes", publishedVolumes).Warning("Volumes are published on the target node, waiting for them to be switched back")
+		migrate.Status.Message = fmt.Sprintf("Waiting for the volumes %v to be unpublished from the target node", publishedVolumes)
+		m.apiClient.Status().Update(ctx, migrate)
+		return fmt.Errorf("volumes %v are published on the target node", publishedVolumes)
+	}
+
+	nodes := []string{}
+	for _, node := range lvg.Spec.Accessibility.Nodes {
+		if node != migrate.Status.TargetNode {
+			nodes = append(nodes, node)
+		}
+	}
+	if arrays.ContainsString(nodes, migrate.Spec.SourceNode) == -1 {
+		nodes = append(nodes, migrate.Spec.SourceNode)
+	}
+	if len(migrate.Status.TargetNode) > 0 && !reflect.DeepEqual(nodes, lvg.Spec.Accessibility.Nodes) {
+		lvg.Spec.Accessibility.Nodes = nodes
+		if err := m.apiClient.Update(ctx, lvg); err != nil {
+			logCtx.WithField("LocalVolumeGroup", lvg.Name).WithError(err).Error("Failed to restore LocalVolumeGroup")
+			return err
+		}
+	}
+
+	for _, volName := range migrate.Status.Volumes {
+		vol := &apisv1alpha1.LocalVolume{}
+		if err := m.apiClient.Get(ctx, types.NamespacedName{Name: volName}, vol); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if !vol.Spec.Convertible {
+			if err := m.dataCopyManager.StopSync(generateJobName(migrate.Name, vol.Spec.PersistentVolumeClaimName), volName); err != nil {
+				logCtx.WithField("LocalVolume", volName).WithError(err).Error("Failed to stop the data sync")
+				return err
+			}
+		}
+
+		if vol.Spec.ReplicaNumber > migrate.Status.OriginalReplicaNumber && vol.Spec.Config != nil {
+			replicas := []apisv1alpha1.VolumeReplica{}
+			for _, replica := range vol.Spec.Config.Replicas {
+				if replica.Hostname != migrate.Status.TargetNode {
+					replicas = append(replicas, replica)
+				}
+			}
+			vol.Spec.Config.Replicas = replicas
+			vol.Spec.Config.ResyncBandwidthLimit = 0
+			vol.Spec.ReplicaNumber = migrate.Status.OriginalReplicaNumber
+		}
+		if anno := vol.GetAnnotations(); anno != nil {
+			delete(anno, apisv1alpha1.VolumeMigrateCompletedAnnoKey)
+			delete(anno, apisv1alpha1.VolumeMigrateTargetNodeAnnoKey)
+			vol.SetAnnotations(anno)
+		}
+		if err := m.apiClient.Update(ctx, vol); err != nil {
+			logCtx.WithField("LocalVolume", volName).WithError(err).Error("Failed to roll back the volume")
+			return err
+		}
+	}
+
+	migrate.Status.Message = "Rolling back the migration"
+	migrate.Status.State = apisv1alpha1.OperationStateAborting
+	return m.apiClient.Status().Update(ctx, migrate)
+}
+
+// volumesPublishedOnTarget returns the volumes of the migration which are published on the target node
+func (m *manager) volumesPublishedOnTarget(migrate *apisv1alpha1.LocalVolumeMigrate) ([]string, error) {
+	if len(migrate.Status.TargetNode) == 0 {
+		return nil, nil
+	}
+	var volumes []string
+	for _, volName := range migrate.Status.Volumes {
+		vol := &apisv1alpha1.LocalVolume{}
+		if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: volName}, vol); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if vol.Status.PublishedNodeName == migrate.Status.TargetNode {
+			volumes = append(volumes, volName)
+		}
+	}
+	return volumes, nil
+}
+
+// volumeMigrateRollback waits for the new replicas to be removed
+func (m *manager) volumeMigrateRollback(migrate *apisv1alpha1.LocalVolumeMigrate) error {
+	logCtx := m.logger.WithFields(log.Fields{"migration": migrate.Name, "spec": migrate.Spec, "status": migrate.Status})
+	logCtx.Debug("Rolling back a VolumeMigrate")
+
+	ctx := context.TODO()
+	for _, volName := range migrate.Status.Volumes {
+		vol := &apisv1alpha1.LocalVolume{}
+		if err := m.apiClient.Get(ctx, types.NamespacedName{Name: volName}, vol); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		// the new replica can't be removed until it's unmounted from the data sync
+		if !vol.Spec.Convertible {
+			released, err := m.dataCopyManager.IsSyncReleased(volName)
+			if err != nil {
+				return err
+			}
+			if !released {
+				logCtx.WithField("LocalVolume", volName).Debug("Still releasing the volume mounted for the data sync")
+				return fmt.Errorf("still releasing the volume %s mounted for the data sync", volName)
+			}
+		}
+
+		replicas, err := m.getReplicasForVolume(volName)
+		if err != nil {
+			return err
+		}
+		for _, replica := range replicas {
+			if replica.Spec.NodeName == migrate.Status.TargetNode {
+				logCtx.WithField("LocalVolumeReplica", replica.Name).Debug("Still removing the new replica")
+				return fmt.Errorf("still removing the new replica %s", replica.Name)
+			}
+		}
+	}
+
+	logCtx.Debug("Rolled back the migration")
+	migrate.Status.Message = "Rolled back the migration"
 	migrate.Status.State = apisv1alpha1.OperationStateAborted
-	return m.apiClient.Status().Update(context.TODO(), migrate)
+	return m.apiClient.Status().Update(ctx, migrate)
 }
 
 func (m *manager) volumeMigrateCleanup(migrate *apisv1alpha1.LocalVolumeMigrate) error {
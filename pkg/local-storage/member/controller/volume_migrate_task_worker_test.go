@@ -2,13 +2,19 @@ package controller
 
 import (
 	"context"
+	"reflect"
 	"sync"
 	"testing"
+	"time"
 
 	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 	"github.com/hwameistor/hwameistor/pkg/local-storage/common"
@@ -199,7 +205,7 @@ func Test_manager_volumeMigrateAbort(t *testing.T) {
 				localNodes:                  map[string]v1alpha1.State{},
 				logger:                      log.WithField("Module", "ControllerManager"),
 			}
-			if err := m.volumeMigrateAbort(tt.args.migrate); (err != nil) != tt.wantErr {
+			if err := m.volumeMigrateAbort(tt.args.migrate, lvg); (err != nil) != tt.wantErr {
 				t.Errorf("volumeMigrateAbort() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -567,3 +573,344 @@ func Test_manager_volumeMigrateSubmit(t *testing.T) {
 		})
 	}
 }
+
+func Test_inMigrateTimeWindows(t *testing.T) {
+	night := []v1alpha1.MigrateTimeWindow{{Start: "22:00", End: "06:00"}}
+	lunch := []v1alpha1.MigrateTimeWindow{{Start: "12:00", End: "13:00"}, {Start: "22:00", End: "06:00"}}
+	tests := []struct {
+		name     string
+		windows  []v1alpha1.MigrateTimeWindow
+		now      time.Time
+		wantIn   bool
+		wantWait time.Duration
+	}{
+		{
+			name:   "no windows",
+			now:    time.Date(2023, 1, 1, 15, 0, 0, 0, time.UTC),
+			wantIn: true,
+		},
+		{
+			name:    "before midnight in window crossing midnight",
+			windows: night,
+			now:     time.Date(2023, 1, 1, 23, 30, 0, 0, time.UTC),
+			wantIn:  true,
+		},
+		{
+			name:    "after midnight in window crossing midnight",
+			windows: night,
+			now:     time.Date(2023, 1, 1, 5, 59, 0, 0, time.UTC),
+			wantIn:  true,
+		},
+		{
+			name:     "at the end of window",
+			windows:  night,
+			now:      time.Date(2023, 1, 1, 6, 0, 0, 0, time.UTC),
+			wantWait: 16 * time.Hour,
+		},
+		{
+			name:     "wait for the nearest window",
+			windows:  lunch,
+			now:      time.Date(2023, 1, 1, 10, 30, 30, 0, time.UTC),
+			wantWait: 89*time.Minute + 30*time.Second,
+		},
+		{
+			name:     "in other time zone",
+			windows:  night,
+			now:      time.Date(2023, 1, 1, 20, 0, 0, 0, time.FixedZone("UTC+8", 8*3600)),
+			wantWait: 10 * time.Hour,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in, wait := inMigrateTimeWindows(tt.windows, tt.now)
+			if in != tt.wantIn || wait != tt.wantWait {
+				t.Errorf("inMigrateTimeWindows() = %v, %v, want %v, %v", in, wait, tt.wantIn, tt.wantWait)
+			}
+		})
+	}
+}
+
+func Test_calculateMigrateProgress(t *testing.T) {
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	prev := &v1alpha1.MigrateProgress{StartTime: &metav1.Time{Time: start}}
+	tests := []struct {
+		name           string
+		prev           *v1alpha1.MigrateProgress
+		copied         int64
+		total          int64
+		now            time.Time
+		wantThroughput int64
+		wantETA        int64
+		wantStart      time.Time
+	}{
+		{
+			name:      "just started",
+			total:     1000,
+			now:       start,
+			wantStart: start,
+		},
+		{
+			name:           "in progress",
+			prev:           prev,
+			copied:         400,
+			total:          1000,
+			now:            start.Add(10 * time.Second),
+			wantThroughput: 40,
+			wantETA:        15,
+			wantStart:      start,
+		},
+		{
+			name:           "completed",
+			prev:           prev,
+			copied:         1200,
+			total:          1000,
+			now:            start.Add(10 * time.Second),
+			wantThroughput: 100,
+			wantStart:      start,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := calculateMigrateProgress(tt.prev, tt.copied, tt.total, tt.now)
+			if got.Throughput != tt.wantThroughput || got.ETASeconds != tt.wantETA || !got.StartTime.Time.Equal(tt.wantStart) || got.CopiedBytes > got.TotalBytes {
+				t.Errorf("calculateMigrateProgress() = %+v", got)
+			}
+		})
+	}
+}
+
+func Test_parseMigrateBandwidthLimit(t *testing.T) {
+	tests := []struct {
+		limit   string
+		want    int64
+		wantErr bool
+	}{
+		{limit: "", want: 0},
+		{limit: "100Mi", want: 100 * 1024 * 1024},
+		{limit: "10M", want: 10 * 1000 * 1000},
+		{limit: "-1Mi", wantErr: true},
+		{limit: "fast", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.limit, func(t *testing.T) {
+			got, err := parseMigrateBandwidthLimit(tt.limit)
+			if (err != nil) != tt.wantErr || got != tt.want {
+				t.Errorf("parseMigrateBandwidthLimit() = %v, %v, want %v, wantErr %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+type fakePodEvictor struct {
+	evicted []string
+}
+
+func (e *fakePodEvictor) Evict(namespace, name string) error {
+	e.evicted = append(e.evicted, namespace+"/"+name)
+	return nil
+}
+
+func newFakeOnlineMigrate(state v1alpha1.State) *v1alpha1.LocalVolumeMigrate {
+	migrate := &v1alpha1.LocalVolumeMigrate{ObjectMeta: metav1.ObjectMeta{Name: "migrate-1"}}
+	migrate.Spec.VolumeName = "pvc-1"
+	migrate.Spec.SourceNode = "node1"
+	migrate.Spec.Online = true
+	migrate.Status.TargetNode = "node2"
+	migrate.Status.Volumes = []string{"pvc-1"}
+	migrate.Status.OriginalReplicaNumber = 1
+	migrate.Status.State = state
+	return migrate
+}
+
+func newFakeMigrateManager(t *testing.T, objs ...client.Object) *manager {
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	if err := v1alpha1.AddToScheme(s); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	return &manager{
+		apiClient:              fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build(),
+		volumeMigrateTaskQueue: common.NewTaskQueue("VolumeMigrateTask", maxRetries),
+		podEvictor:             &fakePodEvictor{},
+		logger:                 log.WithField("Module", "ControllerManager"),
+	}
+}
+
+func Test_manager_volumeMigrateSubmit_online(t *testing.T) {
+	lv := newFakeReplicatedVolume("pvc-1", "node1")
+	lv.Spec.Convertible = false
+	lv.Status.PublishedNodeName = "node1"
+	lvg := &v1alpha1.LocalVolumeGroup{ObjectMeta: metav1.ObjectMeta{Name: "lvg-1"}}
+	migrate := newFakeOnlineMigrate("")
+	m := newFakeMigrateManager(t, lv, lvg, migrate)
+
+	if err := m.volumeMigrateSubmit(migrate, lv, lvg); err == nil {
+		t.Error("volumeMigrateSubmit() got no error for the unconvertible volume")
+	}
+	vol := &v1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: lv.Name}, vol); err != nil {
+		t.Fatalf("Get LocalVolume error = %v", err)
+	}
+	// the pod is never blocked from scheduling by the online migration
+	if vol.Annotations[v1alpha1.VolumeMigrateCompletedAnnoKey] == v1alpha1.MigrateStarted {
+		t.Errorf("volumeMigrateSubmit() got annotations %v", vol.Annotations)
+	}
+}
+
+func Test_manager_volumeMigrateSwitchover(t *testing.T) {
+	tests := []struct {
+		name          string
+		publishedNode string
+		evictPod      bool
+		wantErr       bool
+		wantState     v1alpha1.State
+		wantEvicted   []string
+	}{
+		{
+			name:          "wait for the pod to restart",
+			publishedNode: "node1",
+			wantErr:       true,
+			wantState:     v1alpha1.OperationStateMigrateSwitchover,
+		},
+		{
+			name:          "evict the pod",
+			publishedNode: "node1",
+			evictPod:      true,
+			wantErr:       true,
+			wantState:     v1alpha1.OperationStateMigrateSwitchover,
+			wantEvicted:   []string{"default/app-1"},
+		},
+		{
+			name:          "pod restarted on the target node",
+			publishedNode: "node2",
+			wantState:     v1alpha1.OperationStateMigratePruneReplica,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lv := newFakeReplicatedVolume("pvc-1", "node1", "node2")
+			lv.Spec.PersistentVolumeClaimName = "data-1"
+			lv.Spec.PersistentVolumeClaimNamespace = "default"
+			lv.Status.PublishedNodeName = tt.publishedNode
+			lvg := &v1alpha1.LocalVolumeGroup{ObjectMeta: metav1.ObjectMeta{Name: "lvg-1"}}
+			lvg.Spec.Accessibility.Nodes = []string{"node1", "node2"}
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app-1"}}
+			pod.Spec.NodeName = tt.publishedNode
+			pod.Spec.Volumes = []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data-1"}}}}
+			migrate := newFakeOnlineMigrate(v1alpha1.OperationStateMigrateSwitchover)
+			migrate.Spec.EvictPod = tt.evictPod
+			m := newFakeMigrateManager(t, lv, lvg, pod, migrate)
+
+			if err := m.volumeMigrateSwitchover(migrate, lvg); (err != nil) != tt.wantErr {
+				t.Errorf("volumeMigrateSwitchover() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if migrate.Status.State != tt.wantState {
+				t.Errorf("volumeMigrateSwitchover() got state %s, want %s", migrate.Status.State, tt.wantState)
+			}
+			if evicted := m.podEvictor.(*fakePodEvictor).evicted; !reflect.DeepEqual(evicted, tt.wantEvicted) {
+				t.Errorf("volumeMigrateSwitchover() evicted %v, want %v", evicted, tt.wantEvicted)
+			}
+
+			// the pod can only be scheduled to the target node
+			vol := &v1alpha1.LocalVolume{}
+			if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: lv.Name}, vol); err != nil {
+				t.Fatalf("Get LocalVolume error = %v", err)
+			}
+			if vol.Annotations[v1alpha1.VolumeMigrateCompletedAnnoKey] != v1alpha1.MigrateSwitchover || vol.Annotations[v1alpha1.VolumeMigrateTargetNodeAnnoKey] != "node2" {
+				t.Errorf("volumeMigrateSwitchover() got annotations %v", vol.Annotations)
+			}
+			if !reflect.DeepEqual(lvg.Spec.Accessibility.Nodes, []string{"node2"}) {
+				t.Errorf("volumeMigrateSwitchover() got accessibility %v", lvg.Spec.Accessibility.Nodes)
+			}
+		})
+	}
+}
+
+func Test_manager_volumeMigrateAbort_rollback(t *testing.T) {
+	lv := newFakeReplicatedVolume("pvc-1", "node1", "node2")
+	lv.Spec.Config.ResyncBandwidthLimit = 1024
+	lv.Annotations = map[string]string{
+		v1alpha1.VolumeMigrateCompletedAnnoKey:  v1alpha1.MigrateSwitchover,
+		v1alpha1.VolumeMigrateTargetNodeAnnoKey: "node2",
+	}
+	lvg := &v1alpha1.LocalVolumeGroup{ObjectMeta: metav1.ObjectMeta{Name: "lvg-1"}}
+	lvg.Spec.Accessibility.Nodes = []string{"node2"}
+	replica := &v1alpha1.LocalVolumeReplica{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1-node2"}}
+	replica.Spec.VolumeName = "pvc-1"
+	replica.Spec.NodeName = "node2"
+	migrate := newFakeOnlineMigrate(v1alpha1.OperationStateToBeAborted)
+	m := newFakeMigrateManager(t, lv, lvg, replica, migrate)
+
+	if err := m.volumeMigrateAbort(migrate, lvg); err != nil {
+		t.Fatalf("volumeMigrateAbort() error = %v", err)
+	}
+	if migrate.Status.State != v1alpha1.OperationStateAborting {
+		t.Errorf("volumeMigrateAbort() got state %s", migrate.Status.State)
+	}
+	if !reflect.DeepEqual(lvg.Spec.Accessibility.Nodes, []string{"node1"}) {
+		t.Errorf("volumeMigrateAbort() got accessibility %v", lvg.Spec.Accessibility.Nodes)
+	}
+	vol := &v1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: lv.Name}, vol); err != nil {
+		t.Fatalf("Get LocalVolume error = %v", err)
+	}
+	if vol.Spec.ReplicaNumber != 1 || len(vol.Spec.Config.Replicas) != 1 || vol.Spec.Config.Replicas[0].Hostname != "node1" ||
+		vol.Spec.Config.ResyncBandwidthLimit != 0 || len(vol.Annotations) != 0 {
+		t.Errorf("volumeMigrateAbort() got volume %+v, annotations %v", vol.Spec.Config, vol.Annotations)
+	}
+
+	// wait for the new replica to be removed
+	if err := m.volumeMigrateRollback(migrate); err == nil {
+		t.Error("volumeMigrateRollback() got no error when the new replica exists")
+	}
+	if err := m.apiClient.Delete(context.TODO(), replica); err != nil {
+		t.Fatalf("Delete LocalVolumeReplica error = %v", err)
+	}
+	if err := m.volumeMigrateRollback(migrate); err != nil {
+		t.Fatalf("volumeMigrateRollback() error = %v", err)
+	}
+	if migrate.Status.State != v1alpha1.OperationStateAborted {
+		t.Errorf("volumeMigrateRollback() got state %s", migrate.Status.State)
+	}
+}
+
+func Test_manager_volumeMigrateAbort_publishedOnTarget(t *testing.T) {
+	lv := newFakeReplicatedVolume("pvc-1", "node1", "node2")
+	lv.Status.PublishedNodeName = "node2"
+	lvg := &v1alpha1.LocalVolumeGroup{ObjectMeta: metav1.ObjectMeta{Name: "lvg-1"}}
+	lvg.Spec.Accessibility.Nodes = []string{"node2"}
+	migrate := newFakeOnlineMigrate(v1alpha1.OperationStateMigrateSwitchover)
+	migrate.Spec.Abort = true
+	m := newFakeMigrateManager(t, lv, lvg, migrate)
+
+	// the abort is refused, the migration goes on to prune the source replica
+	if err := m.processVolumeMigrate(migrate.Name); err != nil {
+		t.Fatalf("processVolumeMigrate() error = %v", err)
+	}
+	got := &v1alpha1.LocalVolumeMigrate{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: migrate.Name}, got); err != nil {
+		t.Fatalf("Get LocalVolumeMigrate error = %v", err)
+	}
+	if got.Status.State != v1alpha1.OperationStateMigratePruneReplica {
+		t.Errorf("processVolumeMigrate() got state %s, want %s", got.Status.State, v1alpha1.OperationStateMigratePruneReplica)
+	}
+
+	// the pod restarted on the target node after the abort is accepted
+	got.Status.State = v1alpha1.OperationStateToBeAborted
+	if err := m.volumeMigrateAbort(got, lvg); err == nil {
+		t.Error("volumeMigrateAbort() got no error when the volume is published on the target node")
+	}
+	vol := &v1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: lv.Name}, vol); err != nil {
+		t.Fatalf("Get LocalVolume error = %v", err)
+	}
+	if vol.Spec.ReplicaNumber != 2 || len(vol.Spec.Config.Replicas) != 2 {
+		t.Errorf("volumeMigrateAbort() removed the replica in use: %+v", vol.Spec.Config)
+	}
+	if got.Status.State != v1alpha1.OperationStateToBeAborted {
+		t.Errorf("volumeMigrateAbort() got state %s", got.Status.State)
+	}
+}
@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+func (m *manager) startVolumeScrubScheduleTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("Volume Scrub Schedule Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.volumeScrubScheduleTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the Volume Scrub Schedule worker")
+				break
+			}
+			if err := m.processVolumeScrubSchedule(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.volumeScrubScheduleTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process Volume Scrub Schedule task, retry later")
+				m.volumeScrubScheduleTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a Volume Scrub Schedule task.")
+				m.volumeScrubScheduleTaskQueue.Forget(task)
+			}
+			m.volumeScrubScheduleTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.volumeScrubScheduleTaskQueue.Shutdown()
+}
+
+func (m *manager) processVolumeScrubSchedule(scheduleName string) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeScrubSchedule": scheduleName})
+	logCtx.Debug("Working on a VolumeScrubSchedule task")
+	schedule := &apisv1alpha1.LocalVolumeScrubSchedule{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: scheduleName}, schedule); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get VolumeScrubSchedule from cache")
+			return err
+		}
+		logCtx.Info("Not found the VolumeScrubSchedule from cache, should be deleted already")
+		return nil
+	}
+
+	if schedule.Spec.Suspend {
+		if schedule.Status.NextScheduleTime == nil {
+			return nil
+		}
+		schedule.Status.NextScheduleTime = nil
+		schedule.Status.Schedule = ""
+		return m.apiClient.Status().Update(context.TODO(), schedule)
+	}
+
+	now := time.Now()
+	nextScheduleTime, due, err := nextCronScheduleTime(schedule.Spec.Schedule, schedule.Status.Schedule, schedule.Status.NextScheduleTime, now)
+	if err != nil {
+		logCtx.WithError(err).Error("Invalid schedule of VolumeScrubSchedule")
+		if schedule.Status.Message == err.Error() && schedule.Status.NextScheduleTime == nil {
+			return nil
+		}
+		schedule.Status.Message = err.Error()
+		schedule.Status.NextScheduleTime = nil
+		schedule.Status.Schedule = ""
+		return m.apiClient.Status().Update(context.TODO(), schedule)
+	}
+
+	if due {
+		m.createScheduledVolumeScrubs(schedule, now)
+		m.pruneScheduledVolumeScrubs(schedule)
+	}
+	if due || schedule.Status.NextScheduleTime != nextScheduleTime {
+		if !due {
+			// the schedule is new or changed
+			schedule.Status.Message = ""
+		}
+		schedule.Status.NextScheduleTime = nextScheduleTime
+		schedule.Status.Schedule = schedule.Spec.Schedule
+		if err := m.apiClient.Status().Update(context.TODO(), schedule); err != nil {
+			return err
+		}
+	}
+
+	m.volumeScrubScheduleTaskQueue.AddAfter(schedule.Name, time.Until(schedule.Status.NextScheduleTime.Time))
+	return nil
+}
+
+// createScheduledVolumeScrubs creates the scrubs for all the ready volumes in the pool. The volume which is
+// still being scrubbed is skipped
+func (m *manager) createScheduledVolumeScrubs(schedule *apisv1alpha1.LocalVolumeScrubSchedule, now time.Time) {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeScrubSchedule": schedule.Name, "pool": schedule.Spec.PoolName})
+	logCtx.Debug("Creating scheduled VolumeScrubs")
+
+	scheduleTime := metav1.NewTime(now)
+	schedule.Status.LastScheduleTime = &scheduleTime
+	schedule.Status.Volumes = nil
+	schedule.Status.Message = ""
+
+	volumeList := &apisv1alpha1.LocalVolumeList{}
+	if err := m.apiClient.List(context.TODO(), volumeList); err != nil {
+		logCtx.WithError(err).Error("Failed to list volumes")
+		schedule.Status.Message = fmt.Sprintf("failed to list volumes: %v", err)
+		return
+	}
+
+	for _, volume := range volumeList.Items {
+		if volume.Spec.PoolName != schedule.Spec.PoolName || volume.Status.State != apisv1alpha1.VolumeStateReady {
+			continue
+		}
+		schedule.Status.Volumes = append(schedule.Status.Volumes, volume.Name)
+
+		inProgressScrub, err := m.getInProgressVolumeScrub(volume.Name, "")
+		if err != nil {
+			logCtx.WithField("volume", volume.Name).WithError(err).Error("Failed to check VolumeScrubs")
+			continue
+		}
+		if inProgressScrub != nil {
+			logCtx.WithFields(log.Fields{"volume": volume.Name, "scrub": inProgressScrub.Name}).Info("Volume is still being scrubbed, skip it")
+			continue
+		}
+
+		volumeScrub := &apisv1alpha1.LocalVolumeScrub{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("%s-%s-%s", schedule.Name, volume.Name, now.UTC().Format("20060102150405")),
+				Labels: map[string]string{
+					apisv1alpha1.VolumeScrubScheduleLabelKey: schedule.Name,
+					apisv1alpha1.VolumeScrubVolumeLabelKey:   volume.Name,
+				},
+			},
+			Spec: apisv1alpha1.LocalVolumeScrubSpec{
+				VolumeName: volume.Name,
+			},
+		}
+		if err := m.apiClient.Create(context.TODO(), volumeScrub); err != nil && !errors.IsAlreadyExists(err) {
+			logCtx.WithField("volume", volume.Name).WithError(err).Error("Failed to create scheduled VolumeScrub")
+			schedule.Status.Message = fmt.Sprintf("failed to create scrub for volume %s: %v", volume.Name, err)
+		}
+	}
+}
+
+// pruneScheduledVolumeScrubs deletes the finished scheduled scrubs of each volume beyond the history limit
+func (m *manager) pruneScheduledVolumeScrubs(schedule *apisv1alpha1.LocalVolumeScrubSchedule) {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeScrubSchedule": schedule.Name})
+	logCtx.Debug("Pruning scheduled VolumeScrubs")
+
+	volumeScrubList := &apisv1alpha1.LocalVolumeScrubList{}
+	if err := m.apiClient.List(context.TODO(), volumeScrubList, client.MatchingLabels{apisv1alpha1.VolumeScrubScheduleLabelKey: schedule.Name}); err != nil {
+		logCtx.WithError(err).Error("Failed to list scheduled VolumeScrubs")
+		return
+	}
+
+	historyLimit := schedule.Spec.HistoryLimit
+	if historyLimit <= 0 {
+		historyLimit = apisv1alpha1.VolumeScrubDefaultHistoryLimit
+	}
+
+	volumeScrubs := map[string][]apisv1alpha1.LocalVolumeScrub{}
+	for _, volumeScrub := range volumeScrubList.Items {
+		volumeScrubs[volumeScrub.Spec.VolumeName] = append(volumeScrubs[volumeScrub.Spec.VolumeName], volumeScrub)
+	}
+	for _, scrubs := range volumeScrubs {
+		for _, volumeScrub := range selectVolumeScrubsToPrune(scrubs, historyLimit) {
+			if err := m.apiClient.Delete(context.TODO(), &volumeScrub); err != nil && !errors.IsNotFound(err) {
+				logCtx.WithField("scrub", volumeScrub.Name).WithError(err).Error("Failed to prune VolumeScrub")
+				continue
+			}
+			logCtx.WithField("scrub", volumeScrub.Name).Info("Pruned VolumeScrub")
+		}
+	}
+}
+
+// selectVolumeScrubsToPrune returns the finished scrubs of a volume except the latest ones within the history limit
+func selectVolumeScrubsToPrune(scrubs []apisv1alpha1.LocalVolumeScrub, historyLimit int) []apisv1alpha1.LocalVolumeScrub {
+	finished := map[string]apisv1alpha1.LocalVolumeScrub{}
+	var objects []scheduledObject
+	for _, scrub := range scrubs {
+		switch scrub.Status.State {
+		case apisv1alpha1.OperationStateCompleted, apisv1alpha1.OperationStateFailed, apisv1alpha1.OperationStateAborted:
+			finished[scrub.Name] = scrub
+			objects = append(objects, scheduledObject{name: scrub.Name, creationTimestamp: scrub.CreationTimestamp})
+		}
+	}
+
+	var pruned []apisv1alpha1.LocalVolumeScrub
+	for _, name := range selectScheduledObjectsToPrune(objects, apisv1alpha1.SnapshotRetention{KeepLast: historyLimit}) {
+		pruned = append(pruned, finished[name])
+	}
+	return pruned
+}
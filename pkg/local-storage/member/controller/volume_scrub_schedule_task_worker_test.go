@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/common"
+)
+
+func genFakeVolumeScrub(name string, volume string, state v1alpha1.State, age time.Duration) *v1alpha1.LocalVolumeScrub {
+	volumeScrub := &v1alpha1.LocalVolumeScrub{ObjectMeta: metav1.ObjectMeta{
+		Name:              name,
+		Labels:            map[string]string{v1alpha1.VolumeScrubScheduleLabelKey: "weekly"},
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-age)),
+	}}
+	volumeScrub.Spec.VolumeName = volume
+	volumeScrub.Status.State = state
+	return volumeScrub
+}
+
+func Test_selectVolumeScrubsToPrune(t *testing.T) {
+	scrubs := []v1alpha1.LocalVolumeScrub{
+		*genFakeVolumeScrub("scrub-1", "pvc-1", v1alpha1.OperationStateCompleted, 4*time.Hour),
+		*genFakeVolumeScrub("scrub-2", "pvc-1", v1alpha1.OperationStateFailed, 3*time.Hour),
+		*genFakeVolumeScrub("scrub-3", "pvc-1", v1alpha1.OperationStateCompleted, 2*time.Hour),
+		*genFakeVolumeScrub("scrub-4", "pvc-1", v1alpha1.OperationStateInProgress, time.Hour),
+	}
+	tests := []struct {
+		name         string
+		historyLimit int
+		want         []string
+	}{
+		{name: "within limit", historyLimit: 3, want: nil},
+		{name: "keep latest 2", historyLimit: 2, want: []string{"scrub-1"}},
+		{name: "keep latest 1", historyLimit: 1, want: []string{"scrub-1", "scrub-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, scrub := range selectVolumeScrubsToPrune(scrubs, tt.historyLimit) {
+				got = append(got, scrub.Name)
+			}
+			sort.Strings(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("selectVolumeScrubsToPrune() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_manager_processVolumeScrubSchedule(t *testing.T) {
+	volume := genFakeScrubVolume("pvc-1", false, false, "node1")
+	runningVolume := genFakeScrubVolume("pvc-2", false, false, "node1")
+	otherPoolVolume := genFakeScrubVolume("pvc-3", false, false, "node1")
+	otherPoolVolume.Spec.PoolName = v1alpha1.PoolNameForSSD
+	runningScrub := genFakeVolumeScrub("weekly-pvc-2-1", runningVolume.Name, v1alpha1.OperationStateInProgress, time.Hour)
+	oldScrub := genFakeVolumeScrub("weekly-pvc-1-1", volume.Name, v1alpha1.OperationStateCompleted, time.Hour)
+
+	schedule := &v1alpha1.LocalVolumeScrubSchedule{ObjectMeta: metav1.ObjectMeta{Name: "weekly"}}
+	schedule.Spec.Schedule = "@weekly"
+	schedule.Spec.PoolName = v1alpha1.PoolNameForHDD
+	schedule.Spec.HistoryLimit = 1
+	schedule.Status.NextScheduleTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+	schedule.Status.Schedule = schedule.Spec.Schedule
+
+	s := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(s)
+	m := &manager{
+		apiClient:                    fake.NewClientBuilder().WithScheme(s).WithObjects(volume, runningVolume, otherPoolVolume, runningScrub, oldScrub, schedule).Build(),
+		volumeScrubScheduleTaskQueue: common.NewTaskQueue("VolumeScrubScheduleTask", maxRetries),
+		logger:                       log.WithField("Module", "ControllerManager"),
+	}
+	defer m.volumeScrubScheduleTaskQueue.Shutdown()
+
+	if err := m.processVolumeScrubSchedule(schedule.Name); err != nil {
+		t.Fatalf("processVolumeScrubSchedule() error = %v", err)
+	}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: schedule.Name}, schedule); err != nil {
+		t.Fatalf("Get LocalVolumeScrubSchedule error = %v", err)
+	}
+	sort.Strings(schedule.Status.Volumes)
+	if !reflect.DeepEqual(schedule.Status.Volumes, []string{volume.Name, runningVolume.Name}) ||
+		schedule.Status.LastScheduleTime == nil || !schedule.Status.NextScheduleTime.After(time.Now()) {
+		t.Errorf("processVolumeScrubSchedule() got status %+v", schedule.Status)
+	}
+
+	// the running volume is skipped, and the finished scrub within the history limit is kept
+	scrubList := &v1alpha1.LocalVolumeScrubList{}
+	if err := m.apiClient.List(context.TODO(), scrubList, client.MatchingLabels{v1alpha1.VolumeScrubScheduleLabelKey: schedule.Name}); err != nil {
+		t.Fatalf("List LocalVolumeScrub error = %v", err)
+	}
+	volumeScrubs := map[string]int{}
+	for _, scrub := range scrubList.Items {
+		volumeScrubs[scrub.Spec.VolumeName]++
+	}
+	if want := map[string]int{volume.Name: 2, runningVolume.Name: 1}; !reflect.DeepEqual(volumeScrubs, want) {
+		t.Errorf("processVolumeScrubSchedule() got scrubs %v, want %v", volumeScrubs, want)
+	}
+
+	// the changed schedule starts over from the next schedule time of the new one
+	lastScheduleTime := schedule.Status.LastScheduleTime
+	schedule.Spec.Schedule = "@daily"
+	schedule.Status.NextScheduleTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+	if err := m.apiClient.Update(context.TODO(), schedule); err != nil {
+		t.Fatalf("Update LocalVolumeScrubSchedule error = %v", err)
+	}
+	if err := m.processVolumeScrubSchedule(schedule.Name); err != nil {
+		t.Fatalf("processVolumeScrubSchedule() error = %v", err)
+	}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: schedule.Name}, schedule); err != nil {
+		t.Fatalf("Get LocalVolumeScrubSchedule error = %v", err)
+	}
+	if schedule.Status.Schedule != "@daily" || !schedule.Status.NextScheduleTime.After(time.Now()) ||
+		!schedule.Status.LastScheduleTime.Equal(lastScheduleTime) {
+		t.Errorf("processVolumeScrubSchedule() got status %+v for changed schedule", schedule.Status)
+	}
+}
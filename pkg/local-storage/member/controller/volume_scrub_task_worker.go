@@ -0,0 +1,233 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+func (m *manager) startVolumeScrubTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("Volume Scrub Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.volumeScrubTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the Volume Scrub worker")
+				break
+			}
+			if err := m.processVolumeScrub(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.volumeScrubTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process Volume Scrub task, retry later")
+				m.volumeScrubTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a Volume Scrub task.")
+				m.volumeScrubTaskQueue.Forget(task)
+			}
+			m.volumeScrubTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.volumeScrubTaskQueue.Shutdown()
+}
+
+func (m *manager) processVolumeScrub(scrubName string) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeScrub": scrubName})
+	logCtx.Debug("Working on a VolumeScrub task")
+	volumeScrub := &apisv1alpha1.LocalVolumeScrub{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: scrubName}, volumeScrub); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get VolumeScrub from cache")
+			return err
+		}
+		logCtx.Info("Not found the VolumeScrub from cache, should be deleted already")
+		return nil
+	}
+
+	if volumeScrub.Spec.Abort &&
+		volumeScrub.Status.State != apisv1alpha1.OperationStateToBeAborted &&
+		volumeScrub.Status.State != apisv1alpha1.OperationStateAborted &&
+		volumeScrub.Status.State != apisv1alpha1.OperationStateCompleted &&
+		volumeScrub.Status.State != apisv1alpha1.OperationStateFailed {
+
+		volumeScrub.Status.State = apisv1alpha1.OperationStateToBeAborted
+		return m.apiClient.Status().Update(context.TODO(), volumeScrub)
+	}
+
+	logCtx = m.logger.WithFields(log.Fields{"VolumeScrub": volumeScrub.Name, "Spec": volumeScrub.Spec, "Status": volumeScrub.Status})
+	logCtx.Debug("Starting to process a VolumeScrub")
+
+	// state chain: (empty) -> Submitted -> InProgress -> Completed/Failed
+	// the data is verified by the nodes in the results when InProgress, and the scrub completes after all of them finish
+	switch volumeScrub.Status.State {
+	case "":
+		return m.volumeScrubSubmit(volumeScrub)
+	case apisv1alpha1.OperationStateSubmitted:
+		return m.volumeScrubStart(volumeScrub)
+	case apisv1alpha1.OperationStateInProgress:
+		return m.checkInProgressVolumeScrub(volumeScrub)
+	case apisv1alpha1.OperationStateToBeAborted:
+		return m.volumeScrubAbort(volumeScrub)
+	case apisv1alpha1.OperationStateCompleted, apisv1alpha1.OperationStateFailed, apisv1alpha1.OperationStateAborted:
+		return nil
+	default:
+		logCtx.Error("Invalid state/phase")
+	}
+	return fmt.Errorf("invalid state")
+}
+
+func (m *manager) volumeScrubSubmit(volumeScrub *apisv1alpha1.LocalVolumeScrub) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeScrub": volumeScrub.Name, "Spec": volumeScrub.Spec})
+	logCtx.Debug("Submit a VolumeScrub")
+
+	volume := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeScrub.Spec.VolumeName}, volume); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get volume")
+			return err
+		}
+		volumeScrub.Status.State = apisv1alpha1.OperationStateFailed
+		volumeScrub.Status.Message = fmt.Sprintf("volume %s not found", volumeScrub.Spec.VolumeName)
+		return m.apiClient.Status().Update(context.TODO(), volumeScrub)
+	}
+	if volume.Status.State != apisv1alpha1.VolumeStateReady {
+		err := fmt.Errorf("volume %s is not ready", volume.Name)
+		logCtx.WithError(err).Error("Failed to submit VolumeScrub")
+		return err
+	}
+
+	if inProgressScrub, err := m.getInProgressVolumeScrub(volume.Name, volumeScrub.Name); err != nil {
+		logCtx.WithError(err).Error("Failed to list VolumeScrubs")
+		return err
+	} else if inProgressScrub != nil {
+		volumeScrub.Status.State = apisv1alpha1.OperationStateFailed
+		volumeScrub.Status.Message = fmt.Sprintf("volume is being scrubbed by %s", inProgressScrub.Name)
+		return m.apiClient.Status().Update(context.TODO(), volumeScrub)
+	}
+
+	volumeScrub.Status.PoolName = volume.Spec.PoolName
+	volumeScrub.Status.Results = getVolumeScrubResults(volume)
+	volumeScrub.Status.State = apisv1alpha1.OperationStateSubmitted
+	return m.apiClient.Status().Update(context.TODO(), volumeScrub)
+}
+
+// getVolumeScrubResults returns the scrubs to execute on the nodes according to the layout of the volume:
+// the HA volume is verified by DRBD from the primary node, each raid-backed replica runs a raid check,
+// and a single non-raid replica is checked against its latest snapshot
+func getVolumeScrubResults(volume *apisv1alpha1.LocalVolume) []apisv1alpha1.VolumeScrubResult {
+	var results []apisv1alpha1.VolumeScrubResult
+	nodes := volume.Spec.Accessibility.Nodes
+	if volume.Spec.Config != nil && len(volume.Spec.Config.Replicas) > 0 {
+		nodes = []string{}
+		for _, replica := range volume.Spec.Config.Replicas {
+			nodes = append(nodes, replica.Hostname)
+		}
+	}
+
+	if volume.Spec.Convertible && volume.Spec.Config != nil && len(volume.Spec.Config.Replicas) > 1 {
+		verifyNode := volume.Spec.Config.Replicas[0].Hostname
+		for _, replica := range volume.Spec.Config.Replicas {
+			if replica.Primary {
+				verifyNode = replica.Hostname
+				break
+			}
+		}
+		results = append(results, apisv1alpha1.VolumeScrubResult{NodeName: verifyNode, Method: apisv1alpha1.ScrubMethodDRBDVerify})
+	}
+
+	if volume.Spec.Mirror {
+		for _, nodeName := range nodes {
+			results = append(results, apisv1alpha1.VolumeScrubResult{NodeName: nodeName, Method: apisv1alpha1.ScrubMethodRaidCheck})
+		}
+	} else if len(results) == 0 && len(nodes) > 0 {
+		results = append(results, apisv1alpha1.VolumeScrubResult{NodeName: nodes[0], Method: apisv1alpha1.ScrubMethodSnapshotChecksum})
+	}
+	return results
+}
+
+// getInProgressVolumeScrub returns the other unfinished scrub of the volume, nil if not found
+func (m *manager) getInProgressVolumeScrub(volumeName string, excludedScrub string) (*apisv1alpha1.LocalVolumeScrub, error) {
+	volumeScrubList := &apisv1alpha1.LocalVolumeScrubList{}
+	if err := m.apiClient.List(context.TODO(), volumeScrubList); err != nil {
+		return nil, err
+	}
+	for i, volumeScrub := range volumeScrubList.Items {
+		if volumeScrub.Name == excludedScrub || volumeScrub.Spec.VolumeName != volumeName {
+			continue
+		}
+		if volumeScrub.Status.State == apisv1alpha1.OperationStateSubmitted || volumeScrub.Status.State == apisv1alpha1.OperationStateInProgress {
+			return &volumeScrubList.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *manager) volumeScrubStart(volumeScrub *apisv1alpha1.LocalVolumeScrub) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeScrub": volumeScrub.Name, "Spec": volumeScrub.Spec})
+	logCtx.Debug("Start a VolumeScrub")
+
+	startTime := metav1.Now()
+	volumeScrub.Status.StartTime = &startTime
+	volumeScrub.Status.State = apisv1alpha1.OperationStateInProgress
+	return m.apiClient.Status().Update(context.TODO(), volumeScrub)
+}
+
+func (m *manager) checkInProgressVolumeScrub(volumeScrub *apisv1alpha1.LocalVolumeScrub) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeScrub": volumeScrub.Name, "Spec": volumeScrub.Spec})
+	logCtx.Debug("Check a InProgress VolumeScrub")
+
+	var failures, inconsistencies []string
+	for _, result := range volumeScrub.Status.Results {
+		switch result.State {
+		case apisv1alpha1.OperationStateCompleted:
+			if result.Inconsistent {
+				inconsistencies = append(inconsistencies, fmt.Sprintf("%s on %s", result.Method, result.NodeName))
+			}
+		case apisv1alpha1.OperationStateFailed:
+			failures = append(failures, fmt.Sprintf("%s on %s: %s", result.Method, result.NodeName, result.Message))
+		default:
+			// wait for all the nodes to finish
+			return nil
+		}
+	}
+
+	completionTime := metav1.Now()
+	volumeScrub.Status.CompletionTime = &completionTime
+	volumeScrub.Status.Inconsistent = len(inconsistencies) > 0
+	switch {
+	case len(failures) > 0:
+		volumeScrub.Status.State = apisv1alpha1.OperationStateFailed
+		volumeScrub.Status.Message = fmt.Sprintf("scrub failed: %s", strings.Join(failures, "; "))
+	case len(inconsistencies) > 0:
+		volumeScrub.Status.State = apisv1alpha1.OperationStateCompleted
+		volumeScrub.Status.Message = fmt.Sprintf("inconsistent data found by %s", strings.Join(inconsistencies, ", "))
+	default:
+		volumeScrub.Status.State = apisv1alpha1.OperationStateCompleted
+		volumeScrub.Status.Message = ""
+	}
+	return m.apiClient.Status().Update(context.TODO(), volumeScrub)
+}
+
+func (m *manager) volumeScrubAbort(volumeScrub *apisv1alpha1.LocalVolumeScrub) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeScrub": volumeScrub.Name, "Spec": volumeScrub.Spec})
+	logCtx.Debug("Abort a VolumeScrub")
+
+	// the nodes stop the running DRBD verify, raid check or snapshot checksum, and mark them aborted
+	for _, result := range volumeScrub.Status.Results {
+		if result.State == apisv1alpha1.OperationStateInProgress {
+			return nil
+		}
+	}
+
+	completionTime := metav1.Now()
+	volumeScrub.Status.CompletionTime = &completionTime
+	volumeScrub.Status.State = apisv1alpha1.OperationStateAborted
+	return m.apiClient.Status().Update(context.TODO(), volumeScrub)
+}
@@ -0,0 +1,277 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+func newFakeScrubManager(t *testing.T, objs ...client.Object) *manager {
+	s := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(s); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	return &manager{
+		apiClient: fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build(),
+		logger:    log.WithField("Module", "ControllerManager"),
+	}
+}
+
+func genFakeScrubVolume(name string, convertible bool, mirror bool, nodes ...string) *v1alpha1.LocalVolume {
+	volume := &v1alpha1.LocalVolume{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	volume.Spec.PoolName = v1alpha1.PoolNameForHDD
+	volume.Spec.Convertible = convertible
+	volume.Spec.Mirror = mirror
+	volume.Spec.Accessibility.Nodes = nodes
+	volume.Spec.Config = &v1alpha1.VolumeConfig{VolumeName: name}
+	for i, node := range nodes {
+		volume.Spec.Config.Replicas = append(volume.Spec.Config.Replicas, v1alpha1.VolumeReplica{ID: i + 1, Hostname: node, Primary: i == len(nodes)-1})
+	}
+	volume.Status.State = v1alpha1.VolumeStateReady
+	return volume
+}
+
+func Test_getVolumeScrubResults(t *testing.T) {
+	tests := []struct {
+		name   string
+		volume *v1alpha1.LocalVolume
+		want   []v1alpha1.VolumeScrubResult
+	}{
+		{
+			name:   "single replica",
+			volume: genFakeScrubVolume("pvc-1", false, false, "node1"),
+			want: []v1alpha1.VolumeScrubResult{
+				{NodeName: "node1", Method: v1alpha1.ScrubMethodSnapshotChecksum},
+			},
+		},
+		{
+			name:   "convertible volume with single replica",
+			volume: genFakeScrubVolume("pvc-1", true, false, "node1"),
+			want: []v1alpha1.VolumeScrubResult{
+				{NodeName: "node1", Method: v1alpha1.ScrubMethodSnapshotChecksum},
+			},
+		},
+		{
+			name:   "mirrored single replica",
+			volume: genFakeScrubVolume("pvc-1", false, true, "node1"),
+			want: []v1alpha1.VolumeScrubResult{
+				{NodeName: "node1", Method: v1alpha1.ScrubMethodRaidCheck},
+			},
+		},
+		{
+			name:   "HA volume, verified from the primary",
+			volume: genFakeScrubVolume("pvc-1", true, false, "node1", "node2"),
+			want: []v1alpha1.VolumeScrubResult{
+				{NodeName: "node2", Method: v1alpha1.ScrubMethodDRBDVerify},
+			},
+		},
+		{
+			name:   "mirrored HA volume",
+			volume: genFakeScrubVolume("pvc-1", true, true, "node1", "node2"),
+			want: []v1alpha1.VolumeScrubResult{
+				{NodeName: "node2", Method: v1alpha1.ScrubMethodDRBDVerify},
+				{NodeName: "node1", Method: v1alpha1.ScrubMethodRaidCheck},
+				{NodeName: "node2", Method: v1alpha1.ScrubMethodRaidCheck},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getVolumeScrubResults(tt.volume); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getVolumeScrubResults() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_manager_processVolumeScrub(t *testing.T) {
+	volume := genFakeScrubVolume("pvc-1", true, false, "node1", "node2")
+	volumeScrub := &v1alpha1.LocalVolumeScrub{ObjectMeta: metav1.ObjectMeta{Name: "scrub-1"}}
+	volumeScrub.Spec.VolumeName = volume.Name
+
+	m := newFakeScrubManager(t, volume, volumeScrub)
+
+	// (empty) -> Submitted -> InProgress
+	for i := 0; i < 2; i++ {
+		if err := m.processVolumeScrub(volumeScrub.Name); err != nil {
+			t.Fatalf("processVolumeScrub() error = %v", err)
+		}
+	}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeScrub.Name}, volumeScrub); err != nil {
+		t.Fatalf("Get LocalVolumeScrub error = %v", err)
+	}
+	if volumeScrub.Status.State != v1alpha1.OperationStateInProgress || volumeScrub.Status.StartTime == nil ||
+		volumeScrub.Status.PoolName != v1alpha1.PoolNameForHDD || len(volumeScrub.Status.Results) != 1 {
+		t.Fatalf("processVolumeScrub() got status %+v", volumeScrub.Status)
+	}
+
+	// another scrub of the same volume is refused
+	otherScrub := &v1alpha1.LocalVolumeScrub{ObjectMeta: metav1.ObjectMeta{Name: "scrub-2"}}
+	otherScrub.Spec.VolumeName = volume.Name
+	if err := m.apiClient.Create(context.TODO(), otherScrub); err != nil {
+		t.Fatalf("Create LocalVolumeScrub error = %v", err)
+	}
+	if err := m.processVolumeScrub(otherScrub.Name); err != nil {
+		t.Fatalf("processVolumeScrub() error = %v", err)
+	}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: otherScrub.Name}, otherScrub); err != nil {
+		t.Fatalf("Get LocalVolumeScrub error = %v", err)
+	}
+	if otherScrub.Status.State != v1alpha1.OperationStateFailed {
+		t.Errorf("processVolumeScrub() got status %+v for the concurrent scrub", otherScrub.Status)
+	}
+
+	// wait for the node
+	if err := m.processVolumeScrub(volumeScrub.Name); err != nil {
+		t.Fatalf("processVolumeScrub() error = %v", err)
+	}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeScrub.Name}, volumeScrub); err != nil {
+		t.Fatalf("Get LocalVolumeScrub error = %v", err)
+	}
+	if volumeScrub.Status.State != v1alpha1.OperationStateInProgress {
+		t.Fatalf("processVolumeScrub() got state %s before the node completes", volumeScrub.Status.State)
+	}
+
+	// the node finds the out-of-sync blocks
+	volumeScrub.Status.Results[0].State = v1alpha1.OperationStateCompleted
+	volumeScrub.Status.Results[0].OutOfSyncBytes = 4096
+	volumeScrub.Status.Results[0].Inconsistent = true
+	if err := m.apiClient.Status().Update(context.TODO(), volumeScrub); err != nil {
+		t.Fatalf("Update LocalVolumeScrub error = %v", err)
+	}
+	if err := m.processVolumeScrub(volumeScrub.Name); err != nil {
+		t.Fatalf("processVolumeScrub() error = %v", err)
+	}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeScrub.Name}, volumeScrub); err != nil {
+		t.Fatalf("Get LocalVolumeScrub error = %v", err)
+	}
+	if volumeScrub.Status.State != v1alpha1.OperationStateCompleted || !volumeScrub.Status.Inconsistent ||
+		volumeScrub.Status.CompletionTime == nil || len(volumeScrub.Status.Message) == 0 {
+		t.Errorf("processVolumeScrub() got status %+v", volumeScrub.Status)
+	}
+}
+
+func Test_manager_checkInProgressVolumeScrub(t *testing.T) {
+	tests := []struct {
+		name             string
+		results          []v1alpha1.VolumeScrubResult
+		wantState        v1alpha1.State
+		wantInconsistent bool
+	}{
+		{
+			name: "running",
+			results: []v1alpha1.VolumeScrubResult{
+				{NodeName: "node1", Method: v1alpha1.ScrubMethodRaidCheck, State: v1alpha1.OperationStateCompleted},
+				{NodeName: "node2", Method: v1alpha1.ScrubMethodRaidCheck, State: v1alpha1.OperationStateInProgress},
+			},
+			wantState: v1alpha1.OperationStateInProgress,
+		},
+		{
+			name: "consistent",
+			results: []v1alpha1.VolumeScrubResult{
+				{NodeName: "node1", Method: v1alpha1.ScrubMethodRaidCheck, State: v1alpha1.OperationStateCompleted},
+				{NodeName: "node2", Method: v1alpha1.ScrubMethodRaidCheck, State: v1alpha1.OperationStateCompleted},
+			},
+			wantState: v1alpha1.OperationStateCompleted,
+		},
+		{
+			name: "inconsistent",
+			results: []v1alpha1.VolumeScrubResult{
+				{NodeName: "node1", Method: v1alpha1.ScrubMethodRaidCheck, State: v1alpha1.OperationStateCompleted},
+				{NodeName: "node2", Method: v1alpha1.ScrubMethodRaidCheck, State: v1alpha1.OperationStateCompleted, Mismatches: 8, Inconsistent: true},
+			},
+			wantState:        v1alpha1.OperationStateCompleted,
+			wantInconsistent: true,
+		},
+		{
+			name: "failed",
+			results: []v1alpha1.VolumeScrubResult{
+				{NodeName: "node1", Method: v1alpha1.ScrubMethodRaidCheck, State: v1alpha1.OperationStateFailed, Message: "not a raid LV"},
+				{NodeName: "node2", Method: v1alpha1.ScrubMethodRaidCheck, State: v1alpha1.OperationStateCompleted, Mismatches: 8, Inconsistent: true},
+			},
+			wantState:        v1alpha1.OperationStateFailed,
+			wantInconsistent: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			volumeScrub := &v1alpha1.LocalVolumeScrub{ObjectMeta: metav1.ObjectMeta{Name: "scrub-1"}}
+			volumeScrub.Spec.VolumeName = "pvc-1"
+			volumeScrub.Status.State = v1alpha1.OperationStateInProgress
+			volumeScrub.Status.Results = tt.results
+
+			m := newFakeScrubManager(t, volumeScrub)
+			if err := m.checkInProgressVolumeScrub(volumeScrub); err != nil {
+				t.Fatalf("checkInProgressVolumeScrub() error = %v", err)
+			}
+
+			got := &v1alpha1.LocalVolumeScrub{}
+			if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeScrub.Name}, got); err != nil {
+				t.Fatalf("Get LocalVolumeScrub error = %v", err)
+			}
+			if got.Status.State != tt.wantState || got.Status.Inconsistent != tt.wantInconsistent {
+				t.Errorf("checkInProgressVolumeScrub() got status %+v", got.Status)
+			}
+		})
+	}
+}
+
+func Test_manager_volumeScrubAbort(t *testing.T) {
+	tests := []struct {
+		name      string
+		results   []v1alpha1.VolumeScrubResult
+		wantState v1alpha1.State
+	}{
+		{
+			name: "wait for the node to stop",
+			results: []v1alpha1.VolumeScrubResult{
+				{NodeName: "node1", Method: v1alpha1.ScrubMethodDRBDVerify, State: v1alpha1.OperationStateInProgress},
+			},
+			wantState: v1alpha1.OperationStateToBeAborted,
+		},
+		{
+			name: "stopped by the node",
+			results: []v1alpha1.VolumeScrubResult{
+				{NodeName: "node1", Method: v1alpha1.ScrubMethodDRBDVerify, State: v1alpha1.OperationStateAborted},
+			},
+			wantState: v1alpha1.OperationStateAborted,
+		},
+		{
+			name: "not started",
+			results: []v1alpha1.VolumeScrubResult{
+				{NodeName: "node1", Method: v1alpha1.ScrubMethodRaidCheck},
+			},
+			wantState: v1alpha1.OperationStateAborted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			volumeScrub := &v1alpha1.LocalVolumeScrub{ObjectMeta: metav1.ObjectMeta{Name: "scrub-1"}}
+			volumeScrub.Spec.VolumeName = "pvc-1"
+			volumeScrub.Spec.Abort = true
+			volumeScrub.Status.State = v1alpha1.OperationStateToBeAborted
+			volumeScrub.Status.Results = tt.results
+
+			m := newFakeScrubManager(t, volumeScrub)
+			if err := m.processVolumeScrub(volumeScrub.Name); err != nil {
+				t.Fatalf("processVolumeScrub() error = %v", err)
+			}
+
+			got := &v1alpha1.LocalVolumeScrub{}
+			if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeScrub.Name}, got); err != nil {
+				t.Fatalf("Get LocalVolumeScrub error = %v", err)
+			}
+			if got.Status.State != tt.wantState {
+				t.Errorf("processVolumeScrub() got state %s, want %s", got.Status.State, tt.wantState)
+			}
+		})
+	}
+}
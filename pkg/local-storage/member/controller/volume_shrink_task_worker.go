@@ -0,0 +1,344 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/utils"
+)
+
+func (m *manager) startVolumeShrinkTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("Volume Shrink Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.volumeShrinkTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the Volume Shrink worker")
+				break
+			}
+			if err := m.processVolumeShrink(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.volumeShrinkTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process Volume Shrink task, retry later")
+				m.volumeShrinkTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a Volume Shrink task.")
+				m.volumeShrinkTaskQueue.Forget(task)
+			}
+			m.volumeShrinkTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.volumeShrinkTaskQueue.Shutdown()
+}
+
+func (m *manager) processVolumeShrink(volShrinkName string) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeShrink": volShrinkName})
+	logCtx.Debug("Working on a VolumeShrink task")
+	volShrink := &apisv1alpha1.LocalVolumeShrink{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: volShrinkName}, volShrink); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get VolumeShrink from cache")
+			return err
+		}
+		logCtx.Info("Not found the VolumeShrink from cache, should be deleted already")
+		return nil
+	}
+
+	// the volume is untouched before the filesystem is shrunk, so it's aborted directly.
+	// The node aborts it if the filesystem is not shrunk yet
+	if volShrink.Spec.Abort &&
+		(volShrink.Status.State == "" || volShrink.Status.State == apisv1alpha1.OperationStateSubmitted) {
+
+		volShrink.Status.State = apisv1alpha1.OperationStateAborted
+		volShrink.Status.Message = "aborted by user"
+		return m.apiClient.Status().Update(context.TODO(), volShrink)
+	}
+
+	logCtx = m.logger.WithFields(log.Fields{"VolumeShrink": volShrink.Name, "Spec": volShrink.Spec, "Status": volShrink.Status})
+	logCtx.Debug("Starting to process a VolumeShrink")
+
+	// state chain: (empty) -> Submitted -> ShrinkFilesystem -> ShrinkReplica -> InProgress -> Completed/Failed/Aborted
+	// the node shrinks the filesystem when ShrinkFilesystem, and the controller lowers the capacity of the volume and
+	// its replicas when ShrinkReplica. The replicas are reduced by the nodes, and the capacity is returned to the pool
+	switch volShrink.Status.State {
+	case "":
+		return m.volumeShrinkSubmit(volShrink)
+	case apisv1alpha1.OperationStateSubmitted:
+		return m.volumeShrinkStart(volShrink)
+	case apisv1alpha1.OperationStateShrinkReplica:
+		return m.volumeShrinkReplicas(volShrink)
+	case apisv1alpha1.OperationStateInProgress:
+		return m.volumeShrinkInProgress(volShrink)
+	case apisv1alpha1.OperationStateCompleted, apisv1alpha1.OperationStateFailed, apisv1alpha1.OperationStateAborted:
+		return m.volumeShrinkCleanup(volShrink)
+	case apisv1alpha1.OperationStateShrinkFilesystem:
+		return nil
+	default:
+		logCtx.Error("Invalid state/phase")
+	}
+	return fmt.Errorf("invalid state")
+}
+
+func (m *manager) volumeShrinkSubmit(volShrink *apisv1alpha1.LocalVolumeShrink) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeShrink": volShrink.Name, "Spec": volShrink.Spec})
+	logCtx.Debug("Submit a VolumeShrink")
+
+	volume := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volShrink.Spec.VolumeName}, volume); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get volume")
+			return err
+		}
+		return m.volumeShrinkFail(volShrink, fmt.Sprintf("volume %s not found", volShrink.Spec.VolumeName))
+	}
+	if err := m.validateVolumeShrink(volShrink, volume); err != nil {
+		return m.volumeShrinkFail(volShrink, err.Error())
+	}
+
+	volShrink.Status.OriginalCapacityBytes = volume.Status.AllocatedCapacityBytes
+	volShrink.Status.State = apisv1alpha1.OperationStateSubmitted
+	return m.apiClient.Status().Update(context.TODO(), volShrink)
+}
+
+// validateVolumeShrink checks if the volume can be shrunk. The encrypted or cached volume has another device
+// stacked on the LV, and the ZFS volume is not formatted by HwameiStor, so they are not supported
+func (m *manager) validateVolumeShrink(volShrink *apisv1alpha1.LocalVolumeShrink, volume *apisv1alpha1.LocalVolume) error {
+	if volume.Spec.StorageBackend == apisv1alpha1.StorageBackendZFS {
+		return fmt.Errorf("ZFS volume can't be shrunk")
+	}
+	if volume.Spec.VolumeEncrypt.Enable {
+		return fmt.Errorf("encrypted volume can't be shrunk")
+	}
+	if volume.Spec.VolumeCache != nil {
+		return fmt.Errorf("cached volume can't be shrunk, remove the cache first")
+	}
+	if volume.Status.PublishedRawBlock {
+		return fmt.Errorf("raw block volume can't be shrunk")
+	}
+	if utils.NumericToLVMBytes(volShrink.Spec.RequiredCapacityBytes) >= volume.Status.AllocatedCapacityBytes {
+		return fmt.Errorf("new capacity %d must be less than the current capacity %d", volShrink.Spec.RequiredCapacityBytes, volume.Status.AllocatedCapacityBytes)
+	}
+	if inProgress, exists := volume.Annotations[apisv1alpha1.VolumeShrinkInProgressAnnoKey]; exists && inProgress != volShrink.Name {
+		return fmt.Errorf("volume %s is being shrunk by %s", volume.Name, inProgress)
+	}
+
+	// the snapshot is a COW LV of the same size as the volume
+	snapshotList := &apisv1alpha1.LocalVolumeSnapshotList{}
+	if err := m.apiClient.List(context.TODO(), snapshotList); err != nil {
+		return err
+	}
+	for _, snapshot := range snapshotList.Items {
+		if snapshot.Spec.SourceVolume == volume.Name {
+			return fmt.Errorf("volume %s has snapshot %s, delete it first", volume.Name, snapshot.Name)
+		}
+	}
+	return nil
+}
+
+func (m *manager) volumeShrinkStart(volShrink *apisv1alpha1.LocalVolumeShrink) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeShrink": volShrink.Name, "Spec": volShrink.Spec})
+	logCtx.Debug("Start a VolumeShrink")
+
+	volume := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volShrink.Spec.VolumeName}, volume); err != nil {
+		logCtx.WithError(err).Error("Failed to get volume")
+		return err
+	}
+
+	// the filesystem can only be shrunk offline
+	if volume.Status.PublishedNodeName != "" {
+		message := "waiting for the volume to be unpublished"
+		if volShrink.Status.Message != message {
+			volShrink.Status.Message = message
+			if err := m.apiClient.Status().Update(context.TODO(), volShrink); err != nil {
+				return err
+			}
+		}
+		return fmt.Errorf("volume %s is still in use", volume.Name)
+	}
+	if volume.Status.State != apisv1alpha1.VolumeStateReady {
+		return fmt.Errorf("volume %s is not ready", volume.Name)
+	}
+
+	// from now on, the volume can't be published until it's shrunk
+	if volume.Annotations == nil {
+		volume.Annotations = map[string]string{}
+	}
+	if volume.Annotations[apisv1alpha1.VolumeShrinkInProgressAnnoKey] != volShrink.Name {
+		volume.Annotations[apisv1alpha1.VolumeShrinkInProgressAnnoKey] = volShrink.Name
+		if err := m.apiClient.Update(context.TODO(), volume); err != nil {
+			logCtx.WithError(err).Error("Failed to mark volume with the shrink")
+			return err
+		}
+	}
+
+	startTime := metav1.Now()
+	volShrink.Status.StartTime = &startTime
+	volShrink.Status.NodeName = getVolumePrimaryNode(volume)
+	volShrink.Status.Message = ""
+	volShrink.Status.State = apisv1alpha1.OperationStateShrinkFilesystem
+	return m.apiClient.Status().Update(context.TODO(), volShrink)
+}
+
+// getVolumePrimaryNode returns the node of the primary replica, which is the only one for the non-HA volume
+func getVolumePrimaryNode(volume *apisv1alpha1.LocalVolume) string {
+	if volume.Spec.Config != nil {
+		for _, replica := range volume.Spec.Config.Replicas {
+			if replica.Primary {
+				return replica.Hostname
+			}
+		}
+	}
+	nodes := getVolumeReplicaNodes(volume)
+	if len(nodes) == 0 {
+		return ""
+	}
+	return nodes[0]
+}
+
+func (m *manager) volumeShrinkReplicas(volShrink *apisv1alpha1.LocalVolumeShrink) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeShrink": volShrink.Name, "Spec": volShrink.Spec})
+	logCtx.Debug("Shrink the replicas of volume")
+
+	newCapacityBytes := utils.NumericToLVMBytes(volShrink.Spec.RequiredCapacityBytes)
+	volume := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volShrink.Spec.VolumeName}, volume); err != nil {
+		logCtx.WithError(err).Error("Failed to get volume")
+		return err
+	}
+	if volume.Spec.RequiredCapacityBytes != newCapacityBytes {
+		volume.Spec.RequiredCapacityBytes = newCapacityBytes
+		if volume.Spec.Config != nil {
+			volume.Spec.Config.RequiredCapacityBytes = newCapacityBytes
+		}
+		if err := m.apiClient.Update(context.TODO(), volume); err != nil {
+			logCtx.WithError(err).Error("Failed to update volume with the new capacity")
+			return err
+		}
+	}
+
+	replicas, err := m.getReplicasForVolume(volume.Name)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to list volume replicas")
+		return err
+	}
+	shrunk := true
+	for _, replica := range replicas {
+		if replica.Status.AllocatedCapacityBytes == newCapacityBytes {
+			if !volShrink.HasSub(replica.Name) {
+				volShrink.Status.Subs = append(volShrink.Status.Subs, replica.Name)
+			}
+			continue
+		}
+		shrunk = false
+		if replica.Spec.RequiredCapacityBytes == newCapacityBytes && replica.Annotations[apisv1alpha1.VolumeShrinkInProgressAnnoKey] == volShrink.Name {
+			continue
+		}
+		if replica.Annotations == nil {
+			replica.Annotations = map[string]string{}
+		}
+		replica.Annotations[apisv1alpha1.VolumeShrinkInProgressAnnoKey] = volShrink.Name
+		replica.Spec.RequiredCapacityBytes = newCapacityBytes
+		if err := m.apiClient.Update(context.TODO(), replica); err != nil {
+			logCtx.WithField("replica", replica.Name).WithError(err).Error("Failed to update volume replica with the new capacity")
+			return err
+		}
+	}
+	if !shrunk {
+		if err := m.apiClient.Status().Update(context.TODO(), volShrink); err != nil {
+			return err
+		}
+		return fmt.Errorf("not all the replicas are shrunk")
+	}
+
+	// the DRBD metadata is recreated on all the shrunk replicas, so the HA volume is initialized again
+	if volume.Spec.Config != nil && volume.Spec.Config.Convertible && volume.Spec.Config.Initialized {
+		volume.Spec.Config.Initialized = false
+		if err := m.apiClient.Update(context.TODO(), volume); err != nil {
+			logCtx.WithError(err).Error("Failed to reset the initialization of volume")
+			return err
+		}
+	}
+	if err := m.removeReplicaShrinkAnnotations(replicas); err != nil {
+		logCtx.WithError(err).Error("Failed to remove the shrink annotations of volume replicas")
+		return err
+	}
+	volShrink.Status.State = apisv1alpha1.OperationStateInProgress
+	return m.apiClient.Status().Update(context.TODO(), volShrink)
+}
+
+func (m *manager) removeReplicaShrinkAnnotations(replicas []*apisv1alpha1.LocalVolumeReplica) error {
+	for _, replica := range replicas {
+		if _, exists := replica.Annotations[apisv1alpha1.VolumeShrinkInProgressAnnoKey]; !exists {
+			continue
+		}
+		delete(replica.Annotations, apisv1alpha1.VolumeShrinkInProgressAnnoKey)
+		if err := m.apiClient.Update(context.TODO(), replica); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *manager) volumeShrinkInProgress(volShrink *apisv1alpha1.LocalVolumeShrink) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeShrink": volShrink.Name, "Spec": volShrink.Spec})
+	logCtx.Debug("Check the status of a VolumeShrink in progress")
+
+	volume := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volShrink.Spec.VolumeName}, volume); err != nil {
+		logCtx.WithError(err).Error("Failed to get volume")
+		return err
+	}
+	if volume.Status.State != apisv1alpha1.VolumeStateReady || volume.Status.AllocatedCapacityBytes != utils.NumericToLVMBytes(volShrink.Spec.RequiredCapacityBytes) {
+		logCtx.Debug("Volume is not ready")
+		return fmt.Errorf("volume not ready")
+	}
+
+	completionTime := metav1.Now()
+	volShrink.Status.CompletionTime = &completionTime
+	volShrink.Status.AllocatedCapacityBytes = volume.Status.AllocatedCapacityBytes
+	volShrink.Status.State = apisv1alpha1.OperationStateCompleted
+	volShrink.Status.Message = ""
+	return m.apiClient.Status().Update(context.TODO(), volShrink)
+}
+
+// volumeShrinkCleanup allows the volume to be published again once the shrink is finished
+func (m *manager) volumeShrinkCleanup(volShrink *apisv1alpha1.LocalVolumeShrink) error {
+	volume := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volShrink.Spec.VolumeName}, volume); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+	if volume.Annotations[apisv1alpha1.VolumeShrinkInProgressAnnoKey] != volShrink.Name {
+		return nil
+	}
+
+	replicas, err := m.getReplicasForVolume(volume.Name)
+	if err != nil {
+		return err
+	}
+	if err := m.removeReplicaShrinkAnnotations(replicas); err != nil {
+		return err
+	}
+
+	m.logger.WithFields(log.Fields{"VolumeShrink": volShrink.Name, "LocalVolume": volume.Name, "State": volShrink.Status.State}).Info("Volume shrink is finished")
+	delete(volume.Annotations, apisv1alpha1.VolumeShrinkInProgressAnnoKey)
+	return m.apiClient.Update(context.TODO(), volume)
+}
+
+func (m *manager) volumeShrinkFail(volShrink *apisv1alpha1.LocalVolumeShrink, message string) error {
+	completionTime := metav1.Now()
+	volShrink.Status.CompletionTime = &completionTime
+	volShrink.Status.State = apisv1alpha1.OperationStateFailed
+	volShrink.Status.Message = message
+	return m.apiClient.Status().Update(context.TODO(), volShrink)
+}
@@ -0,0 +1,143 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+func genFakeShrinkVolume(name string, capacityBytes int64, nodes ...string) *v1alpha1.LocalVolume {
+	volume := genFakeScrubVolume(name, len(nodes) > 1, false, nodes...)
+	volume.Spec.RequiredCapacityBytes = capacityBytes
+	volume.Spec.Config.RequiredCapacityBytes = capacityBytes
+	volume.Spec.Config.Convertible = volume.Spec.Convertible
+	volume.Spec.Config.Initialized = true
+	volume.Status.AllocatedCapacityBytes = capacityBytes
+	return volume
+}
+
+func genFakeShrinkReplica(volumeName string, node string, capacityBytes int64) *v1alpha1.LocalVolumeReplica {
+	replica := &v1alpha1.LocalVolumeReplica{ObjectMeta: metav1.ObjectMeta{Name: volumeName + "-" + node}}
+	replica.Spec.VolumeName = volumeName
+	replica.Spec.NodeName = node
+	replica.Spec.RequiredCapacityBytes = capacityBytes
+	replica.Status.AllocatedCapacityBytes = capacityBytes
+	return replica
+}
+
+func getFakeVolumeShrink(t *testing.T, m *manager, name string) *v1alpha1.LocalVolumeShrink {
+	volShrink := &v1alpha1.LocalVolumeShrink{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: name}, volShrink); err != nil {
+		t.Fatalf("Failed to get LocalVolumeShrink: %v", err)
+	}
+	return volShrink
+}
+
+func Test_manager_volumeShrinkSubmit(t *testing.T) {
+	encryptedVolume := genFakeEncryptedVolume("pvc-encrypted", "node1")
+	encryptedVolume.Status.AllocatedCapacityBytes = 2 * 1024 * 1024 * 1024
+	snapshot := &v1alpha1.LocalVolumeSnapshot{ObjectMeta: metav1.ObjectMeta{Name: "snap-1"}}
+	snapshot.Spec.SourceVolume = "pvc-snapshotted"
+
+	tests := []struct {
+		name      string
+		spec      v1alpha1.LocalVolumeShrinkSpec
+		wantState v1alpha1.State
+	}{
+		{
+			name:      "shrink",
+			spec:      v1alpha1.LocalVolumeShrinkSpec{VolumeName: "pvc-plain", RequiredCapacityBytes: 1024 * 1024 * 1024},
+			wantState: v1alpha1.OperationStateSubmitted,
+		},
+		{
+			name:      "larger capacity",
+			spec:      v1alpha1.LocalVolumeShrinkSpec{VolumeName: "pvc-plain", RequiredCapacityBytes: 4 * 1024 * 1024 * 1024},
+			wantState: v1alpha1.OperationStateFailed,
+		},
+		{
+			name:      "volume not found",
+			spec:      v1alpha1.LocalVolumeShrinkSpec{VolumeName: "pvc-not-exist", RequiredCapacityBytes: 1024 * 1024 * 1024},
+			wantState: v1alpha1.OperationStateFailed,
+		},
+		{
+			name:      "encrypted volume",
+			spec:      v1alpha1.LocalVolumeShrinkSpec{VolumeName: "pvc-encrypted", RequiredCapacityBytes: 1024 * 1024 * 1024},
+			wantState: v1alpha1.OperationStateFailed,
+		},
+		{
+			name:      "volume with snapshot",
+			spec:      v1alpha1.LocalVolumeShrinkSpec{VolumeName: "pvc-snapshotted", RequiredCapacityBytes: 1024 * 1024 * 1024},
+			wantState: v1alpha1.OperationStateFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			volShrink := &v1alpha1.LocalVolumeShrink{ObjectMeta: metav1.ObjectMeta{Name: "shrink"}, Spec: tt.spec}
+			m := newFakeKeyRotateManager(t, volShrink, snapshot, encryptedVolume,
+				genFakeShrinkVolume("pvc-plain", 2*1024*1024*1024, "node1"),
+				genFakeShrinkVolume("pvc-snapshotted", 2*1024*1024*1024, "node1"))
+
+			if err := m.volumeShrinkSubmit(volShrink); err != nil {
+				t.Fatalf("volumeShrinkSubmit() error = %v", err)
+			}
+			got := getFakeVolumeShrink(t, m, "shrink")
+			if got.Status.State != tt.wantState {
+				t.Errorf("volumeShrinkSubmit() got status %+v", got.Status)
+			}
+		})
+	}
+}
+
+func Test_manager_volumeShrinkReplicas(t *testing.T) {
+	const oldCapacityBytes, newCapacityBytes = 2 * 1024 * 1024 * 1024, 1024 * 1024 * 1024
+
+	volShrink := &v1alpha1.LocalVolumeShrink{ObjectMeta: metav1.ObjectMeta{Name: "shrink"}}
+	volShrink.Spec = v1alpha1.LocalVolumeShrinkSpec{VolumeName: "pvc-ha", RequiredCapacityBytes: newCapacityBytes}
+	volShrink.Status.State = v1alpha1.OperationStateShrinkReplica
+	shrunkReplica := genFakeShrinkReplica("pvc-ha", "node1", newCapacityBytes)
+	m := newFakeKeyRotateManager(t, volShrink, shrunkReplica,
+		genFakeShrinkReplica("pvc-ha", "node2", oldCapacityBytes), genFakeShrinkVolume("pvc-ha", oldCapacityBytes, "node1", "node2"))
+
+	// waiting for the replica on node2 to be shrunk
+	if err := m.volumeShrinkReplicas(volShrink); err == nil {
+		t.Fatal("volumeShrinkReplicas() should wait for the replicas")
+	}
+	replica := &v1alpha1.LocalVolumeReplica{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: "pvc-ha-node2"}, replica); err != nil {
+		t.Fatal(err)
+	}
+	if replica.Spec.RequiredCapacityBytes != newCapacityBytes || replica.Annotations[v1alpha1.VolumeShrinkInProgressAnnoKey] != "shrink" {
+		t.Errorf("volumeShrinkReplicas() got replica %+v", replica.ObjectMeta)
+	}
+	volume := &v1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: "pvc-ha"}, volume); err != nil {
+		t.Fatal(err)
+	}
+	if volume.Spec.RequiredCapacityBytes != newCapacityBytes || volume.Spec.Config.RequiredCapacityBytes != newCapacityBytes || !volume.Spec.Config.Initialized {
+		t.Errorf("volumeShrinkReplicas() got volume %+v", volume.Spec)
+	}
+
+	// the replica on node2 is shrunk now
+	replica.Status.AllocatedCapacityBytes = newCapacityBytes
+	if err := m.apiClient.Update(context.TODO(), replica); err != nil {
+		t.Fatal(err)
+	}
+	volShrink = getFakeVolumeShrink(t, m, "shrink")
+	if err := m.volumeShrinkReplicas(volShrink); err != nil {
+		t.Fatalf("volumeShrinkReplicas() error = %v", err)
+	}
+	got := getFakeVolumeShrink(t, m, "shrink")
+	if got.Status.State != v1alpha1.OperationStateInProgress || len(got.Status.Subs) != 2 {
+		t.Errorf("volumeShrinkReplicas() got status %+v", got.Status)
+	}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: "pvc-ha"}, volume); err != nil {
+		t.Fatal(err)
+	}
+	if volume.Spec.Config.Initialized {
+		t.Error("volumeShrinkReplicas() should initialize the HA volume again")
+	}
+}
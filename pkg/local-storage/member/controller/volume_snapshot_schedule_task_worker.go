@@ -0,0 +1,322 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+func (m *manager) startVolumeSnapshotScheduleTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("Volume Snapshot Schedule Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.volumeSnapshotScheduleTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the Volume Snapshot Schedule worker")
+				break
+			}
+			if err := m.processVolumeSnapshotSchedule(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.volumeSnapshotScheduleTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process Volume Snapshot Schedule task, retry later")
+				m.volumeSnapshotScheduleTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a Volume Snapshot Schedule task.")
+				m.volumeSnapshotScheduleTaskQueue.Forget(task)
+			}
+			m.volumeSnapshotScheduleTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.volumeSnapshotScheduleTaskQueue.Shutdown()
+}
+
+func (m *manager) processVolumeSnapshotSchedule(scheduleName string) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeSnapshotSchedule": scheduleName})
+	logCtx.Debug("Working on a VolumeSnapshotSchedule task")
+	schedule := &apisv1alpha1.LocalVolumeSnapshotSchedule{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: scheduleName}, schedule); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get VolumeSnapshotSchedule from cache")
+			return err
+		}
+		logCtx.Info("Not found the VolumeSnapshotSchedule from cache, should be deleted already")
+		return nil
+	}
+
+	if schedule.Spec.Suspend {
+		if schedule.Status.NextScheduleTime == nil {
+			return nil
+		}
+		schedule.Status.NextScheduleTime = nil
+		schedule.Status.Schedule = ""
+		return m.apiClient.Status().Update(context.TODO(), schedule)
+	}
+
+	now := time.Now()
+	nextScheduleTime, due, err := nextCronScheduleTime(schedule.Spec.Schedule, schedule.Status.Schedule, schedule.Status.NextScheduleTime, now)
+	if err != nil {
+		logCtx.WithError(err).Error("Invalid schedule of VolumeSnapshotSchedule")
+		if schedule.Status.Message == err.Error() && schedule.Status.NextScheduleTime == nil {
+			return nil
+		}
+		schedule.Status.Message = err.Error()
+		schedule.Status.NextScheduleTime = nil
+		schedule.Status.Schedule = ""
+		return m.apiClient.Status().Update(context.TODO(), schedule)
+	}
+
+	if due {
+		// the snapshots are named after the schedule time, so a retry after failing to update the status
+		// won't take them again
+		m.takeScheduledVolumeSnapshots(schedule, now, schedule.Status.NextScheduleTime.Time)
+		m.pruneScheduledVolumeSnapshots(schedule)
+	}
+	if due || schedule.Status.NextScheduleTime != nextScheduleTime {
+		if !due {
+			// the schedule is new or changed
+			schedule.Status.Message = ""
+		}
+		schedule.Status.NextScheduleTime = nextScheduleTime
+		schedule.Status.Schedule = schedule.Spec.Schedule
+		if err := m.apiClient.Status().Update(context.TODO(), schedule); err != nil {
+			return err
+		}
+	}
+
+	m.volumeSnapshotScheduleTaskQueue.AddAfter(schedule.Name, time.Until(schedule.Status.NextScheduleTime.Time))
+	return nil
+}
+
+// takeScheduledVolumeSnapshots creates the snapshots for all the selected volumes, the result is recorded in the status
+func (m *manager) takeScheduledVolumeSnapshots(schedule *apisv1alpha1.LocalVolumeSnapshotSchedule, now time.Time, scheduledTime time.Time) {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeSnapshotSchedule": schedule.Name})
+	logCtx.Debug("Taking scheduled VolumeSnapshots")
+
+	scheduleTime := metav1.NewTime(now)
+	schedule.Status.LastScheduleTime = &scheduleTime
+	schedule.Status.Volumes = nil
+	schedule.Status.Failures = nil
+	schedule.Status.Message = ""
+
+	volumes, err := m.getScheduledVolumes(schedule)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get volumes of VolumeSnapshotSchedule")
+		schedule.Status.Message = fmt.Sprintf("failed to select volumes: %v", err)
+		return
+	}
+
+	for _, volume := range volumes {
+		schedule.Status.Volumes = append(schedule.Status.Volumes, volume.Name)
+		if err := m.createScheduledVolumeSnapshot(schedule, volume, scheduledTime); err != nil {
+			logCtx.WithField("volume", volume.Name).WithError(err).Error("Failed to create scheduled VolumeSnapshot")
+			schedule.Status.Failures = append(schedule.Status.Failures, apisv1alpha1.SnapshotScheduleFailure{VolumeName: volume.Name, Message: err.Error()})
+		}
+	}
+	if len(schedule.Status.Failures) == 0 {
+		schedule.Status.LastSuccessfulTime = &scheduleTime
+	}
+}
+
+func (m *manager) createScheduledVolumeSnapshot(schedule *apisv1alpha1.LocalVolumeSnapshotSchedule, volume *apisv1alpha1.LocalVolume, scheduledTime time.Time) error {
+	if volume.Status.State != apisv1alpha1.VolumeStateReady {
+		return fmt.Errorf("volume is not ready")
+	}
+	// for now, we only support take snapshot on single replica volume
+	if len(volume.Spec.Accessibility.Nodes) > 1 {
+		return fmt.Errorf("haven't support take snapshot on HA-Volume")
+	}
+
+	volumeSnapshot := &apisv1alpha1.LocalVolumeSnapshot{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("%s-%s-%s", schedule.Name, volume.Name, scheduledTime.UTC().Format("20060102150405")),
+			Labels: map[string]string{
+				apisv1alpha1.VolumeSnapshotScheduleLabelKey:     schedule.Name,
+				apisv1alpha1.VolumeSnapshotSourceVolumeLabelKey: volume.Name,
+			},
+		},
+		Spec: apisv1alpha1.LocalVolumeSnapshotSpec{
+			SourceVolume:          volume.Name,
+			Accessibility:         volume.Spec.Accessibility,
+			RequiredCapacityBytes: volume.Status.AllocatedCapacityBytes,
+			Thin:                  volume.Spec.Thin,
+		},
+	}
+	if err := m.apiClient.Create(context.TODO(), volumeSnapshot); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// getScheduledVolumes returns the volumes whose PVCs are matched by all the selectors of the schedule
+func (m *manager) getScheduledVolumes(schedule *apisv1alpha1.LocalVolumeSnapshotSchedule) ([]*apisv1alpha1.LocalVolume, error) {
+	if schedule.Spec.PVCSelector == nil && schedule.Spec.NamespaceSelector == nil && schedule.Spec.StorageClassSelector == nil {
+		return nil, fmt.Errorf("no selector is specified")
+	}
+
+	volumeList := &apisv1alpha1.LocalVolumeList{}
+	if err := m.apiClient.List(context.TODO(), volumeList); err != nil {
+		return nil, err
+	}
+
+	var volumes []*apisv1alpha1.LocalVolume
+	for i, volume := range volumeList.Items {
+		if len(volume.Spec.PersistentVolumeClaimName) == 0 || len(volume.Spec.PersistentVolumeClaimNamespace) == 0 {
+			continue
+		}
+		matched, err := m.isVolumeMatchedBySchedule(schedule, &volume)
+		if err != nil {
+			return nil, err
+		}
+		if matched {
+			volumes = append(volumes, &volumeList.Items[i])
+		}
+	}
+	return volumes, nil
+}
+
+func (m *manager) isVolumeMatchedBySchedule(schedule *apisv1alpha1.LocalVolumeSnapshotSchedule, volume *apisv1alpha1.LocalVolume) (bool, error) {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Namespace: volume.Spec.PersistentVolumeClaimNamespace, Name: volume.Spec.PersistentVolumeClaimName}, pvc); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if matched, err := matchLabelSelector(schedule.Spec.PVCSelector, pvc.Labels); !matched || err != nil {
+		return false, err
+	}
+
+	if schedule.Spec.NamespaceSelector != nil {
+		namespace := &corev1.Namespace{}
+		if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: pvc.Namespace}, namespace); err != nil {
+			return false, err
+		}
+		if matched, err := matchLabelSelector(schedule.Spec.NamespaceSelector, namespace.Labels); !matched || err != nil {
+			return false, err
+		}
+	}
+
+	if schedule.Spec.StorageClassSelector != nil {
+		if pvc.Spec.StorageClassName == nil {
+			return false, nil
+		}
+		sc := &storagev1.StorageClass{}
+		if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: *pvc.Spec.StorageClassName}, sc); err != nil {
+			return false, err
+		}
+		if matched, err := matchLabelSelector(schedule.Spec.StorageClassSelector, sc.Labels); !matched || err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchLabelSelector matches the labels by the selector, nil selector matches everything
+func matchLabelSelector(labelSelector *metav1.LabelSelector, objLabels map[string]string) (bool, error) {
+	if labelSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return false, err
+	}
+	return selector.Matches(labels.Set(objLabels)), nil
+}
+
+// pruneScheduledVolumeSnapshots deletes the scheduled snapshots which are not kept by the retention.
+// The snapshots still in use by a restore or backup are skipped, and will be pruned at the next schedule
+func (m *manager) pruneScheduledVolumeSnapshots(schedule *apisv1alpha1.LocalVolumeSnapshotSchedule) {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeSnapshotSchedule": schedule.Name})
+	logCtx.Debug("Pruning scheduled VolumeSnapshots")
+
+	volumeSnapshotList := &apisv1alpha1.LocalVolumeSnapshotList{}
+	if err := m.apiClient.List(context.TODO(), volumeSnapshotList, client.MatchingLabels{apisv1alpha1.VolumeSnapshotScheduleLabelKey: schedule.Name}); err != nil {
+		logCtx.WithError(err).Error("Failed to list scheduled VolumeSnapshots")
+		return
+	}
+
+	inUseSnapshots, err := m.getInUseVolumeSnapshots()
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get in use VolumeSnapshots")
+		return
+	}
+
+	volumeSnapshots := map[string][]apisv1alpha1.LocalVolumeSnapshot{}
+	for _, volumeSnapshot := range volumeSnapshotList.Items {
+		if volumeSnapshot.Spec.Delete {
+			continue
+		}
+		volumeSnapshots[volumeSnapshot.Spec.SourceVolume] = append(volumeSnapshots[volumeSnapshot.Spec.SourceVolume], volumeSnapshot)
+	}
+
+	for _, snapshots := range volumeSnapshots {
+		for _, volumeSnapshot := range selectVolumeSnapshotsToPrune(snapshots, schedule.Spec.Retention) {
+			if inUseSnapshots[volumeSnapshot.Name] {
+				logCtx.WithField("snapshot", volumeSnapshot.Name).Info("VolumeSnapshot is in use, skip pruning it")
+				continue
+			}
+			// delete snapshot by setting delete true in snapshot's spec
+			volumeSnapshot.Spec.Delete = true
+			if err := m.apiClient.Update(context.TODO(), &volumeSnapshot); err != nil {
+				logCtx.WithField("snapshot", volumeSnapshot.Name).WithError(err).Error("Failed to prune VolumeSnapshot")
+				continue
+			}
+			logCtx.WithField("snapshot", volumeSnapshot.Name).Info("Pruned VolumeSnapshot")
+		}
+	}
+}
+
+// getInUseVolumeSnapshots returns the snapshots referenced by the in-progress restores and backups
+func (m *manager) getInUseVolumeSnapshots() (map[string]bool, error) {
+	inUseSnapshots := map[string]bool{}
+
+	snapshotRestoreList := &apisv1alpha1.LocalVolumeSnapshotRestoreList{}
+	if err := m.apiClient.List(context.TODO(), snapshotRestoreList); err != nil {
+		return nil, err
+	}
+	for _, snapshotRestore := range snapshotRestoreList.Items {
+		if snapshotRestore.Status.State != apisv1alpha1.OperationStateCompleted && snapshotRestore.Status.State != apisv1alpha1.OperationStateFailed &&
+			snapshotRestore.Status.State != apisv1alpha1.OperationStateAborted {
+			inUseSnapshots[snapshotRestore.Spec.SourceVolumeSnapshot] = true
+		}
+	}
+
+	volumeBackupList := &apisv1alpha1.LocalVolumeBackupList{}
+	if err := m.apiClient.List(context.TODO(), volumeBackupList); err != nil {
+		return nil, err
+	}
+	for _, volumeBackup := range volumeBackupList.Items {
+		if volumeBackup.Status.State != apisv1alpha1.OperationStateCompleted && volumeBackup.Status.State != apisv1alpha1.OperationStateFailed &&
+			volumeBackup.Status.State != apisv1alpha1.OperationStateAborted {
+			inUseSnapshots[volumeBackup.Spec.SourceVolumeSnapshot] = true
+		}
+	}
+	return inUseSnapshots, nil
+}
+
+// selectVolumeSnapshotsToPrune returns the snapshots of a volume which are not kept by any rule of the retention
+func selectVolumeSnapshotsToPrune(snapshots []apisv1alpha1.LocalVolumeSnapshot, retention apisv1alpha1.SnapshotRetention) []apisv1alpha1.LocalVolumeSnapshot {
+	snapshotsByName := map[string]apisv1alpha1.LocalVolumeSnapshot{}
+	var objects []scheduledObject
+	for _, snapshot := range snapshots {
+		snapshotsByName[snapshot.Name] = snapshot
+		objects = append(objects, scheduledObject{name: snapshot.Name, creationTimestamp: snapshot.CreationTimestamp})
+	}
+
+	var pruned []apisv1alpha1.LocalVolumeSnapshot
+	for _, name := range selectScheduledObjectsToPrune(objects, retention) {
+		pruned = append(pruned, snapshotsByName[name])
+	}
+	return pruned
+}
@@ -0,0 +1,242 @@
+package controller
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/common"
+)
+
+func Test_selectVolumeSnapshotsToPrune(t *testing.T) {
+	// snapshots taken every 12 hours in 3 weeks, the latest one is at 2023-06-30 12:00 (Friday)
+	latest := time.Date(2023, 6, 30, 12, 0, 0, 0, time.UTC)
+	var snapshots []v1alpha1.LocalVolumeSnapshot
+	for i := 0; i < 42; i++ {
+		snapshot := v1alpha1.LocalVolumeSnapshot{}
+		snapshot.Name = latest.Add(-time.Duration(i) * 12 * time.Hour).Format("0102-15")
+		snapshot.CreationTimestamp = metav1.NewTime(latest.Add(-time.Duration(i) * 12 * time.Hour))
+		snapshots = append(snapshots, snapshot)
+	}
+
+	tests := []struct {
+		name      string
+		retention v1alpha1.SnapshotRetention
+		wantKept  []string
+	}{
+		{
+			name:      "no retention",
+			retention: v1alpha1.SnapshotRetention{},
+			wantKept:  nil,
+		},
+		{
+			name:      "keep last",
+			retention: v1alpha1.SnapshotRetention{KeepLast: 3},
+			wantKept:  []string{"0630-12", "0630-00", "0629-12"},
+		},
+		{
+			name:      "keep daily",
+			retention: v1alpha1.SnapshotRetention{KeepDaily: 2},
+			wantKept:  []string{"0630-12", "0629-12"},
+		},
+		{
+			name:      "keep weekly",
+			retention: v1alpha1.SnapshotRetention{KeepWeekly: 3},
+			wantKept:  []string{"0630-12", "0625-12", "0618-12"},
+		},
+		{
+			name:      "keep last and daily",
+			retention: v1alpha1.SnapshotRetention{KeepLast: 2, KeepDaily: 2},
+			wantKept:  []string{"0630-12", "0630-00", "0629-12"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pruned := selectVolumeSnapshotsToPrune(append([]v1alpha1.LocalVolumeSnapshot{}, snapshots...), tt.retention)
+			if tt.wantKept == nil {
+				if len(pruned) != 0 {
+					t.Errorf("selectVolumeSnapshotsToPrune() pruned %d snapshots, want none", len(pruned))
+				}
+				return
+			}
+
+			prunedNames := map[string]bool{}
+			for _, snapshot := range pruned {
+				prunedNames[snapshot.Name] = true
+			}
+			var kept []string
+			for _, snapshot := range snapshots {
+				if !prunedNames[snapshot.Name] {
+					kept = append(kept, snapshot.Name)
+				}
+			}
+			sort.Sort(sort.Reverse(sort.StringSlice(kept)))
+			if !reflect.DeepEqual(kept, tt.wantKept) {
+				t.Errorf("selectVolumeSnapshotsToPrune() kept %v, want %v", kept, tt.wantKept)
+			}
+		})
+	}
+}
+
+func newFakeSnapshotScheduleManager(objs ...client.Object) *manager {
+	s := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(s)
+	_ = v1alpha1.AddToScheme(s)
+	return &manager{
+		apiClient:                       fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build(),
+		volumeSnapshotScheduleTaskQueue: common.NewTaskQueue("VolumeSnapshotScheduleTask", maxRetries),
+		logger:                          log.WithField("Module", "ControllerManager"),
+	}
+}
+
+func Test_manager_processVolumeSnapshotSchedule(t *testing.T) {
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data-1", Labels: map[string]string{"backup": "true"}}}
+	otherPVC := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "data-2"}}
+	volume := &v1alpha1.LocalVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"}}
+	volume.Spec.PersistentVolumeClaimNamespace = pvc.Namespace
+	volume.Spec.PersistentVolumeClaimName = pvc.Name
+	volume.Spec.Accessibility.Nodes = []string{"node1"}
+	volume.Status.State = v1alpha1.VolumeStateReady
+	volume.Status.AllocatedCapacityBytes = 1073741824
+	otherVolume := &v1alpha1.LocalVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-2"}}
+	otherVolume.Spec.PersistentVolumeClaimNamespace = otherPVC.Namespace
+	otherVolume.Spec.PersistentVolumeClaimName = otherPVC.Name
+
+	schedule := &v1alpha1.LocalVolumeSnapshotSchedule{ObjectMeta: metav1.ObjectMeta{Name: "daily"}}
+	schedule.Spec.Schedule = "@daily"
+	schedule.Spec.PVCSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"backup": "true"}}
+
+	m := newFakeSnapshotScheduleManager(pvc, otherPVC, volume, otherVolume, schedule)
+	defer m.volumeSnapshotScheduleTaskQueue.Shutdown()
+
+	// the first run only computes the next schedule time
+	if err := m.processVolumeSnapshotSchedule(schedule.Name); err != nil {
+		t.Fatalf("processVolumeSnapshotSchedule() error = %v", err)
+	}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: schedule.Name}, schedule); err != nil {
+		t.Fatalf("Get LocalVolumeSnapshotSchedule error = %v", err)
+	}
+	if schedule.Status.NextScheduleTime == nil || schedule.Status.LastScheduleTime != nil {
+		t.Fatalf("processVolumeSnapshotSchedule() got status %+v", schedule.Status)
+	}
+
+	// it's time to take the snapshots, and it's retried as if the status wasn't updated at the first time
+	scheduledTime := metav1.Time{Time: time.Now().Add(-time.Minute)}
+	for i := 0; i < 2; i++ {
+		if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: schedule.Name}, schedule); err != nil {
+			t.Fatalf("Get LocalVolumeSnapshotSchedule error = %v", err)
+		}
+		schedule.Status.NextScheduleTime = &scheduledTime
+		if err := m.apiClient.Status().Update(context.TODO(), schedule); err != nil {
+			t.Fatalf("Update LocalVolumeSnapshotSchedule error = %v", err)
+		}
+		if err := m.processVolumeSnapshotSchedule(schedule.Name); err != nil {
+			t.Fatalf("processVolumeSnapshotSchedule() error = %v", err)
+		}
+	}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: schedule.Name}, schedule); err != nil {
+		t.Fatalf("Get LocalVolumeSnapshotSchedule error = %v", err)
+	}
+	if !reflect.DeepEqual(schedule.Status.Volumes, []string{volume.Name}) || len(schedule.Status.Failures) != 0 ||
+		schedule.Status.LastSuccessfulTime == nil || !schedule.Status.NextScheduleTime.After(time.Now()) {
+		t.Errorf("processVolumeSnapshotSchedule() got status %+v", schedule.Status)
+	}
+
+	snapshotList := &v1alpha1.LocalVolumeSnapshotList{}
+	if err := m.apiClient.List(context.TODO(), snapshotList, client.MatchingLabels{v1alpha1.VolumeSnapshotScheduleLabelKey: schedule.Name}); err != nil {
+		t.Fatalf("List LocalVolumeSnapshot error = %v", err)
+	}
+	if len(snapshotList.Items) != 1 || snapshotList.Items[0].Spec.SourceVolume != volume.Name ||
+		snapshotList.Items[0].Name != "daily-pvc-1-"+scheduledTime.UTC().Format("20060102150405") ||
+		snapshotList.Items[0].Spec.RequiredCapacityBytes != volume.Status.AllocatedCapacityBytes {
+		t.Errorf("processVolumeSnapshotSchedule() got snapshots %+v", snapshotList.Items)
+	}
+
+	// the changed schedule doesn't take the snapshots at the time computed from the old one
+	schedule.Spec.Schedule = "@hourly"
+	schedule.Status.NextScheduleTime = &metav1.Time{Time: time.Now().Add(-time.Minute)}
+	if err := m.apiClient.Update(context.TODO(), schedule); err != nil {
+		t.Fatalf("Update LocalVolumeSnapshotSchedule error = %v", err)
+	}
+	if err := m.processVolumeSnapshotSchedule(schedule.Name); err != nil {
+		t.Fatalf("processVolumeSnapshotSchedule() error = %v", err)
+	}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: schedule.Name}, schedule); err != nil {
+		t.Fatalf("Get LocalVolumeSnapshotSchedule error = %v", err)
+	}
+	if schedule.Status.Schedule != "@hourly" || !schedule.Status.NextScheduleTime.After(time.Now()) {
+		t.Errorf("processVolumeSnapshotSchedule() got status %+v for changed schedule", schedule.Status)
+	}
+	if err := m.apiClient.List(context.TODO(), snapshotList, client.MatchingLabels{v1alpha1.VolumeSnapshotScheduleLabelKey: schedule.Name}); err != nil {
+		t.Fatalf("List LocalVolumeSnapshot error = %v", err)
+	}
+	if len(snapshotList.Items) != 1 {
+		t.Errorf("processVolumeSnapshotSchedule() got %d snapshots for changed schedule, want 1", len(snapshotList.Items))
+	}
+
+	// invalid schedule
+	schedule.Spec.Schedule = "every day"
+	if err := m.apiClient.Update(context.TODO(), schedule); err != nil {
+		t.Fatalf("Update LocalVolumeSnapshotSchedule error = %v", err)
+	}
+	if err := m.processVolumeSnapshotSchedule(schedule.Name); err != nil {
+		t.Fatalf("processVolumeSnapshotSchedule() error = %v", err)
+	}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: schedule.Name}, schedule); err != nil {
+		t.Fatalf("Get LocalVolumeSnapshotSchedule error = %v", err)
+	}
+	if schedule.Status.NextScheduleTime != nil || len(schedule.Status.Message) == 0 {
+		t.Errorf("processVolumeSnapshotSchedule() got status %+v for invalid schedule", schedule.Status)
+	}
+}
+
+func Test_manager_pruneScheduledVolumeSnapshots(t *testing.T) {
+	// the snapshots from the oldest to the latest, the oldest one is in use by a restore, and the failed restore
+	// of the second one doesn't use it any more
+	objs := []client.Object{}
+	for i, name := range []string{"daily-pvc-1-1", "daily-pvc-1-2", "daily-pvc-1-3", "daily-pvc-1-4"} {
+		snapshot := &v1alpha1.LocalVolumeSnapshot{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Labels:            map[string]string{v1alpha1.VolumeSnapshotScheduleLabelKey: "daily"},
+			CreationTimestamp: metav1.NewTime(time.Now().Add(time.Duration(i-4) * time.Hour)),
+		}}
+		snapshot.Spec.SourceVolume = "pvc-1"
+		objs = append(objs, snapshot)
+	}
+	snapshotRestore := &v1alpha1.LocalVolumeSnapshotRestore{ObjectMeta: metav1.ObjectMeta{Name: "restore-1"}}
+	snapshotRestore.Spec.SourceVolumeSnapshot = "daily-pvc-1-1"
+	snapshotRestore.Status.State = v1alpha1.OperationStateInProgress
+	failedSnapshotRestore := &v1alpha1.LocalVolumeSnapshotRestore{ObjectMeta: metav1.ObjectMeta{Name: "restore-2"}}
+	failedSnapshotRestore.Spec.SourceVolumeSnapshot = "daily-pvc-1-2"
+	failedSnapshotRestore.Status.State = v1alpha1.OperationStateFailed
+
+	schedule := &v1alpha1.LocalVolumeSnapshotSchedule{ObjectMeta: metav1.ObjectMeta{Name: "daily"}}
+	schedule.Spec.Retention.KeepLast = 2
+
+	m := newFakeSnapshotScheduleManager(append(objs, snapshotRestore, failedSnapshotRestore, schedule)...)
+	m.pruneScheduledVolumeSnapshots(schedule)
+
+	snapshotList := &v1alpha1.LocalVolumeSnapshotList{}
+	if err := m.apiClient.List(context.TODO(), snapshotList); err != nil {
+		t.Fatalf("List LocalVolumeSnapshot error = %v", err)
+	}
+	deleted := map[string]bool{}
+	for _, snapshot := range snapshotList.Items {
+		deleted[snapshot.Name] = snapshot.Spec.Delete
+	}
+	want := map[string]bool{"daily-pvc-1-1": false, "daily-pvc-1-2": true, "daily-pvc-1-3": false, "daily-pvc-1-4": false}
+	if !reflect.DeepEqual(deleted, want) {
+		t.Errorf("pruneScheduledVolumeSnapshots() got %v, want %v", deleted, want)
+	}
+}
@@ -161,12 +161,17 @@ func (m *manager) processVolumeReadyAndNotReady(vol *apisv1alpha1.LocalVolume) e
 	allocatedCapacityBytes := int64(0)
 	// for update volume.status.replicas
 	var replicaNames []string
+	var volumeQoS *apisv1alpha1.VolumeQoSStatus
 
 	for _, replica := range replicas {
 		if replica.Status.State == apisv1alpha1.VolumeReplicaStateReady {
 			healthyReplicaCount++
 			allocatedCapacityBytes = replica.Status.AllocatedCapacityBytes
 			replicaNames = append(replicaNames, replica.Name)
+			// report the QoS applied on the node where the volume is used
+			if replica.Status.VolumeQoS != nil && (volumeQoS == nil || replica.Spec.NodeName == vol.Status.PublishedNodeName) {
+				volumeQoS = replica.Status.VolumeQoS
+			}
 		}
 		if isVolumeReplicaUp(replica) {
 			upReplicaCount++
@@ -183,6 +188,7 @@ func (m *manager) processVolumeReadyAndNotReady(vol *apisv1alpha1.LocalVolume) e
 		vol.Status.State = apisv1alpha1.VolumeStateReady
 		vol.Status.AllocatedCapacityBytes = allocatedCapacityBytes
 		vol.Status.Replicas = replicaNames
+		vol.Status.VolumeQoS = volumeQoS.DeepCopy()
 	} else {
 		vol.Status.State = apisv1alpha1.VolumeStateNotReady
 	}
@@ -11,6 +11,7 @@ import (
 	"k8s.io/apimachinery/pkg/fields"
 
 	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/golang/protobuf/ptypes/wrappers"
 	"github.com/hwameistor/hwameistor/pkg/local-storage/utils"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -29,6 +30,7 @@ import (
 
 	apis "github.com/hwameistor/hwameistor/pkg/apis/hwameistor"
 	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/encrypt"
 )
 
 var (
@@ -156,17 +158,31 @@ func (p *plugin) genLocalVolumeFromRequest(_ context.Context, req *csi.CreateVol
 	vol.Spec.VolumeGroup = lvg.Name
 	vol.Spec.Accessibility.Nodes = lvg.Spec.Accessibility.Nodes
 	vol.Spec.Thin = params.thin
-	vol.Spec.VolumeQoS = apisv1alpha1.VolumeQoS{
-		Throughput: params.throughput,
-		IOPS:       params.iops,
-	}
+	vol.Spec.Mirror = params.mirror
+	vol.Spec.StorageBackend = params.storageBackend
+	vol.Spec.VolumeQoS = params.volumeQoS
+	vol.Spec.VolumeCache = params.volumeCache
 
-	// only enable the encryption when the encryptType and encryptSecretNName are both set
-	if params.encryptType != "" && params.encryptSecretNName != "" {
+	// only enable the encryption when the encryptType and encryptSecretNName (or the kms) are both set
+	if params.encryptType != "" && (params.encryptSecretNName != "" || params.encryptKMS != nil) {
 		vol.Spec.VolumeEncrypt = apisv1alpha1.VolumeEncrypt{
 			Enable:               true,
 			SecretNamespacedName: params.encryptSecretNName,
 			Type:                 params.encryptType,
+			KeyProvider:          params.encryptKeyProvider,
+			KMS:                  params.encryptKMS,
+		}
+		// the data key of the volume is generated by the kms, only the wrapped one is saved
+		if params.encryptKMS != nil {
+			provider, err := encrypt.NewKeyProvider(p.apiClient, &vol.Spec.VolumeEncrypt)
+			if err != nil {
+				p.logger.WithError(err).Error("Failed to get key provider")
+				return nil, err
+			}
+			if params.encryptKMS.WrappedKey, err = provider.NewKey(); err != nil {
+				p.logger.WithError(err).Error("Failed to generate key by kms")
+				return nil, err
+			}
 		}
 	}
 
@@ -481,6 +497,11 @@ func constructLocalVolumeForPVC(pvc *corev1.PersistentVolumeClaim, sc *storagev1
 	replica, _ := strconv.Atoi(sc.Parameters[apisv1alpha1.VolumeParameterReplicaNumberKey])
 	lv.Spec.ReplicaNumber = int64(replica)
 	lv.Spec.Thin = utils.IsSupportThinProvisioning(sc.Parameters)
+	lv.Spec.Mirror = utils.IsMirrorEnabled(sc.Parameters)
+	lv.Spec.StorageBackend = sc.Parameters[apisv1alpha1.VolumeParameterStorageBackend]
+	if lv.Spec.VolumeCache, err = utils.GetVolumeCache(sc.Parameters, lv.Spec.RequiredCapacityBytes); err != nil {
+		return nil, err
+	}
 	return &lv, nil
 }
 
@@ -1033,6 +1054,11 @@ func (p *plugin) ControllerPublishVolume(ctx context.Context, req *csi.Controlle
 		p.logger.WithFields(log.Fields{"volName": req.VolumeId, "error": err.Error()}).Error("Failed to query volume")
 		return resp, err
 	}
+	// the filesystem is being shrunk offline, don't mount it until the volume is shrunk
+	if volShrink, exists := vol.Annotations[apisv1alpha1.VolumeShrinkInProgressAnnoKey]; exists {
+		p.logger.WithFields(log.Fields{"volName": req.VolumeId, "volumeShrink": volShrink}).Error("Volume is being shrunk")
+		return resp, fmt.Errorf("volume is being shrunk by %s", volShrink)
+	}
 	volReplica := &apisv1alpha1.LocalVolumeReplica{}
 	for _, replicaName := range vol.Status.Replicas {
 		if err := p.apiClient.Get(ctx, types.NamespacedName{Name: replicaName}, volReplica); err != nil {
@@ -1250,18 +1276,74 @@ func (p *plugin) ListVolumes(ctx context.Context, req *csi.ListVolumesRequest) (
 }
 
 // GetCapacity implementation
+// Reports the capacity available for new volumes of the requested pool class on the given topology.
+// If no topology is specified, the available capacity of all the ready storage nodes is summed up.
 func (p *plugin) GetCapacity(ctx context.Context, req *csi.GetCapacityRequest) (*csi.GetCapacityResponse, error) {
-	p.logger.WithFields(log.Fields{
+	logCtx := p.logger.WithFields(log.Fields{
 		"volumeCapabilities": req.VolumeCapabilities,
 		"parameters":         req.Parameters,
-		"AccessibleTopology": req.AccessibleTopology.Segments,
-	}).Debug("GetCapacity")
+		"AccessibleTopology": req.AccessibleTopology.GetSegments(),
+	})
+	logCtx.Debug("GetCapacity")
 
 	resp := &csi.GetCapacityResponse{}
 
-	// DO NOT SUPPORT: The scheduler should take it into account
+	poolClass, ok := req.Parameters[apisv1alpha1.VolumeParameterPoolClassKey]
+	if !ok {
+		return resp, status.Error(codes.InvalidArgument, "not found pool class")
+	}
+	poolName, err := utils.BuildStoragePoolName(poolClass)
+	if err != nil {
+		return resp, status.Error(codes.InvalidArgument, err.Error())
+	}
+	thin := utils.IsSupportThinProvisioning(req.Parameters)
+	mirror := utils.IsMirrorEnabled(req.Parameters)
+
+	nodes := []apisv1alpha1.LocalStorageNode{}
+	if nodeName, ok := req.AccessibleTopology.GetSegments()[apis.TopologyNodeKey]; ok {
+		node := apisv1alpha1.LocalStorageNode{}
+		if err = p.apiClient.Get(ctx, client.ObjectKey{Name: nodeName}, &node); err != nil {
+			if errors.IsNotFound(err) {
+				// no storage on the node, report zero capacity
+				return resp, nil
+			}
+			logCtx.WithError(err).Error("Failed to get LocalStorageNode")
+			return resp, status.Errorf(codes.Internal, "failed to get LocalStorageNode %s: %v", nodeName, err)
+		}
+		nodes = append(nodes, node)
+	} else {
+		nodeList := apisv1alpha1.LocalStorageNodeList{}
+		if err = p.apiClient.List(ctx, &nodeList); err != nil {
+			logCtx.WithError(err).Error("Failed to list LocalStorageNodes")
+			return resp, status.Errorf(codes.Internal, "failed to list LocalStorageNodes: %v", err)
+		}
+		nodes = nodeList.Items
+	}
+
+	var maxVolumeSize int64
+	for i := range nodes {
+		if nodes[i].Status.State != apisv1alpha1.NodeStateReady {
+			continue
+		}
+		pool, exists := nodes[i].Status.Pools[poolName]
+		if !exists {
+			continue
+		}
+		available := getPoolAvailableCapacityBytes(&pool, thin)
+		if mirror {
+			// a mirrored volume keeps a full copy on each of the disks
+			available /= apisv1alpha1.LVMMirrorCopies
+		}
+		resp.AvailableCapacity += available
+		if available > maxVolumeSize {
+			maxVolumeSize = available
+		}
+	}
+	// a volume(replica) can't span across nodes, so the maximum volume size is limited by the largest node
+	resp.MaximumVolumeSize = &wrappers.Int64Value{Value: maxVolumeSize}
 
-	return resp, fmt.Errorf("not supported")
+	logCtx.WithFields(log.Fields{"availableCapacity": resp.AvailableCapacity, "maximumVolumeSize": maxVolumeSize}).Debug("GetCapacity successfully")
+	return resp, nil
 }
 
 // CreateSnapshot implementation, idempotent
@@ -1313,6 +1395,12 @@ func (p *plugin) CreateSnapshot(ctx context.Context, req *csi.CreateSnapshotRequ
 			return nil, status.Errorf(codes.Internal, "Failed to check whether volume is thin: %v", err)
 		}
 
+		quiesce, err := getSnapshotQuiesce(req.Parameters)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to parse snapshot quiesce parameters")
+			return nil, status.Errorf(codes.InvalidArgument, "Failed to parse snapshot quiesce parameters: %v", err)
+		}
+
 		// for now, we only support take snapshot on single replica volume
 		if len(accessTopology.Nodes) > 1 {
 			logCtx.WithField("topology", accessTopology.Nodes).Error("Haven't support take snapshot on HA-Volume")
@@ -1324,6 +1412,7 @@ func (p *plugin) CreateSnapshot(ctx context.Context, req *csi.CreateSnapshotRequ
 		snapshot.Spec.RequiredCapacityBytes = snapsize
 		snapshot.Spec.SourceVolume = req.SourceVolumeId
 		snapshot.Spec.Thin = isThin
+		snapshot.Spec.Quiesce = quiesce
 
 		if err = p.apiClient.Create(ctx, snapshot); err != nil {
 			logCtx.WithError(err).Error("Failed to create LocalVolumeSnapshot")
@@ -1572,6 +1661,29 @@ func getSnapshotSize(sourceVolume string, params map[string]string, apiClient cl
 	return getVolumeAllocatedCapacity(sourceVolume, apiClient)
 }
 
+// getSnapshotQuiesce returns how to quiesce the volume from the snapshot class parameters, nil if not set
+func getSnapshotQuiesce(params map[string]string) (*apisv1alpha1.SnapshotQuiesce, error) {
+	mode, ok := params[apisv1alpha1.SnapshotParameterQuiesceKey]
+	if !ok {
+		return nil, nil
+	}
+	switch mode {
+	case apisv1alpha1.SnapshotQuiesceModeNone, apisv1alpha1.SnapshotQuiesceModeFSFreeze, apisv1alpha1.SnapshotQuiesceModeHook:
+	default:
+		return nil, fmt.Errorf("invalid quiesce mode %s", mode)
+	}
+
+	quiesce := &apisv1alpha1.SnapshotQuiesce{Mode: mode, TimeoutSeconds: apisv1alpha1.SnapshotQuiesceDefaultTimeoutSeconds}
+	if timeout, ok := params[apisv1alpha1.SnapshotParameterQuiesceTimeoutKey]; ok {
+		timeoutSeconds, err := strconv.Atoi(timeout)
+		if err != nil || timeoutSeconds <= 0 {
+			return nil, fmt.Errorf("invalid quiesce timeout %s", timeout)
+		}
+		quiesce.TimeoutSeconds = timeoutSeconds
+	}
+	return quiesce, nil
+}
+
 func getVolumeAllocatedCapacity(volumeName string, apiClient client.Client) (int64, error) {
 	volume := apisv1alpha1.LocalVolume{}
 	if err := apiClient.Get(context.Background(), types.NamespacedName{Name: volumeName}, &volume); err != nil {
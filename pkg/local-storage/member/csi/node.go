@@ -3,10 +3,8 @@ package csi
 import (
 	"fmt"
 	"path"
-	"strings"
 
 	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/encrypt"
-	v1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/container-storage-interface/spec/lib/go/csi"
@@ -98,7 +96,6 @@ func (p *plugin) NodePublishVolume(ctx context.Context, req *csi.NodePublishVolu
 	}
 
 	encryptType := req.PublishContext[VolumeEncryptTypeKey]
-	encryptSecret := req.PublishContext[VolumeEncryptSecretKey]
 
 	// return directly if volume has already mounted at TargetPath
 	yes, _ := p.mounter.IsMountPoint(req.TargetPath)
@@ -131,7 +128,7 @@ func (p *plugin) NodePublishVolume(ctx context.Context, req *csi.NodePublishVolu
 		// decrypt the device and use the returned volume path
 		var err error
 		if encryptType != "" {
-			if devicePath, err = openEncryptedDevice(p.apiClient, encryptType, devicePath, encryptSecret); err != nil {
+			if devicePath, err = openEncryptedDevice(p.apiClient, encryptType, devicePath, req.VolumeId); err != nil {
 				return resp, err
 			}
 		}
@@ -311,31 +308,16 @@ func (p *plugin) NodeExpandVolume(ctx context.Context, req *csi.NodeExpandVolume
 	return resp, nil
 }
 
-func getEncryptSecretKey(cli client.Client, namespacedName string) (string, error) {
-	secret := &v1.Secret{}
-	ss := strings.Split(namespacedName, "/")
-	if len(ss) != 2 {
-		return "", fmt.Errorf("invalid secret namespaced name %s", namespacedName)
-	}
-
-	key := client.ObjectKey{Name: ss[1], Namespace: ss[0]}
-	err := cli.Get(context.Background(), key, secret)
-	if err != nil {
-		return "", err
-	}
-
-	if secret.Data == nil || secret.Data["key"] == nil {
-		return "", fmt.Errorf("key is not exist in %s", namespacedName)
-	}
-
-	return string(secret.Data["key"]), nil
-}
-
-func openEncryptedDevice(cli client.Client, encryptType, devicePath, encryptSecret string) (string, error) {
+func openEncryptedDevice(cli client.Client, encryptType, devicePath, volumeName string) (string, error) {
 	// decrypt the device and use the returned volume path
 	switch encryptType {
 	case "LUKS":
-		if secret, err := getEncryptSecretKey(cli, encryptSecret); err != nil {
+		// the key is fetched by the volume rather than the publish context, which may be out of date after the key rotation
+		vol := &apisv1alpha1.LocalVolume{}
+		if err := cli.Get(context.Background(), types.NamespacedName{Name: volumeName}, vol); err != nil {
+			return "", err
+		}
+		if secret, err := encrypt.GetVolumeKey(cli, &vol.Spec.VolumeEncrypt); err != nil {
 			return "", err
 		} else {
 			// overwrite devicePath with encrypted devicePath
@@ -16,6 +16,11 @@ const (
 	pvcNamespaceKey       = "csi.storage.k8s.io/pvc/namespace"
 	encryptSecretNNameKey = "encryptSecret"
 	encryptTypeKey        = "encryptType"
+
+	encryptKeyProviderKey    = "encryptKeyProvider"
+	encryptKMSAddressKey     = "encryptKMSAddress"
+	encryptKMSKeyNameKey     = "encryptKMSKeyName"
+	encryptKMSTokenSecretKey = "encryptKMSTokenSecret"
 )
 
 type volumeParameters struct {
@@ -26,12 +31,16 @@ type volumeParameters struct {
 	convertible        bool
 	pvcName            string
 	pvcNamespace       string
-	throughput         string
-	iops               string
+	volumeQoS          apisv1alpha1.VolumeQoS
+	volumeCache        *apisv1alpha1.VolumeCache
 	snapshot           string
 	encryptSecretNName string
 	encryptType        string
+	encryptKeyProvider string
+	encryptKMS         *apisv1alpha1.VolumeEncryptKMS
 	thin               bool
+	mirror             bool
+	storageBackend     string
 }
 
 func parseParameters(req *csi.CreateVolumeRequest) (*volumeParameters, error) {
@@ -53,6 +62,9 @@ func parseParameters(req *csi.CreateVolumeRequest) (*volumeParameters, error) {
 	if err != nil {
 		return nil, err
 	}
+	if replicaNumber < 1 || replicaNumber > apisv1alpha1.MaxVolumeReplicaNumber {
+		return nil, fmt.Errorf("volume replica count %d is out of range [1, %d]", replicaNumber, apisv1alpha1.MaxVolumeReplicaNumber)
+	}
 	convertible := true
 	// for HA volume, already be convertible
 	if replicaNumber < 2 {
@@ -86,6 +98,57 @@ func parseParameters(req *csi.CreateVolumeRequest) (*volumeParameters, error) {
 		return nil, fmt.Errorf("thin provision is not supported for HA volume or convertible")
 	}
 
+	storageBackend := params[apisv1alpha1.VolumeParameterStorageBackend]
+	switch storageBackend {
+	case "", apisv1alpha1.StorageBackendLVM, apisv1alpha1.StorageBackendZFS:
+	default:
+		return nil, fmt.Errorf("storage backend %s is not supported", storageBackend)
+	}
+
+	var encryptKMS *apisv1alpha1.VolumeEncryptKMS
+	encryptKeyProvider := params[encryptKeyProviderKey]
+	switch encryptKeyProvider {
+	case "", apisv1alpha1.VolumeEncryptKeyProviderSecret:
+	case apisv1alpha1.VolumeEncryptKeyProviderKMS:
+		encryptKMS = &apisv1alpha1.VolumeEncryptKMS{
+			Address:     params[encryptKMSAddressKey],
+			KeyName:     params[encryptKMSKeyNameKey],
+			TokenSecret: params[encryptKMSTokenSecretKey],
+		}
+		if encryptKMS.Address == "" || encryptKMS.KeyName == "" {
+			return nil, fmt.Errorf("%s and %s are required for the key provider %s", encryptKMSAddressKey, encryptKMSKeyNameKey, encryptKeyProvider)
+		}
+	default:
+		return nil, fmt.Errorf("encrypt key provider %s is not supported", encryptKeyProvider)
+	}
+
+	volumeQoS := apisv1alpha1.VolumeQoS{
+		Throughput:      params[apisv1alpha1.VolumeParameterThroughput],
+		IOPS:            params[apisv1alpha1.VolumeParameterIOPS],
+		BurstThroughput: params[apisv1alpha1.VolumeParameterBurstThroughput],
+		BurstIOPS:       params[apisv1alpha1.VolumeParameterBurstIOPS],
+		BurstDuration:   params[apisv1alpha1.VolumeParameterBurstDuration],
+		LatencyTarget:   params[apisv1alpha1.VolumeParameterIOLatencyTarget],
+	}
+	if weight, ok := params[apisv1alpha1.VolumeParameterIOWeight]; ok {
+		if volumeQoS.Weight, err = strconv.ParseInt(weight, 10, 64); err != nil || volumeQoS.Weight < 1 || volumeQoS.Weight > 10000 {
+			return nil, fmt.Errorf("invalid %s %s, must be from 1 to 10000", apisv1alpha1.VolumeParameterIOWeight, weight)
+		}
+	}
+
+	mirror := utils.IsMirrorEnabled(params)
+	if mirror && (thin || storageBackend == apisv1alpha1.StorageBackendZFS) {
+		return nil, fmt.Errorf("mirror is only supported for thick LVM volume")
+	}
+
+	volumeCache, err := utils.GetVolumeCache(params, req.GetCapacityRange().GetRequiredBytes())
+	if err != nil {
+		return nil, err
+	}
+	if volumeCache != nil && (convertible || replicaNumber != 1 || storageBackend == apisv1alpha1.StorageBackendZFS) {
+		return nil, fmt.Errorf("cache is only supported for non-HA LVM volume")
+	}
+
 	return &volumeParameters{
 		poolClass: poolClass,
 		// poolType:      poolType,
@@ -94,11 +157,15 @@ func parseParameters(req *csi.CreateVolumeRequest) (*volumeParameters, error) {
 		convertible:        convertible,
 		pvcNamespace:       pvcNamespace,
 		pvcName:            pvcName,
-		throughput:         params[apisv1alpha1.VolumeParameterThroughput],
-		iops:               params[apisv1alpha1.VolumeParameterIOPS],
+		volumeQoS:          volumeQoS,
+		volumeCache:        volumeCache,
 		snapshot:           snapshot,
 		encryptSecretNName: params[encryptSecretNNameKey], /* optional */
 		encryptType:        params[encryptTypeKey],        /* optional */
+		encryptKeyProvider: encryptKeyProvider,
+		encryptKMS:         encryptKMS,
 		thin:               thin,
+		mirror:             mirror,
+		storageBackend:     storageBackend,
 	}, nil
 }
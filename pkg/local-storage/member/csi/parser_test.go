@@ -67,3 +67,63 @@ func Test_parseParameters(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseParameters_mirror(t *testing.T) {
+	newRequest := func(extra map[string]string) *csi.CreateVolumeRequest {
+		params := map[string]string{
+			apisv1alpha1.VolumeParameterPoolClassKey:     "HDD",
+			apisv1alpha1.VolumeParameterReplicaNumberKey: "1",
+			apisv1alpha1.VolumeParameterMirror:           "true",
+			pvcNamespaceKey:                              "default",
+			pvcNameKey:                                   "pvc-1",
+		}
+		for k, v := range extra {
+			params[k] = v
+		}
+		return &csi.CreateVolumeRequest{Parameters: params}
+	}
+
+	tests := []struct {
+		name       string
+		req        *csi.CreateVolumeRequest
+		wantMirror bool
+		wantErr    bool
+	}{
+		{
+			name:       "mirrored LVM volume",
+			req:        newRequest(nil),
+			wantMirror: true,
+		},
+		{
+			name:       "mirrored HA volume",
+			req:        newRequest(map[string]string{apisv1alpha1.VolumeParameterReplicaNumberKey: "2"}),
+			wantMirror: true,
+		},
+		{
+			name:       "not mirrored",
+			req:        newRequest(map[string]string{apisv1alpha1.VolumeParameterMirror: "false"}),
+			wantMirror: false,
+		},
+		{
+			name:    "mirrored thin volume",
+			req:     newRequest(map[string]string{apisv1alpha1.VolumeParameterThin: "true"}),
+			wantErr: true,
+		},
+		{
+			name:    "mirrored ZFS volume",
+			req:     newRequest(map[string]string{apisv1alpha1.VolumeParameterStorageBackend: apisv1alpha1.StorageBackendZFS}),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseParameters(tt.req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseParameters() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.mirror != tt.wantMirror {
+				t.Errorf("parseParameters() mirror = %v, want %v", got.mirror, tt.wantMirror)
+			}
+		})
+	}
+}
@@ -128,7 +128,7 @@ func (p *plugin) initControllerServiceCapabilities() {
 		csi.ControllerServiceCapability_RPC_GET_VOLUME,
 		csi.ControllerServiceCapability_RPC_VOLUME_CONDITION,
 		// for storage capacity
-		//csi.ControllerServiceCapability_RPC_GET_CAPACITY, // don't support it as the scheduler will take care of it
+		csi.ControllerServiceCapability_RPC_GET_CAPACITY,
 		// for snapshot
 		csi.ControllerServiceCapability_RPC_CREATE_DELETE_SNAPSHOT,
 		csi.ControllerServiceCapability_RPC_LIST_SNAPSHOTS,
@@ -11,6 +11,7 @@ import (
 	"github.com/gofrs/uuid"
 	"k8s.io/apimachinery/pkg/util/sets"
 
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 	"github.com/hwameistor/hwameistor/pkg/exechelper"
 	"github.com/hwameistor/hwameistor/pkg/exechelper/nsexecutor"
 )
@@ -150,6 +151,34 @@ func getVolumeMetrics(mntPoint string) (*VolumeMetrics, error) {
 	return nil, fmt.Errorf("not found")
 }
 
+// getPoolAvailableCapacityBytes returns the capacity which can still be provisioned in the pool.
+// The pool is considered as full once its usage reaches StoragePoolCapacityThresholdRatio.
+// For thin provisioning, the capacity is calculated against the overprovisioned size of the thin pool.
+func getPoolAvailableCapacityBytes(pool *apisv1alpha1.LocalPool, thin bool) int64 {
+	var available int64
+	if thin {
+		if pool.ThinPool == nil {
+			return 0
+		}
+		dataPercent, _ := strconv.ParseFloat(pool.ThinPool.DataPercent, 64)
+		if dataPercent >= apisv1alpha1.StoragePoolCapacityThresholdRatio*100 {
+			return 0
+		}
+		overProvisionRatio, _ := strconv.ParseFloat(pool.ThinPool.OverProvisionRatio, 64)
+		available = int64(float64(pool.ThinPool.Size)*overProvisionRatio) - pool.ThinPool.TotalProvisionedSize
+	} else {
+		available = int64(float64(pool.TotalCapacityBytes)*apisv1alpha1.StoragePoolCapacityThresholdRatio) - pool.UsedCapacityBytes
+		if available > pool.FreeCapacityBytes {
+			available = pool.FreeCapacityBytes
+		}
+	}
+
+	if available < 0 {
+		return 0
+	}
+	return available
+}
+
 func isStringInArray(str string, strs []string) bool {
 	for _, s := range strs {
 		if str == s {
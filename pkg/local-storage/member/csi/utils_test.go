@@ -5,6 +5,8 @@ import (
 	"testing"
 
 	csi "github.com/container-storage-interface/spec/lib/go/csi"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 )
 
 func Test_newControllerServiceCapability(t *testing.T) {
@@ -189,6 +191,53 @@ func Test_getVolumeMetrics(t *testing.T) {
 	}
 }
 
+func Test_getPoolAvailableCapacityBytes(t *testing.T) {
+	type args struct {
+		pool *apisv1alpha1.LocalPool
+		thin bool
+	}
+	tests := []struct {
+		name string
+		args args
+		want int64
+	}{
+		{
+			name: "thick pool below threshold",
+			args: args{pool: &apisv1alpha1.LocalPool{TotalCapacityBytes: 100, UsedCapacityBytes: 20, FreeCapacityBytes: 80}},
+			want: 65,
+		},
+		{
+			name: "thick pool over threshold",
+			args: args{pool: &apisv1alpha1.LocalPool{TotalCapacityBytes: 100, UsedCapacityBytes: 90, FreeCapacityBytes: 10}},
+			want: 0,
+		},
+		{
+			name: "thin pool not exist",
+			args: args{pool: &apisv1alpha1.LocalPool{TotalCapacityBytes: 100, FreeCapacityBytes: 100}, thin: true},
+			want: 0,
+		},
+		{
+			name: "thin pool overprovisioned",
+			args: args{pool: &apisv1alpha1.LocalPool{ThinPool: &apisv1alpha1.ThinPoolInfo{
+				Size: 100, OverProvisionRatio: "2.0", TotalProvisionedSize: 50, DataPercent: "10.00"}}, thin: true},
+			want: 150,
+		},
+		{
+			name: "thin pool data usage over threshold",
+			args: args{pool: &apisv1alpha1.LocalPool{ThinPool: &apisv1alpha1.ThinPoolInfo{
+				Size: 100, OverProvisionRatio: "2.0", TotalProvisionedSize: 50, DataPercent: "90.00"}}, thin: true},
+			want: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getPoolAvailableCapacityBytes(tt.args.pool, tt.args.thin); got != tt.want {
+				t.Errorf("getPoolAvailableCapacityBytes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func Test_isStringInArray(t *testing.T) {
 	type args struct {
 		str  string
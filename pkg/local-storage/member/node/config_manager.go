@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -14,6 +15,12 @@ import (
 	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 	"github.com/hwameistor/hwameistor/pkg/local-storage/common"
 	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/configer"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/utils"
+)
+
+const (
+	// interval to update the progress of the replica being resynced
+	replicaResyncCheckInterval = 10 * time.Second
 )
 
 type configManager struct {
@@ -119,10 +126,43 @@ func (m *configManager) ensureConfigForNonHA(replica *apisv1alpha1.LocalVolumeRe
 	logCtx := m.logger.WithFields(log.Fields{"replica": replica.Name})
 	logCtx.Debug("Ensuring config for non-HA volume replica")
 
+	// the volume is used through the cache stacked on the LV
+	if replica.Status.DevicePath == utils.VolumeCacheDevicePath(replica.Spec.VolumeName) {
+		return nil
+	}
+
+	if len(replica.Status.DevicePath) > 0 && replica.Status.DevicePath != replica.Status.StoragePath {
+		removing, err := m.isReplicationRemoving(replica)
+		if err != nil {
+			return err
+		}
+		if !removing {
+			logCtx.WithField("device", replica.Status.DevicePath).Warning("Unexpected device path of the non-HA volume replica, keep it as it is")
+			return nil
+		}
+		// the volume is converted from HA, remove the replication and use the LV directly.
+		// The DRBD metadata is internal at the end of the LV, so the data is kept as it is
+		logCtx.WithField("device", replica.Status.DevicePath).Info("Removing the replication of the volume converted to non-HA")
+		if err := m.configer.DeleteConfig(replica); err != nil {
+			logCtx.WithError(err).Error("Failed to remove the replication config")
+			return err
+		}
+	}
+
 	replica.Status.DevicePath = replica.Status.StoragePath
 	return nil
 }
 
+// isReplicationRemoving checks whether the volume is being converted from HA to non-HA by the LocalVolumeConvert
+func (m *configManager) isReplicationRemoving(replica *apisv1alpha1.LocalVolumeReplica) (bool, error) {
+	vol := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: replica.Spec.VolumeName}, vol); err != nil {
+		return false, err
+	}
+	_, exists := vol.Annotations[apisv1alpha1.VolumeReplicationRemovingAnnoKey]
+	return exists, nil
+}
+
 func (m *configManager) ensureConfigForHA(replica *apisv1alpha1.LocalVolumeReplica, config *apisv1alpha1.VolumeConfig) error {
 	logCtx := m.logger.WithFields(log.Fields{"replica": replica.Name})
 	logCtx.Debug("Ensuring config for HA volume replica")
@@ -259,13 +299,26 @@ func (m *configManager) processReplicaStatusUpdate(replicaName string) error {
 		return err
 	}
 
-	if replica.Status.HAState != nil && *replica.Status.HAState == haState {
+	// the resync progress is not notified by the events, so poll it until the replica is consistent
+	var outOfSyncBytes int64
+	if haState.State == apisv1alpha1.HAVolumeReplicaStateInconsistent {
+		syncing, bytes, err := m.configer.GetReplicaResyncState(&replica)
+		if err != nil {
+			m.logger.WithField("replica", replica.Name).WithError(err).Warning("Failed to get replica resync state")
+		} else if syncing {
+			outOfSyncBytes = bytes
+			m.syncReplicaStatusQueue.AddAfter(replicaName, replicaResyncCheckInterval)
+		}
+	}
+
+	if replica.Status.HAState != nil && *replica.Status.HAState == haState && replica.Status.OutOfSyncBytes == outOfSyncBytes {
 		return nil
 	}
 
 	newReplica := replica.DeepCopy()
 	newReplica.Status.HAState = &haState
 	newReplica.Status.State = m.genReplicaStateFromHAState(haState)
+	newReplica.Status.OutOfSyncBytes = outOfSyncBytes
 	patch := client.MergeFrom(&replica)
 	if err := m.apiClient.Status().Patch(context.TODO(), newReplica, patch); err != nil {
 		return fmt.Errorf("update replica %s status err: %s", replica.Name, err)
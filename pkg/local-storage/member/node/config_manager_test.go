@@ -1,11 +1,16 @@
 package node
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
+	"github.com/golang/mock/gomock"
 	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	v1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 	"github.com/hwameistor/hwameistor/pkg/local-storage/common"
@@ -257,38 +262,69 @@ func Test_configManager_ensureConfigForHA(t *testing.T) {
 }
 
 func Test_configManager_ensureConfigForNonHA(t *testing.T) {
-	type fields struct {
-		hostname               string
-		systemConfig           v1alpha1.SystemConfig
-		apiClient              client.Client
-		configer               configer.Configer
-		logger                 *log.Entry
-		syncReplicaStatusQueue *common.TaskQueue
-	}
-	type args struct {
-		replica *v1alpha1.LocalVolumeReplica
-		config  *v1alpha1.VolumeConfig
-	}
 	tests := []struct {
-		name    string
-		fields  fields
-		args    args
-		wantErr bool
+		name              string
+		devicePath        string
+		removing          bool
+		wantDevicePath    string
+		deleteConfigTimes int
 	}{
-		// TODO: Add test cases.
+		{
+			name:              "non-HA volume",
+			devicePath:        "/dev/LocalStorage_PoolHDD/pvc-1",
+			wantDevicePath:    "/dev/LocalStorage_PoolHDD/pvc-1",
+			deleteConfigTimes: 0,
+		},
+		{
+			name:              "new non-HA volume",
+			devicePath:        "",
+			wantDevicePath:    "/dev/LocalStorage_PoolHDD/pvc-1",
+			deleteConfigTimes: 0,
+		},
+		{
+			name:              "volume converted from HA",
+			devicePath:        "/dev/LocalStorage_PoolHDD-HA/pvc-1",
+			removing:          true,
+			wantDevicePath:    "/dev/LocalStorage_PoolHDD/pvc-1",
+			deleteConfigTimes: 1,
+		},
+		{
+			name:              "unexpected device path",
+			devicePath:        "/dev/LocalStorage_PoolHDD-HA/pvc-1",
+			wantDevicePath:    "/dev/LocalStorage_PoolHDD-HA/pvc-1",
+			deleteConfigTimes: 0,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			vol := &v1alpha1.LocalVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"}}
+			if tt.removing {
+				vol.Annotations = map[string]string{v1alpha1.VolumeReplicationRemovingAnnoKey: "true"}
+			}
+			replica := &v1alpha1.LocalVolumeReplica{}
+			replica.Name = "pvc-1-replica"
+			replica.Spec.VolumeName = vol.Name
+			replica.Status.StoragePath = "/dev/LocalStorage_PoolHDD/pvc-1"
+			replica.Status.DevicePath = tt.devicePath
+
+			c := configer.NewMockConfiger(ctrl)
+			c.EXPECT().DeleteConfig(replica).Return(nil).Times(tt.deleteConfigTimes)
+
+			s := runtime.NewScheme()
+			_ = v1alpha1.AddToScheme(s)
 			m := &configManager{
-				hostname:               tt.fields.hostname,
-				systemConfig:           tt.fields.systemConfig,
-				apiClient:              tt.fields.apiClient,
-				configer:               tt.fields.configer,
-				logger:                 tt.fields.logger,
-				syncReplicaStatusQueue: tt.fields.syncReplicaStatusQueue,
+				apiClient: fake.NewClientBuilder().WithScheme(s).WithObjects(vol).Build(),
+				configer:  c,
+				logger:    log.WithField("Module", "NodeManager"),
+			}
+			if err := m.ensureConfigForNonHA(replica, &v1alpha1.VolumeConfig{}); err != nil {
+				t.Fatalf("ensureConfigForNonHA() error = %v", err)
 			}
-			if err := m.ensureConfigForNonHA(tt.args.replica, tt.args.config); (err != nil) != tt.wantErr {
-				t.Errorf("ensureConfigForNonHA() error = %v, wantErr %v", err, tt.wantErr)
+			if replica.Status.DevicePath != tt.wantDevicePath {
+				t.Errorf("ensureConfigForNonHA() got device path %s, want %s", replica.Status.DevicePath, tt.wantDevicePath)
 			}
 		})
 	}
@@ -565,6 +601,53 @@ func Test_configManager_processReplicaStatusUpdate(t *testing.T) {
 	}
 }
 
+func Test_configManager_processReplicaStatusUpdate_resync(t *testing.T) {
+	replica := &v1alpha1.LocalVolumeReplica{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1-abcde"}}
+	s := runtime.NewScheme()
+	_ = v1alpha1.AddToScheme(s)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	c := configer.NewMockConfiger(ctrl)
+	inconsistent := v1alpha1.HAState{State: v1alpha1.HAVolumeReplicaStateInconsistent, Reason: "device is Inconsistent"}
+	consistent := v1alpha1.HAState{State: v1alpha1.HAVolumeReplicaStateConsistent, Reason: "device is UpToDate"}
+	gomock.InOrder(
+		c.EXPECT().GetReplicaHAState(gomock.Any()).Return(inconsistent, nil),
+		c.EXPECT().GetReplicaResyncState(gomock.Any()).Return(true, int64(4096), nil),
+		c.EXPECT().GetReplicaHAState(gomock.Any()).Return(consistent, nil),
+	)
+
+	m := &configManager{
+		apiClient:              fake.NewClientBuilder().WithScheme(s).WithObjects(replica).Build(),
+		configer:               c,
+		logger:                 log.WithField("Module", "NodeConfigManager"),
+		syncReplicaStatusQueue: common.NewTaskQueue("syncReplicaStatusQueue", 0),
+	}
+	defer m.syncReplicaStatusQueue.Shutdown()
+
+	// resyncing
+	if err := m.processReplicaStatusUpdate(replica.Name); err != nil {
+		t.Fatalf("processReplicaStatusUpdate() error = %v", err)
+	}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: replica.Name}, replica); err != nil {
+		t.Fatalf("Get LocalVolumeReplica error = %v", err)
+	}
+	if replica.Status.OutOfSyncBytes != 4096 || replica.Status.State != v1alpha1.VolumeReplicaStateNotReady {
+		t.Errorf("processReplicaStatusUpdate() got status %+v when resyncing", replica.Status)
+	}
+
+	// resynced
+	if err := m.processReplicaStatusUpdate(replica.Name); err != nil {
+		t.Fatalf("processReplicaStatusUpdate() error = %v", err)
+	}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: replica.Name}, replica); err != nil {
+		t.Fatalf("Get LocalVolumeReplica error = %v", err)
+	}
+	if replica.Status.OutOfSyncBytes != 0 || replica.Status.State != v1alpha1.VolumeReplicaStateReady {
+		t.Errorf("processReplicaStatusUpdate() got status %+v when resynced", replica.Status)
+	}
+}
+
 func Test_configManager_startReplicaStatusSyncWorker(t *testing.T) {
 	type fields struct {
 		hostname               string
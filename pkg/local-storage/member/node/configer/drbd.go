@@ -49,8 +49,13 @@ const (
 	ReplicationEstablished = "Established"
 	ReplicationSyncSource  = "SyncSource"
 	ReplicationSyncTarget  = "SyncTarget"
+	ReplicationVerifyS     = "VerifyS"
+	ReplicationVerifyT     = "VerifyT"
+	ReplicationPausedSyncT = "PausedSyncT"
 
 	drbdMaxPeerCount = 3
+	// quorum doesn't work for 2 peers, the surviving one loses the quorum when the other is down
+	drbdQuorumMinPeerCount = 3
 )
 
 var (
@@ -58,8 +63,20 @@ var (
 
   net {
     protocol C;
+    verify-alg sha256;
   }
-{{ range .Peers }}
+{{ if .ResyncRateKiB }}
+  disk {
+    c-max-rate {{ .ResyncRateKiB }}k;
+  }
+{{ end }}
+{{- if .Quorum }}
+  options {
+    quorum majority;
+    on-no-quorum io-error;
+  }
+{{ end }}
+{{- range .Peers }}
 
   on {{ .Hostname }} { 
     device    minor {{ $.Minor }};
@@ -82,6 +99,10 @@ type drbdConfig struct {
 	Minor        int
 	DevicePath   string
 	Peers        []apisv1alpha1.VolumeReplica
+	// Quorum is enabled for 3 or more peers, the partitioned minority can't write to avoid the split brain
+	Quorum bool
+	// ResyncRateKiB caps the resync rate in KiB per second, no limit if 0
+	ResyncRateKiB int64
 }
 
 type Resource struct {
@@ -672,6 +693,147 @@ func (m *drbdConfigure) GetReplicaHAState(replica *apisv1alpha1.LocalVolumeRepli
 	return haState, nil
 }
 
+// VerifyReplica starts the online verify of the replica against all its peers. The config is rewritten
+// and adjusted at first, as the verify-alg is not set for the resources created by the older versions
+func (m *drbdConfigure) VerifyReplica(replica *apisv1alpha1.LocalVolumeReplica) error {
+	m.lock.Lock()
+	config, exists := m.localConfigs[replica.Name]
+	m.lock.Unlock()
+	if !exists {
+		return fmt.Errorf("replica %s config not found", replica.Name)
+	}
+
+	conf := m.config2DRBDConfig(replica, config)
+	if err := m.writeConfigFile(conf.ResourceName, conf); err != nil {
+		return fmt.Errorf("write config file err: %s", err)
+	}
+	if err := m.adjustResource(conf.ResourceName); err != nil {
+		return err
+	}
+
+	params := exechelper.ExecParams{
+		CmdName: drbdadmCmd,
+		CmdArgs: []string{"verify", conf.ResourceName},
+	}
+	result := m.cmdExec.RunCommand(params)
+	if result.ExitCode != 0 {
+		return fmt.Errorf("verify resource %s err: %d, %s", conf.ResourceName, result.ExitCode, result.ErrBuf.String())
+	}
+	return nil
+}
+
+// StopVerifyReplica stops the running online verify of the replica. DRBD can't stop the verify directly,
+// so the resource is disconnected from its peers and connected again
+func (m *drbdConfigure) StopVerifyReplica(replica *apisv1alpha1.LocalVolumeReplica) error {
+	resourceName := m.genResourceName(replica)
+	for _, action := range []string{"disconnect", "connect"} {
+		params := exechelper.ExecParams{
+			CmdName: drbdadmCmd,
+			CmdArgs: []string{action, resourceName},
+		}
+		result := m.cmdExec.RunCommand(params)
+		if result.ExitCode != 0 {
+			return fmt.Errorf("%s resource %s err: %d, %s", action, resourceName, result.ExitCode, result.ErrBuf.String())
+		}
+	}
+	return nil
+}
+
+// GetReplicaVerifyState returns whether the online verify of the replica is still running,
+// and the size of the out-of-sync blocks found between the replica and its peers
+func (m *drbdConfigure) GetReplicaVerifyState(replica *apisv1alpha1.LocalVolumeReplica) (bool, int64, error) {
+	resourceName := m.genResourceName(replica)
+	params := exechelper.ExecParams{
+		CmdName: drbdsetupCmd,
+		CmdArgs: []string{"status", resourceName, "--statistics"},
+	}
+	result := m.cmdExec.RunCommand(params)
+	if result.ExitCode != 0 {
+		return false, 0, fmt.Errorf("get resource %s status err: %d, %s", resourceName, result.ExitCode, result.ErrBuf.String())
+	}
+
+	running, outOfSyncBytes := parseVerifyState(result.OutBuf.String())
+	return running, outOfSyncBytes, nil
+}
+
+// parseVerifyState parses the output of "drbdsetup status --statistics", eg:
+//
+//	pvc-1 role:Primary
+//	  disk:UpToDate
+//	  node2 role:Secondary
+//	    replication:VerifyS peer-disk:UpToDate done:34.50
+//	        received:0 sent:1024 out-of-sync:8 pending:0 unacked:0
+//
+// the verify is running if any peer is in VerifyS/VerifyT, and the out-of-sync is in KiB
+func parseVerifyState(output string) (bool, int64) {
+	running := false
+	var outOfSyncBytes int64
+	for _, field := range strings.Fields(output) {
+		key, value, found := strings.Cut(field, ":")
+		if !found {
+			continue
+		}
+		switch key {
+		case "replication":
+			if value == ReplicationVerifyS || value == ReplicationVerifyT {
+				running = true
+			}
+		case "out-of-sync":
+			outOfSyncKiB, _ := strconv.ParseInt(value, 10, 64)
+			outOfSyncBytes += outOfSyncKiB * 1024
+		}
+	}
+	return running, outOfSyncBytes
+}
+
+// GetReplicaResyncState returns whether the replica is being resynced from its peers,
+// and the size of the data still to be resynced
+func (m *drbdConfigure) GetReplicaResyncState(replica *apisv1alpha1.LocalVolumeReplica) (bool, int64, error) {
+	resourceName := m.genResourceName(replica)
+	params := exechelper.ExecParams{
+		CmdName: drbdsetupCmd,
+		CmdArgs: []string{"status", resourceName, "--statistics"},
+	}
+	result := m.cmdExec.RunCommand(params)
+	if result.ExitCode != 0 {
+		return false, 0, fmt.Errorf("get resource %s status err: %d, %s", resourceName, result.ExitCode, result.ErrBuf.String())
+	}
+
+	syncing, outOfSyncBytes := parseResyncState(result.OutBuf.String())
+	return syncing, outOfSyncBytes, nil
+}
+
+// parseResyncState parses the output of "drbdsetup status --statistics" on the sync target, eg:
+//
+//	pvc-1 role:Secondary
+//	  disk:Inconsistent
+//	  node1 role:Primary
+//	    replication:SyncTarget peer-disk:UpToDate done:34.50
+//	        received:1024 sent:0 out-of-sync:2048 pending:0 unacked:0
+//
+// only the out-of-sync of the peers syncing to this replica is counted, and it's in KiB
+func parseResyncState(output string) (bool, int64) {
+	syncing, peerSyncing := false, false
+	var outOfSyncBytes int64
+	for _, field := range strings.Fields(output) {
+		key, value, found := strings.Cut(field, ":")
+		if !found {
+			continue
+		}
+		switch key {
+		case "replication":
+			peerSyncing = value == ReplicationSyncTarget || value == ReplicationPausedSyncT
+			syncing = syncing || peerSyncing
+		case "out-of-sync":
+			if peerSyncing {
+				outOfSyncKiB, _ := strconv.ParseInt(value, 10, 64)
+				outOfSyncBytes += outOfSyncKiB * 1024
+			}
+		}
+	}
+	return syncing, outOfSyncBytes
+}
+
 func (m *drbdConfigure) hasMetadata(minor int, devicePath string) bool {
 	// force is needed if the drbd-resource is still in Negotiating state or earlier.
 	// in that case, drbdmeta asks "Exclusive open failed. Do it anyways?" and expects to type 'yes'.
@@ -713,12 +875,34 @@ func (m *drbdConfigure) validateMetadata(resourceName string, nodeID int) bool {
 func (m *drbdConfigure) config2DRBDConfig(replica *apisv1alpha1.LocalVolumeReplica, config apisv1alpha1.VolumeConfig) drbdConfig {
 	port := config.ResourceID + m.systemConfig.DRBD.StartPort
 	return drbdConfig{
-		ResourceName: m.genResourceName(replica),
-		Port:         port,
-		Minor:        port,
-		DevicePath:   replica.Status.StoragePath,
-		Peers:        config.Replicas,
+		ResourceName:  m.genResourceName(replica),
+		Port:          port,
+		Minor:         port,
+		DevicePath:    replica.Status.StoragePath,
+		Peers:         config.Replicas,
+		Quorum:        len(config.Replicas) >= drbdQuorumMinPeerCount,
+		ResyncRateKiB: resyncRateKiB(config.ResyncBandwidthLimit),
+	}
+}
+
+// MetadataSizeBytes returns the space taken by the internal DRBD metadata at the end of the backing device:
+// 4KiB superblock, 32KiB activity log, and a bitmap of 1 bit per 4KiB for each peer, rounded up to 1MiB
+func MetadataSizeBytes(capacityBytes int64) int64 {
+	const align = 4096
+	bitmapBytes := (capacityBytes/(4096*8) + align - 1) / align * align
+	metadataBytes := 4096 + 32*1024 + bitmapBytes*drbdMaxPeerCount
+	return (metadataBytes + 1024*1024 - 1) / (1024 * 1024) * (1024 * 1024)
+}
+
+// resyncRateKiB converts the bandwidth limit in bytes to the rate in KiB, at least 1KiB
+func resyncRateKiB(bandwidthLimit int64) int64 {
+	if bandwidthLimit <= 0 {
+		return 0
+	}
+	if bandwidthLimit < 1024 {
+		return 1
 	}
+	return bandwidthLimit / 1024
 }
 
 func (m *drbdConfigure) isDeviceUpToDate(resourceName string) (bool, error) {
@@ -89,6 +89,38 @@ func (mr *MockConfigerMockRecorder) GetReplicaHAState(replica interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReplicaHAState", reflect.TypeOf((*MockConfiger)(nil).GetReplicaHAState), replica)
 }
 
+// GetReplicaResyncState mocks base method.
+func (m *MockConfiger) GetReplicaResyncState(replica *v1alpha1.LocalVolumeReplica) (bool, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReplicaResyncState", replica)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetReplicaResyncState indicates an expected call of GetReplicaResyncState.
+func (mr *MockConfigerMockRecorder) GetReplicaResyncState(replica interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReplicaResyncState", reflect.TypeOf((*MockConfiger)(nil).GetReplicaResyncState), replica)
+}
+
+// GetReplicaVerifyState mocks base method.
+func (m *MockConfiger) GetReplicaVerifyState(replica *v1alpha1.LocalVolumeReplica) (bool, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReplicaVerifyState", replica)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetReplicaVerifyState indicates an expected call of GetReplicaVerifyState.
+func (mr *MockConfigerMockRecorder) GetReplicaVerifyState(replica interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReplicaVerifyState", reflect.TypeOf((*MockConfiger)(nil).GetReplicaVerifyState), replica)
+}
+
 // HasConfig mocks base method.
 func (m *MockConfiger) HasConfig(replica *v1alpha1.LocalVolumeReplica) bool {
 	m.ctrl.T.Helper()
@@ -142,3 +174,31 @@ func (mr *MockConfigerMockRecorder) Run(stopCh interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Run", reflect.TypeOf((*MockConfiger)(nil).Run), stopCh)
 }
+
+// StopVerifyReplica mocks base method.
+func (m *MockConfiger) StopVerifyReplica(replica *v1alpha1.LocalVolumeReplica) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StopVerifyReplica", replica)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StopVerifyReplica indicates an expected call of StopVerifyReplica.
+func (mr *MockConfigerMockRecorder) StopVerifyReplica(replica interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StopVerifyReplica", reflect.TypeOf((*MockConfiger)(nil).StopVerifyReplica), replica)
+}
+
+// VerifyReplica mocks base method.
+func (m *MockConfiger) VerifyReplica(replica *v1alpha1.LocalVolumeReplica) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyReplica", replica)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyReplica indicates an expected call of VerifyReplica.
+func (mr *MockConfigerMockRecorder) VerifyReplica(replica interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyReplica", reflect.TypeOf((*MockConfiger)(nil).VerifyReplica), replica)
+}
@@ -180,6 +180,71 @@ func Test_drbdConfigure_ConsistencyCheck(t *testing.T) {
 	fmt.Printf("Test_drbdConfigure_ConsistencyCheck")
 }
 
+func Test_drbdConfigure_config2DRBDConfig(t *testing.T) {
+	m, err := NewDRBDConfiger("node1", apisv1alpha1.SystemConfig{DRBD: &apisv1alpha1.DRBDSystemConfig{StartPort: 43001}}, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	replica := &apisv1alpha1.LocalVolumeReplica{}
+	replica.Name = "pvc-1-abcde"
+	replica.Spec.VolumeName = "pvc-1"
+	replica.Status.StoragePath = "/dev/LocalStorage_PoolHDD/pvc-1"
+
+	peers := []apisv1alpha1.VolumeReplica{
+		{ID: 1, Hostname: "node1", IP: "10.6.0.1", Primary: true},
+		{ID: 2, Hostname: "node2", IP: "10.6.0.2"},
+		{ID: 3, Hostname: "node3", IP: "10.6.0.3"},
+	}
+	tests := []struct {
+		name        string
+		peers       []apisv1alpha1.VolumeReplica
+		resyncLimit int64
+		wantQuorum  bool
+		wantRate    string
+	}{
+		{
+			name:       "2 replicas",
+			peers:      peers[:2],
+			wantQuorum: false,
+		},
+		{
+			name:       "3 replicas",
+			peers:      peers,
+			wantQuorum: true,
+		},
+		{
+			name:        "resync bandwidth limited",
+			peers:       peers[:2],
+			resyncLimit: 100 * 1024 * 1024,
+			wantRate:    "c-max-rate 102400k;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := m.config2DRBDConfig(replica, apisv1alpha1.VolumeConfig{ResourceID: 5, Replicas: tt.peers, ResyncBandwidthLimit: tt.resyncLimit})
+			if conf.Port != 43006 || conf.Quorum != tt.wantQuorum {
+				t.Errorf("config2DRBDConfig() got port %d, quorum %v", conf.Port, conf.Quorum)
+			}
+
+			buf := &strings.Builder{}
+			if err := m.template.Execute(buf, conf); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.Contains(buf.String(), "quorum majority;"); got != tt.wantQuorum {
+				t.Errorf("rendered quorum %v, want %v:\n%s", got, tt.wantQuorum, buf.String())
+			}
+			if got := strings.Contains(buf.String(), "c-max-rate"); got != (len(tt.wantRate) > 0) || !strings.Contains(buf.String(), tt.wantRate) {
+				t.Errorf("rendered resync rate %v, want %q:\n%s", got, tt.wantRate, buf.String())
+			}
+			for _, peer := range tt.peers {
+				if !strings.Contains(buf.String(), fmt.Sprintf("node-id %d;", peer.ID)) || !strings.Contains(buf.String(), peer.IP+":43006") {
+					t.Errorf("peer %s is not rendered:\n%s", peer.Hostname, buf.String())
+				}
+			}
+		})
+	}
+}
+
 func Test_genConfigPath(t *testing.T) {
 	type args struct {
 		resourceName string
@@ -334,3 +399,102 @@ func Test_handleDRBDEvent(t *testing.T) {
 	}
 
 }
+
+func Test_parseVerifyState(t *testing.T) {
+	tests := []struct {
+		name               string
+		output             string
+		wantRunning        bool
+		wantOutOfSyncBytes int64
+	}{
+		{
+			name: "verifying",
+			output: `pvc-1 role:Primary
+  disk:UpToDate
+  node2 role:Secondary
+    replication:VerifyS peer-disk:UpToDate done:34.50
+        received:0 sent:1024 out-of-sync:0 pending:0 unacked:0
+`,
+			wantRunning: true,
+		},
+		{
+			name: "out of sync with 2 peers",
+			output: `pvc-1 role:Primary
+  disk:UpToDate
+  node2 role:Secondary
+    replication:Established peer-disk:UpToDate
+        received:0 sent:1024 out-of-sync:8 pending:0 unacked:0
+  node3 role:Secondary
+    replication:Established peer-disk:UpToDate
+        received:0 sent:1024 out-of-sync:4 pending:0 unacked:0
+`,
+			wantOutOfSyncBytes: 12 * 1024,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			running, outOfSyncBytes := parseVerifyState(tt.output)
+			if running != tt.wantRunning || outOfSyncBytes != tt.wantOutOfSyncBytes {
+				t.Errorf("parseVerifyState() = %v, %d, want %v, %d", running, outOfSyncBytes, tt.wantRunning, tt.wantOutOfSyncBytes)
+			}
+		})
+	}
+}
+
+func Test_parseResyncState(t *testing.T) {
+	tests := []struct {
+		name               string
+		output             string
+		wantSyncing        bool
+		wantOutOfSyncBytes int64
+	}{
+		{
+			name: "resyncing from the primary",
+			output: `pvc-1 role:Secondary
+  disk:Inconsistent
+  node1 role:Primary
+    replication:SyncTarget peer-disk:UpToDate done:34.50
+        received:1024 sent:0 out-of-sync:2048 pending:0 unacked:0
+  node3 role:Secondary
+    replication:Established peer-disk:UpToDate
+        received:0 sent:0 out-of-sync:8 pending:0 unacked:0
+`,
+			wantSyncing:        true,
+			wantOutOfSyncBytes: 2048 * 1024,
+		},
+		{
+			name: "synced",
+			output: `pvc-1 role:Secondary
+  disk:UpToDate
+  node1 role:Primary
+    replication:Established peer-disk:UpToDate
+        received:1024 sent:0 out-of-sync:0 pending:0 unacked:0
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			syncing, outOfSyncBytes := parseResyncState(tt.output)
+			if syncing != tt.wantSyncing || outOfSyncBytes != tt.wantOutOfSyncBytes {
+				t.Errorf("parseResyncState() = %v, %d, want %v, %d", syncing, outOfSyncBytes, tt.wantSyncing, tt.wantOutOfSyncBytes)
+			}
+		})
+	}
+}
+
+func TestMetadataSizeBytes(t *testing.T) {
+	tests := []struct {
+		capacityBytes int64
+		want          int64
+	}{
+		{capacityBytes: 1024 * 1024 * 1024, want: 1024 * 1024},
+		{capacityBytes: 100 * 1024 * 1024 * 1024, want: 10 * 1024 * 1024},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.capacityBytes), func(t *testing.T) {
+			if got := MetadataSizeBytes(tt.capacityBytes); got != tt.want {
+				t.Errorf("MetadataSizeBytes() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -20,4 +20,12 @@ type Configer interface {
 	GetReplicaHAState(replica *apisv1alpha1.LocalVolumeReplica) (state apisv1alpha1.HAState, err error)
 
 	ConsistencyCheck(replicas []apisv1alpha1.LocalVolumeReplica)
+	// VerifyReplica starts the online verify of the replica against its peers
+	VerifyReplica(replica *apisv1alpha1.LocalVolumeReplica) error
+	// StopVerifyReplica stops the running online verify of the replica
+	StopVerifyReplica(replica *apisv1alpha1.LocalVolumeReplica) error
+	// GetReplicaVerifyState return whether the verify is running, and the out-of-sync bytes found
+	GetReplicaVerifyState(replica *apisv1alpha1.LocalVolumeReplica) (running bool, outOfSyncBytes int64, err error)
+	// GetReplicaResyncState return whether the replica is being resynced, and the bytes still to be resynced
+	GetReplicaResyncState(replica *apisv1alpha1.LocalVolumeReplica) (syncing bool, outOfSyncBytes int64, err error)
 }
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"syscall"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
@@ -14,6 +17,9 @@ import (
 	"github.com/hwameistor/hwameistor/pkg/local-storage/utils/datacopy"
 )
 
+// syncProgressUpdateInterval is the interval to report the progress of the data sync on the target node
+const syncProgressUpdateInterval = 10 * time.Second
+
 func (m *manager) startSyncVolumeMountTaskWorker(stopCh <-chan struct{}) {
 
 	m.logger.Debug("VolumeBlockMount Assignment Worker is working now")
@@ -86,10 +92,15 @@ func (m *manager) processSyncVolumeMount(lvName string) error {
 		return nil
 	}
 
+	// the block device is copied directly by blocksync, no need to mount it
+	blockSync := cm.Data[datacopy.SyncConfigSyncToolKey] == datacopy.SyncToolBlockSync
+
 	if cm.Data[datacopy.SyncConfigSyncCompleteKey] == datacopy.SyncTrue {
 		m.logger.WithField("mountpoint", mountPoint).Debug("Trying to umount volume")
 
-		if err := m.mounter.Unmount(mountPoint); err != nil {
+		if blockSync {
+			logCtx.Debug("Volume is not mounted for blocksync")
+		} else if err := m.mounter.Unmount(mountPoint); err != nil {
 			if !os.IsNotExist(err) {
 				m.logger.WithField("mountpoint", mountPoint).WithError(err).Error("Failed to Unmount volume")
 				return err
@@ -111,8 +122,11 @@ func (m *manager) processSyncVolumeMount(lvName string) error {
 		return nil
 	}
 
-	newLvName := strings.Replace(lvName, "-", "--", -1)
-	devPath := fmt.Sprintf("/dev/mapper/%s-%s", vol.Spec.PoolName, newLvName)
+	devPath, err := datacopy.GetVolumeDevicePath(m.apiClient, vol, m.name)
+	if err != nil {
+		m.logger.WithField("LocalVolume", lvName).WithError(err).Error("Failed to get the device of the volume")
+		return err
+	}
 
 	fsType := vol.Status.PublishedFSType
 	if len(fsType) == 0 {
@@ -120,7 +134,7 @@ func (m *manager) processSyncVolumeMount(lvName string) error {
 		fsType = "xfs"
 	}
 	// return directly if device has already mounted at TargetPath
-	if !isStringInArray(mountPoint, m.mounter.GetDeviceMountPoints(devPath)) {
+	if !blockSync && !isStringInArray(mountPoint, m.mounter.GetDeviceMountPoints(devPath)) {
 		m.logger.WithField("mountpoint", mountPoint).Debug("Trying to format and mount volume")
 		if err := m.mounter.FormatAndMount(devPath, mountPoint, fsType, []string{}); err != nil {
 			m.logger.WithField("mountpoint", mountPoint).WithError(err).Error("Failed to FormatAndMount volume")
@@ -134,12 +148,23 @@ func (m *manager) processSyncVolumeMount(lvName string) error {
 		}
 		cm.Data[datacopy.SyncConfigSourceNodeReadyKey] = datacopy.SyncTrue
 		cm.Data[datacopy.SyncConfigSourceMountPointKey] = mountPoint
+		// the whole device is copied by blocksync, while only the used space by the others
+		totalBytes := vol.Spec.RequiredCapacityBytes
+		if !blockSync {
+			if totalBytes, err = fsUsedBytes(mountPoint); err != nil {
+				logCtx.WithField("mountpoint", mountPoint).WithError(err).Warning("Failed to get used bytes of volume")
+			}
+		}
+		if totalBytes > 0 {
+			cm.Data[datacopy.SyncConfigTotalBytesKey] = strconv.FormatInt(totalBytes, 10)
+		}
 	} else {
 		if cm.Data[datacopy.SyncConfigTargetNodeReadyKey] == datacopy.SyncTrue {
-			return nil
+			return m.updateSyncProgress(lvName, mountPoint, blockSync, cm)
 		}
 		cm.Data[datacopy.SyncConfigTargetNodeReadyKey] = datacopy.SyncTrue
 		cm.Data[datacopy.SyncConfigTargetMountPointKey] = mountPoint
+		m.syncVolumeMountTaskQueue.AddAfter(lvName, syncProgressUpdateInterval)
 	}
 	if err := m.apiClient.Update(ctx, cm); err != nil {
 		m.logger.WithField("configmap", cm.Name).WithError(err).Error("Failed to update rclone's config")
@@ -149,6 +174,48 @@ func (m *manager) processSyncVolumeMount(lvName string) error {
 	return nil
 }
 
+// updateSyncProgress records the bytes copied into the target volume, and checks it again later until the sync completes
+func (m *manager) updateSyncProgress(lvName string, mountPoint string, blockSync bool, cm *corev1.ConfigMap) error {
+	defer m.syncVolumeMountTaskQueue.AddAfter(lvName, syncProgressUpdateInterval)
+
+	var copiedBytes int64
+	var err error
+	if blockSync {
+		copiedBytes, err = readBlockSyncProgress(datacopy.BlockSyncProgressFile(lvName))
+	} else {
+		copiedBytes, err = fsUsedBytes(mountPoint)
+	}
+	if err != nil {
+		// the progress file is not created until the sync job starts
+		m.logger.WithFields(log.Fields{"LocalVolume": lvName, "error": err.Error()}).Debug("No progress of data sync yet")
+		return nil
+	}
+
+	value := strconv.FormatInt(copiedBytes, 10)
+	if cm.Data[datacopy.SyncConfigCopiedBytesKey] == value {
+		return nil
+	}
+	cm.Data[datacopy.SyncConfigCopiedBytesKey] = value
+	return m.apiClient.Update(context.TODO(), cm)
+}
+
+func readBlockSyncProgress(progressFile string) (int64, error) {
+	content, err := os.ReadFile(progressFile)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+}
+
+// fsUsedBytes returns the used space of the filesystem mounted at the path
+func fsUsedBytes(mountPoint string) (int64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(mountPoint, &stat); err != nil {
+		return 0, err
+	}
+	return int64(stat.Blocks-stat.Bfree) * int64(stat.Bsize), nil
+}
+
 func (m *manager) getReplicasForVolume(volName string) ([]*apisv1alpha1.LocalVolumeReplica, error) {
 	// todo
 	replicaList := &apisv1alpha1.LocalVolumeReplicaList{}
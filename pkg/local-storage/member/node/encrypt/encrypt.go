@@ -1,5 +1,7 @@
 package encrypt
 
+import "context"
+
 type Encryptor interface {
 	// EncryptVolume encrypts the volume with the given secret.
 	EncryptVolume(volumePath string, secret string) error
@@ -15,4 +17,21 @@ type Encryptor interface {
 
 	// OpenVolume opens the volume with given secret and returns the decrypt volume name.
 	OpenVolume(volumePath string, secret string) (string, error)
+
+	// AddKey adds the new secret into a free key slot of the volume, unlocked by the existing secret.
+	AddKey(volumePath string, existingSecret string, newSecret string) error
+
+	// VerifyKey checks if the volume can be unlocked by the given secret.
+	VerifyKey(volumePath string, secret string) error
+
+	// RemoveKey removes the key slot of the given secret from the volume.
+	RemoveKey(volumePath string, secret string) error
+
+	// InitEncryptVolume initializes the encryption of the plain volume in place, the data is shifted by reduceSize
+	// to make room for the header, so the last reduceSize of the volume must be free.
+	InitEncryptVolume(volumePath string, secret string, reduceSize int64) error
+
+	// ResumeEncryptVolume encrypts the rest of the volume initialized by InitEncryptVolume, and reports the progress
+	// in percent. It can be interrupted by the context, and resumed again.
+	ResumeEncryptVolume(ctx context.Context, volumePath string, secret string, progress func(percent float64)) error
 }
@@ -0,0 +1,65 @@
+package encrypt
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+// KeyProvider holds the keys to encrypt the volumes. A key is referenced by a string saved
+// in the volume, e.g. the name of the Secret, or the data key wrapped by the KMS
+type KeyProvider interface {
+	// GetKey returns the key of the reference
+	GetKey(keyRef string) (string, error)
+
+	// NewKey generates a new key and returns its reference
+	NewKey() (string, error)
+}
+
+// NewKeyProvider returns the key provider of the encrypted volume
+func NewKeyProvider(cli client.Client, volumeEncrypt *apisv1alpha1.VolumeEncrypt) (KeyProvider, error) {
+	switch volumeEncrypt.KeyProvider {
+	case "", apisv1alpha1.VolumeEncryptKeyProviderSecret:
+		return NewSecretKeyProvider(cli), nil
+	case apisv1alpha1.VolumeEncryptKeyProviderKMS:
+		if volumeEncrypt.KMS == nil {
+			return nil, fmt.Errorf("kms is not configured for the key provider %s", volumeEncrypt.KeyProvider)
+		}
+		return NewKMSKeyProvider(cli, volumeEncrypt.KMS)
+	default:
+		return nil, fmt.Errorf("unsupported key provider %s", volumeEncrypt.KeyProvider)
+	}
+}
+
+// GetKeyRef returns the reference of the key in use by the encrypted volume
+func GetKeyRef(volumeEncrypt *apisv1alpha1.VolumeEncrypt) string {
+	if volumeEncrypt.KeyProvider == apisv1alpha1.VolumeEncryptKeyProviderKMS {
+		if volumeEncrypt.KMS == nil {
+			return ""
+		}
+		return volumeEncrypt.KMS.WrappedKey
+	}
+	return volumeEncrypt.SecretNamespacedName
+}
+
+// SetKeyRef switches the encrypted volume to the key of the reference
+func SetKeyRef(volumeEncrypt *apisv1alpha1.VolumeEncrypt, keyRef string) {
+	if volumeEncrypt.KeyProvider == apisv1alpha1.VolumeEncryptKeyProviderKMS {
+		if volumeEncrypt.KMS != nil {
+			volumeEncrypt.KMS.WrappedKey = keyRef
+		}
+		return
+	}
+	volumeEncrypt.SecretNamespacedName = keyRef
+}
+
+// GetVolumeKey returns the key in use by the encrypted volume
+func GetVolumeKey(cli client.Client, volumeEncrypt *apisv1alpha1.VolumeEncrypt) (string, error) {
+	provider, err := NewKeyProvider(cli, volumeEncrypt)
+	if err != nil {
+		return "", err
+	}
+	return provider.GetKey(GetKeyRef(volumeEncrypt))
+}
@@ -0,0 +1,158 @@
+package encrypt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+// fakeTransit is a local stand-in of the Vault transit API
+type fakeTransit struct {
+	token   string
+	keyName string
+
+	lock     sync.Mutex
+	count    int
+	dataKeys map[string]string
+}
+
+func (ft *fakeTransit) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.Header.Get(kmsTokenHeader) != ft.token {
+		w.WriteHeader(http.StatusForbidden)
+		_ = json.NewEncoder(w).Encode(map[string][]string{"errors": {"permission denied"}})
+		return
+	}
+
+	ft.lock.Lock()
+	defer ft.lock.Unlock()
+	resp := kmsResponse{}
+	switch r.URL.Path {
+	case "/v1/transit/datakey/plaintext/" + ft.keyName:
+		ft.count++
+		resp.Data.Plaintext = base64.StdEncoding.EncodeToString([]byte(fmt.Sprintf("data-key-%d", ft.count)))
+		resp.Data.Ciphertext = fmt.Sprintf("vault:v1:wrapped-%d", ft.count)
+		ft.dataKeys[resp.Data.Ciphertext] = resp.Data.Plaintext
+	case "/v1/transit/decrypt/" + ft.keyName:
+		req := map[string]string{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		plaintext, exists := ft.dataKeys[req["ciphertext"]]
+		if !exists {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string][]string{"errors": {"invalid ciphertext"}})
+			return
+		}
+		resp.Data.Plaintext = plaintext
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(map[string][]string{"errors": {"no handler for route"}})
+		return
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func newFakeClient(t *testing.T, objs ...client.Object) client.Client {
+	s := runtime.NewScheme()
+	if err := v1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+}
+
+func newSecret(name string, data map[string]string) *v1.Secret {
+	secret := &v1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "hwameistor", Name: name}, Data: map[string][]byte{}}
+	for k, v := range data {
+		secret.Data[k] = []byte(v)
+	}
+	return secret
+}
+
+func TestSecretKeyProvider(t *testing.T) {
+	cli := newFakeClient(t, newSecret("encrypt-secret", map[string]string{"key": "passphrase"}), newSecret("empty", nil))
+	volumeEncrypt := &apisv1alpha1.VolumeEncrypt{Enable: true, Type: "LUKS", SecretNamespacedName: "hwameistor/encrypt-secret"}
+
+	key, err := GetVolumeKey(cli, volumeEncrypt)
+	if err != nil || key != "passphrase" {
+		t.Errorf("GetVolumeKey() = %v, %v, want passphrase", key, err)
+	}
+
+	provider := NewSecretKeyProvider(cli)
+	for _, keyRef := range []string{"encrypt-secret", "hwameistor/empty", "hwameistor/not-exist"} {
+		if _, err := provider.GetKey(keyRef); err == nil {
+			t.Errorf("GetKey(%s) expects an error", keyRef)
+		}
+	}
+	if _, err := provider.NewKey(); err == nil {
+		t.Errorf("NewKey() expects an error")
+	}
+}
+
+func TestKMSKeyProvider(t *testing.T) {
+	transit := &fakeTransit{token: "s.token", keyName: "hwameistor", dataKeys: map[string]string{}}
+	server := httptest.NewServer(transit)
+	defer server.Close()
+
+	cli := newFakeClient(t, newSecret("kms-token", map[string]string{"token": "s.token"}))
+	volumeEncrypt := &apisv1alpha1.VolumeEncrypt{
+		Enable:      true,
+		Type:        "LUKS",
+		KeyProvider: apisv1alpha1.VolumeEncryptKeyProviderKMS,
+		KMS:         &apisv1alpha1.VolumeEncryptKMS{Address: server.URL + "/", KeyName: "hwameistor", TokenSecret: "hwameistor/kms-token"},
+	}
+
+	provider, err := NewKeyProvider(cli, volumeEncrypt)
+	if err != nil {
+		t.Fatalf("NewKeyProvider() error = %v", err)
+	}
+	keyRef, err := provider.NewKey()
+	if err != nil {
+		t.Fatalf("NewKey() error = %v", err)
+	}
+	if !strings.HasPrefix(keyRef, "vault:v1:") {
+		t.Errorf("NewKey() = %s, want a wrapped key", keyRef)
+	}
+
+	SetKeyRef(volumeEncrypt, keyRef)
+	if volumeEncrypt.KMS.WrappedKey != keyRef || volumeEncrypt.SecretNamespacedName != "" {
+		t.Errorf("SetKeyRef() got %+v", volumeEncrypt)
+	}
+	key, err := GetVolumeKey(cli, volumeEncrypt)
+	if err != nil || key != transit.dataKeys[keyRef] {
+		t.Errorf("GetVolumeKey() = %v, %v, want %s", key, err, transit.dataKeys[keyRef])
+	}
+
+	// a new key for the rotation
+	newKeyRef, err := provider.NewKey()
+	if err != nil || newKeyRef == keyRef {
+		t.Errorf("NewKey() = %v, %v, want another key", newKeyRef, err)
+	}
+
+	if _, err := provider.GetKey("vault:v1:unknown"); err == nil || !strings.Contains(err.Error(), "invalid ciphertext") {
+		t.Errorf("GetKey() error = %v, want invalid ciphertext", err)
+	}
+
+	// wrong token
+	badProvider, err := NewKMSKeyProvider(cli, &apisv1alpha1.VolumeEncryptKMS{Address: server.URL, KeyName: "hwameistor"})
+	if err != nil {
+		t.Fatalf("NewKMSKeyProvider() error = %v", err)
+	}
+	if _, err := badProvider.NewKey(); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("NewKey() error = %v, want permission denied", err)
+	}
+
+	if _, err := NewKeyProvider(cli, &apisv1alpha1.VolumeEncrypt{KeyProvider: apisv1alpha1.VolumeEncryptKeyProviderKMS}); err == nil {
+		t.Errorf("NewKeyProvider() expects an error without kms config")
+	}
+}
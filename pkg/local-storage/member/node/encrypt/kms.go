@@ -0,0 +1,122 @@
+package encrypt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+const (
+	kmsRequestTimeout = 10 * time.Second
+
+	// kmsTokenHeader is the header to send the token of the KMS, as Vault does
+	kmsTokenHeader = "X-Vault-Token"
+)
+
+var _ KeyProvider = &KMSKeyProvider{}
+
+// KMSKeyProvider generates the data key of the volume by a KMS with the Vault transit API,
+// and saves only the data key wrapped by the KMS key, so the plain key never leaves the node or the KMS
+type KMSKeyProvider struct {
+	address    string
+	keyName    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewKMSKeyProvider returns the KMS key provider, the token is read from the "token" of the TokenSecret
+func NewKMSKeyProvider(cli client.Client, config *apisv1alpha1.VolumeEncryptKMS) (*KMSKeyProvider, error) {
+	if config.Address == "" || config.KeyName == "" {
+		return nil, fmt.Errorf("address and keyName of kms are required")
+	}
+
+	provider := &KMSKeyProvider{
+		address:    strings.TrimSuffix(config.Address, "/"),
+		keyName:    config.KeyName,
+		httpClient: &http.Client{Timeout: kmsRequestTimeout},
+	}
+	if config.TokenSecret != "" {
+		token, err := getSecretData(cli, config.TokenSecret, "token")
+		if err != nil {
+			return nil, err
+		}
+		provider.token = token
+	}
+	return provider, nil
+}
+
+// kmsResponse is the response of the Vault transit API
+type kmsResponse struct {
+	Data struct {
+		Plaintext  string `json:"plaintext"`
+		Ciphertext string `json:"ciphertext"`
+	} `json:"data"`
+	Errors []string `json:"errors"`
+}
+
+// GetKey decrypts the wrapped data key by the KMS. The base64 encoded plain key is used as the passphrase
+func (kp *KMSKeyProvider) GetKey(keyRef string) (string, error) {
+	if keyRef == "" {
+		return "", fmt.Errorf("wrapped key is empty")
+	}
+	resp, err := kp.post("decrypt", map[string]string{"ciphertext": keyRef})
+	if err != nil {
+		return "", err
+	}
+	if resp.Data.Plaintext == "" {
+		return "", fmt.Errorf("no plaintext returned by kms")
+	}
+	return resp.Data.Plaintext, nil
+}
+
+// NewKey generates a new data key by the KMS, and returns the wrapped one
+func (kp *KMSKeyProvider) NewKey() (string, error) {
+	resp, err := kp.post("datakey/plaintext", map[string]string{})
+	if err != nil {
+		return "", err
+	}
+	if resp.Data.Ciphertext == "" {
+		return "", fmt.Errorf("no ciphertext returned by kms")
+	}
+	return resp.Data.Ciphertext, nil
+}
+
+func (kp *KMSKeyProvider) post(operation string, body interface{}) (*kmsResponse, error) {
+	content, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := fmt.Sprintf("%s/v1/transit/%s/%s", kp.address, operation, url.PathEscape(kp.keyName))
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if kp.token != "" {
+		req.Header.Set(kmsTokenHeader, kp.token)
+	}
+
+	res, err := kp.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	resp := &kmsResponse{}
+	if err := json.NewDecoder(res.Body).Decode(resp); err != nil && res.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("failed to decode the response of kms: %v", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("kms %s failed with status %d: %s", operation, res.StatusCode, strings.Join(resp.Errors, "; "))
+	}
+	return resp, nil
+}
@@ -1,17 +1,28 @@
 package encrypt
 
 import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
 	"github.com/hwameistor/hwameistor/pkg/exechelper"
 	"github.com/hwameistor/hwameistor/pkg/exechelper/basicexecutor"
 	"github.com/hwameistor/hwameistor/pkg/exechelper/nsexecutor"
 	log "github.com/sirupsen/logrus"
 	"os"
+	"os/exec"
 	"path"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
 var _ Encryptor = &LUKS{}
 
+// LUKSHeaderReserveBytes is the space reserved at the end of the volume for the LUKS2 header
+// when encrypting the volume in place
+const LUKSHeaderReserveBytes int64 = 32 * 1024 * 1024
+
 type LUKS struct {
 	nsCmdExec    exechelper.Executor
 	basicCmdExec exechelper.Executor
@@ -123,6 +134,180 @@ func (lk *LUKS) CloseVolume(volumePath string) error {
 	return nil
 }
 
+func (lk *LUKS) AddKey(volumePath string, existingSecret string, newSecret string) error {
+	existingKey := FileHandler{}
+	if err := existingKey.WriteToFile(existingSecret); err != nil {
+		_ = existingKey.DeleteFile()
+		lk.logger.WithError(err).Error("Failed to write secret to file")
+		return err
+	}
+	defer existingKey.DeleteFile()
+
+	newKey := FileHandler{}
+	if err := newKey.WriteToFile(newSecret); err != nil {
+		_ = newKey.DeleteFile()
+		lk.logger.WithError(err).Error("Failed to write secret to file")
+		return err
+	}
+	defer newKey.DeleteFile()
+
+	addKey := exechelper.ExecParams{
+		CmdName: "cryptsetup",
+		CmdArgs: []string{"-q", "luksAddKey", "--key-file", existingKey.FilePath, volumePath, newKey.FilePath},
+	}
+	res := lk.basicCmdExec.RunCommand(addKey)
+	if res.Error != nil {
+		lk.logger.WithError(res.Error).Error("Failed to add key to encrypted volume")
+		return res.Error
+	}
+
+	return nil
+}
+
+func (lk *LUKS) VerifyKey(volumePath string, secret string) error {
+	fh := FileHandler{}
+	if err := fh.WriteToFile(secret); err != nil {
+		_ = fh.DeleteFile()
+		lk.logger.WithError(err).Error("Failed to write secret to file")
+		return err
+	}
+	defer fh.DeleteFile()
+
+	verifyKey := exechelper.ExecParams{
+		CmdName: "cryptsetup",
+		CmdArgs: []string{"luksOpen", "--test-passphrase", "-d", fh.FilePath, volumePath},
+	}
+	res := lk.basicCmdExec.RunCommand(verifyKey)
+	if res.Error != nil {
+		return fmt.Errorf("failed to unlock volume %s with the key: %s", volumePath, strings.TrimSpace(res.ErrBuf.String()))
+	}
+
+	return nil
+}
+
+func (lk *LUKS) RemoveKey(volumePath string, secret string) error {
+	fh := FileHandler{}
+	if err := fh.WriteToFile(secret); err != nil {
+		_ = fh.DeleteFile()
+		lk.logger.WithError(err).Error("Failed to write secret to file")
+		return err
+	}
+	defer fh.DeleteFile()
+
+	removeKey := exechelper.ExecParams{
+		CmdName: "cryptsetup",
+		CmdArgs: []string{"-q", "luksRemoveKey", volumePath, fh.FilePath},
+	}
+	res := lk.basicCmdExec.RunCommand(removeKey)
+	if res.Error != nil {
+		lk.logger.WithError(res.Error).Error("Failed to remove key from encrypted volume")
+		return res.Error
+	}
+
+	return nil
+}
+
+func (lk *LUKS) InitEncryptVolume(volumePath string, secret string, reduceSize int64) error {
+	lk.logger.WithField("volumePath", volumePath).Debug("Initializing encryption of volume with LUKS2")
+
+	fh := FileHandler{}
+	if err := fh.WriteToFile(secret); err != nil {
+		_ = fh.DeleteFile()
+		lk.logger.WithError(err).Error("Failed to write secret to file")
+		return err
+	}
+	defer fh.DeleteFile()
+
+	// the reencryption is recorded in the LUKS2 header with the data shift resilience,
+	// so it can be resumed safely after the node reboots
+	initEncrypt := exechelper.ExecParams{
+		CmdName: "cryptsetup",
+		CmdArgs: []string{"-q", "reencrypt", "--encrypt", "--init-only", "--type", "luks2", "--resilience", "datashift",
+			"--reduce-device-size", fmt.Sprintf("%dB", reduceSize), "--key-file", fh.FilePath, volumePath},
+	}
+	res := lk.basicCmdExec.RunCommand(initEncrypt)
+	if res.Error != nil {
+		lk.logger.WithError(res.Error).Error("Failed to initialize encryption of volume")
+		return fmt.Errorf("%v: %s", res.Error, strings.TrimSpace(res.ErrBuf.String()))
+	}
+
+	return nil
+}
+
+func (lk *LUKS) ResumeEncryptVolume(ctx context.Context, volumePath string, secret string, progress func(percent float64)) error {
+	lk.logger.WithField("volumePath", volumePath).Debug("Encrypting volume with LUKS2")
+
+	fh := FileHandler{}
+	if err := fh.WriteToFile(secret); err != nil {
+		_ = fh.DeleteFile()
+		lk.logger.WithError(err).Error("Failed to write secret to file")
+		return err
+	}
+	defer fh.DeleteFile()
+
+	// it's done online if the volume is opened, otherwise offline
+	cmd := exec.CommandContext(ctx, "cryptsetup", "-q", "reencrypt", "--resume-only", "--progress-frequency", "5",
+		"--key-file", fh.FilePath, volumePath)
+	// cryptsetup stops at a consistent point on SIGINT
+	cmd.Cancel = func() error { return cmd.Process.Signal(os.Interrupt) }
+	var errBuf bytes.Buffer
+	cmd.Stderr = &errBuf
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Split(scanLinesOrCarriageReturns)
+	for scanner.Scan() {
+		if percent, ok := parseReencryptProgress(scanner.Text()); ok && progress != nil {
+			progress(percent)
+		}
+	}
+	if err := cmd.Wait(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		lk.logger.WithError(err).Error("Failed to encrypt volume")
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(errBuf.String()))
+	}
+
+	return nil
+}
+
+var reencryptProgressRegexp = regexp.MustCompile(`Progress:\s*([0-9.]+)%`)
+
+// parseReencryptProgress parses the progress line of cryptsetup, e.g.
+// "Progress:  45.3%, ETA 00:10,  512 MiB written, speed 100.0 MiB/s"
+func parseReencryptProgress(line string) (float64, bool) {
+	matches := reencryptProgressRegexp.FindStringSubmatch(line)
+	if len(matches) != 2 {
+		return 0, false
+	}
+	percent, err := strconv.ParseFloat(matches[1], 64)
+	if err != nil {
+		return 0, false
+	}
+	return percent, true
+}
+
+// scanLinesOrCarriageReturns splits the output by "\n" or "\r", as the progress is refreshed in place by "\r"
+func scanLinesOrCarriageReturns(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		return i + 1, data[0:i], nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
 type FileHandler struct {
 	FilePath string
 }
@@ -0,0 +1,33 @@
+package encrypt
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+)
+
+func Test_parseReencryptProgress(t *testing.T) {
+	output := "Progress:   0.0%, ETA 00:00,    0 MiB written, speed   0.0 MiB/s\r" +
+		"Progress:  45.3%, ETA 00:10,  512 MiB written, speed 100.0 MiB/s\r" +
+		"Progress: 100.0%, ETA 00:00, 1024 MiB written, speed 110.2 MiB/s\n" +
+		"Finished, time 00:09.512, 1024 MiB written, speed 107.6 MiB/s\n"
+
+	var got []float64
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Split(scanLinesOrCarriageReturns)
+	for scanner.Scan() {
+		if percent, ok := parseReencryptProgress(scanner.Text()); ok {
+			got = append(got, percent)
+		}
+	}
+
+	want := []float64{0, 45.3, 100}
+	if len(got) != len(want) {
+		t.Fatalf("parseReencryptProgress() got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("parseReencryptProgress() got %v, want %v", got, want)
+		}
+	}
+}
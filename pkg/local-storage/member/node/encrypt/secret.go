@@ -0,0 +1,50 @@
+package encrypt
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ KeyProvider = &SecretKeyProvider{}
+
+// SecretKeyProvider holds the key in the "key" of a Kubernetes Secret, referenced by the namespaced name of the Secret
+type SecretKeyProvider struct {
+	apiClient client.Client
+}
+
+func NewSecretKeyProvider(cli client.Client) *SecretKeyProvider {
+	return &SecretKeyProvider{apiClient: cli}
+}
+
+func (sp *SecretKeyProvider) GetKey(keyRef string) (string, error) {
+	return getSecretData(sp.apiClient, keyRef, "key")
+}
+
+// NewKey is not supported, the new key should be created as a Secret by the user
+func (sp *SecretKeyProvider) NewKey() (string, error) {
+	return "", fmt.Errorf("new key should be provided by a Secret")
+}
+
+func getSecretData(cli client.Client, namespacedName string, dataKey string) (string, error) {
+	secret := &v1.Secret{}
+	ss := strings.Split(namespacedName, "/")
+	if len(ss) != 2 {
+		return "", fmt.Errorf("invalid secret namespaced name %s", namespacedName)
+	}
+
+	key := client.ObjectKey{Name: ss[1], Namespace: ss[0]}
+	err := cli.Get(context.Background(), key, secret)
+	if err != nil {
+		return "", err
+	}
+
+	if secret.Data == nil || secret.Data[dataKey] == nil {
+		return "", fmt.Errorf("%s is not exist in %s", dataKey, namespacedName)
+	}
+
+	return string(secret.Data[dataKey]), nil
+}
@@ -0,0 +1,129 @@
+package filesystem
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hwameistor/hwameistor/pkg/exechelper"
+	"github.com/hwameistor/hwameistor/pkg/exechelper/nsexecutor"
+)
+
+var (
+	minimumSizeRegex = regexp.MustCompile(`Estimated minimum size of the filesystem:\s*(\d+)`)
+	blockSizeRegex   = regexp.MustCompile(`(?m)^Block size:\s*(\d+)`)
+)
+
+// Shrinker checks and shrinks the filesystem on an unmounted device
+type Shrinker interface {
+	// GetFSType returns the type of the filesystem on the device, empty if not formatted
+	GetFSType(devPath string) (string, error)
+
+	// Check checks and repairs the filesystem, which is required before shrinking it
+	Check(devPath string) error
+
+	// MinimumSizeBytes returns the estimated minimum size the filesystem can be shrunk to
+	MinimumSizeBytes(devPath string) (int64, error)
+
+	// Shrink shrinks the filesystem to the size
+	Shrink(devPath string, sizeBytes int64) error
+}
+
+// IsShrinkable returns true if the filesystem can be shrunk. ext2/3/4 are shrunk by resize2fs offline,
+// while xfs can only grow
+func IsShrinkable(fsType string) bool {
+	return fsType == "ext2" || fsType == "ext3" || fsType == "ext4"
+}
+
+type extShrinker struct {
+	cmdExec exechelper.Executor
+}
+
+// NewShrinker creates a Shrinker for the ext filesystems by e2fsprogs on the host
+func NewShrinker() Shrinker {
+	return &extShrinker{cmdExec: nsexecutor.New()}
+}
+
+func (s *extShrinker) GetFSType(devPath string) (string, error) {
+	res := s.cmdExec.RunCommand(exechelper.ExecParams{
+		CmdName: "blkid",
+		CmdArgs: []string{"-o", "value", "-s", "TYPE", devPath},
+	})
+	// blkid exits with 2 if no filesystem is found on the device
+	if res.ExitCode == 2 {
+		return "", nil
+	}
+	if res.ExitCode != 0 {
+		return "", fmt.Errorf("blkid %s: %v, %s", devPath, res.Error, res.ErrBuf.String())
+	}
+	return strings.TrimSpace(res.OutBuf.String()), nil
+}
+
+func (s *extShrinker) Check(devPath string) error {
+	res := s.cmdExec.RunCommand(exechelper.ExecParams{
+		CmdName: "e2fsck",
+		CmdArgs: []string{"-f", "-y", devPath},
+	})
+	// exit code 1 means the errors are corrected
+	if res.ExitCode != 0 && res.ExitCode != 1 {
+		return fmt.Errorf("e2fsck %s: exit %d, %s", devPath, res.ExitCode, res.ErrBuf.String())
+	}
+	return nil
+}
+
+func (s *extShrinker) MinimumSizeBytes(devPath string) (int64, error) {
+	res := s.cmdExec.RunCommand(exechelper.ExecParams{
+		CmdName: "resize2fs",
+		CmdArgs: []string{"-P", devPath},
+	})
+	if res.ExitCode != 0 {
+		return 0, fmt.Errorf("resize2fs -P %s: %v, %s", devPath, res.Error, res.ErrBuf.String())
+	}
+	blocks, err := parseMinimumBlocks(res.OutBuf.String())
+	if err != nil {
+		return 0, err
+	}
+
+	res = s.cmdExec.RunCommand(exechelper.ExecParams{
+		CmdName: "dumpe2fs",
+		CmdArgs: []string{"-h", devPath},
+	})
+	if res.ExitCode != 0 {
+		return 0, fmt.Errorf("dumpe2fs -h %s: %v, %s", devPath, res.Error, res.ErrBuf.String())
+	}
+	blockSize, err := parseBlockSize(res.OutBuf.String())
+	if err != nil {
+		return 0, err
+	}
+	return blocks * blockSize, nil
+}
+
+func (s *extShrinker) Shrink(devPath string, sizeBytes int64) error {
+	res := s.cmdExec.RunCommand(exechelper.ExecParams{
+		CmdName: "resize2fs",
+		CmdArgs: []string{devPath, fmt.Sprintf("%dK", sizeBytes/1024)},
+	})
+	if res.ExitCode != 0 {
+		return fmt.Errorf("resize2fs %s: %v, %s", devPath, res.Error, res.ErrBuf.String())
+	}
+	return nil
+}
+
+// parseMinimumBlocks parses the output of resize2fs -P, e.g. "Estimated minimum size of the filesystem: 123456"
+func parseMinimumBlocks(output string) (int64, error) {
+	matches := minimumSizeRegex.FindStringSubmatch(output)
+	if len(matches) != 2 {
+		return 0, fmt.Errorf("not found minimum size in %s", output)
+	}
+	return strconv.ParseInt(matches[1], 10, 64)
+}
+
+// parseBlockSize parses the block size from the superblock dumped by dumpe2fs -h
+func parseBlockSize(output string) (int64, error) {
+	matches := blockSizeRegex.FindStringSubmatch(output)
+	if len(matches) != 2 {
+		return 0, fmt.Errorf("not found block size in %s", output)
+	}
+	return strconv.ParseInt(matches[1], 10, 64)
+}
@@ -0,0 +1,39 @@
+package filesystem
+
+import "testing"
+
+func Test_parseMinimumBlocks(t *testing.T) {
+	got, err := parseMinimumBlocks("resize2fs 1.46.5 (30-Dec-2021)\nEstimated minimum size of the filesystem: 142381\n")
+	if err != nil || got != 142381 {
+		t.Errorf("parseMinimumBlocks() = %d, %v", got, err)
+	}
+	if _, err := parseMinimumBlocks("resize2fs: Bad magic number in super-block"); err == nil {
+		t.Error("parseMinimumBlocks() expected error")
+	}
+}
+
+func Test_parseBlockSize(t *testing.T) {
+	output := `Filesystem volume name:   <none>
+Block count:              2621440
+Reserved block count:     131072
+Free blocks:              2541139
+First block:              0
+Block size:               4096
+Fragment size:            4096
+`
+	got, err := parseBlockSize(output)
+	if err != nil || got != 4096 {
+		t.Errorf("parseBlockSize() = %d, %v", got, err)
+	}
+	if _, err := parseBlockSize("Block count: 2621440"); err == nil {
+		t.Error("parseBlockSize() expected error")
+	}
+}
+
+func TestIsShrinkable(t *testing.T) {
+	for fsType, want := range map[string]bool{"ext4": true, "ext3": true, "xfs": false, "": false} {
+		if got := IsShrinkable(fsType); got != want {
+			t.Errorf("IsShrinkable(%s) = %v, want %v", fsType, got, want)
+		}
+	}
+}
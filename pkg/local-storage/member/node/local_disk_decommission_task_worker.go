@@ -0,0 +1,429 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/storage"
+)
+
+const (
+	// interval to check the state of the migrations created by the decommission
+	diskDecommissionCheckInterval = 30 * time.Second
+)
+
+func (m *manager) startLocalDiskDecommissionTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("LocalDiskDecommission Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.localDiskDecommissionTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the LocalDiskDecommission worker")
+				break
+			}
+			if err := m.processLocalDiskDecommission(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.localDiskDecommissionTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process LocalDiskDecommission task, retry later")
+				m.localDiskDecommissionTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a LocalDiskDecommission task.")
+				m.localDiskDecommissionTaskQueue.Forget(task)
+			}
+			m.localDiskDecommissionTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.localDiskDecommissionTaskQueue.Shutdown()
+}
+
+func (m *manager) processLocalDiskDecommission(name string) error {
+	logCtx := m.logger.WithFields(log.Fields{"LocalDiskDecommission": name})
+	logCtx.Debug("Working on a LocalDiskDecommission task")
+
+	decommission := &apisv1alpha1.LocalDiskDecommission{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: name}, decommission); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get LocalDiskDecommission from cache")
+			return err
+		}
+		logCtx.Info("Not found the LocalDiskDecommission from cache, should be deleted already")
+		return nil
+	}
+	if decommission.Spec.NodeName != m.name {
+		return nil
+	}
+
+	if decommission.Spec.Abort &&
+		decommission.Status.State != apisv1alpha1.OperationStateCompleted &&
+		decommission.Status.State != apisv1alpha1.OperationStateAborted {
+		return m.localDiskDecommissionAbort(decommission)
+	}
+
+	switch decommission.Status.State {
+	case "":
+		return m.updateLocalDiskDecommissionState(decommission, apisv1alpha1.OperationStateSubmitted, "")
+	case apisv1alpha1.OperationStateSubmitted:
+		return m.localDiskDecommissionSubmit(decommission)
+	case apisv1alpha1.OperationStateDecommissionEvacuate:
+		return m.localDiskDecommissionEvacuate(decommission)
+	case apisv1alpha1.OperationStateDecommissionMigrate:
+		return m.localDiskDecommissionMigrate(decommission)
+	case apisv1alpha1.OperationStateDecommissionRemove:
+		return m.localDiskDecommissionRemove(decommission)
+	case apisv1alpha1.OperationStateCompleted, apisv1alpha1.OperationStateFailed, apisv1alpha1.OperationStateAborted:
+		return nil
+	default:
+		logCtx.Error("Invalid LocalDiskDecommission state")
+	}
+	return nil
+}
+
+func (m *manager) localDiskDecommissionSubmit(decommission *apisv1alpha1.LocalDiskDecommission) error {
+	logCtx := m.logger.WithFields(log.Fields{"LocalDiskDecommission": decommission.Name, "disk": decommission.Spec.DiskName})
+	logCtx.Debug("Submit a LocalDiskDecommission")
+
+	localDisk := &apisv1alpha1.LocalDisk{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: decommission.Spec.DiskName}, localDisk); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get LocalDisk")
+			return err
+		}
+		return m.updateLocalDiskDecommissionState(decommission, apisv1alpha1.OperationStateFailed, "Not found the disk")
+	}
+	if localDisk.Spec.NodeName != m.name {
+		return m.updateLocalDiskDecommissionState(decommission, apisv1alpha1.OperationStateFailed,
+			fmt.Sprintf("The disk is attached to node %s", localDisk.Spec.NodeName))
+	}
+	decommission.Status.DevicePath = localDisk.Spec.DevicePath
+	decommission.Status.PoolName = m.poolOfDisk(localDisk.Spec.DevicePath)
+
+	// the disk is not in any pool, just release it
+	if len(decommission.Status.PoolName) == 0 {
+		return m.updateLocalDiskDecommissionState(decommission, apisv1alpha1.OperationStateDecommissionRemove, "")
+	}
+
+	usage, err := m.Storage().PoolManager().GetPhysicalVolumeUsage(decommission.Status.PoolName, decommission.Status.DevicePath)
+	if err == storage.ErrorDiskDecommissionNotSupported {
+		return m.updateLocalDiskDecommissionState(decommission, apisv1alpha1.OperationStateFailed, err.Error())
+	}
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get the usage of the disk")
+		return err
+	}
+	logCtx.WithFields(log.Fields{"used": usage.UsedBytes, "freeOnOthers": usage.FreeBytesOnOthers, "replicas": usage.Replicas, "movable": usage.Movable}).Info("Got the usage of the disk")
+
+	nextState := localDiskDecommissionNextState(usage)
+	if nextState == apisv1alpha1.OperationStateFailed {
+		return m.updateLocalDiskDecommissionState(decommission, nextState,
+			"Insufficient capacity to move the data left on the disk, e.g. the thin pool, to the other disks")
+	}
+	// no more data is allocated on the disk while it's being evacuated
+	if err := m.setDecommissionedDiskAllocatable(decommission, false); err != nil {
+		logCtx.WithError(err).Error("Failed to mark the disk non-allocatable")
+		return err
+	}
+	if nextState != apisv1alpha1.OperationStateDecommissionMigrate {
+		return m.updateLocalDiskDecommissionState(decommission, nextState, "")
+	}
+
+	// the volumes have been migrated, but there is still data left on the disk
+	if len(decommission.Status.Migrations) > 0 {
+		return m.updateLocalDiskDecommissionState(decommission, apisv1alpha1.OperationStateFailed,
+			fmt.Sprintf("Data of %s is still left on the disk after migration", strings.Join(usage.Replicas, ",")))
+	}
+	migrations, err := m.createLocalDiskDecommissionMigrations(decommission, usage.Replicas)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to create the migrations")
+		return err
+	}
+	if len(migrations) == 0 {
+		return m.updateLocalDiskDecommissionState(decommission, apisv1alpha1.OperationStateFailed,
+			fmt.Sprintf("Insufficient capacity to move data of %s to the other disks", strings.Join(usage.Replicas, ",")))
+	}
+	decommission.Status.Migrations = migrations
+	return m.updateLocalDiskDecommissionState(decommission, apisv1alpha1.OperationStateDecommissionMigrate, "")
+}
+
+// localDiskDecommissionNextState moves the data to the other disks in the same pool if they have enough space,
+// otherwise migrates the volumes to the other nodes
+func localDiskDecommissionNextState(usage *storage.PhysicalVolumeUsage) apisv1alpha1.State {
+	if usage.UsedBytes == 0 {
+		return apisv1alpha1.OperationStateDecommissionRemove
+	}
+	if usage.Movable && usage.FreeBytesOnOthers >= usage.UsedBytes {
+		return apisv1alpha1.OperationStateDecommissionEvacuate
+	}
+	if len(usage.Replicas) > 0 {
+		return apisv1alpha1.OperationStateDecommissionMigrate
+	}
+	// no volume on the disk, but the data can't be moved out, e.g. an empty thin pool on the final disk
+	return apisv1alpha1.OperationStateFailed
+}
+
+// createLocalDiskDecommissionMigrations creates a LocalVolumeMigrate for each volume (group) on the disk
+func (m *manager) createLocalDiskDecommissionMigrations(decommission *apisv1alpha1.LocalDiskDecommission, replicas []string) ([]string, error) {
+	return m.createVolumeMigrations(decommission.Name, replicas)
+}
+
+// createVolumeMigrations creates a LocalVolumeMigrate named with the prefix for each volume (group) of the replicas,
+// to move them out of this node
+func (m *manager) createVolumeMigrations(prefix string, replicas []string) ([]string, error) {
+	migrations := []string{}
+	groups := map[string]bool{}
+	for _, volName := range replicas {
+		vol := &apisv1alpha1.LocalVolume{}
+		if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: volName}, vol); err != nil {
+			if errors.IsNotFound(err) {
+				// not a volume, e.g. a snapshot
+				continue
+			}
+			return nil, err
+		}
+		// all the volumes in the group are migrated together
+		if len(vol.Spec.VolumeGroup) > 0 {
+			if groups[vol.Spec.VolumeGroup] {
+				continue
+			}
+			groups[vol.Spec.VolumeGroup] = true
+		}
+
+		migrate := &apisv1alpha1.LocalVolumeMigrate{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: fmt.Sprintf("%s-%s", prefix, volName),
+			},
+			Spec: apisv1alpha1.LocalVolumeMigrateSpec{
+				VolumeName:           volName,
+				SourceNode:           m.name,
+				TargetNodesSuggested: []string{},
+				MigrateAllVols:       true,
+			},
+		}
+		if err := m.apiClient.Create(context.TODO(), migrate); err != nil && !errors.IsAlreadyExists(err) {
+			return nil, err
+		}
+		migrations = append(migrations, migrate.Name)
+	}
+	return migrations, nil
+}
+
+func (m *manager) localDiskDecommissionEvacuate(decommission *apisv1alpha1.LocalDiskDecommission) error {
+	logCtx := m.logger.WithFields(log.Fields{"LocalDiskDecommission": decommission.Name, "disk": decommission.Status.DevicePath})
+	logCtx.Debug("Checking the move of the data to the other disks in the pool")
+
+	poolManager := m.Storage().PoolManager()
+	evacuation, err := poolManager.GetPhysicalVolumeEvacuation(decommission.Status.PoolName, decommission.Status.DevicePath)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get the progress of moving the data")
+		return err
+	}
+	if evacuation.InProgress {
+		message := fmt.Sprintf("Moving the data to the other disks, %s%% done", evacuation.Percent)
+		if decommission.Status.Message != message {
+			decommission.Status.Message = message
+			if err := m.apiClient.Status().Update(context.TODO(), decommission); err != nil {
+				return err
+			}
+		}
+		m.localDiskDecommissionTaskQueue.AddAfter(decommission.Name, diskDecommissionCheckInterval)
+		return nil
+	}
+
+	usage, err := poolManager.GetPhysicalVolumeUsage(decommission.Status.PoolName, decommission.Status.DevicePath)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get the usage of the disk")
+		return err
+	}
+	if usage.UsedBytes == 0 {
+		return m.updateLocalDiskDecommissionState(decommission, apisv1alpha1.OperationStateDecommissionRemove, "")
+	}
+
+	// start the move, or resume it if it's interrupted
+	logCtx.WithField("used", usage.UsedBytes).Info("Moving the data to the other disks in the pool")
+	if err := poolManager.EvacuatePhysicalVolume(decommission.Status.PoolName, decommission.Status.DevicePath); err != nil {
+		logCtx.WithError(err).Error("Failed to move the data out of the disk")
+		decommission.Status.Message = err.Error()
+		m.apiClient.Status().Update(context.TODO(), decommission)
+		return err
+	}
+	m.localDiskDecommissionTaskQueue.AddAfter(decommission.Name, diskDecommissionCheckInterval)
+	return nil
+}
+
+func (m *manager) localDiskDecommissionMigrate(decommission *apisv1alpha1.LocalDiskDecommission) error {
+	logCtx := m.logger.WithFields(log.Fields{"LocalDiskDecommission": decommission.Name, "disk": decommission.Status.DevicePath})
+	logCtx.Debug("Checking the migrations of the volumes on the disk")
+
+	waiting := []string{}
+	for _, name := range decommission.Status.Migrations {
+		migrate := &apisv1alpha1.LocalVolumeMigrate{}
+		if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: name}, migrate); err != nil {
+			if errors.IsNotFound(err) {
+				// deleted by the user, the disk will be checked again
+				continue
+			}
+			logCtx.WithError(err).Error("Failed to get LocalVolumeMigrate")
+			return err
+		}
+		switch migrate.Status.State {
+		case apisv1alpha1.OperationStateCompleted:
+		case apisv1alpha1.OperationStateFailed, apisv1alpha1.OperationStateAborted:
+			return m.updateLocalDiskDecommissionState(decommission, apisv1alpha1.OperationStateFailed,
+				fmt.Sprintf("Migration %s is %s", name, migrate.Status.State))
+		default:
+			waiting = append(waiting, name)
+		}
+	}
+
+	if len(waiting) > 0 {
+		message := fmt.Sprintf("Waiting for the migrations %s", strings.Join(waiting, ","))
+		if decommission.Status.Message != message {
+			decommission.Status.Message = message
+			if err := m.apiClient.Status().Update(context.TODO(), decommission); err != nil {
+				return err
+			}
+		}
+		m.localDiskDecommissionTaskQueue.AddAfter(decommission.Name, diskDecommissionCheckInterval)
+		return nil
+	}
+
+	// check the disk again, the remaining data (if any) is moved by pvmove
+	if err := m.Storage().Registry().SyncNodeResources(); err != nil {
+		logCtx.WithError(err).Error("Failed to SyncNodeResources")
+		return err
+	}
+	return m.updateLocalDiskDecommissionState(decommission, apisv1alpha1.OperationStateSubmitted, "")
+}
+
+func (m *manager) localDiskDecommissionRemove(decommission *apisv1alpha1.LocalDiskDecommission) error {
+	logCtx := m.logger.WithFields(log.Fields{"LocalDiskDecommission": decommission.Name, "disk": decommission.Status.DevicePath})
+	logCtx.Info("Removing the disk from the pool")
+
+	if len(decommission.Status.PoolName) > 0 {
+		if err := m.Storage().PoolManager().RemovePhysicalVolume(decommission.Status.PoolName, decommission.Status.DevicePath); err != nil {
+			logCtx.WithError(err).Error("Failed to remove the disk from the pool")
+			if err == storage.ErrorPhysicalVolumeInUse {
+				return m.updateLocalDiskDecommissionState(decommission, apisv1alpha1.OperationStateFailed, err.Error())
+			}
+			decommission.Status.Message = err.Error()
+			m.apiClient.Status().Update(context.TODO(), decommission)
+			return err
+		}
+		if err := m.Storage().Registry().SyncNodeResources(); err != nil {
+			logCtx.WithError(err).Error("Failed to SyncNodeResources")
+			return err
+		}
+	}
+
+	if err := m.releaseDecommissionedDisk(decommission); err != nil {
+		logCtx.WithError(err).Error("Failed to release the disk")
+		return err
+	}
+	return m.updateLocalDiskDecommissionState(decommission, apisv1alpha1.OperationStateCompleted, "")
+}
+
+// releaseDecommissionedDisk releases the disk to be Available, or reserves it for replacement
+func (m *manager) releaseDecommissionedDisk(decommission *apisv1alpha1.LocalDiskDecommission) error {
+	localDisk := &apisv1alpha1.LocalDisk{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: decommission.Spec.DiskName}, localDisk); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	oldDisk := localDisk.DeepCopy()
+	localDisk.Spec.ClaimRef = nil
+	localDisk.Spec.Owner = ""
+	if decommission.Spec.Replace {
+		localDisk.Spec.Reserved = true
+	}
+	return m.apiClient.Patch(context.TODO(), localDisk, client.MergeFrom(oldDisk))
+}
+
+func (m *manager) localDiskDecommissionAbort(decommission *apisv1alpha1.LocalDiskDecommission) error {
+	logCtx := m.logger.WithFields(log.Fields{"LocalDiskDecommission": decommission.Name})
+	logCtx.Info("Aborting the LocalDiskDecommission")
+
+	// the data moved already is left on the other disks
+	if decommission.Status.State == apisv1alpha1.OperationStateDecommissionEvacuate {
+		if err := m.Storage().PoolManager().AbortPhysicalVolumeEvacuation(decommission.Status.PoolName, decommission.Status.DevicePath); err != nil {
+			logCtx.WithError(err).Error("Failed to abort moving the data out of the disk")
+			return err
+		}
+	}
+
+	for _, name := range decommission.Status.Migrations {
+		migrate := &apisv1alpha1.LocalVolumeMigrate{}
+		if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: name}, migrate); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if migrate.Spec.Abort || migrate.Status.State == apisv1alpha1.OperationStateCompleted {
+			continue
+		}
+		migrate.Spec.Abort = true
+		if err := m.apiClient.Update(context.TODO(), migrate); err != nil {
+			logCtx.WithField("LocalVolumeMigrate", name).WithError(err).Error("Failed to abort the migration")
+			return err
+		}
+	}
+
+	if err := m.setDecommissionedDiskAllocatable(decommission, true); err != nil {
+		logCtx.WithError(err).Error("Failed to mark the disk allocatable again")
+		return err
+	}
+	return m.updateLocalDiskDecommissionState(decommission, apisv1alpha1.OperationStateAborted, "")
+}
+
+// setDecommissionedDiskAllocatable changes the allocation of the disk if it's still in the pool.
+// The disk cordoned for its health is not made allocatable
+func (m *manager) setDecommissionedDiskAllocatable(decommission *apisv1alpha1.LocalDiskDecommission, allocatable bool) error {
+	if len(decommission.Status.PoolName) == 0 || m.poolOfDisk(decommission.Status.DevicePath) != decommission.Status.PoolName {
+		return nil
+	}
+	if allocatable {
+		localDisk := &apisv1alpha1.LocalDisk{}
+		if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: decommission.Spec.DiskName}, localDisk); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		if localDisk.Status.Health != nil && localDisk.Status.Health.Cordoned {
+			return nil
+		}
+	}
+
+	if err := m.Storage().PoolManager().SetPhysicalVolumeAllocatable(decommission.Status.PoolName, decommission.Status.DevicePath, allocatable); err != nil {
+		return err
+	}
+	// the free capacity of the pool is changed
+	return m.Storage().Registry().SyncNodeResources()
+}
+
+// poolOfDisk returns the storage pool which the disk belongs to
+func (m *manager) poolOfDisk(devPath string) string {
+	for _, pool := range m.Storage().Registry().Pools() {
+		for _, disk := range pool.Disks {
+			if disk.DevPath == devPath {
+				return pool.Name
+			}
+		}
+	}
+	return ""
+}
+
+func (m *manager) updateLocalDiskDecommissionState(decommission *apisv1alpha1.LocalDiskDecommission, state apisv1alpha1.State, message string) error {
+	m.logger.WithFields(log.Fields{"LocalDiskDecommission": decommission.Name, "state": state, "message": message}).Debug("Updating the state of LocalDiskDecommission")
+	decommission.Status.State = state
+	decommission.Status.Message = message
+	return m.apiClient.Status().Update(context.TODO(), decommission)
+}
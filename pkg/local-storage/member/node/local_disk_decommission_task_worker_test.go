@@ -0,0 +1,189 @@
+package node
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/storage"
+)
+
+func Test_localDiskDecommissionNextState(t *testing.T) {
+	tests := []struct {
+		name  string
+		usage *storage.PhysicalVolumeUsage
+		want  apisv1alpha1.State
+	}{
+		{
+			name:  "empty disk",
+			usage: &storage.PhysicalVolumeUsage{Movable: true},
+			want:  apisv1alpha1.OperationStateDecommissionRemove,
+		},
+		{
+			name:  "enough space on the other disks",
+			usage: &storage.PhysicalVolumeUsage{UsedBytes: 100, FreeBytesOnOthers: 100, Replicas: []string{"pvc-1"}, Movable: true},
+			want:  apisv1alpha1.OperationStateDecommissionEvacuate,
+		},
+		{
+			name:  "insufficient space on the other disks",
+			usage: &storage.PhysicalVolumeUsage{UsedBytes: 100, FreeBytesOnOthers: 99, Replicas: []string{"pvc-1"}, Movable: true},
+			want:  apisv1alpha1.OperationStateDecommissionMigrate,
+		},
+		{
+			name:  "mirrored volume",
+			usage: &storage.PhysicalVolumeUsage{UsedBytes: 100, FreeBytesOnOthers: 1000, Replicas: []string{"pvc-1"}, Movable: false},
+			want:  apisv1alpha1.OperationStateDecommissionMigrate,
+		},
+		{
+			name:  "no volume on the final disk",
+			usage: &storage.PhysicalVolumeUsage{UsedBytes: 100, Movable: true},
+			want:  apisv1alpha1.OperationStateFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := localDiskDecommissionNextState(tt.usage); got != tt.want {
+				t.Errorf("localDiskDecommissionNextState() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newFakeDecommissionManager(t *testing.T, objs ...runtime.Object) *manager {
+	s := runtime.NewScheme()
+	if err := apisv1alpha1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	return &manager{
+		name:      fakeNodename,
+		apiClient: fake.NewClientBuilder().WithScheme(s).WithRuntimeObjects(objs...).Build(),
+		logger:    log.WithField("Module", "NodeManager"),
+	}
+}
+
+func Test_manager_createLocalDiskDecommissionMigrations(t *testing.T) {
+	decommission := &apisv1alpha1.LocalDiskDecommission{ObjectMeta: metav1.ObjectMeta{Name: "decom"}}
+	m := newFakeDecommissionManager(t,
+		&apisv1alpha1.LocalVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"}, Spec: apisv1alpha1.LocalVolumeSpec{VolumeGroup: "lvg-1"}},
+		&apisv1alpha1.LocalVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-2"}, Spec: apisv1alpha1.LocalVolumeSpec{VolumeGroup: "lvg-1"}},
+		&apisv1alpha1.LocalVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-3"}, Spec: apisv1alpha1.LocalVolumeSpec{VolumeGroup: "lvg-2"}},
+	)
+
+	migrations, err := m.createLocalDiskDecommissionMigrations(decommission, []string{"pvc-1", "pvc-2", "pvc-3", "snapcontent-1"})
+	if err != nil {
+		t.Fatalf("createLocalDiskDecommissionMigrations() error = %v", err)
+	}
+	want := []string{"decom-pvc-1", "decom-pvc-3"}
+	if !reflect.DeepEqual(migrations, want) {
+		t.Errorf("createLocalDiskDecommissionMigrations() = %v, want %v", migrations, want)
+	}
+
+	migrate := &apisv1alpha1.LocalVolumeMigrate{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: "decom-pvc-3"}, migrate); err != nil {
+		t.Fatalf("Failed to get LocalVolumeMigrate: %v", err)
+	}
+	if migrate.Spec.SourceNode != fakeNodename || migrate.Spec.VolumeName != "pvc-3" || !migrate.Spec.MigrateAllVols {
+		t.Errorf("Unexpected LocalVolumeMigrate spec %+v", migrate.Spec)
+	}
+
+	// created already
+	if _, err := m.createLocalDiskDecommissionMigrations(decommission, []string{"pvc-3"}); err != nil {
+		t.Errorf("createLocalDiskDecommissionMigrations() error = %v", err)
+	}
+}
+
+func Test_manager_releaseDecommissionedDisk(t *testing.T) {
+	tests := []struct {
+		name         string
+		replace      bool
+		wantReserved bool
+	}{
+		{name: "release", replace: false, wantReserved: false},
+		{name: "replace", replace: true, wantReserved: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			disk := &apisv1alpha1.LocalDisk{
+				ObjectMeta: metav1.ObjectMeta{Name: "disk-1"},
+				Spec: apisv1alpha1.LocalDiskSpec{
+					NodeName:   fakeNodename,
+					DevicePath: "/dev/sdb",
+					Owner:      "local-storage",
+					ClaimRef:   &v1.ObjectReference{Name: "claim-1"},
+				},
+			}
+			m := newFakeDecommissionManager(t, disk)
+			decommission := &apisv1alpha1.LocalDiskDecommission{
+				ObjectMeta: metav1.ObjectMeta{Name: "decom"},
+				Spec:       apisv1alpha1.LocalDiskDecommissionSpec{NodeName: fakeNodename, DiskName: "disk-1", Replace: tt.replace},
+			}
+			if err := m.releaseDecommissionedDisk(decommission); err != nil {
+				t.Fatalf("releaseDecommissionedDisk() error = %v", err)
+			}
+
+			got := &apisv1alpha1.LocalDisk{}
+			if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: "disk-1"}, got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Spec.ClaimRef != nil || got.Spec.Owner != "" || got.Spec.Reserved != tt.wantReserved {
+				t.Errorf("Unexpected LocalDisk spec %+v", got.Spec)
+			}
+		})
+	}
+}
+
+func Test_manager_localDiskDecommissionAbort(t *testing.T) {
+	decommission := &apisv1alpha1.LocalDiskDecommission{
+		ObjectMeta: metav1.ObjectMeta{Name: "decom"},
+		Spec:       apisv1alpha1.LocalDiskDecommissionSpec{NodeName: fakeNodename, DiskName: "disk-1", Abort: true},
+		Status: apisv1alpha1.LocalDiskDecommissionStatus{
+			State:      apisv1alpha1.OperationStateDecommissionMigrate,
+			Migrations: []string{"decom-pvc-1", "decom-pvc-2", "decom-pvc-3"},
+		},
+	}
+	m := newFakeDecommissionManager(t,
+		decommission,
+		&apisv1alpha1.LocalVolumeMigrate{
+			ObjectMeta: metav1.ObjectMeta{Name: "decom-pvc-1"},
+			Status:     apisv1alpha1.LocalVolumeMigrateStatus{State: apisv1alpha1.OperationStateMigrateSyncReplica},
+		},
+		&apisv1alpha1.LocalVolumeMigrate{
+			ObjectMeta: metav1.ObjectMeta{Name: "decom-pvc-2"},
+			Status:     apisv1alpha1.LocalVolumeMigrateStatus{State: apisv1alpha1.OperationStateCompleted},
+		},
+	)
+
+	if err := m.localDiskDecommissionAbort(decommission); err != nil {
+		t.Fatalf("localDiskDecommissionAbort() error = %v", err)
+	}
+
+	migrate := &apisv1alpha1.LocalVolumeMigrate{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: "decom-pvc-1"}, migrate); err != nil {
+		t.Fatal(err)
+	}
+	if !migrate.Spec.Abort {
+		t.Errorf("The running migration is not aborted")
+	}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: "decom-pvc-2"}, migrate); err != nil {
+		t.Fatal(err)
+	}
+	if migrate.Spec.Abort {
+		t.Errorf("The completed migration should not be aborted")
+	}
+
+	got := &apisv1alpha1.LocalDiskDecommission{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: "decom"}, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.State != apisv1alpha1.OperationStateAborted {
+		t.Errorf("Got state %v, want %v", got.Status.State, apisv1alpha1.OperationStateAborted)
+	}
+}
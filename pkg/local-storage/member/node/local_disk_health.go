@@ -0,0 +1,122 @@
+package node
+
+import (
+	"context"
+	"reflect"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/storage"
+)
+
+// defaultDiskHealthReaction is used if there is no DiskHealthPolicy, or the reaction is not set by it
+var defaultDiskHealthReaction = apisv1alpha1.DiskHealthReaction{
+	CordonOn:   apisv1alpha1.DiskDegraded,
+	EvacuateOn: apisv1alpha1.DiskFailing,
+}
+
+// reactToDiskHealth marks the unhealthy disk non-allocatable in the pool, and migrates the volumes out of it
+// according to the DiskHealthPolicy. What is done is recorded in the health status of the disk
+func (m *manager) reactToDiskHealth(localDisk *apisv1alpha1.LocalDisk) error {
+	if localDisk.Status.Health == nil {
+		return nil
+	}
+	poolName := m.poolOfDisk(localDisk.Spec.DevicePath)
+	if len(poolName) == 0 {
+		return nil
+	}
+	logCtx := m.logger.WithFields(log.Fields{"localDisk": localDisk.Name, "pool": poolName, "health": localDisk.Status.Health.State})
+
+	reaction, err := m.getDiskHealthReaction()
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get DiskHealthPolicy")
+		return err
+	}
+
+	oldDisk := localDisk.DeepCopy()
+	health := localDisk.Status.Health
+	cordon, evacuate := diskHealthReaction(health.State, reaction)
+
+	if cordon != health.Cordoned {
+		logCtx.WithField("cordon", cordon).Info("Changing the allocation of the disk for its health")
+		err = m.Storage().PoolManager().SetPhysicalVolumeAllocatable(poolName, localDisk.Spec.DevicePath, !cordon)
+		switch {
+		case err == storage.ErrorDiskCordonNotSupported:
+			logCtx.WithError(err).Warning("Can't change the allocation of the disk")
+		case err != nil:
+			logCtx.WithError(err).Error("Failed to change the allocation of the disk")
+			return err
+		default:
+			health.Cordoned = cordon
+			// the free capacity of the pool is changed
+			if err = m.Storage().Registry().SyncNodeResources(); err != nil {
+				logCtx.WithError(err).Error("Failed to SyncNodeResources")
+				return err
+			}
+		}
+	}
+
+	switch {
+	case evacuate && len(health.Migrations) == 0:
+		migrations, err := m.evacuateUnhealthyDisk(localDisk, poolName)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to evacuate the disk")
+			return err
+		}
+		health.Migrations = migrations
+	case !evacuate:
+		// evacuate the disk again if it's unhealthy again
+		health.Migrations = nil
+	}
+
+	if reflect.DeepEqual(oldDisk.Status, localDisk.Status) {
+		return nil
+	}
+	return m.apiClient.Status().Patch(context.TODO(), localDisk, client.MergeFrom(oldDisk))
+}
+
+// evacuateUnhealthyDisk creates the LocalVolumeMigrates for the volumes having data on the disk
+func (m *manager) evacuateUnhealthyDisk(localDisk *apisv1alpha1.LocalDisk, poolName string) ([]string, error) {
+	logCtx := m.logger.WithFields(log.Fields{"localDisk": localDisk.Name, "pool": poolName})
+
+	usage, err := m.Storage().PoolManager().GetPhysicalVolumeUsage(poolName, localDisk.Spec.DevicePath)
+	if err == storage.ErrorDiskDecommissionNotSupported {
+		logCtx.WithError(err).Warning("Can't find out the volumes on the disk")
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	logCtx.WithField("replicas", usage.Replicas).Info("Migrating the volumes out of the unhealthy disk")
+	return m.createVolumeMigrations(localDisk.Name, usage.Replicas)
+}
+
+func (m *manager) getDiskHealthReaction() (apisv1alpha1.DiskHealthReaction, error) {
+	reaction := defaultDiskHealthReaction
+	policy := &apisv1alpha1.DiskHealthPolicy{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: apisv1alpha1.DefaultDiskHealthPolicyName}, policy); err != nil {
+		if errors.IsNotFound(err) {
+			return reaction, nil
+		}
+		return reaction, err
+	}
+	if len(policy.Spec.Reaction.CordonOn) > 0 {
+		reaction.CordonOn = policy.Spec.Reaction.CordonOn
+	}
+	if len(policy.Spec.Reaction.EvacuateOn) > 0 {
+		reaction.EvacuateOn = policy.Spec.Reaction.EvacuateOn
+	}
+	return reaction, nil
+}
+
+// diskHealthReaction returns whether the disk should be non-allocatable, and whether its volumes should be migrated.
+// A disk evacuated is also non-allocatable, so that the volumes are not allocated on it again
+func diskHealthReaction(state apisv1alpha1.DiskHealthState, reaction apisv1alpha1.DiskHealthReaction) (bool, bool) {
+	evacuate := state.Reaches(reaction.EvacuateOn)
+	return evacuate || state.Reaches(reaction.CordonOn), evacuate
+}
@@ -0,0 +1,84 @@
+package node
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+func Test_diskHealthReaction(t *testing.T) {
+	tests := []struct {
+		name         string
+		state        apisv1alpha1.DiskHealthState
+		reaction     apisv1alpha1.DiskHealthReaction
+		wantCordon   bool
+		wantEvacuate bool
+	}{
+		{
+			name:     "healthy",
+			state:    apisv1alpha1.DiskHealthy,
+			reaction: defaultDiskHealthReaction,
+		},
+		{
+			name:       "degraded",
+			state:      apisv1alpha1.DiskDegraded,
+			reaction:   defaultDiskHealthReaction,
+			wantCordon: true,
+		},
+		{
+			name:         "failing",
+			state:        apisv1alpha1.DiskFailing,
+			reaction:     defaultDiskHealthReaction,
+			wantCordon:   true,
+			wantEvacuate: true,
+		},
+		{
+			name:         "evacuate without cordon",
+			state:        apisv1alpha1.DiskDegraded,
+			reaction:     apisv1alpha1.DiskHealthReaction{CordonOn: apisv1alpha1.DiskHealthNever, EvacuateOn: apisv1alpha1.DiskDegraded},
+			wantCordon:   true,
+			wantEvacuate: true,
+		},
+		{
+			name:     "never",
+			state:    apisv1alpha1.DiskFailing,
+			reaction: apisv1alpha1.DiskHealthReaction{CordonOn: apisv1alpha1.DiskHealthNever, EvacuateOn: apisv1alpha1.DiskHealthNever},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cordon, evacuate := diskHealthReaction(tt.state, tt.reaction)
+			if cordon != tt.wantCordon || evacuate != tt.wantEvacuate {
+				t.Errorf("diskHealthReaction() = %v, %v, want %v, %v", cordon, evacuate, tt.wantCordon, tt.wantEvacuate)
+			}
+		})
+	}
+}
+
+func Test_manager_getDiskHealthReaction(t *testing.T) {
+	m := newFakeDecommissionManager(t)
+	reaction, err := m.getDiskHealthReaction()
+	if err != nil {
+		t.Fatalf("getDiskHealthReaction() error = %v", err)
+	}
+	if reaction != defaultDiskHealthReaction {
+		t.Errorf("getDiskHealthReaction() = %+v, want the default %+v", reaction, defaultDiskHealthReaction)
+	}
+
+	m = newFakeDecommissionManager(t, &apisv1alpha1.DiskHealthPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: apisv1alpha1.DefaultDiskHealthPolicyName},
+		Spec: apisv1alpha1.DiskHealthPolicySpec{
+			Reaction: apisv1alpha1.DiskHealthReaction{EvacuateOn: apisv1alpha1.DiskHealthNever},
+		},
+	})
+	reaction, err = m.getDiskHealthReaction()
+	if err != nil {
+		t.Fatalf("getDiskHealthReaction() error = %v", err)
+	}
+	want := apisv1alpha1.DiskHealthReaction{CordonOn: apisv1alpha1.DiskDegraded, EvacuateOn: apisv1alpha1.DiskHealthNever}
+	if reaction != want {
+		t.Errorf("getDiskHealthReaction() = %+v, want %+v", reaction, want)
+	}
+}
@@ -93,6 +93,11 @@ func (m *manager) processLocalDiskBound(localDisk *apisv1alpha1.LocalDisk) error
 		return err
 	}
 
+	if err = m.reactToDiskHealth(localDisk); err != nil {
+		logCtx.WithError(err).Error("Failed to react to the health of the disk")
+		return err
+	}
+
 	return nil
 }
 
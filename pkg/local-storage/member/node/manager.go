@@ -25,6 +25,7 @@ import (
 	"github.com/hwameistor/hwameistor/pkg/local-storage/member/csi"
 	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/diskmonitor"
 	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/qos"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/quiesce"
 	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/storage"
 	"github.com/hwameistor/hwameistor/pkg/local-storage/utils"
 	"github.com/hwameistor/hwameistor/pkg/local-storage/utils/datacopy"
@@ -81,16 +82,44 @@ type manager struct {
 
 	volumeReplicaSnapshotRestoreTaskQueue *common.TaskQueue
 
+	volumeBackupTaskQueue *common.TaskQueue
+
+	// volumeBackupCancels cancel the running uploads once the backups are aborted or deleted, guarded by lock
+	volumeBackupCancels map[string]context.CancelFunc
+
+	volumeBackupRestoreTaskQueue *common.TaskQueue
+
+	// volumeBackupRestoreCancels cancel the running downloads once the restores are aborted or deleted, guarded by lock
+	volumeBackupRestoreCancels map[string]context.CancelFunc
+
+	volumeScrubTaskQueue *common.TaskQueue
+
+	// volumeScrubRuns are the snapshot checksums running in background, guarded by lock
+	volumeScrubRuns map[string]*volumeScrubRun
+
+	volumeKeyRotateTaskQueue *common.TaskQueue
+
+	volumeEncryptTaskQueue *common.TaskQueue
+
+	// volumeEncryptRuns are the encryptions running in background, guarded by lock
+	volumeEncryptRuns map[string]*volumeEncryptRun
+
+	volumeShrinkTaskQueue *common.TaskQueue
+
 	localDiskClaimTaskQueue *common.TaskQueue
 
 	thinPoolClaimTaskQueue *common.TaskQueue
 
 	localDiskTaskQueue *common.TaskQueue
 
+	localDiskDecommissionTaskQueue *common.TaskQueue
+
 	configManager *configManager
 
 	volumeQoSManager *qos.VolumeQoSManager
 
+	quiescer quiesce.Quiescer
+
 	logger *log.Entry
 
 	lock sync.Mutex
@@ -128,9 +157,16 @@ func New(name string, namespace string, cli client.Client, informersCache runtim
 		localDiskClaimTaskQueue:               common.NewTaskQueue("LocalDiskClaim", maxRetries),
 		thinPoolClaimTaskQueue:                common.NewTaskQueue("ThinPoolClaim", maxRetries),
 		localDiskTaskQueue:                    common.NewTaskQueue("LocalDisk", maxRetries),
+		localDiskDecommissionTaskQueue:        common.NewTaskQueue("LocalDiskDecommission", maxRetries),
 		volumeSnapshotTaskQueue:               common.NewTaskQueue("VolumeSnapshotTask", maxRetries),
 		volumeReplicaSnapshotTaskQueue:        common.NewTaskQueue("VolumeReplicaSnapshotTask", maxRetries),
 		volumeReplicaSnapshotRestoreTaskQueue: common.NewTaskQueue("VolumeReplicaSnapshotRestoreTask", maxRetries),
+		volumeBackupTaskQueue:                 common.NewTaskQueue("VolumeBackupTask", maxRetries),
+		volumeBackupRestoreTaskQueue:          common.NewTaskQueue("VolumeBackupRestoreTask", maxRetries),
+		volumeScrubTaskQueue:                  common.NewTaskQueue("VolumeScrubTask", maxRetries),
+		volumeKeyRotateTaskQueue:              common.NewTaskQueue("VolumeKeyRotateTask", maxRetries),
+		volumeEncryptTaskQueue:                common.NewTaskQueue("VolumeEncryptTask", maxRetries),
+		volumeShrinkTaskQueue:                 common.NewTaskQueue("VolumeShrinkTask", maxRetries),
 		// healthCheckQueue:        common.NewTaskQueue("HealthCheckTask", maxRetries),
 		diskEventQueue:   diskmonitor.NewEventQueue("DiskEvents"),
 		configManager:    configManager,
@@ -139,6 +175,7 @@ func New(name string, namespace string, cli client.Client, informersCache runtim
 		scheme:           scheme,
 		recorder:         recorder,
 		mounter:          csi.NewLinuxMounter(log.WithField("Module", "NodeManager")),
+		quiescer:         quiesce.New(quiesce.NewFSFreezer(), quiesce.NewPodCommandExecutor(), cli),
 		pvMetadataSize:   pvMetadataSize,
 	}, nil
 }
@@ -165,6 +202,8 @@ func (m *manager) Run(stopCh <-chan struct{}) {
 
 	go m.startLocalDiskTaskWorker(stopCh)
 
+	go m.startLocalDiskDecommissionTaskWorker(stopCh)
+
 	go m.startDiskEventWorker(stopCh)
 
 	go m.startSyncVolumeMountTaskWorker(stopCh)
@@ -175,10 +214,22 @@ func (m *manager) Run(stopCh <-chan struct{}) {
 
 	go m.startVolumeReplicaSnapshotRestoreTaskWorker(stopCh)
 
+	go m.startVolumeBackupTaskWorker(stopCh)
+
+	go m.startVolumeBackupRestoreTaskWorker(stopCh)
+
+	go m.startVolumeScrubTaskWorker(stopCh)
+
+	go m.startVolumeKeyRotateTaskWorker(stopCh)
+	go m.startVolumeEncryptTaskWorker(stopCh)
+	go m.startVolumeShrinkTaskWorker(stopCh)
+
 	go diskmonitor.New(m.diskEventQueue).Run(stopCh)
 
 	go m.configManager.Run(stopCh)
 
+	go m.volumeQoSManager.Run(stopCh)
+
 	// move disk health check out, as a separate process
 	//go healths.NewDiskHealthManager(m.name, m.apiClient).Run(stopCh)
 }
@@ -342,6 +393,189 @@ func (m *manager) setupInformers() {
 		UpdateFunc: m.handleVolumeReplicaSnapshotRestoreUpdateEvent,
 		DeleteFunc: m.handleVolumeReplicaSnapshotRestoreDeleteEvent,
 	})
+
+	// setup LocalVolumeBackup informer
+	volumeBackupInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalVolumeBackup{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalVolumeBackup")
+	}
+	volumeBackupInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleVolumeBackupAddEvent,
+		UpdateFunc: m.handleVolumeBackupUpdateEvent,
+	})
+
+	// setup LocalVolumeBackupRestore informer
+	volumeBackupRestoreInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalVolumeBackupRestore{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalVolumeBackupRestore")
+	}
+	volumeBackupRestoreInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleVolumeBackupRestoreAddEvent,
+		UpdateFunc: m.handleVolumeBackupRestoreUpdateEvent,
+	})
+
+	// setup LocalVolumeScrub informer
+	volumeScrubInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalVolumeScrub{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalVolumeScrub")
+	}
+	volumeScrubInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleVolumeScrubAddEvent,
+		UpdateFunc: m.handleVolumeScrubUpdateEvent,
+	})
+
+	// setup LocalVolumeKeyRotate informer
+	volumeKeyRotateInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalVolumeKeyRotate{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalVolumeKeyRotate")
+	}
+	volumeKeyRotateInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleVolumeKeyRotateAddEvent,
+		UpdateFunc: m.handleVolumeKeyRotateUpdateEvent,
+	})
+
+	// setup LocalVolumeEncrypt informer
+	volumeEncryptInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalVolumeEncrypt{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalVolumeEncrypt")
+	}
+	volumeEncryptInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleVolumeEncryptAddEvent,
+		UpdateFunc: m.handleVolumeEncryptUpdateEvent,
+	})
+
+	// setup LocalVolumeShrink informer
+	volumeShrinkInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalVolumeShrink{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalVolumeShrink")
+	}
+	volumeShrinkInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleVolumeShrinkAddEvent,
+		UpdateFunc: m.handleVolumeShrinkUpdateEvent,
+	})
+
+	// setup LocalDiskDecommission informer
+	diskDecommissionInformer, err := m.informersCache.GetInformer(context.TODO(), &apisv1alpha1.LocalDiskDecommission{})
+	if err != nil {
+		// error happens, crash the node
+		m.logger.WithError(err).Fatal("Failed to get informer for LocalDiskDecommission")
+	}
+	diskDecommissionInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    m.handleLocalDiskDecommissionAddEvent,
+		UpdateFunc: m.handleLocalDiskDecommissionUpdateEvent,
+	})
+}
+
+func (m *manager) handleVolumeBackupAddEvent(newObject interface{}) {
+	volumeBackup, ok := newObject.(*apisv1alpha1.LocalVolumeBackup)
+	if !ok || volumeBackup.Status.NodeName != m.name {
+		return
+	}
+	m.volumeBackupTaskQueue.Add(volumeBackup.Name)
+}
+
+func (m *manager) handleVolumeBackupUpdateEvent(oldObj, newObj interface{}) {
+	// the worker is busy in uploading, so interrupt it here
+	if volumeBackup, ok := newObj.(*apisv1alpha1.LocalVolumeBackup); ok && (volumeBackup.Spec.Abort || volumeBackup.DeletionTimestamp != nil) {
+		m.cancelVolumeBackupRun(volumeBackup.Name)
+	}
+	m.handleVolumeBackupAddEvent(newObj)
+}
+
+func (m *manager) handleVolumeBackupRestoreAddEvent(newObject interface{}) {
+	backupRestore, ok := newObject.(*apisv1alpha1.LocalVolumeBackupRestore)
+	if !ok || backupRestore.Status.NodeName != m.name {
+		return
+	}
+	m.volumeBackupRestoreTaskQueue.Add(backupRestore.Name)
+}
+
+func (m *manager) handleVolumeBackupRestoreUpdateEvent(oldObj, newObj interface{}) {
+	// the worker is busy in downloading, so interrupt it here
+	if backupRestore, ok := newObj.(*apisv1alpha1.LocalVolumeBackupRestore); ok && (backupRestore.Spec.Abort || backupRestore.DeletionTimestamp != nil) {
+		m.cancelVolumeBackupRestoreRun(backupRestore.Name)
+	}
+	m.handleVolumeBackupRestoreAddEvent(newObj)
+}
+
+func (m *manager) handleVolumeScrubAddEvent(newObject interface{}) {
+	volumeScrub, ok := newObject.(*apisv1alpha1.LocalVolumeScrub)
+	if !ok {
+		return
+	}
+	for _, result := range volumeScrub.Status.Results {
+		if result.NodeName == m.name {
+			m.volumeScrubTaskQueue.Add(volumeScrub.Name)
+			return
+		}
+	}
+}
+
+func (m *manager) handleVolumeScrubUpdateEvent(oldObj, newObj interface{}) {
+	m.handleVolumeScrubAddEvent(newObj)
+}
+
+func (m *manager) handleVolumeKeyRotateAddEvent(newObject interface{}) {
+	keyRotate, ok := newObject.(*apisv1alpha1.LocalVolumeKeyRotate)
+	if !ok {
+		return
+	}
+	for _, replica := range keyRotate.Status.Replicas {
+		if replica.NodeName == m.name {
+			m.volumeKeyRotateTaskQueue.Add(keyRotate.Name)
+			return
+		}
+	}
+}
+
+func (m *manager) handleVolumeKeyRotateUpdateEvent(oldObj, newObj interface{}) {
+	m.handleVolumeKeyRotateAddEvent(newObj)
+}
+
+func (m *manager) handleVolumeEncryptAddEvent(newObject interface{}) {
+	volEncrypt, ok := newObject.(*apisv1alpha1.LocalVolumeEncrypt)
+	if !ok {
+		return
+	}
+	if volEncrypt.Status.NodeName == m.name {
+		m.volumeEncryptTaskQueue.Add(volEncrypt.Name)
+	}
+}
+
+func (m *manager) handleVolumeEncryptUpdateEvent(oldObj, newObj interface{}) {
+	m.handleVolumeEncryptAddEvent(newObj)
+}
+
+func (m *manager) handleVolumeShrinkAddEvent(newObject interface{}) {
+	volShrink, ok := newObject.(*apisv1alpha1.LocalVolumeShrink)
+	if !ok {
+		return
+	}
+	if volShrink.Status.NodeName == m.name && volShrink.Status.State == apisv1alpha1.OperationStateShrinkFilesystem {
+		m.volumeShrinkTaskQueue.Add(volShrink.Name)
+	}
+}
+
+func (m *manager) handleVolumeShrinkUpdateEvent(oldObj, newObj interface{}) {
+	m.handleVolumeShrinkAddEvent(newObj)
+}
+
+func (m *manager) handleLocalDiskDecommissionAddEvent(newObject interface{}) {
+	decommission, ok := newObject.(*apisv1alpha1.LocalDiskDecommission)
+	if !ok || decommission.Spec.NodeName != m.name {
+		return
+	}
+	m.localDiskDecommissionTaskQueue.Add(decommission.Name)
+}
+
+func (m *manager) handleLocalDiskDecommissionUpdateEvent(oldObj, newObj interface{}) {
+	m.handleLocalDiskDecommissionAddEvent(newObj)
 }
 
 func (m *manager) handleVolumeReplicaSnapshotRestoreAddEvent(newObject interface{}) {
@@ -452,14 +686,15 @@ func (m *manager) register() {
 	}
 	nodeConfig.Name = m.name
 
-	m.storageMgr = storage.NewLocalManager(nodeConfig, m.apiClient, m.scheme, m.recorder, m.snapshotRestoreTimeout)
+	m.storageMgr = storage.NewLocalManager(nodeConfig, m.configManager.systemConfig, m.apiClient, m.scheme, m.recorder, m.snapshotRestoreTimeout)
 	if err := m.storageMgr.Register(); err != nil {
 		logCtx.WithError(err).Fatal("Failed to register node's storage manager")
 	}
 }
 
 // Periodic collection of node resource usage information is meaningful
-// as it allows users to know about the dataPercent and metadataPercent in thin pools
+// as it allows users to know about the dataPercent and metadataPercent in thin pools.
+// The volume replicas are also checked periodically, e.g. to report the sync state of the mirrored ones
 func (m *manager) periodicSyncNodeResources(stopCh <-chan struct{}) {
 	tick := time.Tick(periodicDuration)
 	for {
@@ -467,7 +702,9 @@ func (m *manager) periodicSyncNodeResources(stopCh <-chan struct{}) {
 		case <-stopCh:
 			return
 		case <-tick:
+			m.autoExtendThinPools()
 			m.storageMgr.Registry().SyncNodeResources()
+			m.storageMgr.VolumeReplicaManager().ConsistencyCheck()
 		}
 	}
 }
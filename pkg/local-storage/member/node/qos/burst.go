@@ -0,0 +1,46 @@
+package qos
+
+import "time"
+
+const (
+	// the volume is idle for the burst credit to refill when running below 90% of the base limits
+	burstRefillUsageRatio = 0.9
+)
+
+// burstBucket tracks the burst credit of a volume. The credit is consumed while the volume runs above the base
+// limits, and refilled while it runs below them. Once used up, the volume is kept at the base limits until half
+// of the credit is refilled, so it doesn't flap between the burst and the base limits
+type burstBucket struct {
+	capacity  time.Duration
+	credit    time.Duration
+	exhausted bool
+}
+
+func newBurstBucket(capacity time.Duration) *burstBucket {
+	return &burstBucket{capacity: capacity, credit: capacity}
+}
+
+// update consumes or refills the credit by the elapsed time according to the usage of the volume
+func (b *burstBucket) update(elapsed time.Duration, limits *volumeQoSLimits, usage deviceUsage) {
+	switch {
+	case limits.isAboveBase(usage):
+		b.credit -= elapsed
+		if b.credit <= 0 {
+			b.credit = 0
+			b.exhausted = true
+		}
+	case limits.isBelowBase(usage, burstRefillUsageRatio):
+		b.credit += elapsed
+		if b.credit > b.capacity {
+			b.credit = b.capacity
+		}
+		if b.credit >= b.capacity/2 {
+			b.exhausted = false
+		}
+	}
+}
+
+// bursting returns true if the volume can run at the burst limits
+func (b *burstBucket) bursting() bool {
+	return b.capacity > 0 && b.credit > 0 && !b.exhausted
+}
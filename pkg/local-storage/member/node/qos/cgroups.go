@@ -3,6 +3,7 @@ package qos
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 	"syscall"
 
 	"github.com/containerd/cgroups/v3"
@@ -15,8 +16,18 @@ import (
 type VolumeCgroupsManager interface {
 	// ConfigureQoSForDevice configures the QoS for a volume.
 	ConfigureQoSForDevice(devPath string, iops, throughput int64) error
+
+	// ConfigureProportionalQoS configures the io.weight and the io.latency target in microseconds of the cgroup
+	// for a physical device, e.g. 8:16. 0 resets them to the default.
+	ConfigureProportionalQoS(cgroupPath string, device string, weight, latencyTargetUs int64) error
+
+	// PodCgroupPath returns the cgroup path of the pod, empty if not found.
+	PodCgroupPath(podUID string) (string, error)
 }
 
+// ErrProportionalQoSNotSupported is returned when configuring io.weight or io.latency without cgroup v2
+var ErrProportionalQoSNotSupported = fmt.Errorf("io.weight and io.latency are only supported by cgroup v2")
+
 // NewVolumeCgroupsManager returns a VolumeCgroupsManager according to the cgroups mode.
 func NewVolumeCgroupsManager() (VolumeCgroupsManager, error) {
 	exec := nsexecutor.New()
@@ -111,6 +122,16 @@ func (c *cgroupV1) ConfigureQoSForDevice(devPath string, iops, throughput int64)
 	return nil
 }
 
+// ConfigureProportionalQoS is not supported by cgroup v1.
+func (c *cgroupV1) ConfigureProportionalQoS(string, string, int64, int64) error {
+	return ErrProportionalQoSNotSupported
+}
+
+// PodCgroupPath is not supported by cgroup v1.
+func (c *cgroupV1) PodCgroupPath(string) (string, error) {
+	return "", ErrProportionalQoSNotSupported
+}
+
 var _ VolumeCgroupsManager = &noop{}
 
 type noop struct{}
@@ -119,6 +140,14 @@ func (n *noop) ConfigureQoSForDevice(string, int64, int64) error {
 	return nil
 }
 
+func (n *noop) ConfigureProportionalQoS(string, string, int64, int64) error {
+	return nil
+}
+
+func (n *noop) PodCgroupPath(string) (string, error) {
+	return "", nil
+}
+
 // cgroupV2 is the implementation of VolumeCgroupsManager for cgroup v2.
 type cgroupV2 struct {
 	exec         exechelper.Executor
@@ -158,6 +187,37 @@ func (c *cgroupV2) ConfigureQoSForDevice(devPath string, iops, throughput int64)
 	return nil
 }
 
+// ConfigureProportionalQoS configures the io.weight and io.latency of the pod cgroup for a physical device.
+func (c *cgroupV2) ConfigureProportionalQoS(cgroupPath string, device string, weight, latencyTargetUs int64) error {
+	weightValue := "default"
+	if weight > 0 {
+		weightValue = fmt.Sprintf("%d", weight)
+	}
+	if err := writeFile(c.exec, filepath.Join(cgroupPath, "io.weight"), fmt.Sprintf("%s %s", device, weightValue)); err != nil {
+		return err
+	}
+
+	latencyValue := "max"
+	if latencyTargetUs > 0 {
+		latencyValue = fmt.Sprintf("%d", latencyTargetUs)
+	}
+	return writeFile(c.exec, filepath.Join(cgroupPath, "io.latency"), fmt.Sprintf("%s target=%s", device, latencyValue))
+}
+
+// PodCgroupPath finds the cgroup of the pod under kubepods, for both the systemd and cgroupfs drivers, e.g.
+// kubepods.slice/kubepods-burstable.slice/kubepods-burstable-pod<uid>.slice, kubepods/burstable/pod<uid>
+func (c *cgroupV2) PodCgroupPath(podUID string) (string, error) {
+	result := c.exec.RunCommand(exechelper.ExecParams{
+		CmdName: "find",
+		CmdArgs: []string{filepath.Dir(c.iolimitsPath), "-maxdepth", "2", "-type", "d",
+			"(", "-name", "*pod" + podUID + "*", "-o", "-name", "*pod" + strings.ReplaceAll(podUID, "-", "_") + "*", ")"},
+	})
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return strings.TrimSpace(strings.SplitN(result.OutBuf.String(), "\n", 2)[0]), nil
+}
+
 // getDeviceNumber return the major and minor of a device according to the devicePath.
 func getDeviceNumber(devicePath string) (uint64, uint64, error) {
 	stat := syscall.Stat_t{}
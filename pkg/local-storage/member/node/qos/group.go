@@ -0,0 +1,31 @@
+package qos
+
+// shareGroupBudget divides the budget shared by the volumes of a LocalVolumeGroup. Half of the budget is divided
+// evenly, so every volume keeps a fair floor, and the other half is divided in proportion to the recent usage
+// of the volumes, so the busy ones get more. The sum of the shares never exceeds the budget
+func shareGroupBudget(budget int64, usages map[string]float64) map[string]int64 {
+	if budget <= 0 || len(usages) == 0 {
+		return nil
+	}
+
+	totalUsage := float64(0)
+	for _, usage := range usages {
+		totalUsage += usage
+	}
+
+	shares := make(map[string]int64, len(usages))
+	floor := budget / 2 / int64(len(usages))
+	dynamic := budget - floor*int64(len(usages))
+	for name, usage := range usages {
+		if totalUsage > 0 {
+			shares[name] = floor + int64(float64(dynamic)*usage/totalUsage)
+		} else {
+			shares[name] = floor + dynamic/int64(len(usages))
+		}
+		// a zero limit means no limit
+		if shares[name] == 0 {
+			shares[name] = 1
+		}
+	}
+	return shares
+}
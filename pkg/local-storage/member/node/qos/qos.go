@@ -3,10 +3,22 @@ package qos
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sync"
+	"time"
 
-	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+const (
+	// interval to adjust the limits of the volumes with the burst or the group budget
+	qosAdjustInterval = 5 * time.Second
 )
 
 type VolumeQoSManager struct {
@@ -14,6 +26,79 @@ type VolumeQoSManager struct {
 	cgroups  VolumeCgroupsManager
 
 	client client.Client
+
+	// volumes are the QoS states of the volumes on the node, guarded by lock
+	volumes map[string]*volumeQoSState
+	lock    sync.Mutex
+
+	logger *log.Entry
+}
+
+// volumeQoSLimits is the parsed VolumeQoS, 0 means no limit
+type volumeQoSLimits struct {
+	iops            int64
+	throughput      int64
+	burstIOPS       int64
+	burstThroughput int64
+	burstDuration   time.Duration
+	weight          int64
+	latencyTarget   time.Duration
+}
+
+// deviceUsage is the recent IOPS and throughput of a volume
+type deviceUsage struct {
+	iops       float64
+	throughput float64
+}
+
+// volumeQoSState is the QoS of a volume tracked by the node
+type volumeQoSState struct {
+	replicaName  string
+	devPath      string
+	group        string
+	pvcName      string
+	pvcNamespace string
+
+	limits *volumeQoSLimits
+	bucket *burstBucket
+
+	lastStat       deviceStat
+	lastSampleTime time.Time
+	usage          deviceUsage
+
+	// proportionalCgroups are the cgroups of the pods configured with the io.weight and io.latency,
+	// so the new pods, e.g. restarted, are configured while the others are not touched again
+	proportionalCgroups map[string]bool
+	applied             apisv1alpha1.VolumeQoSStatus
+	reported            bool
+}
+
+// volumeQoSInputs are the objects in the API server which the QoS of a volume depends on. They are fetched
+// without the lock, so the API calls don't block the other volumes
+type volumeQoSInputs struct {
+	// budget is the QoS budget of the LocalVolumeGroup, nil if not set
+	budget *volumeQoSLimits
+	// podUIDs are the running pods on the node using the volume
+	podUIDs []string
+	podsErr error
+}
+
+// trackedVolume is a volume tracked for the periodic adjustment, the fields to fetch its inputs are copied
+// from the state, which can be changed while unlocked
+type trackedVolume struct {
+	state        *volumeQoSState
+	group        string
+	pvcName      string
+	pvcNamespace string
+	proportional bool
+	inputs       volumeQoSInputs
+}
+
+// volumeQoSReport is the applied QoS to report in the status of the volume replica
+type volumeQoSReport struct {
+	state       *volumeQoSState
+	replicaName string
+	applied     apisv1alpha1.VolumeQoSStatus
 }
 
 func NewVolumeQoSManager(nodeName string, client client.Client) (*VolumeQoSManager, error) {
@@ -25,6 +110,8 @@ func NewVolumeQoSManager(nodeName string, client client.Client) (*VolumeQoSManag
 		nodeName: nodeName,
 		cgroups:  cgroups,
 		client:   client,
+		volumes:  map[string]*volumeQoSState{},
+		logger:   log.WithField("Module", "VolumeQoSManager"),
 	}
 	return m, nil
 }
@@ -56,37 +143,434 @@ func (m *VolumeQoSManager) RefreshQoSForLocalVolumeName(volumeName string) error
 	return m.ConfigureQoSForLocalVolumeReplica(targetReplica)
 }
 
-// ConfigureQoSForLocalVolumeReplica configures the QoS for a volume.
+// ConfigureQoSForLocalVolumeReplica configures the QoS for a volume. A changed QoS is applied immediately,
+// and the volume is tracked for the burst credit and the group budget
 func (m *VolumeQoSManager) ConfigureQoSForLocalVolumeReplica(replica *apisv1alpha1.LocalVolumeReplica) error {
-	devPath := getVolumeDevicePath(replica)
-	iops, throughput, err := parseVolumeQoSValues(replica.Spec.VolumeQoS)
+	limits, err := parseVolumeQoS(replica.Spec.VolumeQoS)
+	if err != nil {
+		return err
+	}
+
+	volumeName := replica.Spec.VolumeName
+	volume := &apisv1alpha1.LocalVolume{}
+	volumeErr := m.client.Get(context.TODO(), client.ObjectKey{Name: volumeName}, volume)
+	inputs := &volumeQoSInputs{budget: m.getGroupBudget(volume.Spec.VolumeGroup)}
+	inputs.podUIDs, inputs.podsErr = m.getPodsUsingVolume(volume.Spec.PersistentVolumeClaimName, volume.Spec.PersistentVolumeClaimNamespace)
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	state, exists := m.volumes[volumeName]
+	if !exists || !reflect.DeepEqual(state.limits, limits) {
+		newState := &volumeQoSState{limits: limits, bucket: newBurstBucket(limits.burstDuration)}
+		if exists {
+			// keep the applied one to reset the io.weight and io.latency removed
+			newState.applied = state.applied
+		}
+		state = newState
+		m.volumes[volumeName] = state
+	}
+	state.replicaName = replica.Name
+	state.devPath = getVolumeDevicePath(replica)
+
+	if volumeErr == nil {
+		state.group = volume.Spec.VolumeGroup
+		state.pvcName = volume.Spec.PersistentVolumeClaimName
+		state.pvcNamespace = volume.Spec.PersistentVolumeClaimNamespace
+	}
+
+	groupIOPS, groupThroughput := m.getGroupShares(inputs.budget, state.group, volumeName)
+	_, err = m.applyVolumeQoS(volumeName, state, groupIOPS, groupThroughput, inputs, true)
+	return err
+}
+
+// GetVolumeQoSStatus returns the QoS applied on the volume, nil if not configured
+func (m *VolumeQoSManager) GetVolumeQoSStatus(volumeName string) *apisv1alpha1.VolumeQoSStatus {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	state, exists := m.volumes[volumeName]
+	if !exists {
+		return nil
+	}
+	state.reported = true
+	return state.applied.DeepCopy()
+}
+
+// Run adjusts the limits of the volumes periodically for the burst credit and the group budget,
+// and reports the changed limits in the status of the volume replicas
+func (m *VolumeQoSManager) Run(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(qosAdjustInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			m.adjustVolumeQoS(time.Now())
+		}
+	}
+}
+
+// adjustVolumeQoS samples the usages and applies the limits of the volumes with the lock, while the objects
+// are fetched from and reported to the API server without it
+func (m *VolumeQoSManager) adjustVolumeQoS(now time.Time) {
+	volumes := m.sampleVolumeUsages(now)
+	m.fetchVolumeQoSInputs(volumes)
+
+	reports := []volumeQoSReport{}
+	m.lock.Lock()
+	for volumeName, volume := range volumes {
+		state, exists := m.volumes[volumeName]
+		if !exists || state != volume.state {
+			// deleted or configured again while unlocked
+			continue
+		}
+		groupIOPS, groupThroughput := m.getGroupShares(volume.inputs.budget, state.group, volumeName)
+		dynamic := state.limits.burstDuration > 0 || groupIOPS > 0 || groupThroughput > 0
+		changed, err := m.applyVolumeQoS(volumeName, state, groupIOPS, groupThroughput, &volume.inputs, dynamic)
+		if err != nil {
+			m.logger.WithFields(log.Fields{"volume": volumeName, "error": err.Error()}).Error("Failed to adjust QoS of the volume")
+			continue
+		}
+		if changed || !state.reported {
+			reports = append(reports, volumeQoSReport{state: state, replicaName: state.replicaName, applied: *state.applied.DeepCopy()})
+		}
+	}
+	m.lock.Unlock()
+
+	for _, report := range reports {
+		reported := m.reportVolumeQoS(report.replicaName, &report.applied) == nil
+		m.lock.Lock()
+		// the QoS may be applied again while unlocked
+		if reflect.DeepEqual(report.state.applied, report.applied) {
+			report.state.reported = reported
+		}
+		m.lock.Unlock()
+	}
+}
+
+// sampleVolumeUsages updates the recent usages and the burst credits of the volumes, and stops tracking the
+// volumes deleted or moved away. It returns the volumes tracked
+func (m *VolumeQoSManager) sampleVolumeUsages(now time.Time) map[string]*trackedVolume {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	volumes := map[string]*trackedVolume{}
+	for volumeName, state := range m.volumes {
+		if err := m.sampleVolumeUsage(state, now); err != nil {
+			// the volume is deleted or moved away
+			m.logger.WithFields(log.Fields{"volume": volumeName, "error": err.Error()}).Debug("Stop tracking QoS of the volume")
+			delete(m.volumes, volumeName)
+			continue
+		}
+		if state.limits.burstDuration > 0 {
+			state.bucket.update(now.Sub(state.lastSampleTime), state.limits, state.usage)
+		}
+		volumes[volumeName] = &trackedVolume{
+			state:        state,
+			group:        state.group,
+			pvcName:      state.pvcName,
+			pvcNamespace: state.pvcNamespace,
+			proportional: state.needProportionalQoS(),
+		}
+	}
+	return volumes
+}
+
+// fetchVolumeQoSInputs fetches the inputs of the volumes from the API server, the budget of each group is fetched once
+func (m *VolumeQoSManager) fetchVolumeQoSInputs(volumes map[string]*trackedVolume) {
+	budgets := map[string]*volumeQoSLimits{}
+	for _, volume := range volumes {
+		if _, exists := budgets[volume.group]; !exists {
+			budgets[volume.group] = m.getGroupBudget(volume.group)
+		}
+		volume.inputs.budget = budgets[volume.group]
+		if volume.proportional {
+			volume.inputs.podUIDs, volume.inputs.podsErr = m.getPodsUsingVolume(volume.pvcName, volume.pvcNamespace)
+		}
+	}
+}
+
+// sampleVolumeUsage updates the recent IOPS and throughput of the volume
+func (m *VolumeQoSManager) sampleVolumeUsage(state *volumeQoSState, now time.Time) error {
+	major, minor, err := getDeviceNumber(state.devPath)
 	if err != nil {
 		return err
 	}
-	return m.cgroups.ConfigureQoSForDevice(devPath, iops, throughput)
+	stat, err := readDeviceStat(fmt.Sprintf("%d:%d", major, minor))
+	if err != nil {
+		return err
+	}
+	if !state.lastSampleTime.IsZero() && stat.ios >= state.lastStat.ios && stat.bytes >= state.lastStat.bytes {
+		seconds := now.Sub(state.lastSampleTime).Seconds()
+		if seconds > 0 {
+			state.usage = deviceUsage{
+				iops:       float64(stat.ios-state.lastStat.ios) / seconds,
+				throughput: float64(stat.bytes-state.lastStat.bytes) / seconds,
+			}
+		}
+	}
+	state.lastStat, state.lastSampleTime = stat, now
+	return nil
 }
 
-// parseVolumeQoSValues parses the volume QoS values.
-func parseVolumeQoSValues(qos apisv1alpha1.VolumeQoS) (int64, int64, error) {
+// getGroupBudget returns the QoS budget of the LocalVolumeGroup, nil if not set
+func (m *VolumeQoSManager) getGroupBudget(group string) *volumeQoSLimits {
+	if group == "" {
+		return nil
+	}
+	lvg := &apisv1alpha1.LocalVolumeGroup{}
+	if err := m.client.Get(context.TODO(), client.ObjectKey{Name: group}, lvg); err != nil || lvg.Spec.VolumeQoS == nil {
+		return nil
+	}
+	budget, err := parseVolumeQoS(*lvg.Spec.VolumeQoS)
+	if err != nil {
+		m.logger.WithFields(log.Fields{"group": group, "error": err.Error()}).Error("Invalid QoS budget of the LocalVolumeGroup")
+		return nil
+	}
+	return budget
+}
+
+// getGroupShares returns the IOPS and throughput shared to the volume from the budget of its LocalVolumeGroup
+func (m *VolumeQoSManager) getGroupShares(budget *volumeQoSLimits, group string, volumeName string) (int64, int64) {
+	if group == "" || budget == nil {
+		return 0, 0
+	}
+
+	iopsUsages, throughputUsages := map[string]float64{}, map[string]float64{}
+	for name, state := range m.volumes {
+		if state.group == group {
+			iopsUsages[name] = state.usage.iops
+			throughputUsages[name] = state.usage.throughput
+		}
+	}
+	return shareGroupBudget(budget.iops, iopsUsages)[volumeName], shareGroupBudget(budget.throughput, throughputUsages)[volumeName]
+}
+
+// applyVolumeQoS applies the limits of the volume, and returns true if the applied QoS is changed
+func (m *VolumeQoSManager) applyVolumeQoS(volumeName string, state *volumeQoSState, groupIOPS, groupThroughput int64, inputs *volumeQoSInputs, force bool) (bool, error) {
+	bursting := state.limits.burstDuration > 0 && state.bucket.bursting()
+	iops, throughput, groupShared := state.limits.effectiveLimits(bursting, groupIOPS, groupThroughput)
+	if force || iops != state.applied.IOPS || throughput != state.applied.Throughput {
+		if err := m.cgroups.ConfigureQoSForDevice(state.devPath, iops, throughput); err != nil {
+			return false, err
+		}
+	}
+
+	applied := apisv1alpha1.VolumeQoSStatus{
+		IOPS:          iops,
+		Throughput:    throughput,
+		Bursting:      bursting,
+		GroupShared:   groupShared,
+		Weight:        state.applied.Weight,
+		LatencyTarget: state.applied.LatencyTarget,
+		Message:       state.applied.Message,
+	}
+	proportionalApplied, err := m.configureProportionalQoS(state, inputs)
+	switch {
+	case err != nil:
+		// it's retried by the next adjustment
+		applied.Message = fmt.Sprintf("failed to configure io.weight and io.latency: %v", err)
+	case proportionalApplied:
+		applied.Weight = state.limits.weight
+		applied.LatencyTarget = ""
+		if state.limits.latencyTarget > 0 {
+			applied.LatencyTarget = state.limits.latencyTarget.String()
+		}
+		applied.Message = ""
+	}
+
+	applied.LastUpdateTime = state.applied.LastUpdateTime
+	if reflect.DeepEqual(applied, state.applied) {
+		return false, nil
+	}
+	m.logger.WithFields(log.Fields{"volume": volumeName, "qos": applied}).Info("Applied QoS of the volume")
+	now := metav1.Now()
+	applied.LastUpdateTime = &now
+	state.applied = applied
+	return true, nil
+}
+
+// needProportionalQoS returns true if the io.weight or io.latency is set, or is to be reset
+func (s *volumeQoSState) needProportionalQoS() bool {
+	return s.limits.weight != 0 || s.limits.latencyTarget != 0 || s.applied.Weight != 0 || s.applied.LatencyTarget != ""
+}
+
+// configureProportionalQoS configures the io.weight and io.latency of the pods using the volume for the disks
+// of the volume. Only the cgroups not configured yet are configured, e.g. of a restarted pod.
+// It returns false if there is no pod to configure yet
+func (m *VolumeQoSManager) configureProportionalQoS(state *volumeQoSState, inputs *volumeQoSInputs) (bool, error) {
+	if !state.needProportionalQoS() {
+		return true, nil
+	}
+	if inputs.podsErr != nil || len(inputs.podUIDs) == 0 {
+		return false, inputs.podsErr
+	}
+
+	// forget the cgroups of the pods gone
+	configured := map[string]bool{}
+	defer func() { state.proportionalCgroups = configured }()
+
+	var devices []string
+	for _, podUID := range inputs.podUIDs {
+		cgroupPath, err := m.cgroups.PodCgroupPath(podUID)
+		if err != nil {
+			return false, err
+		}
+		if cgroupPath == "" {
+			continue
+		}
+		if state.proportionalCgroups[cgroupPath] {
+			configured[cgroupPath] = true
+			continue
+		}
+		if devices == nil {
+			if devices, err = getVolumePhysicalDevices(state.devPath); err != nil {
+				return false, err
+			}
+		}
+		for _, device := range devices {
+			if err := m.cgroups.ConfigureProportionalQoS(cgroupPath, device, state.limits.weight, state.limits.latencyTarget.Microseconds()); err != nil {
+				return false, err
+			}
+		}
+		configured[cgroupPath] = true
+	}
+	return len(configured) > 0, nil
+}
+
+// getVolumePhysicalDevices returns the physical devices, e.g. 8:16, of the disks which the volume is located at
+func getVolumePhysicalDevices(devPath string) ([]string, error) {
+	major, minor, err := getDeviceNumber(devPath)
+	if err != nil {
+		return nil, err
+	}
+	return getPhysicalDevices(fmt.Sprintf("%d:%d", major, minor))
+}
+
+// getPodsUsingVolume returns the UIDs of the running pods on the node using the PVC of the volume
+func (m *VolumeQoSManager) getPodsUsingVolume(pvcName string, pvcNamespace string) ([]string, error) {
+	if pvcName == "" {
+		return nil, nil
+	}
+	podList := &corev1.PodList{}
+	if err := m.client.List(context.TODO(), podList, client.InNamespace(pvcNamespace)); err != nil {
+		return nil, err
+	}
+	var podUIDs []string
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName != m.nodeName || pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, podVolume := range pod.Spec.Volumes {
+			if podVolume.PersistentVolumeClaim != nil && podVolume.PersistentVolumeClaim.ClaimName == pvcName {
+				podUIDs = append(podUIDs, string(pod.UID))
+				break
+			}
+		}
+	}
+	return podUIDs, nil
+}
+
+// reportVolumeQoS updates the applied QoS in the status of the volume replica
+func (m *VolumeQoSManager) reportVolumeQoS(replicaName string, applied *apisv1alpha1.VolumeQoSStatus) error {
+	replica := &apisv1alpha1.LocalVolumeReplica{}
+	if err := m.client.Get(context.TODO(), client.ObjectKey{Name: replicaName}, replica); err != nil {
+		return err
+	}
+	if reflect.DeepEqual(replica.Status.VolumeQoS, applied) {
+		return nil
+	}
+	replica.Status.VolumeQoS = applied.DeepCopy()
+	return m.client.Status().Update(context.TODO(), replica)
+}
+
+// parseVolumeQoS parses the volume QoS values.
+func parseVolumeQoS(qos apisv1alpha1.VolumeQoS) (*volumeQoSLimits, error) {
 	var (
-		iops       = resource.MustParse("0")
-		throughput = resource.MustParse("0")
-		err        error
+		limits = &volumeQoSLimits{weight: qos.Weight}
+		err    error
 	)
 
-	if qos.IOPS != "" {
-		iops, err = resource.ParseQuantity(qos.IOPS)
+	for _, quantity := range []struct {
+		value  string
+		target *int64
+	}{
+		{qos.IOPS, &limits.iops},
+		{qos.Throughput, &limits.throughput},
+		{qos.BurstIOPS, &limits.burstIOPS},
+		{qos.BurstThroughput, &limits.burstThroughput},
+	} {
+		if quantity.value == "" {
+			continue
+		}
+		q, err := resource.ParseQuantity(quantity.value)
 		if err != nil {
-			return 0, 0, err
+			return nil, err
 		}
+		*quantity.target = q.Value()
 	}
-	if qos.Throughput != "" {
-		throughput, err = resource.ParseQuantity(qos.Throughput)
-		if err != nil {
-			return 0, 0, err
+
+	if qos.BurstDuration != "" {
+		if limits.burstDuration, err = time.ParseDuration(qos.BurstDuration); err != nil {
+			return nil, err
+		}
+	}
+	if qos.LatencyTarget != "" {
+		if limits.latencyTarget, err = time.ParseDuration(qos.LatencyTarget); err != nil {
+			return nil, err
+		}
+	}
+
+	if limits.burstIOPS > 0 || limits.burstThroughput > 0 {
+		if limits.burstDuration <= 0 {
+			return nil, fmt.Errorf("burstDuration is required for the burst limits")
+		}
+		if (limits.burstIOPS > 0 && limits.iops == 0) || (limits.burstThroughput > 0 && limits.throughput == 0) {
+			return nil, fmt.Errorf("the base limits are required for the burst limits")
 		}
+		if limits.burstIOPS < limits.iops && limits.burstIOPS > 0 || limits.burstThroughput < limits.throughput && limits.burstThroughput > 0 {
+			return nil, fmt.Errorf("the burst limits must not be lower than the base limits")
+		}
+	} else {
+		// nothing to burst to
+		limits.burstDuration = 0
+	}
+	if limits.weight < 0 || limits.weight > 10000 {
+		return nil, fmt.Errorf("weight must be from 1 to 10000")
+	}
+	return limits, nil
+}
+
+// effectiveLimits returns the IOPS and throughput to apply, which are narrowed by the shares of the group budget.
+// It returns true if narrowed
+func (l *volumeQoSLimits) effectiveLimits(bursting bool, groupIOPS, groupThroughput int64) (int64, int64, bool) {
+	iops, throughput := l.iops, l.throughput
+	if bursting && l.burstIOPS > 0 {
+		iops = l.burstIOPS
+	}
+	if bursting && l.burstThroughput > 0 {
+		throughput = l.burstThroughput
+	}
+
+	groupShared := false
+	if groupIOPS > 0 && (iops == 0 || groupIOPS < iops) {
+		iops, groupShared = groupIOPS, true
+	}
+	if groupThroughput > 0 && (throughput == 0 || groupThroughput < throughput) {
+		throughput, groupShared = groupThroughput, true
 	}
-	return iops.Value(), throughput.Value(), nil
+	return iops, throughput, groupShared
+}
+
+// isAboveBase returns true if the volume runs above the base limits
+func (l *volumeQoSLimits) isAboveBase(usage deviceUsage) bool {
+	return (l.iops > 0 && usage.iops > float64(l.iops)) || (l.throughput > 0 && usage.throughput > float64(l.throughput))
+}
+
+// isBelowBase returns true if the volume runs below the ratio of all the base limits
+func (l *volumeQoSLimits) isBelowBase(usage deviceUsage, ratio float64) bool {
+	return (l.iops == 0 || usage.iops < float64(l.iops)*ratio) && (l.throughput == 0 || usage.throughput < float64(l.throughput)*ratio)
 }
 
 // getVolumeDevicePath returns the device path of a volume.
@@ -0,0 +1,337 @@
+package qos
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+// fakeCgroups records the cgroups configured with the io.weight and io.latency
+type fakeCgroups struct {
+	proportionalErr error
+	proportional    []string
+}
+
+func (f *fakeCgroups) ConfigureQoSForDevice(string, int64, int64) error {
+	return nil
+}
+
+func (f *fakeCgroups) ConfigureProportionalQoS(cgroupPath string, device string, weight, latencyTargetUs int64) error {
+	if f.proportionalErr != nil {
+		return f.proportionalErr
+	}
+	f.proportional = append(f.proportional, cgroupPath)
+	return nil
+}
+
+func (f *fakeCgroups) PodCgroupPath(podUID string) (string, error) {
+	return "/kubepods.slice/pod" + podUID, nil
+}
+
+func Test_parseVolumeQoS(t *testing.T) {
+	tests := []struct {
+		name    string
+		qos     apisv1alpha1.VolumeQoS
+		want    *volumeQoSLimits
+		wantErr bool
+	}{
+		{
+			name: "empty",
+			want: &volumeQoSLimits{},
+		},
+		{
+			name: "burst",
+			qos: apisv1alpha1.VolumeQoS{IOPS: "1000", Throughput: "100Mi", BurstIOPS: "3000", BurstThroughput: "200Mi",
+				BurstDuration: "1m", Weight: 200, LatencyTarget: "10ms"},
+			want: &volumeQoSLimits{iops: 1000, throughput: 100 * 1024 * 1024, burstIOPS: 3000, burstThroughput: 200 * 1024 * 1024,
+				burstDuration: time.Minute, weight: 200, latencyTarget: 10 * time.Millisecond},
+		},
+		{
+			name: "duration without burst limits",
+			qos:  apisv1alpha1.VolumeQoS{IOPS: "1000", BurstDuration: "1m"},
+			want: &volumeQoSLimits{iops: 1000},
+		},
+		{
+			name:    "burst without duration",
+			qos:     apisv1alpha1.VolumeQoS{IOPS: "1000", BurstIOPS: "3000"},
+			wantErr: true,
+		},
+		{
+			name:    "burst without base",
+			qos:     apisv1alpha1.VolumeQoS{BurstIOPS: "3000", BurstDuration: "1m"},
+			wantErr: true,
+		},
+		{
+			name:    "burst below base",
+			qos:     apisv1alpha1.VolumeQoS{IOPS: "1000", BurstIOPS: "500", BurstDuration: "1m"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid latency target",
+			qos:     apisv1alpha1.VolumeQoS{LatencyTarget: "10"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseVolumeQoS(tt.qos)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseVolumeQoS() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseVolumeQoS() got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_burstBucket(t *testing.T) {
+	limits := &volumeQoSLimits{iops: 1000, burstIOPS: 3000, burstDuration: 10 * time.Second}
+	bucket := newBurstBucket(limits.burstDuration)
+	busy, idle := deviceUsage{iops: 2500}, deviceUsage{iops: 100}
+
+	bucket.update(6*time.Second, limits, busy)
+	if !bucket.bursting() {
+		t.Fatal("bursting() expected true with credit left")
+	}
+	bucket.update(6*time.Second, limits, busy)
+	if bucket.bursting() {
+		t.Fatal("bursting() expected false with credit used up")
+	}
+
+	// keep at the base limits until half of the credit is refilled
+	bucket.update(3*time.Second, limits, idle)
+	if bucket.bursting() {
+		t.Fatal("bursting() expected false before half of the credit is refilled")
+	}
+	// running at the base limits doesn't refill the credit
+	bucket.update(time.Minute, limits, deviceUsage{iops: 1000})
+	if bucket.bursting() {
+		t.Fatal("bursting() expected false while running at the base limits")
+	}
+	bucket.update(2*time.Second, limits, idle)
+	if !bucket.bursting() {
+		t.Fatal("bursting() expected true after half of the credit is refilled")
+	}
+	bucket.update(time.Hour, limits, idle)
+	if bucket.credit != bucket.capacity {
+		t.Errorf("update() got credit %v over capacity %v", bucket.credit, bucket.capacity)
+	}
+}
+
+func Test_shareGroupBudget(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget int64
+		usages map[string]float64
+		want   map[string]int64
+	}{
+		{
+			name:   "no budget",
+			usages: map[string]float64{"a": 100},
+		},
+		{
+			name:   "idle",
+			budget: 1000,
+			usages: map[string]float64{"a": 0, "b": 0},
+			want:   map[string]int64{"a": 500, "b": 500},
+		},
+		{
+			name:   "by usage",
+			budget: 1000,
+			usages: map[string]float64{"a": 300, "b": 100},
+			want:   map[string]int64{"a": 625, "b": 375},
+		},
+		{
+			name:   "one busy",
+			budget: 1000,
+			usages: map[string]float64{"a": 800, "b": 0},
+			want:   map[string]int64{"a": 750, "b": 250},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := shareGroupBudget(tt.budget, tt.usages)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shareGroupBudget() got %v, want %v", got, tt.want)
+			}
+			total := int64(0)
+			for _, share := range got {
+				total += share
+			}
+			if total > tt.budget {
+				t.Errorf("shareGroupBudget() got total %d over budget %d", total, tt.budget)
+			}
+		})
+	}
+}
+
+func Test_volumeQoSLimits_effectiveLimits(t *testing.T) {
+	limits := &volumeQoSLimits{iops: 1000, throughput: 100, burstIOPS: 3000}
+	tests := []struct {
+		name            string
+		bursting        bool
+		groupIOPS       int64
+		groupThroughput int64
+		wantIOPS        int64
+		wantThroughput  int64
+		wantGroupShared bool
+	}{
+		{name: "base", wantIOPS: 1000, wantThroughput: 100},
+		{name: "bursting", bursting: true, wantIOPS: 3000, wantThroughput: 100},
+		{name: "group share above limits", bursting: true, groupIOPS: 5000, groupThroughput: 500, wantIOPS: 3000, wantThroughput: 100},
+		{name: "narrowed by group share", bursting: true, groupIOPS: 2000, wantIOPS: 2000, wantThroughput: 100, wantGroupShared: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			iops, throughput, groupShared := limits.effectiveLimits(tt.bursting, tt.groupIOPS, tt.groupThroughput)
+			if iops != tt.wantIOPS || throughput != tt.wantThroughput || groupShared != tt.wantGroupShared {
+				t.Errorf("effectiveLimits() got %d, %d, %v", iops, throughput, groupShared)
+			}
+		})
+	}
+}
+
+func Test_readDeviceStatAndPhysicalDevices(t *testing.T) {
+	root := t.TempDir()
+	defer func(origin string) { sysfsRoot = origin }(sysfsRoot)
+	sysfsRoot = root
+
+	mustWrite := func(path, content string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustSymlink := func(target, link string) {
+		if err := os.MkdirAll(filepath.Dir(link), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(target, link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// dm-0 (253:0) is the LVM volume on the partition sdb1 (8:17) of the disk sdb (8:16)
+	devices := filepath.Join(root, "devices")
+	mustWrite(filepath.Join(devices, "dm-0", "stat"), "10 0 80 0 20 0 160 0 0 0 0")
+	mustWrite(filepath.Join(devices, "dm-0", "slaves", "sdb1"), "")
+	mustWrite(filepath.Join(devices, "sdb", "dev"), "8:16\n")
+	mustWrite(filepath.Join(devices, "sdb", "sdb1", "dev"), "8:17\n")
+	mustWrite(filepath.Join(devices, "sdb", "sdb1", "partition"), "1\n")
+	mustSymlink(filepath.Join(devices, "dm-0"), filepath.Join(root, "dev", "block", "253:0"))
+	mustSymlink(filepath.Join(devices, "sdb", "sdb1"), filepath.Join(root, "dev", "block", "8:17"))
+	mustSymlink(filepath.Join(devices, "sdb", "sdb1"), filepath.Join(root, "class", "block", "sdb1"))
+
+	stat, err := readDeviceStat("253:0")
+	if err != nil {
+		t.Fatalf("readDeviceStat() error = %v", err)
+	}
+	if stat.ios != 30 || stat.bytes != 240*512 {
+		t.Errorf("readDeviceStat() got %+v", stat)
+	}
+
+	got, err := getPhysicalDevices("253:0")
+	if err != nil {
+		t.Fatalf("getPhysicalDevices() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{"8:16"}) {
+		t.Errorf("getPhysicalDevices() got %v", got)
+	}
+}
+
+func TestVolumeQoSManager_adjustVolumeQoS(t *testing.T) {
+	// /dev/null (1:3) is the device of the volume
+	root := t.TempDir()
+	defer func(origin string) { sysfsRoot = origin }(sysfsRoot)
+	sysfsRoot = root
+	if err := os.MkdirAll(filepath.Join(root, "dev", "block", "1:3"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "dev", "block", "1:3", "stat"), []byte("0 0 0 0 0 0 0 0 0 0 0"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := runtime.NewScheme()
+	if err := apisv1alpha1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.AddToScheme(s); err != nil {
+		t.Fatal(err)
+	}
+	volume := &apisv1alpha1.LocalVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"}}
+	volume.Spec.PersistentVolumeClaimName = "data"
+	volume.Spec.PersistentVolumeClaimNamespace = "default"
+	replica := &apisv1alpha1.LocalVolumeReplica{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1-replica"}}
+	replica.Spec.VolumeName = volume.Name
+	replica.Spec.VolumeQoS = apisv1alpha1.VolumeQoS{Weight: 200}
+	replica.Status.DevicePath = "/dev/null"
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default", UID: "uid-1"}}
+	pod.Spec.NodeName = "node1"
+	pod.Spec.Volumes = []corev1.Volume{{Name: "data", VolumeSource: corev1.VolumeSource{
+		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}}}
+
+	cgroups := &fakeCgroups{proportionalErr: fmt.Errorf("io.weight is not enabled")}
+	m := &VolumeQoSManager{
+		nodeName: "node1",
+		cgroups:  cgroups,
+		client:   fake.NewClientBuilder().WithScheme(s).WithObjects(volume, replica, pod).Build(),
+		volumes:  map[string]*volumeQoSState{},
+		logger:   log.WithField("Module", "VolumeQoSManager"),
+	}
+	getReported := func() *apisv1alpha1.VolumeQoSStatus {
+		got := &apisv1alpha1.LocalVolumeReplica{}
+		if err := m.client.Get(context.TODO(), client.ObjectKey{Name: replica.Name}, got); err != nil {
+			t.Fatal(err)
+		}
+		return got.Status.VolumeQoS
+	}
+
+	if err := m.ConfigureQoSForLocalVolumeReplica(replica); err != nil {
+		t.Fatalf("ConfigureQoSForLocalVolumeReplica() error = %v", err)
+	}
+	m.adjustVolumeQoS(time.Now())
+	if reported := getReported(); reported == nil || reported.Weight != 0 || reported.Message == "" {
+		t.Fatalf("adjustVolumeQoS() got reported %+v, want the failure", reported)
+	}
+
+	// the failure is retried
+	cgroups.proportionalErr = nil
+	m.adjustVolumeQoS(time.Now())
+	if reported := getReported(); reported.Weight != 200 || reported.Message != "" {
+		t.Errorf("adjustVolumeQoS() got reported %+v after retry", reported)
+	}
+	m.adjustVolumeQoS(time.Now())
+	if !reflect.DeepEqual(cgroups.proportional, []string{"/kubepods.slice/poduid-1"}) {
+		t.Errorf("adjustVolumeQoS() got configured %v", cgroups.proportional)
+	}
+
+	// the restarted pod is configured, even without any change of the QoS
+	if err := m.client.Delete(context.TODO(), pod); err != nil {
+		t.Fatal(err)
+	}
+	pod.ResourceVersion, pod.UID = "", types.UID("uid-2")
+	if err := m.client.Create(context.TODO(), pod); err != nil {
+		t.Fatal(err)
+	}
+	m.adjustVolumeQoS(time.Now())
+	if !reflect.DeepEqual(cgroups.proportional, []string{"/kubepods.slice/poduid-1", "/kubepods.slice/poduid-2"}) {
+		t.Errorf("adjustVolumeQoS() got configured %v", cgroups.proportional)
+	}
+}
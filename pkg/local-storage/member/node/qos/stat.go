@@ -0,0 +1,91 @@
+package qos
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sysfsRoot is the root of the sysfs, replaced in tests
+var sysfsRoot = "/sys"
+
+// deviceStat is the accumulated IO of a block device
+type deviceStat struct {
+	ios   uint64
+	bytes uint64
+}
+
+// readDeviceStat reads the completed IOs and the transferred bytes of the device, e.g. 253:3,
+// from /sys/dev/block/<major:minor>/stat
+func readDeviceStat(device string) (deviceStat, error) {
+	content, err := os.ReadFile(filepath.Join(sysfsRoot, "dev", "block", device, "stat"))
+	if err != nil {
+		return deviceStat{}, err
+	}
+	// fields: reads completed, reads merged, sectors read, time reading, writes completed, writes merged, sectors written, ...
+	fields := strings.Fields(string(content))
+	if len(fields) < 7 {
+		return deviceStat{}, fmt.Errorf("invalid stat of device %s: %s", device, content)
+	}
+	var values [7]uint64
+	for i := range values {
+		if values[i], err = strconv.ParseUint(fields[i], 10, 64); err != nil {
+			return deviceStat{}, fmt.Errorf("invalid stat of device %s: %v", device, err)
+		}
+	}
+	return deviceStat{ios: values[0] + values[4], bytes: (values[2] + values[6]) * 512}, nil
+}
+
+// getPhysicalDevices returns the physical devices under the device, e.g. the disks of an LVM volume or
+// a LUKS device on it. io.weight and io.latency only work on the physical devices
+func getPhysicalDevices(device string) ([]string, error) {
+	devicePath := filepath.Join(sysfsRoot, "dev", "block", device)
+	slaves, err := os.ReadDir(filepath.Join(devicePath, "slaves"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if len(slaves) == 0 {
+		// the partition is under the directory of its disk
+		if _, err := os.Stat(filepath.Join(devicePath, "partition")); err == nil {
+			realPath, err := filepath.EvalSymlinks(devicePath)
+			if err != nil {
+				return nil, err
+			}
+			content, err := os.ReadFile(filepath.Join(filepath.Dir(realPath), "dev"))
+			if err != nil {
+				return nil, err
+			}
+			return []string{strings.TrimSpace(string(content))}, nil
+		}
+		return []string{device}, nil
+	}
+
+	var devices []string
+	for _, slave := range slaves {
+		content, err := os.ReadFile(filepath.Join(sysfsRoot, "class", "block", slave.Name(), "dev"))
+		if err != nil {
+			return nil, err
+		}
+		slaveDevices, err := getPhysicalDevices(strings.TrimSpace(string(content)))
+		if err != nil {
+			return nil, err
+		}
+		for _, slaveDevice := range slaveDevices {
+			if !contains(devices, slaveDevice) {
+				devices = append(devices, slaveDevice)
+			}
+		}
+	}
+	return devices, nil
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,47 @@
+package quiesce
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hwameistor/hwameistor/pkg/exechelper"
+	"github.com/hwameistor/hwameistor/pkg/exechelper/nsexecutor"
+)
+
+// Freezer freezes and thaws the filesystem mounted at the mount point
+type Freezer interface {
+	Freeze(mountPoint string, timeout time.Duration) error
+
+	Thaw(mountPoint string, timeout time.Duration) error
+}
+
+type fsFreezer struct {
+	cmdExec exechelper.Executor
+}
+
+// NewFSFreezer creates a Freezer by fsfreeze on the host
+func NewFSFreezer() Freezer {
+	return &fsFreezer{cmdExec: nsexecutor.New()}
+}
+
+func (f *fsFreezer) Freeze(mountPoint string, timeout time.Duration) error {
+	return f.fsfreeze("--freeze", mountPoint, timeout)
+}
+
+func (f *fsFreezer) Thaw(mountPoint string, timeout time.Duration) error {
+	return f.fsfreeze("--unfreeze", mountPoint, timeout)
+}
+
+func (f *fsFreezer) fsfreeze(option string, mountPoint string, timeout time.Duration) error {
+	params := exechelper.ExecParams{
+		CmdName: "fsfreeze",
+		CmdArgs: []string{option, mountPoint},
+		Timeout: int(math.Ceil(timeout.Seconds())),
+	}
+	res := f.cmdExec.RunCommand(params)
+	if res.ExitCode != 0 {
+		return fmt.Errorf("fsfreeze %s %s: %v, %s", option, mountPoint, res.Error, res.ErrBuf.String())
+	}
+	return nil
+}
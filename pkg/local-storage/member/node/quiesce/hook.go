@@ -0,0 +1,161 @@
+package quiesce
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	k8sutils "github.com/hwameistor/hwameistor/pkg/utils/kubernetes"
+)
+
+// PodCommandExecutor executes the command in the container of the pod
+type PodCommandExecutor interface {
+	Exec(namespace, podName, container string, command []string, timeout time.Duration) (stdout string, stderr string, err error)
+}
+
+type podCommandExecutor struct{}
+
+// NewPodCommandExecutor creates a PodCommandExecutor by the pods/exec API
+func NewPodCommandExecutor() PodCommandExecutor {
+	return &podCommandExecutor{}
+}
+
+func (e *podCommandExecutor) Exec(namespace, podName, container string, command []string, timeout time.Duration) (string, string, error) {
+	config, err := k8sutils.GetConfig()
+	if err != nil {
+		return "", "", err
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return "", "", err
+	}
+
+	req := clientset.CoreV1().RESTClient().Post().Resource("pods").Namespace(namespace).Name(podName).SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return "", "", err
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	result := make(chan error, 1)
+	go func() {
+		result <- executor.Stream(remotecommand.StreamOptions{Stdout: stdout, Stderr: stderr})
+	}()
+	select {
+	case err = <-result:
+		return stdout.String(), stderr.String(), err
+	case <-time.After(timeout):
+		// the stream can't be cancelled, it ends when the command exits in the container
+		return "", "", fmt.Errorf("command %v timed out after %v", command, timeout)
+	}
+}
+
+type hookExecutor struct {
+	podExecutor PodCommandExecutor
+	apiClient   client.Client
+	logger      *log.Entry
+}
+
+type podHook struct {
+	pod       PodRef
+	container string
+	command   string
+}
+
+// preHook executes the pre hook commands in all the pods using the targets, and returns the func to execute the post hook commands.
+// If any of the pre hook commands fails, the post hook commands of the executed ones are executed immediately
+func (h *hookExecutor) preHook(timeout time.Duration, targets []Target) (resumeFunc, error) {
+	pods := map[PodRef]bool{}
+	var preHooks, postHooks []podHook
+	for _, target := range targets {
+		for _, podRef := range target.Pods {
+			if pods[podRef] {
+				continue
+			}
+			pods[podRef] = true
+
+			pod := &corev1.Pod{}
+			if err := h.apiClient.Get(context.TODO(), client.ObjectKey{Namespace: podRef.Namespace, Name: podRef.Name}, pod); err != nil {
+				return nil, err
+			}
+			if pod.Status.Phase != corev1.PodRunning {
+				continue
+			}
+			container := pod.Annotations[apisv1alpha1.SnapshotHookContainerAnnoKey]
+			if len(container) == 0 && len(pod.Spec.Containers) > 0 {
+				container = pod.Spec.Containers[0].Name
+			}
+			if command := pod.Annotations[apisv1alpha1.SnapshotPreHookCommandAnnoKey]; len(command) > 0 {
+				preHooks = append(preHooks, podHook{pod: podRef, container: container, command: command})
+			}
+			if command := pod.Annotations[apisv1alpha1.SnapshotPostHookCommandAnnoKey]; len(command) > 0 {
+				postHooks = append(postHooks, podHook{pod: podRef, container: container, command: command})
+			}
+		}
+	}
+
+	var done []PodRef
+	postHook := func() error {
+		var firstErr error
+		for i := len(postHooks) - 1; i >= 0; i-- {
+			if !containsPod(done, postHooks[i].pod) {
+				continue
+			}
+			if err := h.exec(postHooks[i], timeout); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+
+	for _, hook := range preHooks {
+		// the post hook is expected to undo the partial work of a failed pre hook too, e.g. unlock the tables
+		done = append(done, hook.pod)
+		if err := h.exec(hook, timeout); err != nil {
+			_ = postHook()
+			return nil, err
+		}
+	}
+	// the pods without pre hook still get the post hook
+	for _, hook := range postHooks {
+		if !containsPod(done, hook.pod) {
+			done = append(done, hook.pod)
+		}
+	}
+	return postHook, nil
+}
+
+func (h *hookExecutor) exec(hook podHook, timeout time.Duration) error {
+	logCtx := h.logger.WithFields(log.Fields{"pod": hook.pod, "container": hook.container, "command": hook.command})
+	stdout, stderr, err := h.podExecutor.Exec(hook.pod.Namespace, hook.pod.Name, hook.container, []string{"/bin/sh", "-c", hook.command}, timeout)
+	if err != nil {
+		logCtx.WithFields(log.Fields{"stdout": stdout, "stderr": stderr}).WithError(err).Error("Failed to execute snapshot hook")
+		return fmt.Errorf("failed to execute snapshot hook in pod %s/%s: %v, %s", hook.pod.Namespace, hook.pod.Name, err, stderr)
+	}
+	logCtx.WithField("stdout", stdout).Debug("Executed snapshot hook")
+	return nil
+}
+
+func containsPod(pods []PodRef, pod PodRef) bool {
+	for _, p := range pods {
+		if p == pod {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package quiesce
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+// ErrQuiesceTimeout is returned by the Resumer when the volumes were resumed by the watchdog before the snapshot completed,
+// the snapshot taken in this case is not guaranteed to be application-consistent
+var ErrQuiesceTimeout = fmt.Errorf("quiesce timed out, volumes were resumed before the snapshot completed")
+
+// Target is a volume to quiesce
+type Target struct {
+	// VolumeName is the name of the LocalVolume
+	VolumeName string
+
+	// MountPoints are the mount points of the volume on the node, empty for the raw block volume
+	MountPoints []string
+
+	// Pods are the pods using the volume
+	Pods []PodRef
+}
+
+// PodRef refers to a pod using the volume
+type PodRef struct {
+	Namespace string
+	Name      string
+}
+
+// Resumer resumes the quiesced volumes, it's safe to call it more than once
+type Resumer func() error
+
+// Quiescer quiesces the volumes before taking the snapshots and resumes them after that
+type Quiescer interface {
+	// Quiesce quiesces all the targets together with the given mode. Once it succeeds, the caller must call the Resumer.
+	// The targets are also resumed automatically if the Resumer is not called within the timeout
+	Quiesce(mode string, timeout time.Duration, targets []Target) (Resumer, error)
+}
+
+type quiescer struct {
+	freezer      Freezer
+	hookExecutor *hookExecutor
+	logger       *log.Entry
+}
+
+// New creates a quiescer
+func New(freezer Freezer, podExecutor PodCommandExecutor, cli client.Client) Quiescer {
+	logger := log.WithField("Module", "Quiescer")
+	return &quiescer{
+		freezer:      freezer,
+		hookExecutor: &hookExecutor{podExecutor: podExecutor, apiClient: cli, logger: logger},
+		logger:       logger,
+	}
+}
+
+// resumeFunc resumes what is quiesced successfully, and returns the first error
+type resumeFunc func() error
+
+func (q *quiescer) Quiesce(mode string, timeout time.Duration, targets []Target) (Resumer, error) {
+	logCtx := q.logger.WithFields(log.Fields{"mode": mode, "timeout": timeout})
+	if timeout <= 0 {
+		timeout = apisv1alpha1.SnapshotQuiesceDefaultTimeoutSeconds * time.Second
+	}
+
+	var (
+		resume resumeFunc
+		err    error
+	)
+	switch mode {
+	case "", apisv1alpha1.SnapshotQuiesceModeNone:
+		return func() error { return nil }, nil
+	case apisv1alpha1.SnapshotQuiesceModeFSFreeze:
+		resume, err = q.freeze(timeout, targets)
+	case apisv1alpha1.SnapshotQuiesceModeHook:
+		resume, err = q.hookExecutor.preHook(timeout, targets)
+	default:
+		return nil, fmt.Errorf("invalid quiesce mode %s", mode)
+	}
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to quiesce volumes")
+		return nil, err
+	}
+	logCtx.Debug("Volumes are quiesced")
+
+	// the watchdog makes sure that the volumes are never kept quiesced for long, e.g. the snapshot hangs
+	var (
+		once      sync.Once
+		resumeErr error
+	)
+	doResume := func() {
+		once.Do(func() {
+			if resumeErr = resume(); resumeErr != nil {
+				logCtx.WithError(resumeErr).Error("Failed to resume volumes")
+			} else {
+				logCtx.Debug("Volumes are resumed")
+			}
+		})
+	}
+	watchdog := time.AfterFunc(timeout, func() {
+		logCtx.Warning("Quiesce timed out, resume volumes now")
+		doResume()
+	})
+
+	return func() error {
+		fired := !watchdog.Stop()
+		// wait for the watchdog to complete if it fired already
+		doResume()
+		if fired && resumeErr == nil {
+			return ErrQuiesceTimeout
+		}
+		return resumeErr
+	}, nil
+}
+
+func (q *quiescer) freeze(timeout time.Duration, targets []Target) (resumeFunc, error) {
+	var frozen []string
+	thaw := func() error {
+		var firstErr error
+		for i := len(frozen) - 1; i >= 0; i-- {
+			if err := q.freezer.Thaw(frozen[i], timeout); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+		return firstErr
+	}
+
+	for _, target := range targets {
+		// freezing any of the mount points freezes the filesystem, and the filesystem can't be frozen twice
+		if len(target.MountPoints) == 0 {
+			q.logger.WithField("volume", target.VolumeName).Warning("Volume is not mounted, skip freezing it")
+			continue
+		}
+		if err := q.freezer.Freeze(target.MountPoints[0], timeout); err != nil {
+			_ = thaw()
+			return nil, fmt.Errorf("failed to freeze volume %s: %v", target.VolumeName, err)
+		}
+		frozen = append(frozen, target.MountPoints[0])
+	}
+	return thaw, nil
+}
@@ -0,0 +1,189 @@
+package quiesce
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+// fakeRecorder records the calls of the Freezer and PodCommandExecutor in order
+type fakeRecorder struct {
+	lock  sync.Mutex
+	calls []string
+	fails map[string]bool
+}
+
+func (r *fakeRecorder) record(call string) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.calls = append(r.calls, call)
+	if r.fails[call] {
+		return fmt.Errorf("%s failed", call)
+	}
+	return nil
+}
+
+func (r *fakeRecorder) getCalls() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]string{}, r.calls...)
+}
+
+func (r *fakeRecorder) Freeze(mountPoint string, timeout time.Duration) error {
+	return r.record("freeze " + mountPoint)
+}
+
+func (r *fakeRecorder) Thaw(mountPoint string, timeout time.Duration) error {
+	return r.record("thaw " + mountPoint)
+}
+
+func (r *fakeRecorder) Exec(namespace, podName, container string, command []string, timeout time.Duration) (string, string, error) {
+	return "", "", r.record(fmt.Sprintf("%s/%s/%s %s", namespace, podName, container, command[len(command)-1]))
+}
+
+func Test_quiescer_FSFreeze(t *testing.T) {
+	targets := []Target{
+		{VolumeName: "pvc-1", MountPoints: []string{"/mnt/pvc-1", "/mnt/pvc-1-bind"}},
+		{VolumeName: "pvc-block"},
+		{VolumeName: "pvc-2", MountPoints: []string{"/mnt/pvc-2"}},
+	}
+
+	tests := []struct {
+		name      string
+		fails     map[string]bool
+		wantErr   bool
+		wantCalls []string
+	}{
+		{
+			name:      "freeze and thaw all",
+			wantCalls: []string{"freeze /mnt/pvc-1", "freeze /mnt/pvc-2", "thaw /mnt/pvc-2", "thaw /mnt/pvc-1"},
+		},
+		{
+			name:      "thaw the frozen ones on failure",
+			fails:     map[string]bool{"freeze /mnt/pvc-2": true},
+			wantErr:   true,
+			wantCalls: []string{"freeze /mnt/pvc-1", "freeze /mnt/pvc-2", "thaw /mnt/pvc-1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &fakeRecorder{fails: tt.fails}
+			q := New(recorder, recorder, nil)
+			resume, err := q.Quiesce(apisv1alpha1.SnapshotQuiesceModeFSFreeze, time.Minute, targets)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Quiesce() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if err = resume(); err != nil {
+					t.Errorf("resume() error = %v", err)
+				}
+				// resume more than once is harmless
+				_ = resume()
+			}
+			if got := recorder.getCalls(); !reflect.DeepEqual(got, tt.wantCalls) {
+				t.Errorf("Quiesce() got calls %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func Test_quiescer_Timeout(t *testing.T) {
+	recorder := &fakeRecorder{}
+	q := New(recorder, recorder, nil)
+	resume, err := q.Quiesce(apisv1alpha1.SnapshotQuiesceModeFSFreeze, 10*time.Millisecond,
+		[]Target{{VolumeName: "pvc-1", MountPoints: []string{"/mnt/pvc-1"}}})
+	if err != nil {
+		t.Fatalf("Quiesce() error = %v", err)
+	}
+
+	// the snapshot takes longer than the timeout
+	time.Sleep(50 * time.Millisecond)
+	if got := recorder.getCalls(); !reflect.DeepEqual(got, []string{"freeze /mnt/pvc-1", "thaw /mnt/pvc-1"}) {
+		t.Errorf("watchdog got calls %v", got)
+	}
+	if err = resume(); err != ErrQuiesceTimeout {
+		t.Errorf("resume() error = %v, want %v", err, ErrQuiesceTimeout)
+	}
+	if got := recorder.getCalls(); len(got) != 2 {
+		t.Errorf("resume() thawed again, got calls %v", got)
+	}
+}
+
+func Test_quiescer_Hook(t *testing.T) {
+	newPod := func(name string, annotations map[string]string) *corev1.Pod {
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name, Annotations: annotations}}
+		pod.Spec.Containers = []corev1.Container{{Name: "app"}, {Name: "sidecar"}}
+		pod.Status.Phase = corev1.PodRunning
+		return pod
+	}
+	objs := []client.Object{
+		newPod("mysql", map[string]string{
+			apisv1alpha1.SnapshotPreHookCommandAnnoKey:  "lock",
+			apisv1alpha1.SnapshotPostHookCommandAnnoKey: "unlock",
+		}),
+		newPod("redis", map[string]string{
+			apisv1alpha1.SnapshotHookContainerAnnoKey:   "sidecar",
+			apisv1alpha1.SnapshotPreHookCommandAnnoKey:  "save",
+			apisv1alpha1.SnapshotPostHookCommandAnnoKey: "resume",
+		}),
+		newPod("nginx", nil),
+	}
+	s := runtime.NewScheme()
+	_ = clientgoscheme.AddToScheme(s)
+	cli := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+
+	// the volumes of the group used by the pods
+	targets := []Target{
+		{VolumeName: "pvc-1", Pods: []PodRef{{Namespace: "default", Name: "mysql"}, {Namespace: "default", Name: "nginx"}}},
+		{VolumeName: "pvc-2", Pods: []PodRef{{Namespace: "default", Name: "mysql"}, {Namespace: "default", Name: "redis"}}},
+	}
+
+	tests := []struct {
+		name      string
+		fails     map[string]bool
+		wantErr   bool
+		wantCalls []string
+	}{
+		{
+			name: "pre and post hooks",
+			wantCalls: []string{"default/mysql/app lock", "default/redis/sidecar save",
+				"default/redis/sidecar resume", "default/mysql/app unlock"},
+		},
+		{
+			name:    "post hooks on failure",
+			fails:   map[string]bool{"default/redis/sidecar save": true},
+			wantErr: true,
+			wantCalls: []string{"default/mysql/app lock", "default/redis/sidecar save",
+				"default/redis/sidecar resume", "default/mysql/app unlock"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &fakeRecorder{fails: tt.fails}
+			q := New(recorder, recorder, cli)
+			resume, err := q.Quiesce(apisv1alpha1.SnapshotQuiesceModeHook, time.Minute, targets)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Quiesce() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil {
+				if err = resume(); err != nil {
+					t.Errorf("resume() error = %v", err)
+				}
+			}
+			if got := recorder.getCalls(); !reflect.DeepEqual(got, tt.wantCalls) {
+				t.Errorf("Quiesce() got calls %v, want %v", got, tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,514 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/exechelper"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/utils"
+)
+
+// The cache of a volume is carved from a faster pool, e.g. LocalStorage_PoolNVMe, as LVs. LVM can't cache a LV with
+// the LVs in another volume group, so the cache is stacked on the LV of the volume by device-mapper directly:
+//
+//	/dev/mapper/<volume>-cached (cache, writecache, or linear when detached)
+//	  ├── /dev/LocalStorage_PoolHDD/<volume>
+//	  ├── /dev/LocalStorage_PoolNVMe/<volume>-cache
+//	  └── /dev/LocalStorage_PoolNVMe/<volume>-cachemeta (dm-cache only)
+//
+// The device-mapper table is not persistent, and is loaded again by the volume replica check after the node restarts.
+// The cache LVs are persistent, so the dirty blocks of the writeback cache survive the restart
+
+const (
+	// dmCacheBlockSectors is the block size of dm-cache, 64KiB in sectors
+	dmCacheBlockSectors = 128
+	// dmWritecacheBlockBytes is the block size of dm-writecache
+	dmWritecacheBlockBytes = 4096
+	// dmCacheMetadataBytesMin is the minimum size of the dm-cache metadata
+	dmCacheMetadataBytesMin = 8 * 1024 * 1024
+
+	dmTargetLinear     = "linear"
+	dmTargetCache      = "cache"
+	dmTargetWritecache = "writecache"
+
+	cacheLVSuffix     = "-cache"
+	cacheMetaLVSuffix = "-cachemeta"
+)
+
+func cacheLVName(volumeName string) string {
+	return volumeName + cacheLVSuffix
+}
+
+func cacheMetaLVName(volumeName string) string {
+	return volumeName + cacheMetaLVSuffix
+}
+
+// isVolumeCacheLV returns true if the LV is the cache of a volume rather than a volume
+func isVolumeCacheLV(lvName string) bool {
+	return strings.HasSuffix(lvName, cacheLVSuffix) || strings.HasSuffix(lvName, cacheMetaLVSuffix)
+}
+
+func volumeCacheDeviceName(volumeName string) string {
+	return strings.TrimPrefix(utils.VolumeCacheDevicePath(volumeName), "/dev/mapper/")
+}
+
+// cacheMetadataBytes returns the size of the dm-cache metadata for the cache, about 16 bytes for each cache block
+// plus the space for the superblock and the space maps, as lvmcache does
+func cacheMetadataBytes(cacheBytes int64) int64 {
+	blocks := cacheBytes / (dmCacheBlockSectors * 512)
+	metadataBytes := 4*1024*1024 + blocks*16*2
+	if metadataBytes < dmCacheMetadataBytesMin {
+		return dmCacheMetadataBytesMin
+	}
+	return metadataBytes
+}
+
+// volumeCacheTarget returns the device-mapper target for the cache
+func volumeCacheTarget(cache *apisv1alpha1.VolumeCache) string {
+	if cache == nil {
+		return dmTargetLinear
+	}
+	if cache.Mode == apisv1alpha1.VolumeCacheModeWriteback {
+		return dmTargetWritecache
+	}
+	return dmTargetCache
+}
+
+// volumeCacheTable returns the device-mapper table of the cached device
+func volumeCacheTable(target string, originSectors int64, origin, cacheDevice, metadataDevice string) string {
+	switch target {
+	case dmTargetCache:
+		return fmt.Sprintf("0 %d cache %s %s %s %d 1 writethrough smq 0", originSectors, metadataDevice, cacheDevice, origin, dmCacheBlockSectors)
+	case dmTargetWritecache:
+		return fmt.Sprintf("0 %d writecache s %s %s %d 0", originSectors, origin, cacheDevice, dmWritecacheBlockBytes)
+	}
+	return fmt.Sprintf("0 %d linear %s 0", originSectors, origin)
+}
+
+// parseDMTable returns the target and the length in sectors of the device-mapper table
+func parseDMTable(table string) (string, int64) {
+	fields := strings.Fields(table)
+	if len(fields) < 3 {
+		return "", 0
+	}
+	length, _ := strconv.ParseInt(fields[1], 10, 64)
+	return fields[2], length
+}
+
+// parseVolumeCacheStatus parses the statistics from the device-mapper status of the cached device
+func parseVolumeCacheStatus(status string) (*apisv1alpha1.VolumeCacheStatus, error) {
+	fields := strings.Fields(status)
+	if len(fields) < 3 {
+		return nil, fmt.Errorf("invalid cache status: %s", status)
+	}
+	var values []int64
+	parse := func(from, to int) error {
+		if len(fields) < to {
+			return fmt.Errorf("invalid %s status: %s", fields[2], status)
+		}
+		for _, field := range fields[from:to] {
+			for _, item := range strings.Split(field, "/") {
+				value, err := strconv.ParseInt(item, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid %s status: %s", fields[2], status)
+				}
+				values = append(values, value)
+			}
+		}
+		return nil
+	}
+
+	switch fields[2] {
+	case dmTargetCache:
+		// <metadata block size> <used>/<total metadata blocks> <cache block size> <used>/<total cache blocks>
+		// <read hits> <read misses> <write hits> <write misses> <demotions> <promotions> <dirty> ...
+		if err := parse(3, 14); err != nil {
+			return nil, err
+		}
+		return &apisv1alpha1.VolumeCacheStatus{
+			Mode:           apisv1alpha1.VolumeCacheModeWritethrough,
+			BlockSizeBytes: values[3] * 512,
+			UsedBlocks:     values[4],
+			TotalBlocks:    values[5],
+			ReadHits:       values[6],
+			ReadMisses:     values[7],
+			WriteHits:      values[8],
+			WriteMisses:    values[9],
+			DirtyBlocks:    values[12],
+		}, nil
+	case dmTargetWritecache:
+		// <error> <blocks> <free blocks> <blocks under writeback> [<read blocks> <read hits> <write blocks>
+		// <write hits uncommitted> <write hits committed> ...], the statistics are only reported by kernel 5.15+
+		if err := parse(3, 7); err != nil {
+			return nil, err
+		}
+		cacheStatus := &apisv1alpha1.VolumeCacheStatus{
+			Mode:           apisv1alpha1.VolumeCacheModeWriteback,
+			BlockSizeBytes: dmWritecacheBlockBytes,
+			TotalBlocks:    values[1],
+			UsedBlocks:     values[1] - values[2],
+			// the writecache doesn't report the clean blocks apart, all the used blocks are counted as dirty
+			DirtyBlocks: values[1] - values[2],
+		}
+		if len(fields) >= 12 {
+			if err := parse(7, 12); err != nil {
+				return nil, err
+			}
+			cacheStatus.ReadHits = values[5]
+			cacheStatus.ReadMisses = values[4] - values[5]
+			cacheStatus.WriteHits = values[7] + values[8]
+			cacheStatus.WriteMisses = values[6] - cacheStatus.WriteHits
+		}
+		return cacheStatus, nil
+	}
+	return nil, fmt.Errorf("cache is not attached")
+}
+
+// ensureVolumeCache makes sure the cache is attached to the volume replica as required. Once cached, the volume is
+// used at the cached device, which is kept as a linear device on the LV after the cache is removed, as the volume
+// may be mounted on it. The cache is detached before it's resized or changed to another mode, and attached again
+// as a new one, so the volume stays online
+func (lvm *lvmExecutor) ensureVolumeCache(replica *apisv1alpha1.LocalVolumeReplica) (*apisv1alpha1.LocalVolumeReplica, error) {
+	lvm.lock.Lock()
+	defer lvm.lock.Unlock()
+
+	volumeName := replica.Spec.VolumeName
+	deviceName := volumeCacheDeviceName(volumeName)
+	logCtx := lvm.logger.WithFields(log.Fields{"volume": volumeName, "cache": replica.Spec.VolumeCache})
+
+	table, exists, err := lvm.dmTable(deviceName)
+	if err != nil {
+		return nil, err
+	}
+	cache := replica.Spec.VolumeCache
+	if cache == nil && !exists {
+		return replica, nil
+	}
+
+	record, err := lvm.lvRecord(volumeName, replica.Spec.PoolName)
+	if err != nil {
+		return nil, err
+	}
+	originBytes, err := utils.ConvertLVMBytesToNumeric(record.LvCapacity)
+	if err != nil {
+		return nil, err
+	}
+	originSectors := originBytes / 512
+
+	currentTarget, currentSectors := parseDMTable(table)
+	wantTarget := volumeCacheTarget(cache)
+
+	var cacheRecord *lvRecord
+	resize := false
+	if cache != nil {
+		if cacheRecord, err = lvm.lvRecord(cacheLVName(volumeName), cache.PoolName); err == nil {
+			cacheBytes, err := utils.ConvertLVMBytesToNumeric(cacheRecord.LvCapacity)
+			if err != nil {
+				return nil, err
+			}
+			resize = cacheBytes < cache.CapacityBytes
+		} else {
+			cacheRecord = nil
+		}
+	}
+
+	if (currentTarget == dmTargetCache || currentTarget == dmTargetWritecache) && (currentTarget != wantTarget || resize) {
+		logCtx.WithField("target", currentTarget).Info("Detaching the cache from volume")
+		if err := lvm.detachVolumeCache(deviceName, currentTarget, volumeCacheTable(dmTargetLinear, originSectors, record.LvPath, "", "")); err != nil {
+			return nil, err
+		}
+		currentTarget, currentSectors = dmTargetLinear, originSectors
+	}
+
+	newReplica := replica.DeepCopy()
+	newReplica.Status.DevicePath = utils.VolumeCacheDevicePath(volumeName)
+	newReplica.Status.VolumeCache = nil
+
+	if cache == nil {
+		if err := lvm.removeVolumeCacheLVs(volumeName); err != nil {
+			return nil, err
+		}
+		return newReplica, nil
+	}
+
+	cacheDevice := composePoolVolumePath(cache.PoolName, cacheLVName(volumeName))
+	metadataDevice := composePoolVolumePath(cache.PoolName, cacheMetaLVName(volumeName))
+	if currentTarget != wantTarget {
+		// the cache is new, resized or changed to another mode, the stale content must be wiped.
+		// After the node restarts, the cache is attached with the content as it is
+		fresh := cacheRecord == nil || resize || replica.Status.VolumeCache == nil || replica.Status.VolumeCache.Mode != cache.Mode
+		if err := lvm.prepareVolumeCacheLV(cacheLVName(volumeName), cache.PoolName, cache.CapacityBytes, cacheRecord); err != nil {
+			return nil, err
+		}
+		if wantTarget == dmTargetCache {
+			metadataRecord, err := lvm.lvRecord(cacheMetaLVName(volumeName), cache.PoolName)
+			if err != nil {
+				metadataRecord, fresh = nil, true
+			}
+			if err := lvm.prepareVolumeCacheLV(cacheMetaLVName(volumeName), cache.PoolName, cacheMetadataBytes(cache.CapacityBytes), metadataRecord); err != nil {
+				return nil, err
+			}
+		}
+		if fresh {
+			// dm-cache formats the zeroed metadata, and dm-writecache formats the zeroed superblock
+			wipeDevice := cacheDevice
+			if wantTarget == dmTargetCache {
+				wipeDevice = metadataDevice
+			}
+			if err := lvm.wipeDeviceHeader(wipeDevice); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	wantTable := volumeCacheTable(wantTarget, originSectors, record.LvPath, cacheDevice, metadataDevice)
+	if !exists {
+		logCtx.Info("Creating the cached device of volume")
+		if err := lvm.dmsetup("create", deviceName, "--table", wantTable); err != nil {
+			return nil, err
+		}
+	} else if currentTarget != wantTarget || currentSectors != originSectors {
+		// the volume is expanded, or the cache is attached again
+		logCtx.WithField("sectors", originSectors).Info("Reloading the cached device of volume")
+		if err := lvm.dmReload(deviceName, wantTable, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	if newReplica.Status.VolumeCache, err = lvm.volumeCacheStatus(volumeName, cache.PoolName); err != nil {
+		logCtx.WithError(err).Warning("Failed to get the statistics of the cache")
+	}
+	return newReplica, nil
+}
+
+// volumeCacheStatus returns the statistics of the cache attached to the volume
+func (lvm *lvmExecutor) volumeCacheStatus(volumeName string, poolName string) (*apisv1alpha1.VolumeCacheStatus, error) {
+	params := exechelper.ExecParams{
+		CmdName: "dmsetup",
+		CmdArgs: []string{"status", volumeCacheDeviceName(volumeName)},
+	}
+	res := lvm.cmdExec.RunCommand(params)
+	if res.ExitCode != 0 {
+		return nil, res.Error
+	}
+	cacheStatus, err := parseVolumeCacheStatus(res.OutBuf.String())
+	if err != nil {
+		return nil, err
+	}
+	record, err := lvm.lvRecord(cacheLVName(volumeName), poolName)
+	if err != nil {
+		return nil, err
+	}
+	if cacheStatus.AllocatedCapacityBytes, err = utils.ConvertLVMBytesToNumeric(record.LvCapacity); err != nil {
+		return nil, err
+	}
+	now := metav1.Now()
+	cacheStatus.PoolName = poolName
+	cacheStatus.LastUpdateTime = &now
+	return cacheStatus, nil
+}
+
+// flushVolumeCache writes the dirty blocks of the writeback cache back to the volume, e.g. before taking a snapshot
+// of the LV. The writethrough cache never has dirty blocks
+func (lvm *lvmExecutor) flushVolumeCache(volumeName string) error {
+	table, exists, err := lvm.dmTable(volumeCacheDeviceName(volumeName))
+	if err != nil || !exists {
+		return err
+	}
+	if target, _ := parseDMTable(table); target != dmTargetWritecache {
+		return nil
+	}
+	lvm.logger.WithField("volume", volumeName).Debug("Flushing the writeback cache of volume")
+	return lvm.dmsetup("message", volumeCacheDeviceName(volumeName), "0", "flush")
+}
+
+// removeVolumeCache removes the cached device and the cache of the volume. The dirty blocks are written back
+// to the volume if writeBack, otherwise dropped, e.g. the volume is being deleted. It's loaded again by the
+// volume replica check if the volume is not deleted
+func (lvm *lvmExecutor) removeVolumeCache(volumeName string, writeBack bool) error {
+	deviceName := volumeCacheDeviceName(volumeName)
+	if table, exists, err := lvm.dmTable(deviceName); err != nil {
+		return err
+	} else if exists {
+		if target, sectors := parseDMTable(table); writeBack && (target == dmTargetCache || target == dmTargetWritecache) {
+			if err := lvm.detachVolumeCache(deviceName, target, volumeCacheTable(dmTargetLinear, sectors, dmTableOrigin(table), "", "")); err != nil {
+				return err
+			}
+		}
+		lvm.logger.WithField("volume", volumeName).Info("Removing the cached device of volume")
+		if err := lvm.dmsetup("remove", deviceName); err != nil {
+			return err
+		}
+	}
+	return lvm.removeVolumeCacheLVs(volumeName)
+}
+
+func (lvm *lvmExecutor) removeVolumeCacheLVs(volumeName string) error {
+	lvmStatus, err := lvm.getLVMStatus(LVMask)
+	if err != nil {
+		return err
+	}
+	for _, lvName := range []string{cacheLVName(volumeName), cacheMetaLVName(volumeName)} {
+		if lv, exists := lvmStatus.lvs[lvName]; exists {
+			if err := lvm.lvremove(lv.LvPath, []string{}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// prepareVolumeCacheLV creates the cache LV, or extends it to the capacity
+func (lvm *lvmExecutor) prepareVolumeCacheLV(lvName string, poolName string, capacityBytes int64, record *lvRecord) error {
+	if record == nil {
+		return lvm.lvcreate(lvName, poolName, []string{"--size", utils.ConvertNumericToLVMBytes(capacityBytes), "--stripes", "1"})
+	}
+	currentBytes, err := utils.ConvertLVMBytesToNumeric(record.LvCapacity)
+	if err != nil {
+		return err
+	}
+	if currentBytes >= capacityBytes {
+		return nil
+	}
+	return lvm.lvextend(record.LvPath, utils.NumericToLVMBytes(capacityBytes), []string{})
+}
+
+// detachVolumeCache loads the linear table on the LV of the volume. The writecache writes the dirty blocks back
+// while the device is suspended, so no write lands in the cache after that, and the linear table only takes
+// effect if the cache is clean
+func (lvm *lvmExecutor) detachVolumeCache(deviceName string, target string, linearTable string) error {
+	if target == dmTargetWritecache {
+		if err := lvm.dmsetup("message", deviceName, "0", "flush_on_suspend"); err != nil {
+			return err
+		}
+	}
+	return lvm.dmReload(deviceName, linearTable, func() error {
+		params := exechelper.ExecParams{
+			CmdName: "dmsetup",
+			CmdArgs: []string{"status", deviceName},
+		}
+		res := lvm.cmdExec.RunCommand(params)
+		if res.ExitCode != 0 {
+			return res.Error
+		}
+		return checkVolumeCacheClean(res.OutBuf.String())
+	})
+}
+
+// checkVolumeCacheClean checks there is no dirty block or error in the device-mapper status of the cached device
+func checkVolumeCacheClean(status string) error {
+	fields := strings.Fields(status)
+	if len(fields) < 3 {
+		return fmt.Errorf("invalid cache status: %s", status)
+	}
+	switch fields[2] {
+	case dmTargetCache:
+		// the status ends with the mode, and "Fail" if the cache failed
+		if fields[len(fields)-1] == "Fail" {
+			return fmt.Errorf("cache failed: %s", status)
+		}
+	case dmTargetWritecache:
+		// <error> <blocks> <free blocks> <blocks under writeback>
+		if len(fields) < 7 || fields[3] != "0" {
+			return fmt.Errorf("writecache failed: %s", status)
+		}
+		if fields[4] != fields[5] || fields[6] != "0" {
+			return fmt.Errorf("writecache is not written back: %s", status)
+		}
+		return nil
+	default:
+		return nil
+	}
+	cacheStatus, err := parseVolumeCacheStatus(status)
+	if err != nil {
+		return err
+	}
+	if cacheStatus.DirtyBlocks > 0 {
+		return fmt.Errorf("cache has %d dirty blocks: %s", cacheStatus.DirtyBlocks, status)
+	}
+	return nil
+}
+
+// dmTableOrigin returns the origin device of the device-mapper table of the cached device
+func dmTableOrigin(table string) string {
+	fields := strings.Fields(table)
+	switch {
+	case len(fields) > 5 && fields[2] == dmTargetCache:
+		// <metadata dev> <cache dev> <origin dev> ...
+		return fields[5]
+	case len(fields) > 4 && fields[2] == dmTargetWritecache:
+		// <p|s> <origin dev> <cache dev> ...
+		return fields[4]
+	case len(fields) > 3 && fields[2] == dmTargetLinear:
+		return fields[3]
+	}
+	return ""
+}
+
+func (lvm *lvmExecutor) wipeDeviceHeader(devPath string) error {
+	params := exechelper.ExecParams{
+		CmdName: "dd",
+		CmdArgs: []string{"if=/dev/zero", "of=" + devPath, fmt.Sprintf("bs=%d", dmWritecacheBlockBytes), "count=1", "oflag=direct", "conv=fsync"},
+	}
+	res := lvm.cmdExec.RunCommand(params)
+	if res.ExitCode != 0 {
+		return res.Error
+	}
+	return nil
+}
+
+// dmTable returns the device-mapper table of the device, and false if the device doesn't exist
+func (lvm *lvmExecutor) dmTable(deviceName string) (string, bool, error) {
+	params := exechelper.ExecParams{
+		CmdName: "dmsetup",
+		CmdArgs: []string{"table", deviceName},
+	}
+	res := lvm.cmdExec.RunCommand(params)
+	if res.ExitCode != 0 {
+		if strings.Contains(res.ErrBuf.String(), "No such device or address") {
+			return "", false, nil
+		}
+		return "", false, res.Error
+	}
+	return strings.TrimSpace(res.OutBuf.String()), true, nil
+}
+
+// dmReload replaces the table of the device, the IOs are queued while the device is suspended. The new table
+// only takes effect on resume if the suspended device passes the verify, otherwise the old one is kept
+func (lvm *lvmExecutor) dmReload(deviceName string, table string, verify func() error) error {
+	if err := lvm.dmsetup("suspend", deviceName); err != nil {
+		return err
+	}
+	err := lvm.dmsetup("load", deviceName, "--table", table)
+	if err == nil && verify != nil {
+		if err = verify(); err != nil {
+			// drop the inactive table. Don't resume if failed, or the new table takes effect
+			if clearErr := lvm.dmsetup("clear", deviceName); clearErr != nil {
+				lvm.logger.WithError(clearErr).WithField("device", deviceName).Error("Failed to clear the inactive table, the device is left suspended")
+				return err
+			}
+		}
+	}
+	// always resume the device, the old table is kept if failed to load or verify the new one
+	if resumeErr := lvm.dmsetup("resume", deviceName); resumeErr != nil {
+		return resumeErr
+	}
+	return err
+}
+
+func (lvm *lvmExecutor) dmsetup(args ...string) error {
+	params := exechelper.ExecParams{
+		CmdName: "dmsetup",
+		CmdArgs: args,
+	}
+	res := lvm.cmdExec.RunCommand(params)
+	if res.ExitCode != 0 {
+		return res.Error
+	}
+	return nil
+}
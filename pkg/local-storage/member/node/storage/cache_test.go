@@ -0,0 +1,231 @@
+package storage
+
+import (
+	"reflect"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+func Test_parseVolumeCacheStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		want    *apisv1alpha1.VolumeCacheStatus
+		wantErr bool
+	}{
+		{
+			name:   "dm-cache",
+			status: "0 2097152 cache 8 27/2048 128 100/1000 50 20 30 10 0 5 3 1 writethrough 2 migration_threshold 2048 smq 0 rw -",
+			want: &apisv1alpha1.VolumeCacheStatus{Mode: apisv1alpha1.VolumeCacheModeWritethrough, BlockSizeBytes: 65536,
+				TotalBlocks: 1000, UsedBlocks: 100, DirtyBlocks: 3, ReadHits: 50, ReadMisses: 20, WriteHits: 30, WriteMisses: 10},
+		},
+		{
+			name:   "writecache with statistics",
+			status: "0 2097152 writecache 0 1000 600 0 500 400 300 100 50 0",
+			want: &apisv1alpha1.VolumeCacheStatus{Mode: apisv1alpha1.VolumeCacheModeWriteback, BlockSizeBytes: 4096,
+				TotalBlocks: 1000, UsedBlocks: 400, DirtyBlocks: 400, ReadHits: 400, ReadMisses: 100, WriteHits: 150, WriteMisses: 150},
+		},
+		{
+			name:   "writecache without statistics",
+			status: "0 2097152 writecache 0 1000 600 0",
+			want: &apisv1alpha1.VolumeCacheStatus{Mode: apisv1alpha1.VolumeCacheModeWriteback, BlockSizeBytes: 4096,
+				TotalBlocks: 1000, UsedBlocks: 400, DirtyBlocks: 400},
+		},
+		{
+			name:    "detached",
+			status:  "0 2097152 linear",
+			wantErr: true,
+		},
+		{
+			name:    "truncated",
+			status:  "0 2097152 cache 8 27/2048",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseVolumeCacheStatus(tt.status)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseVolumeCacheStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseVolumeCacheStatus() got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_volumeCacheTable(t *testing.T) {
+	origin, cache, meta := "/dev/LocalStorage_PoolHDD/pvc-1", "/dev/LocalStorage_PoolNVMe/pvc-1-cache", "/dev/LocalStorage_PoolNVMe/pvc-1-cachemeta"
+	tests := []struct {
+		target string
+		want   string
+	}{
+		{dmTargetCache, "0 2048 cache " + meta + " " + cache + " " + origin + " 128 1 writethrough smq 0"},
+		{dmTargetWritecache, "0 2048 writecache s " + origin + " " + cache + " 4096 0"},
+		{dmTargetLinear, "0 2048 linear " + origin + " 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			table := volumeCacheTable(tt.target, 2048, origin, cache, meta)
+			if table != tt.want {
+				t.Errorf("volumeCacheTable() got %s, want %s", table, tt.want)
+			}
+			if target, length := parseDMTable(table); target != tt.target || length != 2048 {
+				t.Errorf("parseDMTable() got %s %d", target, length)
+			}
+		})
+	}
+}
+
+func Test_volumeCacheTarget(t *testing.T) {
+	if got := volumeCacheTarget(nil); got != dmTargetLinear {
+		t.Errorf("volumeCacheTarget() got %s for no cache", got)
+	}
+	if got := volumeCacheTarget(&apisv1alpha1.VolumeCache{Mode: apisv1alpha1.VolumeCacheModeWritethrough}); got != dmTargetCache {
+		t.Errorf("volumeCacheTarget() got %s for writethrough", got)
+	}
+	if got := volumeCacheTarget(&apisv1alpha1.VolumeCache{Mode: apisv1alpha1.VolumeCacheModeWriteback}); got != dmTargetWritecache {
+		t.Errorf("volumeCacheTarget() got %s for writeback", got)
+	}
+}
+
+func Test_cacheMetadataBytes(t *testing.T) {
+	if got := cacheMetadataBytes(64 * 1024 * 1024); got != dmCacheMetadataBytesMin {
+		t.Errorf("cacheMetadataBytes() got %d for small cache", got)
+	}
+	// 1TiB cache has 16Mi blocks of 64KiB
+	if got, want := cacheMetadataBytes(1024*1024*1024*1024), int64(4*1024*1024+16*1024*1024*32); got != want {
+		t.Errorf("cacheMetadataBytes() got %d, want %d", got, want)
+	}
+}
+
+func Test_isVolumeCacheLV(t *testing.T) {
+	for lvName, want := range map[string]bool{
+		"pvc-1":                  false,
+		cacheLVName("pvc-1"):     true,
+		cacheMetaLVName("pvc-1"): true,
+	} {
+		if got := isVolumeCacheLV(lvName); got != want {
+			t.Errorf("isVolumeCacheLV(%s) got %v, want %v", lvName, got, want)
+		}
+	}
+}
+
+func Test_checkVolumeCacheClean(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  string
+		wantErr bool
+	}{
+		{
+			name:   "clean dm-cache",
+			status: "0 2097152 cache 8 27/2048 128 100/1000 50 20 30 10 0 5 0 1 writethrough 2 migration_threshold 2048 smq 0 rw -",
+		},
+		{
+			name:    "dirty dm-cache",
+			status:  "0 2097152 cache 8 27/2048 128 100/1000 50 20 30 10 0 5 3 1 writethrough 2 migration_threshold 2048 smq 0 rw -",
+			wantErr: true,
+		},
+		{
+			name:    "failed dm-cache",
+			status:  "0 2097152 cache 8 27/2048 128 100/1000 50 20 30 10 0 5 0 1 writethrough 2 migration_threshold 2048 smq 0 Fail",
+			wantErr: true,
+		},
+		{
+			name:   "written back writecache",
+			status: "0 2097152 writecache 0 1000 1000 0",
+		},
+		{
+			name:    "dirty writecache",
+			status:  "0 2097152 writecache 0 1000 600 0",
+			wantErr: true,
+		},
+		{
+			name:    "writecache under writeback",
+			status:  "0 2097152 writecache 0 1000 1000 2",
+			wantErr: true,
+		},
+		{
+			name:    "writecache with error",
+			status:  "0 2097152 writecache -5 1000 1000 0",
+			wantErr: true,
+		},
+		{
+			name:   "linear",
+			status: "0 2097152 linear",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := checkVolumeCacheClean(tt.status); (err != nil) != tt.wantErr {
+				t.Errorf("checkVolumeCacheClean() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_dmTableOrigin(t *testing.T) {
+	tests := map[string]string{
+		"0 2097152 cache 253:5 253:4 253:3 128 1 writethrough smq 0": "253:3",
+		"0 2097152 writecache s 253:3 253:4 4096 0":                  "253:3",
+		"0 2097152 linear 253:3 0":                                   "253:3",
+		"0 2097152":                                                  "",
+	}
+	for table, want := range tests {
+		if got := dmTableOrigin(table); got != want {
+			t.Errorf("dmTableOrigin(%q) = %q, want %q", table, got, want)
+		}
+	}
+}
+
+func Test_lvmExecutor_detachVolumeCache(t *testing.T) {
+	linearTable := "0 2097152 linear 253:3 0"
+	tests := []struct {
+		name         string
+		status       string
+		wantErr      bool
+		wantCommands []string
+	}{
+		{
+			name:    "written back",
+			status:  "0 2097152 writecache 0 1000 1000 0",
+			wantErr: false,
+			wantCommands: []string{
+				"dmsetup message pvc-1-cached 0 flush_on_suspend",
+				"dmsetup suspend pvc-1-cached",
+				"dmsetup load pvc-1-cached --table " + linearTable,
+				"dmsetup status pvc-1-cached",
+				"dmsetup resume pvc-1-cached",
+			},
+		},
+		{
+			name:    "dirty blocks left",
+			status:  "0 2097152 writecache 0 1000 999 1",
+			wantErr: true,
+			wantCommands: []string{
+				"dmsetup message pvc-1-cached 0 flush_on_suspend",
+				"dmsetup suspend pvc-1-cached",
+				"dmsetup load pvc-1-cached --table " + linearTable,
+				"dmsetup status pvc-1-cached",
+				"dmsetup clear pvc-1-cached",
+				"dmsetup resume pvc-1-cached",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmdExec := &fakeCmdExecutor{outputs: map[string]string{"dmsetup status pvc-1-cached": tt.status}}
+			lvm := &lvmExecutor{cmdExec: cmdExec, logger: log.WithField("Module", "NodeManager/lvmExecuter")}
+			if err := lvm.detachVolumeCache("pvc-1-cached", dmTargetWritecache, linearTable); (err != nil) != tt.wantErr {
+				t.Errorf("detachVolumeCache() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(cmdExec.commands, tt.wantCommands) {
+				t.Errorf("detachVolumeCache() got commands %v, want %v", cmdExec.commands, tt.wantCommands)
+			}
+		})
+	}
+}
@@ -11,3 +11,12 @@ func mergeRegistryDiskMap(localDiskMap ...map[string]*apisv1alpha1.LocalDevice)
 	}
 	return newLocalDiskMap
 }
+
+// replicaConsumedCapacityBytes returns the pool capacity consumed by the volume replica of the given size,
+// a mirrored volume replica keeps a full copy of the data on each disk
+func replicaConsumedCapacityBytes(replica *apisv1alpha1.LocalVolumeReplica, capacityBytes int64) int64 {
+	if replica.Spec.Mirror {
+		return capacityBytes * apisv1alpha1.LVMMirrorCopies
+	}
+	return capacityBytes
+}
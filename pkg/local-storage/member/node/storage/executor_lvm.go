@@ -7,12 +7,15 @@ import (
 	"fmt"
 	"math"
 	"path"
+	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -45,6 +48,9 @@ var (
 	ErrNotLVMByteNum   = errors.New("LVM byte format unrecognised")
 	ErrReplicaNotFound = errors.New("volume replica not found on host")
 	LVMTimeLayout      = "2006-01-02 15:04:05 -0700"
+
+	// raidSubLVNameRegex matches the hidden images and metadata of a raid LV, e.g. pvc-xxx_rimage_0, pvc-xxx_rmeta_1
+	raidSubLVNameRegex = regexp.MustCompile(`^(.+)_r(image|meta)_[0-9]+`)
 )
 
 // for PV/disk
@@ -111,6 +117,12 @@ type lvRecord struct {
 	LvMetadataSize  string      `json:"lv_metadata_size,omitempty"`
 	LvAttr          string      `json:"lv_attr,omitempty"`
 	Devices         string      `json:"devices,omitempty"`
+	SyncPercent     string      `json:"sync_percent,omitempty"`
+	RaidMismatches  string      `json:"raid_mismatch_count,omitempty"`
+	HealthStatus    string      `json:"lv_health_status,omitempty"`
+	RaidSyncAction  string      `json:"raid_sync_action,omitempty"`
+	MovePV          string      `json:"move_pv,omitempty"`
+	CopyPercent     string      `json:"copy_percent,omitempty"`
 	Disks           sets.String `json:"-"`
 }
 
@@ -152,6 +164,10 @@ type lvmExecutor struct {
 	// if multiple coroutines create volumes, snapshots, and extend volumes simultaneously, it may exceed the thin pool size
 	// so it's required to use lock to make lvm resource operations more accurate
 	lock sync.Mutex
+
+	// thinPoolExtendFailures are the reasons why the thin pools can't be extended automatically, guarded by extendLock
+	thinPoolExtendFailures map[string]string
+	extendLock             sync.Mutex
 }
 
 func (lvm *lvmExecutor) GetPools() (map[string]*apisv1alpha1.LocalPool, error) {
@@ -206,6 +222,15 @@ func (lvm *lvmExecutor) GetPools() (map[string]*apisv1alpha1.LocalPool, error) {
 				Class:         poolClass,
 				State:         apisv1alpha1.DiskStateInUse,
 			})
+			// the free space on the non-allocatable disk, e.g. an unhealthy one, can't be used by the volumes
+			if !pvAllocatable(pv.PvAttr) {
+				pvFree, err := utils.ConvertLVMBytesToNumeric(pv.PvFree)
+				if err != nil {
+					lvm.logger.WithError(err).Errorf("Failed to convert LVM byte numbers int64: %s\n.", pv.PvFree)
+					return nil, err
+				}
+				freeCapacityBytes -= pvFree
+			}
 		}
 
 		// Prepare LV status
@@ -219,6 +244,7 @@ func (lvm *lvmExecutor) GetPools() (map[string]*apisv1alpha1.LocalPool, error) {
 			Name:                     vg.Name,
 			Class:                    poolClass,
 			Type:                     poolType,
+			Backend:                  apisv1alpha1.StorageBackendLVM,
 			TotalCapacityBytes:       int64(totalCapacityBytes),
 			UsedCapacityBytes:        int64(totalCapacityBytes) - int64(freeCapacityBytes),
 			FreeCapacityBytes:        int64(freeCapacityBytes),
@@ -246,7 +272,7 @@ func (lvm *lvmExecutor) GetReplicas() (map[string]*apisv1alpha1.LocalVolumeRepli
 	replicas := make(map[string]*apisv1alpha1.LocalVolumeReplica)
 
 	for lvName, lv := range lvmStatus.lvs {
-		if !strings.HasPrefix(lv.PoolName, apisv1alpha1.PoolNamePrefix) {
+		if !strings.HasPrefix(lv.PoolName, apisv1alpha1.PoolNamePrefix) || isVolumeCacheLV(lvName) {
 			continue
 		}
 
@@ -323,6 +349,9 @@ func (lvm *lvmExecutor) CreateVolumeReplica(replica *apisv1alpha1.LocalVolumeRep
 			position = fmt.Sprintf("%s/%s", replica.Spec.PoolName, *replica.Spec.ThinOriginVolume)
 			options = append(options, "-s", "-k", "n", "--thinpool", apisv1alpha1.ThinPoolName)
 		}
+	} else if replica.Spec.Mirror {
+		// the raid1 images are always allocated on distinct PVs
+		options = append(options, "--size", sizeStr, "--type", "raid1", "--mirrors", fmt.Sprintf("%d", apisv1alpha1.LVMMirrorCopies-1))
 	} else {
 		options = append(options, "--size", sizeStr, "--stripes", fmt.Sprintf("%d", 1))
 	}
@@ -343,6 +372,11 @@ func (lvm *lvmExecutor) CreateVolumeReplica(replica *apisv1alpha1.LocalVolumeRep
 	if err != nil {
 		return nil, err
 	}
+	if replica.Spec.Mirror {
+		if status.disks, err = lvm.mirrorDisks(replica.Spec.VolumeName); err != nil {
+			return nil, err
+		}
+	}
 	newReplica := replica.DeepCopy()
 	newReplica.Status.AllocatedCapacityBytes = allocatedCapacityBytes
 	newReplica.Status.StoragePath = record.LvPath
@@ -367,6 +401,16 @@ func (lvm *lvmExecutor) ExpandVolumeReplica(replica *apisv1alpha1.LocalVolumeRep
 			return nil, err
 		}
 	}
+	if replica.Spec.Mirror {
+		// LVM can't extend the raid LV until the images are in-sync
+		record, err := lvm.lvRecord(replica.Spec.VolumeName, replica.Spec.PoolName)
+		if err != nil {
+			return nil, err
+		}
+		if condition := mirrorSyncCondition(record); condition.Status != metav1.ConditionTrue {
+			return nil, fmt.Errorf("mirrored volume %s is not in-sync: %s", replica.Spec.VolumeName, condition.Message)
+		}
+	}
 
 	newLVMCapacityBytes := utils.NumericToLVMBytes(newCapacityBytes)
 
@@ -394,6 +438,39 @@ func (lvm *lvmExecutor) ExpandVolumeReplica(replica *apisv1alpha1.LocalVolumeRep
 	return newReplica, nil
 }
 
+// shrinkVolumeReplica reduces the LV of the volume replica, the filesystem and the replication on it must be
+// shrunk or removed already, as the data beyond the new size is lost
+func (lvm *lvmExecutor) shrinkVolumeReplica(replica *apisv1alpha1.LocalVolumeReplica, newCapacityBytes int64) (*apisv1alpha1.LocalVolumeReplica, error) {
+	newLVMCapacityBytes := utils.NumericToLVMBytes(newCapacityBytes)
+	if replica.Status.AllocatedCapacityBytes <= newLVMCapacityBytes {
+		return replica, nil
+	}
+	lvm.lock.Lock()
+	defer lvm.lock.Unlock()
+
+	// for compatibility
+	storagePath := replica.Status.StoragePath
+	if len(storagePath) == 0 {
+		storagePath = replica.Status.DevicePath
+	}
+	if err := lvm.lvreduce(storagePath, newLVMCapacityBytes); err != nil {
+		return nil, err
+	}
+
+	record, err := lvm.lvRecord(replica.Spec.VolumeName, replica.Spec.PoolName)
+	if err != nil {
+		return nil, err
+	}
+	allocatedCapacityBytes, err := utils.ConvertLVMBytesToNumeric(record.LvCapacity)
+	if err != nil {
+		return nil, err
+	}
+	newReplica := replica.DeepCopy()
+	newReplica.Status.AllocatedCapacityBytes = allocatedCapacityBytes
+
+	return newReplica, nil
+}
+
 func (lvm *lvmExecutor) DeleteVolumeReplica(replica *apisv1alpha1.LocalVolumeReplica) error {
 	// for compatibility
 	storagePath := replica.Status.StoragePath
@@ -414,6 +491,11 @@ func (lvm *lvmExecutor) TestVolumeReplica(replica *apisv1alpha1.LocalVolumeRepli
 	if err != nil {
 		return nil, err
 	}
+	if replica.Spec.Mirror {
+		if status.disks, err = lvm.mirrorDisks(replica.Spec.VolumeName); err != nil {
+			return nil, err
+		}
+	}
 	newReplica := replica.DeepCopy()
 	newReplica.Status.Synced = true
 	newReplica.Status.Disks = status.disks
@@ -513,6 +595,95 @@ func (lvm *lvmExecutor) ExtendThinPool(tpc *apisv1alpha1.ThinPoolClaim) error {
 	}
 }
 
+// AutoExtendThinPool extends the thin pool by the policy, and records the failure for the scheduler
+// to stop allocating thin volumes in the pool
+func (lvm *lvmExecutor) AutoExtendThinPool(poolName string, policy *apisv1alpha1.ThinPoolAutoExtendPolicy) (bool, error) {
+	extended, err := lvm.autoExtendThinPool(poolName, policy)
+
+	lvm.extendLock.Lock()
+	defer lvm.extendLock.Unlock()
+	if lvm.thinPoolExtendFailures == nil {
+		lvm.thinPoolExtendFailures = map[string]string{}
+	}
+	if err != nil {
+		lvm.thinPoolExtendFailures[poolName] = err.Error()
+	} else {
+		delete(lvm.thinPoolExtendFailures, poolName)
+	}
+	return extended, err
+}
+
+// getThinPoolExtendFailure returns the reason why the thin pool can't be extended, empty if no failure
+func (lvm *lvmExecutor) getThinPoolExtendFailure(poolName string) string {
+	lvm.extendLock.Lock()
+	defer lvm.extendLock.Unlock()
+	return lvm.thinPoolExtendFailures[poolName]
+}
+
+func (lvm *lvmExecutor) autoExtendThinPool(poolName string, policy *apisv1alpha1.ThinPoolAutoExtendPolicy) (bool, error) {
+	lv, err := lvm.lvRecord(apisv1alpha1.ThinPoolName, poolName)
+	if err != nil {
+		// no thin pool to extend
+		return false, nil
+	}
+	lvmStatus, err := lvm.getLVMStatus(VGMask)
+	if err != nil {
+		return false, err
+	}
+	vgFreeBytes, err := utils.ConvertLVMBytesToNumeric(lvmStatus.vgs[poolName].VgFreeByte)
+	if err != nil {
+		return false, err
+	}
+
+	options, err := thinPoolAutoExtendOptions(lv, vgFreeBytes, policy)
+	if err != nil || len(options) == 0 {
+		return false, err
+	}
+	lvm.logger.WithFields(log.Fields{"pool": poolName, "dataPercent": lv.DataPercent, "metadataPercent": lv.MetadataPercent, "options": options}).Info("Extending thin pool automatically")
+	if err := lvm.thinPoolExtend(poolName, apisv1alpha1.ThinPoolName, options); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// thinPoolAutoExtendOptions returns the lvextend options to extend the data and metadata of the thin pool
+// whose usage reaches the threshold, or ErrorThinPoolExtendImpossible if it can't be extended any more
+func thinPoolAutoExtendOptions(lv *lvRecord, vgFreeBytes int64, policy *apisv1alpha1.ThinPoolAutoExtendPolicy) ([]string, error) {
+	dataSize, err := utils.ConvertLVMBytesToNumeric(lv.LvCapacity)
+	if err != nil {
+		return nil, err
+	}
+	dataPercent, _ := strconv.ParseFloat(lv.DataPercent, 64)
+	metadataPercent, _ := strconv.ParseFloat(lv.MetadataPercent, 64)
+	threshold := float64(policy.ThresholdPercent)
+
+	options := []string{}
+	requiredBytes := int64(0)
+	if dataPercent >= threshold {
+		step := utils.NumericToLVMBytes(dataSize * policy.ExtendPercent / 100)
+		if policy.MaxCapacity > 0 && dataSize+step > policy.MaxCapacity*utils.Gi {
+			step = policy.MaxCapacity*utils.Gi - dataSize
+		}
+		if step <= 0 {
+			return nil, fmt.Errorf("%w: data usage %s%% reaches the threshold, and the size reaches the max capacity %dGi",
+				ErrorThinPoolExtendImpossible, lv.DataPercent, policy.MaxCapacity)
+		}
+		requiredBytes += step
+		options = append(options, fmt.Sprintf("--size=+%s", utils.ConvertNumericToLVMBytes(step)))
+	}
+	if metadataPercent >= threshold {
+		step := policy.MetadataExtendSize * utils.Gi
+		// the spare metadata LV is extended together
+		requiredBytes += 2 * step
+		options = append(options, fmt.Sprintf("--poolmetadatasize=+%dG", policy.MetadataExtendSize))
+	}
+	if requiredBytes > vgFreeBytes {
+		return nil, fmt.Errorf("%w: usage reaches the threshold, requires %d bytes but only %d bytes free in the pool",
+			ErrorThinPoolExtendImpossible, requiredBytes, vgFreeBytes)
+	}
+	return options, nil
+}
+
 func (lvm *lvmExecutor) ResizePhysicalVolumes(localDevices map[string]*apisv1alpha1.LocalDevice) error {
 	lvm.logger.Debugf("Resizing pvsize, device(s): %+v, count: %d", localDevicesMap(localDevices).string(), len(localDevices))
 
@@ -526,6 +697,199 @@ func (lvm *lvmExecutor) ResizePhysicalVolumes(localDevices map[string]*apisv1alp
 	return nil
 }
 
+func (lvm *lvmExecutor) GetPhysicalVolumeUsage(poolName string, devPath string) (*PhysicalVolumeUsage, error) {
+	pvsReport, err := lvm.pvs()
+	if err != nil {
+		return nil, err
+	}
+	lvsReport, err := lvm.lvs()
+	if err != nil {
+		return nil, err
+	}
+	return physicalVolumeUsage(pvsReport, lvsReport, poolName, devPath)
+}
+
+// physicalVolumeUsage figures out the allocated size of the PV, the free size of the other PVs in the same VG,
+// and the LVs which have extents on the PV
+func physicalVolumeUsage(pvsReport *pvsReport, lvsReport *lvsReport, poolName string, devPath string) (*PhysicalVolumeUsage, error) {
+	usage := &PhysicalVolumeUsage{PoolName: poolName, DevPath: devPath, Movable: true}
+
+	found := false
+	for _, pvsReportRecords := range pvsReport.Records {
+		for _, pv := range pvsReportRecords.Records {
+			if pv.PoolName != poolName {
+				continue
+			}
+			size, err := utils.ConvertLVMBytesToNumeric(pv.PvSize)
+			if err != nil {
+				return nil, err
+			}
+			free, err := utils.ConvertLVMBytesToNumeric(pv.PvFree)
+			if err != nil {
+				return nil, err
+			}
+			if pv.Name == devPath {
+				found = true
+				usage.UsedBytes = size - free
+			} else {
+				usage.FreeBytesOnOthers += free
+			}
+		}
+	}
+	if !found {
+		return nil, ErrorPhysicalVolumeNotFound
+	}
+
+	replicas := sets.NewString()
+	thinPoolOnDisk := false
+	thinVolumes := []string{}
+	for _, lvsReportRecords := range lvsReport.Records {
+		for _, lv := range lvsReportRecords.Records {
+			if lv.PoolName != poolName {
+				continue
+			}
+			name := strings.TrimSuffix(strings.TrimPrefix(lv.Name, "["), "]")
+			// thin volumes have no extents of their own, they are allocated from the thin pool
+			if len(lv.ThinPoolName) > 0 {
+				thinVolumes = append(thinVolumes, name)
+			}
+			if !lv.Disks.Has(devPath) {
+				continue
+			}
+			switch {
+			case raidSubLVNameRegex.MatchString(name):
+				// pvmove doesn't work on the images of a raid LV
+				replicas.Insert(raidSubLVNameRegex.FindStringSubmatch(name)[1])
+				usage.Movable = false
+			case strings.HasPrefix(name, apisv1alpha1.ThinPoolName):
+				thinPoolOnDisk = true
+			case strings.Contains(name, "pmspare"), strings.HasPrefix(name, "pvmove"):
+			default:
+				replicas.Insert(name)
+			}
+		}
+	}
+	if thinPoolOnDisk {
+		replicas.Insert(thinVolumes...)
+	}
+	usage.Replicas = replicas.List()
+
+	return usage, nil
+}
+
+func (lvm *lvmExecutor) EvacuatePhysicalVolume(poolName string, devPath string) error {
+	lvm.logger.WithFields(log.Fields{"pool": poolName, "disk": devPath}).Info("Moving the extents out of the disk")
+
+	// pvmove polls the progress in background, and resumes the interrupted move of the PV if any
+	params := exechelper.ExecParams{
+		CmdName: "pvmove",
+		CmdArgs: []string{"--background", devPath, "-y"},
+	}
+	res := lvm.cmdExec.RunCommand(params)
+	if res.ExitCode == 0 || strings.Contains(res.ErrBuf.String(), "No data to move") {
+		return nil
+	}
+	return res.Error
+}
+
+// GetPhysicalVolumeEvacuation checks the pvmove LV which is moving the extents out of the PV
+func (lvm *lvmExecutor) GetPhysicalVolumeEvacuation(poolName string, devPath string) (*PhysicalVolumeEvacuation, error) {
+	lvsReport, err := lvm.lvs()
+	if err != nil {
+		return nil, err
+	}
+	return physicalVolumeEvacuation(lvsReport, poolName, devPath), nil
+}
+
+func physicalVolumeEvacuation(lvsReport *lvsReport, poolName string, devPath string) *PhysicalVolumeEvacuation {
+	for _, lvsReportRecords := range lvsReport.Records {
+		for _, lv := range lvsReportRecords.Records {
+			if lv.PoolName == poolName && lv.MovePV == devPath {
+				return &PhysicalVolumeEvacuation{InProgress: true, Percent: lv.CopyPercent}
+			}
+		}
+	}
+	return &PhysicalVolumeEvacuation{}
+}
+
+// AbortPhysicalVolumeEvacuation aborts the pvmove of the PV, the extents moved already are left on the other PVs
+func (lvm *lvmExecutor) AbortPhysicalVolumeEvacuation(poolName string, devPath string) error {
+	evacuation, err := lvm.GetPhysicalVolumeEvacuation(poolName, devPath)
+	if err != nil {
+		return err
+	}
+	if !evacuation.InProgress {
+		return nil
+	}
+	lvm.logger.WithFields(log.Fields{"pool": poolName, "disk": devPath, "percent": evacuation.Percent}).Info("Aborting the move of the extents")
+
+	params := exechelper.ExecParams{
+		CmdName: "pvmove",
+		CmdArgs: []string{"--abort", devPath},
+	}
+	res := lvm.cmdExec.RunCommand(params)
+	if res.ExitCode == 0 {
+		return nil
+	}
+	return res.Error
+}
+
+func (lvm *lvmExecutor) RemovePhysicalVolume(poolName string, devPath string) error {
+	lvm.logger.WithFields(log.Fields{"pool": poolName, "disk": devPath}).Info("Removing the disk from the pool")
+
+	lvmStatus, err := lvm.getLVMStatus(VGMask | PVMask)
+	if err != nil {
+		return err
+	}
+	pv, exists := lvmStatus.pvs[devPath]
+	if !exists {
+		// removed already
+		return nil
+	}
+	if pv.PoolName == poolName {
+		if len(lvmStatus.getPVsByVGName(poolName)) > 1 {
+			err = lvm.vgreduce(poolName, devPath)
+		} else {
+			// the final PV can't be reduced from the VG, so remove the VG instead,
+			// but never with the LVs left in it, e.g. a thin pool
+			if vg, exists := lvmStatus.vgs[poolName]; exists && vg.LvCount != "0" {
+				lvm.logger.WithFields(log.Fields{"pool": poolName, "lvCount": vg.LvCount}).Error("Logical volumes are left in the pool")
+				return ErrorPhysicalVolumeInUse
+			}
+			err = lvm.vgremove(poolName)
+		}
+		if err != nil {
+			lvm.logger.WithError(err).Errorf("Failed to reduce %s from %s", devPath, poolName)
+			return err
+		}
+	}
+	return lvm.pvremove(devPath)
+}
+
+// pvAllocatable checks the first char of pv_attr, e.g. "a--" for an allocatable PV
+func pvAllocatable(pvAttr string) bool {
+	return len(pvAttr) == 0 || pvAttr[0] == 'a'
+}
+
+// SetPhysicalVolumeAllocatable marks the PV (non-)allocatable, no more extents are allocated on a non-allocatable PV
+func (lvm *lvmExecutor) SetPhysicalVolumeAllocatable(poolName string, devPath string, allocatable bool) error {
+	lvm.logger.WithFields(log.Fields{"pool": poolName, "disk": devPath, "allocatable": allocatable}).Info("Changing the allocation of the disk")
+
+	flag := "n"
+	if allocatable {
+		flag = "y"
+	}
+	params := exechelper.ExecParams{
+		CmdName: "pvchange",
+		CmdArgs: []string{"-x", flag, devPath},
+	}
+	res := lvm.cmdExec.RunCommand(params)
+	if res.ExitCode == 0 {
+		return nil
+	}
+	return res.Error
+}
+
 func (lvm *lvmExecutor) ConsistencyCheck(crdReplicas map[string]*apisv1alpha1.LocalVolumeReplica) {
 
 	lvm.logger.Debug("Consistency Checking for LVM volume ...")
@@ -582,9 +946,148 @@ func (lvm *lvmExecutor) ConsistencyCheck(crdReplicas map[string]*apisv1alpha1.Lo
 		}
 	}
 
+	lvm.checkMirroredVolumeReplicas(crdReplicas)
+
 	lvm.logger.Debug("Consistency check completed")
 }
 
+// checkMirroredVolumeReplicas reports the sync state of the mirrored volume replicas as the replica conditions,
+// and repairs the one with a failed disk by allocating a new image on the spare disks in the pool
+func (lvm *lvmExecutor) checkMirroredVolumeReplicas(crdReplicas map[string]*apisv1alpha1.LocalVolumeReplica) {
+	lvmStatus, err := lvm.getLVMStatus(LVMask)
+	if err != nil {
+		lvm.logger.WithError(err).Error("Failed to query LV stats.")
+		return
+	}
+
+	for volName, crd := range crdReplicas {
+		lv, exists := lvmStatus.lvs[volName]
+		if !crd.Spec.Mirror || !exists {
+			continue
+		}
+		logCtx := lvm.logger.WithFields(log.Fields{"volume": volName, "syncPercent": lv.SyncPercent, "mismatches": lv.RaidMismatches, "health": lv.HealthStatus})
+		logCtx.Debug("Checking mirrored volume replica")
+
+		replica := crd.DeepCopy()
+		meta.SetStatusCondition(&replica.Status.Conditions, mirrorSyncCondition(&lv))
+
+		// a raid image is lost with the failed disk
+		if lv.HealthStatus == "partial" {
+			logCtx.Warning("Repairing mirrored volume replica with a failed disk")
+			condition := metav1.Condition{
+				Type:   apisv1alpha1.VolumeReplicaConditionMirrorRepair,
+				Status: metav1.ConditionTrue,
+				Reason: "Repaired",
+			}
+			if err := lvm.lvconvert("--repair", "-y", fmt.Sprintf("%s/%s", lv.PoolName, lv.Name)); err != nil {
+				logCtx.WithError(err).Error("Failed to repair mirrored volume replica, no spare disk in the pool?")
+				condition.Status = metav1.ConditionFalse
+				condition.Reason = "RepairFailed"
+				condition.Message = err.Error()
+			}
+			meta.SetStatusCondition(&replica.Status.Conditions, condition)
+		}
+
+		if reflect.DeepEqual(replica.Status.Conditions, crd.Status.Conditions) {
+			continue
+		}
+		if err := lvm.lm.apiClient.Status().Update(context.TODO(), replica); err != nil {
+			logCtx.WithError(err).Error("Failed to update conditions of mirrored volume replica")
+		}
+	}
+}
+
+// mirrorSyncCondition converts the sync state of the raid LV into the replica condition
+func mirrorSyncCondition(lv *lvRecord) metav1.Condition {
+	syncPercent, _ := strconv.ParseFloat(lv.SyncPercent, 64)
+	mismatches, _ := strconv.ParseInt(lv.RaidMismatches, 10, 64)
+	condition := metav1.Condition{
+		Type:    apisv1alpha1.VolumeReplicaConditionMirrorSync,
+		Status:  metav1.ConditionFalse,
+		Message: fmt.Sprintf("sync %s%%, %d mismatches", lv.SyncPercent, mismatches),
+	}
+	switch {
+	case len(lv.HealthStatus) > 0:
+		condition.Reason = "Unhealthy"
+		condition.Message = fmt.Sprintf("%s, health status: %s", condition.Message, lv.HealthStatus)
+	case syncPercent < 100:
+		condition.Reason = "Syncing"
+	case mismatches > 0:
+		condition.Reason = "Mismatched"
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Synced"
+	}
+	return condition
+}
+
+// mirrorDisks returns the disks of the mirrored volume replica,
+// "lvdisplay -m" only shows the raid images of it rather than the disks
+func (lvm *lvmExecutor) mirrorDisks(lvName string) ([]string, error) {
+	lvmStatus, err := lvm.getLVMStatus(LVMask)
+	if err != nil {
+		return nil, err
+	}
+	lv, exists := lvmStatus.lvs[lvName]
+	if !exists {
+		return nil, ErrReplicaNotFound
+	}
+	return lv.Disks.List(), nil
+}
+
+// startRaidCheck starts to scrub the mirrored volume replica by reading and comparing all the raid images,
+// the mismatches are counted rather than repaired
+func (lvm *lvmExecutor) startRaidCheck(replica *apisv1alpha1.LocalVolumeReplica) error {
+	params := exechelper.ExecParams{
+		CmdName: "lvchange",
+		CmdArgs: []string{"--syncaction", "check", fmt.Sprintf("%s/%s", replica.Spec.PoolName, replica.Spec.VolumeName)},
+	}
+	res := lvm.cmdExec.RunCommand(params)
+	if res.ExitCode != 0 {
+		lvm.logger.WithError(res.Error).Error("Failed to start raid check")
+		return res.Error
+	}
+	return nil
+}
+
+// stopRaidCheck stops the running raid check of the mirrored volume replica
+func (lvm *lvmExecutor) stopRaidCheck(replica *apisv1alpha1.LocalVolumeReplica) error {
+	params := exechelper.ExecParams{
+		CmdName: "lvchange",
+		CmdArgs: []string{"--syncaction", "idle", fmt.Sprintf("%s/%s", replica.Spec.PoolName, replica.Spec.VolumeName)},
+	}
+	res := lvm.cmdExec.RunCommand(params)
+	if res.ExitCode != 0 {
+		lvm.logger.WithError(res.Error).Error("Failed to stop raid check")
+		return res.Error
+	}
+	return nil
+}
+
+// getRaidCheckState returns whether the raid check of the mirrored volume replica is still running,
+// and the number of the mismatches found
+func (lvm *lvmExecutor) getRaidCheckState(replica *apisv1alpha1.LocalVolumeReplica) (bool, int64, error) {
+	lvmStatus, err := lvm.getLVMStatus(LVMask)
+	if err != nil {
+		return false, 0, err
+	}
+	lv, exists := lvmStatus.lvs[replica.Spec.VolumeName]
+	if !exists {
+		return false, 0, ErrReplicaNotFound
+	}
+	return raidCheckState(&lv)
+}
+
+func raidCheckState(lv *lvRecord) (bool, int64, error) {
+	if !strings.HasPrefix(lv.Segtype, "raid") {
+		return false, 0, fmt.Errorf("volume %s is not a raid LV", lv.Name)
+	}
+	mismatches, _ := strconv.ParseInt(lv.RaidMismatches, 10, 64)
+	// the sync action is "idle" when finished, or "check", "resync", "recover" etc. while running
+	running := len(lv.RaidSyncAction) > 0 && lv.RaidSyncAction != "idle"
+	return running, mismatches, nil
+}
+
 // CreateVolumeReplicaSnapshot creates a new COW volume replica snapshot
 func (lvm *lvmExecutor) CreateVolumeReplicaSnapshot(replicaSnapshot *apisv1alpha1.LocalVolumeReplicaSnapshot) error {
 	lvm.lock.Lock()
@@ -619,6 +1122,12 @@ func (lvm *lvmExecutor) CreateVolumeReplicaSnapshot(replicaSnapshot *apisv1alpha
 		}
 	}
 
+	// the dirty blocks in the writeback cache must be in the snapshot
+	if err := lvm.flushVolumeCache(replicaSnapshot.Spec.SourceVolume); err != nil {
+		logCtx.WithError(err).Error("Failed to flush the cache of the source volume")
+		return err
+	}
+
 	// use volume snapshot name as snapshot volume key - avoid duplicate volume replica snapshot with the same snapshot
 	if err = lvm.lvSnapCreate(replicaSnapshot.Spec.VolumeSnapshotName, path.Join(replicaSnapshot.Spec.PoolName, replicaSnapshot.Spec.SourceVolume),
 		replicaSnapshot.Spec.RequiredCapacityBytes, isOriginThin); err != nil {
@@ -720,6 +1229,13 @@ func (lvm *lvmExecutor) RollbackVolumeReplicaSnapshot(snapshotRestore *apisv1alp
 		return err
 	}
 
+	// the cached blocks are stale after the merge, so the cache is removed and attached again as a new one.
+	// It also releases the LV of the volume for the merge
+	if err := lvm.removeVolumeCache(replicaSnapshot.Spec.SourceVolume, true); err != nil {
+		logCtx.WithError(err).Error("Failed to remove the cache of the source volume")
+		return err
+	}
+
 	var options = []string{"--merge", fmt.Sprintf("%s/%s", replicaSnapshot.Spec.PoolName, replicaSnapshot.Spec.VolumeSnapshotName)}
 
 	// lvconvert --merge LocalStorage_PoolHDD/snapshot-name
@@ -828,6 +1344,11 @@ func (lvm *lvmExecutor) GetThinPools() (map[string]*apisv1alpha1.ThinPoolInfo, e
 		}
 
 		thinPools[k].State = state
+
+		// the failure is kept only while the autoextend policy is in effect
+		if utils.GetThinPoolAutoExtendPolicy(lsn.Status.ThinPoolExtendRecords[k]) != nil {
+			thinPools[k].ExtendFailure = lvm.getThinPoolExtendFailure(k)
+		}
 	}
 
 	return thinPools, nil
@@ -938,6 +1459,42 @@ func (lvm *lvmExecutor) vgextend(vgName string, pvs []string) error {
 
 }
 
+func (lvm *lvmExecutor) vgreduce(vgName string, pv string) error {
+	params := exechelper.ExecParams{
+		CmdName: "vgreduce",
+		CmdArgs: []string{vgName, pv, "-y"},
+	}
+	res := lvm.cmdExec.RunCommand(params)
+	if res.ExitCode == 0 {
+		return nil
+	}
+	return res.Error
+}
+
+func (lvm *lvmExecutor) vgremove(vgName string) error {
+	params := exechelper.ExecParams{
+		CmdName: "vgremove",
+		CmdArgs: []string{vgName},
+	}
+	res := lvm.cmdExec.RunCommand(params)
+	if res.ExitCode == 0 {
+		return nil
+	}
+	return res.Error
+}
+
+func (lvm *lvmExecutor) pvremove(pv string) error {
+	params := exechelper.ExecParams{
+		CmdName: "pvremove",
+		CmdArgs: []string{pv, "-y"},
+	}
+	res := lvm.cmdExec.RunCommand(params)
+	if res.ExitCode == 0 {
+		return nil
+	}
+	return res.Error
+}
+
 // func (lvm *lvmExecutor) vgdisplay(vgName string) (*vgStatus, error) {
 // 	params := exechelper.ExecParams{
 // 		CmdName: "vgdisplay",
@@ -1098,7 +1655,7 @@ func (lvm *lvmExecutor) lvs() (*lvsReport, error) {
 	params := exechelper.ExecParams{
 		CmdName: "lvs",
 		CmdArgs: []string{"-a", "-o", "lv_path,lv_name,vg_name,lv_attr,lv_size,pool_lv,origin,data_percent,metadata_percent,move_pv,mirror_log,copy_percent,convert_lv," +
-			"lv_snapshot_invalid,lv_merge_failed,snap_percent,lv_device_open,lv_merging,lv_converting,lv_time,segtype,lv_metadata_size,devices", "--reportformat", "json", "--units", "B"},
+			"lv_snapshot_invalid,lv_merge_failed,snap_percent,lv_device_open,lv_merging,lv_converting,lv_time,segtype,lv_metadata_size,devices,sync_percent,raid_mismatch_count,lv_health_status,raid_sync_action", "--reportformat", "json", "--units", "B"},
 	}
 	res := lvm.cmdExec.RunCommand(params)
 	if res.ExitCode != 0 {
@@ -1186,6 +1743,18 @@ func (lvm *lvmExecutor) lvextend(lvPath string, newCapacityBytes int64, options
 	return res.Error
 }
 
+func (lvm *lvmExecutor) lvreduce(lvPath string, newCapacityBytes int64) error {
+	params := exechelper.ExecParams{
+		CmdName: "lvreduce",
+		CmdArgs: []string{"--size", fmt.Sprintf("%db", newCapacityBytes), lvPath, "--force"},
+	}
+	res := lvm.cmdExec.RunCommand(params)
+	if res.ExitCode == 0 || strings.Contains(res.ErrBuf.String(), "matches existing size") {
+		return nil
+	}
+	return fmt.Errorf("lvreduce %s: %v, %s", lvPath, res.Error, res.ErrBuf.String())
+}
+
 func (lvm *lvmExecutor) pvresize(pv string, options ...string) error {
 	params := exechelper.ExecParams{
 		CmdName: "pvresize",
@@ -1269,6 +1838,7 @@ func (lvm *lvmExecutor) getLVMStatus(masks int) (*lvmStatus, error) {
 			lvm.logger.WithError(err).Error("Failed to get LVM (lv) status.")
 			return nil, err
 		}
+		raidSubLVs := []lvRecord{}
 		for _, lvsReportRecords := range lvsReport.Records {
 			for _, lvRecord := range lvsReportRecords.Records {
 				// for merging snapshot: "lv_name":"[snapcontent-49246190-f939-4e17-912a-394ddc088299]"
@@ -1277,6 +1847,12 @@ func (lvm *lvmExecutor) getLVMStatus(masks int) (*lvmStatus, error) {
 					lvRecord.Name = strings.TrimSuffix(lvRecord.Name, "]")
 				}
 
+				// filter the images and metadata of the mirrored volume
+				if raidSubLVNameRegex.MatchString(lvRecord.Name) {
+					raidSubLVs = append(raidSubLVs, lvRecord)
+					continue
+				}
+
 				// filter thin pool
 				if strings.HasPrefix(lvRecord.Name, apisv1alpha1.ThinPoolName) {
 					continue
@@ -1290,6 +1866,7 @@ func (lvm *lvmExecutor) getLVMStatus(masks int) (*lvmStatus, error) {
 				status.lvs[lvRecord.Name] = lvRecord
 			}
 		}
+		mergeRaidSubLVRecords(status.lvs, raidSubLVs)
 	}
 
 	if masks&VGMask == VGMask {
@@ -1323,6 +1900,25 @@ func (lvm *lvmExecutor) getLVMStatus(masks int) (*lvmStatus, error) {
 	return status, nil
 }
 
+// mergeRaidSubLVRecords merges the disks of the raid images into the raid LV,
+// as the devices of the raid LV are the images rather than the disks
+func mergeRaidSubLVRecords(lvs map[string]lvRecord, raidSubLVs []lvRecord) {
+	for name, lv := range lvs {
+		if strings.HasPrefix(lv.Segtype, "raid") {
+			lv.Disks = sets.NewString()
+			lvs[name] = lv
+		}
+	}
+	for _, subLV := range raidSubLVs {
+		matches := raidSubLVNameRegex.FindStringSubmatch(subLV.Name)
+		lv, exists := lvs[matches[1]]
+		if !exists || matches[2] != "image" || lv.Disks == nil {
+			continue
+		}
+		lv.Disks.Insert(subLV.Disks.UnsortedList()...)
+	}
+}
+
 func (lvm *lvmExecutor) lvconvert(options ...string) error {
 	params := exechelper.ExecParams{
 		CmdName: "lvconvert",
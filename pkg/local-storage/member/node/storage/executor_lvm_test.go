@@ -1,10 +1,14 @@
 package storage
 
 import (
+	"errors"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/golang/mock/gomock"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
 
 	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 )
@@ -230,3 +234,286 @@ func Test_lvmExecutor_ConsistencyCheck(t *testing.T) {
 	m.ConsistencyCheck(lvrmap)
 	fmt.Printf("Test_lvmExecutor_ConsistencyCheck ends")
 }
+
+func Test_mergeRaidSubLVRecords(t *testing.T) {
+	lvs := map[string]lvRecord{
+		"pvc-1": {Name: "pvc-1", Segtype: "raid1", Disks: sets.NewString("pvc-1_rimage_0")},
+		"pvc-2": {Name: "pvc-2", Segtype: "linear", Disks: sets.NewString("/dev/sdd")},
+	}
+	raidSubLVs := []lvRecord{
+		{Name: "pvc-1_rimage_0", Disks: sets.NewString("/dev/sdb")},
+		{Name: "pvc-1_rmeta_0", Disks: sets.NewString("/dev/sdb")},
+		{Name: "pvc-1_rimage_1", Disks: sets.NewString("/dev/sdc")},
+		{Name: "pvc-1_rmeta_1", Disks: sets.NewString("/dev/sdc")},
+		{Name: "pvc-3_rimage_0", Disks: sets.NewString("/dev/sde")},
+	}
+	mergeRaidSubLVRecords(lvs, raidSubLVs)
+
+	want := map[string][]string{
+		"pvc-1": {"/dev/sdb", "/dev/sdc"},
+		"pvc-2": {"/dev/sdd"},
+	}
+	for name, disks := range want {
+		if got := lvs[name].Disks.List(); !reflect.DeepEqual(got, disks) {
+			t.Errorf("mergeRaidSubLVRecords() got disks %v of %s, want %v", got, name, disks)
+		}
+	}
+	if !raidSubLVNameRegex.MatchString("pvc-1_rimage_0_extracted") || raidSubLVNameRegex.MatchString("pvc-1") {
+		t.Errorf("raidSubLVNameRegex doesn't match the raid images as expected")
+	}
+}
+
+func Test_mirrorSyncCondition(t *testing.T) {
+	tests := []struct {
+		name       string
+		lv         lvRecord
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		{
+			name:       "synced",
+			lv:         lvRecord{SyncPercent: "100.00", RaidMismatches: "0"},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: "Synced",
+		},
+		{
+			name:       "syncing",
+			lv:         lvRecord{SyncPercent: "45.12", RaidMismatches: "0"},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "Syncing",
+		},
+		{
+			name:       "mismatched",
+			lv:         lvRecord{SyncPercent: "100.00", RaidMismatches: "8"},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "Mismatched",
+		},
+		{
+			name:       "failed disk",
+			lv:         lvRecord{SyncPercent: "100.00", RaidMismatches: "0", HealthStatus: "partial"},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: "Unhealthy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mirrorSyncCondition(&tt.lv)
+			if got.Type != apisv1alpha1.VolumeReplicaConditionMirrorSync || got.Status != tt.wantStatus || got.Reason != tt.wantReason {
+				t.Errorf("mirrorSyncCondition() = %+v, want status %s, reason %s", got, tt.wantStatus, tt.wantReason)
+			}
+		})
+	}
+}
+
+func Test_raidCheckState(t *testing.T) {
+	tests := []struct {
+		name           string
+		lv             lvRecord
+		wantRunning    bool
+		wantMismatches int64
+		wantErr        bool
+	}{
+		{
+			name:        "checking",
+			lv:          lvRecord{Name: "pvc-1", Segtype: "raid1", RaidSyncAction: "check", RaidMismatches: "0"},
+			wantRunning: true,
+		},
+		{
+			name:           "idle with mismatches",
+			lv:             lvRecord{Name: "pvc-1", Segtype: "raid1", RaidSyncAction: "idle", RaidMismatches: "16"},
+			wantMismatches: 16,
+		},
+		{
+			name:    "not raid",
+			lv:      lvRecord{Name: "pvc-1", Segtype: "linear"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			running, mismatches, err := raidCheckState(&tt.lv)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("raidCheckState() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if running != tt.wantRunning || mismatches != tt.wantMismatches {
+				t.Errorf("raidCheckState() = %v, %d, want %v, %d", running, mismatches, tt.wantRunning, tt.wantMismatches)
+			}
+		})
+	}
+}
+
+func Test_pvAllocatable(t *testing.T) {
+	tests := []struct {
+		name   string
+		pvAttr string
+		want   bool
+	}{
+		{name: "allocatable", pvAttr: "a--", want: true},
+		{name: "non-allocatable", pvAttr: "u--", want: false},
+		{name: "non-allocatable and missing", pvAttr: "--m", want: false},
+		{name: "unknown", pvAttr: "", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pvAllocatable(tt.pvAttr); got != tt.want {
+				t.Errorf("pvAllocatable(%q) = %v, want %v", tt.pvAttr, got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_physicalVolumeUsage(t *testing.T) {
+	pvs := &pvsReport{Records: []pvsReportRecord{{Records: []pvRecord{
+		{Name: "/dev/sdb", PoolName: "LocalStorage_PoolHDD", PvSize: "1000B", PvFree: "400B"},
+		{Name: "/dev/sdc", PoolName: "LocalStorage_PoolHDD", PvSize: "1000B", PvFree: "700B"},
+		{Name: "/dev/sdd", PoolName: "LocalStorage_PoolSSD", PvSize: "1000B", PvFree: "1000B"},
+	}}}}
+	lvs := &lvsReport{Records: []lvsReportRecord{{Records: []lvRecord{
+		{Name: "pvc-1", PoolName: "LocalStorage_PoolHDD", Disks: sets.NewString("/dev/sdb", "/dev/sdc")},
+		{Name: "pvc-2", PoolName: "LocalStorage_PoolHDD", Disks: sets.NewString("/dev/sdc")},
+		{Name: "[lvol0_pmspare]", PoolName: "LocalStorage_PoolHDD", Disks: sets.NewString("/dev/sdb")},
+		{Name: "[LocalStorage_ThinPool_tdata]", PoolName: "LocalStorage_PoolHDD", Disks: sets.NewString("/dev/sdb")},
+		{Name: "pvc-3", PoolName: "LocalStorage_PoolHDD", ThinPoolName: apisv1alpha1.ThinPoolName},
+		{Name: "pvc-4", PoolName: "LocalStorage_PoolHDD", Segtype: "raid1"},
+		{Name: "[pvc-4_rimage_0]", PoolName: "LocalStorage_PoolHDD", Disks: sets.NewString("/dev/sdc")},
+		{Name: "pvc-5", PoolName: "LocalStorage_PoolSSD", Disks: sets.NewString("/dev/sdd")},
+		{Name: "[pvmove0]", PoolName: "LocalStorage_PoolHDD", MovePV: "/dev/sdb", Disks: sets.NewString("/dev/sdb", "/dev/sdc")},
+	}}}}
+
+	tests := []struct {
+		name    string
+		devPath string
+		want    *PhysicalVolumeUsage
+		wantErr error
+	}{
+		{
+			name:    "linear and thin volumes",
+			devPath: "/dev/sdb",
+			want: &PhysicalVolumeUsage{PoolName: "LocalStorage_PoolHDD", DevPath: "/dev/sdb", UsedBytes: 600, FreeBytesOnOthers: 700,
+				Replicas: []string{"pvc-1", "pvc-3"}, Movable: true},
+		},
+		{
+			name:    "mirrored volume",
+			devPath: "/dev/sdc",
+			want: &PhysicalVolumeUsage{PoolName: "LocalStorage_PoolHDD", DevPath: "/dev/sdc", UsedBytes: 300, FreeBytesOnOthers: 400,
+				Replicas: []string{"pvc-1", "pvc-2", "pvc-4"}, Movable: false},
+		},
+		{
+			name:    "not in the pool",
+			devPath: "/dev/sdd",
+			wantErr: ErrorPhysicalVolumeNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := physicalVolumeUsage(pvs, lvs, "LocalStorage_PoolHDD", tt.devPath)
+			if err != tt.wantErr {
+				t.Fatalf("physicalVolumeUsage() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("physicalVolumeUsage() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_physicalVolumeEvacuation(t *testing.T) {
+	lvs := &lvsReport{Records: []lvsReportRecord{{Records: []lvRecord{
+		{Name: "pvc-1", PoolName: "LocalStorage_PoolHDD", Disks: sets.NewString("/dev/sdb")},
+		{Name: "[pvmove0]", PoolName: "LocalStorage_PoolHDD", MovePV: "/dev/sdb", CopyPercent: "45.20", Disks: sets.NewString("/dev/sdb", "/dev/sdc")},
+	}}}}
+
+	tests := []struct {
+		name    string
+		devPath string
+		want    *PhysicalVolumeEvacuation
+	}{
+		{name: "moving", devPath: "/dev/sdb", want: &PhysicalVolumeEvacuation{InProgress: true, Percent: "45.20"}},
+		{name: "not moving", devPath: "/dev/sdc", want: &PhysicalVolumeEvacuation{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := physicalVolumeEvacuation(lvs, "LocalStorage_PoolHDD", tt.devPath); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("physicalVolumeEvacuation() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_thinPoolAutoExtendOptions(t *testing.T) {
+	policy := &apisv1alpha1.ThinPoolAutoExtendPolicy{ThresholdPercent: 80, ExtendPercent: 20, MetadataExtendSize: 1}
+	limitedPolicy := &apisv1alpha1.ThinPoolAutoExtendPolicy{ThresholdPercent: 80, ExtendPercent: 20, MaxCapacity: 11, MetadataExtendSize: 1}
+	maxedPolicy := &apisv1alpha1.ThinPoolAutoExtendPolicy{ThresholdPercent: 80, ExtendPercent: 20, MaxCapacity: 10, MetadataExtendSize: 1}
+	thinPool := func(dataPercent, metadataPercent string) *lvRecord {
+		return &lvRecord{LvCapacity: "10737418240B", DataPercent: dataPercent, MetadataPercent: metadataPercent}
+	}
+
+	tests := []struct {
+		name        string
+		lv          *lvRecord
+		vgFreeBytes int64
+		policy      *apisv1alpha1.ThinPoolAutoExtendPolicy
+		want        []string
+		wantErr     bool
+	}{
+		{
+			name:        "below threshold",
+			lv:          thinPool("50.00", "10.00"),
+			vgFreeBytes: 100 * 1024 * 1024 * 1024,
+			policy:      policy,
+			want:        []string{},
+		},
+		{
+			name:        "extend data",
+			lv:          thinPool("80.00", "10.00"),
+			vgFreeBytes: 100 * 1024 * 1024 * 1024,
+			policy:      policy,
+			want:        []string{"--size=+2147483648B"},
+		},
+		{
+			name:        "extend data and metadata",
+			lv:          thinPool("90.00", "85.00"),
+			vgFreeBytes: 100 * 1024 * 1024 * 1024,
+			policy:      policy,
+			want:        []string{"--size=+2147483648B", "--poolmetadatasize=+1G"},
+		},
+		{
+			name:        "extend data up to the max capacity",
+			lv:          thinPool("90.00", "10.00"),
+			vgFreeBytes: 100 * 1024 * 1024 * 1024,
+			policy:      limitedPolicy,
+			want:        []string{"--size=+1073741824B"},
+		},
+		{
+			name:        "reach the max capacity",
+			lv:          thinPool("90.00", "10.00"),
+			vgFreeBytes: 100 * 1024 * 1024 * 1024,
+			policy:      maxedPolicy,
+			wantErr:     true,
+		},
+		{
+			name:        "no free space in the pool",
+			lv:          thinPool("90.00", "85.00"),
+			vgFreeBytes: 3 * 1024 * 1024 * 1024,
+			policy:      policy,
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := thinPoolAutoExtendOptions(tt.lv, tt.vgFreeBytes, tt.policy)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("thinPoolAutoExtendOptions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrorThinPoolExtendImpossible) {
+					t.Errorf("thinPoolAutoExtendOptions() error = %v, want %v", err, ErrorThinPoolExtendImpossible)
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("thinPoolAutoExtendOptions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,1039 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/exechelper"
+	"github.com/hwameistor/hwameistor/pkg/exechelper/nsexecutor"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/utils"
+)
+
+// consts
+const (
+	ZFSVolumeDevicePathPrefix = "/dev/zvol"
+
+	ZFSDefaultCompression = "lz4"
+	ZFSDefaultChecksum    = "sha256"
+
+	zfsPoolHealthOnline   = "ONLINE"
+	zfsPoolHealthDegraded = "DEGRADED"
+
+	// expose the snapshots of zvol as block devices, so that they can be read by backup
+	zfsSnapdevVisibleOption = "snapdev=visible"
+)
+
+// variables
+var (
+	ErrorThinPoolNotSupported = fmt.Errorf("thin pool is not supported by ZFS, sparse zvol is used for thin volume instead")
+
+	ErrorDiskDecommissionNotSupported = fmt.Errorf("removing disk from the pool is not supported by ZFS")
+	ErrorDiskCordonNotSupported       = fmt.Errorf("marking disk non-allocatable in the pool is not supported by ZFS")
+
+	// sysBlockDir is where the block devices are found in sysfs, it's replaced in the tests
+	sysBlockDir = "/sys/class/block"
+)
+
+// zpoolRecord is a line of "zpool list -H -p -o name,size,alloc,free,health"
+type zpoolRecord struct {
+	Name   string
+	Size   int64
+	Alloc  int64
+	Free   int64
+	Health string
+}
+
+// zvolRecord is a line of "zfs list -H -p -t volume -o name,volsize,refreservation,origin,creation"
+type zvolRecord struct {
+	PoolName       string
+	Name           string
+	VolSize        int64
+	RefReservation int64
+	Origin         string
+	Creation       int64
+}
+
+// zfsDatasetRecord is a line of "zfs list -H -p -o name,used,avail"
+type zfsDatasetRecord struct {
+	Name  string
+	Used  int64
+	Avail int64
+}
+
+// zpoolVdevStatus is the error counters of a vdev in "zpool status -p"
+type zpoolVdevStatus struct {
+	Name           string
+	State          string
+	ReadErrors     int64
+	WriteErrors    int64
+	ChecksumErrors int64
+}
+
+type zfsExecutor struct {
+	lm      *LocalManager
+	cmdExec exechelper.Executor
+	logger  *log.Entry
+
+	// zfs volume and snapshot operations should be done one by one to get the accurate capacity
+	lock sync.Mutex
+}
+
+var zfsExecutorInstance *zfsExecutor
+
+func newZFSExecutor(lm *LocalManager) *zfsExecutor {
+	if zfsExecutorInstance == nil {
+		zfsExecutorInstance = &zfsExecutor{
+			lm:      lm,
+			cmdExec: nsexecutor.New(),
+			logger:  log.WithField("Module", "NodeManager/zfsExecuter"),
+		}
+	}
+	return zfsExecutorInstance
+}
+
+func (zfs *zfsExecutor) GetPools() (map[string]*apisv1alpha1.LocalPool, error) {
+	zpools, err := zfs.zpoolList()
+	if err != nil {
+		zfs.logger.WithError(err).Error("Failed to query zpools.")
+		return nil, err
+	}
+
+	zvols, err := zfs.zvolList()
+	if err != nil {
+		zfs.logger.WithError(err).Error("Failed to query zvols.")
+		return nil, err
+	}
+
+	pools := make(map[string]*apisv1alpha1.LocalPool)
+	for _, zpool := range zpools {
+		if !strings.HasPrefix(zpool.Name, apisv1alpha1.PoolNamePrefix) {
+			continue
+		}
+
+		// the capacity of the root dataset is used, because the reservation of the zvols is accounted in it
+		dataset, err := zfs.datasetRecord(zpool.Name)
+		if err != nil {
+			zfs.logger.WithError(err).Errorf("Failed to query root dataset of zpool %s.", zpool.Name)
+			return nil, err
+		}
+		totalCapacityBytes := dataset.Used + dataset.Avail
+
+		poolClass, poolType := getPoolClassTypeByName(zpool.Name)
+		if len(poolClass) == 0 || len(poolType) == 0 {
+			zfs.logger.Debugf("Failed to passe pool class and pool name: %s\n", zpool.Name)
+		}
+
+		vdevs, err := zfs.zpoolVdevs(zpool.Name)
+		if err != nil {
+			zfs.logger.WithError(err).Errorf("Failed to query vdevs of zpool %s.", zpool.Name)
+			return nil, err
+		}
+		poolDisks := make([]apisv1alpha1.LocalDevice, 0, len(vdevs))
+		for devPath, capacity := range vdevs {
+			poolDisks = append(poolDisks, apisv1alpha1.LocalDevice{
+				DevPath:       devPath,
+				CapacityBytes: capacity,
+				Class:         poolClass,
+				State:         apisv1alpha1.DiskStateInUse,
+			})
+		}
+
+		poolVolumes := []string{}
+		for _, zvol := range zvols {
+			if zvol.PoolName == zpool.Name {
+				poolVolumes = append(poolVolumes, zvol.Name)
+			}
+		}
+
+		pools[zpool.Name] = &apisv1alpha1.LocalPool{
+			Name:                     zpool.Name,
+			Class:                    poolClass,
+			Type:                     poolType,
+			Backend:                  apisv1alpha1.StorageBackendZFS,
+			TotalCapacityBytes:       totalCapacityBytes,
+			UsedCapacityBytes:        dataset.Used,
+			FreeCapacityBytes:        dataset.Avail,
+			VolumeCapacityBytesLimit: totalCapacityBytes,
+			TotalVolumeCount:         apisv1alpha1.LVMVolumeMaxCount,
+			UsedVolumeCount:          int64(len(poolVolumes)),
+			FreeVolumeCount:          apisv1alpha1.LVMVolumeMaxCount - int64(len(poolVolumes)),
+			Disks:                    poolDisks,
+			Volumes:                  poolVolumes,
+		}
+	}
+
+	return pools, nil
+}
+
+// GetThinPools returns nothing, because every zpool is able to provision sparse zvols without a dedicated thin pool
+func (zfs *zfsExecutor) GetThinPools() (map[string]*apisv1alpha1.ThinPoolInfo, error) {
+	return map[string]*apisv1alpha1.ThinPoolInfo{}, nil
+}
+
+func (zfs *zfsExecutor) ExtendThinPool(tpc *apisv1alpha1.ThinPoolClaim) error {
+	zfs.logger.WithField("ThinPoolClaim", tpc.Name).WithError(ErrorThinPoolNotSupported).Error("Failed to extend thin pool")
+	return ErrorThinPoolNotSupported
+}
+
+func (zfs *zfsExecutor) AutoExtendThinPool(poolName string, policy *apisv1alpha1.ThinPoolAutoExtendPolicy) (bool, error) {
+	return false, ErrorThinPoolNotSupported
+}
+
+func (zfs *zfsExecutor) GetReplicas() (map[string]*apisv1alpha1.LocalVolumeReplica, error) {
+	zvols, err := zfs.zvolList()
+	if err != nil {
+		zfs.logger.WithError(err).Error("Failed to query zvols.")
+		return nil, err
+	}
+
+	replicas := make(map[string]*apisv1alpha1.LocalVolumeReplica)
+	for _, zvol := range zvols {
+		if !strings.HasPrefix(zvol.PoolName, apisv1alpha1.PoolNamePrefix) {
+			continue
+		}
+
+		devicePath := zvolDevicePath(zvol.PoolName, zvol.Name)
+		replicas[zvol.Name] = &apisv1alpha1.LocalVolumeReplica{
+			Spec: apisv1alpha1.LocalVolumeReplicaSpec{
+				VolumeName: zvol.Name,
+				PoolName:   zvol.PoolName,
+				Thin:       zvol.RefReservation == 0,
+			},
+			Status: apisv1alpha1.LocalVolumeReplicaStatus{
+				StoragePath:            devicePath,
+				DevicePath:             devicePath,
+				AllocatedCapacityBytes: zvol.VolSize,
+				Synced:                 true,
+				State:                  apisv1alpha1.VolumeStateReady,
+			},
+		}
+		zfs.logger.WithField("volume", zvol.Name).Debug("Detected a ZFS volume")
+	}
+
+	zfs.logger.WithField("volumes", len(replicas)).Debug("Finshed ZFS volume detection")
+	return replicas, nil
+}
+
+func (zfs *zfsExecutor) CreateVolumeReplica(replica *apisv1alpha1.LocalVolumeReplica) (*apisv1alpha1.LocalVolumeReplica, error) {
+	zfs.lock.Lock()
+	defer zfs.lock.Unlock()
+
+	dataset := path.Join(replica.Spec.PoolName, replica.Spec.VolumeName)
+	if replica.Spec.Thin && replica.Spec.ThinOriginVolume != nil {
+		// clone from the origin volume by a snapshot named after the new volume
+		origin := fmt.Sprintf("%s@%s", path.Join(replica.Spec.PoolName, *replica.Spec.ThinOriginVolume), replica.Spec.VolumeName)
+		if err := zfs.snapshot(origin); err != nil {
+			return nil, err
+		}
+		if err := zfs.clone(origin, dataset); err != nil {
+			return nil, err
+		}
+	} else {
+		options := []string{"-V", strconv.FormatInt(utils.NumericToLVMBytes(replica.Spec.RequiredCapacityBytes), 10)}
+		if replica.Spec.Thin {
+			// sparse volume, no reservation
+			options = append(options, "-s")
+		}
+		if err := zfs.createVolume(dataset, options); err != nil {
+			return nil, err
+		}
+	}
+
+	// wait for the zvol device to be created by udev
+	zfs.udevSettle()
+
+	zvol, err := zfs.zvolRecord(replica.Spec.PoolName, replica.Spec.VolumeName)
+	if err != nil {
+		return nil, err
+	}
+	vdevs, err := zfs.zpoolVdevs(replica.Spec.PoolName)
+	if err != nil {
+		return nil, err
+	}
+
+	devicePath := zvolDevicePath(zvol.PoolName, zvol.Name)
+	newReplica := replica.DeepCopy()
+	newReplica.Status.AllocatedCapacityBytes = zvol.VolSize
+	newReplica.Status.StoragePath = devicePath
+	newReplica.Status.DevicePath = devicePath
+	newReplica.Status.Disks = vdevNames(vdevs)
+
+	return newReplica, nil
+}
+
+func (zfs *zfsExecutor) ExpandVolumeReplica(replica *apisv1alpha1.LocalVolumeReplica, newCapacityBytes int64) (*apisv1alpha1.LocalVolumeReplica, error) {
+	if replica.Status.AllocatedCapacityBytes == newCapacityBytes {
+		return replica, nil
+	}
+	zfs.lock.Lock()
+	defer zfs.lock.Unlock()
+
+	// the reservation of a thick zvol is changed along with the volsize
+	dataset := path.Join(replica.Spec.PoolName, replica.Spec.VolumeName)
+	if err := zfs.setProperty(dataset, "volsize", strconv.FormatInt(utils.NumericToLVMBytes(newCapacityBytes), 10)); err != nil {
+		return nil, err
+	}
+
+	zvol, err := zfs.zvolRecord(replica.Spec.PoolName, replica.Spec.VolumeName)
+	if err != nil {
+		return nil, err
+	}
+	newReplica := replica.DeepCopy()
+	newReplica.Status.AllocatedCapacityBytes = zvol.VolSize
+
+	return newReplica, nil
+}
+
+func (zfs *zfsExecutor) DeleteVolumeReplica(replica *apisv1alpha1.LocalVolumeReplica) error {
+	zfs.lock.Lock()
+	defer zfs.lock.Unlock()
+
+	dataset := path.Join(replica.Spec.PoolName, replica.Spec.VolumeName)
+	zvol, err := zfs.zvolRecord(replica.Spec.PoolName, replica.Spec.VolumeName)
+	if err != nil {
+		if err == ErrReplicaNotFound {
+			return nil
+		}
+		return err
+	}
+
+	// the snapshots may back the LocalVolumeReplicaSnapshots, or the clones of other volumes,
+	// so refuse to delete the volume until they are deleted
+	snapshots, err := zfs.snapshotList(dataset)
+	if err != nil {
+		return err
+	}
+	if len(snapshots) > 0 {
+		return fmt.Errorf("volume replica %s still has %d snapshot(s): %v", dataset, len(snapshots), snapshots)
+	}
+
+	if err = zfs.destroy(dataset); err != nil {
+		return err
+	}
+
+	// clean up the snapshot which the volume is cloned from
+	if len(zvol.Origin) > 0 && strings.HasSuffix(zvol.Origin, "@"+replica.Spec.VolumeName) {
+		if err = zfs.destroy(zvol.Origin); err != nil {
+			zfs.logger.WithError(err).WithField("origin", zvol.Origin).Warning("Failed to clean up origin snapshot of the volume")
+		}
+	}
+	return nil
+}
+
+func (zfs *zfsExecutor) TestVolumeReplica(replica *apisv1alpha1.LocalVolumeReplica) (*apisv1alpha1.LocalVolumeReplica, error) {
+	if _, err := zfs.zvolRecord(replica.Spec.PoolName, replica.Spec.VolumeName); err != nil {
+		return nil, err
+	}
+	vdevs, err := zfs.zpoolVdevs(replica.Spec.PoolName)
+	if err != nil {
+		return nil, err
+	}
+	health, err := zfs.zpoolHealth(replica.Spec.PoolName)
+	if err != nil {
+		return nil, err
+	}
+
+	newReplica := replica.DeepCopy()
+	newReplica.Status.Synced = true
+	newReplica.Status.Disks = vdevNames(vdevs)
+	newReplica.Status.State = apisv1alpha1.VolumeStateNotReady
+	if health == zfsPoolHealthOnline || health == zfsPoolHealthDegraded {
+		newReplica.Status.State = apisv1alpha1.VolumeStateReady
+	}
+	if _, err := os.Stat(newReplica.Status.DevicePath); err != nil && len(newReplica.Status.DevicePath) > 0 {
+		zfs.logger.WithField("device", newReplica.Status.DevicePath).WithError(err).Warning("Not found zvol device")
+		newReplica.Status.State = apisv1alpha1.VolumeStateNotReady
+	}
+
+	return newReplica, nil
+}
+
+func (zfs *zfsExecutor) ExtendPools(localDevices []*apisv1alpha1.LocalDevice) (bool, error) {
+	zfs.logger.Debugf("Start extending zpool disk(s): %s, count: %d", localDevicesArray(localDevices).string(), len(localDevices))
+
+	zpools, err := zfs.zpoolList()
+	if err != nil {
+		zfs.logger.WithError(err).Error("Failed to query zpools.")
+		return false, err
+	}
+	existingPools := map[string]bool{}
+	existingDisks := map[string]bool{}
+	for _, zpool := range zpools {
+		existingPools[zpool.Name] = true
+		vdevs, err := zfs.zpoolVdevs(zpool.Name)
+		if err != nil {
+			return false, err
+		}
+		for devPath := range vdevs {
+			existingDisks[devPath] = true
+		}
+	}
+
+	disksToBeExtends := make(map[string][]string)
+	for _, disk := range localDevices {
+		poolName, err := getPoolNameAccordingDisk(disk)
+		if err != nil {
+			zfs.logger.WithError(err).Error("Failed to get pool name of the disk")
+			continue
+		}
+		if existingDisks[disk.DevPath] {
+			continue
+		}
+		disksToBeExtends[poolName] = append(disksToBeExtends[poolName], disk.DevPath)
+	}
+
+	extend := false
+	for poolName, disks := range disksToBeExtends {
+		if len(disks) == 0 {
+			continue
+		}
+		zfs.logger.Debugf("Adding disk(s): %+v to zpool %s", disks, poolName)
+		if existingPools[poolName] {
+			err = zfs.zpoolAdd(poolName, disks)
+		} else {
+			err = zfs.zpoolCreate(poolName, disks)
+		}
+		if err != nil {
+			zfs.logger.WithError(err).Error("Add available disk failed.")
+			return extend, err
+		}
+		extend = true
+	}
+
+	return extend, nil
+}
+
+func (zfs *zfsExecutor) GetPhysicalVolumeUsage(poolName string, devPath string) (*PhysicalVolumeUsage, error) {
+	return nil, ErrorDiskDecommissionNotSupported
+}
+
+func (zfs *zfsExecutor) EvacuatePhysicalVolume(poolName string, devPath string) error {
+	return ErrorDiskDecommissionNotSupported
+}
+
+func (zfs *zfsExecutor) GetPhysicalVolumeEvacuation(poolName string, devPath string) (*PhysicalVolumeEvacuation, error) {
+	return nil, ErrorDiskDecommissionNotSupported
+}
+
+func (zfs *zfsExecutor) AbortPhysicalVolumeEvacuation(poolName string, devPath string) error {
+	return ErrorDiskDecommissionNotSupported
+}
+
+func (zfs *zfsExecutor) RemovePhysicalVolume(poolName string, devPath string) error {
+	return ErrorDiskDecommissionNotSupported
+}
+
+func (zfs *zfsExecutor) SetPhysicalVolumeAllocatable(poolName string, devPath string, allocatable bool) error {
+	return ErrorDiskCordonNotSupported
+}
+
+func (zfs *zfsExecutor) ResizePhysicalVolumes(localDevices map[string]*apisv1alpha1.LocalDevice) error {
+	zfs.logger.Debugf("Expanding vdev(s): %+v, count: %d", localDevicesMap(localDevices).string(), len(localDevices))
+
+	for _, disk := range localDevices {
+		poolName, err := getPoolNameAccordingDisk(disk)
+		if err != nil {
+			return err
+		}
+		params := exechelper.ExecParams{
+			CmdName: "zpool",
+			CmdArgs: []string{"online", "-e", poolName, disk.DevPath},
+		}
+		if res := zfs.cmdExec.RunCommand(params); res.ExitCode != 0 {
+			zfs.logger.WithError(res.Error).Errorf("Failed to expand vdev: %+v", disk.DevPath)
+			return res.Error
+		}
+	}
+
+	return nil
+}
+
+// ConsistencyCheck compares the replicas with the zvols, and checks the data integrity of the zpools by the checksum errors
+func (zfs *zfsExecutor) ConsistencyCheck(crdReplicas map[string]*apisv1alpha1.LocalVolumeReplica) {
+	zfs.logger.Debug("Consistency Checking for ZFS volume ...")
+
+	replicas, err := zfs.GetReplicas()
+	if err != nil {
+		zfs.logger.Error("Failed to collect volume replicas info from OS")
+		return
+	}
+
+	for volName, crd := range crdReplicas {
+		replica, exists := replicas[volName]
+		if !exists {
+			zfs.logger.WithField("volume", volName).WithError(fmt.Errorf("not found on Host")).Warning("Volume replica consistency check failed")
+			continue
+		}
+		if crd.Status.AllocatedCapacityBytes != replica.Status.AllocatedCapacityBytes {
+			zfs.logger.WithFields(log.Fields{
+				"volume":       volName,
+				"crd.capacity": crd.Status.AllocatedCapacityBytes,
+				"rep.capacity": replica.Status.AllocatedCapacityBytes,
+			}).WithError(fmt.Errorf("mismatched allocated capacity")).Warning("Volume replica consistency check failed")
+		}
+	}
+	for volName := range replicas {
+		if _, exists := crdReplicas[volName]; !exists {
+			zfs.logger.WithField("volume", volName).WithError(fmt.Errorf("not found the CRD")).Warning("Volume replica consistency check failed")
+		}
+	}
+
+	zpools, err := zfs.zpoolList()
+	if err != nil {
+		zfs.logger.WithError(err).Error("Failed to query zpools")
+		return
+	}
+	for _, zpool := range zpools {
+		if !strings.HasPrefix(zpool.Name, apisv1alpha1.PoolNamePrefix) {
+			continue
+		}
+		vdevs, err := zfs.zpoolStatus(zpool.Name)
+		if err != nil {
+			zfs.logger.WithError(err).WithField("pool", zpool.Name).Error("Failed to query zpool status")
+			continue
+		}
+		for _, vdev := range vdevs {
+			if vdev.ChecksumErrors > 0 || vdev.ReadErrors > 0 || vdev.WriteErrors > 0 {
+				zfs.logger.WithFields(log.Fields{
+					"pool":           zpool.Name,
+					"vdev":           vdev.Name,
+					"state":          vdev.State,
+					"readErrors":     vdev.ReadErrors,
+					"writeErrors":    vdev.WriteErrors,
+					"checksumErrors": vdev.ChecksumErrors,
+				}).WithError(fmt.Errorf("data integrity errors detected")).Warning("Storage pool integrity check failed")
+			}
+		}
+	}
+
+	zfs.logger.Debug("Consistency check completed")
+}
+
+// CreateVolumeReplicaSnapshot creates a native zfs snapshot of the volume replica
+func (zfs *zfsExecutor) CreateVolumeReplicaSnapshot(replicaSnapshot *apisv1alpha1.LocalVolumeReplicaSnapshot) error {
+	zfs.lock.Lock()
+	defer zfs.lock.Unlock()
+
+	logCtx := zfs.logger.WithFields(log.Fields{
+		"volumeSnapshot":        replicaSnapshot.Spec.VolumeSnapshotName,
+		"volumeReplicaSnapshot": replicaSnapshot.Name,
+		"sourceVolume":          replicaSnapshot.Spec.SourceVolume,
+	})
+	logCtx.Debug("Start creating volume replica snapshot")
+
+	if _, err := zfs.zvolRecord(replicaSnapshot.Spec.PoolName, replicaSnapshot.Spec.SourceVolume); err != nil {
+		logCtx.WithError(err).Error("Failed to get source volume replica on host")
+		return err
+	}
+
+	// use volume snapshot name as snapshot key - avoid duplicate volume replica snapshot with the same snapshot
+	if err := zfs.snapshot(zfsSnapshotName(replicaSnapshot)); err != nil {
+		logCtx.WithError(err).Error("Failed to create volume replica snapshot")
+		return err
+	}
+
+	logCtx.Debugf("Volume replica snapshot created: %s", replicaSnapshot.Name)
+	return nil
+}
+
+func (zfs *zfsExecutor) DeleteVolumeReplicaSnapshot(replicaSnapshot *apisv1alpha1.LocalVolumeReplicaSnapshot) error {
+	zfs.lock.Lock()
+	defer zfs.lock.Unlock()
+
+	if err := zfs.destroy(zfsSnapshotName(replicaSnapshot)); err != nil {
+		zfs.logger.WithError(err).WithField("volumeReplicaSnapshot", replicaSnapshot.Name).Error("Failed to delete volume replica snapshot from host")
+		return err
+	}
+
+	zfs.logger.Debugf("Volume replica snapshot deleted: %s", replicaSnapshot.Name)
+	return nil
+}
+
+func (zfs *zfsExecutor) UpdateVolumeReplicaSnapshot(replicaSnapshot *apisv1alpha1.LocalVolumeReplicaSnapshot) (*apisv1alpha1.LocalVolumeReplicaSnapshotStatus, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// GetVolumeReplicaSnapshot returns a volume replica snapshot attribute including state and creation time
+func (zfs *zfsExecutor) GetVolumeReplicaSnapshot(replicaSnapshot *apisv1alpha1.LocalVolumeReplicaSnapshot) (*apisv1alpha1.LocalVolumeReplicaSnapshotStatus, error) {
+	params := exechelper.ExecParams{
+		CmdName: "zfs",
+		CmdArgs: []string{"list", "-H", "-p", "-t", "snapshot", "-o", "name,volsize,creation", zfsSnapshotName(replicaSnapshot)},
+	}
+	res := zfs.cmdExec.RunCommand(params)
+	if res.ExitCode != 0 {
+		if isZFSNotExistError(res) {
+			return nil, ErrorSnapshotNotFound
+		}
+		return nil, res.Error
+	}
+
+	fields := strings.Split(strings.TrimSpace(res.OutBuf.String()), "\t")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("unrecognized zfs snapshot record: %s", res.OutBuf.String())
+	}
+	capacity, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	creation, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	creationTime := metav1.NewTime(time.Unix(creation, 0))
+	return &apisv1alpha1.LocalVolumeReplicaSnapshotStatus{
+		CreationTime:           &creationTime,
+		AllocatedCapacityBytes: capacity,
+		Conditions:             replicaSnapshot.Status.Conditions,
+		State:                  apisv1alpha1.VolumeStateReady,
+	}, nil
+}
+
+// RollbackVolumeReplicaSnapshot rolls the source volume back to the snapshot
+func (zfs *zfsExecutor) RollbackVolumeReplicaSnapshot(snapshotRestore *apisv1alpha1.LocalVolumeReplicaSnapshotRestore) error {
+	replicaSnapshot := &apisv1alpha1.LocalVolumeReplicaSnapshot{}
+	if err := zfs.lm.apiClient.Get(context.Background(), client.ObjectKey{Name: snapshotRestore.Spec.SourceVolumeReplicaSnapshot}, replicaSnapshot); err != nil {
+		zfs.logger.WithError(err).Error("Failed to get VolumeReplicaSnapshot")
+		return err
+	}
+
+	// the snapshots which are newer than the restored one will be destroyed
+	params := exechelper.ExecParams{
+		CmdName: "zfs",
+		CmdArgs: []string{"rollback", "-r", zfsSnapshotName(replicaSnapshot)},
+	}
+	if res := zfs.cmdExec.RunCommand(params); res.ExitCode != 0 {
+		zfs.logger.WithError(res.Error).Error("Failed to rollback volume to snapshot")
+		return res.Error
+	}
+	return nil
+}
+
+// RestoreVolumeReplicaSnapshot restores the snapshot to a new volume by zfs clone
+func (zfs *zfsExecutor) RestoreVolumeReplicaSnapshot(snapshotRestore *apisv1alpha1.LocalVolumeReplicaSnapshotRestore) error {
+	replicaSnapshot := &apisv1alpha1.LocalVolumeReplicaSnapshot{}
+	if err := zfs.lm.apiClient.Get(context.Background(), client.ObjectKey{Name: snapshotRestore.Spec.SourceVolumeReplicaSnapshot}, replicaSnapshot); err != nil {
+		zfs.logger.WithError(err).Error("Failed to get VolumeReplicaSnapshot")
+		return err
+	}
+
+	zfs.lock.Lock()
+	defer zfs.lock.Unlock()
+
+	target := path.Join(snapshotRestore.Spec.TargetPoolName, snapshotRestore.Spec.TargetVolume)
+	if err := zfs.clone(zfsSnapshotName(replicaSnapshot), target); err != nil {
+		zfs.logger.WithError(err).Error("Failed to clone snapshot to the target volume")
+		return err
+	}
+	zfs.udevSettle()
+	return nil
+}
+
+// ======== Helpers ============
+
+func zvolDevicePath(poolName, volumeName string) string {
+	return path.Join(ZFSVolumeDevicePathPrefix, poolName, volumeName)
+}
+
+// zfsSnapshotDevicePath returns the block device of the zvol snapshot, which exists only with snapdev=visible
+func zfsSnapshotDevicePath(replicaSnapshot *apisv1alpha1.LocalVolumeReplicaSnapshot) string {
+	return path.Join(ZFSVolumeDevicePathPrefix, zfsSnapshotName(replicaSnapshot))
+}
+
+func zfsSnapshotName(replicaSnapshot *apisv1alpha1.LocalVolumeReplicaSnapshot) string {
+	return fmt.Sprintf("%s@%s", path.Join(replicaSnapshot.Spec.PoolName, replicaSnapshot.Spec.SourceVolume), replicaSnapshot.Spec.VolumeSnapshotName)
+}
+
+func vdevNames(vdevs map[string]int64) []string {
+	names := make([]string, 0, len(vdevs))
+	for name := range vdevs {
+		names = append(names, name)
+	}
+	return names
+}
+
+func isZFSNotExistError(res exechelper.ExecResult) bool {
+	return res.ErrBuf != nil && strings.Contains(res.ErrBuf.String(), "does not exist")
+}
+
+// parseZFSNumber parses the parsable(-p) number output of zfs, "-" and "none" are treated as 0
+func parseZFSNumber(value string) (int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "-" || value == "none" || value == "" {
+		return 0, nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+func (zfs *zfsExecutor) zpoolOptions() []string {
+	compression, checksum := ZFSDefaultCompression, ZFSDefaultChecksum
+	if zfs.lm != nil && zfs.lm.systemConfig.ZFS != nil {
+		if len(zfs.lm.systemConfig.ZFS.Compression) > 0 {
+			compression = zfs.lm.systemConfig.ZFS.Compression
+		}
+		if len(zfs.lm.systemConfig.ZFS.Checksum) > 0 {
+			checksum = zfs.lm.systemConfig.ZFS.Checksum
+		}
+	}
+	// the root dataset is not mounted, and all the zvols inherit the compression and checksum
+	return []string{"-m", "none", "-O", "compression=" + compression, "-O", "checksum=" + checksum}
+}
+
+func (zfs *zfsExecutor) zpoolCreate(poolName string, disks []string) error {
+	params := exechelper.ExecParams{
+		CmdName: "zpool",
+		CmdArgs: append(append([]string{"create", "-f"}, zfs.zpoolOptions()...), append([]string{poolName}, disks...)...),
+	}
+	res := zfs.cmdExec.RunCommand(params)
+	if res.ExitCode == 0 {
+		return nil
+	}
+	return res.Error
+}
+
+func (zfs *zfsExecutor) zpoolAdd(poolName string, disks []string) error {
+	params := exechelper.ExecParams{
+		CmdName: "zpool",
+		CmdArgs: append([]string{"add", "-f", poolName}, disks...),
+	}
+	res := zfs.cmdExec.RunCommand(params)
+	if res.ExitCode == 0 {
+		return nil
+	}
+	return res.Error
+}
+
+func (zfs *zfsExecutor) zpoolList() ([]zpoolRecord, error) {
+	params := exechelper.ExecParams{
+		CmdName: "zpool",
+		CmdArgs: []string{"list", "-H", "-p", "-o", "name,size,alloc,free,health"},
+	}
+	res := zfs.cmdExec.RunCommand(params)
+	if res.ExitCode != 0 {
+		zfs.logger.WithError(res.Error).Error("Failed to discover zpools")
+		return nil, res.Error
+	}
+
+	records := []zpoolRecord{}
+	for _, line := range strings.Split(res.OutBuf.String(), "\n") {
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("unrecognized zpool record: %s", line)
+		}
+		record := zpoolRecord{Name: fields[0], Health: fields[4]}
+		var err error
+		if record.Size, err = parseZFSNumber(fields[1]); err != nil {
+			return nil, err
+		}
+		if record.Alloc, err = parseZFSNumber(fields[2]); err != nil {
+			return nil, err
+		}
+		if record.Free, err = parseZFSNumber(fields[3]); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (zfs *zfsExecutor) zpoolHealth(poolName string) (string, error) {
+	zpools, err := zfs.zpoolList()
+	if err != nil {
+		return "", err
+	}
+	for _, zpool := range zpools {
+		if zpool.Name == poolName {
+			return zpool.Health, nil
+		}
+	}
+	return "", ErrorPoolNotFound
+}
+
+// zpoolVdevs returns the disks of the zpool: devPath -> capacity
+func (zfs *zfsExecutor) zpoolVdevs(poolName string) (map[string]int64, error) {
+	params := exechelper.ExecParams{
+		CmdName: "zpool",
+		CmdArgs: []string{"list", "-H", "-p", "-P", "-v", "-o", "name,size", poolName},
+	}
+	res := zfs.cmdExec.RunCommand(params)
+	if res.ExitCode != 0 {
+		return nil, res.Error
+	}
+
+	vdevs := map[string]int64{}
+	for _, line := range strings.Split(res.OutBuf.String(), "\n") {
+		// vdevs are indented with a tab, and shown with the full path because of "-P"
+		if !strings.HasPrefix(line, "\t") {
+			continue
+		}
+		fields := strings.Split(strings.TrimSpace(line), "\t")
+		if len(fields) < 2 || !strings.HasPrefix(fields[0], "/dev/") {
+			continue
+		}
+		size, err := parseZFSNumber(fields[1])
+		if err != nil {
+			return nil, err
+		}
+		vdevs[vdevDiskPath(fields[0])] = size
+	}
+	return vdevs, nil
+}
+
+// vdevDiskPath returns the disk of the vdev. ZFS partitions the whole disk added to the pool,
+// and shows the vdev as the partition, e.g. /dev/sdb1 for /dev/sdb
+func vdevDiskPath(vdev string) string {
+	devPath, err := filepath.EvalSymlinks(vdev)
+	if err != nil {
+		devPath = vdev
+	}
+	name := filepath.Base(devPath)
+	if _, err = os.Stat(filepath.Join(sysBlockDir, name, "partition")); err != nil {
+		return vdev
+	}
+	// e.g. /sys/class/block/sdb1 -> /sys/devices/.../block/sdb/sdb1
+	sysPath, err := filepath.EvalSymlinks(filepath.Join(sysBlockDir, name))
+	if err != nil {
+		return vdev
+	}
+	return "/dev/" + filepath.Base(filepath.Dir(sysPath))
+}
+
+// zpoolStatus returns the error counters of all the vdevs in the zpool
+func (zfs *zfsExecutor) zpoolStatus(poolName string) ([]zpoolVdevStatus, error) {
+	params := exechelper.ExecParams{
+		CmdName: "zpool",
+		CmdArgs: []string{"status", "-p", "-P", poolName},
+	}
+	res := zfs.cmdExec.RunCommand(params)
+	if res.ExitCode != 0 {
+		return nil, res.Error
+	}
+	return parseZpoolStatus(res.OutBuf.String()), nil
+}
+
+// parseZpoolStatus parses the config section of "zpool status", e.g.
+//
+//	NAME                  STATE     READ WRITE CKSUM
+//	LocalStorage_PoolHDD  ONLINE       0     0     0
+//	  /dev/sdb            ONLINE       0     0     2
+func parseZpoolStatus(output string) []zpoolVdevStatus {
+	vdevs := []zpoolVdevStatus{}
+	inConfig := false
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			if inConfig && len(vdevs) > 0 {
+				break
+			}
+			continue
+		}
+		if fields[0] == "NAME" && len(fields) >= 5 && fields[4] == "CKSUM" {
+			inConfig = true
+			continue
+		}
+		if !inConfig || len(fields) < 5 {
+			continue
+		}
+		vdev := zpoolVdevStatus{Name: fields[0], State: fields[1]}
+		vdev.ReadErrors, _ = strconv.ParseInt(fields[2], 10, 64)
+		vdev.WriteErrors, _ = strconv.ParseInt(fields[3], 10, 64)
+		vdev.ChecksumErrors, _ = strconv.ParseInt(fields[4], 10, 64)
+		vdevs = append(vdevs, vdev)
+	}
+	return vdevs
+}
+
+func (zfs *zfsExecutor) datasetRecord(dataset string) (*zfsDatasetRecord, error) {
+	params := exechelper.ExecParams{
+		CmdName: "zfs",
+		CmdArgs: []string{"list", "-H", "-p", "-o", "name,used,avail", dataset},
+	}
+	res := zfs.cmdExec.RunCommand(params)
+	if res.ExitCode != 0 {
+		return nil, res.Error
+	}
+	fields := strings.Split(strings.TrimSpace(res.OutBuf.String()), "\t")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("unrecognized zfs dataset record: %s", res.OutBuf.String())
+	}
+	record := &zfsDatasetRecord{Name: fields[0]}
+	var err error
+	if record.Used, err = parseZFSNumber(fields[1]); err != nil {
+		return nil, err
+	}
+	if record.Avail, err = parseZFSNumber(fields[2]); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (zfs *zfsExecutor) zvolList(datasets ...string) ([]zvolRecord, error) {
+	params := exechelper.ExecParams{
+		CmdName: "zfs",
+		CmdArgs: append([]string{"list", "-H", "-p", "-t", "volume", "-o", "name,volsize,refreservation,origin,creation"}, datasets...),
+	}
+	res := zfs.cmdExec.RunCommand(params)
+	if res.ExitCode != 0 {
+		if isZFSNotExistError(res) {
+			return nil, ErrReplicaNotFound
+		}
+		zfs.logger.WithError(res.Error).Error("Failed to discover zvols")
+		return nil, res.Error
+	}
+	return parseZvolList(res.OutBuf.String())
+}
+
+func parseZvolList(output string) ([]zvolRecord, error) {
+	records := []zvolRecord{}
+	for _, line := range strings.Split(output, "\n") {
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+		fields := strings.Split(line, "\t")
+		if len(fields) != 5 {
+			return nil, fmt.Errorf("unrecognized zvol record: %s", line)
+		}
+		// only the zvols directly under the zpool are managed, e.g. LocalStorage_PoolHDD/pvc-xxx
+		names := strings.Split(fields[0], "/")
+		if len(names) != 2 {
+			continue
+		}
+		record := zvolRecord{PoolName: names[0], Name: names[1]}
+		if fields[3] != "-" {
+			record.Origin = fields[3]
+		}
+		var err error
+		if record.VolSize, err = parseZFSNumber(fields[1]); err != nil {
+			return nil, err
+		}
+		if record.RefReservation, err = parseZFSNumber(fields[2]); err != nil {
+			return nil, err
+		}
+		if record.Creation, err = parseZFSNumber(fields[4]); err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// snapshotList returns the names of the snapshots of the dataset
+func (zfs *zfsExecutor) snapshotList(dataset string) ([]string, error) {
+	params := exechelper.ExecParams{
+		CmdName: "zfs",
+		CmdArgs: []string{"list", "-H", "-t", "snapshot", "-o", "name", "-d", "1", dataset},
+	}
+	res := zfs.cmdExec.RunCommand(params)
+	if res.ExitCode != 0 {
+		if isZFSNotExistError(res) {
+			return nil, nil
+		}
+		return nil, res.Error
+	}
+	var snapshots []string
+	for _, line := range strings.Split(res.OutBuf.String(), "\n") {
+		if name := strings.TrimSpace(line); len(name) > 0 {
+			snapshots = append(snapshots, name)
+		}
+	}
+	return snapshots, nil
+}
+
+func (zfs *zfsExecutor) zvolRecord(poolName, volumeName string) (*zvolRecord, error) {
+	records, err := zfs.zvolList(path.Join(poolName, volumeName))
+	if err != nil {
+		return nil, err
+	}
+	for i := range records {
+		if records[i].PoolName == poolName && records[i].Name == volumeName {
+			return &records[i], nil
+		}
+	}
+	return nil, ErrReplicaNotFound
+}
+
+func (zfs *zfsExecutor) createVolume(dataset string, options []string) error {
+	params := exechelper.ExecParams{
+		CmdName: "zfs",
+		CmdArgs: append(append([]string{"create", "-o", zfsSnapdevVisibleOption}, options...), dataset),
+	}
+	res := zfs.cmdExec.RunCommand(params)
+	if res.ExitCode == 0 || (res.ErrBuf != nil && strings.Contains(res.ErrBuf.String(), "dataset already exists")) {
+		return nil
+	}
+	return res.Error
+}
+
+func (zfs *zfsExecutor) snapshot(snapshot string) error {
+	params := exechelper.ExecParams{
+		CmdName: "zfs",
+		CmdArgs: []string{"snapshot", snapshot},
+	}
+	res := zfs.cmdExec.RunCommand(params)
+	if res.ExitCode == 0 || (res.ErrBuf != nil && strings.Contains(res.ErrBuf.String(), "dataset already exists")) {
+		return nil
+	}
+	return res.Error
+}
+
+func (zfs *zfsExecutor) clone(snapshot string, dataset string) error {
+	params := exechelper.ExecParams{
+		CmdName: "zfs",
+		CmdArgs: []string{"clone", "-o", zfsSnapdevVisibleOption, snapshot, dataset},
+	}
+	res := zfs.cmdExec.RunCommand(params)
+	if res.ExitCode == 0 || (res.ErrBuf != nil && strings.Contains(res.ErrBuf.String(), "dataset already exists")) {
+		return nil
+	}
+	return res.Error
+}
+
+func (zfs *zfsExecutor) destroy(dataset string, options ...string) error {
+	params := exechelper.ExecParams{
+		CmdName: "zfs",
+		CmdArgs: append(append([]string{"destroy"}, options...), dataset),
+	}
+	res := zfs.cmdExec.RunCommand(params)
+	if res.ExitCode == 0 || isZFSNotExistError(res) {
+		return nil
+	}
+	return res.Error
+}
+
+func (zfs *zfsExecutor) setProperty(dataset string, property string, value string) error {
+	params := exechelper.ExecParams{
+		CmdName: "zfs",
+		CmdArgs: []string{"set", fmt.Sprintf("%s=%s", property, value), dataset},
+	}
+	res := zfs.cmdExec.RunCommand(params)
+	if res.ExitCode == 0 {
+		return nil
+	}
+	return res.Error
+}
+
+func (zfs *zfsExecutor) udevSettle() {
+	params := exechelper.ExecParams{
+		CmdName: "udevadm",
+		CmdArgs: []string{"settle"},
+		Timeout: 30,
+	}
+	if res := zfs.cmdExec.RunCommand(params); res.ExitCode != 0 {
+		zfs.logger.WithError(res.Error).Warning("Failed to wait for udev events")
+	}
+}
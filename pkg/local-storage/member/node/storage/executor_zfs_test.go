@@ -0,0 +1,313 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/exechelper"
+)
+
+// fakeCmdExecutor records the commands and returns the canned output by the command line
+type fakeCmdExecutor struct {
+	outputs  map[string]string
+	commands []string
+}
+
+func (f *fakeCmdExecutor) RunCommand(params exechelper.ExecParams) exechelper.ExecResult {
+	cmd := strings.Join(append([]string{params.CmdName}, params.CmdArgs...), " ")
+	f.commands = append(f.commands, cmd)
+	return exechelper.ExecResult{
+		OutBuf: bytes.NewBufferString(f.outputs[cmd]),
+		ErrBuf: bytes.NewBufferString(""),
+	}
+}
+
+func newFakeZFSExecutor(outputs map[string]string) (*zfsExecutor, *fakeCmdExecutor) {
+	cmdExec := &fakeCmdExecutor{outputs: outputs}
+	return &zfsExecutor{
+		lm:      &LocalManager{},
+		cmdExec: cmdExec,
+		logger:  log.WithField("Module", "NodeManager/zfsExecuter"),
+	}, cmdExec
+}
+
+// fakeSysBlockDir fakes the partitions of the disks in sysfs, e.g. sdb1 of sdb
+func fakeSysBlockDir(t *testing.T, partitions map[string]string) {
+	dir := t.TempDir()
+	for part, disk := range partitions {
+		partDir := filepath.Join(dir, "devices", "block", disk, part)
+		if err := os.MkdirAll(partDir, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(partDir, "partition"), []byte("1\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(partDir, filepath.Join(dir, part)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oldSysBlockDir := sysBlockDir
+	sysBlockDir = dir
+	t.Cleanup(func() { sysBlockDir = oldSysBlockDir })
+}
+
+func Test_zfsExecutor_GetPools(t *testing.T) {
+	fakeSysBlockDir(t, map[string]string{"sdb1": "sdb"})
+	zfs, _ := newFakeZFSExecutor(map[string]string{
+		"zpool list -H -p -o name,size,alloc,free,health":                         "LocalStorage_PoolSSD\t10737418240\t1073741824\t9663676416\tONLINE\nrpool\t10737418240\t0\t10737418240\tONLINE\n",
+		"zfs list -H -p -t volume -o name,volsize,refreservation,origin,creation": "LocalStorage_PoolSSD/pvc-1\t1073741824\t1090519040\t-\t1700000000\n",
+		"zfs list -H -p -o name,used,avail LocalStorage_PoolSSD":                  "LocalStorage_PoolSSD\t1090519040\t9300000000\n",
+		"zpool list -H -p -P -v -o name,size LocalStorage_PoolSSD":                "LocalStorage_PoolSSD\t10737418240\n\t/dev/sdb1\t10726932480\n",
+	})
+
+	pools, err := zfs.GetPools()
+	if err != nil {
+		t.Fatalf("GetPools() error = %v", err)
+	}
+	if len(pools) != 1 {
+		t.Fatalf("GetPools() got %d pools, want 1", len(pools))
+	}
+	pool := pools[apisv1alpha1.PoolNameForSSD]
+	if pool == nil {
+		t.Fatalf("GetPools() not found pool %s", apisv1alpha1.PoolNameForSSD)
+	}
+	if pool.Backend != apisv1alpha1.StorageBackendZFS || pool.Class != apisv1alpha1.DiskClassNameSSD {
+		t.Errorf("GetPools() got backend %s, class %s", pool.Backend, pool.Class)
+	}
+	if pool.TotalCapacityBytes != 1090519040+9300000000 || pool.FreeCapacityBytes != 9300000000 {
+		t.Errorf("GetPools() got total %d, free %d", pool.TotalCapacityBytes, pool.FreeCapacityBytes)
+	}
+	if !reflect.DeepEqual(pool.Volumes, []string{"pvc-1"}) {
+		t.Errorf("GetPools() got volumes %v", pool.Volumes)
+	}
+	if len(pool.Disks) != 1 || pool.Disks[0].DevPath != "/dev/sdb" {
+		t.Errorf("GetPools() got disks %v", pool.Disks)
+	}
+}
+
+func Test_zfsExecutor_GetReplicas(t *testing.T) {
+	zfs, _ := newFakeZFSExecutor(map[string]string{
+		"zfs list -H -p -t volume -o name,volsize,refreservation,origin,creation": "LocalStorage_PoolSSD/pvc-1\t1073741824\t1090519040\t-\t1700000000\n" +
+			"LocalStorage_PoolSSD/pvc-2\t2147483648\tnone\t-\t1700000000\n" +
+			"rpool/swap\t1073741824\t1090519040\t-\t1700000000\n",
+	})
+
+	replicas, err := zfs.GetReplicas()
+	if err != nil {
+		t.Fatalf("GetReplicas() error = %v", err)
+	}
+	if len(replicas) != 2 {
+		t.Fatalf("GetReplicas() got %d replicas, want 2", len(replicas))
+	}
+	if replicas["pvc-1"].Spec.Thin || replicas["pvc-1"].Status.DevicePath != "/dev/zvol/LocalStorage_PoolSSD/pvc-1" {
+		t.Errorf("GetReplicas() got pvc-1 %+v", replicas["pvc-1"])
+	}
+	if !replicas["pvc-2"].Spec.Thin || replicas["pvc-2"].Status.AllocatedCapacityBytes != 2147483648 {
+		t.Errorf("GetReplicas() got pvc-2 %+v", replicas["pvc-2"])
+	}
+}
+
+func Test_zfsExecutor_CreateVolumeReplica(t *testing.T) {
+	tests := []struct {
+		name    string
+		thin    bool
+		origin  *string
+		wantCmd string
+	}{
+		{
+			name:    "thick volume",
+			wantCmd: "zfs create -o snapdev=visible -V 1073741824 LocalStorage_PoolSSD/pvc-1",
+		},
+		{
+			name:    "sparse volume",
+			thin:    true,
+			wantCmd: "zfs create -o snapdev=visible -V 1073741824 -s LocalStorage_PoolSSD/pvc-1",
+		},
+		{
+			name:    "cloned volume",
+			thin:    true,
+			origin:  func() *string { s := "pvc-0"; return &s }(),
+			wantCmd: "zfs clone -o snapdev=visible LocalStorage_PoolSSD/pvc-0@pvc-1 LocalStorage_PoolSSD/pvc-1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zfs, cmdExec := newFakeZFSExecutor(map[string]string{
+				"zfs list -H -p -t volume -o name,volsize,refreservation,origin,creation LocalStorage_PoolSSD/pvc-1": "LocalStorage_PoolSSD/pvc-1\t1073741824\t0\t-\t1700000000\n",
+				"zpool list -H -p -P -v -o name,size LocalStorage_PoolSSD":                                           "LocalStorage_PoolSSD\t10737418240\n\t/dev/sdb1\t10726932480\n",
+			})
+			replica := &apisv1alpha1.LocalVolumeReplica{}
+			replica.Spec.VolumeName = "pvc-1"
+			replica.Spec.PoolName = apisv1alpha1.PoolNameForSSD
+			replica.Spec.RequiredCapacityBytes = 1073741824
+			replica.Spec.Thin = tt.thin
+			replica.Spec.ThinOriginVolume = tt.origin
+
+			newReplica, err := zfs.CreateVolumeReplica(replica)
+			if err != nil {
+				t.Fatalf("CreateVolumeReplica() error = %v", err)
+			}
+			found := false
+			for _, cmd := range cmdExec.commands {
+				if cmd == tt.wantCmd {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("CreateVolumeReplica() not found command %s in %v", tt.wantCmd, cmdExec.commands)
+			}
+			if newReplica.Status.DevicePath != "/dev/zvol/LocalStorage_PoolSSD/pvc-1" || newReplica.Status.AllocatedCapacityBytes != 1073741824 {
+				t.Errorf("CreateVolumeReplica() got status %+v", newReplica.Status)
+			}
+		})
+	}
+}
+
+func Test_zfsExecutor_ExtendPools(t *testing.T) {
+	fakeSysBlockDir(t, map[string]string{"sdb1": "sdb"})
+	zfs, cmdExec := newFakeZFSExecutor(map[string]string{
+		"zpool list -H -p -o name,size,alloc,free,health":          "LocalStorage_PoolSSD\t10737418240\t0\t10737418240\tONLINE\n",
+		"zpool list -H -p -P -v -o name,size LocalStorage_PoolSSD": "LocalStorage_PoolSSD\t10737418240\n\t/dev/sdb1\t10726932480\n",
+	})
+	zfs.lm.systemConfig.ZFS = &apisv1alpha1.ZFSSystemConfig{Compression: "zstd"}
+
+	extend, err := zfs.ExtendPools([]*apisv1alpha1.LocalDevice{
+		{DevPath: "/dev/sdb", Class: apisv1alpha1.DiskClassNameSSD},
+		{DevPath: "/dev/sdc", Class: apisv1alpha1.DiskClassNameSSD},
+		{DevPath: "/dev/sdd", Class: apisv1alpha1.DiskClassNameHDD},
+	})
+	if err != nil || !extend {
+		t.Fatalf("ExtendPools() extend = %v, error = %v", extend, err)
+	}
+
+	wantCmds := []string{
+		"zpool add -f LocalStorage_PoolSSD /dev/sdc",
+		"zpool create -f -m none -O compression=zstd -O checksum=sha256 LocalStorage_PoolHDD /dev/sdd",
+	}
+	for _, want := range wantCmds {
+		found := false
+		for _, cmd := range cmdExec.commands {
+			if cmd == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("ExtendPools() not found command %s in %v", want, cmdExec.commands)
+		}
+	}
+	// sdb is already in the pool as sdb1
+	for _, cmd := range cmdExec.commands {
+		if strings.HasPrefix(cmd, "zpool add") && strings.Contains(cmd, "/dev/sdb") {
+			t.Errorf("ExtendPools() added the disk in the pool again: %s", cmd)
+		}
+	}
+}
+
+func Test_zfsExecutor_DeleteVolumeReplica(t *testing.T) {
+	tests := []struct {
+		name      string
+		snapshots string
+		wantErr   bool
+	}{
+		{
+			name: "no snapshot",
+		},
+		{
+			name:      "snapshot exists",
+			snapshots: "LocalStorage_PoolSSD/pvc-1@snapshot-1\n",
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zfs, cmdExec := newFakeZFSExecutor(map[string]string{
+				"zfs list -H -p -t volume -o name,volsize,refreservation,origin,creation LocalStorage_PoolSSD/pvc-1": "LocalStorage_PoolSSD/pvc-1\t1073741824\t0\t-\t1700000000\n",
+				"zfs list -H -t snapshot -o name -d 1 LocalStorage_PoolSSD/pvc-1":                                    tt.snapshots,
+			})
+			replica := &apisv1alpha1.LocalVolumeReplica{}
+			replica.Spec.VolumeName = "pvc-1"
+			replica.Spec.PoolName = apisv1alpha1.PoolNameForSSD
+
+			if err := zfs.DeleteVolumeReplica(replica); (err != nil) != tt.wantErr {
+				t.Fatalf("DeleteVolumeReplica() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			destroyed := false
+			for _, cmd := range cmdExec.commands {
+				if strings.HasPrefix(cmd, "zfs destroy") {
+					if cmd != "zfs destroy LocalStorage_PoolSSD/pvc-1" {
+						t.Errorf("DeleteVolumeReplica() got command %s", cmd)
+					}
+					destroyed = true
+				}
+			}
+			if destroyed == tt.wantErr {
+				t.Errorf("DeleteVolumeReplica() destroyed = %v, commands %v", destroyed, cmdExec.commands)
+			}
+		})
+	}
+}
+
+func Test_zfsExecutor_VolumeReplicaSnapshot(t *testing.T) {
+	zfs, cmdExec := newFakeZFSExecutor(map[string]string{
+		"zfs list -H -p -t volume -o name,volsize,refreservation,origin,creation LocalStorage_PoolSSD/pvc-1": "LocalStorage_PoolSSD/pvc-1\t1073741824\t0\t-\t1700000000\n",
+		"zfs list -H -p -t snapshot -o name,volsize,creation LocalStorage_PoolSSD/pvc-1@snapshot-1":          "LocalStorage_PoolSSD/pvc-1@snapshot-1\t1073741824\t1700000100\n",
+	})
+	replicaSnapshot := &apisv1alpha1.LocalVolumeReplicaSnapshot{}
+	replicaSnapshot.Name = "snapshot-1-replica"
+	replicaSnapshot.Spec.PoolName = apisv1alpha1.PoolNameForSSD
+	replicaSnapshot.Spec.SourceVolume = "pvc-1"
+	replicaSnapshot.Spec.VolumeSnapshotName = "snapshot-1"
+
+	if err := zfs.CreateVolumeReplicaSnapshot(replicaSnapshot); err != nil {
+		t.Fatalf("CreateVolumeReplicaSnapshot() error = %v", err)
+	}
+	if got := cmdExec.commands[len(cmdExec.commands)-1]; got != "zfs snapshot LocalStorage_PoolSSD/pvc-1@snapshot-1" {
+		t.Errorf("CreateVolumeReplicaSnapshot() got command %s", got)
+	}
+
+	status, err := zfs.GetVolumeReplicaSnapshot(replicaSnapshot)
+	if err != nil {
+		t.Fatalf("GetVolumeReplicaSnapshot() error = %v", err)
+	}
+	if status.AllocatedCapacityBytes != 1073741824 || status.CreationTime.Unix() != 1700000100 || status.State != apisv1alpha1.VolumeStateReady {
+		t.Errorf("GetVolumeReplicaSnapshot() got status %+v", status)
+	}
+
+	if err := zfs.DeleteVolumeReplicaSnapshot(replicaSnapshot); err != nil {
+		t.Fatalf("DeleteVolumeReplicaSnapshot() error = %v", err)
+	}
+	if got := cmdExec.commands[len(cmdExec.commands)-1]; got != "zfs destroy LocalStorage_PoolSSD/pvc-1@snapshot-1" {
+		t.Errorf("DeleteVolumeReplicaSnapshot() got command %s", got)
+	}
+}
+
+func Test_parseZpoolStatus(t *testing.T) {
+	output := `  pool: LocalStorage_PoolHDD
+ state: ONLINE
+status: One or more devices has experienced an unrecoverable error.
+config:
+
+	NAME                  STATE     READ WRITE CKSUM
+	LocalStorage_PoolHDD  ONLINE       0     0     0
+	  /dev/sdb            ONLINE       0     0     2
+	  /dev/sdc            ONLINE       1     0     0
+
+errors: No known data errors
+`
+	want := []zpoolVdevStatus{
+		{Name: "LocalStorage_PoolHDD", State: "ONLINE"},
+		{Name: "/dev/sdb", State: "ONLINE", ChecksumErrors: 2},
+		{Name: "/dev/sdc", State: "ONLINE", ReadErrors: 1},
+	}
+	if got := parseZpoolStatus(output); !reflect.DeepEqual(got, want) {
+		t.Errorf("parseZpoolStatus() = %v, want %v", got, want)
+	}
+}
@@ -1,6 +1,8 @@
 package storage
 
 import (
+	"fmt"
+
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -15,6 +17,7 @@ type LocalManager struct {
 	scheme                 *runtime.Scheme
 	recorder               record.EventRecorder
 	nodeConf               *apisv1alpha1.NodeConfig
+	systemConfig           apisv1alpha1.SystemConfig
 
 	registry                     LocalRegistry
 	poolManager                  LocalPoolManager
@@ -23,9 +26,10 @@ type LocalManager struct {
 }
 
 // NewLocalManager creates a local manager
-func NewLocalManager(nodeConf *apisv1alpha1.NodeConfig, cli client.Client, scheme *runtime.Scheme, recorder record.EventRecorder, snapshotRestoreTimeout int) *LocalManager {
+func NewLocalManager(nodeConf *apisv1alpha1.NodeConfig, systemConfig apisv1alpha1.SystemConfig, cli client.Client, scheme *runtime.Scheme, recorder record.EventRecorder, snapshotRestoreTimeout int) *LocalManager {
 	lm := &LocalManager{
 		nodeConf:               nodeConf,
+		systemConfig:           systemConfig,
 		apiClient:              cli,
 		scheme:                 scheme,
 		recorder:               recorder,
@@ -77,3 +81,51 @@ func (lm *LocalManager) VolumeReplicaSnapshotManager() LocalVolumeReplicaSnapsho
 func (lm *LocalManager) NodeConfig() *apisv1alpha1.NodeConfig {
 	return lm.nodeConf
 }
+
+// StorageBackendOfPool gets the storage backend which the pool is provisioned by
+func (lm *LocalManager) StorageBackendOfPool(poolName string) string {
+	poolClass, _ := getPoolClassTypeByName(poolName)
+	return lm.systemConfig.GetStorageBackend(poolClass)
+}
+
+// StorageBackends gets all the storage backends enabled on the node. LVM is always enabled
+func (lm *LocalManager) StorageBackends() []string {
+	backends := []string{apisv1alpha1.StorageBackendLVM}
+	for _, backend := range lm.systemConfig.StorageBackends {
+		if backend == apisv1alpha1.StorageBackendZFS {
+			return append(backends, apisv1alpha1.StorageBackendZFS)
+		}
+	}
+	return backends
+}
+
+// VolumeReplicaSnapshotDevicePath gets the block device of the volume replica snapshot, from which the snapshot data can be read
+func (lm *LocalManager) VolumeReplicaSnapshotDevicePath(replicaSnapshot *apisv1alpha1.LocalVolumeReplicaSnapshot) string {
+	if lm.StorageBackendOfPool(replicaSnapshot.Spec.PoolName) == apisv1alpha1.StorageBackendZFS {
+		return zfsSnapshotDevicePath(replicaSnapshot)
+	}
+	return composePoolVolumePath(replicaSnapshot.Spec.PoolName, replicaSnapshot.Spec.VolumeSnapshotName)
+}
+
+// StartVolumeReplicaRaidCheck starts to check the consistency between the raid images of the mirrored volume replica
+func (lm *LocalManager) StartVolumeReplicaRaidCheck(replica *apisv1alpha1.LocalVolumeReplica) error {
+	return newLVMExecutor(lm).startRaidCheck(replica)
+}
+
+// StopVolumeReplicaRaidCheck stops the running raid check of the mirrored volume replica
+func (lm *LocalManager) StopVolumeReplicaRaidCheck(replica *apisv1alpha1.LocalVolumeReplica) error {
+	return newLVMExecutor(lm).stopRaidCheck(replica)
+}
+
+// GetVolumeReplicaRaidCheckState returns whether the raid check is still running, and the number of the mismatches found
+func (lm *LocalManager) GetVolumeReplicaRaidCheckState(replica *apisv1alpha1.LocalVolumeReplica) (bool, int64, error) {
+	return newLVMExecutor(lm).getRaidCheckState(replica)
+}
+
+// ShrinkVolumeReplica reduces the volume replica to the new capacity, only for LVM volume
+func (lm *LocalManager) ShrinkVolumeReplica(replica *apisv1alpha1.LocalVolumeReplica, newCapacityBytes int64) (*apisv1alpha1.LocalVolumeReplica, error) {
+	if lm.StorageBackendOfPool(replica.Spec.PoolName) != apisv1alpha1.StorageBackendLVM {
+		return nil, fmt.Errorf("shrinking volume replica is only supported on LVM pool")
+	}
+	return newLVMExecutor(lm).shrinkVolumeReplica(replica, newCapacityBytes)
+}
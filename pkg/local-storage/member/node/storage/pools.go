@@ -10,41 +10,184 @@ import (
 )
 
 type localPoolManager struct {
-	cmdExec LocalPoolExecutor
-	logger  *log.Entry
-	lm      *LocalManager
+	// executors of the enabled storage backends, e.g. LVM, ZFS
+	cmdExecs map[string]LocalPoolExecutor
+	logger   *log.Entry
+	lm       *LocalManager
 }
 
 func (mgr *localPoolManager) ExtendPools(localDisks []*apisv1alpha1.LocalDevice) (bool, error) {
+	disksByBackend := map[string][]*apisv1alpha1.LocalDevice{}
+	for _, disk := range localDisks {
+		backend := mgr.lm.systemConfig.GetStorageBackend(disk.Class)
+		disksByBackend[backend] = append(disksByBackend[backend], disk)
+	}
 
-	return mgr.cmdExec.ExtendPools(localDisks)
+	extend := false
+	for backend, disks := range disksByBackend {
+		cmdExec, err := mgr.executor(backend)
+		if err != nil {
+			mgr.logger.WithError(err).WithField("backend", backend).Error("Failed to extend pools")
+			return extend, err
+		}
+		extendOne, err := cmdExec.ExtendPools(disks)
+		extend = extend || extendOne
+		if err != nil {
+			return extend, err
+		}
+	}
+	return extend, nil
 }
 
 func (mgr *localPoolManager) GetPools() (map[string]*apisv1alpha1.LocalPool, error) {
-	return mgr.cmdExec.GetPools()
+	pools := map[string]*apisv1alpha1.LocalPool{}
+	for _, cmdExec := range mgr.cmdExecs {
+		backendPools, err := cmdExec.GetPools()
+		if err != nil {
+			return nil, err
+		}
+		for name, pool := range backendPools {
+			pools[name] = pool
+		}
+	}
+	return pools, nil
 }
 
 func (mgr *localPoolManager) GetReplicas() (map[string]*apisv1alpha1.LocalVolumeReplica, error) {
-	return mgr.cmdExec.GetReplicas()
+	replicas := map[string]*apisv1alpha1.LocalVolumeReplica{}
+	for _, cmdExec := range mgr.cmdExecs {
+		backendReplicas, err := cmdExec.GetReplicas()
+		if err != nil {
+			return nil, err
+		}
+		for name, replica := range backendReplicas {
+			replicas[name] = replica
+		}
+	}
+	return replicas, nil
 }
 
 func (mgr *localPoolManager) ResizePhysicalVolumes(localDisks map[string]*apisv1alpha1.LocalDevice) error {
-	return mgr.cmdExec.ResizePhysicalVolumes(localDisks)
+	disksByBackend := map[string]map[string]*apisv1alpha1.LocalDevice{}
+	for name, disk := range localDisks {
+		backend := mgr.lm.systemConfig.GetStorageBackend(disk.Class)
+		if _, exists := disksByBackend[backend]; !exists {
+			disksByBackend[backend] = map[string]*apisv1alpha1.LocalDevice{}
+		}
+		disksByBackend[backend][name] = disk
+	}
+
+	for backend, disks := range disksByBackend {
+		cmdExec, err := mgr.executor(backend)
+		if err != nil {
+			return err
+		}
+		if err = cmdExec.ResizePhysicalVolumes(disks); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (mgr *localPoolManager) ExtendThinPool(tpc *apisv1alpha1.ThinPoolClaim) error {
-	return mgr.cmdExec.ExtendThinPool(tpc)
+	cmdExec, err := mgr.executor(mgr.lm.StorageBackendOfPool(tpc.Spec.Description.PoolName))
+	if err != nil {
+		return err
+	}
+	return cmdExec.ExtendThinPool(tpc)
+}
+
+func (mgr *localPoolManager) AutoExtendThinPool(poolName string, policy *apisv1alpha1.ThinPoolAutoExtendPolicy) (bool, error) {
+	cmdExec, err := mgr.executor(mgr.lm.StorageBackendOfPool(poolName))
+	if err != nil {
+		return false, err
+	}
+	return cmdExec.AutoExtendThinPool(poolName, policy)
 }
 
 func (mgr *localPoolManager) GetThinPools() (map[string]*apisv1alpha1.ThinPoolInfo, error) {
-	return mgr.cmdExec.GetThinPools()
+	thinPools := map[string]*apisv1alpha1.ThinPoolInfo{}
+	for _, cmdExec := range mgr.cmdExecs {
+		backendThinPools, err := cmdExec.GetThinPools()
+		if err != nil {
+			return nil, err
+		}
+		for name, thinPool := range backendThinPools {
+			thinPools[name] = thinPool
+		}
+	}
+	return thinPools, nil
+}
+
+func (mgr *localPoolManager) GetPhysicalVolumeUsage(poolName string, devPath string) (*PhysicalVolumeUsage, error) {
+	cmdExec, err := mgr.executor(mgr.lm.StorageBackendOfPool(poolName))
+	if err != nil {
+		return nil, err
+	}
+	return cmdExec.GetPhysicalVolumeUsage(poolName, devPath)
+}
+
+func (mgr *localPoolManager) EvacuatePhysicalVolume(poolName string, devPath string) error {
+	cmdExec, err := mgr.executor(mgr.lm.StorageBackendOfPool(poolName))
+	if err != nil {
+		return err
+	}
+	return cmdExec.EvacuatePhysicalVolume(poolName, devPath)
+}
+
+func (mgr *localPoolManager) GetPhysicalVolumeEvacuation(poolName string, devPath string) (*PhysicalVolumeEvacuation, error) {
+	cmdExec, err := mgr.executor(mgr.lm.StorageBackendOfPool(poolName))
+	if err != nil {
+		return nil, err
+	}
+	return cmdExec.GetPhysicalVolumeEvacuation(poolName, devPath)
+}
+
+func (mgr *localPoolManager) AbortPhysicalVolumeEvacuation(poolName string, devPath string) error {
+	cmdExec, err := mgr.executor(mgr.lm.StorageBackendOfPool(poolName))
+	if err != nil {
+		return err
+	}
+	return cmdExec.AbortPhysicalVolumeEvacuation(poolName, devPath)
+}
+
+func (mgr *localPoolManager) RemovePhysicalVolume(poolName string, devPath string) error {
+	cmdExec, err := mgr.executor(mgr.lm.StorageBackendOfPool(poolName))
+	if err != nil {
+		return err
+	}
+	return cmdExec.RemovePhysicalVolume(poolName, devPath)
+}
+
+func (mgr *localPoolManager) SetPhysicalVolumeAllocatable(poolName string, devPath string, allocatable bool) error {
+	cmdExec, err := mgr.executor(mgr.lm.StorageBackendOfPool(poolName))
+	if err != nil {
+		return err
+	}
+	return cmdExec.SetPhysicalVolumeAllocatable(poolName, devPath, allocatable)
+}
+
+func (mgr *localPoolManager) executor(backend string) (LocalPoolExecutor, error) {
+	if cmdExec, exists := mgr.cmdExecs[backend]; exists {
+		return cmdExec, nil
+	}
+	return nil, fmt.Errorf("storage backend %s is not enabled", backend)
 }
 
 func newLocalPoolManager(lm *LocalManager) LocalPoolManager {
+	cmdExecs := map[string]LocalPoolExecutor{}
+	for _, backend := range lm.StorageBackends() {
+		switch backend {
+		case apisv1alpha1.StorageBackendLVM:
+			cmdExecs[backend] = newLVMExecutor(lm)
+		case apisv1alpha1.StorageBackendZFS:
+			cmdExecs[backend] = newZFSExecutor(lm)
+		}
+	}
 	return &localPoolManager{
-		cmdExec: newLVMExecutor(lm),
-		lm:      lm,
-		logger:  log.WithField("Module", "NodeManager/LocalPoolManager"),
+		cmdExecs: cmdExecs,
+		lm:       lm,
+		logger:   log.WithField("Module", "NodeManager/LocalPoolManager"),
 	}
 }
 
@@ -130,13 +130,14 @@ func (lr *localRegistry) registerVolumeReplica(replica *apisv1alpha1.LocalVolume
 		}
 		// update volume replica registration data
 		if !replica.Spec.Thin {
-			pool.FreeCapacityBytes = pool.FreeCapacityBytes + oldReplica.Status.AllocatedCapacityBytes - replica.Status.AllocatedCapacityBytes
-			pool.UsedCapacityBytes = pool.UsedCapacityBytes + replica.Status.AllocatedCapacityBytes - oldReplica.Status.AllocatedCapacityBytes
+			changedCapacityBytes := replicaConsumedCapacityBytes(replica, replica.Status.AllocatedCapacityBytes-oldReplica.Status.AllocatedCapacityBytes)
+			pool.FreeCapacityBytes -= changedCapacityBytes
+			pool.UsedCapacityBytes += changedCapacityBytes
 		}
 	} else {
 		if !replica.Spec.Thin {
-			pool.FreeCapacityBytes -= replica.Status.AllocatedCapacityBytes
-			pool.UsedCapacityBytes += replica.Status.AllocatedCapacityBytes
+			pool.FreeCapacityBytes -= replicaConsumedCapacityBytes(replica, replica.Status.AllocatedCapacityBytes)
+			pool.UsedCapacityBytes += replicaConsumedCapacityBytes(replica, replica.Status.AllocatedCapacityBytes)
 		}
 		pool.FreeVolumeCount--
 		pool.UsedVolumeCount++
@@ -179,8 +180,8 @@ func (lr *localRegistry) deregisterVolumeReplica(replica *apisv1alpha1.LocalVolu
 		}
 		pool.ThinPool = thinPools[replica.Spec.PoolName]
 	} else {
-		pool.FreeCapacityBytes += replica.Status.AllocatedCapacityBytes
-		pool.UsedCapacityBytes -= replica.Status.AllocatedCapacityBytes
+		pool.FreeCapacityBytes += replicaConsumedCapacityBytes(replica, replica.Status.AllocatedCapacityBytes)
+		pool.UsedCapacityBytes -= replicaConsumedCapacityBytes(replica, replica.Status.AllocatedCapacityBytes)
 	}
 
 	pool.FreeVolumeCount++
@@ -295,6 +296,12 @@ func (lr *localRegistry) UpdateCondition(condition apisv1alpha1.StorageNodeCondi
 	switch condition.Type {
 	case apisv1alpha1.StorageExpandFailure, apisv1alpha1.StorageUnAvailable:
 		lr.recorder.Event(storageNode, v1.EventTypeWarning, condition.Reason, condition.Message)
+	case apisv1alpha1.StorageThinPoolExtendFailure:
+		if condition.Status == apisv1alpha1.ConditionTrue {
+			lr.recorder.Event(storageNode, v1.EventTypeWarning, condition.Reason, condition.Message)
+		} else {
+			lr.recorder.Event(storageNode, v1.EventTypeNormal, condition.Reason, condition.Message)
+		}
 	case apisv1alpha1.StorageExpandSuccess, apisv1alpha1.StorageProgressing:
 		lr.recorder.Event(storageNode, v1.EventTypeNormal, condition.Reason, condition.Message)
 	default:
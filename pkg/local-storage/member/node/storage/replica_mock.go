@@ -93,6 +93,107 @@ func (mr *MockLocalPoolManagerMockRecorder) ResizePhysicalVolumes(localDisks int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResizePhysicalVolumes", reflect.TypeOf((*MockLocalPoolManager)(nil).ResizePhysicalVolumes), localDisks)
 }
 
+// AbortPhysicalVolumeEvacuation mocks base method.
+func (m *MockLocalPoolManager) AbortPhysicalVolumeEvacuation(poolName, devPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AbortPhysicalVolumeEvacuation", poolName, devPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AbortPhysicalVolumeEvacuation indicates an expected call of AbortPhysicalVolumeEvacuation.
+func (mr *MockLocalPoolManagerMockRecorder) AbortPhysicalVolumeEvacuation(poolName, devPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AbortPhysicalVolumeEvacuation", reflect.TypeOf((*MockLocalPoolManager)(nil).AbortPhysicalVolumeEvacuation), poolName, devPath)
+}
+
+// AutoExtendThinPool mocks base method.
+func (m *MockLocalPoolManager) AutoExtendThinPool(poolName string, policy *v1alpha1.ThinPoolAutoExtendPolicy) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AutoExtendThinPool", poolName, policy)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AutoExtendThinPool indicates an expected call of AutoExtendThinPool.
+func (mr *MockLocalPoolManagerMockRecorder) AutoExtendThinPool(poolName, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AutoExtendThinPool", reflect.TypeOf((*MockLocalPoolManager)(nil).AutoExtendThinPool), poolName, policy)
+}
+
+// EvacuatePhysicalVolume mocks base method.
+func (m *MockLocalPoolManager) EvacuatePhysicalVolume(poolName, devPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EvacuatePhysicalVolume", poolName, devPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EvacuatePhysicalVolume indicates an expected call of EvacuatePhysicalVolume.
+func (mr *MockLocalPoolManagerMockRecorder) EvacuatePhysicalVolume(poolName, devPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvacuatePhysicalVolume", reflect.TypeOf((*MockLocalPoolManager)(nil).EvacuatePhysicalVolume), poolName, devPath)
+}
+
+// GetPhysicalVolumeEvacuation mocks base method.
+func (m *MockLocalPoolManager) GetPhysicalVolumeEvacuation(poolName, devPath string) (*PhysicalVolumeEvacuation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPhysicalVolumeEvacuation", poolName, devPath)
+	ret0, _ := ret[0].(*PhysicalVolumeEvacuation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPhysicalVolumeEvacuation indicates an expected call of GetPhysicalVolumeEvacuation.
+func (mr *MockLocalPoolManagerMockRecorder) GetPhysicalVolumeEvacuation(poolName, devPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPhysicalVolumeEvacuation", reflect.TypeOf((*MockLocalPoolManager)(nil).GetPhysicalVolumeEvacuation), poolName, devPath)
+}
+
+// GetPhysicalVolumeUsage mocks base method.
+func (m *MockLocalPoolManager) GetPhysicalVolumeUsage(poolName, devPath string) (*PhysicalVolumeUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPhysicalVolumeUsage", poolName, devPath)
+	ret0, _ := ret[0].(*PhysicalVolumeUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPhysicalVolumeUsage indicates an expected call of GetPhysicalVolumeUsage.
+func (mr *MockLocalPoolManagerMockRecorder) GetPhysicalVolumeUsage(poolName, devPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPhysicalVolumeUsage", reflect.TypeOf((*MockLocalPoolManager)(nil).GetPhysicalVolumeUsage), poolName, devPath)
+}
+
+// RemovePhysicalVolume mocks base method.
+func (m *MockLocalPoolManager) RemovePhysicalVolume(poolName, devPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePhysicalVolume", poolName, devPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePhysicalVolume indicates an expected call of RemovePhysicalVolume.
+func (mr *MockLocalPoolManagerMockRecorder) RemovePhysicalVolume(poolName, devPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePhysicalVolume", reflect.TypeOf((*MockLocalPoolManager)(nil).RemovePhysicalVolume), poolName, devPath)
+}
+
+// SetPhysicalVolumeAllocatable mocks base method.
+func (m *MockLocalPoolManager) SetPhysicalVolumeAllocatable(poolName, devPath string, allocatable bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPhysicalVolumeAllocatable", poolName, devPath, allocatable)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPhysicalVolumeAllocatable indicates an expected call of SetPhysicalVolumeAllocatable.
+func (mr *MockLocalPoolManagerMockRecorder) SetPhysicalVolumeAllocatable(poolName, devPath, allocatable interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPhysicalVolumeAllocatable", reflect.TypeOf((*MockLocalPoolManager)(nil).SetPhysicalVolumeAllocatable), poolName, devPath, allocatable)
+}
+
 // MockLocalVolumeReplicaManager is a mock of LocalVolumeReplicaManager interface.
 type MockLocalVolumeReplicaManager struct {
 	ctrl     *gomock.Controller
@@ -619,3 +720,104 @@ func (mr *MockLocalPoolExecutorMockRecorder) ResizePhysicalVolumes(localDisks in
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResizePhysicalVolumes", reflect.TypeOf((*MockLocalPoolExecutor)(nil).ResizePhysicalVolumes), localDisks)
 }
+
+// AbortPhysicalVolumeEvacuation mocks base method.
+func (m *MockLocalPoolExecutor) AbortPhysicalVolumeEvacuation(poolName, devPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AbortPhysicalVolumeEvacuation", poolName, devPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AbortPhysicalVolumeEvacuation indicates an expected call of AbortPhysicalVolumeEvacuation.
+func (mr *MockLocalPoolExecutorMockRecorder) AbortPhysicalVolumeEvacuation(poolName, devPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AbortPhysicalVolumeEvacuation", reflect.TypeOf((*MockLocalPoolExecutor)(nil).AbortPhysicalVolumeEvacuation), poolName, devPath)
+}
+
+// AutoExtendThinPool mocks base method.
+func (m *MockLocalPoolExecutor) AutoExtendThinPool(poolName string, policy *v1alpha1.ThinPoolAutoExtendPolicy) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AutoExtendThinPool", poolName, policy)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AutoExtendThinPool indicates an expected call of AutoExtendThinPool.
+func (mr *MockLocalPoolExecutorMockRecorder) AutoExtendThinPool(poolName, policy interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AutoExtendThinPool", reflect.TypeOf((*MockLocalPoolExecutor)(nil).AutoExtendThinPool), poolName, policy)
+}
+
+// EvacuatePhysicalVolume mocks base method.
+func (m *MockLocalPoolExecutor) EvacuatePhysicalVolume(poolName, devPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EvacuatePhysicalVolume", poolName, devPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EvacuatePhysicalVolume indicates an expected call of EvacuatePhysicalVolume.
+func (mr *MockLocalPoolExecutorMockRecorder) EvacuatePhysicalVolume(poolName, devPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvacuatePhysicalVolume", reflect.TypeOf((*MockLocalPoolExecutor)(nil).EvacuatePhysicalVolume), poolName, devPath)
+}
+
+// GetPhysicalVolumeEvacuation mocks base method.
+func (m *MockLocalPoolExecutor) GetPhysicalVolumeEvacuation(poolName, devPath string) (*PhysicalVolumeEvacuation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPhysicalVolumeEvacuation", poolName, devPath)
+	ret0, _ := ret[0].(*PhysicalVolumeEvacuation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPhysicalVolumeEvacuation indicates an expected call of GetPhysicalVolumeEvacuation.
+func (mr *MockLocalPoolExecutorMockRecorder) GetPhysicalVolumeEvacuation(poolName, devPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPhysicalVolumeEvacuation", reflect.TypeOf((*MockLocalPoolExecutor)(nil).GetPhysicalVolumeEvacuation), poolName, devPath)
+}
+
+// GetPhysicalVolumeUsage mocks base method.
+func (m *MockLocalPoolExecutor) GetPhysicalVolumeUsage(poolName, devPath string) (*PhysicalVolumeUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPhysicalVolumeUsage", poolName, devPath)
+	ret0, _ := ret[0].(*PhysicalVolumeUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPhysicalVolumeUsage indicates an expected call of GetPhysicalVolumeUsage.
+func (mr *MockLocalPoolExecutorMockRecorder) GetPhysicalVolumeUsage(poolName, devPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPhysicalVolumeUsage", reflect.TypeOf((*MockLocalPoolExecutor)(nil).GetPhysicalVolumeUsage), poolName, devPath)
+}
+
+// RemovePhysicalVolume mocks base method.
+func (m *MockLocalPoolExecutor) RemovePhysicalVolume(poolName, devPath string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemovePhysicalVolume", poolName, devPath)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemovePhysicalVolume indicates an expected call of RemovePhysicalVolume.
+func (mr *MockLocalPoolExecutorMockRecorder) RemovePhysicalVolume(poolName, devPath interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePhysicalVolume", reflect.TypeOf((*MockLocalPoolExecutor)(nil).RemovePhysicalVolume), poolName, devPath)
+}
+
+// SetPhysicalVolumeAllocatable mocks base method.
+func (m *MockLocalPoolExecutor) SetPhysicalVolumeAllocatable(poolName, devPath string, allocatable bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPhysicalVolumeAllocatable", poolName, devPath, allocatable)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPhysicalVolumeAllocatable indicates an expected call of SetPhysicalVolumeAllocatable.
+func (mr *MockLocalPoolExecutorMockRecorder) SetPhysicalVolumeAllocatable(poolName, devPath, allocatable interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPhysicalVolumeAllocatable", reflect.TypeOf((*MockLocalPoolExecutor)(nil).SetPhysicalVolumeAllocatable), poolName, devPath, allocatable)
+}
@@ -2,25 +2,38 @@ package storage
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 	log "github.com/sirupsen/logrus"
 )
 
 type localVolumeReplicaSnapshotManager struct {
-	cmdExec         LocalVolumeReplicaSnapshotExecutor
+	// executors of the enabled storage backends, e.g. LVM, ZFS
+	cmdExecs        map[string]LocalVolumeReplicaSnapshotExecutor
 	ddExec          LocalVolumeReplicaSnapshotRestoreManager
 	registry        LocalRegistry
 	volumeValidator *validator
 	logger          *log.Entry
+	lm              *LocalManager
 }
 
 func newLocalVolumeReplicaSnapshotManager(lm *LocalManager) LocalVolumeReplicaSnapshotManager {
+	cmdExecs := map[string]LocalVolumeReplicaSnapshotExecutor{}
+	for _, backend := range lm.StorageBackends() {
+		switch backend {
+		case v1alpha1.StorageBackendLVM:
+			cmdExecs[backend] = newLVMExecutor(lm)
+		case v1alpha1.StorageBackendZFS:
+			cmdExecs[backend] = newZFSExecutor(lm)
+		}
+	}
 	return &localVolumeReplicaSnapshotManager{
-		cmdExec:         newLVMExecutor(lm),
+		cmdExecs:        cmdExecs,
 		ddExec:          newDDExecutor(lm.snapshotRestoreTimeout),
 		volumeValidator: newValidator(),
 		registry:        lm.Registry(),
+		lm:              lm,
 		logger:          log.WithField("Module", "NodeManager/LocalVolumeReplicaSnapshotManager"),
 	}
 }
@@ -29,7 +42,11 @@ func (snapMgr *localVolumeReplicaSnapshotManager) CreateVolumeReplicaSnapshot(re
 	snapMgr.logger.Debugf("Creating VolumeReplicaSnapshot. name:%s, pool:%s, size:%d", replicaSnapshot.Name, replicaSnapshot.Spec.PoolName, replicaSnapshot.Spec.RequiredCapacityBytes)
 	if err := snapMgr.volumeValidator.canCreateVolumeReplicaSnapshot(replicaSnapshot, snapMgr.registry); err == nil {
 		// case 1: create snap if not exists
-		err = snapMgr.cmdExec.CreateVolumeReplicaSnapshot(replicaSnapshot)
+		cmdExec, err := snapMgr.executor(replicaSnapshot.Spec.PoolName)
+		if err != nil {
+			return err
+		}
+		err = cmdExec.CreateVolumeReplicaSnapshot(replicaSnapshot)
 		if err != nil {
 			snapMgr.logger.WithError(err).Error("Failed to exec replica snap create")
 			return err
@@ -47,7 +64,11 @@ func (snapMgr *localVolumeReplicaSnapshotManager) CreateVolumeReplicaSnapshot(re
 }
 
 func (snapMgr *localVolumeReplicaSnapshotManager) DeleteVolumeReplicaSnapshot(replicaSnapshot *v1alpha1.LocalVolumeReplicaSnapshot) error {
-	return snapMgr.cmdExec.DeleteVolumeReplicaSnapshot(replicaSnapshot)
+	cmdExec, err := snapMgr.executor(replicaSnapshot.Spec.PoolName)
+	if err != nil {
+		return err
+	}
+	return cmdExec.DeleteVolumeReplicaSnapshot(replicaSnapshot)
 }
 
 func (snapMgr *localVolumeReplicaSnapshotManager) UpdateVolumeReplicaSnapshot(replicaSnapshot *v1alpha1.LocalVolumeReplicaSnapshot) (*v1alpha1.LocalVolumeReplicaSnapshotStatus, error) {
@@ -56,13 +77,37 @@ func (snapMgr *localVolumeReplicaSnapshotManager) UpdateVolumeReplicaSnapshot(re
 }
 
 func (snapMgr *localVolumeReplicaSnapshotManager) GetVolumeReplicaSnapshot(replicaSnapshot *v1alpha1.LocalVolumeReplicaSnapshot) (*v1alpha1.LocalVolumeReplicaSnapshotStatus, error) {
-	return snapMgr.cmdExec.GetVolumeReplicaSnapshot(replicaSnapshot)
+	cmdExec, err := snapMgr.executor(replicaSnapshot.Spec.PoolName)
+	if err != nil {
+		return nil, err
+	}
+	return cmdExec.GetVolumeReplicaSnapshot(replicaSnapshot)
 }
 
 func (snapMgr *localVolumeReplicaSnapshotManager) RollbackVolumeReplicaSnapshot(snapshotRestore *v1alpha1.LocalVolumeReplicaSnapshotRestore) error {
-	return snapMgr.cmdExec.RollbackVolumeReplicaSnapshot(snapshotRestore)
+	cmdExec, err := snapMgr.executor(snapshotRestore.Spec.TargetPoolName)
+	if err != nil {
+		return err
+	}
+	return cmdExec.RollbackVolumeReplicaSnapshot(snapshotRestore)
 }
 
 func (snapMgr *localVolumeReplicaSnapshotManager) RestoreVolumeReplicaSnapshot(snapshotRestore *v1alpha1.LocalVolumeReplicaSnapshotRestore) error {
+	// zfs snapshot can be cloned to the new volume directly
+	if snapMgr.lm.StorageBackendOfPool(snapshotRestore.Spec.TargetPoolName) == v1alpha1.StorageBackendZFS {
+		cmdExec, err := snapMgr.executor(snapshotRestore.Spec.TargetPoolName)
+		if err != nil {
+			return err
+		}
+		return cmdExec.RestoreVolumeReplicaSnapshot(snapshotRestore)
+	}
 	return snapMgr.ddExec.RestoreVolumeReplicaSnapshot(snapshotRestore)
 }
+
+func (snapMgr *localVolumeReplicaSnapshotManager) executor(poolName string) (LocalVolumeReplicaSnapshotExecutor, error) {
+	backend := snapMgr.lm.StorageBackendOfPool(poolName)
+	if cmdExec, exists := snapMgr.cmdExecs[backend]; exists {
+		return cmdExec, nil
+	}
+	return nil, fmt.Errorf("storage backend %s of pool %s is not enabled", backend, poolName)
+}
@@ -4,17 +4,16 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
 
 	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
 	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/encrypt"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/utils"
 	log "github.com/sirupsen/logrus"
-	v1 "k8s.io/api/core/v1"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type localVolumeReplicaManager struct {
-	cmdExec         LocalVolumeExecutor
+	// executors of the enabled storage backends, e.g. LVM, ZFS
+	cmdExecs        map[string]LocalVolumeExecutor
 	volumeValidator *validator
 	registry        LocalRegistry
 	logger          *log.Entry
@@ -22,8 +21,17 @@ type localVolumeReplicaManager struct {
 }
 
 func newLocalVolumeReplicaManager(lm *LocalManager) LocalVolumeReplicaManager {
+	cmdExecs := map[string]LocalVolumeExecutor{}
+	for _, backend := range lm.StorageBackends() {
+		switch backend {
+		case apisv1alpha1.StorageBackendLVM:
+			cmdExecs[backend] = newLVMExecutor(lm)
+		case apisv1alpha1.StorageBackendZFS:
+			cmdExecs[backend] = newZFSExecutor(lm)
+		}
+	}
 	return &localVolumeReplicaManager{
-		cmdExec:         newLVMExecutor(lm),
+		cmdExecs:        cmdExecs,
 		volumeValidator: newValidator(),
 		registry:        lm.Registry(),
 		lm:              lm,
@@ -36,7 +44,12 @@ func (mgr *localVolumeReplicaManager) CreateVolumeReplica(replica *apisv1alpha1.
 	mgr.logger.Debugf("Creating VolumeReplica. name:%s, pool:%s, size:%d", replica.Spec.VolumeName, replica.Spec.PoolName, replica.Spec.RequiredCapacityBytes)
 	if err := mgr.volumeValidator.canCreateVolumeReplica(replica, mgr.registry); err == nil {
 		// case 1: create replica if not exists
-		newReplica, err = mgr.cmdExec.CreateVolumeReplica(replica)
+		cmdExec, err := mgr.executor(replica.Spec.PoolName)
+		if err != nil {
+			mgr.logger.WithError(err).Error("Failed to get executor of the pool")
+			return nil, err
+		}
+		newReplica, err = cmdExec.CreateVolumeReplica(replica)
 		if err != nil {
 			mgr.logger.WithError(err).Error("Failed to exec replica create")
 			return nil, err
@@ -61,12 +74,25 @@ func (mgr *localVolumeReplicaManager) CreateVolumeReplica(replica *apisv1alpha1.
 		var err error
 		lvName := fmt.Sprintf("%s/%s", replica.Spec.PoolName, replica.Spec.VolumeName)
 		mgr.logger.WithField("lvName", lvName).Info("Thin volume should be extended")
-		newReplica, err = mgr.cmdExec.ExpandVolumeReplica(newReplica, replica.Spec.RequiredCapacityBytes)
+		cmdExec, err := mgr.executor(replica.Spec.PoolName)
+		if err != nil {
+			return nil, err
+		}
+		newReplica, err = cmdExec.ExpandVolumeReplica(newReplica, replica.Spec.RequiredCapacityBytes)
 		if err != nil {
 			return nil, err
 		}
 	}
 
+	// attach the cache if needed, the volume is encrypted on the cached device
+	if replica.Spec.VolumeCache != nil {
+		var err error
+		if newReplica, err = mgr.ensureVolumeCache(newReplica); err != nil {
+			mgr.logger.WithError(err).Error("Failed to attach the cache")
+			return nil, err
+		}
+	}
+
 	// encrypt volume if needed
 	if replica.Spec.VolumeEncrypt.Enable {
 		if err := mgr.EncryptVolumeReplica(newReplica); err != nil {
@@ -80,9 +106,9 @@ func (mgr *localVolumeReplicaManager) CreateVolumeReplica(replica *apisv1alpha1.
 }
 
 func (mgr *localVolumeReplicaManager) EncryptVolumeReplica(replica *apisv1alpha1.LocalVolumeReplica) error {
-	key, err := getEncryptSecretKey(mgr.lm.apiClient, replica.Spec.VolumeEncrypt.SecretNamespacedName)
+	key, err := encrypt.GetVolumeKey(mgr.lm.apiClient, &replica.Spec.VolumeEncrypt)
 	if err != nil {
-		mgr.logger.WithError(err).Errorf("Failed to get key from secret %s", replica.Spec.VolumeEncrypt.SecretNamespacedName)
+		mgr.logger.WithError(err).Errorf("Failed to get key from %s", replica.Spec.VolumeEncrypt.KeyProvider)
 		return err
 	}
 
@@ -107,7 +133,18 @@ func (mgr *localVolumeReplicaManager) DeleteVolumeReplica(replica *apisv1alpha1.
 		return err
 	}
 
-	if err := mgr.cmdExec.DeleteVolumeReplica(replica); err != nil {
+	cmdExec, err := mgr.executor(replica.Spec.PoolName)
+	if err != nil {
+		mgr.logger.WithError(err).Error("Failed to get executor of the pool")
+		return err
+	}
+	if lvmExec, ok := cmdExec.(*lvmExecutor); ok {
+		if err := lvmExec.removeVolumeCache(replica.Spec.VolumeName, false); err != nil {
+			mgr.logger.WithError(err).Error("Failed to remove the cache")
+			return err
+		}
+	}
+	if err := cmdExec.DeleteVolumeReplica(replica); err != nil {
 		mgr.logger.WithError(err).Error("Failed to exec replica delete")
 		return err
 	}
@@ -123,13 +160,19 @@ func (mgr *localVolumeReplicaManager) ExpandVolumeReplica(replica *apisv1alpha1.
 		return nil, err
 	}
 
-	newReplica, err := mgr.cmdExec.ExpandVolumeReplica(replica, newCapacityBytes)
+	cmdExec, err := mgr.executor(replica.Spec.PoolName)
+	if err != nil {
+		mgr.logger.WithError(err).Error("Failed to get executor of the pool")
+		return nil, err
+	}
+	newReplica, err := cmdExec.ExpandVolumeReplica(replica, newCapacityBytes)
 	if err != nil {
 		mgr.logger.WithError(err).Error("Failed to exec replica expansion.")
 		return nil, err
 	}
 
-	return newReplica, nil
+	// the cached device is resized with the LV
+	return mgr.ensureVolumeCache(newReplica)
 }
 
 func (mgr *localVolumeReplicaManager) GetVolumeReplica(replica *apisv1alpha1.LocalVolumeReplica) (*apisv1alpha1.LocalVolumeReplica, error) {
@@ -142,7 +185,32 @@ func (mgr *localVolumeReplicaManager) GetVolumeReplica(replica *apisv1alpha1.Loc
 }
 
 func (mgr *localVolumeReplicaManager) TestVolumeReplica(replica *apisv1alpha1.LocalVolumeReplica) (*apisv1alpha1.LocalVolumeReplica, error) {
-	return mgr.cmdExec.TestVolumeReplica(replica)
+	cmdExec, err := mgr.executor(replica.Spec.PoolName)
+	if err != nil {
+		return nil, err
+	}
+	newReplica, err := cmdExec.TestVolumeReplica(replica)
+	if err != nil {
+		return nil, err
+	}
+	// the cached device is lost after the node restarts, and the cache may be changed
+	return mgr.ensureVolumeCache(newReplica)
+}
+
+// ensureVolumeCache attaches, resizes or detaches the cache of the LVM volume replica as required
+func (mgr *localVolumeReplicaManager) ensureVolumeCache(replica *apisv1alpha1.LocalVolumeReplica) (*apisv1alpha1.LocalVolumeReplica, error) {
+	if replica.Spec.VolumeCache == nil && replica.Status.DevicePath != utils.VolumeCacheDevicePath(replica.Spec.VolumeName) {
+		return replica, nil
+	}
+	cmdExec, err := mgr.executor(replica.Spec.PoolName)
+	if err != nil {
+		return nil, err
+	}
+	lvmExec, ok := cmdExec.(*lvmExecutor)
+	if !ok {
+		return nil, fmt.Errorf("cache is only supported for LVM volume")
+	}
+	return lvmExec.ensureVolumeCache(replica)
 }
 
 func (mgr *localVolumeReplicaManager) ConsistencyCheck() {
@@ -154,35 +222,33 @@ func (mgr *localVolumeReplicaManager) ConsistencyCheck() {
 		mgr.logger.Error("Failed to list volume replicas info from CRDs")
 		return
 	}
-	crdReplicas := map[string]*apisv1alpha1.LocalVolumeReplica{}
+	crdReplicas := map[string]map[string]*apisv1alpha1.LocalVolumeReplica{}
+	for backend := range mgr.cmdExecs {
+		crdReplicas[backend] = map[string]*apisv1alpha1.LocalVolumeReplica{}
+	}
 	for i, item := range replicaList.Items {
 		if item.Spec.NodeName != mgr.lm.nodeConf.Name {
 			continue
 		}
-		crdReplicas[item.Spec.VolumeName] = &replicaList.Items[i]
+		backend := mgr.lm.StorageBackendOfPool(item.Spec.PoolName)
+		if _, exists := crdReplicas[backend]; !exists {
+			mgr.logger.WithField("volume", item.Spec.VolumeName).Warningf("Storage backend %s is not enabled", backend)
+			continue
+		}
+		crdReplicas[backend][item.Spec.VolumeName] = &replicaList.Items[i]
 	}
 
-	mgr.cmdExec.ConsistencyCheck(crdReplicas)
+	for backend, cmdExec := range mgr.cmdExecs {
+		cmdExec.ConsistencyCheck(crdReplicas[backend])
+	}
 
 	mgr.logger.Debug("Consistency check completed")
 }
 
-func getEncryptSecretKey(cli client.Client, namespacedName string) (string, error) {
-	secret := &v1.Secret{}
-	ss := strings.Split(namespacedName, "/")
-	if len(ss) != 2 {
-		return "", fmt.Errorf("invalid secret namespaced name %s", namespacedName)
+func (mgr *localVolumeReplicaManager) executor(poolName string) (LocalVolumeExecutor, error) {
+	backend := mgr.lm.StorageBackendOfPool(poolName)
+	if cmdExec, exists := mgr.cmdExecs[backend]; exists {
+		return cmdExec, nil
 	}
-
-	key := client.ObjectKey{Name: ss[1], Namespace: ss[0]}
-	err := cli.Get(context.Background(), key, secret)
-	if err != nil {
-		return "", err
-	}
-
-	if secret.Data == nil || secret.Data["key"] == nil {
-		return "", fmt.Errorf("key is not exist in %s", namespacedName)
-	}
-
-	return string(secret.Data["key"]), nil
+	return nil, fmt.Errorf("storage backend %s of pool %s is not enabled", backend, poolName)
 }
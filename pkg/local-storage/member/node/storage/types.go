@@ -18,8 +18,38 @@ var (
 	ErrorInsufficientRequestResources     = errors.New("insufficient request resources")
 	ErrorOverLimitedRequestResource       = errors.New("over limited request resources")
 	ErrorThinPoolNotFound                 = errors.New("not found thin pool")
+	ErrorPhysicalVolumeNotFound           = errors.New("not found physical volume")
+	ErrorPhysicalVolumeInUse              = errors.New("logical volumes are left on the physical volume")
+	ErrorThinPoolExtendImpossible         = errors.New("unable to extend thin pool")
 )
 
+// PhysicalVolumeUsage describes how a disk in a storage pool is consumed
+type PhysicalVolumeUsage struct {
+	PoolName string
+	DevPath  string
+
+	// UsedBytes is the size of the extents allocated on the disk
+	UsedBytes int64
+
+	// FreeBytesOnOthers is the free capacity of the other disks in the same pool
+	FreeBytesOnOthers int64
+
+	// Replicas are the volume replicas which have data on the disk
+	Replicas []string
+
+	// Movable is false if the data can't be moved by pvmove, e.g. images of a mirrored volume
+	Movable bool
+}
+
+// PhysicalVolumeEvacuation describes the progress of moving the data out of a disk
+type PhysicalVolumeEvacuation struct {
+	// InProgress is true if the data is still being moved in the background
+	InProgress bool
+
+	// Percent is the percentage of the data moved, e.g. "45.20"
+	Percent string
+}
+
 /* A set of interface for Hwameistor Local Object */
 
 // LocalPoolManager is an interface to manage local storage pools
@@ -30,6 +60,8 @@ type LocalPoolManager interface {
 
 	ExtendThinPool(*apisv1alpha1.ThinPoolClaim) error
 
+	AutoExtendThinPool(poolName string, policy *apisv1alpha1.ThinPoolAutoExtendPolicy) (bool, error)
+
 	GetPools() (map[string]*apisv1alpha1.LocalPool, error)
 
 	GetThinPools() (map[string]*apisv1alpha1.ThinPoolInfo, error)
@@ -37,6 +69,18 @@ type LocalPoolManager interface {
 	GetReplicas() (map[string]*apisv1alpha1.LocalVolumeReplica, error)
 
 	ResizePhysicalVolumes(localDisks map[string]*apisv1alpha1.LocalDevice) error
+
+	GetPhysicalVolumeUsage(poolName string, devPath string) (*PhysicalVolumeUsage, error)
+
+	EvacuatePhysicalVolume(poolName string, devPath string) error
+
+	GetPhysicalVolumeEvacuation(poolName string, devPath string) (*PhysicalVolumeEvacuation, error)
+
+	AbortPhysicalVolumeEvacuation(poolName string, devPath string) error
+
+	RemovePhysicalVolume(poolName string, devPath string) error
+
+	SetPhysicalVolumeAllocatable(poolName string, devPath string, allocatable bool) error
 }
 
 // LocalVolumeReplicaManager interface
@@ -122,8 +166,15 @@ type LocalVolumeReplicaSnapshotExecutor interface {
 type LocalPoolExecutor interface {
 	ExtendPools(localDisks []*apisv1alpha1.LocalDevice) (bool, error)
 	ExtendThinPool(*apisv1alpha1.ThinPoolClaim) error
+	AutoExtendThinPool(poolName string, policy *apisv1alpha1.ThinPoolAutoExtendPolicy) (bool, error)
 	GetPools() (map[string]*apisv1alpha1.LocalPool, error)
 	GetThinPools() (map[string]*apisv1alpha1.ThinPoolInfo, error)
 	GetReplicas() (map[string]*apisv1alpha1.LocalVolumeReplica, error)
 	ResizePhysicalVolumes(localDisks map[string]*apisv1alpha1.LocalDevice) error
+	GetPhysicalVolumeUsage(poolName string, devPath string) (*PhysicalVolumeUsage, error)
+	EvacuatePhysicalVolume(poolName string, devPath string) error
+	GetPhysicalVolumeEvacuation(poolName string, devPath string) (*PhysicalVolumeEvacuation, error)
+	AbortPhysicalVolumeEvacuation(poolName string, devPath string) error
+	RemovePhysicalVolume(poolName string, devPath string) error
+	SetPhysicalVolumeAllocatable(poolName string, devPath string, allocatable bool) error
 }
@@ -138,7 +138,10 @@ func (cr *validator) checkPoolCapacity(vr *apisv1alpha1.LocalVolumeReplica, reg
 
 	requiredCapacityBytes := utils.NumericToLVMBytes(vr.Spec.RequiredCapacityBytes)
 	if !vr.Spec.Thin {
-		if pool.FreeCapacityBytes < requiredCapacityBytes {
+		if vr.Spec.Mirror && len(pool.Disks) < apisv1alpha1.LVMMirrorCopies {
+			return ErrorInsufficientRequestResources
+		}
+		if pool.FreeCapacityBytes < replicaConsumedCapacityBytes(vr, requiredCapacityBytes) {
 			return ErrorInsufficientRequestResources
 		}
 		return nil
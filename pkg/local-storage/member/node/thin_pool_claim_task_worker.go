@@ -2,14 +2,20 @@ package node
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 
 	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/utils"
+	utils2 "github.com/hwameistor/hwameistor/pkg/utils"
 )
 
 func (m *manager) startThinPoolClaimTaskWorker(stopCh <-chan struct{}) {
@@ -103,3 +109,83 @@ func (m *manager) updateThinPoolClaimConsumed(tpc *apisv1alpha1.ThinPoolClaim) e
 	tpc.Status.Status = apisv1alpha1.ThinPoolClaimPhaseConsumed
 	return m.apiClient.Status().Update(context.Background(), tpc)
 }
+
+// autoExtendThinPools extends the thin pools whose data or metadata usage reaches the threshold of the autoextend policy,
+// and records the result in the conditions of the LocalStorageNode
+func (m *manager) autoExtendThinPools() {
+	storageNode := &apisv1alpha1.LocalStorageNode{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: m.name}, storageNode); err != nil {
+		m.logger.WithError(err).Error("Failed to get LocalStorageNode")
+		return
+	}
+
+	extended, failures := []string{}, []string{}
+	for poolName, records := range storageNode.Status.ThinPoolExtendRecords {
+		policy := utils.GetThinPoolAutoExtendPolicy(records)
+		if policy == nil {
+			continue
+		}
+		ok, err := m.storageMgr.PoolManager().AutoExtendThinPool(poolName, policy)
+		if err != nil {
+			m.logger.WithField("pool", poolName).WithError(err).Error("Failed to extend thin pool automatically")
+			failures = append(failures, fmt.Sprintf("%s: %s", poolName, err.Error()))
+		} else if ok {
+			extended = append(extended, poolName)
+		}
+	}
+	sort.Strings(extended)
+	sort.Strings(failures)
+
+	for _, condition := range thinPoolExtendConditions(storageNode.Status.Conditions, extended, failures, metav1.Now()) {
+		if err := m.storageMgr.Registry().UpdateCondition(condition); err != nil {
+			m.logger.WithField("condition", condition).WithError(err).Error("Failed to update condition")
+		}
+	}
+}
+
+// thinPoolExtendConditions returns the conditions to update according to the result of the thin pool autoextension.
+// The failure condition is kept True as long as any thin pool can't be extended. The failure of each thin pool
+// is reported in its ThinPoolInfo, so that no more thin volume is allocated in that pool
+func thinPoolExtendConditions(conditions []apisv1alpha1.StorageNodeCondition, extended, failures []string, now metav1.Time) []apisv1alpha1.StorageNodeCondition {
+	result := []apisv1alpha1.StorageNodeCondition{}
+	if len(extended) > 0 {
+		result = append(result, apisv1alpha1.StorageNodeCondition{
+			Type:               apisv1alpha1.StorageThinPoolExtendSuccess,
+			Status:             apisv1alpha1.ConditionTrue,
+			LastUpdateTime:     now,
+			LastTransitionTime: now,
+			Reason:             "Storage" + string(apisv1alpha1.StorageThinPoolExtendSuccess),
+			Message:            fmt.Sprintf("Successfully to extend thin pool %s", strings.Join(extended, ",")),
+		})
+	}
+
+	_, failure := utils2.GetStorageCondition(conditions, apisv1alpha1.StorageThinPoolExtendFailure)
+	if len(failures) > 0 {
+		message := fmt.Sprintf("Failed to extend thin pool, %s", strings.Join(failures, "; "))
+		if failure != nil && failure.Status == apisv1alpha1.ConditionTrue && failure.Message == message {
+			return result
+		}
+		transitionTime := now
+		if failure != nil && failure.Status == apisv1alpha1.ConditionTrue {
+			transitionTime = failure.LastTransitionTime
+		}
+		result = append(result, apisv1alpha1.StorageNodeCondition{
+			Type:               apisv1alpha1.StorageThinPoolExtendFailure,
+			Status:             apisv1alpha1.ConditionTrue,
+			LastUpdateTime:     now,
+			LastTransitionTime: transitionTime,
+			Reason:             "Storage" + string(apisv1alpha1.StorageThinPoolExtendFailure),
+			Message:            message,
+		})
+	} else if failure != nil && failure.Status == apisv1alpha1.ConditionTrue {
+		result = append(result, apisv1alpha1.StorageNodeCondition{
+			Type:               apisv1alpha1.StorageThinPoolExtendFailure,
+			Status:             apisv1alpha1.ConditionFalse,
+			LastUpdateTime:     now,
+			LastTransitionTime: now,
+			Reason:             "Storage" + string(apisv1alpha1.StorageThinPoolExtendFailure),
+			Message:            "Sufficient thin pool capacity",
+		})
+	}
+	return result
+}
@@ -0,0 +1,79 @@
+package node
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+func Test_thinPoolExtendConditions(t *testing.T) {
+	now := metav1.Now()
+	earlier := metav1.NewTime(now.Add(-time.Hour))
+	failed := apisv1alpha1.StorageNodeCondition{
+		Type:               apisv1alpha1.StorageThinPoolExtendFailure,
+		Status:             apisv1alpha1.ConditionTrue,
+		LastTransitionTime: earlier,
+		Message:            "Failed to extend thin pool, LocalStorage_PoolHDD: no space",
+	}
+
+	type want struct {
+		conditionType  apisv1alpha1.StorageNodeConditionType
+		status         apisv1alpha1.ConditionStatus
+		transitionTime metav1.Time
+	}
+	tests := []struct {
+		name       string
+		conditions []apisv1alpha1.StorageNodeCondition
+		extended   []string
+		failures   []string
+		want       []want
+	}{
+		{
+			name: "nothing happens",
+			want: []want{},
+		},
+		{
+			name:     "extended",
+			extended: []string{"LocalStorage_PoolHDD"},
+			want:     []want{{apisv1alpha1.StorageThinPoolExtendSuccess, apisv1alpha1.ConditionTrue, now}},
+		},
+		{
+			name:     "failed for the first time",
+			failures: []string{"LocalStorage_PoolHDD: no space"},
+			want:     []want{{apisv1alpha1.StorageThinPoolExtendFailure, apisv1alpha1.ConditionTrue, now}},
+		},
+		{
+			name:       "failed again with the same reason",
+			conditions: []apisv1alpha1.StorageNodeCondition{failed},
+			failures:   []string{"LocalStorage_PoolHDD: no space"},
+			want:       []want{},
+		},
+		{
+			name:       "failed again with another reason",
+			conditions: []apisv1alpha1.StorageNodeCondition{failed},
+			failures:   []string{"LocalStorage_PoolHDD: no space", "LocalStorage_PoolSSD: no space"},
+			want:       []want{{apisv1alpha1.StorageThinPoolExtendFailure, apisv1alpha1.ConditionTrue, earlier}},
+		},
+		{
+			name:       "recovered",
+			conditions: []apisv1alpha1.StorageNodeCondition{failed},
+			want:       []want{{apisv1alpha1.StorageThinPoolExtendFailure, apisv1alpha1.ConditionFalse, now}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := thinPoolExtendConditions(tt.conditions, tt.extended, tt.failures, now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("thinPoolExtendConditions() got %d conditions, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i].Type != tt.want[i].conditionType || got[i].Status != tt.want[i].status || !got[i].LastTransitionTime.Equal(&tt.want[i].transitionTime) {
+					t.Errorf("thinPoolExtendConditions() got %+v, want %+v", got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/utils/backup"
+)
+
+func (m *manager) startVolumeBackupTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("Volume Backup Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.volumeBackupTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the Volume Backup worker")
+				break
+			}
+			if err := m.processVolumeBackup(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.volumeBackupTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process Volume Backup task, retry later")
+				m.volumeBackupTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a Volume Backup task.")
+				m.volumeBackupTaskQueue.Forget(task)
+			}
+			m.volumeBackupTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.volumeBackupTaskQueue.Shutdown()
+}
+
+func (m *manager) processVolumeBackup(backupName string) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeBackup": backupName})
+	logCtx.Debug("Working on a VolumeBackup task")
+	volumeBackup := &apisv1alpha1.LocalVolumeBackup{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: backupName}, volumeBackup); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get VolumeBackup from cache")
+			return err
+		}
+		logCtx.Info("Not found the VolumeBackup from cache, should be deleted already")
+		return nil
+	}
+
+	// the backup is submitted and aborted by the controller, node only uploads the data
+	if volumeBackup.Status.NodeName != m.name || volumeBackup.Status.State != apisv1alpha1.OperationStateInProgress ||
+		volumeBackup.Spec.Abort || volumeBackup.DeletionTimestamp != nil {
+		return nil
+	}
+	return m.backupVolumeSnapshot(volumeBackup)
+}
+
+func (m *manager) backupVolumeSnapshot(volumeBackup *apisv1alpha1.LocalVolumeBackup) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeBackup": volumeBackup.Name, "Spec": volumeBackup.Spec, "Status": volumeBackup.Status})
+	logCtx.Debug("Backing up a VolumeSnapshot")
+
+	replicaSnapshot, err := m.getMyVolumeReplicaSnapshot(volumeBackup.Spec.SourceVolumeSnapshot)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get VolumeReplicaSnapshot")
+		return err
+	}
+
+	backupManager, err := backup.NewManagerForTarget(m.apiClient, volumeBackup.Spec.Target)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to connect to backup target")
+		return m.volumeBackupFailed(volumeBackup, err)
+	}
+
+	devicePath := m.Storage().VolumeReplicaSnapshotDevicePath(replicaSnapshot)
+	device, err := os.Open(devicePath)
+	if err != nil {
+		logCtx.WithError(err).WithField("device", devicePath).Error("Failed to open snapshot device")
+		return err
+	}
+	defer device.Close()
+
+	sizeBytes, err := device.Seek(0, io.SeekEnd)
+	if err != nil {
+		logCtx.WithError(err).WithField("device", devicePath).Error("Failed to get size of snapshot device")
+		return err
+	}
+
+	// register the run before updating the status, so the abort happening after the update can cancel it,
+	// and the one happening before fails the update by conflict
+	ctx := m.startVolumeBackupRun(volumeBackup.Name)
+	defer m.cancelVolumeBackupRun(volumeBackup.Name)
+
+	startTime := metav1.Now()
+	volumeBackup.Status.StartTime = &startTime
+	if err = m.apiClient.Status().Update(context.TODO(), volumeBackup); err != nil {
+		return err
+	}
+
+	chunkSizeBytes := volumeBackup.Spec.ChunkSizeBytes
+	if chunkSizeBytes == 0 {
+		chunkSizeBytes = apisv1alpha1.BackupDefaultChunkSizeBytes
+	}
+	_, stats, err := backupManager.Backup(ctx, volumeBackup.Name, volumeBackup.Status.SourceVolume,
+		volumeBackup.Status.ParentBackup, device, sizeBytes, chunkSizeBytes)
+	if err != nil && ctx.Err() != nil {
+		// the state is set to Aborted by the controller
+		logCtx.Info("VolumeBackup is aborted, stop uploading")
+		return nil
+	}
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to back up VolumeSnapshot")
+		return m.volumeBackupFailed(volumeBackup, err)
+	}
+
+	completionTime := metav1.Now()
+	volumeBackup.Status.ManifestKey = backupManager.ManifestKey(volumeBackup.Name)
+	volumeBackup.Status.VolumeCapacityBytes = sizeBytes
+	volumeBackup.Status.TotalChunks = stats.TotalChunks
+	volumeBackup.Status.UploadedChunks = stats.UploadedChunks
+	volumeBackup.Status.UploadedBytes = stats.UploadedBytes
+	volumeBackup.Status.CompletionTime = &completionTime
+	volumeBackup.Status.State = apisv1alpha1.OperationStateCompleted
+	volumeBackup.Status.Message = ""
+	return m.apiClient.Status().Update(context.TODO(), volumeBackup)
+}
+
+// startVolumeBackupRun returns the context of the upload, which is cancelled once the backup is aborted or deleted
+func (m *manager) startVolumeBackupRun(name string) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.volumeBackupCancels == nil {
+		m.volumeBackupCancels = map[string]context.CancelFunc{}
+	}
+	m.volumeBackupCancels[name] = cancel
+	return ctx
+}
+
+func (m *manager) cancelVolumeBackupRun(name string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if cancel, exists := m.volumeBackupCancels[name]; exists {
+		cancel()
+		delete(m.volumeBackupCancels, name)
+	}
+}
+
+func (m *manager) volumeBackupFailed(volumeBackup *apisv1alpha1.LocalVolumeBackup, err error) error {
+	volumeBackup.Status.State = apisv1alpha1.OperationStateFailed
+	volumeBackup.Status.Message = err.Error()
+	return m.apiClient.Status().Update(context.TODO(), volumeBackup)
+}
+
+func (m *manager) getMyVolumeReplicaSnapshot(volumeSnapshotName string) (*apisv1alpha1.LocalVolumeReplicaSnapshot, error) {
+	volumeSnapshot := &apisv1alpha1.LocalVolumeSnapshot{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeSnapshotName}, volumeSnapshot); err != nil {
+		return nil, err
+	}
+	for _, replicaSnapshotName := range volumeSnapshot.Status.ReplicaSnapshots {
+		replicaSnapshot := &apisv1alpha1.LocalVolumeReplicaSnapshot{}
+		if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: replicaSnapshotName}, replicaSnapshot); err != nil {
+			return nil, err
+		}
+		if replicaSnapshot.Spec.NodeName == m.name {
+			return replicaSnapshot, nil
+		}
+	}
+	return nil, fmt.Errorf("not found replica snapshot of %s on node %s", volumeSnapshotName, m.name)
+}
+
+func (m *manager) startVolumeBackupRestoreTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("Volume Backup Restore Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.volumeBackupRestoreTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the Volume Backup Restore worker")
+				break
+			}
+			if err := m.processVolumeBackupRestore(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.volumeBackupRestoreTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process Volume Backup Restore task, retry later")
+				m.volumeBackupRestoreTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a Volume Backup Restore task.")
+				m.volumeBackupRestoreTaskQueue.Forget(task)
+			}
+			m.volumeBackupRestoreTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.volumeBackupRestoreTaskQueue.Shutdown()
+}
+
+func (m *manager) processVolumeBackupRestore(restoreName string) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeBackupRestore": restoreName})
+	logCtx.Debug("Working on a VolumeBackupRestore task")
+	backupRestore := &apisv1alpha1.LocalVolumeBackupRestore{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: restoreName}, backupRestore); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get VolumeBackupRestore from cache")
+			return err
+		}
+		logCtx.Info("Not found the VolumeBackupRestore from cache, should be deleted already")
+		return nil
+	}
+
+	// the target volume is created by the controller, node only downloads the data
+	if backupRestore.Status.NodeName != m.name || backupRestore.Status.State != apisv1alpha1.OperationStateInProgress ||
+		backupRestore.Spec.Abort || backupRestore.DeletionTimestamp != nil {
+		return nil
+	}
+	return m.restoreVolumeFromBackup(backupRestore)
+}
+
+func (m *manager) restoreVolumeFromBackup(backupRestore *apisv1alpha1.LocalVolumeBackupRestore) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeBackupRestore": backupRestore.Name, "Spec": backupRestore.Spec})
+	logCtx.Debug("Restoring a VolumeBackupRestore")
+
+	volumeBackup := &apisv1alpha1.LocalVolumeBackup{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: backupRestore.Spec.SourceBackup}, volumeBackup); err != nil {
+		logCtx.WithError(err).Error("Failed to get source VolumeBackup")
+		return err
+	}
+
+	replica, err := m.getMyVolumeReplica(backupRestore.Spec.TargetVolume)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get replica of target volume")
+		return err
+	}
+	if replica.Status.State != apisv1alpha1.VolumeReplicaStateReady || len(replica.Status.DevicePath) == 0 {
+		return fmt.Errorf("replica %s of target volume is not ready", replica.Name)
+	}
+
+	// consider data security, never overwrite a volume in use
+	if len(m.mounter.GetDeviceMountPoints(replica.Status.DevicePath)) > 0 {
+		return m.volumeBackupRestoreFailed(backupRestore, fmt.Errorf("target volume is already mounted, cannot restore from backup now"))
+	}
+
+	backupManager, err := backup.NewManagerForTarget(m.apiClient, volumeBackup.Spec.Target)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to connect to backup target")
+		return m.volumeBackupRestoreFailed(backupRestore, err)
+	}
+
+	device, err := os.OpenFile(replica.Status.DevicePath, os.O_WRONLY, 0)
+	if err != nil {
+		logCtx.WithError(err).WithField("device", replica.Status.DevicePath).Error("Failed to open target device")
+		return err
+	}
+	defer device.Close()
+
+	// register the run and then check the latest one in cache again,
+	// so the abort happening at any time can stop the download
+	ctx := m.startVolumeBackupRestoreRun(backupRestore.Name)
+	defer m.cancelVolumeBackupRestoreRun(backupRestore.Name)
+	latestRestore := &apisv1alpha1.LocalVolumeBackupRestore{}
+	if err = m.apiClient.Get(context.TODO(), client.ObjectKey{Name: backupRestore.Name}, latestRestore); err != nil {
+		return err
+	}
+	if latestRestore.Spec.Abort || latestRestore.DeletionTimestamp != nil {
+		return nil
+	}
+
+	restoredChunks, err := backupManager.Restore(ctx, volumeBackup.Name, device)
+	if err == nil {
+		err = device.Sync()
+	}
+	if err != nil && ctx.Err() != nil {
+		// the state is set to Aborted by the controller, the target volume is left with the partial data
+		logCtx.Info("VolumeBackupRestore is aborted, stop downloading")
+		return nil
+	}
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to restore VolumeBackup")
+		return m.volumeBackupRestoreFailed(backupRestore, err)
+	}
+
+	completionTime := metav1.Now()
+	backupRestore.Status.RestoredChunks = restoredChunks
+	backupRestore.Status.CompletionTime = &completionTime
+	backupRestore.Status.State = apisv1alpha1.OperationStateCompleted
+	backupRestore.Status.Message = ""
+	return m.apiClient.Status().Update(context.TODO(), backupRestore)
+}
+
+// startVolumeBackupRestoreRun returns the context of the download, which is cancelled once the restore is aborted or deleted
+func (m *manager) startVolumeBackupRestoreRun(name string) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.volumeBackupRestoreCancels == nil {
+		m.volumeBackupRestoreCancels = map[string]context.CancelFunc{}
+	}
+	m.volumeBackupRestoreCancels[name] = cancel
+	return ctx
+}
+
+func (m *manager) cancelVolumeBackupRestoreRun(name string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if cancel, exists := m.volumeBackupRestoreCancels[name]; exists {
+		cancel()
+		delete(m.volumeBackupRestoreCancels, name)
+	}
+}
+
+func (m *manager) volumeBackupRestoreFailed(backupRestore *apisv1alpha1.LocalVolumeBackupRestore, err error) error {
+	backupRestore.Status.State = apisv1alpha1.OperationStateFailed
+	backupRestore.Status.Message = err.Error()
+	return m.apiClient.Status().Update(context.TODO(), backupRestore)
+}
@@ -0,0 +1,54 @@
+package node
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/common"
+)
+
+func Test_manager_handleVolumeBackupUpdateEvent(t *testing.T) {
+	deletionTime := metav1.Now()
+	tests := []struct {
+		name          string
+		abort         bool
+		deleted       bool
+		wantCancelled bool
+	}{
+		{name: "running"},
+		{name: "aborted", abort: true, wantCancelled: true},
+		{name: "deleted", deleted: true, wantCancelled: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newFakeDecommissionManager(t)
+			m.volumeBackupTaskQueue = common.NewTaskQueue("VolumeBackupTask", 0)
+			m.volumeBackupRestoreTaskQueue = common.NewTaskQueue("VolumeBackupRestoreTask", 0)
+
+			volumeBackup := &apisv1alpha1.LocalVolumeBackup{ObjectMeta: metav1.ObjectMeta{Name: "backup-1"}}
+			volumeBackup.Spec.Abort = tt.abort
+			volumeBackup.Status.NodeName = fakeNodename
+			backupRestore := &apisv1alpha1.LocalVolumeBackupRestore{ObjectMeta: metav1.ObjectMeta{Name: "restore-1"}}
+			backupRestore.Spec.Abort = tt.abort
+			backupRestore.Status.NodeName = fakeNodename
+			if tt.deleted {
+				volumeBackup.DeletionTimestamp = &deletionTime
+				backupRestore.DeletionTimestamp = &deletionTime
+			}
+
+			backupCtx := m.startVolumeBackupRun(volumeBackup.Name)
+			restoreCtx := m.startVolumeBackupRestoreRun(backupRestore.Name)
+			m.handleVolumeBackupUpdateEvent(nil, volumeBackup)
+			m.handleVolumeBackupRestoreUpdateEvent(nil, backupRestore)
+
+			if cancelled := backupCtx.Err() != nil; cancelled != tt.wantCancelled {
+				t.Errorf("handleVolumeBackupUpdateEvent() got cancelled %v, want %v", cancelled, tt.wantCancelled)
+			}
+			if cancelled := restoreCtx.Err() != nil; cancelled != tt.wantCancelled {
+				t.Errorf("handleVolumeBackupRestoreUpdateEvent() got cancelled %v, want %v", cancelled, tt.wantCancelled)
+			}
+		})
+	}
+}
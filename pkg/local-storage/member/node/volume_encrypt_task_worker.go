@@ -0,0 +1,273 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/encrypt"
+)
+
+const (
+	// interval to report the progress of the running encryption
+	volumeEncryptCheckInterval = 30 * time.Second
+)
+
+// volumeEncryptRun is the in-place encryption running in background. It's lost when the node restarts,
+// and the encryption is resumed from the LUKS2 header then
+type volumeEncryptRun struct {
+	lock     sync.Mutex
+	progress float64
+	done     bool
+	err      error
+	cancel   context.CancelFunc
+	finished chan struct{}
+}
+
+func (r *volumeEncryptRun) state() (float64, bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.progress, r.done, r.err
+}
+
+func (r *volumeEncryptRun) setProgress(percent float64) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.progress = percent
+}
+
+func (r *volumeEncryptRun) finish(err error) {
+	r.lock.Lock()
+	r.done, r.err = true, err
+	r.lock.Unlock()
+	close(r.finished)
+}
+
+func (m *manager) startVolumeEncryptTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("Volume Encrypt Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.volumeEncryptTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the Volume Encrypt worker")
+				break
+			}
+			if err := m.processVolumeEncrypt(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.volumeEncryptTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process Volume Encrypt task, retry later")
+				m.volumeEncryptTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a Volume Encrypt task.")
+				m.volumeEncryptTaskQueue.Forget(task)
+			}
+			m.volumeEncryptTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.volumeEncryptTaskQueue.Shutdown()
+}
+
+func (m *manager) processVolumeEncrypt(volEncryptName string) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeEncrypt": volEncryptName})
+	logCtx.Debug("Working on a VolumeEncrypt task")
+	volEncrypt := &apisv1alpha1.LocalVolumeEncrypt{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: volEncryptName}, volEncrypt); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get VolumeEncrypt from cache")
+			return err
+		}
+		logCtx.Info("Not found the VolumeEncrypt from cache, should be deleted already")
+		return nil
+	}
+
+	// the encryption is submitted and switched by the controller, node only encrypts the data in place
+	switch volEncrypt.Status.State {
+	case apisv1alpha1.OperationStateEncryptInitialize:
+		return m.volumeEncryptInitialize(volEncrypt, encrypt.NewLUKS())
+	case apisv1alpha1.OperationStateEncryptReencrypt:
+		return m.volumeEncryptReencrypt(volEncrypt, encrypt.NewLUKS())
+	}
+	return nil
+}
+
+// volumeEncryptInitialize extends the replica for the LUKS2 header and initializes the encryption. The volume is
+// switched back by Rollback if failed, as the data is still in plain text
+func (m *manager) volumeEncryptInitialize(volEncrypt *apisv1alpha1.LocalVolumeEncrypt, encryptor encrypt.Encryptor) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeEncrypt": volEncrypt.Name, "volume": volEncrypt.Spec.VolumeName})
+
+	if volEncrypt.Spec.Abort {
+		volEncrypt.Status.State = apisv1alpha1.OperationStateRollback
+		volEncrypt.Status.Message = "aborted by user"
+		return m.apiClient.Status().Update(context.TODO(), volEncrypt)
+	}
+
+	replica, err := m.getMyVolumeReplica(volEncrypt.Spec.VolumeName)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get VolumeReplica")
+		return err
+	}
+	if replica.Spec.VolumeEncrypt.Type != "LUKS" {
+		return m.volumeEncryptRollback(volEncrypt, fmt.Sprintf("unsupported encrypt type %s", replica.Spec.VolumeEncrypt.Type))
+	}
+	key, err := encrypt.GetVolumeKey(m.apiClient, &replica.Spec.VolumeEncrypt)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get key")
+		return err
+	}
+
+	// the header is initialized already if failed to update the state last time
+	encrypted, err := encryptor.IsVolumeEncrypted(replica.Status.DevicePath)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to check if volume is encrypted")
+		return err
+	}
+	if !encrypted {
+		// the volume is extended for the header by the controller already, it's only checked again here
+		requiredCapacityBytes := volEncrypt.Status.OriginalCapacityBytes + encrypt.LUKSHeaderReserveBytes
+		if replica.Status.AllocatedCapacityBytes < requiredCapacityBytes {
+			logCtx.WithField("capacity", requiredCapacityBytes).Info("Extending volume replica for the LUKS2 header")
+			newReplica, err := m.Storage().VolumeReplicaManager().ExpandVolumeReplica(replica, requiredCapacityBytes)
+			if err != nil {
+				return m.volumeEncryptRollback(volEncrypt, fmt.Sprintf("failed to extend volume replica for the LUKS2 header: %v", err))
+			}
+			if err := m.apiClient.Status().Update(context.TODO(), newReplica); err != nil {
+				logCtx.WithError(err).Error("Failed to update VolumeReplica")
+				return err
+			}
+		}
+		if err := encryptor.InitEncryptVolume(replica.Status.DevicePath, key, encrypt.LUKSHeaderReserveBytes); err != nil {
+			return m.volumeEncryptRollback(volEncrypt, fmt.Sprintf("failed to initialize the encryption: %v", err))
+		}
+	}
+
+	logCtx.Info("Initialized the encryption of volume replica")
+	volEncrypt.Status.State = apisv1alpha1.OperationStateEncryptReencrypt
+	return m.apiClient.Status().Update(context.TODO(), volEncrypt)
+}
+
+// volumeEncryptReencrypt encrypts the data in background, and reports the progress periodically. It's resumed from
+// the LUKS2 header if not running, e.g. the node restarts
+func (m *manager) volumeEncryptReencrypt(volEncrypt *apisv1alpha1.LocalVolumeEncrypt, encryptor encrypt.Encryptor) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeEncrypt": volEncrypt.Name, "volume": volEncrypt.Spec.VolumeName})
+
+	m.lock.Lock()
+	run, exists := m.volumeEncryptRuns[volEncrypt.Name]
+	m.lock.Unlock()
+
+	if volEncrypt.Spec.Abort {
+		if !exists {
+			return m.volumeEncryptInterrupt(volEncrypt)
+		}
+		// interrupt the running encryption, and check whether it's completed just before that
+		run.cancel()
+		<-run.finished
+	}
+
+	if !exists {
+		replica, err := m.getMyVolumeReplica(volEncrypt.Spec.VolumeName)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to get VolumeReplica")
+			return err
+		}
+		key, err := encrypt.GetVolumeKey(m.apiClient, &replica.Spec.VolumeEncrypt)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to get key")
+			return err
+		}
+
+		logCtx.Info("Encrypting the data of volume replica")
+		m.startVolumeEncryptRun(volEncrypt.Name, func(ctx context.Context, run *volumeEncryptRun) error {
+			return encryptor.ResumeEncryptVolume(ctx, replica.Status.DevicePath, key, run.setProgress)
+		})
+		m.volumeEncryptTaskQueue.AddAfter(volEncrypt.Name, volumeEncryptCheckInterval)
+		return nil
+	}
+
+	progress, done, err := run.state()
+	if !done {
+		if int64(progress) != volEncrypt.Status.Progress {
+			volEncrypt.Status.Progress = int64(progress)
+			if err := m.apiClient.Status().Update(context.TODO(), volEncrypt); err != nil {
+				return err
+			}
+		}
+		m.volumeEncryptTaskQueue.AddAfter(volEncrypt.Name, volumeEncryptCheckInterval)
+		return nil
+	}
+
+	completionTime := metav1.Now()
+	volEncrypt.Status.CompletionTime = &completionTime
+	if err != nil && volEncrypt.Spec.Abort {
+		volEncrypt.Status.Progress = int64(progress)
+		if err := m.volumeEncryptInterrupt(volEncrypt); err != nil {
+			return err
+		}
+		m.removeVolumeEncryptRun(volEncrypt.Name)
+		return nil
+	}
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to encrypt the data of volume replica")
+		volEncrypt.Status.State = apisv1alpha1.OperationStateFailed
+		volEncrypt.Status.Message = fmt.Sprintf("%v, the encryption can be resumed by another LocalVolumeEncrypt of the volume", err)
+	} else {
+		logCtx.Info("Encrypted the data of volume replica")
+		volEncrypt.Status.Progress = 100
+		volEncrypt.Status.State = apisv1alpha1.OperationStateCompleted
+		volEncrypt.Status.Message = ""
+	}
+	if err := m.apiClient.Status().Update(context.TODO(), volEncrypt); err != nil {
+		return err
+	}
+	m.removeVolumeEncryptRun(volEncrypt.Name)
+	return nil
+}
+
+// volumeEncryptInterrupt aborts the encryption, the volume keeps the LUKS2 header in reencryption state
+func (m *manager) volumeEncryptInterrupt(volEncrypt *apisv1alpha1.LocalVolumeEncrypt) error {
+	m.logger.WithFields(log.Fields{"VolumeEncrypt": volEncrypt.Name, "progress": volEncrypt.Status.Progress}).Info("Interrupted the encryption of volume replica")
+	completionTime := metav1.Now()
+	volEncrypt.Status.CompletionTime = &completionTime
+	volEncrypt.Status.State = apisv1alpha1.OperationStateAborted
+	volEncrypt.Status.Message = "interrupted, the encryption can be resumed by another LocalVolumeEncrypt of the volume"
+	return m.apiClient.Status().Update(context.TODO(), volEncrypt)
+}
+
+// startVolumeEncryptRun runs the encryption in background
+func (m *manager) startVolumeEncryptRun(name string, encryptFunc func(ctx context.Context, run *volumeEncryptRun) error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &volumeEncryptRun{cancel: cancel, finished: make(chan struct{})}
+
+	m.lock.Lock()
+	if m.volumeEncryptRuns == nil {
+		m.volumeEncryptRuns = map[string]*volumeEncryptRun{}
+	}
+	m.volumeEncryptRuns[name] = run
+	m.lock.Unlock()
+
+	go func() {
+		run.finish(encryptFunc(ctx, run))
+	}()
+}
+
+func (m *manager) removeVolumeEncryptRun(name string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if run, exists := m.volumeEncryptRuns[name]; exists {
+		run.cancel()
+		delete(m.volumeEncryptRuns, name)
+	}
+}
+
+func (m *manager) volumeEncryptRollback(volEncrypt *apisv1alpha1.LocalVolumeEncrypt, message string) error {
+	m.logger.WithFields(log.Fields{"VolumeEncrypt": volEncrypt.Name, "message": message}).Error("Failed to initialize the encryption, roll back")
+	volEncrypt.Status.State = apisv1alpha1.OperationStateRollback
+	volEncrypt.Status.Message = message
+	return m.apiClient.Status().Update(context.TODO(), volEncrypt)
+}
@@ -0,0 +1,90 @@
+package node
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/common"
+)
+
+func Test_manager_volumeEncryptReencrypt(t *testing.T) {
+	tests := []struct {
+		name         string
+		abort        bool
+		complete     bool
+		wantState    apisv1alpha1.State
+		wantProgress int64
+	}{
+		{
+			name:         "running",
+			wantState:    apisv1alpha1.OperationStateEncryptReencrypt,
+			wantProgress: 40,
+		},
+		{
+			name:         "completed",
+			complete:     true,
+			wantState:    apisv1alpha1.OperationStateCompleted,
+			wantProgress: 100,
+		},
+		{
+			name:         "interrupted by abort",
+			abort:        true,
+			wantState:    apisv1alpha1.OperationStateAborted,
+			wantProgress: 40,
+		},
+		{
+			name:         "completed before abort",
+			abort:        true,
+			complete:     true,
+			wantState:    apisv1alpha1.OperationStateCompleted,
+			wantProgress: 100,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			volEncrypt := &apisv1alpha1.LocalVolumeEncrypt{ObjectMeta: metav1.ObjectMeta{Name: "encrypt"}}
+			volEncrypt.Spec.VolumeName = "pvc-1"
+			volEncrypt.Spec.Abort = tt.abort
+			volEncrypt.Status.NodeName = fakeNodename
+			volEncrypt.Status.State = apisv1alpha1.OperationStateEncryptReencrypt
+			m := newFakeDecommissionManager(t, volEncrypt)
+			m.volumeEncryptTaskQueue = common.NewTaskQueue("VolumeEncryptTask", 0)
+			defer m.volumeEncryptTaskQueue.Shutdown()
+
+			reported := make(chan struct{})
+			m.startVolumeEncryptRun(volEncrypt.Name, func(ctx context.Context, run *volumeEncryptRun) error {
+				run.setProgress(40)
+				close(reported)
+				if tt.complete {
+					return nil
+				}
+				<-ctx.Done()
+				return ctx.Err()
+			})
+			<-reported
+			if tt.complete {
+				<-m.volumeEncryptRuns[volEncrypt.Name].finished
+			}
+
+			if err := m.volumeEncryptReencrypt(volEncrypt, &fakeEncryptor{}); err != nil {
+				t.Fatalf("volumeEncryptReencrypt() error = %v", err)
+			}
+			got := &apisv1alpha1.LocalVolumeEncrypt{}
+			if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: volEncrypt.Name}, got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status.State != tt.wantState || got.Status.Progress != tt.wantProgress {
+				t.Errorf("volumeEncryptReencrypt() got state %v progress %d, want %v progress %d",
+					got.Status.State, got.Status.Progress, tt.wantState, tt.wantProgress)
+			}
+			if _, exists := m.volumeEncryptRuns[volEncrypt.Name]; exists != (tt.wantState == apisv1alpha1.OperationStateEncryptReencrypt) {
+				t.Errorf("volumeEncryptReencrypt() got run exists %v", exists)
+			}
+			m.removeVolumeEncryptRun(volEncrypt.Name)
+		})
+	}
+}
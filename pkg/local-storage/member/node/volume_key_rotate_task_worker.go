@@ -0,0 +1,163 @@
+package node
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/encrypt"
+)
+
+func (m *manager) startVolumeKeyRotateTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("Volume KeyRotate Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.volumeKeyRotateTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the Volume KeyRotate worker")
+				break
+			}
+			if err := m.processVolumeKeyRotate(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.volumeKeyRotateTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process Volume KeyRotate task, retry later")
+				m.volumeKeyRotateTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a Volume KeyRotate task.")
+				m.volumeKeyRotateTaskQueue.Forget(task)
+			}
+			m.volumeKeyRotateTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.volumeKeyRotateTaskQueue.Shutdown()
+}
+
+func (m *manager) processVolumeKeyRotate(keyRotateName string) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeKeyRotate": keyRotateName})
+	logCtx.Debug("Working on a VolumeKeyRotate task")
+	keyRotate := &apisv1alpha1.LocalVolumeKeyRotate{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: keyRotateName}, keyRotate); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get VolumeKeyRotate from cache")
+			return err
+		}
+		logCtx.Info("Not found the VolumeKeyRotate from cache, should be deleted already")
+		return nil
+	}
+
+	// the rotation is submitted, switched and completed by the controller, node only adds or removes the keys
+	switch keyRotate.Status.State {
+	case apisv1alpha1.OperationStateKeyRotateAddKey, apisv1alpha1.OperationStateKeyRotateRemoveKey, apisv1alpha1.OperationStateRollback:
+	default:
+		return nil
+	}
+
+	for i := range keyRotate.Status.Replicas {
+		replicaStatus := &keyRotate.Status.Replicas[i]
+		if replicaStatus.NodeName != m.name {
+			continue
+		}
+		nextState, err := m.rotateVolumeReplicaKey(keyRotate, replicaStatus.State)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to rotate the key of volume replica")
+			nextState = apisv1alpha1.OperationStateFailed
+			replicaStatus.Message = err.Error()
+		}
+		if nextState == replicaStatus.State {
+			return nil
+		}
+		replicaStatus.State = nextState
+		return m.apiClient.Status().Update(context.TODO(), keyRotate)
+	}
+	return nil
+}
+
+// rotateVolumeReplicaKey adds, removes or rolls back the key of the local replica according to the phase of the rotation,
+// and returns the next state of the replica
+func (m *manager) rotateVolumeReplicaKey(keyRotate *apisv1alpha1.LocalVolumeKeyRotate, replicaState apisv1alpha1.State) (apisv1alpha1.State, error) {
+	switch {
+	case keyRotate.Status.State == apisv1alpha1.OperationStateKeyRotateAddKey && replicaState == "":
+	case keyRotate.Status.State == apisv1alpha1.OperationStateKeyRotateRemoveKey && replicaState == apisv1alpha1.OperationStateKeyRotateKeyAdded:
+	case keyRotate.Status.State == apisv1alpha1.OperationStateRollback && (replicaState == "" || replicaState == apisv1alpha1.OperationStateKeyRotateKeyAdded):
+	default:
+		return replicaState, nil
+	}
+
+	replica, err := m.getMyVolumeReplica(keyRotate.Spec.VolumeName)
+	if err != nil {
+		return replicaState, fmt.Errorf("failed to get volume replica: %v", err)
+	}
+	if replica.Spec.VolumeEncrypt.Type != "LUKS" {
+		return replicaState, fmt.Errorf("unsupported encrypt type %s", replica.Spec.VolumeEncrypt.Type)
+	}
+	provider, err := encrypt.NewKeyProvider(m.apiClient, &replica.Spec.VolumeEncrypt)
+	if err != nil {
+		return replicaState, err
+	}
+	oldKey, err := provider.GetKey(keyRotate.Status.OldKey)
+	if err != nil {
+		return replicaState, fmt.Errorf("failed to get old key: %v", err)
+	}
+	newKey, err := provider.GetKey(keyRotate.Status.NewKey)
+	if err != nil {
+		return replicaState, fmt.Errorf("failed to get new key: %v", err)
+	}
+
+	encryptor := encrypt.NewLUKS()
+	switch keyRotate.Status.State {
+	case apisv1alpha1.OperationStateKeyRotateAddKey:
+		if err := addVolumeReplicaKey(encryptor, replica.Status.DevicePath, oldKey, newKey); err != nil {
+			return replicaState, err
+		}
+		return apisv1alpha1.OperationStateKeyRotateKeyAdded, nil
+	case apisv1alpha1.OperationStateKeyRotateRemoveKey:
+		if err := removeVolumeReplicaKey(encryptor, replica.Status.DevicePath, oldKey, newKey); err != nil {
+			return replicaState, err
+		}
+		return apisv1alpha1.OperationStateCompleted, nil
+	default:
+		if err := removeVolumeReplicaKey(encryptor, replica.Status.DevicePath, newKey, oldKey); err != nil {
+			return replicaState, err
+		}
+		return apisv1alpha1.OperationStateAborted, nil
+	}
+}
+
+// addVolumeReplicaKey adds the new key into a free key slot and verifies it. It's skipped if the new key works already
+func addVolumeReplicaKey(encryptor encrypt.Encryptor, devicePath string, oldKey string, newKey string) error {
+	if oldKey == newKey {
+		return fmt.Errorf("new key is the same as the old one")
+	}
+	if encryptor.VerifyKey(devicePath, newKey) == nil {
+		return nil
+	}
+	if err := encryptor.AddKey(devicePath, oldKey, newKey); err != nil {
+		return fmt.Errorf("failed to add new key: %v", err)
+	}
+	if err := encryptor.VerifyKey(devicePath, newKey); err != nil {
+		return fmt.Errorf("failed to verify new key: %v", err)
+	}
+	return nil
+}
+
+// removeVolumeReplicaKey removes the key only if the remaining key works, so the volume can never be locked out.
+// It's skipped if the key is removed already
+func removeVolumeReplicaKey(encryptor encrypt.Encryptor, devicePath string, key string, remainingKey string) error {
+	if key == remainingKey {
+		return fmt.Errorf("the key to remove is the same as the remaining one")
+	}
+	if err := encryptor.VerifyKey(devicePath, remainingKey); err != nil {
+		return fmt.Errorf("failed to verify the remaining key: %v", err)
+	}
+	if encryptor.VerifyKey(devicePath, key) != nil {
+		return nil
+	}
+	if err := encryptor.RemoveKey(devicePath, key); err != nil {
+		return fmt.Errorf("failed to remove key: %v", err)
+	}
+	return nil
+}
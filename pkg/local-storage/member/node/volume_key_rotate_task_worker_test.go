@@ -0,0 +1,183 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// fakeEncryptor keeps the keys in the key slots of the volume
+type fakeEncryptor struct {
+	keys       map[string]bool
+	addBroken  bool
+	operations []string
+}
+
+func (fe *fakeEncryptor) EncryptVolume(volumePath string, secret string) error { return nil }
+
+func (fe *fakeEncryptor) DecryptVolume(volumePath string, secret string) error { return nil }
+
+func (fe *fakeEncryptor) IsVolumeEncrypted(volumePath string) (bool, error) { return true, nil }
+
+func (fe *fakeEncryptor) CloseVolume(volumePath string) error { return nil }
+
+func (fe *fakeEncryptor) OpenVolume(volumePath string, secret string) (string, error) {
+	return volumePath, nil
+}
+
+func (fe *fakeEncryptor) AddKey(volumePath string, existingSecret string, newSecret string) error {
+	fe.operations = append(fe.operations, "add "+newSecret)
+	if !fe.keys[existingSecret] {
+		return fmt.Errorf("no key available with this passphrase")
+	}
+	// a broken key slot can't be unlocked
+	fe.keys[newSecret] = !fe.addBroken
+	return nil
+}
+
+func (fe *fakeEncryptor) VerifyKey(volumePath string, secret string) error {
+	if !fe.keys[secret] {
+		return fmt.Errorf("no key available with this passphrase")
+	}
+	return nil
+}
+
+func (fe *fakeEncryptor) RemoveKey(volumePath string, secret string) error {
+	fe.operations = append(fe.operations, "remove "+secret)
+	if !fe.keys[secret] {
+		return fmt.Errorf("no key available with this passphrase")
+	}
+	delete(fe.keys, secret)
+	return nil
+}
+
+func (fe *fakeEncryptor) InitEncryptVolume(volumePath string, secret string, reduceSize int64) error {
+	return nil
+}
+
+func (fe *fakeEncryptor) ResumeEncryptVolume(ctx context.Context, volumePath string, secret string, progress func(percent float64)) error {
+	return nil
+}
+
+func Test_addVolumeReplicaKey(t *testing.T) {
+	tests := []struct {
+		name           string
+		encryptor      *fakeEncryptor
+		oldKey, newKey string
+		wantErr        bool
+		wantKeys       map[string]bool
+		wantOperations []string
+	}{
+		{
+			name:           "add new key",
+			encryptor:      &fakeEncryptor{keys: map[string]bool{"old": true}},
+			oldKey:         "old",
+			newKey:         "new",
+			wantKeys:       map[string]bool{"old": true, "new": true},
+			wantOperations: []string{"add new"},
+		},
+		{
+			name:      "added already",
+			encryptor: &fakeEncryptor{keys: map[string]bool{"old": true, "new": true}},
+			oldKey:    "old",
+			newKey:    "new",
+			wantKeys:  map[string]bool{"old": true, "new": true},
+		},
+		{
+			name:           "wrong old key",
+			encryptor:      &fakeEncryptor{keys: map[string]bool{"other": true}},
+			oldKey:         "old",
+			newKey:         "new",
+			wantErr:        true,
+			wantKeys:       map[string]bool{"other": true},
+			wantOperations: []string{"add new"},
+		},
+		{
+			name:           "failed to verify new key",
+			encryptor:      &fakeEncryptor{keys: map[string]bool{"old": true}, addBroken: true},
+			oldKey:         "old",
+			newKey:         "new",
+			wantErr:        true,
+			wantKeys:       map[string]bool{"old": true, "new": false},
+			wantOperations: []string{"add new"},
+		},
+		{
+			name:      "same key",
+			encryptor: &fakeEncryptor{keys: map[string]bool{"old": true}},
+			oldKey:    "old",
+			newKey:    "old",
+			wantErr:   true,
+			wantKeys:  map[string]bool{"old": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := addVolumeReplicaKey(tt.encryptor, "/dev/pool/pvc-1", tt.oldKey, tt.newKey); (err != nil) != tt.wantErr {
+				t.Errorf("addVolumeReplicaKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.encryptor.keys, tt.wantKeys) {
+				t.Errorf("addVolumeReplicaKey() got keys %v, want %v", tt.encryptor.keys, tt.wantKeys)
+			}
+			if !reflect.DeepEqual(tt.encryptor.operations, tt.wantOperations) {
+				t.Errorf("addVolumeReplicaKey() got operations %v, want %v", tt.encryptor.operations, tt.wantOperations)
+			}
+		})
+	}
+}
+
+func Test_removeVolumeReplicaKey(t *testing.T) {
+	tests := []struct {
+		name              string
+		encryptor         *fakeEncryptor
+		key, remainingKey string
+		wantErr           bool
+		wantKeys          map[string]bool
+		wantOperations    []string
+	}{
+		{
+			name:           "remove old key",
+			encryptor:      &fakeEncryptor{keys: map[string]bool{"old": true, "new": true}},
+			key:            "old",
+			remainingKey:   "new",
+			wantKeys:       map[string]bool{"new": true},
+			wantOperations: []string{"remove old"},
+		},
+		{
+			name:         "removed already",
+			encryptor:    &fakeEncryptor{keys: map[string]bool{"new": true}},
+			key:          "old",
+			remainingKey: "new",
+			wantKeys:     map[string]bool{"new": true},
+		},
+		{
+			name:         "remaining key doesn't work",
+			encryptor:    &fakeEncryptor{keys: map[string]bool{"old": true}},
+			key:          "old",
+			remainingKey: "new",
+			wantErr:      true,
+			wantKeys:     map[string]bool{"old": true},
+		},
+		{
+			name:         "same key",
+			encryptor:    &fakeEncryptor{keys: map[string]bool{"old": true}},
+			key:          "old",
+			remainingKey: "old",
+			wantErr:      true,
+			wantKeys:     map[string]bool{"old": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := removeVolumeReplicaKey(tt.encryptor, "/dev/pool/pvc-1", tt.key, tt.remainingKey); (err != nil) != tt.wantErr {
+				t.Errorf("removeVolumeReplicaKey() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(tt.encryptor.keys, tt.wantKeys) {
+				t.Errorf("removeVolumeReplicaKey() got keys %v, want %v", tt.encryptor.keys, tt.wantKeys)
+			}
+			if !reflect.DeepEqual(tt.encryptor.operations, tt.wantOperations) {
+				t.Errorf("removeVolumeReplicaKey() got operations %v, want %v", tt.encryptor.operations, tt.wantOperations)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package node
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/quiesce"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/storage"
+)
+
+// createQuiescedVolumeReplicaSnapshot creates the on-host snapshot with the volume quiesced if required.
+// For the member of a LocalVolumeGroupSnapshot, the snapshots of all the members are created together in one quiesce window.
+// The volume is always resumed, no matter whether the snapshot succeeds or not
+func (m *manager) createQuiescedVolumeReplicaSnapshot(snapshot *apisv1alpha1.LocalVolumeReplicaSnapshot) error {
+	snapshots, err := m.getGroupVolumeReplicaSnapshots(snapshot)
+	if err != nil {
+		m.logger.WithField("Snapshot", snapshot.Name).WithError(err).Error("Failed to get member snapshots of the group snapshot")
+		return err
+	}
+
+	quiesceSpec := snapshot.Spec.Quiesce
+	if quiesceSpec == nil || quiesceSpec.Mode == "" || quiesceSpec.Mode == apisv1alpha1.SnapshotQuiesceModeNone {
+		_, err = m.createVolumeReplicaSnapshots(snapshots)
+		return err
+	}
+
+	logCtx := m.logger.WithFields(log.Fields{"Snapshot": snapshot.Name, "Quiesce": quiesceSpec})
+	var targets []quiesce.Target
+	if len(snapshots) > 1 {
+		targets, err = m.getVolumeReplicaSnapshotsQuiesceTargets(snapshots)
+	} else {
+		targets, err = m.getVolumeQuiesceTargets(snapshot.Spec.SourceVolume)
+	}
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get volumes to quiesce")
+		return err
+	}
+
+	resume, err := m.quiescer.Quiesce(quiesceSpec.Mode, time.Duration(quiesceSpec.TimeoutSeconds)*time.Second, targets)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to quiesce volumes")
+		return err
+	}
+	created, err := m.createVolumeReplicaSnapshots(snapshots)
+	resumeErr := resume()
+	if err != nil {
+		return err
+	}
+
+	switch resumeErr {
+	case nil:
+		return nil
+	case quiesce.ErrQuiesceTimeout:
+		// the snapshots are not consistent, remove them to take them again
+		logCtx.WithError(resumeErr).Warning("Volumes are resumed before the snapshot completed, remove the snapshot")
+		for _, s := range created {
+			if err = m.storageMgr.VolumeReplicaSnapshotManager().DeleteVolumeReplicaSnapshot(s); err != nil {
+				logCtx.WithField("Snapshot", s.Name).WithError(err).Error("Failed to remove the inconsistent snapshot")
+			}
+		}
+		return resumeErr
+	default:
+		// the snapshot is consistent, but the application might be still blocked
+		logCtx.WithError(resumeErr).Error("Failed to resume volumes after the snapshot")
+		m.recorder.Eventf(snapshot, corev1.EventTypeWarning, "ResumeFailed", "Failed to resume volumes after the snapshot: %v", resumeErr)
+		return nil
+	}
+}
+
+// createVolumeReplicaSnapshots creates the on-host snapshots which don't exist yet, and returns the created ones.
+// If any of them fails, the ones created by this call are removed
+func (m *manager) createVolumeReplicaSnapshots(snapshots []*apisv1alpha1.LocalVolumeReplicaSnapshot) ([]*apisv1alpha1.LocalVolumeReplicaSnapshot, error) {
+	var created []*apisv1alpha1.LocalVolumeReplicaSnapshot
+	for _, snapshot := range snapshots {
+		_, err := m.storageMgr.VolumeReplicaSnapshotManager().GetVolumeReplicaSnapshot(snapshot)
+		if err == nil {
+			continue
+		}
+		if err == storage.ErrorSnapshotNotFound {
+			err = m.storageMgr.VolumeReplicaSnapshotManager().CreateVolumeReplicaSnapshot(snapshot)
+		}
+		if err != nil {
+			for _, s := range created {
+				if delErr := m.storageMgr.VolumeReplicaSnapshotManager().DeleteVolumeReplicaSnapshot(s); delErr != nil {
+					m.logger.WithField("Snapshot", s.Name).WithError(delErr).Error("Failed to remove the snapshot of the group")
+				}
+			}
+			return nil, err
+		}
+		created = append(created, snapshot)
+	}
+	return created, nil
+}
+
+// getGroupVolumeReplicaSnapshots returns the replica snapshots on this node of all the members of the LocalVolumeGroupSnapshot
+// which the snapshot belongs to, or only the snapshot itself if it's not a member of any group snapshot
+func (m *manager) getGroupVolumeReplicaSnapshots(snapshot *apisv1alpha1.LocalVolumeReplicaSnapshot) ([]*apisv1alpha1.LocalVolumeReplicaSnapshot, error) {
+	volumeSnapshot := &apisv1alpha1.LocalVolumeSnapshot{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: snapshot.Spec.VolumeSnapshotName}, volumeSnapshot); err != nil {
+		return nil, err
+	}
+	groupSnapshotName, ok := volumeSnapshot.Labels[apisv1alpha1.VolumeGroupSnapshotLabelKey]
+	if !ok {
+		return []*apisv1alpha1.LocalVolumeReplicaSnapshot{snapshot}, nil
+	}
+	groupSnapshot := &apisv1alpha1.LocalVolumeGroupSnapshot{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: groupSnapshotName}, groupSnapshot); err != nil {
+		return nil, err
+	}
+
+	replicaSnapshotList := &apisv1alpha1.LocalVolumeReplicaSnapshotList{}
+	if err := m.apiClient.List(context.TODO(), replicaSnapshotList); err != nil {
+		return nil, err
+	}
+	var snapshots []*apisv1alpha1.LocalVolumeReplicaSnapshot
+	for _, member := range groupSnapshot.Status.VolumeSnapshots {
+		if member == snapshot.Spec.VolumeSnapshotName {
+			snapshots = append(snapshots, snapshot)
+			continue
+		}
+		var memberSnapshot *apisv1alpha1.LocalVolumeReplicaSnapshot
+		for i := range replicaSnapshotList.Items {
+			if replicaSnapshotList.Items[i].Spec.VolumeSnapshotName == member && replicaSnapshotList.Items[i].Spec.NodeName == m.name {
+				memberSnapshot = &replicaSnapshotList.Items[i]
+				break
+			}
+		}
+		if memberSnapshot == nil {
+			// wait for all the members to be dispatched to this node
+			return nil, fmt.Errorf("not found replica snapshot of the member %s of group snapshot %s", member, groupSnapshotName)
+		}
+		snapshots = append(snapshots, memberSnapshot)
+	}
+	return snapshots, nil
+}
+
+// getVolumeReplicaSnapshotsQuiesceTargets returns the source volumes of the snapshots to quiesce
+func (m *manager) getVolumeReplicaSnapshotsQuiesceTargets(snapshots []*apisv1alpha1.LocalVolumeReplicaSnapshot) ([]quiesce.Target, error) {
+	var targets []quiesce.Target
+	for _, snapshot := range snapshots {
+		volume := &apisv1alpha1.LocalVolume{}
+		if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: snapshot.Spec.SourceVolume}, volume); err != nil {
+			return nil, err
+		}
+		replica, err := m.getMyVolumeReplica(volume.Name)
+		if err != nil {
+			return nil, err
+		}
+		pods, err := m.getPodsUsingVolume(volume)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, quiesce.Target{
+			VolumeName:  volume.Name,
+			MountPoints: m.mounter.GetDeviceMountPoints(replica.Status.DevicePath),
+			Pods:        pods,
+		})
+	}
+	return targets, nil
+}
+
+// getVolumeQuiesceTargets returns the volume and the other volumes in the same LocalVolumeGroup on this node,
+// they are quiesced together to keep the snapshots consistent across the group
+func (m *manager) getVolumeQuiesceTargets(volumeName string) ([]quiesce.Target, error) {
+	volume := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeName}, volume); err != nil {
+		return nil, err
+	}
+
+	volumes := []*apisv1alpha1.LocalVolume{volume}
+	if len(volume.Spec.VolumeGroup) > 0 {
+		volumeGroup := &apisv1alpha1.LocalVolumeGroup{}
+		if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volume.Spec.VolumeGroup}, volumeGroup); err != nil {
+			return nil, err
+		}
+		for _, volumeInfo := range volumeGroup.Spec.Volumes {
+			if volumeInfo.LocalVolumeName == volumeName {
+				continue
+			}
+			groupVolume := &apisv1alpha1.LocalVolume{}
+			if err := m.apiClient.Get(context.TODO(), client.ObjectKey{Name: volumeInfo.LocalVolumeName}, groupVolume); err != nil {
+				return nil, err
+			}
+			volumes = append(volumes, groupVolume)
+		}
+	}
+
+	var targets []quiesce.Target
+	for _, vol := range volumes {
+		replica, err := m.getMyVolumeReplica(vol.Name)
+		if err != nil {
+			// the volume in the group might not be created on this node yet
+			m.logger.WithField("volume", vol.Name).WithError(err).Warning("Not found volume replica on this node, skip quiescing it")
+			continue
+		}
+		pods, err := m.getPodsUsingVolume(vol)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, quiesce.Target{
+			VolumeName:  vol.Name,
+			MountPoints: m.mounter.GetDeviceMountPoints(replica.Status.DevicePath),
+			Pods:        pods,
+		})
+	}
+	return targets, nil
+}
+
+// getPodsUsingVolume returns the pods on this node using the PVC of the volume
+func (m *manager) getPodsUsingVolume(volume *apisv1alpha1.LocalVolume) ([]quiesce.PodRef, error) {
+	if len(volume.Spec.PersistentVolumeClaimName) == 0 {
+		return nil, nil
+	}
+	podList := &corev1.PodList{}
+	if err := m.apiClient.List(context.TODO(), podList, client.InNamespace(volume.Spec.PersistentVolumeClaimNamespace)); err != nil {
+		return nil, err
+	}
+
+	var pods []quiesce.PodRef
+	for _, pod := range podList.Items {
+		if pod.Spec.NodeName != m.name {
+			continue
+		}
+		for _, vol := range pod.Spec.Volumes {
+			if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == volume.Spec.PersistentVolumeClaimName {
+				pods = append(pods, quiesce.PodRef{Namespace: pod.Namespace, Name: pod.Name})
+				break
+			}
+		}
+	}
+	return pods, nil
+}
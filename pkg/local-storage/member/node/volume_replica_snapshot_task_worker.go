@@ -127,7 +127,7 @@ func (m *manager) volumeReplicaSnapshotCreate(snapshot *apisv1alpha1.LocalVolume
 	}
 
 	if !exist {
-		if err = m.storageMgr.VolumeReplicaSnapshotManager().CreateVolumeReplicaSnapshot(snapshot); err != nil {
+		if err = m.createQuiescedVolumeReplicaSnapshot(snapshot); err != nil {
 			logCtx.WithError(err).Error("Failed to create VolumeReplica Snapshot")
 			return err
 		}
@@ -161,6 +161,18 @@ func (m *manager) processVolumeReplicaCheck(replica *apisv1alpha1.LocalVolumeRep
 		}
 	}()
 
+	// for the case of capacity shrink, only when the filesystem on it is shrunk by LocalVolumeShrink
+	if isVolumeReplicaToBeShrunk(replica) {
+		newReplica, err := m.shrinkVolumeReplica(replica)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to shrink volume replica")
+			return err
+		}
+		newReplica.Status.State = apisv1alpha1.VolumeReplicaStateNotReady
+		toBeUpdatedReplica = newReplica
+		return nil
+	}
+
 	// for the case of capacity expansion, only for LVM volume
 	if replica.Status.State == apisv1alpha1.VolumeReplicaStateReady &&
 		replica.Spec.RequiredCapacityBytes > replica.Status.AllocatedCapacityBytes+apisv1alpha1.VolumeExpansionCapacityBytesMin {
@@ -185,6 +197,9 @@ func (m *manager) processVolumeReplicaCheck(replica *apisv1alpha1.LocalVolumeRep
 		m.logger.WithError(err).Error("Failed to configure QoS for VolumeReplica")
 		return err
 	}
+	if qosStatus := m.volumeQoSManager.GetVolumeQoSStatus(replica.Spec.VolumeName); qosStatus != nil {
+		testReplica.Status.VolumeQoS = qosStatus
+	}
 
 	// idempotent operation
 	// 1. configure for HA volume by replication module like DRBD
@@ -0,0 +1,440 @@
+package node
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+const (
+	// interval to check the state of the running DRBD verify, raid check or snapshot checksum
+	volumeScrubCheckInterval = 30 * time.Second
+)
+
+// volumeScrubRun is the snapshot checksum running in background, as reading all the snapshots takes long.
+// It's lost when the node restarts, and started again then
+type volumeScrubRun struct {
+	lock      sync.Mutex
+	checksums map[string]string
+	done      bool
+	err       error
+	cancel    context.CancelFunc
+}
+
+func (r *volumeScrubRun) state() (map[string]string, bool, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.checksums, r.done, r.err
+}
+
+func (r *volumeScrubRun) finish(checksums map[string]string, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.checksums, r.done, r.err = checksums, true, err
+}
+
+func (m *manager) startVolumeScrubTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("Volume Scrub Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.volumeScrubTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the Volume Scrub worker")
+				break
+			}
+			if err := m.processVolumeScrub(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.volumeScrubTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process Volume Scrub task, retry later")
+				m.volumeScrubTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a Volume Scrub task.")
+				m.volumeScrubTaskQueue.Forget(task)
+			}
+			m.volumeScrubTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.volumeScrubTaskQueue.Shutdown()
+}
+
+func (m *manager) processVolumeScrub(scrubName string) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeScrub": scrubName})
+	logCtx.Debug("Working on a VolumeScrub task")
+	volumeScrub := &apisv1alpha1.LocalVolumeScrub{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: scrubName}, volumeScrub); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get VolumeScrub from cache")
+			return err
+		}
+		logCtx.Info("Not found the VolumeScrub from cache, should be deleted already")
+		return nil
+	}
+
+	// the scrub is submitted, completed and aborted by the controller, node only verifies the data,
+	// and stops the running verification when the scrub is to be aborted
+	switch volumeScrub.Status.State {
+	case apisv1alpha1.OperationStateInProgress:
+		if volumeScrub.Spec.Abort {
+			return nil
+		}
+	case apisv1alpha1.OperationStateToBeAborted:
+		return m.volumeScrubAbort(volumeScrub)
+	default:
+		return nil
+	}
+
+	updated, running := false, false
+	for i := range volumeScrub.Status.Results {
+		result := &volumeScrub.Status.Results[i]
+		if result.NodeName != m.name || result.State == apisv1alpha1.OperationStateCompleted ||
+			result.State == apisv1alpha1.OperationStateFailed || result.State == apisv1alpha1.OperationStateAborted {
+			continue
+		}
+		if m.scrubVolumeReplica(volumeScrub, result) {
+			updated = true
+		}
+		if result.State == apisv1alpha1.OperationStateInProgress {
+			running = true
+		}
+	}
+
+	if updated {
+		if err := m.apiClient.Status().Update(context.TODO(), volumeScrub); err != nil {
+			return err
+		}
+	}
+	if running {
+		m.volumeScrubTaskQueue.AddAfter(volumeScrub.Name, volumeScrubCheckInterval)
+	}
+	return nil
+}
+
+// volumeScrubAbort stops the scrubs running on this node, and marks them aborted
+func (m *manager) volumeScrubAbort(volumeScrub *apisv1alpha1.LocalVolumeScrub) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeScrub": volumeScrub.Name, "volume": volumeScrub.Spec.VolumeName})
+
+	updated := false
+	for i := range volumeScrub.Status.Results {
+		result := &volumeScrub.Status.Results[i]
+		if result.NodeName != m.name || result.State != apisv1alpha1.OperationStateInProgress {
+			continue
+		}
+		if err := m.stopVolumeReplicaScrub(volumeScrub, result); err != nil {
+			logCtx.WithField("method", result.Method).WithError(err).Error("Failed to stop the scrub of volume replica")
+			return err
+		}
+		result.State = apisv1alpha1.OperationStateAborted
+		result.Message = "aborted by user"
+		updated = true
+	}
+	if !updated {
+		return nil
+	}
+	return m.apiClient.Status().Update(context.TODO(), volumeScrub)
+}
+
+// stopVolumeReplicaScrub stops the DRBD verify, the raid check or the snapshot checksum running for the scrub
+func (m *manager) stopVolumeReplicaScrub(volumeScrub *apisv1alpha1.LocalVolumeScrub, result *apisv1alpha1.VolumeScrubResult) error {
+	if result.Method == apisv1alpha1.ScrubMethodSnapshotChecksum {
+		m.removeVolumeScrubRun(volumeScrub.Name)
+		return nil
+	}
+
+	replica, err := m.getMyVolumeReplica(volumeScrub.Spec.VolumeName)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// nothing is running on the replica deleted already
+			return nil
+		}
+		return err
+	}
+	switch result.Method {
+	case apisv1alpha1.ScrubMethodDRBDVerify:
+		running, _, err := m.configManager.configer.GetReplicaVerifyState(replica)
+		if err != nil || !running {
+			return err
+		}
+		return m.configManager.configer.StopVerifyReplica(replica)
+	case apisv1alpha1.ScrubMethodRaidCheck:
+		running, _, err := m.Storage().GetVolumeReplicaRaidCheckState(replica)
+		if err != nil || !running {
+			return err
+		}
+		return m.Storage().StopVolumeReplicaRaidCheck(replica)
+	}
+	return nil
+}
+
+// scrubVolumeReplica starts the scrub of the local replica, or checks the state of the running one.
+// The result is updated in place, and returns true if changed
+func (m *manager) scrubVolumeReplica(volumeScrub *apisv1alpha1.LocalVolumeScrub, result *apisv1alpha1.VolumeScrubResult) bool {
+	logCtx := m.logger.WithFields(log.Fields{"volume": volumeScrub.Spec.VolumeName, "method": result.Method, "state": result.State})
+	logCtx.Debug("Scrubbing volume replica")
+
+	oldResult := *result
+	replica, err := m.getMyVolumeReplica(volumeScrub.Spec.VolumeName)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get VolumeReplica")
+		result.State = apisv1alpha1.OperationStateFailed
+		result.Message = fmt.Sprintf("failed to get volume replica: %v", err)
+		return true
+	}
+
+	switch result.Method {
+	case apisv1alpha1.ScrubMethodDRBDVerify:
+		err = m.scrubVolumeReplicaByDRBDVerify(replica, result)
+	case apisv1alpha1.ScrubMethodRaidCheck:
+		err = m.scrubVolumeReplicaByRaidCheck(replica, result)
+	case apisv1alpha1.ScrubMethodSnapshotChecksum:
+		err = m.scrubVolumeReplicaBySnapshotChecksum(volumeScrub.Name, replica, result)
+	default:
+		err = fmt.Errorf("unsupported scrub method %s", result.Method)
+	}
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to scrub volume replica")
+		result.State = apisv1alpha1.OperationStateFailed
+		result.Message = err.Error()
+	}
+	return *result != oldResult
+}
+
+func (m *manager) scrubVolumeReplicaByDRBDVerify(replica *apisv1alpha1.LocalVolumeReplica, result *apisv1alpha1.VolumeScrubResult) error {
+	running, outOfSyncBytes, err := m.configManager.configer.GetReplicaVerifyState(replica)
+	if err != nil {
+		return err
+	}
+	// start the verify at the first time, it may be started already if failed to update the result last time
+	if len(result.State) == 0 {
+		if !running {
+			if err := m.configManager.configer.VerifyReplica(replica); err != nil {
+				return err
+			}
+		}
+		result.State = apisv1alpha1.OperationStateInProgress
+		return nil
+	}
+	if running {
+		return nil
+	}
+
+	result.OutOfSyncBytes = outOfSyncBytes
+	result.Inconsistent = outOfSyncBytes > 0
+	result.State = apisv1alpha1.OperationStateCompleted
+	if result.Inconsistent {
+		result.Message = fmt.Sprintf("%d bytes out of sync, reconnect the resource to resync them", outOfSyncBytes)
+	}
+	return nil
+}
+
+func (m *manager) scrubVolumeReplicaByRaidCheck(replica *apisv1alpha1.LocalVolumeReplica, result *apisv1alpha1.VolumeScrubResult) error {
+	running, mismatches, err := m.Storage().GetVolumeReplicaRaidCheckState(replica)
+	if err != nil {
+		return err
+	}
+	// start the check at the first time, it may be started already if failed to update the result last time
+	if len(result.State) == 0 {
+		if !running {
+			if err := m.Storage().StartVolumeReplicaRaidCheck(replica); err != nil {
+				return err
+			}
+		}
+		result.State = apisv1alpha1.OperationStateInProgress
+		return nil
+	}
+	if running {
+		return nil
+	}
+
+	result.Mismatches = mismatches
+	result.Inconsistent = mismatches > 0
+	result.State = apisv1alpha1.OperationStateCompleted
+	if result.Inconsistent {
+		result.Message = fmt.Sprintf("%d mismatches found between the raid images, run \"lvchange --syncaction repair\" to repair them", mismatches)
+	}
+	return nil
+}
+
+// scrubVolumeReplicaBySnapshotChecksum checksums all the snapshots of the replica in background. The data of a snapshot
+// never changes, so the checksum is compared with the one recorded on the snapshot by the previous scrub,
+// or recorded as the baseline if not scrubbed before
+func (m *manager) scrubVolumeReplicaBySnapshotChecksum(scrubName string, replica *apisv1alpha1.LocalVolumeReplica, result *apisv1alpha1.VolumeScrubResult) error {
+	m.lock.Lock()
+	run, exists := m.volumeScrubRuns[scrubName]
+	m.lock.Unlock()
+
+	if !exists {
+		replicaSnapshots, err := m.getReadyVolumeReplicaSnapshots(replica.Spec.VolumeName)
+		if err != nil {
+			return err
+		}
+		if len(replicaSnapshots) == 0 {
+			result.State = apisv1alpha1.OperationStateCompleted
+			result.Message = "no snapshot of the volume to checksum against, skipped"
+			return nil
+		}
+
+		devicePaths := map[string]string{}
+		for _, replicaSnapshot := range replicaSnapshots {
+			devicePaths[replicaSnapshot.Name] = m.Storage().VolumeReplicaSnapshotDevicePath(replicaSnapshot)
+		}
+		m.startVolumeScrubRun(scrubName, func(ctx context.Context) (map[string]string, error) {
+			checksums := map[string]string{}
+			for name, devicePath := range devicePaths {
+				checksum, err := checksumDevice(ctx, devicePath)
+				if err != nil {
+					return nil, fmt.Errorf("failed to checksum snapshot %s: %v", name, err)
+				}
+				checksums[name] = checksum
+			}
+			return checksums, nil
+		})
+		result.State = apisv1alpha1.OperationStateInProgress
+		return nil
+	}
+
+	checksums, done, err := run.state()
+	if !done {
+		return nil
+	}
+	m.removeVolumeScrubRun(scrubName)
+	if err != nil {
+		return err
+	}
+	return m.compareVolumeReplicaSnapshotChecksums(checksums, result)
+}
+
+// compareVolumeReplicaSnapshotChecksums compares the checksums with the ones recorded on the snapshots,
+// and records them on the snapshots scrubbed at the first time
+func (m *manager) compareVolumeReplicaSnapshotChecksums(checksums map[string]string, result *apisv1alpha1.VolumeScrubResult) error {
+	var latestSnapshot *apisv1alpha1.LocalVolumeReplicaSnapshot
+	var mismatches []string
+	verified, recorded := 0, 0
+	for name, checksum := range checksums {
+		replicaSnapshot := &apisv1alpha1.LocalVolumeReplicaSnapshot{}
+		if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: name}, replicaSnapshot); err != nil {
+			if errors.IsNotFound(err) {
+				// deleted during the scrub
+				continue
+			}
+			return err
+		}
+		if latestSnapshot == nil || latestSnapshot.CreationTimestamp.Before(&replicaSnapshot.CreationTimestamp) {
+			latestSnapshot = replicaSnapshot
+		}
+
+		recordedChecksum, exists := replicaSnapshot.Annotations[apisv1alpha1.VolumeScrubChecksumAnnoKey]
+		if !exists {
+			if replicaSnapshot.Annotations == nil {
+				replicaSnapshot.Annotations = map[string]string{}
+			}
+			replicaSnapshot.Annotations[apisv1alpha1.VolumeScrubChecksumAnnoKey] = checksum
+			if err := m.apiClient.Update(context.TODO(), replicaSnapshot); err != nil {
+				return err
+			}
+			recorded++
+			continue
+		}
+		verified++
+		if recordedChecksum != checksum {
+			mismatches = append(mismatches, name)
+		}
+	}
+
+	result.State = apisv1alpha1.OperationStateCompleted
+	if latestSnapshot != nil {
+		result.Snapshot = latestSnapshot.Name
+		result.Checksum = checksums[latestSnapshot.Name]
+	}
+	if len(mismatches) > 0 {
+		sort.Strings(mismatches)
+		result.Inconsistent = true
+		result.Message = fmt.Sprintf("checksum of the snapshots %s mismatched with the ones recorded by the previous scrubs", strings.Join(mismatches, ", "))
+		return nil
+	}
+	result.Message = fmt.Sprintf("%d snapshot(s) verified, %d snapshot(s) recorded as the baseline", verified, recorded)
+	return nil
+}
+
+// getReadyVolumeReplicaSnapshots returns the ready snapshots of the volume on this node
+func (m *manager) getReadyVolumeReplicaSnapshots(volumeName string) ([]*apisv1alpha1.LocalVolumeReplicaSnapshot, error) {
+	replicaSnapshotList := &apisv1alpha1.LocalVolumeReplicaSnapshotList{}
+	if err := m.apiClient.List(context.TODO(), replicaSnapshotList); err != nil {
+		return nil, err
+	}
+
+	var replicaSnapshots []*apisv1alpha1.LocalVolumeReplicaSnapshot
+	for i, replicaSnapshot := range replicaSnapshotList.Items {
+		if replicaSnapshot.Spec.SourceVolume != volumeName || replicaSnapshot.Spec.NodeName != m.name ||
+			replicaSnapshot.Spec.Delete || replicaSnapshot.Status.State != apisv1alpha1.VolumeStateReady {
+			continue
+		}
+		replicaSnapshots = append(replicaSnapshots, &replicaSnapshotList.Items[i])
+	}
+	return replicaSnapshots, nil
+}
+
+// startVolumeScrubRun runs the snapshot checksum in background
+func (m *manager) startVolumeScrubRun(name string, checksumFunc func(ctx context.Context) (map[string]string, error)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	run := &volumeScrubRun{cancel: cancel}
+
+	m.lock.Lock()
+	if m.volumeScrubRuns == nil {
+		m.volumeScrubRuns = map[string]*volumeScrubRun{}
+	}
+	m.volumeScrubRuns[name] = run
+	m.lock.Unlock()
+
+	go func() {
+		run.finish(checksumFunc(ctx))
+	}()
+}
+
+func (m *manager) removeVolumeScrubRun(name string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if run, exists := m.volumeScrubRuns[name]; exists {
+		run.cancel()
+		delete(m.volumeScrubRuns, name)
+	}
+}
+
+// contextReader stops reading once the context is done
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (r *contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.reader.Read(p)
+}
+
+func checksumDevice(ctx context.Context, devicePath string) (string, error) {
+	device, err := os.Open(devicePath)
+	if err != nil {
+		return "", err
+	}
+	defer device.Close()
+
+	hash := sha256.New()
+	if _, err = io.Copy(hash, &contextReader{ctx: ctx, reader: device}); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,130 @@
+package node
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+func Test_manager_compareVolumeReplicaSnapshotChecksums(t *testing.T) {
+	genReplicaSnapshot := func(name string, creation time.Time, checksum string) *apisv1alpha1.LocalVolumeReplicaSnapshot {
+		replicaSnapshot := &apisv1alpha1.LocalVolumeReplicaSnapshot{ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(creation)}}
+		if len(checksum) > 0 {
+			replicaSnapshot.Annotations = map[string]string{apisv1alpha1.VolumeScrubChecksumAnnoKey: checksum}
+		}
+		return replicaSnapshot
+	}
+	now := time.Now().Truncate(time.Second)
+
+	tests := []struct {
+		name             string
+		checksums        map[string]string
+		wantInconsistent bool
+		wantMessage      string
+	}{
+		{
+			name:        "verified and recorded",
+			checksums:   map[string]string{"snap-1": "aaa", "snap-2": "bbb", "snap-3": "ccc"},
+			wantMessage: "2 snapshot(s) verified, 1 snapshot(s) recorded as the baseline",
+		},
+		{
+			name:             "older snapshot corrupted",
+			checksums:        map[string]string{"snap-1": "xxx", "snap-2": "bbb", "snap-3": "ccc"},
+			wantInconsistent: true,
+			wantMessage:      "checksum of the snapshots snap-1 mismatched with the ones recorded by the previous scrubs",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newFakeDecommissionManager(t,
+				genReplicaSnapshot("snap-1", now.Add(-2*time.Hour), "aaa"),
+				genReplicaSnapshot("snap-2", now.Add(-time.Hour), "bbb"),
+				genReplicaSnapshot("snap-3", now, ""),
+			)
+
+			result := &apisv1alpha1.VolumeScrubResult{Method: apisv1alpha1.ScrubMethodSnapshotChecksum, State: apisv1alpha1.OperationStateInProgress}
+			if err := m.compareVolumeReplicaSnapshotChecksums(tt.checksums, result); err != nil {
+				t.Fatalf("compareVolumeReplicaSnapshotChecksums() error = %v", err)
+			}
+			if result.State != apisv1alpha1.OperationStateCompleted || result.Inconsistent != tt.wantInconsistent ||
+				result.Message != tt.wantMessage || result.Snapshot != "snap-3" || result.Checksum != "ccc" {
+				t.Errorf("compareVolumeReplicaSnapshotChecksums() got result %+v", result)
+			}
+
+			// the latest snapshot is recorded as the baseline for the next scrub
+			got := &apisv1alpha1.LocalVolumeReplicaSnapshot{}
+			if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: "snap-3"}, got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Annotations[apisv1alpha1.VolumeScrubChecksumAnnoKey] != "ccc" {
+				t.Errorf("compareVolumeReplicaSnapshotChecksums() got annotations %v", got.Annotations)
+			}
+		})
+	}
+}
+
+func Test_manager_volumeScrubAbort(t *testing.T) {
+	volumeScrub := &apisv1alpha1.LocalVolumeScrub{ObjectMeta: metav1.ObjectMeta{Name: "scrub-1"}}
+	volumeScrub.Spec.VolumeName = "pvc-1"
+	volumeScrub.Spec.Abort = true
+	volumeScrub.Status.State = apisv1alpha1.OperationStateToBeAborted
+	volumeScrub.Status.Results = []apisv1alpha1.VolumeScrubResult{
+		{NodeName: fakeNodename, Method: apisv1alpha1.ScrubMethodSnapshotChecksum, State: apisv1alpha1.OperationStateInProgress},
+	}
+	m := newFakeDecommissionManager(t, volumeScrub)
+
+	stopped := make(chan struct{})
+	m.startVolumeScrubRun(volumeScrub.Name, func(ctx context.Context) (map[string]string, error) {
+		<-ctx.Done()
+		close(stopped)
+		return nil, ctx.Err()
+	})
+
+	if err := m.processVolumeScrub(volumeScrub.Name); err != nil {
+		t.Fatalf("processVolumeScrub() error = %v", err)
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("processVolumeScrub() didn't stop the snapshot checksum")
+	}
+	if _, exists := m.volumeScrubRuns[volumeScrub.Name]; exists {
+		t.Error("processVolumeScrub() didn't remove the snapshot checksum")
+	}
+
+	got := &apisv1alpha1.LocalVolumeScrub{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: volumeScrub.Name}, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Status.Results[0].State != apisv1alpha1.OperationStateAborted {
+		t.Errorf("processVolumeScrub() got result %+v", got.Status.Results[0])
+	}
+}
+
+func Test_checksumDevice(t *testing.T) {
+	devicePath := filepath.Join(t.TempDir(), "snapshot")
+	if err := os.WriteFile(devicePath, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	checksum, err := checksumDevice(context.TODO(), devicePath)
+	if err != nil {
+		t.Fatalf("checksumDevice() error = %v", err)
+	}
+	if want := "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824"; checksum != want {
+		t.Errorf("checksumDevice() = %s, want %s", checksum, want)
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	if _, err = checksumDevice(ctx, devicePath); err == nil {
+		t.Error("checksumDevice() expects error after the context is canceled")
+	}
+}
@@ -0,0 +1,175 @@
+package node
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/configer"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/member/node/filesystem"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/utils"
+)
+
+func (m *manager) startVolumeShrinkTaskWorker(stopCh <-chan struct{}) {
+	m.logger.Debug("Volume Shrink Worker is working now")
+	go func() {
+		for {
+			task, shutdown := m.volumeShrinkTaskQueue.Get()
+			if shutdown {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Stop the Volume Shrink worker")
+				break
+			}
+			if err := m.processVolumeShrink(task); err != nil {
+				m.logger.WithFields(log.Fields{"task": task, "attempts": m.volumeShrinkTaskQueue.NumRequeues(task), "error": err.Error()}).Error("Failed to process Volume Shrink task, retry later")
+				m.volumeShrinkTaskQueue.AddRateLimited(task)
+			} else {
+				m.logger.WithFields(log.Fields{"task": task}).Debug("Completed a Volume Shrink task.")
+				m.volumeShrinkTaskQueue.Forget(task)
+			}
+			m.volumeShrinkTaskQueue.Done(task)
+		}
+	}()
+
+	<-stopCh
+	m.volumeShrinkTaskQueue.Shutdown()
+}
+
+func (m *manager) processVolumeShrink(volShrinkName string) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeShrink": volShrinkName})
+	logCtx.Debug("Working on a VolumeShrink task")
+	volShrink := &apisv1alpha1.LocalVolumeShrink{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: volShrinkName}, volShrink); err != nil {
+		if !errors.IsNotFound(err) {
+			logCtx.WithError(err).Error("Failed to get VolumeShrink from cache")
+			return err
+		}
+		logCtx.Info("Not found the VolumeShrink from cache, should be deleted already")
+		return nil
+	}
+
+	// the node only shrinks the filesystem, and the volume replicas are shrunk by the replica check on each node
+	if volShrink.Status.State != apisv1alpha1.OperationStateShrinkFilesystem || volShrink.Status.NodeName != m.name {
+		return nil
+	}
+	return m.volumeShrinkFilesystem(volShrink, filesystem.NewShrinker())
+}
+
+// volumeShrinkFilesystem shrinks the filesystem on the device of the volume replica. For the HA volume, the
+// filesystem is shrunk through the DRBD device, so it's shrunk on all the replicas at once
+func (m *manager) volumeShrinkFilesystem(volShrink *apisv1alpha1.LocalVolumeShrink, shrinker filesystem.Shrinker) error {
+	logCtx := m.logger.WithFields(log.Fields{"VolumeShrink": volShrink.Name, "volume": volShrink.Spec.VolumeName})
+
+	if volShrink.Spec.Abort {
+		volShrink.Status.State = apisv1alpha1.OperationStateAborted
+		volShrink.Status.Message = "aborted by user"
+		return m.apiClient.Status().Update(context.TODO(), volShrink)
+	}
+
+	vol := &apisv1alpha1.LocalVolume{}
+	if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: volShrink.Spec.VolumeName}, vol); err != nil {
+		logCtx.WithError(err).Error("Failed to get volume")
+		return err
+	}
+	replica, err := m.getMyVolumeReplica(volShrink.Spec.VolumeName)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get VolumeReplica")
+		return err
+	}
+	devPath := replica.Status.DevicePath
+
+	fsType, err := shrinker.GetFSType(devPath)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get filesystem type")
+		return err
+	}
+	volShrink.Status.FSType = fsType
+	if len(fsType) == 0 {
+		return m.volumeShrinkFail(volShrink, "no filesystem found on the volume")
+	}
+	if !filesystem.IsShrinkable(fsType) {
+		return m.volumeShrinkFail(volShrink, fmt.Sprintf("%s filesystem can't be shrunk", fsType))
+	}
+
+	fsSizeBytes := getShrunkFilesystemSizeBytes(vol, volShrink.Spec.RequiredCapacityBytes)
+	if err := shrinker.Check(devPath); err != nil {
+		return m.volumeShrinkFail(volShrink, fmt.Sprintf("failed to check filesystem: %v", err))
+	}
+	minSizeBytes, err := shrinker.MinimumSizeBytes(devPath)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get the minimum size of filesystem")
+		return err
+	}
+	if minSizeBytes > fsSizeBytes {
+		return m.volumeShrinkFail(volShrink, fmt.Sprintf("filesystem needs at least %d bytes, more than %d bytes available after shrunk", minSizeBytes, fsSizeBytes))
+	}
+
+	logCtx.WithFields(log.Fields{"fsType": fsType, "size": fsSizeBytes, "minSize": minSizeBytes}).Info("Shrinking filesystem of the volume")
+	if err := shrinker.Shrink(devPath, fsSizeBytes); err != nil {
+		return m.volumeShrinkFail(volShrink, fmt.Sprintf("failed to shrink filesystem: %v", err))
+	}
+
+	volShrink.Status.State = apisv1alpha1.OperationStateShrinkReplica
+	volShrink.Status.Message = ""
+	return m.apiClient.Status().Update(context.TODO(), volShrink)
+}
+
+func (m *manager) volumeShrinkFail(volShrink *apisv1alpha1.LocalVolumeShrink, message string) error {
+	m.logger.WithFields(log.Fields{"VolumeShrink": volShrink.Name, "message": message}).Error("Failed to shrink filesystem of the volume")
+	completionTime := metav1.Now()
+	volShrink.Status.CompletionTime = &completionTime
+	volShrink.Status.State = apisv1alpha1.OperationStateFailed
+	volShrink.Status.Message = message
+	return m.apiClient.Status().Update(context.TODO(), volShrink)
+}
+
+// getShrunkFilesystemSizeBytes returns the size to shrink the filesystem to. The DRBD metadata is recreated at
+// the end of the shrunk LV of the HA volume, so the space for it is excluded
+func getShrunkFilesystemSizeBytes(vol *apisv1alpha1.LocalVolume, requiredCapacityBytes int64) int64 {
+	sizeBytes := utils.NumericToLVMBytes(requiredCapacityBytes)
+	if vol.Spec.Config != nil && vol.Spec.Config.Convertible {
+		sizeBytes -= configer.MetadataSizeBytes(sizeBytes)
+	}
+	return sizeBytes
+}
+
+// isVolumeReplicaToBeShrunk returns true if the volume replica is required to be shrunk by LocalVolumeShrink.
+// The replica may be larger than required for other reasons, e.g. the space reserved for the LUKS header,
+// so it's never shrunk without LocalVolumeShrink
+func isVolumeReplicaToBeShrunk(replica *apisv1alpha1.LocalVolumeReplica) bool {
+	if _, exists := replica.Annotations[apisv1alpha1.VolumeShrinkInProgressAnnoKey]; !exists {
+		return false
+	}
+	return utils.NumericToLVMBytes(replica.Spec.RequiredCapacityBytes) < replica.Status.AllocatedCapacityBytes
+}
+
+// shrinkVolumeReplica reduces the volume replica. For the HA volume, the DRBD resource is removed with the
+// metadata at the end of the LV first, and is recreated on the shrunk LV by the following replica check
+func (m *manager) shrinkVolumeReplica(replica *apisv1alpha1.LocalVolumeReplica) (*apisv1alpha1.LocalVolumeReplica, error) {
+	logCtx := m.logger.WithFields(log.Fields{"replica": replica.Name, "capacity": replica.Spec.RequiredCapacityBytes})
+
+	_, isHA, err := m.configManager.getConfig(replica)
+	if err != nil {
+		return nil, err
+	}
+	if isHA {
+		logCtx.Info("Removing the replication before shrinking volume replica")
+		if err := m.configManager.DeleteConfig(replica); err != nil {
+			return nil, err
+		}
+	}
+
+	logCtx.Info("Shrinking volume replica")
+	newReplica, err := m.Storage().ShrinkVolumeReplica(replica, replica.Spec.RequiredCapacityBytes)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Storage().Registry().SyncNodeResources(); err != nil {
+		logCtx.WithError(err).Warn("Failed to sync node resources after shrinking volume replica")
+	}
+	return newReplica, nil
+}
@@ -0,0 +1,116 @@
+package node
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+type fakeShrinker struct {
+	fsType       string
+	minSizeBytes int64
+	shrunkBytes  int64
+}
+
+func (s *fakeShrinker) GetFSType(devPath string) (string, error) {
+	return s.fsType, nil
+}
+
+func (s *fakeShrinker) Check(devPath string) error {
+	return nil
+}
+
+func (s *fakeShrinker) MinimumSizeBytes(devPath string) (int64, error) {
+	return s.minSizeBytes, nil
+}
+
+func (s *fakeShrinker) Shrink(devPath string, sizeBytes int64) error {
+	s.shrunkBytes = sizeBytes
+	return nil
+}
+
+func Test_manager_volumeShrinkFilesystem(t *testing.T) {
+	tests := []struct {
+		name        string
+		convertible bool
+		shrinker    *fakeShrinker
+		wantState   apisv1alpha1.State
+		wantShrunk  int64
+	}{
+		{
+			name:       "ext4",
+			shrinker:   &fakeShrinker{fsType: "ext4", minSizeBytes: 512 * 1024 * 1024},
+			wantState:  apisv1alpha1.OperationStateShrinkReplica,
+			wantShrunk: 1024 * 1024 * 1024,
+		},
+		{
+			name:        "ext4 on HA volume",
+			convertible: true,
+			shrinker:    &fakeShrinker{fsType: "ext4", minSizeBytes: 512 * 1024 * 1024},
+			wantState:   apisv1alpha1.OperationStateShrinkReplica,
+			wantShrunk:  1023 * 1024 * 1024,
+		},
+		{
+			name:      "xfs",
+			shrinker:  &fakeShrinker{fsType: "xfs"},
+			wantState: apisv1alpha1.OperationStateFailed,
+		},
+		{
+			name:      "not formatted",
+			shrinker:  &fakeShrinker{},
+			wantState: apisv1alpha1.OperationStateFailed,
+		},
+		{
+			name:      "too much data",
+			shrinker:  &fakeShrinker{fsType: "ext4", minSizeBytes: 1536 * 1024 * 1024},
+			wantState: apisv1alpha1.OperationStateFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			volShrink := &apisv1alpha1.LocalVolumeShrink{ObjectMeta: metav1.ObjectMeta{Name: "shrink"}}
+			volShrink.Spec.VolumeName = "pvc-1"
+			volShrink.Spec.RequiredCapacityBytes = 1024 * 1024 * 1024
+			volShrink.Status.NodeName = fakeNodename
+			volShrink.Status.State = apisv1alpha1.OperationStateShrinkFilesystem
+			vol := &apisv1alpha1.LocalVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"}}
+			vol.Spec.Config = &apisv1alpha1.VolumeConfig{Convertible: tt.convertible}
+			replica := &apisv1alpha1.LocalVolumeReplica{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1-replica"}}
+			replica.Status.DevicePath = "/dev/LocalStorage_PoolHDD/pvc-1"
+			m := newFakeDecommissionManager(t, volShrink, vol, replica)
+			m.replicaRecords = map[string]string{"pvc-1": replica.Name}
+
+			if err := m.volumeShrinkFilesystem(volShrink, tt.shrinker); err != nil {
+				t.Fatalf("volumeShrinkFilesystem() error = %v", err)
+			}
+			got := &apisv1alpha1.LocalVolumeShrink{}
+			if err := m.apiClient.Get(context.TODO(), types.NamespacedName{Name: volShrink.Name}, got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Status.State != tt.wantState || tt.shrinker.shrunkBytes != tt.wantShrunk {
+				t.Errorf("volumeShrinkFilesystem() got status %+v, shrunk to %d", got.Status, tt.shrinker.shrunkBytes)
+			}
+		})
+	}
+}
+
+func Test_isVolumeReplicaToBeShrunk(t *testing.T) {
+	replica := &apisv1alpha1.LocalVolumeReplica{}
+	replica.Spec.RequiredCapacityBytes = 1024 * 1024 * 1024
+	replica.Status.AllocatedCapacityBytes = 2 * 1024 * 1024 * 1024
+	if isVolumeReplicaToBeShrunk(replica) {
+		t.Error("isVolumeReplicaToBeShrunk() should be false without the shrink in progress")
+	}
+	replica.Annotations = map[string]string{apisv1alpha1.VolumeShrinkInProgressAnnoKey: "shrink"}
+	if !isVolumeReplicaToBeShrunk(replica) {
+		t.Error("isVolumeReplicaToBeShrunk() should be true")
+	}
+	replica.Status.AllocatedCapacityBytes = replica.Spec.RequiredCapacityBytes
+	if isVolumeReplicaToBeShrunk(replica) {
+		t.Error("isVolumeReplicaToBeShrunk() should be false after shrunk")
+	}
+}
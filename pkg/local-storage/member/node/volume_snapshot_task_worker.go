@@ -101,6 +101,7 @@ func (m *manager) createVolumeReplicaSnapshot(volumeSnapshot apisv1alpha.LocalVo
 			Delete:                volumeSnapshot.Spec.Delete,
 			SourceVolume:          volumeSnapshot.Spec.SourceVolume,
 			RequiredCapacityBytes: volumeSnapshot.Spec.RequiredCapacityBytes,
+			Quiesce:               volumeSnapshot.Spec.Quiesce,
 		},
 	}
 
@@ -111,6 +111,8 @@ func (m *manager) createVolumeReplica(vol *apisv1alpha1.LocalVolume) error {
 			VolumeQoS:             vol.Spec.VolumeQoS,
 			VolumeEncrypt:         vol.Spec.VolumeEncrypt,
 			Thin:                  vol.Spec.Thin,
+			Mirror:                vol.Spec.Mirror,
+			VolumeCache:           vol.Spec.VolumeCache,
 			NodeName:              m.name,
 		},
 	}
@@ -166,6 +168,13 @@ func (m *manager) updateVolumeReplica(replica *apisv1alpha1.LocalVolumeReplica,
 		return m.apiClient.Update(context.TODO(), replica)
 	}
 
+	if !reflect.DeepEqual(vol.Spec.VolumeCache, replica.Spec.VolumeCache) {
+		// for cache resize, mode change or removal
+		logCtx.Debug("Update LocalVolumeReplica cache")
+		replica.Spec.VolumeCache = vol.Spec.VolumeCache
+		return m.apiClient.Update(context.TODO(), replica)
+	}
+
 	m.volumeReplicaTaskQueue.Add(replica.Name)
 	return nil
 }
@@ -0,0 +1,331 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	ManifestVersion = 1
+	CompressionGzip = "gzip"
+
+	manifestFileName = "manifest.json"
+)
+
+// Manifest describes a backup point. The volume data is split into the chunks with the fixed size, and each chunk
+// is stored as an object addressed by the sha256 of its content. So the chunks can be shared by all the backups,
+// and the unchanged chunks are not uploaded again by the incremental backup
+type Manifest struct {
+	Version        int    `json:"version"`
+	Name           string `json:"name"`
+	Volume         string `json:"volume"`
+	Parent         string `json:"parent,omitempty"`
+	SizeBytes      int64  `json:"sizeBytes"`
+	ChunkSizeBytes int64  `json:"chunkSizeBytes"`
+	Compression    string `json:"compression"`
+	// Chunks are the content hashes of the chunks in order, empty for the chunk full of zero which is not stored
+	Chunks       []string  `json:"chunks"`
+	CreationTime time.Time `json:"creationTime"`
+}
+
+// Stats is the statistics of a backup
+type Stats struct {
+	TotalChunks    int64
+	UploadedChunks int64
+	UploadedBytes  int64
+}
+
+// Manager backs up the data to the object store, and restores it from the object store
+type Manager struct {
+	store  ObjectStore
+	prefix string
+	logger *log.Entry
+}
+
+// NewManager creates a backup manager, all the objects are stored under the prefix
+func NewManager(store ObjectStore, prefix string) *Manager {
+	return &Manager{
+		store:  store,
+		prefix: prefix,
+		logger: log.WithField("Module", "BackupManager"),
+	}
+}
+
+// ManifestKey returns the object key of the backup manifest
+func (m *Manager) ManifestKey(name string) string {
+	return path.Join(m.prefix, "backups", name, manifestFileName)
+}
+
+func (m *Manager) chunkKey(hash string) string {
+	return path.Join(m.prefix, "chunks", hash[:2], hash)
+}
+
+// GetManifest gets the manifest of the backup, ErrObjectNotFound is returned if the backup doesn't complete
+func (m *Manager) GetManifest(ctx context.Context, name string) (*Manifest, error) {
+	return m.getManifestByKey(ctx, m.ManifestKey(name))
+}
+
+func (m *Manager) getManifestByKey(ctx context.Context, key string) (*Manifest, error) {
+	data, err := m.store.GetObject(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	if err = json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %v", key, err)
+	}
+	if manifest.Version != ManifestVersion {
+		return nil, fmt.Errorf("unsupported manifest version %d of %s", manifest.Version, key)
+	}
+	return manifest, nil
+}
+
+// Backup reads the data from the source and uploads the changed chunks. If parent is not empty, the chunks in the
+// parent backup are treated as uploaded, otherwise every chunk is checked in the object store before uploading.
+// The manifest is uploaded at last, so a backup without manifest is incomplete and can't be restored from
+func (m *Manager) Backup(ctx context.Context, name string, volume string, parent string, src io.ReaderAt, sizeBytes int64, chunkSizeBytes int64) (*Manifest, *Stats, error) {
+	if chunkSizeBytes <= 0 {
+		return nil, nil, fmt.Errorf("invalid chunk size %d", chunkSizeBytes)
+	}
+	logCtx := m.logger.WithFields(log.Fields{"backup": name, "volume": volume, "parent": parent})
+
+	uploaded := map[string]bool{}
+	if len(parent) > 0 {
+		parentManifest, err := m.GetManifest(ctx, parent)
+		if err != nil {
+			logCtx.WithError(err).Error("Failed to get manifest of the parent backup")
+			return nil, nil, err
+		}
+		for _, hash := range parentManifest.Chunks {
+			uploaded[hash] = true
+		}
+	}
+
+	manifest := &Manifest{
+		Version:        ManifestVersion,
+		Name:           name,
+		Volume:         volume,
+		Parent:         parent,
+		SizeBytes:      sizeBytes,
+		ChunkSizeBytes: chunkSizeBytes,
+		Compression:    CompressionGzip,
+		Chunks:         make([]string, 0, (sizeBytes+chunkSizeBytes-1)/chunkSizeBytes),
+	}
+	stats := &Stats{}
+
+	buf := make([]byte, chunkSizeBytes)
+	for offset := int64(0); offset < sizeBytes; offset += chunkSizeBytes {
+		if err := ctx.Err(); err != nil {
+			return nil, stats, err
+		}
+
+		chunk := buf[:minInt64(chunkSizeBytes, sizeBytes-offset)]
+		if n, err := src.ReadAt(chunk, offset); err != nil && !(errors.Is(err, io.EOF) && n == len(chunk)) {
+			logCtx.WithError(err).WithField("offset", offset).Error("Failed to read data")
+			return nil, stats, err
+		}
+		stats.TotalChunks++
+
+		if isZero(chunk) {
+			manifest.Chunks = append(manifest.Chunks, "")
+			continue
+		}
+
+		hash := sha256Hex(chunk)
+		manifest.Chunks = append(manifest.Chunks, hash)
+		if uploaded[hash] {
+			continue
+		}
+
+		exists, err := m.store.ObjectExists(ctx, m.chunkKey(hash))
+		if err != nil {
+			logCtx.WithError(err).WithField("chunk", hash).Error("Failed to check chunk")
+			return nil, stats, err
+		}
+		if !exists {
+			data, err := compress(chunk)
+			if err != nil {
+				return nil, stats, err
+			}
+			if err = m.store.PutObject(ctx, m.chunkKey(hash), data); err != nil {
+				logCtx.WithError(err).WithField("chunk", hash).Error("Failed to upload chunk")
+				return nil, stats, err
+			}
+			stats.UploadedChunks++
+			stats.UploadedBytes += int64(len(data))
+		}
+		uploaded[hash] = true
+	}
+
+	manifest.CreationTime = time.Now().UTC()
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, stats, err
+	}
+	if err = m.store.PutObject(ctx, m.ManifestKey(name), data); err != nil {
+		logCtx.WithError(err).Error("Failed to upload manifest")
+		return nil, stats, err
+	}
+
+	logCtx.WithFields(log.Fields{"chunks": stats.TotalChunks, "uploadedChunks": stats.UploadedChunks, "uploadedBytes": stats.UploadedBytes}).Info("Backup completed")
+	return manifest, stats, nil
+}
+
+// Restore downloads the chunks of the backup and writes them to the destination.
+// It returns the number of the chunks restored
+func (m *Manager) Restore(ctx context.Context, name string, dst io.WriterAt) (int64, error) {
+	logCtx := m.logger.WithField("backup", name)
+
+	manifest, err := m.GetManifest(ctx, name)
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to get manifest of the backup")
+		return 0, err
+	}
+	if manifest.Compression != CompressionGzip {
+		return 0, fmt.Errorf("unsupported compression %s", manifest.Compression)
+	}
+
+	zero := make([]byte, manifest.ChunkSizeBytes)
+	restored := int64(0)
+	for i, hash := range manifest.Chunks {
+		if err := ctx.Err(); err != nil {
+			return restored, err
+		}
+
+		offset := int64(i) * manifest.ChunkSizeBytes
+		length := minInt64(manifest.ChunkSizeBytes, manifest.SizeBytes-offset)
+		chunk := zero[:length]
+		if len(hash) > 0 {
+			data, err := m.store.GetObject(ctx, m.chunkKey(hash))
+			if err != nil {
+				logCtx.WithError(err).WithField("chunk", hash).Error("Failed to download chunk")
+				return restored, err
+			}
+			if chunk, err = decompress(data); err != nil {
+				return restored, err
+			}
+			if int64(len(chunk)) != length || sha256Hex(chunk) != hash {
+				return restored, fmt.Errorf("chunk %s is corrupted", hash)
+			}
+		}
+
+		if _, err := dst.WriteAt(chunk, offset); err != nil {
+			logCtx.WithError(err).WithField("offset", offset).Error("Failed to write data")
+			return restored, err
+		}
+		restored++
+	}
+
+	logCtx.WithField("chunks", restored).Info("Restore completed")
+	return restored, nil
+}
+
+// Delete deletes the manifest of the backup and its chunks which are not referenced by any other backup under
+// the prefix. The manifest is deleted at last, so the deletion can be retried until it succeeds.
+// It returns the number of the chunks deleted
+func (m *Manager) Delete(ctx context.Context, name string) (int64, error) {
+	logCtx := m.logger.WithField("backup", name)
+
+	manifest, err := m.GetManifest(ctx, name)
+	if err != nil {
+		if errors.Is(err, ErrObjectNotFound) {
+			logCtx.Info("Not found the manifest of the backup, should be deleted already")
+			return 0, nil
+		}
+		logCtx.WithError(err).Error("Failed to get manifest of the backup")
+		return 0, err
+	}
+
+	// the chunks are shared by all the backups under the prefix
+	manifestKeys, err := m.store.ListObjects(ctx, path.Join(m.prefix, "backups")+"/")
+	if err != nil {
+		logCtx.WithError(err).Error("Failed to list the backups")
+		return 0, err
+	}
+	inUse := map[string]bool{}
+	for _, key := range manifestKeys {
+		if path.Base(key) != manifestFileName || strings.TrimPrefix(key, "/") == strings.TrimPrefix(m.ManifestKey(name), "/") {
+			continue
+		}
+		other, err := m.getManifestByKey(ctx, key)
+		if err != nil {
+			if errors.Is(err, ErrObjectNotFound) {
+				continue
+			}
+			logCtx.WithError(err).WithField("manifest", key).Error("Failed to get manifest of other backup")
+			return 0, err
+		}
+		for _, hash := range other.Chunks {
+			inUse[hash] = true
+		}
+	}
+
+	deleted := int64(0)
+	for _, hash := range manifest.Chunks {
+		if len(hash) == 0 || inUse[hash] {
+			continue
+		}
+		if err = m.store.DeleteObject(ctx, m.chunkKey(hash)); err != nil {
+			logCtx.WithError(err).WithField("chunk", hash).Error("Failed to delete chunk")
+			return deleted, err
+		}
+		// the same chunk may appear more than once in a backup
+		inUse[hash] = true
+		deleted++
+	}
+
+	if err = m.store.DeleteObject(ctx, m.ManifestKey(name)); err != nil {
+		logCtx.WithError(err).Error("Failed to delete manifest")
+		return deleted, err
+	}
+
+	logCtx.WithField("deletedChunks", deleted).Info("Backup deleted")
+	return deleted, nil
+}
+
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+func isZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func minInt64(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
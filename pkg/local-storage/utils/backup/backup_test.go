@@ -0,0 +1,287 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testAccessKeyID     = "minio"
+	testSecretAccessKey = "minio123"
+	testBucket          = "backups"
+	testChunkSize       = 64 * 1024
+)
+
+// fakeS3 is an in-process S3-compatible server which supports the path-style PUT, GET, HEAD and DELETE of objects,
+// and ListObjectsV2 of the bucket which returns 2 keys per page
+type fakeS3 struct {
+	lock    sync.Mutex
+	objects map[string][]byte
+	puts    int
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential="+testAccessKeyID+"/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if r.URL.Path == "/"+testBucket && r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2" {
+		f.list(w, r.URL.Query().Get("prefix"), r.URL.Query().Get("continuation-token"))
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, "/"+testBucket+"/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/"+testBucket+"/")
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		if sha256Hex(data) != r.Header.Get("x-amz-content-sha256") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = data
+		f.puts++
+	case http.MethodGet, http.MethodHead:
+		data, exists := f.objects[key]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) list(w http.ResponseWriter, prefix string, token string) {
+	f.lock.Lock()
+	keys := []string{}
+	for key := range f.objects {
+		if strings.HasPrefix(key, prefix) && key > token {
+			keys = append(keys, key)
+		}
+	}
+	f.lock.Unlock()
+	sort.Strings(keys)
+
+	result := s3ListBucketResult{}
+	for i, key := range keys {
+		if i == 2 {
+			result.IsTruncated = true
+			result.NextContinuationToken = keys[i-1]
+			break
+		}
+		result.Contents = append(result.Contents, struct {
+			Key string `xml:"Key"`
+		}{Key: key})
+	}
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+// memDevice is a block device in memory
+type memDevice struct {
+	data []byte
+}
+
+func (d *memDevice) ReadAt(p []byte, off int64) (int, error) {
+	return bytes.NewReader(d.data).ReadAt(p, off)
+}
+
+func (d *memDevice) WriteAt(p []byte, off int64) (int, error) {
+	return copy(d.data[off:], p), nil
+}
+
+func newTestManager(t *testing.T) (*Manager, *fakeS3) {
+	fake := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	store, err := NewS3ObjectStore(S3Config{
+		Endpoint:        server.URL,
+		Bucket:          testBucket,
+		AccessKeyID:     testAccessKeyID,
+		SecretAccessKey: testSecretAccessKey,
+	})
+	if err != nil {
+		t.Fatalf("NewS3ObjectStore() error = %v", err)
+	}
+	return NewManager(store, "cluster-1"), fake
+}
+
+func TestManager_BackupAndRestore(t *testing.T) {
+	manager, fake := newTestManager(t)
+	ctx := context.Background()
+
+	// 4 chunks: data, zero, data, a partial chunk of data
+	size := int64(3*testChunkSize + 1000)
+	source := &memDevice{data: make([]byte, size)}
+	copy(source.data[0:], bytes.Repeat([]byte("a"), testChunkSize))
+	copy(source.data[2*testChunkSize:], bytes.Repeat([]byte("b"), testChunkSize))
+	copy(source.data[3*testChunkSize:], bytes.Repeat([]byte("c"), 1000))
+
+	full, stats, err := manager.Backup(ctx, "backup-1", "pvc-1", "", source, size, testChunkSize)
+	if err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	if stats.TotalChunks != 4 || stats.UploadedChunks != 3 || full.Chunks[1] != "" {
+		t.Errorf("Backup() got stats %+v, chunks %v", stats, full.Chunks)
+	}
+
+	// change one chunk, only it should be uploaded by the incremental backup
+	copy(source.data[2*testChunkSize:], bytes.Repeat([]byte("d"), 10))
+	putsBefore := fake.puts
+	incremental, stats, err := manager.Backup(ctx, "backup-2", "pvc-1", "backup-1", source, size, testChunkSize)
+	if err != nil {
+		t.Fatalf("Backup() incremental error = %v", err)
+	}
+	if stats.UploadedChunks != 1 || fake.puts-putsBefore != 2 /* chunk and manifest */ {
+		t.Errorf("Backup() incremental got stats %+v, puts %d", stats, fake.puts-putsBefore)
+	}
+	if incremental.Parent != "backup-1" || incremental.Chunks[0] != full.Chunks[0] || incremental.Chunks[2] == full.Chunks[2] {
+		t.Errorf("Backup() incremental got manifest %+v", incremental)
+	}
+
+	// restore both the backup points to the dirty devices
+	for _, tt := range []struct {
+		name string
+		want []byte
+	}{
+		{name: "backup-1", want: func() []byte {
+			data := append([]byte{}, source.data...)
+			copy(data[2*testChunkSize:], bytes.Repeat([]byte("b"), 10))
+			return data
+		}()},
+		{name: "backup-2", want: source.data},
+	} {
+		target := &memDevice{data: bytes.Repeat([]byte("x"), int(size))}
+		restored, err := manager.Restore(ctx, tt.name, target)
+		if err != nil {
+			t.Fatalf("Restore() %s error = %v", tt.name, err)
+		}
+		if restored != 4 || !bytes.Equal(target.data, tt.want) {
+			t.Errorf("Restore() %s got restored %d, data mismatched", tt.name, restored)
+		}
+	}
+}
+
+func TestManager_RestoreCorruptedChunk(t *testing.T) {
+	manager, fake := newTestManager(t)
+	ctx := context.Background()
+
+	source := &memDevice{data: bytes.Repeat([]byte("a"), testChunkSize)}
+	manifest, _, err := manager.Backup(ctx, "backup-1", "pvc-1", "", source, testChunkSize, testChunkSize)
+	if err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	data, _ := compress(bytes.Repeat([]byte("b"), testChunkSize))
+	fake.objects[strings.TrimPrefix(manager.chunkKey(manifest.Chunks[0]), "/")] = data
+
+	if _, err = manager.Restore(ctx, "backup-1", &memDevice{data: make([]byte, testChunkSize)}); err == nil {
+		t.Errorf("Restore() should fail for the corrupted chunk")
+	}
+}
+
+func TestManager_GetManifestNotFound(t *testing.T) {
+	manager, _ := newTestManager(t)
+	if _, err := manager.GetManifest(context.Background(), "not-exist"); err != ErrObjectNotFound {
+		t.Errorf("GetManifest() error = %v, want %v", err, ErrObjectNotFound)
+	}
+}
+
+func TestManager_Delete(t *testing.T) {
+	manager, fake := newTestManager(t)
+	ctx := context.Background()
+
+	// 3 chunks: a, b, c
+	size := int64(3 * testChunkSize)
+	source := &memDevice{data: make([]byte, size)}
+	for i, c := range []string{"a", "b", "c"} {
+		copy(source.data[int64(i)*testChunkSize:], bytes.Repeat([]byte(c), testChunkSize))
+	}
+	full, _, err := manager.Backup(ctx, "backup-1", "pvc-1", "", source, size, testChunkSize)
+	if err != nil {
+		t.Fatalf("Backup() error = %v", err)
+	}
+	// the incremental backup shares the chunks a and c, and changes b to d
+	copy(source.data[testChunkSize:], bytes.Repeat([]byte("d"), testChunkSize))
+	incremental, _, err := manager.Backup(ctx, "backup-2", "pvc-1", "backup-1", source, size, testChunkSize)
+	if err != nil {
+		t.Fatalf("Backup() incremental error = %v", err)
+	}
+	// another volume shares the chunk b
+	other := &memDevice{data: bytes.Repeat([]byte("b"), testChunkSize)}
+	if _, _, err = manager.Backup(ctx, "backup-3", "pvc-2", "", other, testChunkSize, testChunkSize); err != nil {
+		t.Fatalf("Backup() other volume error = %v", err)
+	}
+
+	objectExists := func(key string) bool {
+		_, exists := fake.objects[strings.TrimPrefix(key, "/")]
+		return exists
+	}
+
+	// only the chunk d is not shared
+	deleted, err := manager.Delete(ctx, "backup-2")
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if deleted != 1 || objectExists(manager.ManifestKey("backup-2")) || objectExists(manager.chunkKey(incremental.Chunks[1])) ||
+		!objectExists(manager.chunkKey(incremental.Chunks[0])) {
+		t.Errorf("Delete() backup-2 got deleted %d", deleted)
+	}
+
+	// deleting again is a no-op
+	if deleted, err = manager.Delete(ctx, "backup-2"); err != nil || deleted != 0 {
+		t.Errorf("Delete() again got deleted %d, error %v", deleted, err)
+	}
+
+	// the chunk b is still used by the other volume
+	deleted, err = manager.Delete(ctx, "backup-1")
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if deleted != 2 || objectExists(manager.ManifestKey("backup-1")) || !objectExists(manager.chunkKey(full.Chunks[1])) {
+		t.Errorf("Delete() backup-1 got deleted %d", deleted)
+	}
+
+	// the last backup takes all the remaining objects away
+	if _, err = manager.Delete(ctx, "backup-3"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if len(fake.objects) != 0 {
+		t.Errorf("Delete() got remaining objects %d", len(fake.objects))
+	}
+}
+
+func Test_s3EscapePath(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/bucket/prefix/chunks/ab/abcdef", want: "/bucket/prefix/chunks/ab/abcdef"},
+		{path: "/bucket/a b+c", want: "/bucket/a%20b%2Bc"},
+	}
+	for _, tt := range tests {
+		if got := s3EscapePath(tt.path); got != tt.want {
+			t.Errorf("s3EscapePath(%s) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+// Design for backing up the volume data to an S3-compatible object store by content-addressed chunks
+package backup
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrObjectNotFound is returned when the object doesn't exist in the object store
+	ErrObjectNotFound = errors.New("object not found")
+)
+
+// ObjectStore is the interface of the object store where the backup data is stored
+type ObjectStore interface {
+	// PutObject uploads the object, the existing one with the same key will be overwritten
+	PutObject(ctx context.Context, key string, data []byte) error
+	// GetObject downloads the object, ErrObjectNotFound is returned if it doesn't exist
+	GetObject(ctx context.Context, key string) ([]byte, error)
+	// ObjectExists checks whether the object exists or not
+	ObjectExists(ctx context.Context, key string) (bool, error)
+	// DeleteObject deletes the object, it's not an error if the object doesn't exist
+	DeleteObject(ctx context.Context, key string) error
+	// ListObjects lists the keys of all the objects with the prefix
+	ListObjects(ctx context.Context, prefix string) ([]string, error)
+}
@@ -0,0 +1,51 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+const (
+	CredentialAccessKeyID     = "accessKeyID"
+	CredentialSecretAccessKey = "secretAccessKey"
+)
+
+// NewManagerForTarget creates a backup manager for the target, the credential is read from the secret of the target
+func NewManagerForTarget(cli client.Client, target apisv1alpha1.BackupTarget) (*Manager, error) {
+	ss := strings.Split(target.CredentialSecret, "/")
+	if len(ss) != 2 {
+		return nil, fmt.Errorf("invalid secret namespaced name %s", target.CredentialSecret)
+	}
+	secret := &corev1.Secret{}
+	if err := cli.Get(context.Background(), client.ObjectKey{Namespace: ss[0], Name: ss[1]}, secret); err != nil {
+		return nil, err
+	}
+	if len(secret.Data[CredentialAccessKeyID]) == 0 || len(secret.Data[CredentialSecretAccessKey]) == 0 {
+		return nil, fmt.Errorf("%s or %s is not exist in %s", CredentialAccessKeyID, CredentialSecretAccessKey, target.CredentialSecret)
+	}
+
+	store, err := NewS3ObjectStore(S3Config{
+		Endpoint:              target.Endpoint,
+		Bucket:                target.Bucket,
+		Region:                target.Region,
+		AccessKeyID:           string(secret.Data[CredentialAccessKeyID]),
+		SecretAccessKey:       string(secret.Data[CredentialSecretAccessKey]),
+		InsecureSkipTLSVerify: target.InsecureSkipTLSVerify,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return NewManager(store, target.Prefix), nil
+}
+
+// SameTarget checks whether the two targets are the same location, so that the chunks can be shared
+func SameTarget(a, b apisv1alpha1.BackupTarget) bool {
+	return strings.TrimSuffix(a.Endpoint, "/") == strings.TrimSuffix(b.Endpoint, "/") &&
+		a.Bucket == b.Bucket && strings.Trim(a.Prefix, "/") == strings.Trim(b.Prefix, "/")
+}
@@ -0,0 +1,200 @@
+package datacopy
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	// BlockSyncDefaultChunkSize is the size of the chunk to send and check for zero at a time
+	BlockSyncDefaultChunkSize = 4 * 1024 * 1024
+)
+
+// the records of the block stream from the sender on the source node to the receiver on the target node,
+// each one is a 1-byte type and a 4-byte big-endian length, followed by the data for the data record only
+const (
+	blockRecordData byte = 'D'
+	blockRecordZero byte = 'Z'
+	blockRecordEnd  byte = 'E'
+
+	blockRecordHeaderSize = 5
+	// maxBlockRecordSize bounds the buffer allocated by the receiver for a record
+	maxBlockRecordSize = 64 * 1024 * 1024
+)
+
+// BlockCopyResult is the result of copying a block device
+type BlockCopyResult struct {
+	// Bytes is the size of all the data read from the source
+	Bytes int64
+	// ZeroBytes is the size of the zeroed chunks, sent as the zero records without the data
+	ZeroBytes int64
+	// SkippedBytes is the size of the zeroed chunks not written into the target
+	SkippedBytes int64
+	// Checksum is the sha256 rolled over all the data read from the source, no matter skipped or not
+	Checksum string
+}
+
+// SendBlocks reads src chunk by chunk, and writes the chunks into dst as the block stream. The zeroed chunks
+// are checked on the source and sent as the zero records, so they never go over the wire
+func SendBlocks(src io.Reader, dst io.Writer, chunkSize int) (*BlockCopyResult, error) {
+	if chunkSize <= 0 || chunkSize > maxBlockRecordSize {
+		return nil, fmt.Errorf("invalid chunk size %d", chunkSize)
+	}
+
+	result := &BlockCopyResult{}
+	hash := sha256.New()
+	chunk := make([]byte, chunkSize)
+	for {
+		n, err := io.ReadFull(src, chunk)
+		if n > 0 {
+			hash.Write(chunk[:n])
+			if isZeroChunk(chunk[:n]) {
+				result.ZeroBytes += int64(n)
+				if err := writeBlockRecord(dst, blockRecordZero, n, nil); err != nil {
+					return result, err
+				}
+			} else if err := writeBlockRecord(dst, blockRecordData, n, chunk[:n]); err != nil {
+				return result, err
+			}
+			result.Bytes += int64(n)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return result, err
+		}
+	}
+	result.Checksum = hex.EncodeToString(hash.Sum(nil))
+	// the end record tells the complete stream from the one broken by the sender
+	return result, writeBlockRecord(dst, blockRecordEnd, 0, nil)
+}
+
+// ReceiveBlocks writes the block stream from src into dst. The zero records are not written when skipZero,
+// which keeps the unallocated extents of the thin target unallocated, so it must be set for the thin target only.
+// progress is called with the bytes copied so far after each record if not nil
+func ReceiveBlocks(src io.Reader, dst io.WriterAt, skipZero bool, progress func(copiedBytes int64)) (*BlockCopyResult, error) {
+	result := &BlockCopyResult{}
+	hash := sha256.New()
+	header := make([]byte, blockRecordHeaderSize)
+	var chunk, zeroChunk []byte
+	for {
+		if _, err := io.ReadFull(src, header); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return result, fmt.Errorf("block stream ended without the end record")
+			}
+			return result, err
+		}
+		recordType, n := header[0], int(binary.BigEndian.Uint32(header[1:]))
+		if n > maxBlockRecordSize {
+			return result, fmt.Errorf("block record of %d bytes exceeds the limit", n)
+		}
+
+		switch recordType {
+		case blockRecordEnd:
+			result.Checksum = hex.EncodeToString(hash.Sum(nil))
+			return result, nil
+		case blockRecordData:
+			if len(chunk) < n {
+				chunk = make([]byte, n)
+			}
+			if _, err := io.ReadFull(src, chunk[:n]); err != nil {
+				return result, fmt.Errorf("failed to read block record: %v", err)
+			}
+			hash.Write(chunk[:n])
+			if _, err := dst.WriteAt(chunk[:n], result.Bytes); err != nil {
+				return result, err
+			}
+		case blockRecordZero:
+			if len(zeroChunk) < n {
+				zeroChunk = make([]byte, n)
+			}
+			hash.Write(zeroChunk[:n])
+			result.ZeroBytes += int64(n)
+			if skipZero {
+				result.SkippedBytes += int64(n)
+			} else if _, err := dst.WriteAt(zeroChunk[:n], result.Bytes); err != nil {
+				return result, err
+			}
+		default:
+			return result, fmt.Errorf("unknown block record type %q", recordType)
+		}
+		result.Bytes += int64(n)
+		if progress != nil {
+			progress(result.Bytes)
+		}
+	}
+}
+
+func writeBlockRecord(dst io.Writer, recordType byte, n int, data []byte) error {
+	header := make([]byte, blockRecordHeaderSize)
+	header[0] = recordType
+	binary.BigEndian.PutUint32(header[1:], uint32(n))
+	if _, err := dst.Write(header); err != nil {
+		return err
+	}
+	if len(data) > 0 {
+		_, err := dst.Write(data)
+		return err
+	}
+	return nil
+}
+
+// ChecksumBlocks returns the sha256 of all the data in src, same as the one of SendBlocks and sha256sum
+func ChecksumBlocks(src io.Reader) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, src); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func isZeroChunk(chunk []byte) bool {
+	for _, b := range chunk {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ThrottledReader limits the rate of reading from the underlying reader in bytes per second
+type ThrottledReader struct {
+	reader    io.Reader
+	rateLimit int64
+	startTime time.Time
+	readBytes int64
+	now       func() time.Time
+	sleep     func(time.Duration)
+}
+
+// NewThrottledReader returns the reader limited to rateLimit bytes per second, or the reader itself if no limit
+func NewThrottledReader(reader io.Reader, rateLimit int64) io.Reader {
+	if rateLimit <= 0 {
+		return reader
+	}
+	return &ThrottledReader{reader: reader, rateLimit: rateLimit, now: time.Now, sleep: time.Sleep}
+}
+
+func (r *ThrottledReader) Read(p []byte) (int, error) {
+	if r.startTime.IsZero() {
+		r.startTime = r.now()
+	}
+	// never read more than one second of data at a time, so the rate keeps smooth
+	if int64(len(p)) > r.rateLimit {
+		p = p[:r.rateLimit]
+	}
+	n, err := r.reader.Read(p)
+	r.readBytes += int64(n)
+
+	// wait until the bytes read so far are within the rate limit
+	expected := time.Duration(float64(r.readBytes) / float64(r.rateLimit) * float64(time.Second))
+	if wait := expected - r.now().Sub(r.startTime); wait > 0 {
+		r.sleep(wait)
+	}
+	return n, err
+}
@@ -0,0 +1,127 @@
+package datacopy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"testing"
+	"time"
+)
+
+// deviceBuffer is a fake device prefilled with the stale data
+type deviceBuffer struct {
+	data []byte
+}
+
+func (d *deviceBuffer) WriteAt(p []byte, off int64) (int, error) {
+	return copy(d.data[off:], p), nil
+}
+
+func TestSendReceiveBlocks(t *testing.T) {
+	chunkSize := 4
+	// chunks: data, zero, data, partial zero
+	source := []byte{1, 2, 3, 4, 0, 0, 0, 0, 5, 6, 7, 8, 0, 0}
+	sum := sha256.Sum256(source)
+	wantChecksum := hex.EncodeToString(sum[:])
+
+	stream := &bytes.Buffer{}
+	sent, err := SendBlocks(bytes.NewReader(source), stream, chunkSize)
+	if err != nil {
+		t.Fatalf("SendBlocks() error = %v", err)
+	}
+	if sent.Bytes != int64(len(source)) || sent.ZeroBytes != 6 || sent.Checksum != wantChecksum {
+		t.Errorf("SendBlocks() got result %+v", sent)
+	}
+	// the zeroed chunks are sent without the data
+	if wantSize := 5*blockRecordHeaderSize + 8; stream.Len() != wantSize {
+		t.Errorf("SendBlocks() got stream of %d bytes, want %d", stream.Len(), wantSize)
+	}
+
+	tests := []struct {
+		name        string
+		skipZero    bool
+		wantTarget  []byte
+		wantSkipped int64
+	}{
+		{
+			name:       "thick target",
+			skipZero:   false,
+			wantTarget: []byte{1, 2, 3, 4, 0, 0, 0, 0, 5, 6, 7, 8, 0, 0},
+		},
+		{
+			name:        "thin target",
+			skipZero:    true,
+			wantTarget:  []byte{1, 2, 3, 4, 9, 9, 9, 9, 5, 6, 7, 8, 9, 9},
+			wantSkipped: 6,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &deviceBuffer{data: bytes.Repeat([]byte{9}, len(source))}
+			var progress int64
+			result, err := ReceiveBlocks(bytes.NewReader(stream.Bytes()), target, tt.skipZero, func(copiedBytes int64) {
+				progress = copiedBytes
+			})
+			if err != nil {
+				t.Fatalf("ReceiveBlocks() error = %v", err)
+			}
+			if !bytes.Equal(target.data, tt.wantTarget) {
+				t.Errorf("ReceiveBlocks() got target %v, want %v", target.data, tt.wantTarget)
+			}
+			if result.Bytes != int64(len(source)) || result.ZeroBytes != 6 || result.SkippedBytes != tt.wantSkipped ||
+				result.Checksum != wantChecksum || progress != result.Bytes {
+				t.Errorf("ReceiveBlocks() got result %+v, progress %d", result, progress)
+			}
+		})
+	}
+
+	// the stream broken before the end record must fail
+	broken := stream.Bytes()[:stream.Len()-blockRecordHeaderSize]
+	if _, err := ReceiveBlocks(bytes.NewReader(broken), &deviceBuffer{data: make([]byte, len(source))}, false, nil); err == nil {
+		t.Error("ReceiveBlocks() got no error for the broken stream")
+	}
+}
+
+func TestChecksumBlocks(t *testing.T) {
+	source := bytes.Repeat([]byte{1, 0, 2}, 1000)
+	result, err := SendBlocks(bytes.NewReader(source), io.Discard, 7)
+	if err != nil {
+		t.Fatalf("SendBlocks() error = %v", err)
+	}
+	checksum, err := ChecksumBlocks(bytes.NewReader(source))
+	if err != nil {
+		t.Fatalf("ChecksumBlocks() error = %v", err)
+	}
+	if checksum != result.Checksum {
+		t.Errorf("ChecksumBlocks() = %s, want the rolling checksum %s", checksum, result.Checksum)
+	}
+}
+
+func TestThrottledReader(t *testing.T) {
+	if r := NewThrottledReader(bytes.NewReader(nil), 0); r == nil {
+		t.Fatal("NewThrottledReader() got nil reader without limit")
+	} else if _, ok := r.(*ThrottledReader); ok {
+		t.Error("NewThrottledReader() got throttled reader without limit")
+	}
+
+	now := time.Unix(0, 0)
+	var slept time.Duration
+	r := NewThrottledReader(bytes.NewReader(make([]byte, 30)), 10).(*ThrottledReader)
+	r.now = func() time.Time { return now }
+	r.sleep = func(d time.Duration) {
+		slept += d
+		now = now.Add(d)
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatalf("ReadAll() error = %v", err)
+	}
+	if len(data) != 30 {
+		t.Errorf("ReadAll() got %d bytes, want 30", len(data))
+	}
+	if slept != 3*time.Second {
+		t.Errorf("ThrottledReader slept %v, want 3s", slept)
+	}
+}
@@ -0,0 +1,268 @@
+package datacopy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+var (
+	blockSyncImageName = "ghcr.io/hwameistor/blocksync:latest"
+)
+
+// BlockSync copies the raw block device of the volume, rather than the files in it. The job runs on the target node,
+// streams the source LV over ssh with the sync key pair, and verifies the data with a rolling checksum
+type BlockSync struct {
+	namespace string
+	apiClient k8sclient.Client
+}
+
+// LVDevicePath returns the device path of the LV on the node
+func LVDevicePath(poolName, lvName string) string {
+	return fmt.Sprintf("/dev/mapper/%s-%s", poolName, strings.Replace(lvName, "-", "--", -1))
+}
+
+// ZVolDevicePath returns the device path of the ZFS volume on the node
+func ZVolDevicePath(poolName, volName string) string {
+	return path.Join("/dev/zvol", poolName, volName)
+}
+
+// CacheDevicePath returns the device of the volume through the cache on the node
+func CacheDevicePath(volName string) string {
+	return fmt.Sprintf("/dev/mapper/%s-cached", volName)
+}
+
+// VolumeDevicePath returns the device to copy the volume data on the node with the storage backend. The cache
+// device holds the LV exclusively, so the volume with cache must be accessed through the cache device
+func VolumeDevicePath(vol *apisv1alpha1.LocalVolume, storageBackend string) string {
+	if vol.Spec.VolumeCache != nil {
+		return CacheDevicePath(vol.Name)
+	}
+	if storageBackend == apisv1alpha1.StorageBackendZFS {
+		return ZVolDevicePath(vol.Spec.PoolName, vol.Name)
+	}
+	return LVDevicePath(vol.Spec.PoolName, vol.Name)
+}
+
+// GetVolumeDevicePath returns the device to copy the volume data on the node. The volume without the required
+// storage backend takes the backend of its pool on the node
+func GetVolumeDevicePath(apiClient k8sclient.Client, vol *apisv1alpha1.LocalVolume, nodeName string) (string, error) {
+	storageBackend := vol.Spec.StorageBackend
+	if len(storageBackend) == 0 {
+		node := &apisv1alpha1.LocalStorageNode{}
+		if err := apiClient.Get(context.TODO(), k8sclient.ObjectKey{Name: nodeName}, node); err != nil {
+			return "", err
+		}
+		storageBackend = node.Status.Pools[vol.Spec.PoolName].Backend
+	}
+	return VolumeDevicePath(vol, storageBackend), nil
+}
+
+// BlockSyncProgressFile returns the file on the target node where the sync job records the bytes copied
+func BlockSyncProgressFile(volName string) string {
+	return filepath.Join(SyncProgressDir, volName)
+}
+
+func (bs *BlockSync) Prepare(targetNodeName, sourceNodeName, volName string) error {
+	vol := &apisv1alpha1.LocalVolume{}
+	if err := bs.apiClient.Get(context.TODO(), k8sclient.ObjectKey{Name: volName}, vol); err != nil {
+		logger.WithField("volume", volName).WithError(err).Error("Failed to get LocalVolume")
+		return err
+	}
+
+	// the unallocated extents of the new thin target are read as zero, so the zeroed chunks can be skipped.
+	// The thick target may have the stale data left, all the chunks must be written
+	skipZero := SyncFalse
+	if vol.Spec.Thin {
+		skipZero = SyncTrue
+	}
+	sourceDevicePath, err := GetVolumeDevicePath(bs.apiClient, vol, sourceNodeName)
+	if err != nil {
+		logger.WithField("node", sourceNodeName).WithError(err).Error("Failed to get the device of the source volume")
+		return err
+	}
+	targetDevicePath, err := GetVolumeDevicePath(bs.apiClient, vol, targetNodeName)
+	if err != nil {
+		logger.WithField("node", targetNodeName).WithError(err).Error("Failed to get the device of the target volume")
+		return err
+	}
+	data := map[string]string{
+		SyncConfigVolumeNameKey:     volName,
+		SyncConfigSourceNodeNameKey: sourceNodeName,
+		SyncConfigTargetNodeNameKey: targetNodeName,
+		SyncConfigSyncToolKey:       SyncToolBlockSync,
+		SyncConfigSourceDeviceKey:   sourceDevicePath,
+		SyncConfigTargetDeviceKey:   targetDevicePath,
+		SyncConfigSkipZeroKey:       skipZero,
+	}
+	return prepareSyncConfigMap(bs.apiClient, bs.namespace, data)
+}
+
+func (bs *BlockSync) StartSync(jobName, volName, excludedRunningNodeName, runningNodeName string, dataCheckNeed bool) error {
+	job, err := bs.buildJob(jobName, volName, runningNodeName, dataCheckNeed)
+	if err != nil {
+		logger.WithField("job", jobName).WithError(err).Error("Failed to build sync job")
+		return err
+	}
+
+	if err := bs.apiClient.Create(context.TODO(), job); err != nil {
+		if k8serrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create sync job, already exists")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// buildJob builds the job to run on the target node, because the target device is written locally.
+// The excluded node is not used, as the target node is never the source one
+func (bs *BlockSync) buildJob(jobName string, volName string, runningNodeName string, dataCheckNeed bool) (*batchv1.Job, error) {
+	imageName := blockSyncImageName
+	if value := os.Getenv("MIGRAGE_BLOCKSYNC_IMAGE"); len(value) > 0 {
+		imageName = value
+	}
+
+	cm, err := getSyncConfigMap(bs.apiClient, bs.namespace, volName)
+	if err != nil {
+		return nil, err
+	}
+	if len(strings.TrimSpace(runningNodeName)) == 0 {
+		runningNodeName = cm.Data[SyncConfigTargetNodeNameKey]
+	}
+	// use StorageNodeIP instead of nodeName, more details see #1195
+	sourceNodeIP, err := getStorageNodeIP(bs.apiClient, cm.Data[SyncConfigSourceNodeNameKey])
+	if err != nil {
+		return nil, err
+	}
+
+	command := []string{
+		"/blocksync",
+		"--source-host=" + sourceNodeIP,
+		"--source-device=" + cm.Data[SyncConfigSourceDeviceKey],
+		"--target-device=" + cm.Data[SyncConfigTargetDeviceKey],
+		fmt.Sprintf("--skip-zero=%t", cm.Data[SyncConfigSkipZeroKey] == SyncTrue),
+		fmt.Sprintf("--verify-target=%t", dataCheckNeed),
+		"--progress-file=" + BlockSyncProgressFile(volName),
+	}
+	if bandwidthLimit := cm.Data[SyncConfigBandwidthLimitKey]; len(bandwidthLimit) > 0 {
+		command = append(command, "--bandwidth-limit="+bandwidthLimit)
+	}
+
+	var privileged = true
+	var keyFileMode int32 = 0400
+	var progressDirType = corev1.HostPathDirectoryOrCreate
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      jobName,
+			Namespace: bs.namespace,
+			Labels: map[string]string{
+				"app": SyncJobLabelApp,
+			},
+			Finalizers: []string{SyncJobFinalizer},
+		},
+		Spec: batchv1.JobSpec{
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app": SyncJobLabelApp,
+					},
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: "Never",
+					Containers: []corev1.Container{
+						{
+							Name:            syncMountContainerName,
+							Image:           imageName,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							SecurityContext: &corev1.SecurityContext{
+								Privileged: &privileged,
+							},
+							Command: command,
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "key-config",
+									MountPath: "/root/.ssh/id_rsa",
+									SubPath:   SyncPrivateKeyFileName,
+								},
+								{
+									Name:      "host-dev",
+									MountPath: "/dev",
+								},
+								{
+									Name:      "host-progress",
+									MountPath: SyncProgressDir,
+								},
+							},
+						},
+					},
+					Affinity: &corev1.Affinity{
+						NodeAffinity: &corev1.NodeAffinity{
+							RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+								NodeSelectorTerms: []corev1.NodeSelectorTerm{
+									{
+										MatchExpressions: []corev1.NodeSelectorRequirement{
+											{
+												Key:      SyncJobAffinityKey,
+												Operator: corev1.NodeSelectorOpIn,
+												Values:   []string{runningNodeName},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "key-config",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{Name: SyncKeyConfigMapName},
+									Items: []corev1.KeyToPath{
+										{
+											Key:  SyncPrivateKeyFileName,
+											Path: SyncPrivateKeyFileName,
+										},
+									},
+									// ssh refuses the private key accessible by others
+									DefaultMode: &keyFileMode,
+								},
+							},
+						},
+						{
+							Name: "host-dev",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: "/dev",
+								},
+							},
+						},
+						{
+							Name: "host-progress",
+							VolumeSource: corev1.VolumeSource{
+								HostPath: &corev1.HostPathVolumeSource{
+									Path: SyncProgressDir,
+									Type: &progressDirType,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	return job, nil
+}
@@ -0,0 +1,149 @@
+package datacopy
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	k8sclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+func newFakeClient(t *testing.T, objs ...k8sclient.Object) k8sclient.Client {
+	s := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(s); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	if err := apisv1alpha1.AddToScheme(s); err != nil {
+		t.Fatalf("AddToScheme() error = %v", err)
+	}
+	return fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+}
+
+func TestNewSyncer(t *testing.T) {
+	tests := []struct {
+		name       string
+		syncerName string
+		want       DataSyncer
+	}{
+		{name: "juicesync", syncerName: SyncToolJuiceSync, want: &JuiceSync{namespace: "hwameistor"}},
+		{name: "blocksync", syncerName: SyncToolBlockSync, want: &BlockSync{namespace: "hwameistor"}},
+		{name: "unknown", syncerName: "rclone", want: &JuiceSync{namespace: "hwameistor"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewSyncer(tt.syncerName, "hwameistor", nil); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewSyncer() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLVDevicePath(t *testing.T) {
+	if got, want := LVDevicePath("LocalStorage_PoolHDD", "pvc-1-2"), "/dev/mapper/LocalStorage_PoolHDD-pvc--1--2"; got != want {
+		t.Errorf("LVDevicePath() = %s, want %s", got, want)
+	}
+}
+
+func TestBlockSync_PrepareAndBuildJob(t *testing.T) {
+	volume := &apisv1alpha1.LocalVolume{ObjectMeta: metav1.ObjectMeta{Name: "pvc-1"}}
+	volume.Spec.PoolName = apisv1alpha1.PoolNameForHDD
+	volume.Spec.Thin = true
+	sourceNode := &apisv1alpha1.LocalStorageNode{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+	sourceNode.Spec.StorageIP = "10.0.0.1"
+	// the pool of the same class is on ZFS on the target node
+	targetNode := &apisv1alpha1.LocalStorageNode{ObjectMeta: metav1.ObjectMeta{Name: "node2"}}
+	targetNode.Status.Pools = map[string]apisv1alpha1.LocalPool{
+		apisv1alpha1.PoolNameForHDD: {Backend: apisv1alpha1.StorageBackendZFS},
+	}
+
+	bs := &BlockSync{namespace: "hwameistor", apiClient: newFakeClient(t, volume, sourceNode, targetNode)}
+	if err := bs.Prepare("node2", "node1", volume.Name); err != nil {
+		t.Fatalf("Prepare() error = %v", err)
+	}
+	cm := &corev1.ConfigMap{}
+	if err := bs.apiClient.Get(context.TODO(), types.NamespacedName{Namespace: bs.namespace, Name: GetConfigMapName(SyncConfigMapName, volume.Name)}, cm); err != nil {
+		t.Fatalf("Get ConfigMap error = %v", err)
+	}
+	sourceDevicePath := "/dev/mapper/LocalStorage_PoolHDD-pvc--1"
+	targetDevicePath := "/dev/zvol/LocalStorage_PoolHDD/pvc-1"
+	if cm.Data[SyncConfigSyncToolKey] != SyncToolBlockSync || cm.Data[SyncConfigSourceDeviceKey] != sourceDevicePath ||
+		cm.Data[SyncConfigTargetDeviceKey] != targetDevicePath || cm.Data[SyncConfigSkipZeroKey] != SyncTrue {
+		t.Errorf("Prepare() got config %v", cm.Data)
+	}
+
+	// the config of the other sync tool is refused
+	js := &JuiceSync{namespace: bs.namespace, apiClient: bs.apiClient}
+	if err := js.Prepare("node2", "node1", volume.Name); err == nil {
+		t.Errorf("Prepare() of juicesync got no error on the blocksync config")
+	}
+
+	dcm := &DataCopyManager{k8sControllerClient: bs.apiClient}
+	if err := dcm.setSyncBandwidthLimit(cm, 100*1024*1024); err != nil {
+		t.Fatalf("setSyncBandwidthLimit() error = %v", err)
+	}
+
+	job, err := bs.buildJob("job-1", volume.Name, "", true)
+	if err != nil {
+		t.Fatalf("buildJob() error = %v", err)
+	}
+	wantCommand := []string{
+		"/blocksync",
+		"--source-host=10.0.0.1",
+		"--source-device=" + sourceDevicePath,
+		"--target-device=" + targetDevicePath,
+		"--skip-zero=true",
+		"--verify-target=true",
+		"--progress-file=/mnt/hwameistor/progress/pvc-1",
+		"--bandwidth-limit=104857600",
+	}
+	if got := job.Spec.Template.Spec.Containers[0].Command; !reflect.DeepEqual(got, wantCommand) {
+		t.Errorf("buildJob() got command %v, want %v", got, wantCommand)
+	}
+	// runs on the target node
+	if got := job.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions[0]; got.Operator != corev1.NodeSelectorOpIn || !reflect.DeepEqual(got.Values, []string{"node2"}) {
+		t.Errorf("buildJob() got node affinity %+v", got)
+	}
+}
+
+func Test_getSyncProgress(t *testing.T) {
+	tests := []struct {
+		name string
+		data map[string]string
+		want *Progress
+	}{
+		{
+			name: "not started",
+			data: map[string]string{},
+		},
+		{
+			name: "in progress",
+			data: map[string]string{SyncConfigTotalBytesKey: "1000", SyncConfigCopiedBytesKey: "400"},
+			want: &Progress{CopiedBytes: 400, TotalBytes: 1000},
+		},
+		{
+			name: "copied more than total",
+			data: map[string]string{SyncConfigTotalBytesKey: "1000", SyncConfigCopiedBytesKey: "1200"},
+			want: &Progress{CopiedBytes: 1000, TotalBytes: 1000},
+		},
+		{
+			name: "completed",
+			data: map[string]string{SyncConfigTotalBytesKey: "1000", SyncConfigCopiedBytesKey: "900", SyncConfigSyncCompleteKey: SyncTrue},
+			want: &Progress{CopiedBytes: 1000, TotalBytes: 1000},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getSyncProgress(&corev1.ConfigMap{Data: tt.data}); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getSyncProgress() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
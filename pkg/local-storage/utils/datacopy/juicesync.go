@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
 
 	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
@@ -18,7 +19,7 @@ import (
 )
 
 var (
-	juiceSyncImageName = "ghcr.io/hwameistor/hwameistor-juicesync:v1.0.4-01"
+	juiceSyncImageName = "ghcr.io/hwameistor/hwameistor-juicesync:v1.0.4-02"
 )
 
 type JuiceSync struct {
@@ -27,22 +28,30 @@ type JuiceSync struct {
 }
 
 func (js *JuiceSync) Prepare(targetNodeName, sourceNodeName, volName string) (err error) {
-	ctx := context.TODO()
 	data := map[string]string{
 		SyncConfigVolumeNameKey:     volName,
 		SyncConfigSourceNodeNameKey: sourceNodeName,
 		SyncConfigTargetNodeNameKey: targetNodeName,
 	}
+	return prepareSyncConfigMap(js.apiClient, js.namespace, data)
+}
+
+// prepareSyncConfigMap creates the config of the volume data sync, or checks the existing one
+func prepareSyncConfigMap(apiClient k8sclient.Client, namespace string, data map[string]string) (err error) {
+	ctx := context.TODO()
+	volName := data[SyncConfigVolumeNameKey]
+	sourceNodeName := data[SyncConfigSourceNodeNameKey]
+	targetNodeName := data[SyncConfigTargetNodeNameKey]
 
 	cmName := GetConfigMapName(SyncConfigMapName, volName)
 	cm := &corev1.ConfigMap{}
-	if err = js.apiClient.Get(ctx, types.NamespacedName{Namespace: js.namespace, Name: cmName}, cm); err == nil {
+	if err = apiClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: cmName}, cm); err == nil {
 		// configmap exists, check and correct if necessary
 		if cm.Data == nil {
 			cm.Data = data
 			logger.WithField("configmap", cmName).Debug("The config of data sync already exists, but no data found, update it now")
 			oldCM := cm.DeepCopy()
-			return js.apiClient.Patch(context.TODO(), cm, k8sclient.MergeFrom(oldCM))
+			return apiClient.Patch(context.TODO(), cm, k8sclient.MergeFrom(oldCM))
 		}
 
 		if v, ok := cm.Data[SyncConfigVolumeNameKey]; ok && v != volName {
@@ -59,19 +68,24 @@ func (js *JuiceSync) Prepare(targetNodeName, sourceNodeName, volName string) (er
 			logger.WithFields(log.Fields{"configmap": cmName, "originTargetNode": v, "newTargetNode": targetNodeName}).Debug("The config of data sync already exists, but targetNode is changed")
 			return fmt.Errorf("migrate config %s is already exist and targetNode is changed, cannot start job now", cmName)
 		}
+
+		if v := cm.Data[SyncConfigSyncToolKey]; v != data[SyncConfigSyncToolKey] {
+			logger.WithFields(log.Fields{"configmap": cmName, "originSyncTool": v, "newSyncTool": data[SyncConfigSyncToolKey]}).Debug("The config of data sync already exists, but syncTool is changed")
+			return fmt.Errorf("migrate config %s is already exist and syncTool is changed, cannot start job now", cmName)
+		}
 		return nil
 	}
 
 	cm = &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      cmName,
-			Namespace: js.namespace,
+			Namespace: namespace,
 			Labels:    map[string]string{},
 		},
 		Data: data,
 	}
 
-	if err = js.apiClient.Create(ctx, cm); err != nil {
+	if err = apiClient.Create(ctx, cm); err != nil {
 		logger.WithError(err).Error("Failed to create MigrateConfigmap")
 		return err
 	}
@@ -80,9 +94,9 @@ func (js *JuiceSync) Prepare(targetNodeName, sourceNodeName, volName string) (er
 }
 
 // getStorageNodeIP returns the StorageIP configured in the corresponding LocalStorageNode
-func (js *JuiceSync) getStorageNodeIP(nodeName string) (string, error) {
+func getStorageNodeIP(apiClient k8sclient.Client, nodeName string) (string, error) {
 	storageNode := apisv1alpha1.LocalStorageNode{}
-	if err := js.apiClient.Get(context.TODO(), k8sclient.ObjectKey{Name: nodeName}, &storageNode); err != nil {
+	if err := apiClient.Get(context.TODO(), k8sclient.ObjectKey{Name: nodeName}, &storageNode); err != nil {
 		return "", err
 	}
 	return storageNode.Spec.StorageIP, nil
@@ -106,11 +120,6 @@ func (js *JuiceSync) buildJob(jobName string, volName string, excludedRunningNod
 		juiceSyncImageName = value
 	}
 
-	runCommand := "sync_hwameistor_volumes.sh"
-	if dataCheckNeed {
-		runCommand = "sync_hwameistor_volumes_check.sh"
-	}
-
 	nodeSelectExpression := []corev1.NodeSelectorRequirement{}
 	if len(strings.TrimSpace(runningNodeName)) > 0 {
 		nodeSelectExpression = append(nodeSelectExpression, corev1.NodeSelectorRequirement{
@@ -133,21 +142,29 @@ func (js *JuiceSync) buildJob(jobName string, volName string, excludedRunningNod
 
 	// use StorageNodeIP instead of nodeName, more details see #1195
 	var sourceNodeIP, targetNodeIP string
-	cm, err := js.getConfigMap(volName)
+	cm, err := getSyncConfigMap(js.apiClient, js.namespace, volName)
 	if err != nil {
 		logger.WithError(err).Error("failed to get config map for job")
 		return nil
 	} else {
-		if sourceNodeIP, err = js.getStorageNodeIP(cm.Data[SyncConfigSourceNodeNameKey]); err != nil {
+		if sourceNodeIP, err = getStorageNodeIP(js.apiClient, cm.Data[SyncConfigSourceNodeNameKey]); err != nil {
 			logger.WithError(err).Error("failed to get source node ip from config map for job")
 			return nil
 		}
-		if targetNodeIP, err = js.getStorageNodeIP(cm.Data[SyncConfigTargetNodeNameKey]); err != nil {
+		if targetNodeIP, err = getStorageNodeIP(js.apiClient, cm.Data[SyncConfigTargetNodeNameKey]); err != nil {
 			logger.WithError(err).Error("failed to get target node ip from config map for job")
 			return nil
 		}
 	}
 
+	runCommand := "sync_hwameistor_volumes.sh"
+	if dataCheckNeed {
+		runCommand = "sync_hwameistor_volumes_check.sh"
+	}
+	if bandwidthLimit, _ := strconv.ParseInt(cm.Data[SyncConfigBandwidthLimitKey], 10, 64); bandwidthLimit > 0 {
+		runCommand += fmt.Sprintf(" --bwlimit %d", juiceSyncBandwidthLimit(bandwidthLimit))
+	}
+
 	var privileged = true
 	baseStruct := &batchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
@@ -291,8 +308,14 @@ func (js *JuiceSync) buildJob(jobName string, volName string, excludedRunningNod
 	return baseStruct
 }
 
-func (js *JuiceSync) getConfigMap(volName string) (*corev1.ConfigMap, error) {
+// juiceSyncBandwidthLimit converts the bandwidth limit in bytes per second to Mbps taken by juicesync,
+// it's rounded up to keep the limit above zero
+func juiceSyncBandwidthLimit(bandwidthLimit int64) int64 {
+	return (bandwidthLimit*8 + 999999) / 1000000
+}
+
+func getSyncConfigMap(apiClient k8sclient.Client, namespace string, volName string) (*corev1.ConfigMap, error) {
 	cmName := GetConfigMapName(SyncConfigMapName, volName)
 	cm := &corev1.ConfigMap{}
-	return cm, js.apiClient.Get(context.TODO(), types.NamespacedName{Namespace: js.namespace, Name: cmName}, cm)
+	return cm, apiClient.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: cmName}, cm)
 }
@@ -0,0 +1,67 @@
+package datacopy
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+)
+
+func TestJuiceSync_buildJob(t *testing.T) {
+	tests := []struct {
+		name           string
+		bandwidthLimit int64
+		dataCheckNeed  bool
+		wantCommand    []string
+	}{
+		{
+			name:        "no bandwidth limit",
+			wantCommand: []string{"sh", "-c", "sync_hwameistor_volumes.sh"},
+		},
+		{
+			name:           "bandwidth limit",
+			bandwidthLimit: 100 * 1024 * 1024,
+			wantCommand:    []string{"sh", "-c", "sync_hwameistor_volumes.sh --bwlimit 839"},
+		},
+		{
+			name:           "bandwidth limit below 1Mbps",
+			bandwidthLimit: 1024,
+			dataCheckNeed:  true,
+			wantCommand:    []string{"sh", "-c", "sync_hwameistor_volumes_check.sh --bwlimit 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sourceNode := &apisv1alpha1.LocalStorageNode{ObjectMeta: metav1.ObjectMeta{Name: "node1"}}
+			sourceNode.Spec.StorageIP = "10.0.0.1"
+			targetNode := &apisv1alpha1.LocalStorageNode{ObjectMeta: metav1.ObjectMeta{Name: "node2"}}
+			targetNode.Spec.StorageIP = "10.0.0.2"
+
+			js := &JuiceSync{namespace: "hwameistor", apiClient: newFakeClient(t, sourceNode, targetNode)}
+			if err := js.Prepare("node2", "node1", "pvc-1"); err != nil {
+				t.Fatalf("Prepare() error = %v", err)
+			}
+			cm := &corev1.ConfigMap{}
+			if err := js.apiClient.Get(context.TODO(), types.NamespacedName{Namespace: js.namespace, Name: GetConfigMapName(SyncConfigMapName, "pvc-1")}, cm); err != nil {
+				t.Fatalf("Get ConfigMap error = %v", err)
+			}
+			dcm := &DataCopyManager{k8sControllerClient: js.apiClient}
+			if err := dcm.setSyncBandwidthLimit(cm, tt.bandwidthLimit); err != nil {
+				t.Fatalf("setSyncBandwidthLimit() error = %v", err)
+			}
+
+			job := js.buildJob("job-1", "pvc-1", "", "node2", tt.dataCheckNeed)
+			if job == nil {
+				t.Fatal("buildJob() got nil job")
+			}
+			if got := job.Spec.Template.Spec.Containers[0].Command; !reflect.DeepEqual(got, tt.wantCommand) {
+				t.Errorf("buildJob() got command %v, want %v", got, tt.wantCommand)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -80,7 +81,9 @@ func (dcm *DataCopyManager) Run() {
 	dcm.statusGenerator.Run()
 }
 
-func (dcm *DataCopyManager) Sync(jobName, srcNodeName, dstNodeName, volName string) error {
+// Sync copies the data of the volume from the source node to the target node, bandwidthLimit
+// caps the bandwidth of the copy in bytes per second, no limit if 0
+func (dcm *DataCopyManager) Sync(jobName, srcNodeName, dstNodeName, volName string, bandwidthLimit int64) error {
 	logCtx := logger.WithFields(log.Fields{"job": jobName, "volume": volName})
 	logCtx.Debug("Preparing the resources for data sync ...")
 
@@ -110,6 +113,10 @@ func (dcm *DataCopyManager) Sync(jobName, srcNodeName, dstNodeName, volName stri
 	if err := dcm.k8sControllerClient.Get(ctx, types.NamespacedName{Namespace: dcm.workingNamespace, Name: jobName}, syncJob); err != nil {
 		if errors.IsNotFound(err) {
 			logCtx.WithField("Job", jobName).Info("No job is created to sync replicas, create one ...")
+			if err := dcm.setSyncBandwidthLimit(cm, bandwidthLimit); err != nil {
+				logCtx.WithField("configmap", cmName).WithError(err).Error("Failed to set bandwidth limit")
+				return err
+			}
 			if err := dcm.syncer.StartSync(jobName, volName, srcNodeName, "", dcm.dataCheckNeed); err != nil {
 				logCtx.WithField("LocalVolume", volName).WithError(err).Error("Failed to start a job to sync replicas")
 				return fmt.Errorf("failed to start a job to sync replicas for volume %s", volName)
@@ -165,6 +172,98 @@ func (dcm *DataCopyManager) Sync(jobName, srcNodeName, dstNodeName, volName stri
 	return nil
 }
 
+// setSyncBandwidthLimit records the bandwidth limit in the sync config, which is read by the sync job
+func (dcm *DataCopyManager) setSyncBandwidthLimit(cm *corev1.ConfigMap, bandwidthLimit int64) error {
+	value := ""
+	if bandwidthLimit > 0 {
+		value = strconv.FormatInt(bandwidthLimit, 10)
+	}
+	if cm.Data[SyncConfigBandwidthLimitKey] == value {
+		return nil
+	}
+	if len(value) == 0 {
+		delete(cm.Data, SyncConfigBandwidthLimitKey)
+	} else {
+		cm.Data[SyncConfigBandwidthLimitKey] = value
+	}
+	return dcm.k8sControllerClient.Update(context.TODO(), cm)
+}
+
+// GetProgress returns the progress of the data copy of the volume reported by the nodes, nil if not started yet
+func (dcm *DataCopyManager) GetProgress(volName string) (*Progress, error) {
+	cm := &corev1.ConfigMap{}
+	if err := dcm.k8sControllerClient.Get(context.TODO(), types.NamespacedName{Namespace: dcm.workingNamespace, Name: GetConfigMapName(SyncConfigMapName, volName)}, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return getSyncProgress(cm), nil
+}
+
+func getSyncProgress(cm *corev1.ConfigMap) *Progress {
+	totalBytes, err := strconv.ParseInt(cm.Data[SyncConfigTotalBytesKey], 10, 64)
+	if err != nil {
+		return nil
+	}
+	progress := &Progress{TotalBytes: totalBytes}
+	progress.CopiedBytes, _ = strconv.ParseInt(cm.Data[SyncConfigCopiedBytesKey], 10, 64)
+	if cm.Data[SyncConfigSyncCompleteKey] == SyncTrue || progress.CopiedBytes > totalBytes {
+		progress.CopiedBytes = totalBytes
+	}
+	return progress
+}
+
+// StopSync deletes the sync job of the volume, and releases the volume mounted on the nodes for the sync
+func (dcm *DataCopyManager) StopSync(jobName, volName string) error {
+	logCtx := logger.WithFields(log.Fields{"job": jobName, "volume": volName})
+	ctx := context.TODO()
+
+	syncJob := &batchv1.Job{}
+	if err := dcm.k8sControllerClient.Get(ctx, types.NamespacedName{Namespace: dcm.workingNamespace, Name: jobName}, syncJob); err == nil {
+		logCtx.Info("Stopping the sync job")
+		syncJob.Finalizers = []string{}
+		if err := dcm.k8sControllerClient.Update(ctx, syncJob); err != nil {
+			return err
+		}
+		propagation := metav1.DeletePropagationBackground
+		if err := dcm.k8sControllerClient.Delete(ctx, syncJob, &k8sclient.DeleteOptions{PropagationPolicy: &propagation}); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	} else if !errors.IsNotFound(err) {
+		return err
+	}
+
+	// the nodes unmount the volume once the sync is completed
+	cm := &corev1.ConfigMap{}
+	if err := dcm.k8sControllerClient.Get(ctx, types.NamespacedName{Namespace: dcm.workingNamespace, Name: GetConfigMapName(SyncConfigMapName, volName)}, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if cm.Data[SyncConfigSyncCompleteKey] == SyncTrue {
+		return nil
+	}
+	cm.Data[SyncConfigSyncCompleteKey] = SyncTrue
+	return dcm.k8sControllerClient.Update(ctx, cm)
+}
+
+// IsSyncReleased checks if the volume mounted for the sync is released on the nodes
+func (dcm *DataCopyManager) IsSyncReleased(volName string) (bool, error) {
+	cm := &corev1.ConfigMap{}
+	if err := dcm.k8sControllerClient.Get(context.TODO(), types.NamespacedName{Namespace: dcm.workingNamespace, Name: GetConfigMapName(SyncConfigMapName, volName)}, cm); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	// the node not ready has nothing mounted
+	sourceReleased := cm.Data[SyncConfigSourceNodeReadyKey] != SyncTrue || cm.Data[SyncConfigSourceNodeCompleteKey] == SyncTrue
+	targetReleased := cm.Data[SyncConfigTargetNodeReadyKey] != SyncTrue || cm.Data[SyncConfigTargetNodeCompleteKey] == SyncTrue
+	return sourceReleased && targetReleased, nil
+}
+
 func (dcm *DataCopyManager) prepareForSync(jobName, srcNodeName, dstNodeName, volName string) error {
 	logCtx := logger.WithFields(log.Fields{"job": jobName, "volume": volName})
 	logCtx.Debug("Preparing the resources for volume sync")
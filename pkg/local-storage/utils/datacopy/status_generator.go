@@ -24,8 +24,11 @@ const (
 	DataCopyStatusFailed  = "failed"
 )
 
-// TODO
-type Progress struct{}
+// Progress is the progress of the data copy
+type Progress struct {
+	CopiedBytes int64
+	TotalBytes  int64
+}
 
 type DataCopyStatus struct {
 	UserData string
@@ -33,7 +36,6 @@ type DataCopyStatus struct {
 	Phase    string
 	Event    string
 	Message  string
-	// TODO
 	Progress *Progress
 }
 
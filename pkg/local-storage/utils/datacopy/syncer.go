@@ -15,6 +15,8 @@ const (
 
 	SyncSourceMountPoint = "/mnt/hwameistor/src/"
 	SyncTargetMountPoint = "/mnt/hwameistor/dst/"
+	// SyncProgressDir is where the sync job records the bytes copied, which is reported by the target node
+	SyncProgressDir = "/mnt/hwameistor/progress/"
 
 	SyncConfigSourceMountPointKey   = "sourceMountPoint"
 	SyncConfigTargetMountPointKey   = "targetMountPoint"
@@ -26,6 +28,13 @@ const (
 	SyncConfigSourceNodeCompleteKey = "sourceCompleted"
 	SyncConfigTargetNodeCompleteKey = "targetCompleted"
 	SyncConfigSyncCompleteKey       = "syncCompleted"
+	SyncConfigSyncToolKey           = "syncTool"
+	SyncConfigSourceDeviceKey       = "sourceDevice"
+	SyncConfigTargetDeviceKey       = "targetDevice"
+	SyncConfigSkipZeroKey           = "skipZero"
+	SyncConfigBandwidthLimitKey     = "bandwidthLimit"
+	SyncConfigCopiedBytesKey        = "copiedBytes"
+	SyncConfigTotalBytesKey         = "totalBytes"
 
 	SyncTrue  string = "yes"
 	SyncFalse string = "no"
@@ -33,6 +42,7 @@ const (
 	SyncJobFinalizer = "hwameistor.io/sync-job-protect"
 
 	SyncToolJuiceSync = "juicesync"
+	SyncToolBlockSync = "blocksync"
 )
 
 type DataSyncer interface {
@@ -40,7 +50,18 @@ type DataSyncer interface {
 	StartSync(jobName, lvName, excludedRunningNodeName, runningNodeName string, dataCheckNeed bool) error
 }
 
+// NewSyncer returns the DataSyncer of the sync tool, juicesync is used for the unknown tool
 func NewSyncer(syncerName string, namespace string, client k8sclient.Client) DataSyncer {
+	switch syncerName {
+	case SyncToolBlockSync:
+		return &BlockSync{
+			namespace: namespace,
+			apiClient: client,
+		}
+	case SyncToolJuiceSync:
+	default:
+		logger.WithField("syncTool", syncerName).Warning("Unknown data sync tool, use juicesync instead")
+	}
 	return &JuiceSync{
 		namespace: namespace,
 		apiClient: client,
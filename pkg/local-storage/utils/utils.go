@@ -17,9 +17,11 @@ import (
 	"k8s.io/client-go/rest"
 
 	apisv1alpha1 "github.com/hwameistor/hwameistor/pkg/apis/hwameistor/v1alpha1"
+	"github.com/hwameistor/hwameistor/pkg/local-storage/utils/datacopy"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -265,6 +267,66 @@ func CalculateOverProvisionRatio(records []apisv1alpha1.ThinPoolExtendRecord) st
 	return "1.0"
 }
 
+// GetThinPoolAutoExtendPolicy returns the latest non-nil autoextend policy of the thin pool, nil if not set
+func GetThinPoolAutoExtendPolicy(records []apisv1alpha1.ThinPoolExtendRecord) *apisv1alpha1.ThinPoolAutoExtendPolicy {
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].Description.AutoExtend != nil {
+			return records[i].Description.AutoExtend
+		}
+	}
+	return nil
+}
+
+// IsMirrorEnabled returns true if the volume replica should be a LVM raid1 volume mirrored across the disks
+func IsMirrorEnabled(params map[string]string) bool {
+	return strings.ToLower(params[apisv1alpha1.VolumeParameterMirror]) == "true"
+}
+
+// GetVolumeCache returns the cache of the volume with the given capacity from the StorageClass parameters,
+// nil if the cache is not required
+func GetVolumeCache(params map[string]string, capacityBytes int64) (*apisv1alpha1.VolumeCache, error) {
+	cachePoolClass := params[apisv1alpha1.VolumeParameterCachePoolClass]
+	if len(cachePoolClass) == 0 {
+		return nil, nil
+	}
+	if cachePoolClass != apisv1alpha1.DiskClassNameSSD && cachePoolClass != apisv1alpha1.DiskClassNameNVMe {
+		return nil, fmt.Errorf("cache pool class %s is not supported, must be %s or %s", cachePoolClass, apisv1alpha1.DiskClassNameSSD, apisv1alpha1.DiskClassNameNVMe)
+	}
+	if cachePoolClass == params[apisv1alpha1.VolumeParameterPoolClassKey] {
+		return nil, fmt.Errorf("cache pool class must be different from the pool class %s", cachePoolClass)
+	}
+	cachePoolName, _ := BuildStoragePoolName(cachePoolClass)
+
+	cache := &apisv1alpha1.VolumeCache{
+		PoolName:      cachePoolName,
+		CapacityBytes: capacityBytes * apisv1alpha1.VolumeCacheCapacityPercentDefault / 100,
+		Mode:          apisv1alpha1.VolumeCacheModeWritethrough,
+	}
+	if capacity, ok := params[apisv1alpha1.VolumeParameterCacheCapacity]; ok {
+		quantity, err := resource.ParseQuantity(capacity)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache capacity %s: %v", capacity, err)
+		}
+		cache.CapacityBytes = quantity.Value()
+	}
+	if cache.CapacityBytes < apisv1alpha1.VolumeCacheCapacityBytesMin {
+		cache.CapacityBytes = apisv1alpha1.VolumeCacheCapacityBytesMin
+	}
+	switch mode := params[apisv1alpha1.VolumeParameterCacheMode]; mode {
+	case "":
+	case apisv1alpha1.VolumeCacheModeWritethrough, apisv1alpha1.VolumeCacheModeWriteback:
+		cache.Mode = mode
+	default:
+		return nil, fmt.Errorf("cache mode %s is not supported", mode)
+	}
+	return cache, nil
+}
+
+// VolumeCacheDevicePath returns the device of the volume through the cache on the node
+func VolumeCacheDevicePath(volumeName string) string {
+	return datacopy.CacheDevicePath(volumeName)
+}
+
 func IsSupportThinProvisioning(params map[string]string) bool {
 	thinValue, ok := params[apisv1alpha1.VolumeParameterThin]
 	if ok && strings.ToLower(thinValue) == "true" {
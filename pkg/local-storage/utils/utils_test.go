@@ -294,6 +294,41 @@ func TestCalculateOverProvisionRatio(t *testing.T) {
 	}
 }
 
+func TestGetThinPoolAutoExtendPolicy(t *testing.T) {
+	policy := &apisv1alpha1.ThinPoolAutoExtendPolicy{ThresholdPercent: 70, ExtendPercent: 10, MetadataExtendSize: 1}
+	tests := []struct {
+		name    string
+		records []apisv1alpha1.ThinPoolExtendRecord
+		want    *apisv1alpha1.ThinPoolAutoExtendPolicy
+	}{
+		{
+			name:    "should return nil when no records",
+			records: []apisv1alpha1.ThinPoolExtendRecord{},
+			want:    nil,
+		},
+		{
+			name: "should skip records without policy",
+			records: []apisv1alpha1.ThinPoolExtendRecord{
+				{
+					Description: apisv1alpha1.ThinPoolClaimDescription{AutoExtend: policy},
+				},
+				{
+					Description: apisv1alpha1.ThinPoolClaimDescription{},
+				},
+			},
+			want: policy,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GetThinPoolAutoExtendPolicy(tt.records); got != tt.want {
+				t.Errorf("GetThinPoolAutoExtendPolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestIsSupportThinProvisioning(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -344,6 +379,62 @@ func TestIsSupportThinProvisioning(t *testing.T) {
 	}
 }
 
+func TestGetVolumeCache(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]string
+		want    *apisv1alpha1.VolumeCache
+		wantErr bool
+	}{
+		{
+			name:   "should return nil when no cache pool class",
+			params: map[string]string{apisv1alpha1.VolumeParameterPoolClassKey: apisv1alpha1.DiskClassNameHDD},
+		},
+		{
+			name:   "should use the default capacity and mode",
+			params: map[string]string{apisv1alpha1.VolumeParameterCachePoolClass: apisv1alpha1.DiskClassNameNVMe},
+			want: &apisv1alpha1.VolumeCache{PoolName: apisv1alpha1.PoolNameForNVMe, CapacityBytes: Gi / 10,
+				Mode: apisv1alpha1.VolumeCacheModeWritethrough},
+		},
+		{
+			name: "should use the given capacity and mode",
+			params: map[string]string{apisv1alpha1.VolumeParameterCachePoolClass: apisv1alpha1.DiskClassNameSSD,
+				apisv1alpha1.VolumeParameterCacheCapacity: "1Mi", apisv1alpha1.VolumeParameterCacheMode: apisv1alpha1.VolumeCacheModeWriteback},
+			want: &apisv1alpha1.VolumeCache{PoolName: apisv1alpha1.PoolNameForSSD, CapacityBytes: apisv1alpha1.VolumeCacheCapacityBytesMin,
+				Mode: apisv1alpha1.VolumeCacheModeWriteback},
+		},
+		{
+			name:    "should return error when cache pool class is HDD",
+			params:  map[string]string{apisv1alpha1.VolumeParameterCachePoolClass: apisv1alpha1.DiskClassNameHDD},
+			wantErr: true,
+		},
+		{
+			name: "should return error when cache pool class is the pool class",
+			params: map[string]string{apisv1alpha1.VolumeParameterPoolClassKey: apisv1alpha1.DiskClassNameSSD,
+				apisv1alpha1.VolumeParameterCachePoolClass: apisv1alpha1.DiskClassNameSSD},
+			wantErr: true,
+		},
+		{
+			name: "should return error when cache mode is invalid",
+			params: map[string]string{apisv1alpha1.VolumeParameterCachePoolClass: apisv1alpha1.DiskClassNameNVMe,
+				apisv1alpha1.VolumeParameterCacheMode: "writearound"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetVolumeCache(tt.params, Gi)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetVolumeCache() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetVolumeCache() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
 // Helper function to create string pointer
 func stringPtr(s string) *string {
 	return &s
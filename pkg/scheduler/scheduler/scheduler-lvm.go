@@ -160,6 +160,11 @@ func (s *LVMVolumeScheduler) filterForExistingLocalVolumes(lvs []string, node *c
 				if migrate == v1alpha1.MigrateStarted {
 					return false, fmt.Errorf("volume is in migrating")
 				}
+				// the online migration switches the volume over to the target node
+				if migrate == v1alpha1.MigrateSwitchover && lv.GetAnnotations()[v1alpha1.VolumeMigrateTargetNodeAnnoKey] != node.Name {
+					log.WithFields(log.Fields{"localvolume": lvName, "node": node.Name}).Debug("LocalVolume is switching over to the other node")
+					return false, nil
+				}
 			}
 		}
 